@@ -0,0 +1,387 @@
+// Package alerts watches instruments for price level, percent change, volume spike and
+// spread width alerts. An alert fires once and is then moved to the alert log. Alerts and
+// the log are saved to a JSON file so they survive a restart.
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"finam-terminal/atomicfile"
+)
+
+// Alert kinds
+const (
+	KindPrice  = "price"  // Last price reaches Value
+	KindChange = "change" // Change from the previous close reaches Value percent
+	KindVolume = "volume" // Volume of the last minute reaches Value times the average minute volume
+	KindSpread = "spread" // Ask minus bid reaches Value
+)
+
+// MaxLog is the number of fired alerts kept in the log.
+const MaxLog = 200
+
+// Volume spike windows: the last minute is compared with the average minute of the
+// quarter of an hour before it, once at least minBaseline of it was seen.
+const (
+	spikeWindow    = time.Minute
+	baselineWindow = 15 * time.Minute
+	minBaseline    = 5 * time.Minute
+)
+
+// Alert watches one instrument. Above selects the direction of price and change alerts;
+// volume and spread alerts always watch for a value at or above Value.
+type Alert struct {
+	ID      string    `json:"id"`
+	Symbol  string    `json:"symbol"`
+	Kind    string    `json:"kind"`
+	Above   bool      `json:"above"`
+	Value   float64   `json:"value"`
+	Created time.Time `json:"created"`
+}
+
+// String describes the alert, e.g. "price ≥ 310" or "volume ≥ 3x".
+func (a Alert) String() string {
+	op := "≤"
+	if a.Above || a.Kind == KindVolume || a.Kind == KindSpread {
+		op = "≥"
+	}
+	value := strconv.FormatFloat(a.Value, 'f', -1, 64)
+	switch a.Kind {
+	case KindChange:
+		if a.Value > 0 {
+			value = "+" + value
+		}
+		return fmt.Sprintf("change %s %s%%", op, value)
+	case KindVolume:
+		return fmt.Sprintf("volume %s %sx", op, value)
+	case KindSpread:
+		return fmt.Sprintf("spread %s %s", op, value)
+	default:
+		return fmt.Sprintf("price %s %s", op, value)
+	}
+}
+
+// Validate checks the alert before it is added.
+func (a Alert) Validate() error {
+	if a.Symbol == "" {
+		return errors.New("alert has no instrument")
+	}
+	switch a.Kind {
+	case KindPrice, KindVolume, KindSpread:
+		if a.Value <= 0 {
+			return fmt.Errorf("%s alert needs a value above zero", a.Kind)
+		}
+	case KindChange:
+		if a.Value == 0 {
+			return errors.New("change alert needs a non-zero percent")
+		}
+	default:
+		return fmt.Errorf("unknown alert kind %q", a.Kind)
+	}
+	return nil
+}
+
+// Sample is the market state of an instrument. Zero fields are unknown and never fire an
+// alert. Volume is the cumulative volume of the trading day.
+type Sample struct {
+	Last      float64
+	Bid       float64
+	Ask       float64
+	PrevClose float64
+	Volume    float64
+}
+
+// Event is a fired alert in the log.
+type Event struct {
+	Time     time.Time `json:"time"`
+	Alert    Alert     `json:"alert"`
+	Observed float64   `json:"observed"` // Value that fired the alert, in the unit of its kind
+}
+
+// String describes what fired, e.g. "SBER price ≥ 310: last 310.5".
+func (e Event) String() string {
+	return fmt.Sprintf("%s %s: %s", e.Alert.Symbol, e.Alert, e.Observation())
+}
+
+// Observation describes the value that fired the alert, e.g. "last 310.5".
+func (e Event) Observation() string {
+	switch e.Alert.Kind {
+	case KindChange:
+		return "change " + strconv.FormatFloat(e.Observed, 'f', 2, 64) + "%"
+	case KindVolume:
+		return "last minute " + strconv.FormatFloat(e.Observed, 'f', 1, 64) + "x average"
+	case KindSpread:
+		return "spread " + strconv.FormatFloat(e.Observed, 'f', -1, 64)
+	default:
+		return "last " + strconv.FormatFloat(e.Observed, 'f', -1, 64)
+	}
+}
+
+type volumeSample struct {
+	at     time.Time
+	volume float64
+}
+
+// state is the content of the manager's file.
+type state struct {
+	Alerts []Alert `json:"alerts"`
+	Log    []Event `json:"log"`
+}
+
+// Manager keeps the alerts, the alert log and their file. It is safe for concurrent use.
+type Manager struct {
+	// Now returns the current time, used to stamp alerts and volume samples.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	path    string
+	alerts  map[string]*Alert
+	log     []Event // Newest first
+	volumes map[string][]volumeSample
+	next    int
+}
+
+// NewManager returns a manager saving its alerts to path. An empty path keeps the alerts
+// in memory only.
+func NewManager(path string) *Manager {
+	return &Manager{
+		Now:     time.Now,
+		path:    path,
+		alerts:  make(map[string]*Alert),
+		volumes: make(map[string][]volumeSample),
+	}
+}
+
+// Load reads the alerts and the log saved in the manager's file. A missing file is not an
+// error.
+func (m *Manager) Load() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read alerts: %w", err)
+	}
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("failed to parse alerts %s: %w", m.path, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range s.Alerts {
+		a := s.Alerts[i]
+		m.alerts[a.ID] = &a
+		m.seen(a.ID)
+	}
+	for _, e := range s.Log {
+		m.seen(e.Alert.ID)
+	}
+	m.log = s.Log
+	if len(m.log) > MaxLog {
+		m.log = m.log[:MaxLog]
+	}
+	return nil
+}
+
+// Add validates a and starts watching it.
+func (m *Manager) Add(a Alert) (Alert, error) {
+	if err := a.Validate(); err != nil {
+		return Alert{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.next++
+	a.ID = fmt.Sprintf("A%d", m.next)
+	a.Created = m.Now()
+	m.alerts[a.ID] = &a
+	return a, m.save()
+}
+
+// Remove stops watching the alert id.
+func (m *Manager) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.alerts[id]; !ok {
+		return nil
+	}
+	delete(m.alerts, id)
+	return m.save()
+}
+
+// Alerts returns the active alerts ordered by creation.
+func (m *Manager) Alerts() []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Alert, 0, len(m.alerts))
+	for _, a := range m.alerts {
+		out = append(out, *a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].before(out[j]) })
+	return out
+}
+
+// Log returns the fired alerts, newest first.
+func (m *Manager) Log() []Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Event(nil), m.log...)
+}
+
+// ClearLog forgets the fired alerts.
+func (m *Manager) ClearLog() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.log = nil
+	return m.save()
+}
+
+// Observe checks the alerts on symbol against s and returns the ones that fired, which
+// are moved to the log.
+func (m *Manager) Observe(symbol string, s Sample) ([]Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.Now()
+	spike, spikeOK := m.volumeSpike(symbol, now, s.Volume)
+
+	var fired []Event
+	for _, a := range m.alerts {
+		if a.Symbol != symbol {
+			continue
+		}
+		var observed float64
+		var ok bool
+		switch a.Kind {
+		case KindPrice:
+			observed, ok = s.Last, s.Last > 0
+		case KindChange:
+			if s.Last > 0 && s.PrevClose > 0 {
+				observed, ok = (s.Last-s.PrevClose)/s.PrevClose*100, true
+			}
+		case KindVolume:
+			observed, ok = spike, spikeOK
+		case KindSpread:
+			observed, ok = s.Ask-s.Bid, s.Bid > 0 && s.Ask > 0
+		}
+		if !ok || !a.reached(observed) {
+			continue
+		}
+		fired = append(fired, Event{Time: now, Alert: *a, Observed: observed})
+	}
+	if len(fired) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(fired, func(i, j int) bool { return fired[i].Alert.before(fired[j].Alert) })
+	for _, e := range fired {
+		delete(m.alerts, e.Alert.ID)
+		m.log = append([]Event{e}, m.log...)
+	}
+	if len(m.log) > MaxLog {
+		m.log = m.log[:MaxLog]
+	}
+	return fired, m.save()
+}
+
+// reached reports whether observed satisfies the alert.
+func (a Alert) reached(observed float64) bool {
+	if a.Above || a.Kind == KindVolume || a.Kind == KindSpread {
+		return observed >= a.Value
+	}
+	return observed <= a.Value
+}
+
+// before orders alerts by creation, then by ID.
+func (a Alert) before(b Alert) bool {
+	if !a.Created.Equal(b.Created) {
+		return a.Created.Before(b.Created)
+	}
+	na, _ := strconv.Atoi(strings.TrimPrefix(a.ID, "A"))
+	nb, _ := strconv.Atoi(strings.TrimPrefix(b.ID, "A"))
+	return na < nb
+}
+
+// volumeSpike records the cumulative volume of symbol and returns the volume of the last
+// minute as a multiple of the average minute before it. ok is false until enough history
+// was seen. Called with m.mu held.
+func (m *Manager) volumeSpike(symbol string, now time.Time, volume float64) (ratio float64, ok bool) {
+	if volume <= 0 {
+		return 0, false
+	}
+	samples := m.volumes[symbol]
+	if n := len(samples); n > 0 && volume < samples[n-1].volume {
+		// A new trading day started
+		samples = nil
+	}
+	samples = append(samples, volumeSample{at: now, volume: volume})
+
+	// Keep one sample at or before the start of the baseline
+	cutoff := now.Add(-spikeWindow - baselineWindow)
+	for len(samples) > 1 && !samples[1].at.After(cutoff) {
+		samples = samples[1:]
+	}
+	m.volumes[symbol] = samples
+
+	// The latest sample at least a minute old splits the last minute from the baseline
+	split := -1
+	for i := len(samples) - 1; i >= 0; i-- {
+		if !samples[i].at.After(now.Add(-spikeWindow)) {
+			split = i
+			break
+		}
+	}
+	if split < 0 {
+		return 0, false
+	}
+	first, mid := samples[0], samples[split]
+	span := mid.at.Sub(first.at)
+	if span < minBaseline {
+		return 0, false
+	}
+	baseline := (mid.volume - first.volume) / span.Minutes()
+	if baseline <= 0 {
+		return 0, false
+	}
+	last := (volume - mid.volume) / now.Sub(mid.at).Minutes()
+	return last / baseline, true
+}
+
+// seen keeps new IDs above id. Called with m.mu held.
+func (m *Manager) seen(id string) {
+	if n, err := strconv.Atoi(strings.TrimPrefix(id, "A")); err == nil && n > m.next {
+		m.next = n
+	}
+}
+
+// save writes the alerts and the log to the manager's file. Called with m.mu held.
+func (m *Manager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	s := state{Alerts: make([]Alert, 0, len(m.alerts)), Log: m.log}
+	for _, a := range m.alerts {
+		s.Alerts = append(s.Alerts, *a)
+	}
+	sort.Slice(s.Alerts, func(i, j int) bool { return s.Alerts[i].before(s.Alerts[j]) })
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.Write(m.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save alerts: %w", err)
+	}
+	return nil
+}
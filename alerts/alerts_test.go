@@ -0,0 +1,145 @@
+package alerts
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestObserve_PriceChangeAndSpread(t *testing.T) {
+	m := NewManager("")
+	price, _ := m.Add(Alert{Symbol: "SBER", Kind: KindPrice, Above: true, Value: 310})
+	drop, _ := m.Add(Alert{Symbol: "SBER", Kind: KindChange, Value: -3})
+	spread, _ := m.Add(Alert{Symbol: "SBER", Kind: KindSpread, Value: 0.5})
+
+	if fired, _ := m.Observe("SBER", Sample{Last: 305, Bid: 304.9, Ask: 305.1, PrevClose: 300}); len(fired) != 0 {
+		t.Fatalf("Expected nothing to fire, got %v", fired)
+	}
+	if fired, _ := m.Observe("GAZP", Sample{Last: 400}); len(fired) != 0 {
+		t.Fatalf("Expected no alert from another symbol, got %v", fired)
+	}
+
+	fired, err := m.Observe("SBER", Sample{Last: 310.5, Bid: 310, Ask: 310.6, PrevClose: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fired) != 2 || fired[0].Alert.ID != price.ID || fired[1].Alert.ID != spread.ID {
+		t.Fatalf("Expected the price and spread alerts to fire, got %v", fired)
+	}
+	if got := fired[0].String(); got != "SBER price ≥ 310: last 310.5" {
+		t.Errorf("Unexpected event %q", got)
+	}
+
+	// Fired alerts are one-shot
+	if fired, _ := m.Observe("SBER", Sample{Last: 311, Bid: 310, Ask: 311, PrevClose: 300}); len(fired) != 0 {
+		t.Fatalf("Expected fired alerts not to fire again, got %v", fired)
+	}
+
+	fired, _ = m.Observe("SBER", Sample{Last: 290, PrevClose: 300})
+	if len(fired) != 1 || fired[0].Alert.ID != drop.ID {
+		t.Fatalf("Expected the change alert to fire at -3.33%%, got %v", fired)
+	}
+	if got := m.Alerts(); len(got) != 0 {
+		t.Errorf("Expected no active alerts left, got %v", got)
+	}
+	if log := m.Log(); len(log) != 3 || log[0].Alert.ID != drop.ID {
+		t.Errorf("Expected three events newest first, got %v", log)
+	}
+}
+
+func TestObserve_VolumeSpike(t *testing.T) {
+	m := NewManager("")
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	m.Now = func() time.Time { return now }
+	m.Add(Alert{Symbol: "SBER", Kind: KindVolume, Value: 3})
+
+	// 1000 a minute for ten minutes
+	volume := 0.0
+	for i := 0; i <= 10; i++ {
+		if fired, _ := m.Observe("SBER", Sample{Volume: volume}); len(fired) != 0 {
+			t.Fatalf("Expected no spike at a steady volume, got %v", fired)
+		}
+		now = now.Add(time.Minute)
+		volume += 1000
+	}
+
+	// 2500 in the next minute is not enough
+	volume += 1500
+	if fired, _ := m.Observe("SBER", Sample{Volume: volume}); len(fired) != 0 {
+		t.Fatalf("Expected no spike at 2.5x, got %v", fired)
+	}
+
+	now = now.Add(time.Minute)
+	volume += 4000
+	fired, _ := m.Observe("SBER", Sample{Volume: volume})
+	if len(fired) != 1 || fired[0].Observed < 3 {
+		t.Fatalf("Expected a spike, got %v", fired)
+	}
+}
+
+func TestObserve_VolumeNeedsBaseline(t *testing.T) {
+	m := NewManager("")
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	m.Now = func() time.Time { return now }
+	m.Add(Alert{Symbol: "SBER", Kind: KindVolume, Value: 2})
+
+	m.Observe("SBER", Sample{Volume: 1000})
+	now = now.Add(2 * time.Minute)
+	m.Observe("SBER", Sample{Volume: 1100})
+	now = now.Add(time.Minute)
+	if fired, _ := m.Observe("SBER", Sample{Volume: 50000}); len(fired) != 0 {
+		t.Errorf("Expected no spike without five minutes of history, got %v", fired)
+	}
+}
+
+func TestAlert_Validate(t *testing.T) {
+	tests := []struct {
+		a  Alert
+		ok bool
+	}{
+		{Alert{Symbol: "SBER", Kind: KindPrice, Value: 310}, true},
+		{Alert{Symbol: "SBER", Kind: KindChange, Value: -5}, true},
+		{Alert{Symbol: "SBER", Kind: KindChange}, false},
+		{Alert{Symbol: "SBER", Kind: KindVolume, Value: -1}, false},
+		{Alert{Kind: KindPrice, Value: 1}, false},
+		{Alert{Symbol: "SBER", Kind: "news", Value: 1}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.a.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%+v: got %v, want ok=%v", tt.a, err, tt.ok)
+		}
+	}
+}
+
+func TestManager_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	m := NewManager(path)
+	a, _ := m.Add(Alert{Symbol: "SBER", Kind: KindPrice, Above: true, Value: 310})
+	b, _ := m.Add(Alert{Symbol: "GAZP", Kind: KindSpread, Value: 1})
+	if _, err := m.Observe("SBER", Sample{Last: 311}); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := NewManager(path)
+	if err := restarted.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := restarted.Alerts(); len(got) != 1 || got[0].ID != b.ID {
+		t.Fatalf("Expected %s to survive a restart, got %v", b.ID, got)
+	}
+	if log := restarted.Log(); len(log) != 1 || log[0].Alert.ID != a.ID {
+		t.Fatalf("Expected the fired %s in the log, got %v", a.ID, log)
+	}
+
+	next, _ := restarted.Add(Alert{Symbol: "LKOH", Kind: KindPrice, Value: 1})
+	if next.ID == a.ID || next.ID == b.ID {
+		t.Errorf("Expected a new ID, got %s again", next.ID)
+	}
+}
+
+func TestManager_LoadMissingFile(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "none.json"))
+	if err := m.Load(); err != nil {
+		t.Errorf("Expected no error for a missing file, got %v", err)
+	}
+}
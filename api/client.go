@@ -7,15 +7,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"finam-terminal/api/journal"
 	"finam-terminal/models"
 
 	"google.golang.org/genproto/googleapis/type/decimal"
 	"google.golang.org/genproto/googleapis/type/interval"
+	"google.golang.org/genproto/googleapis/type/money"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
@@ -49,11 +52,24 @@ type Client struct {
 	refreshCancel context.CancelFunc
 
 	// Cache for instrument MIC codes
-	assetMicCache       map[string]string  // ticker -> symbol@mic
-	assetLotCache       map[string]float64 // ticker -> lot size
-	instrumentNameCache map[string]string  // ticker or symbol -> human-readable name
+	assetMicCache       map[string]string         // ticker -> symbol@mic
+	assetLotCache       map[string]models.Decimal // ticker -> lot size
+	instrumentNameCache map[string]string         // ticker or symbol -> human-readable name
 	securityCache       []models.SecurityInfo
 	assetMutex          sync.RWMutex
+
+	// Streaming quote subscription shared by all views
+	quotes quoteStream
+
+	// Market data journal, see StartRecording
+	rec recorder
+
+	// File order modifications are audited to, see SetModifyAudit
+	modifyAudit   string
+	modifyAuditMu sync.Mutex
+
+	// onClose releases resources owned by the client, such as the paper-trading server
+	onClose func()
 }
 
 // NewClient creates a new Finam API client
@@ -90,7 +106,7 @@ func newClientFromConn(conn *grpc.ClientConn, apiToken string) (*Client, error)
 		ordersClient:        orders.NewOrdersServiceClient(conn),
 		apiToken:            apiToken,
 		assetMicCache:       make(map[string]string),
-		assetLotCache:       make(map[string]float64),
+		assetLotCache:       make(map[string]models.Decimal),
 		instrumentNameCache: make(map[string]string),
 		securityCache:       make([]models.SecurityInfo, 0),
 	}
@@ -118,10 +134,18 @@ func (c *Client) Close() error {
 	if c.refreshCancel != nil {
 		c.refreshCancel()
 	}
+	c.stopQuoteStream()
+	if err := c.StopRecording(); err != nil {
+		log.Printf("[WARN] %v", err)
+	}
+	var err error
 	if c.conn != nil {
-		return c.conn.Close()
+		err = c.conn.Close()
 	}
-	return nil
+	if c.onClose != nil {
+		c.onClose()
+	}
+	return err
 }
 
 // startTokenRefresh runs in a goroutine and proactively refreshes the token
@@ -341,7 +365,7 @@ func (c *Client) getFullSymbol(ticker string, accountID string) string {
 	if resp.Ticker != "" && resp.Board != "" {
 		fullSymbol := fmt.Sprintf("%s@%s", resp.Ticker, resp.Board)
 		lotSizeStr := formatDecimal(resp.LotSize)
-		lotSize, parseErr := strconv.ParseFloat(strings.ReplaceAll(lotSizeStr, ",", "."), 64)
+		lotSize, parseErr := models.ParseDecimal(lotSizeStr)
 		if parseErr != nil {
 			log.Printf("[WARN] Failed to parse lot size '%s' for %s: %v", lotSizeStr, ticker, parseErr)
 		}
@@ -386,7 +410,7 @@ func (c *Client) fetchLotSize(symbol string, accountID string) {
 
 	if resp.LotSize != nil {
 		lotSizeStr := formatDecimal(resp.LotSize)
-		lotSize, parseErr := strconv.ParseFloat(strings.ReplaceAll(lotSizeStr, ",", "."), 64)
+		lotSize, parseErr := models.ParseDecimal(lotSizeStr)
 		if parseErr != nil {
 			log.Printf("[WARN] Failed to parse lot size '%s' for %s: %v", lotSizeStr, symbol, parseErr)
 			return
@@ -402,8 +426,8 @@ func (c *Client) fetchLotSize(symbol string, accountID string) {
 	}
 }
 
-// GetLotSize returns the cached lot size for a ticker
-func (c *Client) GetLotSize(ticker string) float64 {
+// GetLotSize returns the cached lot size for a ticker, or zero when it is unknown
+func (c *Client) GetLotSize(ticker string) models.Decimal {
 	c.assetMutex.RLock()
 	defer c.assetMutex.RUnlock()
 
@@ -417,7 +441,7 @@ func (c *Client) GetLotSize(ticker string) float64 {
 		return c.assetLotCache[full]
 	}
 
-	return 0
+	return models.Decimal{}
 }
 
 // GetInstrumentName returns the cached human-readable name for a ticker or full symbol.
@@ -483,10 +507,31 @@ func (c *Client) getContext() (context.Context, context.CancelFunc) {
 
 // PlaceOrder places a new order. Quantity is in lots; it is multiplied by the lot size before sending to the API.
 // params is optional — when nil or when OrderType is empty/Market, a market order is placed.
-func (c *Client) PlaceOrder(accountID string, symbol string, buySell string, quantity float64, params *models.OrderParams) (string, error) {
+func (c *Client) PlaceOrder(accountID string, symbol string, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error) {
+	req, err := c.BuildOrder(accountID, symbol, buySell, quantity, params)
+	if err != nil {
+		return "", err
+	}
+
 	ctx, cancel := c.getContext()
 	defer cancel()
 
+	resp, err := c.ordersClient.PlaceOrder(ctx, req)
+	if err != nil {
+		c.logGRPCError("OrdersService", "PlaceOrder", err,
+			fmt.Sprintf("AccountId: %s", accountID),
+			fmt.Sprintf("Symbol: %s", req.Symbol),
+			fmt.Sprintf("Side: %s", buySell),
+			fmt.Sprintf("Quantity: %v", quantity))
+		return "", fmt.Errorf("failed to place order: %w", err)
+	}
+
+	return resp.OrderId, nil
+}
+
+// BuildOrder resolves the full symbol and converts lots to shares, returning the request
+// PlaceOrder would send. It makes no order RPC, so it is also used for dry runs.
+func (c *Client) BuildOrder(accountID string, symbol string, buySell string, quantity models.Decimal, params *models.OrderParams) (*orders.Order, error) {
 	fullSymbol := c.getFullSymbol(symbol, accountID)
 	log.Printf("[DEBUG] PlaceOrder: input='%s', resolved='%s'", symbol, fullSymbol)
 
@@ -497,26 +542,24 @@ func (c *Client) PlaceOrder(accountID string, symbol string, buySell string, qua
 	case "sell":
 		side = tradeapiv1.Side_SIDE_SELL
 	default:
-		return "", fmt.Errorf("invalid direction: %s", buySell)
+		return nil, fmt.Errorf("invalid direction: %s", buySell)
 	}
 
 	// Multiply quantity (lots) by lot size to get shares
 	lotSize := c.GetLotSize(symbol)
-	if lotSize <= 0 {
+	if lotSize.Sign() <= 0 {
 		lotSize = c.GetLotSize(fullSymbol)
 	}
 	actualQuantity := quantity
-	if lotSize > 0 {
-		actualQuantity = quantity * lotSize
-		log.Printf("[DEBUG] PlaceOrder: %v lots * %.0f lot size = %.0f shares", quantity, lotSize, actualQuantity)
+	if lotSize.Sign() > 0 {
+		actualQuantity = quantity.Mul(lotSize)
+		log.Printf("[DEBUG] PlaceOrder: %s lots * %v lot size = %s shares", quantity, lotSize, actualQuantity)
 	}
 
-	qtyDecimal := &decimal.Decimal{Value: strconv.FormatFloat(actualQuantity, 'f', -1, 64)}
-
 	req := &orders.Order{
 		AccountId: accountID,
 		Symbol:    fullSymbol,
-		Quantity:  qtyDecimal,
+		Quantity:  toProtoDecimal(actualQuantity),
 		Side:      side,
 		Type:      orders.OrderType_ORDER_TYPE_MARKET,
 	}
@@ -526,50 +569,86 @@ func (c *Client) PlaceOrder(accountID string, symbol string, buySell string, qua
 		switch params.OrderType {
 		case models.OrderTypeLimit:
 			req.Type = orders.OrderType_ORDER_TYPE_LIMIT
-			req.LimitPrice = &decimal.Decimal{Value: strconv.FormatFloat(params.LimitPrice, 'f', -1, 64)}
+			req.LimitPrice = toProtoDecimal(params.LimitPrice)
+			req.ValidBefore = validBefore(params.Validity)
 		case models.OrderTypeStop:
 			req.Type = orders.OrderType_ORDER_TYPE_STOP
-			req.StopPrice = &decimal.Decimal{Value: strconv.FormatFloat(params.StopPrice, 'f', -1, 64)}
+			req.StopPrice = toProtoDecimal(params.StopPrice)
 			// SL: sell when price drops (LAST_DOWN), buy when price rises (LAST_UP)
 			if side == tradeapiv1.Side_SIDE_SELL {
 				req.StopCondition = orders.StopCondition_STOP_CONDITION_LAST_DOWN
 			} else {
 				req.StopCondition = orders.StopCondition_STOP_CONDITION_LAST_UP
 			}
-			req.ValidBefore = orders.ValidBefore_VALID_BEFORE_GOOD_TILL_CANCEL
+			req.ValidBefore = validBefore(params.Validity)
 		case models.OrderTypeTakeProfit:
 			req.Type = orders.OrderType_ORDER_TYPE_STOP
-			req.StopPrice = &decimal.Decimal{Value: strconv.FormatFloat(params.StopPrice, 'f', -1, 64)}
+			req.StopPrice = toProtoDecimal(params.StopPrice)
 			// TP: sell when price rises (LAST_UP), buy when price drops (LAST_DOWN)
 			if side == tradeapiv1.Side_SIDE_SELL {
 				req.StopCondition = orders.StopCondition_STOP_CONDITION_LAST_UP
 			} else {
 				req.StopCondition = orders.StopCondition_STOP_CONDITION_LAST_DOWN
 			}
-			req.ValidBefore = orders.ValidBefore_VALID_BEFORE_GOOD_TILL_CANCEL
+			req.ValidBefore = validBefore(params.Validity)
+		case models.OrderTypeStopLimit:
+			req.Type = orders.OrderType_ORDER_TYPE_STOP_LIMIT
+			req.StopPrice = toProtoDecimal(params.StopPrice)
+			req.LimitPrice = toProtoDecimal(params.LimitPrice)
+			// Triggers like a stop-loss, then rests at the limit price
+			if side == tradeapiv1.Side_SIDE_SELL {
+				req.StopCondition = orders.StopCondition_STOP_CONDITION_LAST_DOWN
+			} else {
+				req.StopCondition = orders.StopCondition_STOP_CONDITION_LAST_UP
+			}
+			req.ValidBefore = validBefore(params.Validity)
 		}
 	}
 
-	resp, err := c.ordersClient.PlaceOrder(ctx, req)
-	if err != nil {
-		c.logGRPCError("OrdersService", "PlaceOrder", err,
-			fmt.Sprintf("AccountId: %s", accountID),
-			fmt.Sprintf("Symbol: %s", fullSymbol),
-			fmt.Sprintf("Side: %s", buySell),
-			fmt.Sprintf("Quantity: %v", quantity))
-		return "", fmt.Errorf("failed to place order: %w", err)
-	}
+	return req, nil
+}
 
-	return resp.OrderId, nil
+// validBefore maps the validity chosen in the order modal to the API enum. The request has
+// no expiry date, so a GTD order goes out as good-till-cancel and the terminal cancels it
+// after its last day.
+func validBefore(validity string) orders.ValidBefore {
+	switch validity {
+	case models.ValidityDay:
+		return orders.ValidBefore_VALID_BEFORE_END_OF_DAY
+	default:
+		return orders.ValidBefore_VALID_BEFORE_GOOD_TILL_CANCEL
+	}
 }
 
 // PlaceSLTPOrder places a linked stop-loss + take-profit order pair.
 // Quantities are in lots; they are multiplied by the lot size before sending.
 // Either slPrice or tpPrice (or both) must be non-zero.
-func (c *Client) PlaceSLTPOrder(accountID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice float64) (string, error) {
+func (c *Client) PlaceSLTPOrder(accountID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice models.Decimal) (string, error) {
+	req, err := c.BuildSLTPOrder(accountID, symbol, buySell, slQty, slPrice, tpQty, tpPrice)
+	if err != nil {
+		return "", err
+	}
+
 	ctx, cancel := c.getContext()
 	defer cancel()
 
+	resp, err := c.ordersClient.PlaceSLTPOrder(ctx, req)
+	if err != nil {
+		c.logGRPCError("OrdersService", "PlaceSLTPOrder", err,
+			fmt.Sprintf("AccountId: %s", accountID),
+			fmt.Sprintf("Symbol: %s", req.Symbol),
+			fmt.Sprintf("Side: %s", buySell),
+			fmt.Sprintf("SL: qty=%v price=%v", slQty, slPrice),
+			fmt.Sprintf("TP: qty=%v price=%v", tpQty, tpPrice))
+		return "", fmt.Errorf("failed to place SL/TP order: %w", err)
+	}
+
+	return resp.OrderId, nil
+}
+
+// BuildSLTPOrder returns the request PlaceSLTPOrder would send, with quantities converted
+// from lots to shares. It makes no order RPC, so it is also used for dry runs.
+func (c *Client) BuildSLTPOrder(accountID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice models.Decimal) (*orders.SLTPOrder, error) {
 	fullSymbol := c.getFullSymbol(symbol, accountID)
 
 	var side tradeapiv1.Side
@@ -579,12 +658,12 @@ func (c *Client) PlaceSLTPOrder(accountID, symbol, buySell string, slQty, slPric
 	case "sell":
 		side = tradeapiv1.Side_SIDE_SELL
 	default:
-		return "", fmt.Errorf("invalid direction: %s", buySell)
+		return nil, fmt.Errorf("invalid direction: %s", buySell)
 	}
 
 	// Resolve lot size
 	lotSize := c.GetLotSize(symbol)
-	if lotSize <= 0 {
+	if lotSize.Sign() <= 0 {
 		lotSize = c.GetLotSize(fullSymbol)
 	}
 
@@ -595,36 +674,25 @@ func (c *Client) PlaceSLTPOrder(accountID, symbol, buySell string, slQty, slPric
 		ValidBefore: orders.ValidBefore_VALID_BEFORE_GOOD_TILL_CANCEL,
 	}
 
-	if slPrice > 0 {
+	if slPrice.Sign() > 0 {
 		actualSlQty := slQty
-		if lotSize > 0 {
-			actualSlQty = slQty * lotSize
+		if lotSize.Sign() > 0 {
+			actualSlQty = slQty.Mul(lotSize)
 		}
-		req.QuantitySl = &decimal.Decimal{Value: strconv.FormatFloat(actualSlQty, 'f', -1, 64)}
-		req.SlPrice = &decimal.Decimal{Value: strconv.FormatFloat(slPrice, 'f', -1, 64)}
+		req.QuantitySl = toProtoDecimal(actualSlQty)
+		req.SlPrice = toProtoDecimal(slPrice)
 	}
 
-	if tpPrice > 0 {
+	if tpPrice.Sign() > 0 {
 		actualTpQty := tpQty
-		if lotSize > 0 {
-			actualTpQty = tpQty * lotSize
+		if lotSize.Sign() > 0 {
+			actualTpQty = tpQty.Mul(lotSize)
 		}
-		req.QuantityTp = &decimal.Decimal{Value: strconv.FormatFloat(actualTpQty, 'f', -1, 64)}
-		req.TpPrice = &decimal.Decimal{Value: strconv.FormatFloat(tpPrice, 'f', -1, 64)}
-	}
-
-	resp, err := c.ordersClient.PlaceSLTPOrder(ctx, req)
-	if err != nil {
-		c.logGRPCError("OrdersService", "PlaceSLTPOrder", err,
-			fmt.Sprintf("AccountId: %s", accountID),
-			fmt.Sprintf("Symbol: %s", fullSymbol),
-			fmt.Sprintf("Side: %s", buySell),
-			fmt.Sprintf("SL: qty=%v price=%v", slQty, slPrice),
-			fmt.Sprintf("TP: qty=%v price=%v", tpQty, tpPrice))
-		return "", fmt.Errorf("failed to place SL/TP order: %w", err)
+		req.QuantityTp = toProtoDecimal(actualTpQty)
+		req.TpPrice = toProtoDecimal(tpPrice)
 	}
 
-	return resp.OrderId, nil
+	return req, nil
 }
 
 // CancelOrder cancels an active order by its ID.
@@ -648,7 +716,7 @@ func (c *Client) CancelOrder(accountID, orderID string) error {
 }
 
 // ClosePosition closes (fully or partially) an existing position
-func (c *Client) ClosePosition(accountID string, symbol string, currentQuantity string, closeQuantity float64) (string, error) {
+func (c *Client) ClosePosition(accountID string, symbol string, currentQuantity, closeQuantity models.Decimal) (string, error) {
 	// Determine direction
 	pos := models.Position{Quantity: currentQuantity}
 	dir := pos.GetCloseDirection()
@@ -758,15 +826,20 @@ func (c *Client) GetAccountDetails(accountID string) (*models.AccountInfo, []mod
 			Name:          name,
 			MIC:           mic,
 			LotSize:       lotSize,
-			Quantity:      formatDecimal(pos.Quantity),
-			AveragePrice:  formatDecimal(pos.AveragePrice),
-			CurrentPrice:  formatDecimal(pos.CurrentPrice),
-			DailyPnL:      formatDecimal(pos.DailyPnl),
-			UnrealizedPnL: formatDecimal(pos.UnrealizedPnl),
+			Quantity:      decimalOf(pos.Quantity),
+			AveragePrice:  decimalOf(pos.AveragePrice),
+			CurrentPrice:  decimalOf(pos.CurrentPrice),
+			DailyPnL:      decimalOf(pos.DailyPnl),
+			UnrealizedPnL: decimalOf(pos.UnrealizedPnl),
+		}
+
+		// A position closed today still carries the day's realized result
+		if position.DailyPnL.Known() {
+			account.DailyPnL = account.DailyPnL.Add(position.DailyPnL)
 		}
 
 		// Filter out zero positions (historical or closed)
-		if qtyVal, err := strconv.ParseFloat(position.Quantity, 64); err == nil && qtyVal == 0 {
+		if position.Quantity.IsZero() {
 			continue
 		}
 
@@ -800,28 +873,36 @@ func (c *Client) GetQuotes(accountID string, symbols []string) (map[string]*mode
 		if q == nil {
 			continue
 		}
+		c.record(journal.KindQuote, fullSymbol, 0, q)
 
-		quotes[fullSymbol] = &models.Quote{
-			Symbol:       fullSymbol,
-			Bid:          formatDecimal(q.Bid),
-			BidSize:      formatDecimal(q.BidSize),
-			Ask:          formatDecimal(q.Ask),
-			AskSize:      formatDecimal(q.AskSize),
-			Last:         formatDecimal(q.Last),
-			LastSize:     formatDecimal(q.LastSize),
-			Volume:       formatDecimal(q.Volume),
-			Open:         formatDecimal(q.Open),
-			High:         formatDecimal(q.High),
-			Low:          formatDecimal(q.Low),
-			Close:        formatDecimal(q.Close),
-			OpenInterest: formatDecimal(q.OpenInterest),
-			Timestamp:    q.Timestamp.AsTime().Local(),
-		}
+		quote := quoteFromProto(q)
+		quote.Symbol = fullSymbol
+		quotes[fullSymbol] = quote
 	}
 
 	return quotes, nil
 }
 
+// quoteFromProto converts an API quote into the model used by the UI.
+func quoteFromProto(q *marketdata.Quote) *models.Quote {
+	return &models.Quote{
+		Symbol:       q.Symbol,
+		Bid:          decimalOf(q.Bid),
+		BidSize:      decimalOf(q.BidSize),
+		Ask:          decimalOf(q.Ask),
+		AskSize:      decimalOf(q.AskSize),
+		Last:         decimalOf(q.Last),
+		LastSize:     decimalOf(q.LastSize),
+		Volume:       decimalOf(q.Volume),
+		Open:         decimalOf(q.Open),
+		High:         decimalOf(q.High),
+		Low:          decimalOf(q.Low),
+		Close:        decimalOf(q.Close),
+		OpenInterest: decimalOf(q.OpenInterest),
+		Timestamp:    q.Timestamp.AsTime().Local(),
+	}
+}
+
 // SearchSecurities searches for securities by ticker or name
 func (c *Client) SearchSecurities(query string) ([]models.SecurityInfo, error) {
 	c.assetMutex.RLock()
@@ -846,61 +927,161 @@ func (c *Client) SearchSecurities(query string) ([]models.SecurityInfo, error) {
 	return results, nil
 }
 
-// GetTradeHistory returns trade history for an account
+// TradeHistoryChunk is the longest period fetched by one Trades request. Longer periods
+// are walked back chunk by chunk.
+const TradeHistoryChunk = 30 * 24 * time.Hour
+
+// GetTradeHistory returns the trades of an account over the last 30 days
 func (c *Client) GetTradeHistory(accountID string) ([]models.Trade, error) {
+	now := time.Now()
+	return c.GetTradeHistoryRange(accountID, now.AddDate(0, 0, -30), now)
+}
+
+// GetTradeHistoryRange returns the trades of an account between from and to, oldest first.
+// The period is fetched in TradeHistoryChunk pieces from the newest back, and trades seen
+// in two pieces are returned once.
+func (c *Client) GetTradeHistoryRange(accountID string, from, to time.Time) ([]models.Trade, error) {
+	seen := make(map[string]bool)
+	var trades []models.Trade
+	err := walkBack(from, to, func(start, end time.Time) error {
+		chunk, err := c.fetchTrades(accountID, start, end)
+		if err != nil {
+			return err
+		}
+		for _, t := range chunk {
+			if t.ID != "" && seen[t.ID] {
+				continue
+			}
+			seen[t.ID] = true
+			trades = append(trades, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].Timestamp.Before(trades[j].Timestamp) })
+	return trades, nil
+}
+
+// walkBack calls fetch for the period between from and to in TradeHistoryChunk pieces,
+// newest first, and stops at the first error.
+func walkBack(from, to time.Time, fetch func(start, end time.Time) error) error {
+	for end := to; end.After(from); end = end.Add(-TradeHistoryChunk) {
+		start := end.Add(-TradeHistoryChunk)
+		if start.Before(from) {
+			start = from
+		}
+		if err := fetch(start, end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchTrades makes one Trades request for the period between start and end.
+func (c *Client) fetchTrades(accountID string, start, end time.Time) ([]models.Trade, error) {
 	ctx, cancel := c.getContext()
 	defer cancel()
 
-	now := time.Now()
-	startTime := now.AddDate(0, 0, -30) // Last 30 days
-
 	resp, err := c.accountsClient.Trades(ctx, &accounts.TradesRequest{
 		AccountId: accountID,
 		Interval: &interval.Interval{
-			StartTime: timestamppb.New(startTime),
-			EndTime:   timestamppb.New(now),
+			StartTime: timestamppb.New(start),
+			EndTime:   timestamppb.New(end),
 		},
 	})
 	if err != nil {
 		c.logGRPCError("AccountsService", "Trades", err,
 			fmt.Sprintf("AccountId: %s", accountID),
-			fmt.Sprintf("Interval: %s / %s", startTime.Format(time.RFC3339), now.Format(time.RFC3339)))
+			fmt.Sprintf("Interval: %s / %s", start.Format(time.RFC3339), end.Format(time.RFC3339)))
 		return nil, fmt.Errorf("failed to get trades: %w", err)
 	}
 
 	var trades []models.Trade
 	for _, t := range resp.Trades {
-		side := "Unknown"
-		switch t.Side {
-		case tradeapiv1.Side_SIDE_BUY:
-			side = "Buy"
-		case tradeapiv1.Side_SIDE_SELL:
-			side = "Sell"
-		}
+		trades = append(trades, c.tradeFromProto(t))
+	}
+	return trades, nil
+}
 
-		priceStr := formatDecimal(t.Price)
-		qtyStr := formatDecimal(t.Size)
+// tradeFromProto converts an account trade from Trades or the trades stream into a models.Trade.
+func (c *Client) tradeFromProto(t *tradeapiv1.AccountTrade) models.Trade {
+	side := "Unknown"
+	switch t.Side {
+	case tradeapiv1.Side_SIDE_BUY:
+		side = "Buy"
+	case tradeapiv1.Side_SIDE_SELL:
+		side = "Sell"
+	}
 
-		price, _ := strconv.ParseFloat(priceStr, 64)
-		qty, _ := strconv.ParseFloat(qtyStr, 64)
-		total := price * qty
+	price := decimalOf(t.Price)
+	qty := decimalOf(t.Size)
 
-		c.assetMutex.RLock()
-		name := c.instrumentNameCache[t.Symbol]
-		c.assetMutex.RUnlock()
+	c.assetMutex.RLock()
+	name := c.instrumentNameCache[t.Symbol]
+	c.assetMutex.RUnlock()
+
+	return models.Trade{
+		ID:        t.TradeId,
+		Symbol:    t.Symbol,
+		Name:      name,
+		Side:      side,
+		Price:     price,
+		Quantity:  qty,
+		Total:     price.Mul(qty),
+		Timestamp: t.Timestamp.AsTime().Local(),
+	}
+}
+
+// GetTransactions returns the cash transactions of an account between from and to, oldest
+// first. Like trades, the period is fetched in TradeHistoryChunk pieces.
+func (c *Client) GetTransactions(accountID string, from, to time.Time) ([]models.Transaction, error) {
+	seen := make(map[string]bool)
+	var txs []models.Transaction
+	err := walkBack(from, to, func(start, end time.Time) error {
+		ctx, cancel := c.getContext()
+		defer cancel()
 
-		trades = append(trades, models.Trade{
-			ID:        t.TradeId,
-			Symbol:    t.Symbol,
-			Name:      name,
-			Side:      side,
-			Price:     priceStr,
-			Quantity:  qtyStr,
-			Total:     fmt.Sprintf("%.2f", total),
-			Timestamp: t.Timestamp.AsTime().Local(),
+		resp, err := c.accountsClient.Transactions(ctx, &accounts.TransactionsRequest{
+			AccountId: accountID,
+			Interval: &interval.Interval{
+				StartTime: timestamppb.New(start),
+				EndTime:   timestamppb.New(end),
+			},
 		})
+		if err != nil {
+			c.logGRPCError("AccountsService", "Transactions", err,
+				fmt.Sprintf("AccountId: %s", accountID),
+				fmt.Sprintf("Interval: %s / %s", start.Format(time.RFC3339), end.Format(time.RFC3339)))
+			return fmt.Errorf("failed to get transactions: %w", err)
+		}
+
+		for _, t := range resp.Transactions {
+			if t.Id != "" && seen[t.Id] {
+				continue
+			}
+			seen[t.Id] = true
+			tx := models.Transaction{
+				ID:          t.Id,
+				Category:    models.TxCategory(t.Category),
+				Description: t.Category,
+				Symbol:      t.Symbol,
+				Amount:      moneyDecimal(t.Change),
+				Timestamp:   t.Timestamp.AsTime().Local(),
+			}
+			if t.Change != nil {
+				tx.Currency = t.Change.CurrencyCode
+			}
+			txs = append(txs, tx)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return trades, nil
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].Timestamp.Before(txs[j].Timestamp) })
+	return txs, nil
 }
 
 // GetActiveOrders returns active orders for an account
@@ -918,173 +1099,166 @@ func (c *Client) GetActiveOrders(accountID string) ([]models.Order, error) {
 
 	var activeOrders []models.Order
 	for _, o := range resp.Orders {
-		side := "Unknown"
-		if o.Order != nil {
-			switch o.Order.Side {
-			case tradeapiv1.Side_SIDE_BUY:
-				side = "Buy"
-			case tradeapiv1.Side_SIDE_SELL:
-				side = "Sell"
-			}
+		activeOrders = append(activeOrders, c.orderFromProto(o))
+	}
+	return activeOrders, nil
+}
+
+// orderFromProto converts an order state from GetOrders or the orders stream into a models.Order.
+func (c *Client) orderFromProto(o *orders.OrderState) models.Order {
+	side := "Unknown"
+	if o.Order != nil {
+		switch o.Order.Side {
+		case tradeapiv1.Side_SIDE_BUY:
+			side = "Buy"
+		case tradeapiv1.Side_SIDE_SELL:
+			side = "Sell"
 		}
+	}
 
-		status := "Active"
-		switch o.Status {
-		case orders.OrderStatus_ORDER_STATUS_UNSPECIFIED:
-			status = "Active"
-		case orders.OrderStatus_ORDER_STATUS_NEW,
-			orders.OrderStatus_ORDER_STATUS_WATCHING,
-			orders.OrderStatus_ORDER_STATUS_WAIT,
-			orders.OrderStatus_ORDER_STATUS_FORWARDING,
-			orders.OrderStatus_ORDER_STATUS_PENDING_NEW:
-			status = "Active"
-		case orders.OrderStatus_ORDER_STATUS_PARTIALLY_FILLED:
-			status = "Partial"
-		case orders.OrderStatus_ORDER_STATUS_FILLED:
-			status = "Filled"
-		case orders.OrderStatus_ORDER_STATUS_CANCELED:
-			status = "Cancelled"
-		case orders.OrderStatus_ORDER_STATUS_REJECTED,
-			orders.OrderStatus_ORDER_STATUS_DENIED_BY_BROKER,
-			orders.OrderStatus_ORDER_STATUS_REJECTED_BY_EXCHANGE:
-			status = "Rejected"
-		case orders.OrderStatus_ORDER_STATUS_EXECUTED,
-			orders.OrderStatus_ORDER_STATUS_SL_EXECUTED,
-			orders.OrderStatus_ORDER_STATUS_TP_EXECUTED:
-			status = "Executed"
-		case orders.OrderStatus_ORDER_STATUS_EXPIRED,
-			orders.OrderStatus_ORDER_STATUS_DONE_FOR_DAY:
-			status = "Expired"
-		case orders.OrderStatus_ORDER_STATUS_SUSPENDED,
-			orders.OrderStatus_ORDER_STATUS_DISABLED:
-			status = "Suspended"
-		case orders.OrderStatus_ORDER_STATUS_FAILED:
-			status = "Failed"
-		case orders.OrderStatus_ORDER_STATUS_LINK_WAIT,
-			orders.OrderStatus_ORDER_STATUS_SL_GUARD_TIME,
-			orders.OrderStatus_ORDER_STATUS_SL_FORWARDING,
-			orders.OrderStatus_ORDER_STATUS_TP_GUARD_TIME,
-			orders.OrderStatus_ORDER_STATUS_TP_CORRECTION,
-			orders.OrderStatus_ORDER_STATUS_TP_FORWARDING,
-			orders.OrderStatus_ORDER_STATUS_TP_CORR_GUARD_TIME:
-			status = "Active"
+	status := "Active"
+	switch o.Status {
+	case orders.OrderStatus_ORDER_STATUS_UNSPECIFIED:
+		status = "Active"
+	case orders.OrderStatus_ORDER_STATUS_NEW,
+		orders.OrderStatus_ORDER_STATUS_WATCHING,
+		orders.OrderStatus_ORDER_STATUS_WAIT,
+		orders.OrderStatus_ORDER_STATUS_FORWARDING,
+		orders.OrderStatus_ORDER_STATUS_PENDING_NEW:
+		status = "Active"
+	case orders.OrderStatus_ORDER_STATUS_PARTIALLY_FILLED:
+		status = "Partial"
+	case orders.OrderStatus_ORDER_STATUS_FILLED:
+		status = "Filled"
+	case orders.OrderStatus_ORDER_STATUS_CANCELED:
+		status = "Cancelled"
+	case orders.OrderStatus_ORDER_STATUS_REJECTED,
+		orders.OrderStatus_ORDER_STATUS_DENIED_BY_BROKER,
+		orders.OrderStatus_ORDER_STATUS_REJECTED_BY_EXCHANGE:
+		status = "Rejected"
+	case orders.OrderStatus_ORDER_STATUS_EXECUTED,
+		orders.OrderStatus_ORDER_STATUS_SL_EXECUTED,
+		orders.OrderStatus_ORDER_STATUS_TP_EXECUTED:
+		status = "Executed"
+	case orders.OrderStatus_ORDER_STATUS_EXPIRED,
+		orders.OrderStatus_ORDER_STATUS_DONE_FOR_DAY:
+		status = "Expired"
+	case orders.OrderStatus_ORDER_STATUS_SUSPENDED,
+		orders.OrderStatus_ORDER_STATUS_DISABLED:
+		status = "Suspended"
+	case orders.OrderStatus_ORDER_STATUS_FAILED:
+		status = "Failed"
+	case orders.OrderStatus_ORDER_STATUS_LINK_WAIT,
+		orders.OrderStatus_ORDER_STATUS_SL_GUARD_TIME,
+		orders.OrderStatus_ORDER_STATUS_SL_FORWARDING,
+		orders.OrderStatus_ORDER_STATUS_TP_GUARD_TIME,
+		orders.OrderStatus_ORDER_STATUS_TP_CORRECTION,
+		orders.OrderStatus_ORDER_STATUS_TP_FORWARDING,
+		orders.OrderStatus_ORDER_STATUS_TP_CORR_GUARD_TIME:
+		status = "Active"
+	}
+
+	order := models.Order{
+		ID:     o.OrderId,
+		Status: status,
+		Side:   side,
+	}
+
+	// Populate executed/remaining quantities from OrderState
+	order.ExecutedQty = decimalOf(o.ExecutedQuantity)
+	order.RemainingQty = decimalOf(o.RemainingQuantity)
+
+	if o.Order != nil {
+		order.Symbol = o.Order.Symbol
+		c.assetMutex.RLock()
+		order.Name = c.instrumentNameCache[o.Order.Symbol]
+		c.assetMutex.RUnlock()
+		switch o.Order.Type {
+		case orders.OrderType_ORDER_TYPE_LIMIT:
+			order.Type = "Limit"
+		case orders.OrderType_ORDER_TYPE_MARKET:
+			order.Type = "Market"
+		case orders.OrderType_ORDER_TYPE_STOP:
+			order.Type = "Stop"
+		case orders.OrderType_ORDER_TYPE_STOP_LIMIT:
+			order.Type = "Stop-Limit"
+		default:
+			order.Type = o.Order.Type.String()
+			order.Type = strings.TrimPrefix(order.Type, "ORDER_TYPE_")
+		}
+		order.Quantity = decimalOf(o.Order.Quantity)
+
+		// Populate separate price fields
+		order.LimitPrice = decimalOf(o.Order.LimitPrice)
+		order.StopPrice = decimalOf(o.Order.StopPrice)
+
+		// Show the most relevant price based on order type
+		switch o.Order.Type {
+		case orders.OrderType_ORDER_TYPE_STOP, orders.OrderType_ORDER_TYPE_STOP_LIMIT:
+			order.Price = decimalOf(o.Order.StopPrice)
+		case orders.OrderType_ORDER_TYPE_LIMIT:
+			order.Price = decimalOf(o.Order.LimitPrice)
+		default:
+			order.Price = decimalOf(o.Order.LimitPrice)
+		}
+		if !order.Price.Known() {
+			order.Price = models.Decimal{}
 		}
 
-		order := models.Order{
-			ID:     o.OrderId,
-			Status: status,
-			Side:   side,
+		// Stop condition
+		switch o.Order.StopCondition {
+		case orders.StopCondition_STOP_CONDITION_LAST_UP:
+			order.StopCondition = "Last Up"
+		case orders.StopCondition_STOP_CONDITION_LAST_DOWN:
+			order.StopCondition = "Last Down"
 		}
 
-		// Populate executed/remaining quantities from OrderState
-		order.ExecutedQty = formatDecimal(o.ExecutedQuantity)
-		order.RemainingQty = formatDecimal(o.RemainingQuantity)
+		// Validity
+		order.Validity = formatValidBefore(o.Order.ValidBefore)
+	}
 
-		if o.Order != nil {
-			order.Symbol = o.Order.Symbol
+	// Check for SL/TP linked orders
+	if o.SltpOrder != nil {
+		order.Type = "SL/TP"
+		symbol := o.SltpOrder.Symbol
+		if symbol != "" {
+			order.Symbol = symbol
 			c.assetMutex.RLock()
-			order.Name = c.instrumentNameCache[o.Order.Symbol]
-			c.assetMutex.RUnlock()
-			switch o.Order.Type {
-			case orders.OrderType_ORDER_TYPE_LIMIT:
-				order.Type = "Limit"
-			case orders.OrderType_ORDER_TYPE_MARKET:
-				order.Type = "Market"
-			case orders.OrderType_ORDER_TYPE_STOP:
-				order.Type = "Stop"
-			case orders.OrderType_ORDER_TYPE_STOP_LIMIT:
-				order.Type = "Stop-Limit"
-			default:
-				order.Type = o.Order.Type.String()
-				order.Type = strings.TrimPrefix(order.Type, "ORDER_TYPE_")
+			if name := c.instrumentNameCache[symbol]; name != "" {
+				order.Name = name
 			}
-			order.Quantity = formatDecimal(o.Order.Quantity)
-
-			// Populate separate price fields
-			order.LimitPrice = formatDecimal(o.Order.LimitPrice)
-			order.StopPrice = formatDecimal(o.Order.StopPrice)
-
-			// Show the most relevant price based on order type
-			switch o.Order.Type {
-			case orders.OrderType_ORDER_TYPE_STOP, orders.OrderType_ORDER_TYPE_STOP_LIMIT:
-				order.Price = formatDecimal(o.Order.StopPrice)
-			case orders.OrderType_ORDER_TYPE_LIMIT:
-				order.Price = formatDecimal(o.Order.LimitPrice)
-			default:
-				order.Price = formatDecimal(o.Order.LimitPrice)
-			}
-			if order.Price == "0" || order.Price == "" || order.Price == "N/A" {
-				order.Price = "Market"
-			}
-
-			// Stop condition
-			switch o.Order.StopCondition {
-			case orders.StopCondition_STOP_CONDITION_LAST_UP:
-				order.StopCondition = "Last Up"
-			case orders.StopCondition_STOP_CONDITION_LAST_DOWN:
-				order.StopCondition = "Last Down"
-			}
-
-			// Validity
-			order.Validity = formatValidBefore(o.Order.ValidBefore)
+			c.assetMutex.RUnlock()
 		}
 
-		// Check for SL/TP linked orders
-		if o.SltpOrder != nil {
-			order.Type = "SL/TP"
-			symbol := o.SltpOrder.Symbol
-			if symbol != "" {
-				order.Symbol = symbol
-				c.assetMutex.RLock()
-				if name := c.instrumentNameCache[symbol]; name != "" {
-					order.Name = name
-				}
-				c.assetMutex.RUnlock()
-			}
-
-			// Populate SL/TP specific fields
-			order.SLPrice = formatDecimal(o.SltpOrder.SlPrice)
-			order.TPPrice = formatDecimal(o.SltpOrder.TpPrice)
-			order.SLQty = formatDecimal(o.SltpOrder.QuantitySl)
-			order.TPQty = formatDecimal(o.SltpOrder.QuantityTp)
-			order.Validity = formatValidBefore(o.SltpOrder.ValidBefore)
-
-			// Populate side from SL/TP order
-			switch o.SltpOrder.Side {
-			case tradeapiv1.Side_SIDE_BUY:
-				order.Side = "Buy"
-			case tradeapiv1.Side_SIDE_SELL:
-				order.Side = "Sell"
-			}
+		// Populate SL/TP specific fields
+		order.SLPrice = decimalOf(o.SltpOrder.SlPrice)
+		order.TPPrice = decimalOf(o.SltpOrder.TpPrice)
+		order.SLQty = decimalOf(o.SltpOrder.QuantitySl)
+		order.TPQty = decimalOf(o.SltpOrder.QuantityTp)
+		order.Validity = formatValidBefore(o.SltpOrder.ValidBefore)
 
-			// Show SL and TP prices in the combined Price field
-			var priceParts []string
-			if order.SLPrice != "" && order.SLPrice != "N/A" && order.SLPrice != "0" {
-				priceParts = append(priceParts, "SL:"+order.SLPrice)
-			}
-			if order.TPPrice != "" && order.TPPrice != "N/A" && order.TPPrice != "0" {
-				priceParts = append(priceParts, "TP:"+order.TPPrice)
-			}
-			if len(priceParts) > 0 {
-				order.Price = strings.Join(priceParts, " ")
-			}
-		}
-
-		// Pick the best available timestamp:
-		// - TransactAt: when the order was placed on exchange
-		// - WithdrawAt: when the order was cancelled/executed
-		// - AcceptAt: when the order was accepted by broker (fallback for active Stop/SL/TP)
-		if o.TransactAt != nil && o.TransactAt.GetSeconds() != 0 {
-			order.CreationTime = o.TransactAt.AsTime().Local()
-		} else if o.WithdrawAt != nil && o.WithdrawAt.GetSeconds() != 0 {
-			order.CreationTime = o.WithdrawAt.AsTime().Local()
-		} else if o.AcceptAt != nil && o.AcceptAt.GetSeconds() != 0 {
-			order.CreationTime = o.AcceptAt.AsTime().Local()
+		// Populate side from SL/TP order
+		switch o.SltpOrder.Side {
+		case tradeapiv1.Side_SIDE_BUY:
+			order.Side = "Buy"
+		case tradeapiv1.Side_SIDE_SELL:
+			order.Side = "Sell"
 		}
+	}
 
-		activeOrders = append(activeOrders, order)
+	// Pick the best available timestamp:
+	// - TransactAt: when the order was placed on exchange
+	// - WithdrawAt: when the order was cancelled/executed
+	// - AcceptAt: when the order was accepted by broker (fallback for active Stop/SL/TP)
+	if o.TransactAt != nil && o.TransactAt.GetSeconds() != 0 {
+		order.CreationTime = o.TransactAt.AsTime().Local()
+	} else if o.WithdrawAt != nil && o.WithdrawAt.GetSeconds() != 0 {
+		order.CreationTime = o.WithdrawAt.AsTime().Local()
+	} else if o.AcceptAt != nil && o.AcceptAt.GetSeconds() != 0 {
+		order.CreationTime = o.AcceptAt.AsTime().Local()
 	}
-	return activeOrders, nil
+
+	return order
 }
 
 // GetSnapshots returns initial prices for a list of securities
@@ -1115,13 +1289,14 @@ func (c *Client) GetSnapshots(accountID string, symbols []string) (map[string]mo
 		if q == nil {
 			continue
 		}
+		c.record(journal.KindQuote, fullSymbol, 0, q)
 
 		quotes[ticker] = models.Quote{
 			Symbol:    fullSymbol,
-			Last:      formatDecimal(q.Last),
-			LastSize:  formatDecimal(q.LastSize),
-			Volume:    formatDecimal(q.Volume),
-			Close:     formatDecimal(q.Close),
+			Last:      decimalOf(q.Last),
+			LastSize:  decimalOf(q.LastSize),
+			Volume:    decimalOf(q.Volume),
+			Close:     decimalOf(q.Close),
 			Timestamp: q.Timestamp.AsTime().Local(),
 		}
 	}
@@ -1154,16 +1329,17 @@ func (c *Client) GetBars(accountID string, symbol string, timeframe marketdata.T
 	if resp == nil {
 		return nil, nil
 	}
+	c.record(journal.KindBars, fullSymbol, int32(timeframe), resp)
 
 	bars := make([]models.Bar, 0, len(resp.Bars))
 	for _, b := range resp.Bars {
 		bars = append(bars, models.Bar{
 			Timestamp: b.Timestamp.AsTime().Local(),
-			Open:      parseDecimalFloat(b.Open),
-			High:      parseDecimalFloat(b.High),
-			Low:       parseDecimalFloat(b.Low),
-			Close:     parseDecimalFloat(b.Close),
-			Volume:    parseDecimalFloat(b.Volume),
+			Open:      decimalOf(b.Open).Float64(),
+			High:      decimalOf(b.High).Float64(),
+			Low:       decimalOf(b.Low).Float64(),
+			Close:     decimalOf(b.Close).Float64(),
+			Volume:    decimalOf(b.Volume).Float64(),
 		})
 	}
 
@@ -1335,16 +1511,18 @@ func formatValidBefore(vb orders.ValidBefore) string {
 	}
 }
 
-// parseDecimalFloat parses a google Decimal to float64, returns 0 on failure
-func parseDecimalFloat(d *decimal.Decimal) float64 {
-	if d == nil || d.Value == "" {
-		return 0
-	}
-	v, err := strconv.ParseFloat(strings.ReplaceAll(d.Value, ",", "."), 64)
-	if err != nil {
-		return 0
+// decimalOf returns a google decimal value as a models.Decimal, NA when it is missing or
+// not a number
+func decimalOf(d *decimal.Decimal) models.Decimal {
+	if d == nil {
+		return models.NA
 	}
-	return v
+	return models.DecimalOf(d.Value)
+}
+
+// toProtoDecimal returns d as a google decimal value for a request
+func toProtoDecimal(d models.Decimal) *decimal.Decimal {
+	return &decimal.Decimal{Value: d.String()}
 }
 
 // formatDecimal formats a google decimal value
@@ -1354,3 +1532,21 @@ func formatDecimal(d *decimal.Decimal) string {
 	}
 	return d.Value
 }
+
+// moneyDecimal returns a google money amount exactly, without its currency
+func moneyDecimal(m *money.Money) models.Decimal {
+	if m == nil {
+		return models.Decimal{}
+	}
+	units, nanos := m.Units, int64(m.Nanos)
+	sign := ""
+	if units < 0 || nanos < 0 {
+		sign = "-"
+		units, nanos = -units, -nanos
+	}
+	s := sign + strconv.FormatInt(units, 10)
+	if nanos != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%09d", nanos), "0")
+	}
+	return models.DecimalOf(s)
+}
@@ -40,7 +40,7 @@ func TestIntegration_AssetCache_LotSizeFetchOnDemand(t *testing.T) {
 	lotBefore := client.assetLotCache["SBER"]
 	client.assetMutex.RUnlock()
 
-	if lotBefore != 0 {
+	if !lotBefore.IsZero() {
 		t.Fatalf("expected lot size to be 0 before demand fetch, got %v", lotBefore)
 	}
 
@@ -53,7 +53,7 @@ func TestIntegration_AssetCache_LotSizeFetchOnDemand(t *testing.T) {
 	client.assetMutex.RUnlock()
 
 	// After the fetch, lot size should be cached by both ticker and full symbol
-	if lotAfter == 0 && lotFull == 0 {
+	if lotAfter.IsZero() && lotFull.IsZero() {
 		t.Error("expected lot size to be cached after demand fetch")
 	}
 }
@@ -66,11 +66,11 @@ func TestIntegration_GetLotSize_CacheLookup(t *testing.T) {
 
 	// Lookup by ticker
 	lot := client.GetLotSize("SBER")
-	if lot == 0 {
+	if lot.IsZero() {
 		// Try full symbol
 		lot = client.GetLotSize("SBER@TQBR")
 	}
-	if lot != 10 {
+	if lot.String() != "10" {
 		t.Errorf("expected lot size 10, got %v", lot)
 	}
 }
@@ -4,6 +4,7 @@ package api
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"finam-terminal/models"
 
 	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/orders"
 )
 
 // setupTestServer creates a TestServer + Client pair for integration tests.
@@ -184,11 +186,106 @@ func TestIntegration_GetQuotes(t *testing.T) {
 	if sberQuote == nil {
 		t.Fatal("expected SBER@TQBR quote")
 	}
-	if sberQuote.Last == "" || sberQuote.Last == "N/A" {
+	if !sberQuote.Last.Known() {
 		t.Error("expected Last price for SBER")
 	}
 }
 
+func TestIntegration_SubscribeQuotes(t *testing.T) {
+	client, _ := setupTestServer(t)
+
+	got := make(chan models.Quote, 4)
+	client.SetQuoteHandler(func(q models.Quote) {
+		got <- q
+	})
+	client.SubscribeQuotes("ACC001", []string{"SBER"})
+
+	select {
+	case q := <-got:
+		if q.Symbol != "SBER@TQBR" {
+			t.Errorf("expected SBER@TQBR, got %s", q.Symbol)
+		}
+		if !q.Last.Known() {
+			t.Error("expected Last price in streamed quote")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for streamed quote")
+	}
+}
+
+func TestIntegration_SubscribeOrders(t *testing.T) {
+	client, ts := setupTestServer(t)
+
+	ts.Orders.Mu.Lock()
+	ts.Orders.StreamedOrders = map[string][]*orders.OrderState{
+		"ACC001": {{OrderId: "ORD001", Status: orders.OrderStatus_ORDER_STATUS_FILLED}},
+	}
+	ts.Orders.Mu.Unlock()
+
+	snapshots := make(chan []models.Order, 2)
+	updates := make(chan []models.Order, 2)
+	stop := client.SubscribeOrders("ACC001",
+		func(list []models.Order) { snapshots <- list },
+		func(list []models.Order) { updates <- list })
+	defer stop()
+
+	select {
+	case list := <-snapshots:
+		if len(list) == 0 {
+			t.Error("expected orders in snapshot")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for orders snapshot")
+	}
+
+	select {
+	case list := <-updates:
+		if len(list) != 1 || list[0].ID != "ORD001" || list[0].Status != "Filled" {
+			t.Errorf("unexpected order update: %+v", list)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for order update")
+	}
+}
+
+func TestIntegration_GetOrderBook(t *testing.T) {
+	client, _ := setupTestServer(t)
+
+	book, err := client.GetOrderBook("ACC001", "SBER")
+	if err != nil {
+		t.Fatalf("GetOrderBook error: %v", err)
+	}
+
+	if len(book.Bids) != 3 || len(book.Asks) != 3 {
+		t.Fatalf("expected 3 bids and 3 asks, got %d/%d", len(book.Bids), len(book.Asks))
+	}
+	if !book.Bids[0].Price.Equal(models.DecimalOf("284.90")) {
+		t.Errorf("expected best bid 284.90, got %v", book.Bids[0].Price)
+	}
+	if !book.Asks[0].Price.Equal(models.DecimalOf("285.10")) {
+		t.Errorf("expected best ask 285.10, got %v", book.Asks[0].Price)
+	}
+}
+
+func TestIntegration_GetLatestTrades(t *testing.T) {
+	client, _ := setupTestServer(t)
+
+	trades, err := client.GetLatestTrades("ACC001", "SBER")
+	if err != nil {
+		t.Fatalf("GetLatestTrades error: %v", err)
+	}
+
+	if len(trades) != 2 {
+		t.Fatalf("expected 2 trades, got %d", len(trades))
+	}
+	if trades[0].Side != "Buy" || trades[1].Side != "Sell" {
+		t.Errorf("expected Buy then Sell, got %s/%s", trades[0].Side, trades[1].Side)
+	}
+	if !trades[1].Size.Equal(models.DecimalFromInt(500)) {
+		t.Errorf("expected size 500, got %v", trades[1].Size)
+	}
+}
+
 func TestIntegration_GetSnapshots(t *testing.T) {
 	client, _ := setupTestServer(t)
 
@@ -337,7 +434,7 @@ func TestIntegration_GetActiveOrders(t *testing.T) {
 func TestIntegration_PlaceOrder_Market(t *testing.T) {
 	client, ts := setupTestServer(t)
 
-	orderID, err := client.PlaceOrder("ACC001", "SBER", "buy", 5, nil)
+	orderID, err := client.PlaceOrder("ACC001", "SBER", "buy", models.DecimalFromInt(5), nil)
 	if err != nil {
 		t.Fatalf("PlaceOrder error: %v", err)
 	}
@@ -361,9 +458,9 @@ func TestIntegration_PlaceOrder_Market(t *testing.T) {
 func TestIntegration_PlaceOrder_Limit(t *testing.T) {
 	client, ts := setupTestServer(t)
 
-	orderID, err := client.PlaceOrder("ACC001", "SBER", "buy", 3, &models.OrderParams{
+	orderID, err := client.PlaceOrder("ACC001", "SBER", "buy", models.DecimalFromInt(3), &models.OrderParams{
 		OrderType:  models.OrderTypeLimit,
-		LimitPrice: 280.50,
+		LimitPrice: models.DecimalOf("280.50"),
 	})
 	if err != nil {
 		t.Fatalf("PlaceOrder error: %v", err)
@@ -383,9 +480,9 @@ func TestIntegration_PlaceOrder_Limit(t *testing.T) {
 func TestIntegration_PlaceOrder_Stop(t *testing.T) {
 	client, ts := setupTestServer(t)
 
-	_, err := client.PlaceOrder("ACC001", "SBER", "sell", 2, &models.OrderParams{
+	_, err := client.PlaceOrder("ACC001", "SBER", "sell", models.DecimalFromInt(2), &models.OrderParams{
 		OrderType: models.OrderTypeStop,
-		StopPrice: 275.00,
+		StopPrice: models.DecimalOf("275.00"),
 	})
 	if err != nil {
 		t.Fatalf("PlaceOrder error: %v", err)
@@ -406,7 +503,7 @@ func TestIntegration_PlaceOrder_Stop(t *testing.T) {
 func TestIntegration_PlaceSLTPOrder(t *testing.T) {
 	client, ts := setupTestServer(t)
 
-	orderID, err := client.PlaceSLTPOrder("ACC001", "SBER", "sell", 5, 270.0, 5, 300.0)
+	orderID, err := client.PlaceSLTPOrder("ACC001", "SBER", "sell", models.DecimalFromInt(5), models.DecimalOf("270.0"), models.DecimalFromInt(5), models.DecimalOf("300.0"))
 	if err != nil {
 		t.Fatalf("PlaceSLTPOrder error: %v", err)
 	}
@@ -450,7 +547,7 @@ func TestIntegration_ClosePosition(t *testing.T) {
 	client, _ := setupTestServer(t)
 
 	// Positive quantity means long position -> close by selling
-	orderID, err := client.ClosePosition("ACC001", "SBER", "100", 5)
+	orderID, err := client.ClosePosition("ACC001", "SBER", models.DecimalOf("100"), models.DecimalFromInt(5))
 	if err != nil {
 		t.Fatalf("ClosePosition error: %v", err)
 	}
@@ -459,3 +556,92 @@ func TestIntegration_ClosePosition(t *testing.T) {
 	}
 }
 
+
+// --- Paper trading ---
+
+func TestIntegration_PaperTrading(t *testing.T) {
+	sim := testserver.NewPaperSimulator(100000)
+	ts := testserver.NewPaperServer(sim)
+	ts.Start()
+
+	conn, err := ts.Dial(context.Background())
+	if err != nil {
+		t.Fatalf("failed to dial paper server: %v", err)
+	}
+	client, err := newClientFromConn(conn, testserver.PaperToken)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = client.Close()
+		ts.Stop()
+	})
+
+	accounts, err := client.GetAccounts()
+	if err != nil || len(accounts) != 1 || accounts[0].ID != testserver.PaperAccountID {
+		t.Fatalf("expected the paper account, got %+v, %v", accounts, err)
+	}
+
+	// 2 lots of 10 shares at the 285.01 ask
+	if _, err := client.PlaceOrder(testserver.PaperAccountID, "SBER", "Buy", models.DecimalFromInt(2), nil); err != nil {
+		t.Fatalf("PlaceOrder error: %v", err)
+	}
+
+	_, positions, err := client.GetAccountDetails(testserver.PaperAccountID)
+	if err != nil {
+		t.Fatalf("GetAccountDetails error: %v", err)
+	}
+	if len(positions) != 1 || positions[0].Symbol != "SBER@TQBR" || positions[0].Quantity.String() != "20" {
+		t.Fatalf("expected 20 SBER shares, got %+v", positions)
+	}
+
+	trades, err := client.GetTradeHistory(testserver.PaperAccountID)
+	if err != nil || len(trades) != 1 || trades[0].Price.String() != "285.01" {
+		t.Fatalf("expected one fill at the ask, got %+v, %v", trades, err)
+	}
+}
+
+func TestIntegration_RecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.ftj")
+
+	sim := testserver.NewPaperSimulator(100000)
+	ts := testserver.NewPaperServer(sim)
+	ts.Start()
+	conn, err := ts.Dial(context.Background())
+	if err != nil {
+		t.Fatalf("failed to dial paper server: %v", err)
+	}
+	client, err := newClientFromConn(conn, testserver.PaperToken)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if err := client.StartRecording(path); err != nil {
+		t.Fatalf("StartRecording error: %v", err)
+	}
+	if _, err := client.GetQuotes(testserver.PaperAccountID, []string{"SBER"}); err != nil {
+		t.Fatalf("GetQuotes error: %v", err)
+	}
+	if _, err := client.GetOrderBook(testserver.PaperAccountID, "SBER"); err != nil {
+		t.Fatalf("GetOrderBook error: %v", err)
+	}
+	_ = client.Close()
+	ts.Stop()
+
+	replay, err := testserver.LoadReplay(path)
+	if err != nil {
+		t.Fatalf("LoadReplay error: %v", err)
+	}
+	if replay.Len() != 2 {
+		t.Fatalf("expected a quote and an order book record, got %d", replay.Len())
+	}
+	if err := replay.Play(context.Background(), 0); err != nil {
+		t.Fatalf("Play error: %v", err)
+	}
+	if q := replay.Quote("SBER@TQBR"); decimalOf(q.GetLast()).String() != "285" {
+		t.Errorf("expected the recorded SBER quote, got %v", q)
+	}
+	if book := replay.OrderBook("SBER@TQBR"); len(book.GetRows()) == 0 {
+		t.Error("expected the recorded order book")
+	}
+}
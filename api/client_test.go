@@ -16,6 +16,7 @@ import (
 	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/orders"
 	"google.golang.org/genproto/googleapis/type/decimal"
 	"google.golang.org/genproto/googleapis/type/interval"
+	"google.golang.org/genproto/googleapis/type/money"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -25,6 +26,10 @@ type mockMarketDataServiceClient struct {
 	marketdata.MarketDataServiceClient
 	LastQuoteFunc func(ctx context.Context, in *marketdata.QuoteRequest, opts ...grpc.CallOption) (*marketdata.QuoteResponse, error)
 	BarsFunc      func(ctx context.Context, in *marketdata.BarsRequest, opts ...grpc.CallOption) (*marketdata.BarsResponse, error)
+
+	SubscribeQuoteFunc func(ctx context.Context, in *marketdata.SubscribeQuoteRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[marketdata.SubscribeQuoteResponse], error)
+	OrderBookFunc      func(ctx context.Context, in *marketdata.OrderBookRequest, opts ...grpc.CallOption) (*marketdata.OrderBookResponse, error)
+	LatestTradesFunc   func(ctx context.Context, in *marketdata.LatestTradesRequest, opts ...grpc.CallOption) (*marketdata.LatestTradesResponse, error)
 }
 
 func (m *mockMarketDataServiceClient) LastQuote(ctx context.Context, in *marketdata.QuoteRequest, opts ...grpc.CallOption) (*marketdata.QuoteResponse, error) {
@@ -35,6 +40,18 @@ func (m *mockMarketDataServiceClient) Bars(ctx context.Context, in *marketdata.B
 	return m.BarsFunc(ctx, in, opts...)
 }
 
+func (m *mockMarketDataServiceClient) SubscribeQuote(ctx context.Context, in *marketdata.SubscribeQuoteRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[marketdata.SubscribeQuoteResponse], error) {
+	return m.SubscribeQuoteFunc(ctx, in, opts...)
+}
+
+func (m *mockMarketDataServiceClient) OrderBook(ctx context.Context, in *marketdata.OrderBookRequest, opts ...grpc.CallOption) (*marketdata.OrderBookResponse, error) {
+	return m.OrderBookFunc(ctx, in, opts...)
+}
+
+func (m *mockMarketDataServiceClient) LatestTrades(ctx context.Context, in *marketdata.LatestTradesRequest, opts ...grpc.CallOption) (*marketdata.LatestTradesResponse, error) {
+	return m.LatestTradesFunc(ctx, in, opts...)
+}
+
 // mockAssetsServiceClient is a manual mock for assets.AssetsServiceClient
 type mockAssetsServiceClient struct {
 	assets.AssetsServiceClient
@@ -63,8 +80,9 @@ func (m *mockAssetsServiceClient) Schedule(ctx context.Context, in *assets.Sched
 // mockAccountsServiceClient is a manual mock for accounts.AccountsServiceClient
 type mockAccountsServiceClient struct {
 	accounts.AccountsServiceClient
-	GetAccountFunc func(ctx context.Context, in *accounts.GetAccountRequest, opts ...grpc.CallOption) (*accounts.GetAccountResponse, error)
-	TradesFunc     func(ctx context.Context, in *accounts.TradesRequest, opts ...grpc.CallOption) (*accounts.TradesResponse, error)
+	GetAccountFunc   func(ctx context.Context, in *accounts.GetAccountRequest, opts ...grpc.CallOption) (*accounts.GetAccountResponse, error)
+	TradesFunc       func(ctx context.Context, in *accounts.TradesRequest, opts ...grpc.CallOption) (*accounts.TradesResponse, error)
+	TransactionsFunc func(ctx context.Context, in *accounts.TransactionsRequest, opts ...grpc.CallOption) (*accounts.TransactionsResponse, error)
 }
 
 func (m *mockAccountsServiceClient) GetAccount(ctx context.Context, in *accounts.GetAccountRequest, opts ...grpc.CallOption) (*accounts.GetAccountResponse, error) {
@@ -75,6 +93,10 @@ func (m *mockAccountsServiceClient) Trades(ctx context.Context, in *accounts.Tra
 	return m.TradesFunc(ctx, in, opts...)
 }
 
+func (m *mockAccountsServiceClient) Transactions(ctx context.Context, in *accounts.TransactionsRequest, opts ...grpc.CallOption) (*accounts.TransactionsResponse, error) {
+	return m.TransactionsFunc(ctx, in, opts...)
+}
+
 // mockAuthServiceClient is a manual mock for auth.AuthServiceClient
 type mockAuthServiceClient struct {
 	auth.AuthServiceClient
@@ -97,6 +119,9 @@ type mockOrdersServiceClient struct {
 	PlaceSLTPOrderFunc func(ctx context.Context, in *orders.SLTPOrder, opts ...grpc.CallOption) (*orders.OrderState, error)
 	GetOrdersFunc      func(ctx context.Context, in *orders.OrdersRequest, opts ...grpc.CallOption) (*orders.OrdersResponse, error)
 	CancelOrderFunc    func(ctx context.Context, in *orders.CancelOrderRequest, opts ...grpc.CallOption) (*orders.OrderState, error)
+
+	SubscribeOrdersFunc func(ctx context.Context, in *orders.SubscribeOrdersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[orders.SubscribeOrdersResponse], error)
+	SubscribeTradesFunc func(ctx context.Context, in *orders.SubscribeTradesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[orders.SubscribeTradesResponse], error)
 }
 
 func (m *mockOrdersServiceClient) PlaceOrder(ctx context.Context, in *orders.Order, opts ...grpc.CallOption) (*orders.OrderState, error) {
@@ -115,6 +140,14 @@ func (m *mockOrdersServiceClient) CancelOrder(ctx context.Context, in *orders.Ca
 	return m.CancelOrderFunc(ctx, in, opts...)
 }
 
+func (m *mockOrdersServiceClient) SubscribeOrders(ctx context.Context, in *orders.SubscribeOrdersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[orders.SubscribeOrdersResponse], error) {
+	return m.SubscribeOrdersFunc(ctx, in, opts...)
+}
+
+func (m *mockOrdersServiceClient) SubscribeTrades(ctx context.Context, in *orders.SubscribeTradesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[orders.SubscribeTradesResponse], error) {
+	return m.SubscribeTradesFunc(ctx, in, opts...)
+}
+
 func TestPlaceOrder_Success(t *testing.T) {
 	mockOrders := &mockOrdersServiceClient{
 		PlaceOrderFunc: func(ctx context.Context, in *orders.Order, opts ...grpc.CallOption) (*orders.OrderState, error) {
@@ -142,12 +175,12 @@ func TestPlaceOrder_Success(t *testing.T) {
 		assetMicCache: map[string]string{
 			"SBER": "SBER@TQBR",
 		},
-		assetLotCache: map[string]float64{
-			"SBER": 1,
+		assetLotCache: map[string]models.Decimal{
+			"SBER": models.DecimalFromInt(1),
 		},
 	}
 
-	txID, err := client.PlaceOrder("test-acc", "SBER", "Buy", 10, nil)
+	txID, err := client.PlaceOrder("test-acc", "SBER", "Buy", models.DecimalFromInt(10), nil)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -169,17 +202,49 @@ func TestPlaceOrder_Error(t *testing.T) {
 		assetMicCache: map[string]string{
 			"SBER": "SBER@TQBR",
 		},
-		assetLotCache: map[string]float64{
-			"SBER": 1,
+		assetLotCache: map[string]models.Decimal{
+			"SBER": models.DecimalFromInt(1),
 		},
 	}
 
-	_, err := client.PlaceOrder("test-acc", "SBER", "Buy", 10, nil)
+	_, err := client.PlaceOrder("test-acc", "SBER", "Buy", models.DecimalFromInt(10), nil)
 	if err == nil {
 		t.Fatal("Expected error, got nil")
 	}
 }
 
+func TestBuildOrder_DoesNotSend(t *testing.T) {
+	client := &Client{
+		ordersClient: &mockOrdersServiceClient{
+			PlaceOrderFunc: func(ctx context.Context, in *orders.Order, opts ...grpc.CallOption) (*orders.OrderState, error) {
+				t.Fatal("BuildOrder must not call PlaceOrder")
+				return nil, nil
+			},
+		},
+		assetMicCache: map[string]string{
+			"SBER": "SBER@TQBR",
+		},
+		assetLotCache: map[string]models.Decimal{
+			"SBER": models.DecimalFromInt(10),
+		},
+	}
+
+	req, err := client.BuildOrder("test-acc", "SBER", "Sell", models.DecimalFromInt(2), &models.OrderParams{OrderType: models.OrderTypeStop, StopPrice: models.DecimalOf("270.5")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if req.Symbol != "SBER@TQBR" || req.Quantity.Value != "20" {
+		t.Errorf("Expected 20 shares of SBER@TQBR, got %s of %s", req.Quantity.Value, req.Symbol)
+	}
+	if req.StopPrice.Value != "270.5" || req.StopCondition != orders.StopCondition_STOP_CONDITION_LAST_DOWN {
+		t.Errorf("Unexpected stop parameters: %s %v", req.StopPrice.Value, req.StopCondition)
+	}
+
+	if _, err := client.BuildOrder("test-acc", "SBER", "Hold", models.DecimalFromInt(1), nil); err == nil {
+		t.Error("Expected error for invalid direction")
+	}
+}
+
 func TestClosePosition_Success(t *testing.T) {
 	mockOrders := &mockOrdersServiceClient{
 		PlaceOrderFunc: func(ctx context.Context, in *orders.Order, opts ...grpc.CallOption) (*orders.OrderState, error) {
@@ -199,13 +264,13 @@ func TestClosePosition_Success(t *testing.T) {
 		assetMicCache: map[string]string{
 			"SBER": "SBER@TQBR",
 		},
-		assetLotCache: map[string]float64{
-			"SBER": 1,
+		assetLotCache: map[string]models.Decimal{
+			"SBER": models.DecimalFromInt(1),
 		},
 	}
 
 	// Long position
-	id, err := client.ClosePosition("test-acc", "SBER", "10", 5)
+	id, err := client.ClosePosition("test-acc", "SBER", models.DecimalOf("10"), models.DecimalFromInt(5))
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -236,6 +301,11 @@ func TestGetAccountDetails(t *testing.T) {
 						DailyPnl:      &decimal.Decimal{Value: "100"},
 						UnrealizedPnl: &decimal.Decimal{Value: "100"},
 					},
+					{
+						Symbol:   "SBER",
+						Quantity: &decimal.Decimal{Value: "0"},
+						DailyPnl: &decimal.Decimal{Value: "-40"},
+					},
 				},
 			}, nil
 		},
@@ -245,9 +315,11 @@ func TestGetAccountDetails(t *testing.T) {
 		accountsClient: mockAccounts,
 		assetMicCache: map[string]string{
 			"GAZP": "GAZP@TQBR",
+			"SBER": "SBER@TQBR",
 		},
-		assetLotCache: map[string]float64{
-			"GAZP": 1,
+		assetLotCache: map[string]models.Decimal{
+			"GAZP": models.DecimalFromInt(1),
+			"SBER": models.DecimalFromInt(10),
 		},
 		instrumentNameCache: map[string]string{
 			"GAZP":      "Газпром",
@@ -269,6 +341,9 @@ func TestGetAccountDetails(t *testing.T) {
 	if account.UnrealizedPnL != "10.5" {
 		t.Errorf("Expected UnrealizedPnL 10.5, got %s", account.UnrealizedPnL)
 	}
+	if !account.DailyPnL.Equal(models.DecimalFromInt(60)) {
+		t.Errorf("Expected DailyPnL 60 with the closed position, got %s", account.DailyPnL)
+	}
 	if len(positions) != 1 {
 		t.Errorf("Expected 1 position, got %d", len(positions))
 	}
@@ -394,8 +469,8 @@ func TestGetSnapshots(t *testing.T) {
 		assetMicCache: map[string]string{
 			"SBER": "SBER@TQBR",
 		},
-		assetLotCache: map[string]float64{
-			"SBER": 1,
+		assetLotCache: map[string]models.Decimal{
+			"SBER": models.DecimalFromInt(1),
 		},
 	}
 
@@ -414,7 +489,7 @@ func TestGetSnapshots(t *testing.T) {
 		t.Errorf("Expected quote for SBER")
 	}
 
-	if q.Last != "250.50" {
+	if q.Last.String() != "250.50" {
 		t.Errorf("Expected Last 250.50, got %s", q.Last)
 	}
 }
@@ -461,7 +536,7 @@ func TestGetTradeHistory(t *testing.T) {
 	if trades[0].Side != "Buy" {
 		t.Errorf("Expected Side Buy, got %s", trades[0].Side)
 	}
-	if trades[0].Total != "2500.00" {
+	if trades[0].Total.String() != "2500.00" {
 		t.Errorf("Expected Total 2500.00, got %s", trades[0].Total)
 	}
 	if trades[0].Name != "Сбербанк" {
@@ -516,6 +591,88 @@ func TestGetTradeHistory_LocalTimezone(t *testing.T) {
 	}
 }
 
+func TestGetTradeHistoryRange_WalksBackInChunks(t *testing.T) {
+	to := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-75 * 24 * time.Hour)
+	var intervals [][2]time.Time
+
+	mockAccounts := &mockAccountsServiceClient{
+		TradesFunc: func(ctx context.Context, in *accounts.TradesRequest, opts ...grpc.CallOption) (*accounts.TradesResponse, error) {
+			start, end := in.Interval.StartTime.AsTime(), in.Interval.EndTime.AsTime()
+			intervals = append(intervals, [2]time.Time{start, end})
+			// T2 sits on the boundary between the first two chunks and comes back twice
+			boundary := to.Add(-TradeHistoryChunk)
+			resp := &accounts.TradesResponse{Trades: []*tradeapiv1.AccountTrade{
+				{TradeId: "T2", Symbol: "SBER", Price: &decimal.Decimal{Value: "100"}, Size: &decimal.Decimal{Value: "1"},
+					Side: tradeapiv1.Side_SIDE_BUY, Timestamp: timestamppb.New(boundary)},
+			}}
+			if start.Equal(from) {
+				resp.Trades = append(resp.Trades, &tradeapiv1.AccountTrade{TradeId: "T1", Symbol: "SBER",
+					Price: &decimal.Decimal{Value: "90"}, Size: &decimal.Decimal{Value: "1"},
+					Side: tradeapiv1.Side_SIDE_BUY, Timestamp: timestamppb.New(from.Add(time.Hour))})
+			}
+			return resp, nil
+		},
+	}
+	client := &Client{accountsClient: mockAccounts, instrumentNameCache: map[string]string{}}
+
+	trades, err := client.GetTradeHistoryRange("acc1", from, to)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(intervals) != 3 {
+		t.Fatalf("Expected 75 days in three requests, got %v", intervals)
+	}
+	if !intervals[0][1].Equal(to) || !intervals[2][0].Equal(from) {
+		t.Errorf("Expected the requests to walk back from %v to %v, got %v", to, from, intervals)
+	}
+	if len(trades) != 2 || trades[0].ID != "T1" || trades[1].ID != "T2" {
+		t.Errorf("Expected T1 then T2 once, got %+v", trades)
+	}
+}
+
+func TestGetTransactions(t *testing.T) {
+	to := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	from := to.Add(-45 * 24 * time.Hour)
+	requests := 0
+
+	mockAccounts := &mockAccountsServiceClient{
+		TransactionsFunc: func(ctx context.Context, in *accounts.TransactionsRequest, opts ...grpc.CallOption) (*accounts.TransactionsResponse, error) {
+			requests++
+			if in.AccountId != "acc1" {
+				t.Errorf("Expected account acc1, got %s", in.AccountId)
+			}
+			if !in.Interval.StartTime.AsTime().Equal(from) {
+				return &accounts.TransactionsResponse{}, nil
+			}
+			return &accounts.TransactionsResponse{Transactions: []*accounts.Transaction{
+				{Id: "X2", Category: "Комиссия брокера", Timestamp: timestamppb.New(from.Add(2 * time.Hour)),
+					Change: &money.Money{CurrencyCode: "RUB", Units: -12, Nanos: -500000000}},
+				{Id: "X1", Category: "DIVIDEND", Symbol: "SBER@MISX", Timestamp: timestamppb.New(from.Add(time.Hour)),
+					Change: &money.Money{CurrencyCode: "RUB", Units: 3480}},
+			}}, nil
+		},
+	}
+	client := &Client{accountsClient: mockAccounts}
+
+	txs, err := client.GetTransactions("acc1", from, to)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 45 days in two requests, got %d", requests)
+	}
+	if len(txs) != 2 || txs[0].ID != "X1" {
+		t.Fatalf("Expected two transactions oldest first, got %+v", txs)
+	}
+	if txs[0].Category != models.TxDividend || txs[0].Amount.String() != "3480" || txs[0].Symbol != "SBER@MISX" {
+		t.Errorf("Unexpected dividend %+v", txs[0])
+	}
+	if txs[1].Category != models.TxCommission || txs[1].Amount.String() != "-12.5" || txs[1].Currency != "RUB" {
+		t.Errorf("Unexpected commission %+v", txs[1])
+	}
+}
+
 func TestGetActiveOrders(t *testing.T) {
 	mockOrders := &mockOrdersServiceClient{
 		GetOrdersFunc: func(ctx context.Context, in *orders.OrdersRequest, opts ...grpc.CallOption) (*orders.OrdersResponse, error) {
@@ -621,7 +778,7 @@ func TestGetAccountDetails_LocalTimezone(t *testing.T) {
 	client := &Client{
 		accountsClient:      mockAccounts,
 		assetMicCache:       make(map[string]string),
-		assetLotCache:       make(map[string]float64),
+		assetLotCache:       make(map[string]models.Decimal),
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -708,7 +865,7 @@ func TestLotSizeRetrieval(t *testing.T) {
 	client := &Client{
 		assetsClient:        mockAssets,
 		assetMicCache:       make(map[string]string),
-		assetLotCache:       make(map[string]float64),
+		assetLotCache:       make(map[string]models.Decimal),
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -723,8 +880,8 @@ func TestLotSizeRetrieval(t *testing.T) {
 	lot := client.assetLotCache["SBER"]
 	client.assetMutex.RUnlock()
 
-	if lot != 10 {
-		t.Errorf("Expected cached LotSize 10, got %f", lot)
+	if lot.String() != "10" {
+		t.Errorf("Expected cached LotSize 10, got %s", lot)
 	}
 }
 
@@ -757,7 +914,7 @@ func TestGetAccountDetails_LotSize(t *testing.T) {
 		accountsClient:      mockAccounts,
 		assetsClient:        mockAssets,
 		assetMicCache:       make(map[string]string),
-		assetLotCache:       make(map[string]float64),
+		assetLotCache:       make(map[string]models.Decimal),
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -770,8 +927,8 @@ func TestGetAccountDetails_LotSize(t *testing.T) {
 		t.Fatalf("Expected 1 position, got %d", len(positions))
 	}
 
-	if positions[0].LotSize != 10 {
-		t.Errorf("Expected LotSize 10, got %f", positions[0].LotSize)
+	if positions[0].LotSize.String() != "10" {
+		t.Errorf("Expected LotSize 10, got %s", positions[0].LotSize)
 	}
 }
 
@@ -794,14 +951,14 @@ func TestPlaceOrder_LotMultiplication(t *testing.T) {
 		assetMicCache: map[string]string{
 			"SBER": "SBER@TQBR",
 		},
-		assetLotCache: map[string]float64{
-			"SBER":      10,
-			"SBER@TQBR": 10,
+		assetLotCache: map[string]models.Decimal{
+			"SBER":      models.DecimalFromInt(10),
+			"SBER@TQBR": models.DecimalFromInt(10),
 		},
 	}
 
 	// Place order for 1 lot
-	txID, err := client.PlaceOrder("test-acc", "SBER", "Buy", 1, nil)
+	txID, err := client.PlaceOrder("test-acc", "SBER", "Buy", models.DecimalFromInt(1), nil)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -813,7 +970,7 @@ func TestPlaceOrder_LotMultiplication(t *testing.T) {
 func TestInstrumentNameCache(t *testing.T) {
 	client := &Client{
 		assetMicCache:       make(map[string]string),
-		assetLotCache:       make(map[string]float64),
+		assetLotCache:       make(map[string]models.Decimal),
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -856,7 +1013,7 @@ func TestLoadAssetCache_PopulatesInstrumentNames(t *testing.T) {
 	client := &Client{
 		assetsClient:        mockAssets,
 		assetMicCache:       make(map[string]string),
-		assetLotCache:       make(map[string]float64),
+		assetLotCache:       make(map[string]models.Decimal),
 		instrumentNameCache: make(map[string]string),
 		securityCache:       make([]models.SecurityInfo, 0),
 	}
@@ -898,7 +1055,7 @@ func TestGetQuotes_LocalTimezone(t *testing.T) {
 	client := &Client{
 		marketDataClient: mockMarketData,
 		assetMicCache:    map[string]string{"SBER": "SBER@TQBR"},
-		assetLotCache:    map[string]float64{"SBER": 1},
+		assetLotCache:    map[string]models.Decimal{"SBER": models.DecimalFromInt(1)},
 	}
 
 	quotes, err := client.GetQuotes("acc1", []string{"SBER"})
@@ -936,7 +1093,7 @@ func TestGetSnapshots_LocalTimezone(t *testing.T) {
 	client := &Client{
 		marketDataClient: mockMarketData,
 		assetMicCache:    map[string]string{"SBER": "SBER@TQBR"},
-		assetLotCache:    map[string]float64{"SBER": 1},
+		assetLotCache:    map[string]models.Decimal{"SBER": models.DecimalFromInt(1)},
 	}
 
 	quotes, err := client.GetSnapshots("acc1", []string{"SBER"})
@@ -972,13 +1129,13 @@ func TestPlaceOrder_LotMultiplication_MultipleLots(t *testing.T) {
 		assetMicCache: map[string]string{
 			"GAZP": "GAZP@TQBR",
 		},
-		assetLotCache: map[string]float64{
-			"GAZP":      10,
-			"GAZP@TQBR": 10,
+		assetLotCache: map[string]models.Decimal{
+			"GAZP":      models.DecimalFromInt(10),
+			"GAZP@TQBR": models.DecimalFromInt(10),
 		},
 	}
 
-	txID, err := client.PlaceOrder("test-acc", "GAZP", "Sell", 5, nil)
+	txID, err := client.PlaceOrder("test-acc", "GAZP", "Sell", models.DecimalFromInt(5), nil)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -1063,31 +1220,31 @@ func TestGetActiveOrders_ExtendedFields(t *testing.T) {
 	if stop.StopCondition != "Last Down" {
 		t.Errorf("Expected StopCondition 'Last Down', got '%s'", stop.StopCondition)
 	}
-	if stop.StopPrice != "240.00" {
+	if stop.StopPrice.String() != "240.00" {
 		t.Errorf("Expected StopPrice '240.00', got '%s'", stop.StopPrice)
 	}
 	if stop.Validity != "GTC" {
 		t.Errorf("Expected Validity 'GTC', got '%s'", stop.Validity)
 	}
-	if stop.ExecutedQty != "0" {
+	if stop.ExecutedQty.String() != "0" {
 		t.Errorf("Expected ExecutedQty '0', got '%s'", stop.ExecutedQty)
 	}
-	if stop.RemainingQty != "100" {
+	if stop.RemainingQty.String() != "100" {
 		t.Errorf("Expected RemainingQty '100', got '%s'", stop.RemainingQty)
 	}
 
 	// Limit order checks
 	limit := activeOrders[1]
-	if limit.LimitPrice != "150.00" {
+	if limit.LimitPrice.String() != "150.00" {
 		t.Errorf("Expected LimitPrice '150.00', got '%s'", limit.LimitPrice)
 	}
 	if limit.Validity != "Day" {
 		t.Errorf("Expected Validity 'Day', got '%s'", limit.Validity)
 	}
-	if limit.ExecutedQty != "50" {
+	if limit.ExecutedQty.String() != "50" {
 		t.Errorf("Expected ExecutedQty '50', got '%s'", limit.ExecutedQty)
 	}
-	if limit.RemainingQty != "150" {
+	if limit.RemainingQty.String() != "150" {
 		t.Errorf("Expected RemainingQty '150', got '%s'", limit.RemainingQty)
 	}
 
@@ -1096,16 +1253,16 @@ func TestGetActiveOrders_ExtendedFields(t *testing.T) {
 	if sltp.Type != "SL/TP" {
 		t.Errorf("Expected Type 'SL/TP', got '%s'", sltp.Type)
 	}
-	if sltp.SLPrice != "170.00" {
+	if sltp.SLPrice.String() != "170.00" {
 		t.Errorf("Expected SLPrice '170.00', got '%s'", sltp.SLPrice)
 	}
-	if sltp.TPPrice != "200.00" {
+	if sltp.TPPrice.String() != "200.00" {
 		t.Errorf("Expected TPPrice '200.00', got '%s'", sltp.TPPrice)
 	}
-	if sltp.SLQty != "10" {
+	if sltp.SLQty.String() != "10" {
 		t.Errorf("Expected SLQty '10', got '%s'", sltp.SLQty)
 	}
-	if sltp.TPQty != "10" {
+	if sltp.TPQty.String() != "10" {
 		t.Errorf("Expected TPQty '10', got '%s'", sltp.TPQty)
 	}
 	if sltp.Validity != "GTC" {
@@ -1160,7 +1317,7 @@ func newTestOrderClient(mock *mockOrdersServiceClient) *Client {
 	return &Client{
 		ordersClient:        mock,
 		assetMicCache:       map[string]string{"SBER": "SBER@TQBR"},
-		assetLotCache:       map[string]float64{"SBER": 1},
+		assetLotCache:       map[string]models.Decimal{"SBER": models.DecimalFromInt(1)},
 		instrumentNameCache: make(map[string]string),
 	}
 }
@@ -1174,14 +1331,18 @@ func TestPlaceOrder_LimitOrder(t *testing.T) {
 			if in.LimitPrice == nil || in.LimitPrice.Value != "250.5" {
 				t.Errorf("Expected LimitPrice 250.5, got %v", in.LimitPrice)
 			}
+			if in.ValidBefore != orders.ValidBefore_VALID_BEFORE_END_OF_DAY {
+				t.Errorf("Expected END_OF_DAY, got %v", in.ValidBefore)
+			}
 			return &orders.OrderState{OrderId: "LIM-1"}, nil
 		},
 	}
 	client := newTestOrderClient(mockOrders)
 
-	id, err := client.PlaceOrder("acc1", "SBER", "Buy", 1, &models.OrderParams{
+	id, err := client.PlaceOrder("acc1", "SBER", "Buy", models.DecimalFromInt(1), &models.OrderParams{
 		OrderType:  models.OrderTypeLimit,
-		LimitPrice: 250.5,
+		LimitPrice: models.DecimalOf("250.5"),
+		Validity:   models.ValidityDay,
 	})
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -1212,9 +1373,9 @@ func TestPlaceOrder_StopLossOrder(t *testing.T) {
 	}
 	client := newTestOrderClient(mockOrders)
 
-	id, err := client.PlaceOrder("acc1", "SBER", "Sell", 1, &models.OrderParams{
+	id, err := client.PlaceOrder("acc1", "SBER", "Sell", models.DecimalFromInt(1), &models.OrderParams{
 		OrderType: models.OrderTypeStop,
-		StopPrice: 240,
+		StopPrice: models.DecimalFromInt(240),
 	})
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -1235,9 +1396,9 @@ func TestPlaceOrder_StopLossBuy_UsesLastUp(t *testing.T) {
 	}
 	client := newTestOrderClient(mockOrders)
 
-	_, err := client.PlaceOrder("acc1", "SBER", "Buy", 1, &models.OrderParams{
+	_, err := client.PlaceOrder("acc1", "SBER", "Buy", models.DecimalFromInt(1), &models.OrderParams{
 		OrderType: models.OrderTypeStop,
-		StopPrice: 260,
+		StopPrice: models.DecimalFromInt(260),
 	})
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -1259,15 +1420,63 @@ func TestPlaceOrder_TakeProfitSell_UsesLastUp(t *testing.T) {
 	}
 	client := newTestOrderClient(mockOrders)
 
-	_, err := client.PlaceOrder("acc1", "SBER", "Sell", 1, &models.OrderParams{
+	_, err := client.PlaceOrder("acc1", "SBER", "Sell", models.DecimalFromInt(1), &models.OrderParams{
 		OrderType: models.OrderTypeTakeProfit,
-		StopPrice: 280,
+		StopPrice: models.DecimalFromInt(280),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestPlaceOrder_StopLimitOrder(t *testing.T) {
+	mockOrders := &mockOrdersServiceClient{
+		PlaceOrderFunc: func(ctx context.Context, in *orders.Order, opts ...grpc.CallOption) (*orders.OrderState, error) {
+			if in.Type != orders.OrderType_ORDER_TYPE_STOP_LIMIT {
+				t.Errorf("Expected STOP_LIMIT type, got %v", in.Type)
+			}
+			if in.StopPrice == nil || in.StopPrice.Value != "240" {
+				t.Errorf("Expected StopPrice 240, got %v", in.StopPrice)
+			}
+			if in.LimitPrice == nil || in.LimitPrice.Value != "239.5" {
+				t.Errorf("Expected LimitPrice 239.5, got %v", in.LimitPrice)
+			}
+			if in.StopCondition != orders.StopCondition_STOP_CONDITION_LAST_DOWN {
+				t.Errorf("Expected LAST_DOWN for sell stop-limit, got %v", in.StopCondition)
+			}
+			if in.ValidBefore != orders.ValidBefore_VALID_BEFORE_END_OF_DAY {
+				t.Errorf("Expected END_OF_DAY, got %v", in.ValidBefore)
+			}
+			return &orders.OrderState{OrderId: "SLM-1"}, nil
+		},
+	}
+	client := newTestOrderClient(mockOrders)
+
+	_, err := client.PlaceOrder("acc1", "SBER", "Sell", models.DecimalFromInt(1), &models.OrderParams{
+		OrderType:  models.OrderTypeStopLimit,
+		StopPrice:  models.DecimalFromInt(240),
+		LimitPrice: models.DecimalOf("239.5"),
+		Validity:   models.ValidityDay,
 	})
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 }
 
+func TestValidBefore(t *testing.T) {
+	tests := map[string]orders.ValidBefore{
+		"":                 orders.ValidBefore_VALID_BEFORE_GOOD_TILL_CANCEL,
+		models.ValidityGTC: orders.ValidBefore_VALID_BEFORE_GOOD_TILL_CANCEL,
+		models.ValidityDay: orders.ValidBefore_VALID_BEFORE_END_OF_DAY,
+		models.ValidityGTD: orders.ValidBefore_VALID_BEFORE_GOOD_TILL_CANCEL,
+	}
+	for validity, want := range tests {
+		if got := validBefore(validity); got != want {
+			t.Errorf("validBefore(%q) = %v, want %v", validity, got, want)
+		}
+	}
+}
+
 func TestPlaceSLTPOrder_Success(t *testing.T) {
 	mockOrders := &mockOrdersServiceClient{
 		PlaceSLTPOrderFunc: func(ctx context.Context, in *orders.SLTPOrder, opts ...grpc.CallOption) (*orders.OrderState, error) {
@@ -1297,7 +1506,7 @@ func TestPlaceSLTPOrder_Success(t *testing.T) {
 	}
 	client := newTestOrderClient(mockOrders)
 
-	id, err := client.PlaceSLTPOrder("acc1", "SBER", "Sell", 10, 230, 10, 280)
+	id, err := client.PlaceSLTPOrder("acc1", "SBER", "Sell", models.DecimalFromInt(10), models.DecimalFromInt(230), models.DecimalFromInt(10), models.DecimalFromInt(280))
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -1320,7 +1529,7 @@ func TestPlaceSLTPOrder_OnlySL(t *testing.T) {
 	}
 	client := newTestOrderClient(mockOrders)
 
-	id, err := client.PlaceSLTPOrder("acc1", "SBER", "Sell", 5, 230, 5, 0)
+	id, err := client.PlaceSLTPOrder("acc1", "SBER", "Sell", models.DecimalFromInt(5), models.DecimalFromInt(230), models.DecimalFromInt(5), models.DecimalFromInt(0))
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -1343,9 +1552,9 @@ func TestPlaceSLTPOrder_LotMultiplication(t *testing.T) {
 		},
 	}
 	client := newTestOrderClient(mockOrders)
-	client.assetLotCache["SBER"] = 10 // Override lot size for this test
+	client.assetLotCache["SBER"] = models.DecimalFromInt(10) // Override lot size for this test
 
-	_, err := client.PlaceSLTPOrder("acc1", "SBER", "Buy", 2, 230, 2, 280)
+	_, err := client.PlaceSLTPOrder("acc1", "SBER", "Buy", models.DecimalFromInt(2), models.DecimalFromInt(230), models.DecimalFromInt(2), models.DecimalFromInt(280))
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -1359,7 +1568,7 @@ func TestPlaceSLTPOrder_Error(t *testing.T) {
 	}
 	client := newTestOrderClient(mockOrders)
 
-	_, err := client.PlaceSLTPOrder("acc1", "SBER", "Sell", 10, 230, 10, 280)
+	_, err := client.PlaceSLTPOrder("acc1", "SBER", "Sell", models.DecimalFromInt(10), models.DecimalFromInt(230), models.DecimalFromInt(10), models.DecimalFromInt(280))
 	if err == nil {
 		t.Fatal("Expected error, got nil")
 	}
@@ -1397,7 +1606,7 @@ func TestGetBars(t *testing.T) {
 	client := &Client{
 		marketDataClient:    mockMD,
 		assetMicCache:       map[string]string{"SBER": "SBER@TQBR"},
-		assetLotCache:       map[string]float64{"SBER": 10},
+		assetLotCache:       map[string]models.Decimal{"SBER": models.DecimalFromInt(10)},
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -1441,7 +1650,7 @@ func TestGetAssetInfo(t *testing.T) {
 	client := &Client{
 		assetsClient:        mockAssets,
 		assetMicCache:       map[string]string{"SBER": "SBER@TQBR"},
-		assetLotCache:       map[string]float64{"SBER": 10},
+		assetLotCache:       map[string]models.Decimal{"SBER": models.DecimalFromInt(10)},
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -1483,7 +1692,7 @@ func TestGetAssetParams(t *testing.T) {
 	client := &Client{
 		assetsClient:        mockAssets,
 		assetMicCache:       map[string]string{"SBER": "SBER@TQBR"},
-		assetLotCache:       map[string]float64{"SBER": 10},
+		assetLotCache:       map[string]models.Decimal{"SBER": models.DecimalFromInt(10)},
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -1526,7 +1735,7 @@ func TestGetSchedule(t *testing.T) {
 	client := &Client{
 		assetsClient:        mockAssets,
 		assetMicCache:       make(map[string]string),
-		assetLotCache:       make(map[string]float64),
+		assetLotCache:       make(map[string]models.Decimal),
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -1551,7 +1760,7 @@ func TestGetSchedule(t *testing.T) {
 func TestGetFullSymbol_CacheHit(t *testing.T) {
 	client := &Client{
 		assetMicCache: map[string]string{"SBER": "SBER@TQBR"},
-		assetLotCache: map[string]float64{"SBER": 10, "SBER@TQBR": 10},
+		assetLotCache: map[string]models.Decimal{"SBER": models.DecimalFromInt(10), "SBER@TQBR": models.DecimalFromInt(10)},
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -1575,7 +1784,7 @@ func TestGetFullSymbol_AlreadyFullSymbol(t *testing.T) {
 	client := &Client{
 		assetsClient:        mockAssets,
 		assetMicCache:       map[string]string{},
-		assetLotCache:       map[string]float64{},
+		assetLotCache:       map[string]models.Decimal{},
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -1600,7 +1809,7 @@ func TestGetFullSymbol_CacheMissFallback(t *testing.T) {
 	client := &Client{
 		assetsClient:        mockAssets,
 		assetMicCache:       map[string]string{}, // empty cache
-		assetLotCache:       map[string]float64{},
+		assetLotCache:       map[string]models.Decimal{},
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -1618,7 +1827,7 @@ func TestGetFullSymbol_CacheMissFallback(t *testing.T) {
 	if cached != "YNDX@TQBR" {
 		t.Errorf("expected cached YNDX@TQBR, got %s", cached)
 	}
-	if lot != 1 {
+	if lot.String() != "1" {
 		t.Errorf("expected lot size 1, got %v", lot)
 	}
 }
@@ -10,6 +10,8 @@ import (
 
 	"time"
 
+	"finam-terminal/models"
+
 	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/accounts"
 	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/assets"
 	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/auth"
@@ -169,7 +171,7 @@ func TestLoadAssetCache_LogsGRPCError(t *testing.T) {
 	client := &Client{
 		assetsClient:        mockAssets,
 		assetMicCache:       make(map[string]string),
-		assetLotCache:       make(map[string]float64),
+		assetLotCache:       make(map[string]models.Decimal),
 		instrumentNameCache: make(map[string]string),
 		securityCache:       nil,
 	}
@@ -377,7 +379,7 @@ func TestGetQuotes_LogsGRPCError(t *testing.T) {
 	client := &Client{
 		marketDataClient: mockMarketData,
 		assetMicCache:    map[string]string{"SBER": "SBER@TQBR"},
-		assetLotCache:    map[string]float64{"SBER": 1},
+		assetLotCache:    map[string]models.Decimal{"SBER": models.DecimalFromInt(1)},
 	}
 
 	_, _ = client.GetQuotes("acc1", []string{"SBER"})
@@ -409,7 +411,7 @@ func TestGetSnapshots_LogsGRPCError(t *testing.T) {
 	client := &Client{
 		marketDataClient: mockMarketData,
 		assetMicCache:    map[string]string{"GAZP": "GAZP@TQBR"},
-		assetLotCache:    map[string]float64{"GAZP": 1},
+		assetLotCache:    map[string]models.Decimal{"GAZP": models.DecimalFromInt(1)},
 	}
 
 	_, _ = client.GetSnapshots("acc1", []string{"GAZP"})
@@ -440,7 +442,7 @@ func TestGetBars_LogsGRPCError(t *testing.T) {
 			},
 		},
 		assetMicCache: map[string]string{"SBER": "SBER@TQBR"},
-		assetLotCache: map[string]float64{"SBER": 1},
+		assetLotCache: map[string]models.Decimal{"SBER": models.DecimalFromInt(1)},
 	}
 
 	now := time.Now()
@@ -492,7 +494,7 @@ func TestGetFullSymbol_LogsGRPCError(t *testing.T) {
 	client := &Client{
 		assetsClient:        mockAssets,
 		assetMicCache:       make(map[string]string),
-		assetLotCache:       make(map[string]float64),
+		assetLotCache:       make(map[string]models.Decimal),
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -526,7 +528,7 @@ func TestFetchLotSize_LogsGRPCError(t *testing.T) {
 	client := &Client{
 		assetsClient:        mockAssets,
 		assetMicCache:       make(map[string]string),
-		assetLotCache:       make(map[string]float64),
+		assetLotCache:       make(map[string]models.Decimal),
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -560,7 +562,7 @@ func TestGetAssetInfo_LogsGRPCError(t *testing.T) {
 	client := &Client{
 		assetsClient:        mockAssets,
 		assetMicCache:       map[string]string{"SBER": "SBER@TQBR"},
-		assetLotCache:       map[string]float64{"SBER": 1},
+		assetLotCache:       map[string]models.Decimal{"SBER": models.DecimalFromInt(1)},
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -613,7 +615,7 @@ func TestGetAssetParams_LogsGRPCError(t *testing.T) {
 			},
 		},
 		assetMicCache:       map[string]string{"SBER": "SBER@TQBR"},
-		assetLotCache:       map[string]float64{"SBER": 1},
+		assetLotCache:       map[string]models.Decimal{"SBER": models.DecimalFromInt(1)},
 		instrumentNameCache: make(map[string]string),
 	}
 
@@ -700,10 +702,10 @@ func TestPlaceOrder_LogsGRPCError(t *testing.T) {
 	client := &Client{
 		ordersClient:  mockOrders,
 		assetMicCache: map[string]string{"SBER": "SBER@TQBR"},
-		assetLotCache: map[string]float64{"SBER": 10, "SBER@TQBR": 10},
+		assetLotCache: map[string]models.Decimal{"SBER": models.DecimalFromInt(10), "SBER@TQBR": models.DecimalFromInt(10)},
 	}
 
-	_, err := client.PlaceOrder("acc1", "SBER", "Buy", 5, nil)
+	_, err := client.PlaceOrder("acc1", "SBER", "Buy", models.DecimalFromInt(5), nil)
 	if err == nil {
 		t.Fatal("Expected error, got nil")
 	}
@@ -0,0 +1,304 @@
+// Package journal reads and writes market-data journals: an append-only file of
+// API responses recorded during a live session, used to replay the session later.
+//
+// A journal starts with the magic bytes "FTJ1". Each record follows as a uvarint
+// length and a protobuf-encoded envelope:
+//
+//	1: kind      (varint)
+//	2: time      (varint, Unix nanoseconds when the response was received)
+//	3: symbol    (string)
+//	4: timeframe (varint, bars only)
+//	5: payload   (bytes, the protobuf-encoded API message)
+package journal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// Kind identifies the API message stored in a record.
+type Kind int32
+
+// Record kinds. Values are stored in the file and must not change.
+const (
+	KindQuote       Kind = 1 // marketdata.Quote
+	KindBars        Kind = 2 // marketdata.BarsResponse
+	KindOrderBook   Kind = 3 // marketdata.OrderBook, a full snapshot
+	KindBookUpdate  Kind = 4 // marketdata.StreamOrderBook, incremental rows
+	KindTrades      Kind = 5 // marketdata.LatestTradesResponse
+	maxRecordLength      = 16 << 20
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindQuote:
+		return "quote"
+	case KindBars:
+		return "bars"
+	case KindOrderBook:
+		return "orderbook"
+	case KindBookUpdate:
+		return "orderbook-update"
+	case KindTrades:
+		return "trades"
+	}
+	return fmt.Sprintf("kind(%d)", int32(k))
+}
+
+// magic identifies a journal file and its format version.
+var magic = []byte("FTJ1")
+
+// ErrNotJournal is returned when a file does not start with the journal magic.
+var ErrNotJournal = errors.New("not a market-data journal")
+
+// Record is one recorded API response.
+type Record struct {
+	Kind      Kind
+	Time      time.Time
+	Symbol    string
+	Timeframe int32
+	Payload   []byte
+}
+
+// Unmarshal decodes the payload into m.
+func (r Record) Unmarshal(m proto.Message) error {
+	if err := proto.Unmarshal(r.Payload, m); err != nil {
+		return fmt.Errorf("failed to decode %s record: %w", r.Kind, err)
+	}
+	return nil
+}
+
+// Writer appends records to a journal. It is safe for concurrent use.
+type Writer struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+}
+
+// NewWriter writes the journal header to w and returns a Writer appending to it.
+func NewWriter(w io.Writer) (*Writer, error) {
+	jw := &Writer{w: bufio.NewWriter(w)}
+	if c, ok := w.(io.Closer); ok {
+		jw.closer = c
+	}
+	if _, err := jw.w.Write(magic); err != nil {
+		return nil, err
+	}
+	return jw, nil
+}
+
+// Create creates or truncates the journal file at path.
+func Create(path string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create journal: %w", err)
+	}
+	w, err := NewWriter(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to write journal header: %w", err)
+	}
+	return w, nil
+}
+
+// Write appends one record.
+func (w *Writer) Write(r Record) error {
+	var env []byte
+	env = protowire.AppendTag(env, 1, protowire.VarintType)
+	env = protowire.AppendVarint(env, uint64(r.Kind))
+	env = protowire.AppendTag(env, 2, protowire.VarintType)
+	env = protowire.AppendVarint(env, uint64(r.Time.UnixNano()))
+	if r.Symbol != "" {
+		env = protowire.AppendTag(env, 3, protowire.BytesType)
+		env = protowire.AppendString(env, r.Symbol)
+	}
+	if r.Timeframe != 0 {
+		env = protowire.AppendTag(env, 4, protowire.VarintType)
+		env = protowire.AppendVarint(env, uint64(r.Timeframe))
+	}
+	env = protowire.AppendTag(env, 5, protowire.BytesType)
+	env = protowire.AppendBytes(env, r.Payload)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.w.Write(protowire.AppendVarint(nil, uint64(len(env)))); err != nil {
+		return err
+	}
+	_, err := w.w.Write(env)
+	return err
+}
+
+// WriteMessage encodes m and appends it as a record of the given kind, stamped with t.
+func (w *Writer) WriteMessage(kind Kind, t time.Time, symbol string, timeframe int32, m proto.Message) error {
+	payload, err := proto.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s record: %w", kind, err)
+	}
+	return w.Write(Record{Kind: kind, Time: t, Symbol: symbol, Timeframe: timeframe, Payload: payload})
+}
+
+// Flush writes buffered records to the underlying writer.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Flush()
+}
+
+// Close flushes buffered records and closes the underlying writer if it is an io.Closer.
+func (w *Writer) Close() error {
+	err := w.Flush()
+	if w.closer != nil {
+		if cerr := w.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Reader reads records from a journal.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader checks the journal header of r and returns a Reader positioned at the first record.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	head := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, head); err != nil || string(head) != string(magic) {
+		return nil, ErrNotJournal
+	}
+	return &Reader{r: br}, nil
+}
+
+// Next returns the next record. It returns io.EOF at the end of the journal and
+// io.ErrUnexpectedEOF when the last record was cut short, as happens when the
+// recording process is killed.
+func (r *Reader) Next() (Record, error) {
+	n, err := binaryUvarint(r.r)
+	if err != nil {
+		return Record{}, err
+	}
+	if n > maxRecordLength {
+		return Record{}, fmt.Errorf("record length %d exceeds limit", n)
+	}
+	env := make([]byte, n)
+	if _, err := io.ReadFull(r.r, env); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return Record{}, err
+	}
+	return parseEnvelope(env)
+}
+
+// ReadFile reads every record of the journal at path. A record cut short at the end of
+// the file is dropped; records before it are returned with a nil error.
+func ReadFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	r, err := NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var records []Record
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return records, nil
+		}
+		if err != nil {
+			return records, fmt.Errorf("%s: record %d: %w", path, len(records)+1, err)
+		}
+		records = append(records, rec)
+	}
+}
+
+// binaryUvarint reads a uvarint length prefix. A clean end of input before the first
+// byte is io.EOF; an end inside the prefix is io.ErrUnexpectedEOF.
+func binaryUvarint(r io.ByteReader) (uint64, error) {
+	var v uint64
+	for i := 0; i < binaryMaxVarintLen; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			if i > 0 && errors.Is(err, io.EOF) {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		v |= uint64(b&0x7f) << (7 * i)
+		if b < 0x80 {
+			return v, nil
+		}
+	}
+	return 0, errors.New("malformed record length")
+}
+
+const binaryMaxVarintLen = 10
+
+func parseEnvelope(b []byte) (Record, error) {
+	var rec Record
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return Record{}, protowire.ParseError(n)
+		}
+		b = b[n:]
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return Record{}, protowire.ParseError(n)
+			}
+			rec.Kind = Kind(v)
+			b = b[n:]
+		case num == 2 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return Record{}, protowire.ParseError(n)
+			}
+			rec.Time = time.Unix(0, int64(v))
+			b = b[n:]
+		case num == 3 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			if n < 0 {
+				return Record{}, protowire.ParseError(n)
+			}
+			rec.Symbol = v
+			b = b[n:]
+		case num == 4 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return Record{}, protowire.ParseError(n)
+			}
+			rec.Timeframe = int32(v)
+			b = b[n:]
+		case num == 5 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return Record{}, protowire.ParseError(n)
+			}
+			rec.Payload = append([]byte(nil), v...)
+			b = b[n:]
+		default:
+			// Skip fields added by later versions
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return Record{}, protowire.ParseError(n)
+			}
+			b = b[n:]
+		}
+	}
+	return rec, nil
+}
@@ -0,0 +1,110 @@
+package journal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/decimal"
+)
+
+func TestWriterReader_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	t0 := time.Date(2026, 3, 2, 10, 0, 0, 123, time.UTC)
+	if err := w.WriteMessage(KindQuote, t0, "SBER@TQBR", 0, &decimal.Decimal{Value: "285.10"}); err != nil {
+		t.Fatalf("WriteMessage failed: %v", err)
+	}
+	if err := w.WriteMessage(KindBars, t0.Add(time.Second), "GAZP@TQBR", 9, &decimal.Decimal{Value: "160.3"}); err != nil {
+		t.Fatalf("WriteMessage failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	rec, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if rec.Kind != KindQuote || rec.Symbol != "SBER@TQBR" || !rec.Time.Equal(t0) || rec.Timeframe != 0 {
+		t.Errorf("Unexpected first record: %+v", rec)
+	}
+	var d decimal.Decimal
+	if err := rec.Unmarshal(&d); err != nil || d.Value != "285.10" {
+		t.Errorf("Expected payload 285.10, got %q (%v)", d.Value, err)
+	}
+
+	rec, err = r.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if rec.Kind != KindBars || rec.Timeframe != 9 || rec.Symbol != "GAZP@TQBR" {
+		t.Errorf("Unexpected second record: %+v", rec)
+	}
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Expected io.EOF at end of journal, got %v", err)
+	}
+}
+
+func TestNewReader_RejectsOtherFiles(t *testing.T) {
+	if _, err := NewReader(bytes.NewReader([]byte("not a journal"))); !errors.Is(err, ErrNotJournal) {
+		t.Errorf("Expected ErrNotJournal, got %v", err)
+	}
+	if _, err := NewReader(bytes.NewReader(nil)); !errors.Is(err, ErrNotJournal) {
+		t.Errorf("Expected ErrNotJournal for empty input, got %v", err)
+	}
+}
+
+func TestReadFile_DropsTruncatedTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.ftj")
+	w, err := Create(path)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := w.WriteMessage(KindTrades, time.Unix(int64(i), 0), "SBER@TQBR", 0, &decimal.Decimal{Value: "1"}); err != nil {
+			t.Fatalf("WriteMessage failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a recorder killed in the middle of the last record
+	if err := os.WriteFile(path, data[:len(data)-3], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 complete records, got %d", len(records))
+	}
+	if records[1].Time.Unix() != 1 {
+		t.Errorf("Expected second record at t=1, got %v", records[1].Time)
+	}
+
+	r, _ := NewReader(bytes.NewReader(data[:len(data)-3]))
+	_, _ = r.Next()
+	_, _ = r.Next()
+	if _, err := r.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected io.ErrUnexpectedEOF for cut record, got %v", err)
+	}
+}
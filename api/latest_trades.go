@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"finam-terminal/api/journal"
+	"finam-terminal/models"
+
+	tradeapiv1 "github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1"
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+)
+
+// GetLatestTrades returns the most recent exchange prints for a symbol
+func (c *Client) GetLatestTrades(accountID string, symbol string) ([]models.MarketTrade, error) {
+	return c.fetchLatestTrades(c.getFullSymbol(symbol, accountID))
+}
+
+func (c *Client) fetchLatestTrades(fullSymbol string) ([]models.MarketTrade, error) {
+	ctx, cancel := c.getContext()
+	defer cancel()
+
+	resp, err := c.marketDataClient.LatestTrades(ctx, &marketdata.LatestTradesRequest{Symbol: fullSymbol})
+	if err != nil {
+		c.logGRPCError("MarketDataService", "LatestTrades", err, fmt.Sprintf("Symbol: %s", fullSymbol))
+		return nil, fmt.Errorf("failed to get latest trades for %s: %w", fullSymbol, err)
+	}
+	c.record(journal.KindTrades, fullSymbol, 0, resp)
+
+	return marketTradesFromProto(fullSymbol, resp.GetTrades()), nil
+}
+
+// SubscribeLatestTrades streams exchange prints for a symbol. The handler first receives
+// the latest trades snapshot and then every streamed batch; after a reconnect the snapshot
+// is sent again, so handlers should de-duplicate by trade ID. The stream resubscribes with
+// backoff when it drops and runs until the returned stop function is called.
+// The handler runs on the stream goroutine.
+func (c *Client) SubscribeLatestTrades(accountID string, symbol string, handler func([]models.MarketTrade)) (stop func()) {
+	fullSymbol := c.getFullSymbol(symbol, accountID)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		log.Printf("[INFO] Latest trades stream started for %s", fullSymbol)
+		c.keepStream(ctx, "Latest trades", func(ctx context.Context) (bool, error) {
+			return c.consumeLatestTradesStream(ctx, fullSymbol, handler)
+		})
+	}()
+
+	return cancel
+}
+
+func (c *Client) consumeLatestTradesStream(ctx context.Context, fullSymbol string, handler func([]models.MarketTrade)) (bool, error) {
+	stream, err := c.marketDataClient.SubscribeLatestTrades(c.getStreamContext(ctx), &marketdata.SubscribeLatestTradesRequest{
+		Symbol: fullSymbol,
+	})
+	if err != nil {
+		c.logGRPCError("MarketDataService", "SubscribeLatestTrades", err, fmt.Sprintf("Symbol: %s", fullSymbol))
+		return false, err
+	}
+
+	// Backfill the tape so it is not empty until the next print
+	if trades, err := c.fetchLatestTrades(fullSymbol); err == nil && len(trades) > 0 {
+		handler(trades)
+	}
+
+	received := false
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return received, fmt.Errorf("stream closed by server")
+			}
+			if ctx.Err() == nil {
+				c.logGRPCError("MarketDataService", "SubscribeLatestTrades", err, fmt.Sprintf("Symbol: %s", fullSymbol))
+			}
+			return received, err
+		}
+		received = true
+		c.record(journal.KindTrades, fullSymbol, 0, resp)
+
+		if trades := marketTradesFromProto(fullSymbol, resp.GetTrades()); len(trades) > 0 {
+			handler(trades)
+		}
+	}
+}
+
+// marketTradesFromProto converts exchange prints, skipping empty entries.
+func marketTradesFromProto(symbol string, in []*marketdata.Trade) []models.MarketTrade {
+	trades := make([]models.MarketTrade, 0, len(in))
+	for _, t := range in {
+		if t == nil {
+			continue
+		}
+		side := "Unknown"
+		switch t.Side {
+		case tradeapiv1.Side_SIDE_BUY:
+			side = "Buy"
+		case tradeapiv1.Side_SIDE_SELL:
+			side = "Sell"
+		}
+		trades = append(trades, models.MarketTrade{
+			ID:        t.TradeId,
+			Symbol:    symbol,
+			Price:     decimalOf(t.Price),
+			Size:      decimalOf(t.Size),
+			Side:      side,
+			Timestamp: t.Timestamp.AsTime().Local(),
+		})
+	}
+	return trades
+}
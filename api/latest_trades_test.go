@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"finam-terminal/models"
+
+	tradeapiv1 "github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1"
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+	"google.golang.org/genproto/googleapis/type/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestGetLatestTrades(t *testing.T) {
+	ts := time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC)
+	mockMarketData := &mockMarketDataServiceClient{
+		LatestTradesFunc: func(ctx context.Context, in *marketdata.LatestTradesRequest, opts ...grpc.CallOption) (*marketdata.LatestTradesResponse, error) {
+			if in.Symbol != "SBER@TQBR" {
+				t.Errorf("Expected symbol SBER@TQBR, got %s", in.Symbol)
+			}
+			return &marketdata.LatestTradesResponse{
+				Symbol: in.Symbol,
+				Trades: []*marketdata.Trade{
+					{TradeId: "1", Timestamp: timestamppb.New(ts), Price: &decimal.Decimal{Value: "250.10"}, Size: &decimal.Decimal{Value: "20"}, Side: tradeapiv1.Side_SIDE_BUY},
+					{TradeId: "2", Timestamp: timestamppb.New(ts), Price: &decimal.Decimal{Value: "250.00"}, Size: &decimal.Decimal{Value: "5"}, Side: tradeapiv1.Side_SIDE_SELL},
+					{TradeId: "3", Timestamp: timestamppb.New(ts), Price: &decimal.Decimal{Value: "250.00"}, Size: &decimal.Decimal{Value: "1"}},
+					nil,
+				},
+			}, nil
+		},
+	}
+
+	client := &Client{
+		marketDataClient: mockMarketData,
+		assetMicCache:    map[string]string{"SBER": "SBER@TQBR"},
+		assetLotCache:    map[string]models.Decimal{"SBER": models.DecimalFromInt(10)},
+	}
+
+	trades, err := client.GetLatestTrades("acc1", "SBER")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(trades) != 3 {
+		t.Fatalf("Expected 3 trades, got %d", len(trades))
+	}
+
+	want := []string{"Buy", "Sell", "Unknown"}
+	for i, tr := range trades {
+		if tr.Side != want[i] {
+			t.Errorf("Trade %d: expected side %s, got %s", i, want[i], tr.Side)
+		}
+		if tr.Symbol != "SBER@TQBR" {
+			t.Errorf("Trade %d: expected symbol SBER@TQBR, got %s", i, tr.Symbol)
+		}
+	}
+	if trades[0].Price.String() != "250.10" || trades[0].Size.String() != "20" {
+		t.Errorf("Expected 20 @ 250.10, got %v @ %v", trades[0].Size, trades[0].Price)
+	}
+	if trades[0].Timestamp.Location() != time.Local {
+		t.Errorf("Expected local timestamp, got %v", trades[0].Timestamp.Location())
+	}
+}
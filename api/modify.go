@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"finam-terminal/models"
+)
+
+// ModifyError reports a modification that did not complete. The old order is still working
+// unless it was filled in the meantime. NewOrderID is set when the replacement was placed
+// and could not be withdrawn, so both orders may be working.
+type ModifyError struct {
+	OldOrderID string
+	NewOrderID string
+	Err        error
+}
+
+func (e *ModifyError) Error() string {
+	if e.NewOrderID != "" {
+		return fmt.Sprintf("order %s was not cancelled and its replacement %s could not be withdrawn, both may be active: %v",
+			e.OldOrderID, e.NewOrderID, e.Err)
+	}
+	return fmt.Sprintf("order %s was not modified: %v", e.OldOrderID, e.Err)
+}
+
+func (e *ModifyError) Unwrap() error {
+	return e.Err
+}
+
+// ModifyOrder replaces the working order orderID with the order PlaceOrder would send for
+// the other arguments, and returns the ID of the replacement. The old order stays working
+// until the replacement is confirmed: the replacement is placed first and the old order is
+// cancelled after it, and when that cancel fails the replacement is cancelled again. Failures
+// are returned as *ModifyError.
+func (c *Client) ModifyOrder(accountID, orderID, symbol, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error) {
+	return c.placeThenCancel(accountID, orderID, func() (string, error) {
+		return c.PlaceOrder(accountID, symbol, buySell, quantity, params)
+	})
+}
+
+// ModifySLTPOrder is ModifyOrder for a linked stop-loss + take-profit pair, placed as
+// PlaceSLTPOrder would place it.
+func (c *Client) ModifySLTPOrder(accountID, orderID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice models.Decimal) (string, error) {
+	return c.placeThenCancel(accountID, orderID, func() (string, error) {
+		return c.PlaceSLTPOrder(accountID, symbol, buySell, slQty, slPrice, tpQty, tpPrice)
+	})
+}
+
+// placeThenCancel places the replacement, then cancels orderID, and rolls the replacement
+// back when the old order cannot be cancelled.
+func (c *Client) placeThenCancel(accountID, orderID string, place func() (string, error)) (string, error) {
+	newID, err := place()
+	if err != nil {
+		c.auditModify(accountID, orderID, "", "replacement rejected, old order kept")
+		return "", &ModifyError{OldOrderID: orderID, Err: err}
+	}
+
+	cancelErr := c.CancelOrder(accountID, orderID)
+	if cancelErr == nil {
+		c.auditModify(accountID, orderID, newID, "replaced")
+		return newID, nil
+	}
+
+	if err := c.CancelOrder(accountID, newID); err != nil {
+		c.auditModify(accountID, orderID, newID, "old order not cancelled, rollback failed")
+		return "", &ModifyError{OldOrderID: orderID, NewOrderID: newID, Err: errors.Join(cancelErr, err)}
+	}
+	c.auditModify(accountID, orderID, newID, "old order not cancelled, replacement rolled back")
+	return "", &ModifyError{OldOrderID: orderID, Err: cancelErr}
+}
+
+// SetModifyAudit appends the audit entry of every order modification to path from now on.
+// Entries are always written to the log as well.
+func (c *Client) SetModifyAudit(path string) {
+	c.modifyAuditMu.Lock()
+	defer c.modifyAuditMu.Unlock()
+	c.modifyAudit = path
+}
+
+// auditModify writes the audit entry linking a modified order to its replacement.
+func (c *Client) auditModify(accountID, oldID, newID, outcome string) {
+	if newID == "" {
+		newID = "-"
+	}
+	log.Printf("[AUDIT] Modify order: account=%s old=%s new=%s outcome=%s", accountID, oldID, newID, outcome)
+
+	c.modifyAuditMu.Lock()
+	defer c.modifyAuditMu.Unlock()
+	if c.modifyAudit == "" {
+		return
+	}
+	if err := appendModifyAudit(c.modifyAudit, fmt.Sprintf("%s account=%s old=%s new=%s outcome=%s\n",
+		time.Now().Format(time.RFC3339), accountID, oldID, newID, outcome)); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+}
+
+func appendModifyAudit(path, entry string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write modify audit: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write modify audit: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(entry); err != nil {
+		return fmt.Errorf("failed to write modify audit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"finam-terminal/models"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/orders"
+	"google.golang.org/grpc"
+)
+
+func TestModifyOrder_PlacesBeforeCancelling(t *testing.T) {
+	var calls []string
+	mockOrders := &mockOrdersServiceClient{
+		PlaceOrderFunc: func(ctx context.Context, in *orders.Order, opts ...grpc.CallOption) (*orders.OrderState, error) {
+			calls = append(calls, "place "+in.LimitPrice.Value)
+			return &orders.OrderState{OrderId: "new-1"}, nil
+		},
+		CancelOrderFunc: func(ctx context.Context, in *orders.CancelOrderRequest, opts ...grpc.CallOption) (*orders.OrderState, error) {
+			calls = append(calls, "cancel "+in.OrderId)
+			return &orders.OrderState{OrderId: in.OrderId}, nil
+		},
+	}
+	client := newTestOrderClient(mockOrders)
+
+	id, err := client.ModifyOrder("test-acc", "old-1", "SBER", "Buy", models.DecimalFromInt(10),
+		&models.OrderParams{OrderType: models.OrderTypeLimit, LimitPrice: models.DecimalFromInt(251)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if id != "new-1" {
+		t.Errorf("Expected new order ID new-1, got %s", id)
+	}
+	if fmt.Sprint(calls) != "[place 251 cancel old-1]" {
+		t.Errorf("Expected place then cancel, got %v", calls)
+	}
+}
+
+func TestModifyOrder_PlaceFailsKeepsOldOrder(t *testing.T) {
+	mockOrders := &mockOrdersServiceClient{
+		PlaceOrderFunc: func(ctx context.Context, in *orders.Order, opts ...grpc.CallOption) (*orders.OrderState, error) {
+			return nil, fmt.Errorf("insufficient funds")
+		},
+		CancelOrderFunc: func(ctx context.Context, in *orders.CancelOrderRequest, opts ...grpc.CallOption) (*orders.OrderState, error) {
+			t.Error("CancelOrder must not be called when the replacement is rejected")
+			return nil, nil
+		},
+	}
+	client := newTestOrderClient(mockOrders)
+
+	_, err := client.ModifyOrder("test-acc", "old-1", "SBER", "Buy", models.DecimalFromInt(10), nil)
+	var merr *ModifyError
+	if !errors.As(err, &merr) || merr.OldOrderID != "old-1" || merr.NewOrderID != "" {
+		t.Errorf("Expected a ModifyError keeping old-1, got %v", err)
+	}
+}
+
+func TestModifyOrder_CancelFailsRollsBack(t *testing.T) {
+	var cancelled []string
+	mockOrders := &mockOrdersServiceClient{
+		PlaceOrderFunc: func(ctx context.Context, in *orders.Order, opts ...grpc.CallOption) (*orders.OrderState, error) {
+			return &orders.OrderState{OrderId: "new-1"}, nil
+		},
+		CancelOrderFunc: func(ctx context.Context, in *orders.CancelOrderRequest, opts ...grpc.CallOption) (*orders.OrderState, error) {
+			cancelled = append(cancelled, in.OrderId)
+			if in.OrderId == "old-1" {
+				return nil, fmt.Errorf("order already executed")
+			}
+			return &orders.OrderState{OrderId: in.OrderId}, nil
+		},
+	}
+	client := newTestOrderClient(mockOrders)
+
+	_, err := client.ModifyOrder("test-acc", "old-1", "SBER", "Buy", models.DecimalFromInt(10), nil)
+	var merr *ModifyError
+	if !errors.As(err, &merr) || merr.NewOrderID != "" {
+		t.Fatalf("Expected a rolled back modification, got %v", err)
+	}
+	if fmt.Sprint(cancelled) != "[old-1 new-1]" {
+		t.Errorf("Expected the replacement to be cancelled after the failed cancel, got %v", cancelled)
+	}
+}
+
+func TestModifyOrder_RollbackFailsReportsBothOrders(t *testing.T) {
+	mockOrders := &mockOrdersServiceClient{
+		PlaceSLTPOrderFunc: func(ctx context.Context, in *orders.SLTPOrder, opts ...grpc.CallOption) (*orders.OrderState, error) {
+			return &orders.OrderState{OrderId: "new-1"}, nil
+		},
+		CancelOrderFunc: func(ctx context.Context, in *orders.CancelOrderRequest, opts ...grpc.CallOption) (*orders.OrderState, error) {
+			return nil, fmt.Errorf("connection lost")
+		},
+	}
+	client := newTestOrderClient(mockOrders)
+
+	_, err := client.ModifySLTPOrder("test-acc", "old-1", "SBER", "Sell", models.DecimalFromInt(10), models.DecimalFromInt(240), models.DecimalFromInt(10), models.DecimalFromInt(260))
+	var merr *ModifyError
+	if !errors.As(err, &merr) || merr.OldOrderID != "old-1" || merr.NewOrderID != "new-1" {
+		t.Errorf("Expected both order IDs in the error, got %v", err)
+	}
+}
+
+func TestModifyOrder_AppendsAuditFile(t *testing.T) {
+	mockOrders := &mockOrdersServiceClient{
+		PlaceOrderFunc: func(ctx context.Context, in *orders.Order, opts ...grpc.CallOption) (*orders.OrderState, error) {
+			return &orders.OrderState{OrderId: "new-1"}, nil
+		},
+		CancelOrderFunc: func(ctx context.Context, in *orders.CancelOrderRequest, opts ...grpc.CallOption) (*orders.OrderState, error) {
+			return &orders.OrderState{OrderId: in.OrderId}, nil
+		},
+	}
+	client := newTestOrderClient(mockOrders)
+	path := filepath.Join(t.TempDir(), "modify-audit.log")
+	client.SetModifyAudit(path)
+
+	if _, err := client.ModifyOrder("test-acc", "old-1", "SBER", "Buy", models.DecimalFromInt(10), nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := client.ModifyOrder("test-acc", "new-1", "SBER", "Buy", models.DecimalFromInt(5), nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected the audit file, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "account=test-acc old=old-1 new=new-1 outcome=replaced") {
+		t.Errorf("Unexpected audit entries:\n%s", data)
+	}
+}
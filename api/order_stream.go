@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"finam-terminal/models"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/orders"
+)
+
+// SubscribeOrders streams order state changes for an account. On every (re)subscribe
+// onSnapshot receives the full order list, so updates missed while the stream was down
+// are not lost; after that onUpdate receives each streamed batch of changed orders.
+// The stream resubscribes with backoff when it drops and runs until the returned stop
+// function is called. Both callbacks run on the stream goroutine.
+func (c *Client) SubscribeOrders(accountID string, onSnapshot func([]models.Order), onUpdate func([]models.Order)) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		log.Printf("[INFO] Orders stream started for account %s", accountID)
+		c.keepStream(ctx, "Orders", func(ctx context.Context) (bool, error) {
+			return c.consumeOrdersStream(ctx, accountID, onSnapshot, onUpdate)
+		})
+	}()
+
+	return cancel
+}
+
+func (c *Client) consumeOrdersStream(ctx context.Context, accountID string, onSnapshot, onUpdate func([]models.Order)) (bool, error) {
+	stream, err := c.ordersClient.SubscribeOrders(c.getStreamContext(ctx), &orders.SubscribeOrdersRequest{
+		AccountId: accountID,
+	})
+	if err != nil {
+		c.logGRPCError("OrdersService", "SubscribeOrders", err, fmt.Sprintf("AccountId: %s", accountID))
+		return false, err
+	}
+
+	// Subscribe first, then resync, so nothing falls between the snapshot and the stream
+	if list, err := c.GetActiveOrders(accountID); err == nil {
+		onSnapshot(list)
+	}
+
+	received := false
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return received, fmt.Errorf("stream closed by server")
+			}
+			if ctx.Err() == nil {
+				c.logGRPCError("OrdersService", "SubscribeOrders", err, fmt.Sprintf("AccountId: %s", accountID))
+			}
+			return received, err
+		}
+		received = true
+
+		var changed []models.Order
+		for _, o := range resp.GetOrders() {
+			if o == nil || o.OrderId == "" {
+				continue
+			}
+			changed = append(changed, c.orderFromProto(o))
+		}
+		if len(changed) > 0 {
+			onUpdate(changed)
+		}
+	}
+}
+
+// SubscribeTrades streams own trades for an account. The handler first receives the
+// trade history snapshot and then every streamed batch; after a reconnect the snapshot
+// is sent again, so handlers should de-duplicate by trade ID. The stream resubscribes
+// with backoff when it drops and runs until the returned stop function is called.
+// The handler runs on the stream goroutine.
+func (c *Client) SubscribeTrades(accountID string, handler func([]models.Trade)) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		log.Printf("[INFO] Trades stream started for account %s", accountID)
+		c.keepStream(ctx, "Trades", func(ctx context.Context) (bool, error) {
+			return c.consumeTradesStream(ctx, accountID, handler)
+		})
+	}()
+
+	return cancel
+}
+
+func (c *Client) consumeTradesStream(ctx context.Context, accountID string, handler func([]models.Trade)) (bool, error) {
+	stream, err := c.ordersClient.SubscribeTrades(c.getStreamContext(ctx), &orders.SubscribeTradesRequest{
+		AccountId: accountID,
+	})
+	if err != nil {
+		c.logGRPCError("OrdersService", "SubscribeTrades", err, fmt.Sprintf("AccountId: %s", accountID))
+		return false, err
+	}
+
+	if trades, err := c.GetTradeHistory(accountID); err == nil && len(trades) > 0 {
+		handler(trades)
+	}
+
+	received := false
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return received, fmt.Errorf("stream closed by server")
+			}
+			if ctx.Err() == nil {
+				c.logGRPCError("OrdersService", "SubscribeTrades", err, fmt.Sprintf("AccountId: %s", accountID))
+			}
+			return received, err
+		}
+		received = true
+
+		var trades []models.Trade
+		for _, t := range resp.GetTrades() {
+			if t == nil || t.TradeId == "" {
+				continue
+			}
+			trades = append(trades, c.tradeFromProto(t))
+		}
+		if len(trades) > 0 {
+			handler(trades)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"finam-terminal/models"
+
+	tradeapiv1 "github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1"
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/accounts"
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/orders"
+	"google.golang.org/genproto/googleapis/type/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// mockOrdersStream replays canned responses and then returns io.EOF.
+type mockOrdersStream struct {
+	grpc.ClientStream
+	responses []*orders.SubscribeOrdersResponse
+}
+
+func (s *mockOrdersStream) Recv() (*orders.SubscribeOrdersResponse, error) {
+	if len(s.responses) == 0 {
+		return nil, io.EOF
+	}
+	resp := s.responses[0]
+	s.responses = s.responses[1:]
+	return resp, nil
+}
+
+// mockTradesStream replays canned responses and then returns io.EOF.
+type mockTradesStream struct {
+	grpc.ClientStream
+	responses []*orders.SubscribeTradesResponse
+}
+
+func (s *mockTradesStream) Recv() (*orders.SubscribeTradesResponse, error) {
+	if len(s.responses) == 0 {
+		return nil, io.EOF
+	}
+	resp := s.responses[0]
+	s.responses = s.responses[1:]
+	return resp, nil
+}
+
+func TestConsumeOrdersStream_SnapshotThenUpdates(t *testing.T) {
+	mockOrders := &mockOrdersServiceClient{
+		SubscribeOrdersFunc: func(ctx context.Context, in *orders.SubscribeOrdersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[orders.SubscribeOrdersResponse], error) {
+			if in.AccountId != "acc1" {
+				t.Errorf("Expected account acc1, got %s", in.AccountId)
+			}
+			return &mockOrdersStream{responses: []*orders.SubscribeOrdersResponse{
+				{Orders: []*orders.OrderState{{
+					OrderId:          "ORD1",
+					Status:           orders.OrderStatus_ORDER_STATUS_FILLED,
+					ExecutedQuantity: &decimal.Decimal{Value: "10"},
+					Order: &orders.Order{
+						Symbol:   "SBER@TQBR",
+						Side:     tradeapiv1.Side_SIDE_BUY,
+						Type:     orders.OrderType_ORDER_TYPE_LIMIT,
+						Quantity: &decimal.Decimal{Value: "10"},
+					},
+				}}},
+				// Empty entries are skipped
+				{Orders: []*orders.OrderState{{}}},
+			}}, nil
+		},
+		GetOrdersFunc: func(ctx context.Context, in *orders.OrdersRequest, opts ...grpc.CallOption) (*orders.OrdersResponse, error) {
+			return &orders.OrdersResponse{Orders: []*orders.OrderState{{
+				OrderId: "ORD1",
+				Status:  orders.OrderStatus_ORDER_STATUS_NEW,
+				Order:   &orders.Order{Symbol: "SBER@TQBR", Side: tradeapiv1.Side_SIDE_BUY},
+			}}}, nil
+		},
+	}
+
+	client := &Client{ordersClient: mockOrders}
+
+	var snapshot []models.Order
+	var updates [][]models.Order
+	received, err := client.consumeOrdersStream(context.Background(), "acc1",
+		func(list []models.Order) { snapshot = list },
+		func(list []models.Order) { updates = append(updates, list) })
+
+	if err == nil {
+		t.Fatal("Expected an error when the stream ends")
+	}
+	if !received {
+		t.Error("Expected received to be true")
+	}
+	if len(snapshot) != 1 || snapshot[0].Status != "Active" {
+		t.Fatalf("Expected snapshot with one active order, got %+v", snapshot)
+	}
+	if len(updates) != 1 || len(updates[0]) != 1 {
+		t.Fatalf("Expected one update batch with one order, got %+v", updates)
+	}
+	got := updates[0][0]
+	if got.ID != "ORD1" || got.Status != "Filled" || got.Side != "Buy" || got.Type != "Limit" || got.ExecutedQty.String() != "10" {
+		t.Errorf("Unexpected order update: %+v", got)
+	}
+}
+
+func TestConsumeTradesStream_BackfillsHistory(t *testing.T) {
+	ts := timestamppb.New(time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC))
+	mockOrders := &mockOrdersServiceClient{
+		SubscribeTradesFunc: func(ctx context.Context, in *orders.SubscribeTradesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[orders.SubscribeTradesResponse], error) {
+			return &mockTradesStream{responses: []*orders.SubscribeTradesResponse{
+				{Trades: []*tradeapiv1.AccountTrade{{
+					TradeId:   "T2",
+					Symbol:    "SBER@TQBR",
+					Price:     &decimal.Decimal{Value: "251.00"},
+					Size:      &decimal.Decimal{Value: "20"},
+					Side:      tradeapiv1.Side_SIDE_SELL,
+					Timestamp: ts,
+				}}},
+			}}, nil
+		},
+	}
+	mockAccounts := &mockAccountsServiceClient{
+		TradesFunc: func(ctx context.Context, in *accounts.TradesRequest, opts ...grpc.CallOption) (*accounts.TradesResponse, error) {
+			return &accounts.TradesResponse{Trades: []*tradeapiv1.AccountTrade{{
+				TradeId:   "T1",
+				Symbol:    "SBER@TQBR",
+				Price:     &decimal.Decimal{Value: "250.00"},
+				Size:      &decimal.Decimal{Value: "10"},
+				Side:      tradeapiv1.Side_SIDE_BUY,
+				Timestamp: ts,
+			}}}, nil
+		},
+	}
+
+	client := &Client{ordersClient: mockOrders, accountsClient: mockAccounts}
+
+	var batches [][]models.Trade
+	if _, err := client.consumeTradesStream(context.Background(), "acc1", func(trades []models.Trade) {
+		batches = append(batches, trades)
+	}); err == nil {
+		t.Fatal("Expected an error when the stream ends")
+	}
+
+	if len(batches) != 2 {
+		t.Fatalf("Expected snapshot and one streamed batch, got %d", len(batches))
+	}
+	if batches[0][0].ID != "T1" {
+		t.Errorf("Expected snapshot trade T1, got %s", batches[0][0].ID)
+	}
+	got := batches[1][0]
+	if got.ID != "T2" || got.Side != "Sell" || got.Total.String() != "5020.00" {
+		t.Errorf("Unexpected streamed trade: %+v", got)
+	}
+}
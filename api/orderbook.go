@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"time"
+
+	"finam-terminal/api/journal"
+	"finam-terminal/models"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+	"google.golang.org/genproto/googleapis/type/decimal"
+)
+
+// orderBookState accumulates order book rows into bid and ask levels keyed by price,
+// written without trailing zeros so "250.40" and "250.4" are one level.
+type orderBookState struct {
+	symbol string
+	bids   map[string]models.OrderBookLevel
+	asks   map[string]models.OrderBookLevel
+}
+
+func newOrderBookState(symbol string) *orderBookState {
+	return &orderBookState{
+		symbol: symbol,
+		bids:   make(map[string]models.OrderBookLevel),
+		asks:   make(map[string]models.OrderBookLevel),
+	}
+}
+
+// apply sets or removes one price level. A row carries either a buy or a sell size;
+// a removal without a size clears the price on both sides.
+func (s *orderBookState) apply(price, buySize, sellSize *decimal.Decimal, remove bool) {
+	if price == nil {
+		return
+	}
+	p := decimalOf(price)
+	if !p.Known() {
+		return
+	}
+
+	switch {
+	case buySize != nil:
+		setLevel(s.bids, p, decimalOf(buySize), remove)
+	case sellSize != nil:
+		setLevel(s.asks, p, decimalOf(sellSize), remove)
+	case remove:
+		delete(s.bids, p.Trim().String())
+		delete(s.asks, p.Trim().String())
+	}
+}
+
+func setLevel(levels map[string]models.OrderBookLevel, price, size models.Decimal, remove bool) {
+	key := price.Trim().String()
+	if remove || size.Sign() <= 0 {
+		delete(levels, key)
+		return
+	}
+	levels[key] = models.OrderBookLevel{Price: price, Size: size}
+}
+
+// snapshot returns the current book with bids sorted descending and asks ascending.
+func (s *orderBookState) snapshot() *models.OrderBook {
+	book := &models.OrderBook{
+		Symbol:    s.symbol,
+		Bids:      make([]models.OrderBookLevel, 0, len(s.bids)),
+		Asks:      make([]models.OrderBookLevel, 0, len(s.asks)),
+		Timestamp: time.Now(),
+	}
+	for _, lvl := range s.bids {
+		book.Bids = append(book.Bids, lvl)
+	}
+	for _, lvl := range s.asks {
+		book.Asks = append(book.Asks, lvl)
+	}
+	sort.Slice(book.Bids, func(i, j int) bool { return book.Bids[i].Price.Cmp(book.Bids[j].Price) > 0 })
+	sort.Slice(book.Asks, func(i, j int) bool { return book.Asks[i].Price.Cmp(book.Asks[j].Price) < 0 })
+	return book
+}
+
+// GetOrderBook returns the current depth of market for a symbol
+func (c *Client) GetOrderBook(accountID string, symbol string) (*models.OrderBook, error) {
+	fullSymbol := c.getFullSymbol(symbol, accountID)
+	state, err := c.fetchOrderBook(fullSymbol)
+	if err != nil {
+		return nil, err
+	}
+	return state.snapshot(), nil
+}
+
+// fetchOrderBook loads an order book snapshot into a fresh orderBookState.
+func (c *Client) fetchOrderBook(fullSymbol string) (*orderBookState, error) {
+	ctx, cancel := c.getContext()
+	defer cancel()
+
+	resp, err := c.marketDataClient.OrderBook(ctx, &marketdata.OrderBookRequest{Symbol: fullSymbol})
+	if err != nil {
+		c.logGRPCError("MarketDataService", "OrderBook", err, fmt.Sprintf("Symbol: %s", fullSymbol))
+		return nil, fmt.Errorf("failed to get order book for %s: %w", fullSymbol, err)
+	}
+
+	c.record(journal.KindOrderBook, fullSymbol, 0, resp.GetOrderbook())
+
+	state := newOrderBookState(fullSymbol)
+	for _, row := range resp.GetOrderbook().GetRows() {
+		state.apply(row.GetPrice(), row.GetBuySize(), row.GetSellSize(),
+			row.GetAction() == marketdata.OrderBook_Row_ACTION_REMOVE)
+	}
+	return state, nil
+}
+
+// SubscribeOrderBook streams the order book for a symbol, calling handler with a full
+// snapshot after every update. The stream resubscribes with backoff when it drops and
+// runs until the returned stop function is called. The handler runs on the stream goroutine.
+func (c *Client) SubscribeOrderBook(accountID string, symbol string, handler func(*models.OrderBook)) (stop func()) {
+	fullSymbol := c.getFullSymbol(symbol, accountID)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		log.Printf("[INFO] Order book stream started for %s", fullSymbol)
+		c.keepStream(ctx, "Order book", func(ctx context.Context) (bool, error) {
+			return c.consumeOrderBookStream(ctx, fullSymbol, handler)
+		})
+	}()
+
+	return cancel
+}
+
+// consumeOrderBookStream seeds the book from a snapshot, then applies streamed rows until the stream ends.
+func (c *Client) consumeOrderBookStream(ctx context.Context, fullSymbol string, handler func(*models.OrderBook)) (bool, error) {
+	stream, err := c.marketDataClient.SubscribeOrderBook(c.getStreamContext(ctx), &marketdata.SubscribeOrderBookRequest{
+		Symbol: fullSymbol,
+	})
+	if err != nil {
+		c.logGRPCError("MarketDataService", "SubscribeOrderBook", err, fmt.Sprintf("Symbol: %s", fullSymbol))
+		return false, err
+	}
+
+	// The stream carries incremental changes, so start from a fresh snapshot on every (re)subscribe
+	state, err := c.fetchOrderBook(fullSymbol)
+	if err != nil {
+		return false, err
+	}
+	handler(state.snapshot())
+
+	received := false
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return received, fmt.Errorf("stream closed by server")
+			}
+			if ctx.Err() == nil {
+				c.logGRPCError("MarketDataService", "SubscribeOrderBook", err, fmt.Sprintf("Symbol: %s", fullSymbol))
+			}
+			return received, err
+		}
+		received = true
+
+		changed := false
+		for _, ob := range resp.GetOrderBook() {
+			if ob.GetSymbol() != "" && ob.GetSymbol() != fullSymbol {
+				continue
+			}
+			c.record(journal.KindBookUpdate, fullSymbol, 0, ob)
+			for _, row := range ob.GetRows() {
+				state.apply(row.GetPrice(), row.GetBuySize(), row.GetSellSize(),
+					row.GetAction() == marketdata.StreamOrderBook_Row_ACTION_REMOVE)
+				changed = true
+			}
+		}
+		if changed {
+			handler(state.snapshot())
+		}
+	}
+}
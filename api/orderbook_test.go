@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"finam-terminal/models"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+	"google.golang.org/genproto/googleapis/type/decimal"
+	"google.golang.org/grpc"
+)
+
+func TestOrderBookState_ApplyAndSnapshot(t *testing.T) {
+	s := newOrderBookState("SBER@TQBR")
+	d := func(v string) *decimal.Decimal { return &decimal.Decimal{Value: v} }
+
+	s.apply(d("250.40"), d("10"), nil, false)
+	s.apply(d("250.50"), d("5"), nil, false)
+	s.apply(d("250.70"), nil, d("7"), false)
+	s.apply(d("250.60"), nil, d("3"), false)
+
+	book := s.snapshot()
+	if len(book.Bids) != 2 || len(book.Asks) != 2 {
+		t.Fatalf("Expected 2 bids and 2 asks, got %d/%d", len(book.Bids), len(book.Asks))
+	}
+	if book.Bids[0].Price.String() != "250.50" || book.Asks[0].Price.String() != "250.60" {
+		t.Errorf("Expected best bid 250.50 and best ask 250.60, got %v/%v", book.Bids[0].Price, book.Asks[0].Price)
+	}
+
+	// Update, remove by action, and remove by zero size at the same price written shorter
+	s.apply(d("250.50"), d("8"), nil, false)
+	s.apply(d("250.60"), nil, d("3"), true)
+	s.apply(d("250.4"), d("0"), nil, false)
+
+	book = s.snapshot()
+	if len(book.Bids) != 1 || book.Bids[0].Size.String() != "8" {
+		t.Errorf("Expected single bid of size 8, got %+v", book.Bids)
+	}
+	if len(book.Asks) != 1 || book.Asks[0].Price.String() != "250.70" {
+		t.Errorf("Expected single ask at 250.70, got %+v", book.Asks)
+	}
+}
+
+func TestGetOrderBook(t *testing.T) {
+	mockMarketData := &mockMarketDataServiceClient{
+		OrderBookFunc: func(ctx context.Context, in *marketdata.OrderBookRequest, opts ...grpc.CallOption) (*marketdata.OrderBookResponse, error) {
+			if in.Symbol != "SBER@TQBR" {
+				t.Errorf("Expected symbol SBER@TQBR, got %s", in.Symbol)
+			}
+			return &marketdata.OrderBookResponse{
+				Symbol: in.Symbol,
+				Orderbook: &marketdata.OrderBook{Rows: []*marketdata.OrderBook_Row{
+					{Price: &decimal.Decimal{Value: "250.60"}, Side: &marketdata.OrderBook_Row_SellSize{SellSize: &decimal.Decimal{Value: "4"}}},
+					{Price: &decimal.Decimal{Value: "250.40"}, Side: &marketdata.OrderBook_Row_BuySize{BuySize: &decimal.Decimal{Value: "9"}}},
+				}},
+			}, nil
+		},
+	}
+
+	client := &Client{
+		marketDataClient: mockMarketData,
+		assetMicCache:    map[string]string{"SBER": "SBER@TQBR"},
+		assetLotCache:    map[string]models.Decimal{"SBER": models.DecimalFromInt(10)},
+	}
+
+	book, err := client.GetOrderBook("acc1", "SBER")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if book.Symbol != "SBER@TQBR" {
+		t.Errorf("Expected symbol SBER@TQBR, got %s", book.Symbol)
+	}
+	if spread, ok := book.Spread(); !ok || !spread.Equal(models.DecimalOf("0.2")) {
+		t.Errorf("Expected spread 0.20, got %v (ok=%v)", spread, ok)
+	}
+}
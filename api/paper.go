@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"finam-terminal/api/testserver"
+)
+
+// paperTickInterval is how often the synthetic feed of the paper server moves prices.
+const paperTickInterval = 1 * time.Second
+
+// NewPaperClient starts an in-process paper-trading server with a fresh simulator and a
+// synthetic quote feed, and returns a client connected to it. No network access or API
+// token is needed. Closing the client stops the server; the paper account is not kept.
+func NewPaperClient() (*Client, error) {
+	sim := testserver.NewPaperSimulator(testserver.PaperCash)
+	ts := testserver.NewPaperServer(sim)
+
+	client, err := startPaperClient(ts, func(ctx context.Context) {
+		sim.RunSynthetic(ctx, paperTickInterval, uint64(time.Now().UnixNano()))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[INFO] Paper trading: account %s with %d RUB, simulated quotes every %v",
+		testserver.PaperAccountID, testserver.PaperCash, paperTickInterval)
+	return client, nil
+}
+
+// NewReplayClient is NewPaperClient with market data played back from the journal at path
+// instead of the synthetic feed. speed divides the recorded pauses; 0 plays the journal as
+// fast as possible. Paper orders fill against the replayed quotes.
+func NewReplayClient(path string, speed float64) (*Client, error) {
+	replay, err := testserver.LoadReplay(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := testserver.NewSimulator()
+	sim.AddAccount(testserver.PaperAccountID, testserver.PaperCash)
+	replay.OnQuote = sim.SetQuote
+	ts := testserver.NewPaperServer(sim)
+	ts.MarketData.Replay = replay
+
+	client, err := startPaperClient(ts, func(ctx context.Context) {
+		start := time.Now()
+		if err := replay.Play(ctx, speed); err != nil {
+			return
+		}
+		log.Printf("[INFO] Replay of %s finished in %v", path, time.Since(start).Round(time.Millisecond))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[INFO] Replaying %d market data records from %s (symbols: %v)", replay.Len(), path, replay.Symbols())
+	return client, nil
+}
+
+// startPaperClient starts ts with feed running in the background and connects a client to
+// it. Closing the client stops the feed and the server.
+func startPaperClient(ts *testserver.TestServer, feed func(ctx context.Context)) (*Client, error) {
+	ts.Start()
+
+	feedCtx, stopFeed := context.WithCancel(context.Background())
+	go feed(feedCtx)
+
+	shutdown := func() {
+		stopFeed()
+		ts.Stop()
+	}
+
+	conn, err := ts.Dial(context.Background())
+	if err != nil {
+		shutdown()
+		return nil, fmt.Errorf("failed to connect to paper server: %w", err)
+	}
+
+	client, err := newClientFromConn(conn, testserver.PaperToken)
+	if err != nil {
+		_ = conn.Close()
+		shutdown()
+		return nil, err
+	}
+	client.onClose = shutdown
+	return client, nil
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+
+	"finam-terminal/api/journal"
+	"finam-terminal/models"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+)
+
+// quoteStream holds the state of the single long-lived SubscribeQuote stream.
+type quoteStream struct {
+	mu      sync.Mutex
+	symbols []string // sorted, de-duplicated full symbols of the active subscription
+	handler func(models.Quote)
+	cancel  context.CancelFunc
+	last    map[string]models.Quote // last merged quote per symbol
+}
+
+// SetQuoteHandler registers the callback that receives streamed quote updates.
+// The handler is called from the stream goroutine, not from the UI thread.
+func (c *Client) SetQuoteHandler(handler func(models.Quote)) {
+	c.quotes.mu.Lock()
+	defer c.quotes.mu.Unlock()
+	c.quotes.handler = handler
+}
+
+// SubscribeQuotes replaces the set of streamed symbols. All symbols share one
+// SubscribeQuote stream; when the set changes the stream is reopened with the
+// new set, and an empty set closes it. Calling it with an unchanged set is a no-op.
+func (c *Client) SubscribeQuotes(accountID string, symbols []string) {
+	resolved := make([]string, 0, len(symbols))
+	for _, s := range symbols {
+		if s == "" {
+			continue
+		}
+		full := c.getFullSymbol(s, accountID)
+		if !strings.Contains(full, "@") {
+			continue
+		}
+		resolved = append(resolved, full)
+	}
+	slices.Sort(resolved)
+	resolved = slices.Compact(resolved)
+
+	c.quotes.mu.Lock()
+	defer c.quotes.mu.Unlock()
+
+	if slices.Equal(resolved, c.quotes.symbols) && (c.quotes.cancel != nil || len(resolved) == 0) {
+		return
+	}
+
+	if c.quotes.cancel != nil {
+		c.quotes.cancel()
+		c.quotes.cancel = nil
+	}
+	c.quotes.symbols = resolved
+
+	if len(resolved) == 0 {
+		log.Printf("[INFO] Quote stream closed: no symbols in view")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.quotes.cancel = cancel
+	go c.runQuoteStream(ctx, resolved)
+}
+
+// stopQuoteStream cancels the active quote stream, if any.
+func (c *Client) stopQuoteStream() {
+	c.quotes.mu.Lock()
+	defer c.quotes.mu.Unlock()
+	if c.quotes.cancel != nil {
+		c.quotes.cancel()
+		c.quotes.cancel = nil
+	}
+	c.quotes.symbols = nil
+}
+
+// runQuoteStream keeps a SubscribeQuote stream open until ctx is cancelled.
+func (c *Client) runQuoteStream(ctx context.Context, symbols []string) {
+	log.Printf("[INFO] Quote stream started for %d symbols", len(symbols))
+	c.keepStream(ctx, "Quote", func(ctx context.Context) (bool, error) {
+		return c.consumeQuoteStream(ctx, symbols)
+	})
+}
+
+// consumeQuoteStream opens one SubscribeQuote stream and delivers updates until it ends.
+// It reports whether at least one message was received, so the caller can reset its backoff.
+func (c *Client) consumeQuoteStream(ctx context.Context, symbols []string) (bool, error) {
+	stream, err := c.marketDataClient.SubscribeQuote(c.getStreamContext(ctx), &marketdata.SubscribeQuoteRequest{
+		Symbols: symbols,
+	})
+	if err != nil {
+		c.logGRPCError("MarketDataService", "SubscribeQuote", err, fmt.Sprintf("Symbols: %d", len(symbols)))
+		return false, err
+	}
+
+	received := false
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return received, fmt.Errorf("stream closed by server")
+			}
+			if ctx.Err() == nil {
+				c.logGRPCError("MarketDataService", "SubscribeQuote", err, fmt.Sprintf("Symbols: %d", len(symbols)))
+			}
+			return received, err
+		}
+		received = true
+
+		if e := resp.GetError(); e != nil {
+			log.Printf("[WARN] Quote stream error: code=%d %s", e.GetCode(), e.GetDescription())
+		}
+		for _, q := range resp.GetQuote() {
+			if q == nil || q.Symbol == "" {
+				continue
+			}
+			c.record(journal.KindQuote, q.Symbol, 0, q)
+			c.deliverQuote(*quoteFromProto(q))
+		}
+	}
+}
+
+// deliverQuote merges an update with the last known quote for the symbol and passes it to the handler.
+func (c *Client) deliverQuote(update models.Quote) {
+	c.quotes.mu.Lock()
+	if c.quotes.last == nil {
+		c.quotes.last = make(map[string]models.Quote)
+	}
+	merged := update
+	if prev, ok := c.quotes.last[update.Symbol]; ok {
+		merged = mergeQuote(prev, update)
+	}
+	c.quotes.last[update.Symbol] = merged
+	handler := c.quotes.handler
+	c.quotes.mu.Unlock()
+
+	if handler != nil {
+		handler(merged)
+	}
+}
+
+// mergeQuote overlays the fields present in update on top of prev.
+// Streams may omit unchanged fields, which arrive here as NA.
+func mergeQuote(prev, update models.Quote) models.Quote {
+	pick := func(old, cur models.Decimal) models.Decimal {
+		if !cur.Known() {
+			return old
+		}
+		return cur
+	}
+	merged := models.Quote{
+		Symbol:       update.Symbol,
+		Bid:          pick(prev.Bid, update.Bid),
+		BidSize:      pick(prev.BidSize, update.BidSize),
+		Ask:          pick(prev.Ask, update.Ask),
+		AskSize:      pick(prev.AskSize, update.AskSize),
+		Last:         pick(prev.Last, update.Last),
+		LastSize:     pick(prev.LastSize, update.LastSize),
+		Volume:       pick(prev.Volume, update.Volume),
+		Open:         pick(prev.Open, update.Open),
+		High:         pick(prev.High, update.High),
+		Low:          pick(prev.Low, update.Low),
+		Close:        pick(prev.Close, update.Close),
+		OpenInterest: pick(prev.OpenInterest, update.OpenInterest),
+		Timestamp:    update.Timestamp,
+	}
+	// A missing timestamp converts to the Unix epoch rather than the zero time.
+	if merged.Timestamp.IsZero() || merged.Timestamp.Unix() == 0 {
+		merged.Timestamp = prev.Timestamp
+	}
+	return merged
+}
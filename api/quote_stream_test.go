@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"finam-terminal/models"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+	"google.golang.org/genproto/googleapis/type/decimal"
+	"google.golang.org/grpc"
+)
+
+// mockQuoteStream replays canned responses and then returns io.EOF.
+type mockQuoteStream struct {
+	grpc.ClientStream
+	responses []*marketdata.SubscribeQuoteResponse
+}
+
+func (s *mockQuoteStream) Recv() (*marketdata.SubscribeQuoteResponse, error) {
+	if len(s.responses) == 0 {
+		return nil, io.EOF
+	}
+	resp := s.responses[0]
+	s.responses = s.responses[1:]
+	return resp, nil
+}
+
+func TestSubscribeQuotes_DeliversMergedUpdates(t *testing.T) {
+	var gotSymbols []string
+	mockMarketData := &mockMarketDataServiceClient{
+		SubscribeQuoteFunc: func(ctx context.Context, in *marketdata.SubscribeQuoteRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[marketdata.SubscribeQuoteResponse], error) {
+			gotSymbols = in.Symbols
+			return &mockQuoteStream{responses: []*marketdata.SubscribeQuoteResponse{
+				{Quote: []*marketdata.Quote{{
+					Symbol: "SBER@TQBR",
+					Bid:    &decimal.Decimal{Value: "250.40"},
+					Ask:    &decimal.Decimal{Value: "250.60"},
+					Last:   &decimal.Decimal{Value: "250.50"},
+				}}},
+				// Partial update: only the last price changed
+				{Quote: []*marketdata.Quote{{
+					Symbol: "SBER@TQBR",
+					Last:   &decimal.Decimal{Value: "251.00"},
+				}}},
+			}}, nil
+		},
+	}
+
+	client := &Client{
+		marketDataClient: mockMarketData,
+		assetMicCache:    map[string]string{"SBER": "SBER@TQBR"},
+		assetLotCache:    map[string]models.Decimal{"SBER": models.DecimalFromInt(10), "SBER@TQBR": models.DecimalFromInt(10)},
+	}
+	defer client.stopQuoteStream()
+
+	var mu sync.Mutex
+	var received []models.Quote
+	done := make(chan struct{})
+	client.SetQuoteHandler(func(q models.Quote) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, q)
+		if len(received) == 2 {
+			close(done)
+		}
+	})
+
+	client.SubscribeQuotes("acc1", []string{"SBER", "SBER@TQBR"})
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for streamed quotes")
+	}
+
+	if len(gotSymbols) != 1 || gotSymbols[0] != "SBER@TQBR" {
+		t.Errorf("Expected subscription to [SBER@TQBR], got %v", gotSymbols)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	last := received[1]
+	if last.Last.String() != "251.00" {
+		t.Errorf("Expected Last 251.00, got %s", last.Last)
+	}
+	if last.Bid.String() != "250.40" || last.Ask.String() != "250.60" {
+		t.Errorf("Expected Bid/Ask to be kept from previous update, got %s/%s", last.Bid, last.Ask)
+	}
+}
+
+func TestSubscribeQuotes_UnchangedSetIsNoop(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	mockMarketData := &mockMarketDataServiceClient{
+		SubscribeQuoteFunc: func(ctx context.Context, in *marketdata.SubscribeQuoteRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[marketdata.SubscribeQuoteResponse], error) {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	client := &Client{
+		marketDataClient: mockMarketData,
+		assetMicCache:    map[string]string{"SBER": "SBER@TQBR", "GAZP": "GAZP@TQBR"},
+		assetLotCache:    map[string]models.Decimal{"SBER": models.DecimalFromInt(10), "GAZP": models.DecimalFromInt(10), "GAZP@TQBR": models.DecimalFromInt(10)},
+	}
+	defer client.stopQuoteStream()
+
+	client.SubscribeQuotes("acc1", []string{"SBER", "GAZP"})
+	client.SubscribeQuotes("acc1", []string{"GAZP@TQBR", "SBER"})
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 1 {
+		t.Errorf("Expected 1 SubscribeQuote call for an unchanged set, got %d", calls)
+	}
+}
+
+func TestMergeQuote(t *testing.T) {
+	ts := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	prev := models.Quote{Symbol: "SBER@TQBR", Bid: models.DecimalOf("250.40"), Ask: models.DecimalOf("250.60"), Last: models.DecimalOf("250.50"), Volume: models.DecimalOf("1000"), Timestamp: ts}
+	update := models.Quote{Symbol: "SBER@TQBR", Bid: models.NA, Ask: models.DecimalOf("250.70"), Last: models.NA, Volume: models.DecimalOf("1200"), Timestamp: time.Unix(0, 0)}
+
+	merged := mergeQuote(prev, update)
+
+	if merged.Bid.String() != "250.40" {
+		t.Errorf("Expected Bid 250.40, got %s", merged.Bid)
+	}
+	if merged.Ask.String() != "250.70" {
+		t.Errorf("Expected Ask 250.70, got %s", merged.Ask)
+	}
+	if merged.Last.String() != "250.50" {
+		t.Errorf("Expected Last 250.50, got %s", merged.Last)
+	}
+	if merged.Volume.String() != "1200" {
+		t.Errorf("Expected Volume 1200, got %s", merged.Volume)
+	}
+	if !merged.Timestamp.Equal(ts) {
+		t.Errorf("Expected timestamp to be kept, got %v", merged.Timestamp)
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	if got := nextBackoff(streamMinBackoff); got != 2*streamMinBackoff {
+		t.Errorf("Expected %v, got %v", 2*streamMinBackoff, got)
+	}
+	if got := nextBackoff(20 * time.Second); got != streamMaxBackoff {
+		t.Errorf("Expected backoff capped at %v, got %v", streamMaxBackoff, got)
+	}
+}
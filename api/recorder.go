@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"finam-terminal/api/journal"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// recorder writes market-data responses to a journal while recording is on.
+type recorder struct {
+	mu   sync.Mutex
+	w    *journal.Writer
+	path string
+}
+
+// StartRecording writes every quote, candle, order book and exchange print response the
+// client receives from now on to a journal at path, replacing an existing file. The journal
+// can be played back with testserver.LoadReplay. A recording already in progress is stopped.
+func (c *Client) StartRecording(path string) error {
+	w, err := journal.Create(path)
+	if err != nil {
+		return err
+	}
+
+	c.rec.mu.Lock()
+	prev := c.rec.w
+	c.rec.w, c.rec.path = w, path
+	c.rec.mu.Unlock()
+
+	if prev != nil {
+		if err := prev.Close(); err != nil {
+			log.Printf("[WARN] Failed to close previous market data journal: %v", err)
+		}
+	}
+	log.Printf("[INFO] Recording market data to %s", path)
+	return nil
+}
+
+// StopRecording flushes and closes the journal. It does nothing when not recording.
+func (c *Client) StopRecording() error {
+	c.rec.mu.Lock()
+	w, path := c.rec.w, c.rec.path
+	c.rec.w = nil
+	c.rec.mu.Unlock()
+
+	if w == nil {
+		return nil
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to close market data journal: %w", err)
+	}
+	log.Printf("[INFO] Market data recording saved to %s", path)
+	return nil
+}
+
+// record appends a response to the journal when recording is on. Records are flushed one
+// by one, so a crash loses at most the record being written. A write failure stops the
+// recording instead of failing the request.
+func (c *Client) record(kind journal.Kind, symbol string, timeframe int32, m proto.Message) {
+	c.rec.mu.Lock()
+	defer c.rec.mu.Unlock()
+	if c.rec.w == nil || m == nil {
+		return
+	}
+
+	err := c.rec.w.WriteMessage(kind, time.Now(), symbol, timeframe, m)
+	if err == nil {
+		err = c.rec.w.Flush()
+	}
+	if err != nil {
+		log.Printf("[WARN] Market data recording to %s stopped: %v", c.rec.path, err)
+		_ = c.rec.w.Close()
+		c.rec.w = nil
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// streamMinBackoff is the delay before the first resubscribe attempt after a stream drops.
+	streamMinBackoff = 1 * time.Second
+	// streamMaxBackoff caps the exponential resubscribe delay.
+	streamMaxBackoff = 30 * time.Second
+)
+
+// keepStream runs consume until ctx is cancelled, resubscribing with exponential
+// backoff whenever the stream drops. consume reports whether it received at least
+// one message, which resets the backoff.
+func (c *Client) keepStream(ctx context.Context, name string, consume func(ctx context.Context) (bool, error)) {
+	backoff := streamMinBackoff
+	for {
+		received, err := consume(ctx)
+		if ctx.Err() != nil {
+			log.Printf("[INFO] %s stream stopped", name)
+			return
+		}
+		if received {
+			backoff = streamMinBackoff
+		}
+		log.Printf("[WARN] %s stream dropped: %v. Resubscribing in %v", name, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("[INFO] %s stream stopped", name)
+			return
+		case <-time.After(backoff):
+		}
+		backoff = nextBackoff(backoff)
+	}
+}
+
+// nextBackoff doubles the delay up to streamMaxBackoff.
+func nextBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if d > streamMaxBackoff {
+		return streamMaxBackoff
+	}
+	return d
+}
+
+// getStreamContext returns a context with authentication metadata and no deadline,
+// for long-lived server streams. The stream is bound to parent for cancellation.
+func (c *Client) getStreamContext(parent context.Context) context.Context {
+	c.tokenMutex.RLock()
+	defer c.tokenMutex.RUnlock()
+	return metadata.AppendToOutgoingContext(parent, "Authorization", c.token)
+}
@@ -18,8 +18,15 @@ type MockAccountsServer struct {
 	// TradeHistory keyed by account ID.
 	TradeHistory map[string][]*tradeapiv1.AccountTrade
 
+	// CashTransactions keyed by account ID.
+	CashTransactions map[string][]*accounts.Transaction
+
 	// GetAccountError, if set, is returned by GetAccount.
 	GetAccountError error
+
+	// Sim, if set, serves accounts and trades from the paper-trading simulator
+	// instead of the canned data above.
+	Sim *Simulator
 }
 
 // NewMockAccountsServer creates a MockAccountsServer with default data.
@@ -39,6 +46,9 @@ func (m *MockAccountsServer) GetAccount(_ context.Context, req *accounts.GetAcco
 	if m.GetAccountError != nil {
 		return nil, m.GetAccountError
 	}
+	if m.Sim != nil {
+		return m.Sim.Account(req.AccountId)
+	}
 
 	positions := m.Positions[req.AccountId]
 	return &accounts.GetAccountResponse{
@@ -50,8 +60,26 @@ func (m *MockAccountsServer) GetAccount(_ context.Context, req *accounts.GetAcco
 
 // Trades returns trade history.
 func (m *MockAccountsServer) Trades(_ context.Context, req *accounts.TradesRequest) (*accounts.TradesResponse, error) {
+	if m.Sim != nil {
+		trades, err := m.Sim.Trades(req.AccountId)
+		if err != nil {
+			return nil, err
+		}
+		return &accounts.TradesResponse{Trades: trades}, nil
+	}
+
 	trades := m.TradeHistory[req.AccountId]
 	return &accounts.TradesResponse{
 		Trades: trades,
 	}, nil
 }
+
+// Transactions returns cash transactions. The simulator keeps none.
+func (m *MockAccountsServer) Transactions(_ context.Context, req *accounts.TransactionsRequest) (*accounts.TransactionsResponse, error) {
+	if m.Sim != nil {
+		return &accounts.TransactionsResponse{}, nil
+	}
+	return &accounts.TransactionsResponse{
+		Transactions: m.CashTransactions[req.AccountId],
+	}, nil
+}
@@ -2,8 +2,10 @@ package testserver
 
 import (
 	"context"
+	"slices"
 
 	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -14,6 +16,14 @@ type MockMarketDataServer struct {
 
 	// QuoteOverride, if set, is called instead of the default behavior.
 	QuoteOverride func(ctx context.Context, req *marketdata.QuoteRequest) (*marketdata.QuoteResponse, error)
+
+	// Sim, if set, serves quotes, order books, prints and candles from the
+	// paper-trading simulator. QuoteOverride still takes precedence.
+	Sim *Simulator
+
+	// Replay, if set, serves market data from a played-back journal instead of the
+	// simulator. QuoteOverride still takes precedence.
+	Replay *Replayer
 }
 
 // NewMockMarketDataServer creates a MockMarketDataServer with defaults.
@@ -28,6 +38,12 @@ func (m *MockMarketDataServer) LastQuote(ctx context.Context, req *marketdata.Qu
 	}
 
 	q := DefaultQuote(req.Symbol)
+	switch {
+	case m.Replay != nil:
+		q = m.Replay.Quote(req.Symbol)
+	case m.Sim != nil:
+		q = m.Sim.Quote(req.Symbol)
+	}
 	if q == nil {
 		return nil, status.Errorf(codes.NotFound, "quote not found for %s", req.Symbol)
 	}
@@ -40,8 +56,151 @@ func (m *MockMarketDataServer) LastQuote(ctx context.Context, req *marketdata.Qu
 // Bars returns candlestick data for the requested symbol.
 func (m *MockMarketDataServer) Bars(_ context.Context, req *marketdata.BarsRequest) (*marketdata.BarsResponse, error) {
 	bars := DefaultBars(req.Symbol)
+	from, to := req.GetInterval().GetStartTime().AsTime(), req.GetInterval().GetEndTime().AsTime()
+	switch {
+	case m.Replay != nil:
+		bars = m.Replay.Bars(req.Symbol, req.Timeframe, from, to)
+	case m.Sim != nil:
+		bars = m.Sim.Bars(req.Symbol, req.Timeframe, from, to)
+	}
 	return &marketdata.BarsResponse{
 		Symbol: req.Symbol,
 		Bars:   bars,
 	}, nil
 }
+
+// OrderBook returns the depth of market for the requested symbol.
+func (m *MockMarketDataServer) OrderBook(_ context.Context, req *marketdata.OrderBookRequest) (*marketdata.OrderBookResponse, error) {
+	book := DefaultOrderBook(req.Symbol)
+	switch {
+	case m.Replay != nil:
+		book = m.Replay.OrderBook(req.Symbol)
+	case m.Sim != nil:
+		book = m.Sim.OrderBook(req.Symbol)
+	}
+	if book == nil {
+		return nil, status.Errorf(codes.NotFound, "order book not found for %s", req.Symbol)
+	}
+	return &marketdata.OrderBookResponse{
+		Symbol:    req.Symbol,
+		Orderbook: book,
+	}, nil
+}
+
+// LatestTrades returns recent exchange prints for the requested symbol.
+func (m *MockMarketDataServer) LatestTrades(_ context.Context, req *marketdata.LatestTradesRequest) (*marketdata.LatestTradesResponse, error) {
+	if m.Replay != nil {
+		return &marketdata.LatestTradesResponse{
+			Symbol: req.Symbol,
+			Trades: m.Replay.LatestTrades(req.Symbol),
+		}, nil
+	}
+	if m.Sim != nil {
+		return &marketdata.LatestTradesResponse{
+			Symbol: req.Symbol,
+			Trades: m.Sim.LatestTrades(req.Symbol),
+		}, nil
+	}
+	return &marketdata.LatestTradesResponse{
+		Symbol: req.Symbol,
+		Trades: DefaultLatestTrades(req.Symbol),
+	}, nil
+}
+
+// SubscribeQuote sends one quote per known requested symbol and keeps the stream open
+// until the client cancels it. With a simulator or a replay every later quote change is
+// streamed too. During a replay, symbols without a quote yet are streamed once one is played.
+func (m *MockMarketDataServer) SubscribeQuote(req *marketdata.SubscribeQuoteRequest, stream grpc.ServerStreamingServer[marketdata.SubscribeQuoteResponse]) error {
+	quote := DefaultQuote
+	switch {
+	case m.Replay != nil:
+		quote = m.Replay.Quote
+	case m.Sim != nil:
+		quote = m.Sim.Quote
+	}
+	var quotes []*marketdata.Quote
+	for _, sym := range req.Symbols {
+		if q := quote(sym); q != nil {
+			quotes = append(quotes, q)
+		}
+	}
+	if len(quotes) == 0 && m.Replay == nil {
+		return status.Errorf(codes.NotFound, "no quotes for %v", req.Symbols)
+	}
+	if len(quotes) > 0 {
+		if err := stream.Send(&marketdata.SubscribeQuoteResponse{Quote: quotes}); err != nil {
+			return err
+		}
+	}
+	if m.Replay != nil {
+		return m.Replay.stream(stream.Context(), func(ev replayEvent) error {
+			if ev.quote == nil || !slices.Contains(req.Symbols, ev.quote.Symbol) {
+				return nil
+			}
+			return stream.Send(&marketdata.SubscribeQuoteResponse{Quote: []*marketdata.Quote{ev.quote}})
+		})
+	}
+	if m.Sim != nil {
+		return m.Sim.stream(stream.Context(), func(ev simEvent) error {
+			if ev.quote == nil || !slices.Contains(req.Symbols, ev.quote.Symbol) {
+				return nil
+			}
+			return stream.Send(&marketdata.SubscribeQuoteResponse{Quote: []*marketdata.Quote{ev.quote}})
+		})
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+// SubscribeOrderBook streams changes of the simulated or replayed order book. Without
+// either the book never changes, so the stream only stays open until the client cancels it.
+func (m *MockMarketDataServer) SubscribeOrderBook(req *marketdata.SubscribeOrderBookRequest, stream grpc.ServerStreamingServer[marketdata.SubscribeOrderBookResponse]) error {
+	if m.Replay != nil {
+		return m.Replay.stream(stream.Context(), func(ev replayEvent) error {
+			if ev.bookSymbol != req.Symbol || len(ev.bookRows) == 0 {
+				return nil
+			}
+			return stream.Send(&marketdata.SubscribeOrderBookResponse{
+				OrderBook: []*marketdata.StreamOrderBook{{Symbol: req.Symbol, Rows: ev.bookRows}},
+			})
+		})
+	}
+	if m.Sim == nil {
+		<-stream.Context().Done()
+		return nil
+	}
+	return m.Sim.StreamOrderBook(stream.Context(), req.Symbol, func(rows []*marketdata.StreamOrderBook_Row) error {
+		return stream.Send(&marketdata.SubscribeOrderBookResponse{
+			OrderBook: []*marketdata.StreamOrderBook{{Symbol: req.Symbol, Rows: rows}},
+		})
+	})
+}
+
+// SubscribeLatestTrades streams the simulated or replayed exchange prints for the symbol.
+// Without either it only stays open until the client cancels it.
+func (m *MockMarketDataServer) SubscribeLatestTrades(req *marketdata.SubscribeLatestTradesRequest, stream grpc.ServerStreamingServer[marketdata.SubscribeLatestTradesResponse]) error {
+	if m.Replay != nil {
+		return m.Replay.stream(stream.Context(), func(ev replayEvent) error {
+			if ev.printSymbol != req.Symbol || len(ev.prints) == 0 {
+				return nil
+			}
+			return stream.Send(&marketdata.SubscribeLatestTradesResponse{
+				Symbol: req.Symbol,
+				Trades: ev.prints,
+			})
+		})
+	}
+	if m.Sim == nil {
+		<-stream.Context().Done()
+		return nil
+	}
+	return m.Sim.stream(stream.Context(), func(ev simEvent) error {
+		if ev.print == nil || ev.printSymbol != req.Symbol {
+			return nil
+		}
+		return stream.Send(&marketdata.SubscribeLatestTradesResponse{
+			Symbol: req.Symbol,
+			Trades: []*marketdata.Trade{ev.print},
+		})
+	})
+}
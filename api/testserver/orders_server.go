@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"sync"
 
+	tradeapiv1 "github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1"
 	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/orders"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -32,6 +34,16 @@ type MockOrdersServer struct {
 	// CancelOrderError, if set, is returned by CancelOrder.
 	CancelOrderError error
 
+	// StreamedOrders keyed by account ID are sent once by SubscribeOrders.
+	StreamedOrders map[string][]*orders.OrderState
+
+	// StreamedTrades keyed by account ID are sent once by SubscribeTrades.
+	StreamedTrades map[string][]*tradeapiv1.AccountTrade
+
+	// Sim, if set, executes orders in the paper-trading simulator. Requests are
+	// still recorded above.
+	Sim *Simulator
+
 	Mu          sync.Mutex
 	nextOrderID int
 }
@@ -54,6 +66,10 @@ func (m *MockOrdersServer) PlaceOrder(_ context.Context, req *orders.Order) (*or
 
 	m.Mu.Lock()
 	m.RecordedOrders = append(m.RecordedOrders, req)
+	if m.Sim != nil {
+		m.Mu.Unlock()
+		return m.Sim.PlaceOrder(req)
+	}
 	orderID := m.nextOrderID
 	m.nextOrderID++
 	m.Mu.Unlock()
@@ -69,6 +85,10 @@ func (m *MockOrdersServer) PlaceOrder(_ context.Context, req *orders.Order) (*or
 func (m *MockOrdersServer) PlaceSLTPOrder(_ context.Context, req *orders.SLTPOrder) (*orders.OrderState, error) {
 	m.Mu.Lock()
 	m.RecordedSLTPOrders = append(m.RecordedSLTPOrders, req)
+	if m.Sim != nil {
+		m.Mu.Unlock()
+		return m.Sim.PlaceSLTPOrder(req)
+	}
 	orderID := m.nextOrderID
 	m.nextOrderID++
 	m.Mu.Unlock()
@@ -88,6 +108,9 @@ func (m *MockOrdersServer) CancelOrder(_ context.Context, req *orders.CancelOrde
 	m.Mu.Lock()
 	m.RecordedCancellations = append(m.RecordedCancellations, req)
 	m.Mu.Unlock()
+	if m.Sim != nil {
+		return m.Sim.CancelOrder(req.AccountId, req.OrderId)
+	}
 
 	return &orders.OrderState{
 		OrderId: req.OrderId,
@@ -97,6 +120,14 @@ func (m *MockOrdersServer) CancelOrder(_ context.Context, req *orders.CancelOrde
 
 // GetOrders returns active orders for the account.
 func (m *MockOrdersServer) GetOrders(_ context.Context, req *orders.OrdersRequest) (*orders.OrdersResponse, error) {
+	if m.Sim != nil {
+		states, err := m.Sim.Orders(req.AccountId)
+		if err != nil {
+			return nil, err
+		}
+		return &orders.OrdersResponse{Orders: states}, nil
+	}
+
 	activeOrders := m.ActiveOrders[req.AccountId]
 	if activeOrders == nil {
 		return nil, status.Errorf(codes.NotFound, "no orders for account %s", req.AccountId)
@@ -105,3 +136,53 @@ func (m *MockOrdersServer) GetOrders(_ context.Context, req *orders.OrdersReques
 		Orders: activeOrders,
 	}, nil
 }
+
+// SubscribeOrders sends the streamed order updates for the account and keeps the stream
+// open until the client cancels it. With a simulator it streams every order state change.
+func (m *MockOrdersServer) SubscribeOrders(req *orders.SubscribeOrdersRequest, stream grpc.ServerStreamingServer[orders.SubscribeOrdersResponse]) error {
+	if m.Sim != nil {
+		return m.Sim.stream(stream.Context(), func(ev simEvent) error {
+			if ev.order == nil || ev.account != req.AccountId {
+				return nil
+			}
+			return stream.Send(&orders.SubscribeOrdersResponse{Orders: []*orders.OrderState{ev.order}})
+		})
+	}
+
+	m.Mu.Lock()
+	updates := m.StreamedOrders[req.AccountId]
+	m.Mu.Unlock()
+
+	if len(updates) > 0 {
+		if err := stream.Send(&orders.SubscribeOrdersResponse{Orders: updates}); err != nil {
+			return err
+		}
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+// SubscribeTrades sends the streamed trades for the account and keeps the stream
+// open until the client cancels it. With a simulator it streams every fill.
+func (m *MockOrdersServer) SubscribeTrades(req *orders.SubscribeTradesRequest, stream grpc.ServerStreamingServer[orders.SubscribeTradesResponse]) error {
+	if m.Sim != nil {
+		return m.Sim.stream(stream.Context(), func(ev simEvent) error {
+			if ev.trade == nil || ev.account != req.AccountId {
+				return nil
+			}
+			return stream.Send(&orders.SubscribeTradesResponse{Trades: []*tradeapiv1.AccountTrade{ev.trade}})
+		})
+	}
+
+	m.Mu.Lock()
+	trades := m.StreamedTrades[req.AccountId]
+	m.Mu.Unlock()
+
+	if len(trades) > 0 {
+		if err := stream.Send(&orders.SubscribeTradesResponse{Trades: trades}); err != nil {
+			return err
+		}
+	}
+	<-stream.Context().Done()
+	return nil
+}
@@ -0,0 +1,39 @@
+package testserver
+
+const (
+	// PaperToken is the API token accepted by a paper-trading server.
+	PaperToken = "paper-api-token"
+
+	// PaperAccountID is the account of the default paper simulator.
+	PaperAccountID = "PAPER"
+
+	// PaperCash is the starting cash balance of the default paper account.
+	PaperCash = 1_000_000
+)
+
+// NewPaperSimulator returns a simulator with one PaperAccountID account funded with cash
+// and the instruments of DefaultAssets at realistic prices.
+func NewPaperSimulator(cash float64) *Simulator {
+	sim := NewSimulator()
+	sim.AddAccount(PaperAccountID, cash)
+	sim.AddInstrument("SBER@TQBR", 285.00, 0.01)
+	sim.AddInstrument("GAZP@TQBR", 160.30, 0.01)
+	sim.AddInstrument("LKOH@TQBR", 7100.0, 0.5)
+	sim.AddInstrument("YNDX@TQBR", 4000.0, 0.5)
+	sim.AddInstrument("ROSN@TQBR", 560.00, 0.05)
+	return sim
+}
+
+// NewPaperServer creates a TestServer whose accounts, orders and market data are served
+// by sim. Authentication accepts PaperToken and lists the simulator's accounts; assets
+// keep the default instrument list.
+func NewPaperServer(sim *Simulator) *TestServer {
+	ts := NewTestServer()
+	ts.Sim = sim
+	ts.Auth.ValidTokens[PaperToken] = true
+	ts.Auth.AccountIDs = sim.AccountIDs()
+	ts.Accounts.Sim = sim
+	ts.MarketData.Sim = sim
+	ts.Orders.Sim = sim
+	return ts
+}
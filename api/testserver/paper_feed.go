@@ -0,0 +1,372 @@
+package testserver
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// bookDepth is the number of price levels per side in a simulated order book.
+const bookDepth = 5
+
+// maxBars caps the number of synthetic candles returned by Bars.
+const maxBars = 500
+
+// simInstrument is the state of the synthetic random walk for one symbol.
+type simInstrument struct {
+	tick      float64
+	prevClose float64
+	open      float64
+	high      float64
+	low       float64
+	last      float64
+	volume    float64
+}
+
+// AddInstrument seeds a symbol for the synthetic feed at price, moving in steps of tick.
+// The seed price is also the previous close for daily P&L.
+func (s *Simulator) AddInstrument(symbol string, price, tick float64) {
+	s.mu.Lock()
+	in := &simInstrument{tick: tick, prevClose: price, open: price, high: price, low: price, last: price}
+	s.instruments[symbol] = in
+	q := s.instrumentQuote(symbol, in, 0, price, price+tick)
+	s.mu.Unlock()
+
+	s.SetQuote(q)
+}
+
+// RunSynthetic moves every instrument added with AddInstrument by a random walk, one step
+// per interval, until ctx is cancelled. The same seed gives the same price path.
+func (s *Simulator) RunSynthetic(ctx context.Context, interval time.Duration, seed uint64) {
+	rng := rand.New(rand.NewPCG(seed, seed>>32))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, q := range s.step(rng) {
+				s.SetQuote(q)
+			}
+		}
+	}
+}
+
+// step advances the random walk and returns the quotes of the symbols that traded.
+func (s *Simulator) step(rng *rand.Rand) []*marketdata.Quote {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	symbols := make([]string, 0, len(s.instruments))
+	for sym := range s.instruments {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+
+	var quotes []*marketdata.Quote
+	for _, sym := range symbols {
+		// Roughly every other instrument trades on a given step
+		if rng.IntN(2) == 0 {
+			continue
+		}
+		in := s.instruments[sym]
+		move := float64(rng.IntN(3) - 1)
+		if in.last+move*in.tick > in.tick {
+			in.last = roundTo(in.last+move*in.tick, in.tick)
+		}
+		in.high = math.Max(in.high, in.last)
+		in.low = math.Min(in.low, in.last)
+		size := float64(rng.IntN(20)+1) * 10
+		in.volume += size
+
+		// An up-tick trades at the ask, a down-tick at the bid
+		bid, ask := in.last, in.last+in.tick
+		if move < 0 {
+			bid, ask = in.last-in.tick, in.last
+		}
+		quotes = append(quotes, s.instrumentQuote(sym, in, size, roundTo(bid, in.tick), roundTo(ask, in.tick)))
+	}
+	return quotes
+}
+
+// instrumentQuote builds a quote from the walk state. Called with s.mu held.
+func (s *Simulator) instrumentQuote(symbol string, in *simInstrument, lastSize, bid, ask float64) *marketdata.Quote {
+	q := &marketdata.Quote{
+		Symbol:    symbol,
+		Timestamp: timestamppb.New(s.Now()),
+		Bid:       newDecimal(bid),
+		BidSize:   newDecimal(levelSize(symbol, bid)),
+		Ask:       newDecimal(ask),
+		AskSize:   newDecimal(levelSize(symbol, ask)),
+		Last:      newDecimal(in.last),
+		Volume:    newDecimal(in.volume),
+		Open:      newDecimal(in.open),
+		High:      newDecimal(in.high),
+		Low:       newDecimal(in.low),
+		Close:     newDecimal(in.prevClose),
+		Change:    newDecimal(in.last - in.prevClose),
+	}
+	if lastSize > 0 {
+		q.LastSize = newDecimal(lastSize)
+	}
+	return q
+}
+
+// Replay feeds recorded quotes to the simulator in order, one every interval, and returns
+// when they are exhausted or ctx is cancelled. A zero interval replays as fast as possible.
+func (s *Simulator) Replay(ctx context.Context, quotes []*marketdata.Quote, interval time.Duration) {
+	for _, q := range quotes {
+		if ctx.Err() != nil {
+			return
+		}
+		s.SetQuote(q)
+		if interval <= 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// bookLevel is one price level of a simulated order book.
+type bookLevel struct {
+	price float64
+	buy   bool
+}
+
+// bookLevels derives bookDepth levels per side from the quote, spaced by the instrument tick
+// or, for replayed quotes, by the spread. Sizes are a stable function of the price.
+// Called with s.mu held.
+func (s *Simulator) bookLevels(symbol string) map[bookLevel]float64 {
+	q := s.quotes[symbol]
+	bid, ask := decimalFloat(q.GetBid()), decimalFloat(q.GetAsk())
+	if bid <= 0 || ask <= 0 {
+		return nil
+	}
+	step := ask - bid
+	if in := s.instruments[symbol]; in != nil {
+		step = in.tick
+	}
+	if step <= 0 {
+		step = 0.01
+	}
+
+	levels := make(map[bookLevel]float64, 2*bookDepth)
+	for i := 0; i < bookDepth; i++ {
+		sell := roundTo(ask+float64(i)*step, step)
+		buy := roundTo(bid-float64(i)*step, step)
+		levels[bookLevel{price: sell}] = levelSize(symbol, sell)
+		if buy > 0 {
+			levels[bookLevel{price: buy, buy: true}] = levelSize(symbol, buy)
+		}
+	}
+	return levels
+}
+
+// OrderBook returns the simulated depth of market for symbol, or nil without a quote.
+func (s *Simulator) OrderBook(symbol string) *marketdata.OrderBook {
+	s.mu.Lock()
+	levels := s.bookLevels(symbol)
+	s.mu.Unlock()
+	if levels == nil {
+		return nil
+	}
+	return orderBookFromLevels(levels)
+}
+
+// orderBookFromLevels builds a full order book of ADD rows, highest price first.
+func orderBookFromLevels(levels map[bookLevel]float64) *marketdata.OrderBook {
+	book := &marketdata.OrderBook{}
+	for _, lv := range sortedLevels(levels) {
+		row := &marketdata.OrderBook_Row{
+			Price:  newDecimal(lv.price),
+			Action: marketdata.OrderBook_Row_ACTION_ADD,
+		}
+		if lv.buy {
+			row.Side = &marketdata.OrderBook_Row_BuySize{BuySize: newDecimal(levels[lv])}
+		} else {
+			row.Side = &marketdata.OrderBook_Row_SellSize{SellSize: newDecimal(levels[lv])}
+		}
+		book.Rows = append(book.Rows, row)
+	}
+	return book
+}
+
+// bookDiff returns the rows that turn the prev levels into next.
+func bookDiff(prev, next map[bookLevel]float64) []*marketdata.StreamOrderBook_Row {
+	var rows []*marketdata.StreamOrderBook_Row
+	row := func(lv bookLevel, size float64, action marketdata.StreamOrderBook_Row_Action) {
+		r := &marketdata.StreamOrderBook_Row{Price: newDecimal(lv.price), Action: action}
+		if lv.buy {
+			r.Side = &marketdata.StreamOrderBook_Row_BuySize{BuySize: newDecimal(size)}
+		} else {
+			r.Side = &marketdata.StreamOrderBook_Row_SellSize{SellSize: newDecimal(size)}
+		}
+		rows = append(rows, r)
+	}
+	for _, lv := range sortedLevels(prev) {
+		if _, ok := next[lv]; !ok {
+			row(lv, 0, marketdata.StreamOrderBook_Row_ACTION_REMOVE)
+		}
+	}
+	for _, lv := range sortedLevels(next) {
+		size := next[lv]
+		if old, ok := prev[lv]; !ok {
+			row(lv, size, marketdata.StreamOrderBook_Row_ACTION_ADD)
+		} else if old != size {
+			row(lv, size, marketdata.StreamOrderBook_Row_ACTION_UPDATE)
+		}
+	}
+	return rows
+}
+
+// sortedLevels lists levels from the highest price down, asks before bids at the same price.
+func sortedLevels(levels map[bookLevel]float64) []bookLevel {
+	out := make([]bookLevel, 0, len(levels))
+	for lv := range levels {
+		out = append(out, lv)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].price != out[j].price {
+			return out[i].price > out[j].price
+		}
+		return !out[i].buy && out[j].buy
+	})
+	return out
+}
+
+// StreamOrderBook sends the changes of the simulated book for symbol after every quote
+// until ctx is cancelled. Clients take the starting state from OrderBook.
+func (s *Simulator) StreamOrderBook(ctx context.Context, symbol string, send func([]*marketdata.StreamOrderBook_Row) error) error {
+	s.mu.Lock()
+	prev := s.bookLevels(symbol)
+	s.mu.Unlock()
+
+	return s.stream(ctx, func(ev simEvent) error {
+		if ev.quote == nil || ev.quote.Symbol != symbol {
+			return nil
+		}
+		s.mu.Lock()
+		next := s.bookLevels(symbol)
+		s.mu.Unlock()
+
+		rows := bookDiff(prev, next)
+		prev = next
+		if len(rows) == 0 {
+			return nil
+		}
+		return send(rows)
+	})
+}
+
+// Bars returns synthetic candles for symbol between from and to that end at the current
+// last price. The path is a random walk seeded by the symbol and timeframe, so repeated
+// requests return the same history.
+func (s *Simulator) Bars(symbol string, tf marketdata.TimeFrame, from, to time.Time) []*marketdata.Bar {
+	s.mu.Lock()
+	last := decimalFloat(s.quotes[symbol].GetLast())
+	tick := 0.01
+	if in := s.instruments[symbol]; in != nil {
+		tick = in.tick
+	}
+	now := s.Now()
+	s.mu.Unlock()
+
+	if last <= 0 {
+		return nil
+	}
+	d := timeFrameDuration(tf)
+	if to.IsZero() || to.After(now) {
+		to = now
+	}
+	end := to.Truncate(d)
+	if from.IsZero() {
+		from = end.Add(-maxBars * d)
+	}
+	count := int(end.Sub(from)/d) + 1
+	if count <= 0 {
+		return nil
+	}
+	count = min(count, maxBars)
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(symbol + tf.String()))
+	rng := rand.New(rand.NewPCG(h.Sum64(), uint64(tf)))
+
+	// Walk backwards from the current price so the last candle closes at it
+	vol := math.Max(tick, last*0.004*math.Sqrt(d.Hours()))
+	bars := make([]*marketdata.Bar, count)
+	closePrice := last
+	for i := count - 1; i >= 0; i-- {
+		open := math.Max(tick, roundTo(closePrice+rng.NormFloat64()*vol, tick))
+		high := roundTo(math.Max(open, closePrice)+rng.Float64()*vol/2, tick)
+		low := math.Max(tick, roundTo(math.Min(open, closePrice)-rng.Float64()*vol/2, tick))
+		bars[i] = &marketdata.Bar{
+			Timestamp: timestamppb.New(end.Add(-time.Duration(count-1-i) * d)),
+			Open:      newDecimal(open),
+			High:      newDecimal(high),
+			Low:       newDecimal(low),
+			Close:     newDecimal(closePrice),
+			Volume:    newDecimal(float64(rng.IntN(1000)+1) * 100),
+		}
+		closePrice = open
+	}
+	return bars
+}
+
+// timeFrameDuration approximates the length of one candle.
+func timeFrameDuration(tf marketdata.TimeFrame) time.Duration {
+	switch tf {
+	case marketdata.TimeFrame_TIME_FRAME_M1:
+		return time.Minute
+	case marketdata.TimeFrame_TIME_FRAME_M5:
+		return 5 * time.Minute
+	case marketdata.TimeFrame_TIME_FRAME_M15:
+		return 15 * time.Minute
+	case marketdata.TimeFrame_TIME_FRAME_M30:
+		return 30 * time.Minute
+	case marketdata.TimeFrame_TIME_FRAME_H1:
+		return time.Hour
+	case marketdata.TimeFrame_TIME_FRAME_H2:
+		return 2 * time.Hour
+	case marketdata.TimeFrame_TIME_FRAME_H4:
+		return 4 * time.Hour
+	case marketdata.TimeFrame_TIME_FRAME_H8:
+		return 8 * time.Hour
+	case marketdata.TimeFrame_TIME_FRAME_W:
+		return 7 * 24 * time.Hour
+	case marketdata.TimeFrame_TIME_FRAME_MN:
+		return 30 * 24 * time.Hour
+	case marketdata.TimeFrame_TIME_FRAME_QR:
+		return 91 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// levelSize is the resting size at a price level: a multiple of 10 between 10 and 500
+// derived from the price, so a level keeps its size while it stays in the book.
+func levelSize(symbol string, price float64) float64 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(symbol))
+	_, _ = h.Write([]byte(newDecimal(price).Value))
+	return float64(h.Sum32()%50+1) * 10
+}
+
+// roundTo rounds v to a multiple of step.
+func roundTo(v, step float64) float64 {
+	if step <= 0 {
+		return v
+	}
+	return math.Round(math.Round(v/step)*step*1e6) / 1e6
+}
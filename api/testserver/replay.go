@@ -0,0 +1,387 @@
+package testserver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"finam-terminal/api/journal"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+	"google.golang.org/protobuf/proto"
+)
+
+// replayStep is one decoded journal record.
+type replayStep struct {
+	at        time.Time
+	kind      journal.Kind
+	symbol    string
+	timeframe marketdata.TimeFrame
+	msg       proto.Message
+}
+
+// barsKey identifies a recorded candle series.
+type barsKey struct {
+	symbol string
+	tf     marketdata.TimeFrame
+}
+
+// replayEvent is published to streams when a journal record is played.
+type replayEvent struct {
+	quote       *marketdata.Quote
+	bookSymbol  string
+	bookRows    []*marketdata.StreamOrderBook_Row
+	printSymbol string
+	prints      []*marketdata.Trade
+}
+
+// Replayer plays back a market-data journal written by the client recorder. It keeps the
+// market state as of the last played record: quotes, order books, exchange prints and
+// candles are served from it, and streams receive every change as it is played.
+type Replayer struct {
+	steps []replayStep
+
+	// OnQuote, if set, is called with every played quote, for example Simulator.SetQuote
+	// so that paper orders fill against the recorded prices.
+	OnQuote func(*marketdata.Quote)
+
+	mu          sync.Mutex
+	quotes      map[string]*marketdata.Quote
+	books       map[string]map[bookLevel]float64
+	prints      map[string][]*marketdata.Trade
+	printIDs    map[string]bool
+	bars        map[barsKey][]*marketdata.Bar
+	watchers    map[int]chan replayEvent
+	nextWatcher int
+}
+
+// LoadReplay reads and decodes the journal at path. A record cut short at the end of the
+// file, as left by a killed recorder, is ignored.
+func LoadReplay(path string) (*Replayer, error) {
+	records, err := journal.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := newReplayer()
+	for i, rec := range records {
+		var msg proto.Message
+		switch rec.Kind {
+		case journal.KindQuote:
+			msg = &marketdata.Quote{}
+		case journal.KindBars:
+			msg = &marketdata.BarsResponse{}
+		case journal.KindOrderBook:
+			msg = &marketdata.OrderBook{}
+		case journal.KindBookUpdate:
+			msg = &marketdata.StreamOrderBook{}
+		case journal.KindTrades:
+			msg = &marketdata.LatestTradesResponse{}
+		default:
+			// Written by a newer recorder
+			continue
+		}
+		if err := rec.Unmarshal(msg); err != nil {
+			return nil, fmt.Errorf("%s: record %d: %w", path, i+1, err)
+		}
+		r.steps = append(r.steps, replayStep{
+			at:        rec.Time,
+			kind:      rec.Kind,
+			symbol:    rec.Symbol,
+			timeframe: marketdata.TimeFrame(rec.Timeframe),
+			msg:       msg,
+		})
+	}
+	return r, nil
+}
+
+func newReplayer() *Replayer {
+	return &Replayer{
+		quotes:   make(map[string]*marketdata.Quote),
+		books:    make(map[string]map[bookLevel]float64),
+		prints:   make(map[string][]*marketdata.Trade),
+		printIDs: make(map[string]bool),
+		bars:     make(map[barsKey][]*marketdata.Bar),
+		watchers: make(map[int]chan replayEvent),
+	}
+}
+
+// Len returns the number of records to play.
+func (r *Replayer) Len() int { return len(r.steps) }
+
+// Symbols returns the instruments that have quotes in the journal, sorted.
+func (r *Replayer) Symbols() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, st := range r.steps {
+		if st.kind == journal.KindQuote && st.symbol != "" && !seen[st.symbol] {
+			seen[st.symbol] = true
+			out = append(out, st.symbol)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ParseReplaySpeed parses a playback speed such as "1x" or "10x". "max" plays the journal
+// without pauses and is returned as 0.
+func ParseReplaySpeed(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "max" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "x"), 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid replay speed %q: use 1x, 10x or max", s)
+	}
+	return v, nil
+}
+
+// replayDelay is the pause before a record recorded gap after the previous one.
+// Speed 0 means as fast as possible.
+func replayDelay(gap time.Duration, speed float64) time.Duration {
+	if speed <= 0 || gap <= 0 {
+		return 0
+	}
+	return time.Duration(float64(gap) / speed)
+}
+
+// Play applies the journal records in order, keeping the recorded gaps between them
+// divided by speed; speed 0 plays without pauses. It returns when the journal ends or
+// ctx is cancelled. The final state keeps being served after Play returns.
+func (r *Replayer) Play(ctx context.Context, speed float64) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	for i, st := range r.steps {
+		if i > 0 {
+			if d := replayDelay(st.at.Sub(r.steps[i-1].at), speed); d > 0 {
+				timer.Reset(d)
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		r.apply(st)
+	}
+	return nil
+}
+
+// apply updates the market state with one record and publishes the change.
+func (r *Replayer) apply(st replayStep) {
+	var played *marketdata.Quote
+
+	r.mu.Lock()
+	switch m := st.msg.(type) {
+	case *marketdata.Quote:
+		played = r.applyQuote(m)
+	case *marketdata.BarsResponse:
+		r.applyBars(barsKey{symbol: st.symbol, tf: st.timeframe}, m.GetBars())
+	case *marketdata.OrderBook:
+		r.applyBook(st.symbol, m)
+	case *marketdata.StreamOrderBook:
+		r.applyBookUpdate(st.symbol, m)
+	case *marketdata.LatestTradesResponse:
+		r.applyTrades(st.symbol, m.GetTrades())
+	}
+	r.mu.Unlock()
+
+	if played != nil && r.OnQuote != nil {
+		r.OnQuote(proto.Clone(played).(*marketdata.Quote))
+	}
+}
+
+// applyQuote merges a quote into the last one for its symbol: streamed quotes only carry
+// the fields that changed. Called with r.mu held.
+func (r *Replayer) applyQuote(q *marketdata.Quote) *marketdata.Quote {
+	if q.GetSymbol() == "" {
+		return nil
+	}
+	merged := q
+	if prev := r.quotes[q.Symbol]; prev != nil {
+		merged = proto.Clone(prev).(*marketdata.Quote)
+		proto.Merge(merged, q)
+	}
+	r.quotes[q.Symbol] = merged
+	r.publish(replayEvent{quote: merged})
+	return merged
+}
+
+// applyBars merges recorded candles into the series, replacing candles with the same timestamp.
+// Called with r.mu held.
+func (r *Replayer) applyBars(key barsKey, bars []*marketdata.Bar) {
+	byTime := make(map[int64]*marketdata.Bar, len(r.bars[key])+len(bars))
+	for _, b := range r.bars[key] {
+		byTime[b.GetTimestamp().AsTime().UnixNano()] = b
+	}
+	for _, b := range bars {
+		byTime[b.GetTimestamp().AsTime().UnixNano()] = b
+	}
+	merged := make([]*marketdata.Bar, 0, len(byTime))
+	for _, b := range byTime {
+		merged = append(merged, b)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].GetTimestamp().AsTime().Before(merged[j].GetTimestamp().AsTime())
+	})
+	r.bars[key] = merged
+}
+
+// applyBook replaces the order book with a snapshot and streams the difference.
+// Called with r.mu held.
+func (r *Replayer) applyBook(symbol string, book *marketdata.OrderBook) {
+	next := make(map[bookLevel]float64)
+	for _, row := range book.GetRows() {
+		if row.GetAction() == marketdata.OrderBook_Row_ACTION_REMOVE {
+			continue
+		}
+		if size := decimalFloat(row.GetBuySize()); size > 0 {
+			next[bookLevel{price: decimalFloat(row.GetPrice()), buy: true}] = size
+		} else if size := decimalFloat(row.GetSellSize()); size > 0 {
+			next[bookLevel{price: decimalFloat(row.GetPrice())}] = size
+		}
+	}
+	rows := bookDiff(r.books[symbol], next)
+	r.books[symbol] = next
+	if len(rows) > 0 {
+		r.publish(replayEvent{bookSymbol: symbol, bookRows: rows})
+	}
+}
+
+// applyBookUpdate applies streamed order book rows. Called with r.mu held.
+func (r *Replayer) applyBookUpdate(symbol string, ob *marketdata.StreamOrderBook) {
+	if ob.GetSymbol() != "" {
+		symbol = ob.GetSymbol()
+	}
+	levels := r.books[symbol]
+	if levels == nil {
+		levels = make(map[bookLevel]float64)
+		r.books[symbol] = levels
+	}
+	for _, row := range ob.GetRows() {
+		price := decimalFloat(row.GetPrice())
+		buy := row.GetBuySize() != nil
+		size := decimalFloat(row.GetBuySize())
+		if !buy {
+			size = decimalFloat(row.GetSellSize())
+		}
+		if row.GetAction() == marketdata.StreamOrderBook_Row_ACTION_REMOVE || size <= 0 {
+			delete(levels, bookLevel{price: price, buy: buy})
+			continue
+		}
+		levels[bookLevel{price: price, buy: buy}] = size
+	}
+	if len(ob.GetRows()) > 0 {
+		r.publish(replayEvent{bookSymbol: symbol, bookRows: ob.GetRows()})
+	}
+}
+
+// applyTrades adds prints not seen before. Snapshots and stream batches overlap, so
+// prints are de-duplicated by trade ID. Called with r.mu held.
+func (r *Replayer) applyTrades(symbol string, trades []*marketdata.Trade) {
+	var fresh []*marketdata.Trade
+	for _, t := range trades {
+		if t == nil || (t.GetTradeId() != "" && r.printIDs[symbol+"/"+t.GetTradeId()]) {
+			continue
+		}
+		if t.GetTradeId() != "" {
+			r.printIDs[symbol+"/"+t.GetTradeId()] = true
+		}
+		fresh = append(fresh, t)
+	}
+	if len(fresh) == 0 {
+		return
+	}
+	prints := append(r.prints[symbol], fresh...)
+	if len(prints) > maxPrints {
+		prints = prints[len(prints)-maxPrints:]
+	}
+	r.prints[symbol] = prints
+	r.publish(replayEvent{printSymbol: symbol, prints: fresh})
+}
+
+// Quote returns the last played quote for symbol, or nil when none was played yet.
+func (r *Replayer) Quote(symbol string) *marketdata.Quote {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.quotes[symbol]
+}
+
+// OrderBook returns the played order book for symbol. Before the first book record it is
+// empty rather than missing, so that order book streams can start and fill in later.
+func (r *Replayer) OrderBook(symbol string) *marketdata.OrderBook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return orderBookFromLevels(r.books[symbol])
+}
+
+// LatestTrades returns the played exchange prints for symbol, oldest first.
+func (r *Replayer) LatestTrades(symbol string) []*marketdata.Trade {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*marketdata.Trade(nil), r.prints[symbol]...)
+}
+
+// Bars returns the played candles of the series within [from, to].
+func (r *Replayer) Bars(symbol string, tf marketdata.TimeFrame, from, to time.Time) []*marketdata.Bar {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*marketdata.Bar
+	for _, b := range r.bars[barsKey{symbol: symbol, tf: tf}] {
+		ts := b.GetTimestamp().AsTime()
+		if (from.IsZero() || !ts.Before(from)) && (to.IsZero() || !ts.After(to)) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// watch registers a stream for replay events. The returned function unregisters it.
+func (r *Replayer) watch() (<-chan replayEvent, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := r.nextWatcher
+	r.nextWatcher++
+	ch := make(chan replayEvent, watcherBuffer)
+	r.watchers[id] = ch
+	return ch, func() {
+		r.mu.Lock()
+		delete(r.watchers, id)
+		r.mu.Unlock()
+	}
+}
+
+// publish hands ev to every stream without blocking playback. Called with r.mu held.
+func (r *Replayer) publish(ev replayEvent) {
+	for _, ch := range r.watchers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// stream calls send for every event until ctx is done or send fails.
+func (r *Replayer) stream(ctx context.Context, send func(replayEvent) error) error {
+	events, stop := r.watch()
+	defer stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-events:
+			if err := send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package testserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"finam-terminal/api/journal"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+)
+
+func TestParseReplaySpeed(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"1x", 1, false},
+		{"10x", 10, false},
+		{"MAX", 0, false},
+		{"2.5", 2.5, false},
+		{"0x", 0, true},
+		{"fast", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseReplaySpeed(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseReplaySpeed(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestReplayDelay(t *testing.T) {
+	if d := replayDelay(10*time.Second, 1); d != 10*time.Second {
+		t.Errorf("Expected 10s at 1x, got %v", d)
+	}
+	if d := replayDelay(10*time.Second, 10); d != time.Second {
+		t.Errorf("Expected 1s at 10x, got %v", d)
+	}
+	if d := replayDelay(10*time.Second, 0); d != 0 {
+		t.Errorf("Expected no pause at max speed, got %v", d)
+	}
+	if d := replayDelay(-time.Second, 1); d != 0 {
+		t.Errorf("Expected no pause for out-of-order records, got %v", d)
+	}
+}
+
+func TestReplayer_PlayKeepsRecordedPace(t *testing.T) {
+	t0 := time.Date(2026, 4, 7, 10, 0, 0, 0, time.UTC)
+	trade := func(id string) *marketdata.LatestTradesResponse {
+		return &marketdata.LatestTradesResponse{Trades: []*marketdata.Trade{{TradeId: id, Price: newDecimal(285)}}}
+	}
+	r := newReplayer()
+	r.steps = []replayStep{
+		{at: t0, kind: journal.KindTrades, symbol: "SBER@TQBR", msg: trade("1")},
+		{at: t0.Add(2 * time.Second), kind: journal.KindTrades, symbol: "SBER@TQBR", msg: trade("2")},
+	}
+
+	start := time.Now()
+	if err := r.Play(context.Background(), 20); err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected a 2s gap to take 100ms at 20x, took %v", elapsed)
+	}
+	if n := len(r.LatestTrades("SBER@TQBR")); n != 2 {
+		t.Errorf("Expected 2 prints after replay, got %d", n)
+	}
+
+	// A cancelled replay stops at the next pause
+	r = newReplayer()
+	r.steps = []replayStep{
+		{at: t0, kind: journal.KindTrades, symbol: "SBER@TQBR", msg: trade("1")},
+		{at: t0.Add(time.Hour), kind: journal.KindTrades, symbol: "SBER@TQBR", msg: trade("2")},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.Play(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+	if n := len(r.LatestTrades("SBER@TQBR")); n != 1 {
+		t.Errorf("Expected only the first print before cancellation, got %d", n)
+	}
+}
+
+func TestReplayer_TradesDeduplicated(t *testing.T) {
+	r := newReplayer()
+	events, stop := r.watch()
+	defer stop()
+
+	newPrint := func(id string) *marketdata.Trade { return &marketdata.Trade{TradeId: id, Price: newDecimal(285)} }
+	// A snapshot after a reconnect repeats prints that were already streamed
+	r.apply(replayStep{msg: &marketdata.LatestTradesResponse{Trades: []*marketdata.Trade{newPrint("1"), newPrint("2")}}, symbol: "SBER@TQBR"})
+	r.apply(replayStep{msg: &marketdata.LatestTradesResponse{Trades: []*marketdata.Trade{newPrint("2"), newPrint("3")}}, symbol: "SBER@TQBR"})
+
+	trades := r.LatestTrades("SBER@TQBR")
+	if len(trades) != 3 || trades[2].TradeId != "3" {
+		t.Fatalf("Expected prints 1, 2, 3, got %v", trades)
+	}
+	<-events
+	ev := <-events
+	if len(ev.prints) != 1 || ev.prints[0].TradeId != "3" {
+		t.Errorf("Expected only the new print to be streamed, got %v", ev.prints)
+	}
+}
+
+func TestReplayer_OrderBook(t *testing.T) {
+	r := newReplayer()
+	if book := r.OrderBook("SBER@TQBR"); book == nil || len(book.Rows) != 0 {
+		t.Fatalf("Expected an empty book before the first record, got %v", book)
+	}
+
+	r.apply(replayStep{symbol: "SBER@TQBR", msg: &marketdata.StreamOrderBook{Rows: []*marketdata.StreamOrderBook_Row{
+		{Price: newDecimal(285.1), Action: marketdata.StreamOrderBook_Row_ACTION_ADD,
+			Side: &marketdata.StreamOrderBook_Row_SellSize{SellSize: newDecimal(40)}},
+		{Price: newDecimal(284.9), Action: marketdata.StreamOrderBook_Row_ACTION_ADD,
+			Side: &marketdata.StreamOrderBook_Row_BuySize{BuySize: newDecimal(25)}},
+	}}})
+	r.apply(replayStep{symbol: "SBER@TQBR", msg: &marketdata.StreamOrderBook{Rows: []*marketdata.StreamOrderBook_Row{
+		{Price: newDecimal(285.1), Action: marketdata.StreamOrderBook_Row_ACTION_REMOVE,
+			Side: &marketdata.StreamOrderBook_Row_SellSize{SellSize: newDecimal(0)}},
+	}}})
+
+	book := r.OrderBook("SBER@TQBR")
+	if len(book.Rows) != 1 || decimalFloat(book.Rows[0].GetBuySize()) != 25 {
+		t.Errorf("Expected a single bid of 25, got %v", book.Rows)
+	}
+
+	// A snapshot replaces the book and streams the difference
+	events, stop := r.watch()
+	defer stop()
+	r.apply(replayStep{symbol: "SBER@TQBR", msg: &marketdata.OrderBook{Rows: []*marketdata.OrderBook_Row{
+		{Price: newDecimal(285.2), Action: marketdata.OrderBook_Row_ACTION_ADD,
+			Side: &marketdata.OrderBook_Row_SellSize{SellSize: newDecimal(10)}},
+	}}})
+	ev := <-events
+	if ev.bookSymbol != "SBER@TQBR" || len(ev.bookRows) != 2 {
+		t.Fatalf("Expected a remove and an add row, got %v", ev.bookRows)
+	}
+	if ev.bookRows[0].Action != marketdata.StreamOrderBook_Row_ACTION_REMOVE {
+		t.Errorf("Expected the old bid to be removed first, got %v", ev.bookRows[0].Action)
+	}
+}
@@ -26,6 +26,9 @@ type TestServer struct {
 	MarketData *MockMarketDataServer
 	Assets     *MockAssetsServer
 	Orders     *MockOrdersServer
+
+	// Sim is the paper-trading simulator behind the services, or nil for canned data.
+	Sim *Simulator
 }
 
 // NewTestServer creates a new TestServer with all mock services registered.
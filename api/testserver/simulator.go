@@ -0,0 +1,741 @@
+package testserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	tradeapiv1 "github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1"
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/accounts"
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/orders"
+	"google.golang.org/genproto/googleapis/type/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// watcherBuffer is the number of events a stream may lag behind before it loses updates.
+	watcherBuffer = 1024
+	// maxPrints is the number of exchange prints kept per symbol for LatestTrades.
+	maxPrints = 100
+)
+
+// Simulator is a stateful paper-trading engine behind the mock services. It keeps
+// cash, positions, orders and trades per account and matches working orders against
+// the quotes passed to SetQuote, which come from a synthetic or replayed feed.
+type Simulator struct {
+	mu          sync.Mutex
+	accounts    map[string]*simAccount
+	accountIDs  []string
+	instruments map[string]*simInstrument
+	quotes      map[string]*marketdata.Quote
+	prints      map[string][]*marketdata.Trade
+	watchers    map[int]chan simEvent
+
+	nextWatcher int
+	nextOrderID int
+	nextTradeID int
+	nextPrintID int
+
+	// Now returns the simulation time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+type simAccount struct {
+	id        string
+	cash      float64
+	positions map[string]*simPosition
+	orders    []*simOrder
+	trades    []*tradeapiv1.AccountTrade
+	openedAt  time.Time
+}
+
+// simPosition is a net position with its average entry price. Short positions have a negative quantity.
+type simPosition struct {
+	qty      float64
+	avgPrice float64
+}
+
+// simOrder is the engine's view of a placed order; OrderState messages are built from it on demand.
+type simOrder struct {
+	id        string
+	account   string
+	req       *orders.Order     // nil for SL/TP orders
+	sltp      *orders.SLTPOrder // nil for regular orders
+	status    orders.OrderStatus
+	qty       float64
+	executed  float64
+	triggered bool // the stop price was reached; a stop-limit now works as a limit order
+
+	acceptAt, transactAt, withdrawAt time.Time
+}
+
+// simEvent is published to streams whenever the simulator state changes.
+type simEvent struct {
+	account string
+	order   *orders.OrderState
+	trade   *tradeapiv1.AccountTrade
+	quote   *marketdata.Quote
+
+	print       *marketdata.Trade
+	printSymbol string
+}
+
+// NewSimulator creates an empty simulator. Add accounts with AddAccount and
+// instruments with AddInstrument or SetQuote.
+func NewSimulator() *Simulator {
+	return &Simulator{
+		accounts:    make(map[string]*simAccount),
+		instruments: make(map[string]*simInstrument),
+		quotes:      make(map[string]*marketdata.Quote),
+		prints:      make(map[string][]*marketdata.Trade),
+		watchers:    make(map[int]chan simEvent),
+		nextOrderID: 1,
+		nextTradeID: 1,
+		nextPrintID: 1,
+		Now:         time.Now,
+	}
+}
+
+// AddAccount opens an account with the given cash balance.
+func (s *Simulator) AddAccount(accountID string, cash float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[accountID]; ok {
+		return
+	}
+	s.accounts[accountID] = &simAccount{
+		id:        accountID,
+		cash:      cash,
+		positions: make(map[string]*simPosition),
+		openedAt:  s.Now(),
+	}
+	s.accountIDs = append(s.accountIDs, accountID)
+}
+
+// AccountIDs returns the accounts in the order they were added.
+func (s *Simulator) AccountIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.accountIDs...)
+}
+
+// SetQuote publishes a new quote and fills or triggers the working orders on its symbol.
+// A quote with a LastSize is also recorded as an exchange print.
+func (s *Simulator) SetQuote(q *marketdata.Quote) {
+	if q == nil || q.Symbol == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := s.quotes[q.Symbol]
+	s.quotes[q.Symbol] = q
+	s.publish(simEvent{quote: q})
+
+	if size := decimalFloat(q.LastSize); size > 0 && decimalFloat(q.Last) > 0 {
+		s.recordPrint(q, prev)
+	}
+
+	for _, id := range s.accountIDs {
+		acc := s.accounts[id]
+		for _, o := range acc.orders {
+			if o.working() && o.symbol() == q.Symbol {
+				s.match(acc, o, q, false)
+			}
+		}
+	}
+}
+
+// Quote returns the last quote for symbol, or nil when none was set.
+func (s *Simulator) Quote(symbol string) *marketdata.Quote {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.quotes[symbol]
+}
+
+// LatestTrades returns the recent exchange prints for symbol, oldest first.
+func (s *Simulator) LatestTrades(symbol string) []*marketdata.Trade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*marketdata.Trade(nil), s.prints[symbol]...)
+}
+
+// recordPrint keeps q's last trade as an exchange print. The aggressor side follows the tick direction.
+func (s *Simulator) recordPrint(q, prev *marketdata.Quote) {
+	side := tradeapiv1.Side_SIDE_BUY
+	prints := s.prints[q.Symbol]
+	if prev != nil {
+		last, prevLast := decimalFloat(q.Last), decimalFloat(prev.Last)
+		switch {
+		case last < prevLast:
+			side = tradeapiv1.Side_SIDE_SELL
+		case last == prevLast && len(prints) > 0:
+			side = prints[len(prints)-1].Side
+		}
+	}
+
+	ts := q.Timestamp
+	if ts == nil {
+		ts = timestamppb.New(s.Now())
+	}
+	p := &marketdata.Trade{
+		TradeId:   fmt.Sprintf("SIM%d", s.nextPrintID),
+		Timestamp: ts,
+		Price:     q.Last,
+		Size:      q.LastSize,
+		Side:      side,
+	}
+	s.nextPrintID++
+
+	prints = append(prints, p)
+	if len(prints) > maxPrints {
+		prints = prints[len(prints)-maxPrints:]
+	}
+	s.prints[q.Symbol] = prints
+	s.publish(simEvent{print: p, printSymbol: q.Symbol})
+}
+
+// PlaceOrder accepts a market, limit, stop or stop-limit order. Marketable orders are filled
+// against the current quote before PlaceOrder returns; the rest keep working until a quote
+// reaches their price.
+func (s *Simulator) PlaceOrder(req *orders.Order) (*orders.OrderState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, err := s.account(req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+	qty := decimalFloat(req.GetQuantity())
+	if qty <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "quantity must be positive")
+	}
+	if req.GetSide() != tradeapiv1.Side_SIDE_BUY && req.GetSide() != tradeapiv1.Side_SIDE_SELL {
+		return nil, status.Errorf(codes.InvalidArgument, "side is required")
+	}
+	q := s.quotes[req.GetSymbol()]
+	if q == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown instrument %s", req.GetSymbol())
+	}
+
+	limit, stop := decimalFloat(req.GetLimitPrice()), decimalFloat(req.GetStopPrice())
+	price := touchPrice(req.GetSide(), q)
+	switch req.GetType() {
+	case orders.OrderType_ORDER_TYPE_MARKET:
+	case orders.OrderType_ORDER_TYPE_LIMIT:
+		if limit <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "limit price is required")
+		}
+		price = limit
+	case orders.OrderType_ORDER_TYPE_STOP:
+		if stop <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "stop price is required")
+		}
+		price = stop
+	case orders.OrderType_ORDER_TYPE_STOP_LIMIT:
+		if stop <= 0 || limit <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "stop and limit prices are required")
+		}
+		price = limit
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "order type %s is not supported", req.GetType())
+	}
+	if price <= 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "no market price for %s", req.GetSymbol())
+	}
+	if err := acc.checkFunds(req.GetSymbol(), req.GetSide(), qty, price); err != nil {
+		return nil, err
+	}
+
+	o := s.newOrder(acc, qty)
+	o.req = req
+	s.publish(simEvent{account: acc.id, order: o.state()})
+	s.match(acc, o, q, true)
+	return o.state(), nil
+}
+
+// PlaceSLTPOrder accepts a linked stop-loss / take-profit pair. The first leg whose price
+// is reached by the last trade price is executed at market and the pair is closed.
+func (s *Simulator) PlaceSLTPOrder(req *orders.SLTPOrder) (*orders.OrderState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, err := s.account(req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetSide() != tradeapiv1.Side_SIDE_BUY && req.GetSide() != tradeapiv1.Side_SIDE_SELL {
+		return nil, status.Errorf(codes.InvalidArgument, "side is required")
+	}
+	if s.quotes[req.GetSymbol()] == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown instrument %s", req.GetSymbol())
+	}
+	slPrice, tpPrice := decimalFloat(req.GetSlPrice()), decimalFloat(req.GetTpPrice())
+	if slPrice <= 0 && tpPrice <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "stop-loss or take-profit price is required")
+	}
+	qty := math.Max(decimalFloat(req.GetQuantitySl()), decimalFloat(req.GetQuantityTp()))
+	if qty <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "quantity must be positive")
+	}
+
+	o := s.newOrder(acc, qty)
+	o.sltp = req
+	o.status = orders.OrderStatus_ORDER_STATUS_WATCHING
+	s.publish(simEvent{account: acc.id, order: o.state()})
+	s.match(acc, o, s.quotes[req.GetSymbol()], true)
+	return o.state(), nil
+}
+
+// CancelOrder withdraws a working order.
+func (s *Simulator) CancelOrder(accountID, orderID string) (*orders.OrderState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, err := s.account(accountID)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range acc.orders {
+		if o.id != orderID {
+			continue
+		}
+		if !o.working() {
+			return nil, status.Errorf(codes.FailedPrecondition, "order %s is not active", orderID)
+		}
+		o.status = orders.OrderStatus_ORDER_STATUS_CANCELED
+		o.withdrawAt = s.Now()
+		st := o.state()
+		s.publish(simEvent{account: acc.id, order: st})
+		return st, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "order %s not found", orderID)
+}
+
+// Orders returns every order of the account, including filled and cancelled ones.
+func (s *Simulator) Orders(accountID string) ([]*orders.OrderState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, err := s.account(accountID)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]*orders.OrderState, 0, len(acc.orders))
+	for _, o := range acc.orders {
+		states = append(states, o.state())
+	}
+	return states, nil
+}
+
+// Trades returns the fills of the account, oldest first.
+func (s *Simulator) Trades(accountID string) ([]*tradeapiv1.AccountTrade, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, err := s.account(accountID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*tradeapiv1.AccountTrade(nil), acc.trades...), nil
+}
+
+// Account returns the account with positions marked to the last price. Equity is cash plus
+// the market value of all positions; daily P&L is measured from the previous close.
+func (s *Simulator) Account(accountID string) (*accounts.GetAccountResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, err := s.account(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0, len(acc.positions))
+	for sym, p := range acc.positions {
+		if p.qty != 0 {
+			symbols = append(symbols, sym)
+		}
+	}
+	sort.Strings(symbols)
+
+	equity, unrealized := acc.cash, 0.0
+	positions := make([]*accounts.Position, 0, len(symbols))
+	for _, sym := range symbols {
+		p := acc.positions[sym]
+		q := s.quotes[sym]
+		last := decimalFloat(q.GetLast())
+		if last <= 0 {
+			last = p.avgPrice
+		}
+		pnl := (last - p.avgPrice) * p.qty
+		equity += p.qty * last
+		unrealized += pnl
+
+		pos := &accounts.Position{
+			Symbol:        sym,
+			Quantity:      newDecimal(p.qty),
+			AveragePrice:  newDecimal(p.avgPrice),
+			CurrentPrice:  newDecimal(last),
+			UnrealizedPnl: newDecimal(pnl),
+		}
+		if prevClose := decimalFloat(q.GetClose()); prevClose > 0 {
+			pos.DailyPnl = newDecimal((last - prevClose) * p.qty)
+		}
+		positions = append(positions, pos)
+	}
+
+	return &accounts.GetAccountResponse{
+		AccountId:        acc.id,
+		Type:             "PAPER",
+		Status:           "ACTIVE",
+		Equity:           newDecimal(equity),
+		UnrealizedProfit: newDecimal(unrealized),
+		Positions:        positions,
+		OpenAccountDate:  timestamppb.New(acc.openedAt),
+	}, nil
+}
+
+// account looks an account up. Called with s.mu held.
+func (s *Simulator) account(accountID string) (*simAccount, error) {
+	acc, ok := s.accounts[accountID]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "account %s not found", accountID)
+	}
+	return acc, nil
+}
+
+// newOrder registers a new working order. Called with s.mu held.
+func (s *Simulator) newOrder(acc *simAccount, qty float64) *simOrder {
+	o := &simOrder{
+		id:       fmt.Sprintf("P%06d", s.nextOrderID),
+		account:  acc.id,
+		status:   orders.OrderStatus_ORDER_STATUS_NEW,
+		qty:      qty,
+		acceptAt: s.Now(),
+	}
+	s.nextOrderID++
+	acc.orders = append(acc.orders, o)
+	return o
+}
+
+// match fills or triggers o against q. aggressive is set when the order has just been
+// placed, so a marketable limit order takes the quote price instead of its own limit.
+// Called with s.mu held.
+func (s *Simulator) match(acc *simAccount, o *simOrder, q *marketdata.Quote, aggressive bool) {
+	if o.sltp != nil {
+		s.matchSLTP(acc, o, q)
+		return
+	}
+
+	req := o.req
+	side := req.GetSide()
+	switch req.GetType() {
+	case orders.OrderType_ORDER_TYPE_MARKET:
+		if price := touchPrice(side, q); price > 0 {
+			s.fill(acc, o, price, o.remaining())
+		}
+		return
+	case orders.OrderType_ORDER_TYPE_STOP, orders.OrderType_ORDER_TYPE_STOP_LIMIT:
+		if !o.triggered {
+			if !stopReached(side, req.GetStopCondition(), decimalFloat(req.GetStopPrice()), decimalFloat(q.GetLast())) {
+				return
+			}
+			o.triggered = true
+			if req.GetType() == orders.OrderType_ORDER_TYPE_STOP {
+				if price := touchPrice(side, q); price > 0 {
+					s.fill(acc, o, price, o.remaining())
+				}
+				return
+			}
+			// A triggered stop-limit enters the book and may fill right away
+			aggressive = true
+		}
+	}
+
+	limit := decimalFloat(req.GetLimitPrice())
+	price := touchPrice(side, q)
+	if price <= 0 || (side == tradeapiv1.Side_SIDE_BUY && price > limit) || (side == tradeapiv1.Side_SIDE_SELL && price < limit) {
+		return
+	}
+	qty := o.remaining()
+	if size := touchSize(side, q); size > 0 && size < qty {
+		qty = size
+	}
+	if !aggressive {
+		price = limit
+	}
+	s.fill(acc, o, price, qty)
+}
+
+// matchSLTP executes the stop-loss or take-profit leg once the last price reaches it.
+// A sell pair protects a long position: SL below the market, TP above. Called with s.mu held.
+func (s *Simulator) matchSLTP(acc *simAccount, o *simOrder, q *marketdata.Quote) {
+	last := decimalFloat(q.GetLast())
+	if last <= 0 {
+		return
+	}
+	req := o.sltp
+	sell := req.GetSide() == tradeapiv1.Side_SIDE_SELL
+	sl, tp := decimalFloat(req.GetSlPrice()), decimalFloat(req.GetTpPrice())
+
+	var qty float64
+	var done orders.OrderStatus
+	switch {
+	case sl > 0 && ((sell && last <= sl) || (!sell && last >= sl)):
+		qty, done = decimalFloat(req.GetQuantitySl()), orders.OrderStatus_ORDER_STATUS_SL_EXECUTED
+	case tp > 0 && ((sell && last >= tp) || (!sell && last <= tp)):
+		qty, done = decimalFloat(req.GetQuantityTp()), orders.OrderStatus_ORDER_STATUS_TP_EXECUTED
+	default:
+		return
+	}
+
+	price := touchPrice(req.GetSide(), q)
+	if price <= 0 || qty <= 0 {
+		return
+	}
+	o.qty = qty
+	s.fill(acc, o, price, qty)
+	o.status = done
+	s.publish(simEvent{account: acc.id, order: o.state()})
+}
+
+// fill executes qty of o at price, updating cash, the position and the trade log, and
+// publishes the trade and, for regular orders, the new order state. Called with s.mu held.
+func (s *Simulator) fill(acc *simAccount, o *simOrder, price, qty float64) {
+	if qty <= 0 {
+		return
+	}
+	side, symbol := o.side(), o.symbol()
+	signed := qty
+	if side == tradeapiv1.Side_SIDE_SELL {
+		signed = -qty
+	}
+
+	acc.cash -= signed * price
+	pos := acc.positions[symbol]
+	if pos == nil {
+		pos = &simPosition{}
+		acc.positions[symbol] = pos
+	}
+	pos.apply(signed, price)
+
+	now := s.Now()
+	o.executed += qty
+	o.transactAt = now
+	if o.remaining() <= 0 {
+		o.status = orders.OrderStatus_ORDER_STATUS_FILLED
+		o.withdrawAt = now
+	} else {
+		o.status = orders.OrderStatus_ORDER_STATUS_PARTIALLY_FILLED
+	}
+
+	tr := &tradeapiv1.AccountTrade{
+		TradeId:   fmt.Sprintf("PT%06d", s.nextTradeID),
+		AccountId: acc.id,
+		Symbol:    symbol,
+		Side:      side,
+		Price:     newDecimal(price),
+		Size:      newDecimal(qty),
+		Timestamp: timestamppb.New(now),
+		OrderId:   o.id,
+	}
+	s.nextTradeID++
+	acc.trades = append(acc.trades, tr)
+
+	s.publish(simEvent{account: acc.id, trade: tr})
+	if o.sltp == nil {
+		s.publish(simEvent{account: acc.id, order: o.state()})
+	}
+}
+
+// checkFunds refuses a buy whose cost exceeds the cash balance. Buying back a short
+// position needs no cash for the covered part.
+func (acc *simAccount) checkFunds(symbol string, side tradeapiv1.Side, qty, price float64) error {
+	if side != tradeapiv1.Side_SIDE_BUY {
+		return nil
+	}
+	if p := acc.positions[symbol]; p != nil && p.qty < 0 {
+		qty = math.Max(0, qty+p.qty)
+	}
+	if cost := qty * price; cost > acc.cash {
+		return status.Errorf(codes.FailedPrecondition, "insufficient funds: order cost %s, available %s",
+			formatAmount(cost), formatAmount(acc.cash))
+	}
+	return nil
+}
+
+// apply adds a signed fill to the position. Adding keeps a weighted average price;
+// reducing keeps it; crossing through zero starts a new position at the fill price.
+func (p *simPosition) apply(signed, price float64) {
+	total := p.qty + signed
+	switch {
+	case p.qty == 0 || (p.qty > 0) == (signed > 0):
+		p.avgPrice = (p.avgPrice*math.Abs(p.qty) + price*math.Abs(signed)) / math.Abs(total)
+	case total == 0:
+		p.avgPrice = 0
+	case (total > 0) != (p.qty > 0):
+		p.avgPrice = price
+	}
+	p.qty = total
+}
+
+func (o *simOrder) working() bool {
+	switch o.status {
+	case orders.OrderStatus_ORDER_STATUS_NEW,
+		orders.OrderStatus_ORDER_STATUS_PARTIALLY_FILLED,
+		orders.OrderStatus_ORDER_STATUS_WATCHING:
+		return true
+	}
+	return false
+}
+
+func (o *simOrder) remaining() float64 { return o.qty - o.executed }
+
+func (o *simOrder) side() tradeapiv1.Side {
+	if o.sltp != nil {
+		return o.sltp.GetSide()
+	}
+	return o.req.GetSide()
+}
+
+func (o *simOrder) symbol() string {
+	if o.sltp != nil {
+		return o.sltp.GetSymbol()
+	}
+	return o.req.GetSymbol()
+}
+
+// state builds a fresh OrderState snapshot, safe to hand to a stream.
+func (o *simOrder) state() *orders.OrderState {
+	st := &orders.OrderState{
+		OrderId:           o.id,
+		Status:            o.status,
+		Order:             o.req,
+		SltpOrder:         o.sltp,
+		InitialQuantity:   newDecimal(o.qty),
+		ExecutedQuantity:  newDecimal(o.executed),
+		RemainingQuantity: newDecimal(math.Max(0, o.remaining())),
+		AcceptAt:          timestamppb.New(o.acceptAt),
+	}
+	if !o.transactAt.IsZero() {
+		st.TransactAt = timestamppb.New(o.transactAt)
+	}
+	if !o.withdrawAt.IsZero() {
+		st.WithdrawAt = timestamppb.New(o.withdrawAt)
+	}
+	return st
+}
+
+// stopReached reports whether the last price has reached a stop price. Without an explicit
+// condition a buy stop fires on the way up and a sell stop on the way down.
+func stopReached(side tradeapiv1.Side, cond orders.StopCondition, stop, last float64) bool {
+	if last <= 0 {
+		return false
+	}
+	if cond == orders.StopCondition_STOP_CONDITION_UNSPECIFIED {
+		cond = orders.StopCondition_STOP_CONDITION_LAST_DOWN
+		if side == tradeapiv1.Side_SIDE_BUY {
+			cond = orders.StopCondition_STOP_CONDITION_LAST_UP
+		}
+	}
+	if cond == orders.StopCondition_STOP_CONDITION_LAST_UP {
+		return last >= stop
+	}
+	return last <= stop
+}
+
+// touchPrice is the price a market order of the given side trades at: the ask for a buy,
+// the bid for a sell, falling back to the last price.
+func touchPrice(side tradeapiv1.Side, q *marketdata.Quote) float64 {
+	price := decimalFloat(q.GetBid())
+	if side == tradeapiv1.Side_SIDE_BUY {
+		price = decimalFloat(q.GetAsk())
+	}
+	if price <= 0 {
+		price = decimalFloat(q.GetLast())
+	}
+	return price
+}
+
+// touchSize is the size quoted at touchPrice; zero means unknown and does not limit fills.
+func touchSize(side tradeapiv1.Side, q *marketdata.Quote) float64 {
+	if side == tradeapiv1.Side_SIDE_BUY {
+		return decimalFloat(q.GetAskSize())
+	}
+	return decimalFloat(q.GetBidSize())
+}
+
+// watch registers a stream for simulator events. The returned function unregisters it.
+func (s *Simulator) watch() (<-chan simEvent, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextWatcher
+	s.nextWatcher++
+	ch := make(chan simEvent, watcherBuffer)
+	s.watchers[id] = ch
+	return ch, func() {
+		s.mu.Lock()
+		delete(s.watchers, id)
+		s.mu.Unlock()
+	}
+}
+
+// publish hands ev to every stream. A stream that falls more than watcherBuffer events
+// behind loses updates rather than blocking the engine. Called with s.mu held.
+func (s *Simulator) publish(ev simEvent) {
+	for _, ch := range s.watchers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// stream calls send for every event until ctx is done or send fails.
+func (s *Simulator) stream(ctx context.Context, send func(simEvent) error) error {
+	events, stop := s.watch()
+	defer stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-events:
+			if err := send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// decimalFloat parses an API decimal, returning 0 for nil or malformed values.
+func decimalFloat(d *decimal.Decimal) float64 {
+	if d == nil {
+		return 0
+	}
+	v, err := strconv.ParseFloat(d.Value, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// newDecimal formats v for the API, rounding away binary floating point noise.
+func newDecimal(v float64) *decimal.Decimal {
+	v = math.Round(v*1e6) / 1e6
+	if v == 0 {
+		v = 0 // drop the sign of negative zero
+	}
+	return &decimal.Decimal{Value: strconv.FormatFloat(v, 'f', -1, 64)}
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,289 @@
+package testserver
+
+import (
+	"testing"
+	"time"
+
+	tradeapiv1 "github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1"
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/orders"
+	"google.golang.org/genproto/googleapis/type/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestSimulator(cash float64) *Simulator {
+	sim := NewSimulator()
+	sim.Now = func() time.Time { return time.Date(2026, 4, 7, 10, 0, 0, 0, time.UTC) }
+	sim.AddAccount("SIM1", cash)
+	sim.SetQuote(simQuote(284.9, 285.1, 285, 0))
+	return sim
+}
+
+// simQuote builds an SBER quote; askSize limits how much a buy limit order can take.
+func simQuote(bid, ask, last, askSize float64) *marketdata.Quote {
+	q := &marketdata.Quote{
+		Symbol: "SBER@TQBR",
+		Bid:    newDecimal(bid),
+		Ask:    newDecimal(ask),
+		Last:   newDecimal(last),
+		Close:  newDecimal(280),
+	}
+	if askSize > 0 {
+		q.AskSize = newDecimal(askSize)
+	}
+	return q
+}
+
+func simOrderReq(side tradeapiv1.Side, qty string, typ orders.OrderType) *orders.Order {
+	return &orders.Order{
+		AccountId: "SIM1",
+		Symbol:    "SBER@TQBR",
+		Side:      side,
+		Type:      typ,
+		Quantity:  &decimal.Decimal{Value: qty},
+	}
+}
+
+func TestSimulator_MarketOrderFillsAtTouch(t *testing.T) {
+	sim := newTestSimulator(100000)
+
+	st, err := sim.PlaceOrder(simOrderReq(tradeapiv1.Side_SIDE_BUY, "100", orders.OrderType_ORDER_TYPE_MARKET))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if st.Status != orders.OrderStatus_ORDER_STATUS_FILLED {
+		t.Fatalf("Expected FILLED, got %v", st.Status)
+	}
+
+	trades, _ := sim.Trades("SIM1")
+	if len(trades) != 1 || trades[0].Price.Value != "285.1" || trades[0].OrderId != st.OrderId {
+		t.Fatalf("Expected one trade at the ask, got %+v", trades)
+	}
+
+	acc, err := sim.Account("SIM1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(acc.Positions) != 1 || acc.Positions[0].Quantity.Value != "100" || acc.Positions[0].AveragePrice.Value != "285.1" {
+		t.Fatalf("Unexpected positions %+v", acc.Positions)
+	}
+	// 100000 - 28510 cash + 100 * 285 market value
+	if acc.Equity.Value != "99990" {
+		t.Errorf("Expected equity 99990, got %s", acc.Equity.Value)
+	}
+	if acc.UnrealizedProfit.Value != "-10" {
+		t.Errorf("Expected unrealized -10, got %s", acc.UnrealizedProfit.Value)
+	}
+	if acc.Positions[0].DailyPnl.Value != "500" {
+		t.Errorf("Expected daily P&L 500 from the 280 close, got %s", acc.Positions[0].DailyPnl.Value)
+	}
+}
+
+func TestSimulator_LimitOrderRestsThenFillsPartially(t *testing.T) {
+	sim := newTestSimulator(100000)
+
+	req := simOrderReq(tradeapiv1.Side_SIDE_BUY, "50", orders.OrderType_ORDER_TYPE_LIMIT)
+	req.LimitPrice = &decimal.Decimal{Value: "284.00"}
+	st, err := sim.PlaceOrder(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if st.Status != orders.OrderStatus_ORDER_STATUS_NEW {
+		t.Fatalf("Expected the order to rest, got %v", st.Status)
+	}
+
+	sim.SetQuote(simQuote(283.8, 283.9, 283.9, 30))
+	states, _ := sim.Orders("SIM1")
+	if states[0].Status != orders.OrderStatus_ORDER_STATUS_PARTIALLY_FILLED || states[0].ExecutedQuantity.Value != "30" {
+		t.Fatalf("Expected 30 of 50 filled, got %v %s", states[0].Status, states[0].ExecutedQuantity.Value)
+	}
+
+	sim.SetQuote(simQuote(283.8, 283.9, 283.9, 100))
+	states, _ = sim.Orders("SIM1")
+	if states[0].Status != orders.OrderStatus_ORDER_STATUS_FILLED || states[0].RemainingQuantity.Value != "0" {
+		t.Fatalf("Expected the rest to fill, got %v", states[0].Status)
+	}
+
+	trades, _ := sim.Trades("SIM1")
+	for _, tr := range trades {
+		if tr.Price.Value != "284" {
+			t.Errorf("Expected resting fills at the limit price, got %s", tr.Price.Value)
+		}
+	}
+}
+
+func TestSimulator_StopTriggersOnLastPrice(t *testing.T) {
+	sim := newTestSimulator(100000)
+	if _, err := sim.PlaceOrder(simOrderReq(tradeapiv1.Side_SIDE_BUY, "10", orders.OrderType_ORDER_TYPE_MARKET)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	req := simOrderReq(tradeapiv1.Side_SIDE_SELL, "10", orders.OrderType_ORDER_TYPE_STOP)
+	req.StopPrice = &decimal.Decimal{Value: "280"}
+	req.StopCondition = orders.StopCondition_STOP_CONDITION_LAST_DOWN
+	st, err := sim.PlaceOrder(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sim.SetQuote(simQuote(280.5, 280.7, 280.6, 0))
+	if states, _ := sim.Orders("SIM1"); states[1].Status != orders.OrderStatus_ORDER_STATUS_NEW {
+		t.Fatalf("Stop must not fire above its price, got %v", states[1].Status)
+	}
+
+	sim.SetQuote(simQuote(279.8, 280.0, 279.9, 0))
+	states, _ := sim.Orders("SIM1")
+	if states[1].OrderId != st.OrderId || states[1].Status != orders.OrderStatus_ORDER_STATUS_FILLED {
+		t.Fatalf("Expected the stop to fill, got %v", states[1].Status)
+	}
+	acc, _ := sim.Account("SIM1")
+	if len(acc.Positions) != 0 {
+		t.Errorf("Expected the position to be closed, got %+v", acc.Positions)
+	}
+}
+
+func TestSimulator_SLTPExecutesTakeProfit(t *testing.T) {
+	sim := newTestSimulator(100000)
+	if _, err := sim.PlaceOrder(simOrderReq(tradeapiv1.Side_SIDE_BUY, "10", orders.OrderType_ORDER_TYPE_MARKET)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	st, err := sim.PlaceSLTPOrder(&orders.SLTPOrder{
+		AccountId:  "SIM1",
+		Symbol:     "SBER@TQBR",
+		Side:       tradeapiv1.Side_SIDE_SELL,
+		QuantitySl: &decimal.Decimal{Value: "10"},
+		SlPrice:    &decimal.Decimal{Value: "270"},
+		QuantityTp: &decimal.Decimal{Value: "10"},
+		TpPrice:    &decimal.Decimal{Value: "300"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if st.Status != orders.OrderStatus_ORDER_STATUS_WATCHING {
+		t.Fatalf("Expected WATCHING, got %v", st.Status)
+	}
+
+	sim.SetQuote(simQuote(300.0, 300.2, 300.1, 0))
+	states, _ := sim.Orders("SIM1")
+	if states[1].Status != orders.OrderStatus_ORDER_STATUS_TP_EXECUTED {
+		t.Fatalf("Expected TP_EXECUTED, got %v", states[1].Status)
+	}
+	trades, _ := sim.Trades("SIM1")
+	if last := trades[len(trades)-1]; last.Side != tradeapiv1.Side_SIDE_SELL || last.Price.Value != "300" {
+		t.Errorf("Expected a sell at the bid, got %v %s", last.Side, last.Price.Value)
+	}
+
+	// The pair is closed: a later drop through the stop-loss does nothing
+	sim.SetQuote(simQuote(260, 260.2, 260.1, 0))
+	if trades, _ := sim.Trades("SIM1"); len(trades) != 2 {
+		t.Errorf("Expected no further fills, got %d trades", len(trades))
+	}
+}
+
+func TestSimulator_Rejections(t *testing.T) {
+	sim := newTestSimulator(1000)
+
+	_, err := sim.PlaceOrder(simOrderReq(tradeapiv1.Side_SIDE_BUY, "10", orders.OrderType_ORDER_TYPE_MARKET))
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for insufficient funds, got %v", err)
+	}
+
+	unknown := simOrderReq(tradeapiv1.Side_SIDE_BUY, "1", orders.OrderType_ORDER_TYPE_MARKET)
+	unknown.Symbol = "NOPE@TQBR"
+	if _, err := sim.PlaceOrder(unknown); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown symbol, got %v", err)
+	}
+
+	noPrice := simOrderReq(tradeapiv1.Side_SIDE_SELL, "1", orders.OrderType_ORDER_TYPE_LIMIT)
+	if _, err := sim.PlaceOrder(noPrice); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without a limit price, got %v", err)
+	}
+
+	// Short selling needs no cash
+	if _, err := sim.PlaceOrder(simOrderReq(tradeapiv1.Side_SIDE_SELL, "10", orders.OrderType_ORDER_TYPE_MARKET)); err != nil {
+		t.Errorf("Unexpected error for a short sale: %v", err)
+	}
+}
+
+func TestSimulator_CancelOrder(t *testing.T) {
+	sim := newTestSimulator(100000)
+
+	req := simOrderReq(tradeapiv1.Side_SIDE_BUY, "10", orders.OrderType_ORDER_TYPE_LIMIT)
+	req.LimitPrice = &decimal.Decimal{Value: "250"}
+	st, _ := sim.PlaceOrder(req)
+
+	cancelled, err := sim.CancelOrder("SIM1", st.OrderId)
+	if err != nil || cancelled.Status != orders.OrderStatus_ORDER_STATUS_CANCELED {
+		t.Fatalf("Expected CANCELED, got %v, %v", cancelled, err)
+	}
+	if _, err := sim.CancelOrder("SIM1", st.OrderId); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for a second cancel, got %v", err)
+	}
+	if _, err := sim.CancelOrder("SIM1", "P999999"); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+
+	// A cancelled order no longer fills
+	sim.SetQuote(simQuote(249, 249.5, 249.5, 0))
+	if trades, _ := sim.Trades("SIM1"); len(trades) != 0 {
+		t.Errorf("Expected no fills, got %d", len(trades))
+	}
+}
+
+func TestSimPosition_Apply(t *testing.T) {
+	p := &simPosition{}
+	p.apply(10, 100)
+	p.apply(10, 110)
+	if p.qty != 20 || p.avgPrice != 105 {
+		t.Fatalf("Expected 20 @ 105, got %v @ %v", p.qty, p.avgPrice)
+	}
+	p.apply(-5, 120)
+	if p.qty != 15 || p.avgPrice != 105 {
+		t.Fatalf("Reducing must keep the average, got %v @ %v", p.qty, p.avgPrice)
+	}
+	p.apply(-25, 90)
+	if p.qty != -10 || p.avgPrice != 90 {
+		t.Fatalf("Flipping must start at the fill price, got %v @ %v", p.qty, p.avgPrice)
+	}
+	p.apply(10, 95)
+	if p.qty != 0 || p.avgPrice != 0 {
+		t.Fatalf("Expected a flat position, got %v @ %v", p.qty, p.avgPrice)
+	}
+}
+
+func TestBookDiff(t *testing.T) {
+	prev := map[bookLevel]float64{{price: 100}: 10, {price: 99, buy: true}: 20}
+	next := map[bookLevel]float64{{price: 100}: 15, {price: 100.5}: 30}
+
+	rows := bookDiff(prev, next)
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(rows))
+	}
+	if rows[0].Action != marketdata.StreamOrderBook_Row_ACTION_REMOVE || rows[0].GetBuySize() == nil {
+		t.Errorf("Expected the bid at 99 to be removed first, got %+v", rows[0])
+	}
+	if rows[1].Action != marketdata.StreamOrderBook_Row_ACTION_ADD || rows[1].Price.Value != "100.5" {
+		t.Errorf("Expected the new ask at 100.5, got %+v", rows[1])
+	}
+	if rows[2].Action != marketdata.StreamOrderBook_Row_ACTION_UPDATE || rows[2].GetSellSize().Value != "15" {
+		t.Errorf("Expected the ask at 100 to be resized, got %+v", rows[2])
+	}
+}
+
+func TestSimulator_BarsEndAtLastPrice(t *testing.T) {
+	sim := newTestSimulator(0)
+	to := sim.Now()
+	bars := sim.Bars("SBER@TQBR", marketdata.TimeFrame_TIME_FRAME_H1, to.Add(-10*time.Hour), to)
+	if len(bars) != 11 {
+		t.Fatalf("Expected 11 hourly bars, got %d", len(bars))
+	}
+	if last := bars[len(bars)-1]; last.Close.Value != "285" {
+		t.Errorf("Expected the last bar to close at 285, got %s", last.Close.Value)
+	}
+	again := sim.Bars("SBER@TQBR", marketdata.TimeFrame_TIME_FRAME_H1, to.Add(-10*time.Hour), to)
+	if again[0].Open.Value != bars[0].Open.Value {
+		t.Error("Expected repeated requests to return the same history")
+	}
+}
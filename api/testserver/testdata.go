@@ -104,6 +104,47 @@ func DefaultBars(symbol string) []*marketdata.Bar {
 	return bars
 }
 
+// DefaultOrderBook returns three price levels per side around the default SBER quote.
+func DefaultOrderBook(symbol string) *marketdata.OrderBook {
+	if symbol != "SBER@TQBR" {
+		return nil
+	}
+	row := func(price, size string, buy bool) *marketdata.OrderBook_Row {
+		r := &marketdata.OrderBook_Row{
+			Price:  &decimal.Decimal{Value: price},
+			Action: marketdata.OrderBook_Row_ACTION_ADD,
+		}
+		if buy {
+			r.Side = &marketdata.OrderBook_Row_BuySize{BuySize: &decimal.Decimal{Value: size}}
+		} else {
+			r.Side = &marketdata.OrderBook_Row_SellSize{SellSize: &decimal.Decimal{Value: size}}
+		}
+		return r
+	}
+	return &marketdata.OrderBook{
+		Rows: []*marketdata.OrderBook_Row{
+			row("285.30", "300", false),
+			row("285.20", "200", false),
+			row("285.10", "100", false),
+			row("284.90", "150", true),
+			row("284.80", "250", true),
+			row("284.70", "350", true),
+		},
+	}
+}
+
+// DefaultLatestTrades returns a few exchange prints for SBER with both aggressor sides.
+func DefaultLatestTrades(symbol string) []*marketdata.Trade {
+	if symbol != "SBER@TQBR" {
+		return nil
+	}
+	base := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	return []*marketdata.Trade{
+		{TradeId: "MT1", Timestamp: timestamppb.New(base), Price: &decimal.Decimal{Value: "285.00"}, Size: &decimal.Decimal{Value: "10"}, Side: tradeapiv1.Side_SIDE_BUY},
+		{TradeId: "MT2", Timestamp: timestamppb.New(base.Add(time.Second)), Price: &decimal.Decimal{Value: "284.90"}, Size: &decimal.Decimal{Value: "500"}, Side: tradeapiv1.Side_SIDE_SELL},
+	}
+}
+
 // DefaultOrders returns a mix of order types for testing.
 func DefaultOrders(accountID string) []*orders.OrderState {
 	return []*orders.OrderState{
@@ -0,0 +1,61 @@
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestWrite_CreatesAndReplaces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "orders.json")
+
+	if err := Write(path, []byte("first"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := Write(path, []byte("second"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "second" {
+		t.Errorf("Expected the file to be replaced, got %q", data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files left behind, got %d entries", len(entries))
+	}
+}
+
+func TestWrite_ConcurrentWritersLeaveOneWholeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.json")
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Write(path, fmt.Appendf(nil, "writer %d", i), 0644); err != nil {
+				t.Errorf("Writer %d: %v", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if _, err := fmt.Sscanf(string(data), "writer %d", &n); err != nil || fmt.Sprintf("writer %d", n) != string(data) {
+		t.Errorf("Expected the contents of one writer, got %q", data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files left behind, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,327 @@
+// Package cli implements headless subcommands for scripts and cron jobs.
+// Every command reuses the API client of the TUI and prints a table, JSON or CSV.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"finam-terminal/models"
+	"finam-terminal/risk"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/orders"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK       = 0
+	ExitError    = 1 // API or connection failure
+	ExitUsage    = 2 // bad command line or invalid order parameters
+	ExitRejected = 3 // the broker refused an order request
+)
+
+// Client is the subset of api.Client used by the subcommands.
+type Client interface {
+	GetAccounts() ([]models.AccountInfo, error)
+	GetAccountDetails(accountID string) (*models.AccountInfo, []models.Position, error)
+	GetQuotes(accountID string, symbols []string) (map[string]*models.Quote, error)
+	GetActiveOrders(accountID string) ([]models.Order, error)
+	GetTradeHistoryRange(accountID string, from, to time.Time) ([]models.Trade, error)
+	GetTransactions(accountID string, from, to time.Time) ([]models.Transaction, error)
+	GetBars(accountID string, symbol string, timeframe marketdata.TimeFrame, from, to time.Time) ([]models.Bar, error)
+	GetLotSize(ticker string) models.Decimal
+	GetAssetParams(accountID string, symbol string) (*models.AssetParams, error)
+	Close() error
+
+	BuildOrder(accountID string, symbol string, buySell string, quantity models.Decimal, params *models.OrderParams) (*orders.Order, error)
+	BuildSLTPOrder(accountID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice models.Decimal) (*orders.SLTPOrder, error)
+	PlaceOrder(accountID string, symbol string, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error)
+	PlaceSLTPOrder(accountID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice models.Decimal) (string, error)
+	CancelOrder(accountID, orderID string) error
+}
+
+// command is one subcommand with its usage line for help output.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(env *env, args []string) error
+}
+
+// Connect creates the API client and the risk engine that checks every order before it
+// is placed.
+type Connect func() (Client, *risk.Engine, error)
+
+// env carries the shared state of a single invocation.
+type env struct {
+	connect Connect
+	client  Client
+	risk    *risk.Engine
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+// usageError marks command line mistakes, which exit with ExitUsage.
+type usageError struct{ msg string }
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+var commands = []command{
+	{"accounts", "accounts [--format F]", "List accounts with equity and unrealized P&L", runAccounts},
+	{"positions", "positions [--account A] [--format F]", "List open positions of an account", runPositions},
+	{"quote", "quote SYMBOL... [--account A] [--format F]", "Show the last quote for one or more symbols", runQuote},
+	{"orders", "orders [--account A] [--active] [--format F]", "List orders of an account", runOrders},
+	{"trades", "trades [--account A] [--from DATE] [--to DATE] [--format F]", "List own trades (last 30 days by default)", runTrades},
+	{"pnl", "pnl [--account A] [--method fifo|average] [--by instrument|day|lot] [--from DATE] [--to DATE] [--format F]", "Show realized P&L matched from the trade history", runPnL},
+	{"transactions", "transactions [--account A] [--from DATE] [--to DATE] [--by entry|month|quarter|year] [--format F]", "List deposits, withdrawals, commissions and income (last 90 days by default)", runTransactions},
+	{"export", "export positions|trades|orders|transactions [--account A | --all] [--from DATE] [--to DATE] [--format csv|json|xlsx] [--output FILE]", "Dump data for spreadsheets and notebooks", runExport},
+	{"tax", "tax [--year Y] [--account A] [--from DATE] [--by summary|closes|income|all] [--format table|json|csv|xlsx] [--output FILE]", "Build the yearly tax report for the 3-NDFL declaration", runTax},
+	{"bars", "bars SYMBOL [--tf TF] [--from DATE] [--to DATE] [--account A] [--format F]", "Show candles for a symbol", runBars},
+	{"order", `order place --symbol S --side buy|sell --lots N [--type market|limit|stop|take-profit|stop-limit] [--price P] [--stop-price P] [--validity day|gtc] [--dry-run]
+       finam-terminal order sltp --symbol S --side buy|sell --lots N [--sl P] [--tp P] [--dry-run]
+       finam-terminal order cancel ORDER_ID [--dry-run]
+       (all accept --account A and --format F)`, "Place, protect or cancel orders", runOrder},
+}
+
+// IsCommand reports whether name is a known subcommand or a help request.
+func IsCommand(name string) bool {
+	if name == "help" {
+		return true
+	}
+	_, ok := findCommand(name)
+	return ok
+}
+
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// Run executes the subcommand in args[0] and returns the process exit code.
+// connect is called lazily, so usage errors never touch the network.
+func Run(args []string, connect Connect, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stdout)
+		return ExitOK
+	}
+
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		printUsage(stderr)
+		return ExitUsage
+	}
+
+	e := &env{connect: connect, stdout: stdout, stderr: stderr}
+	defer func() {
+		if e.client != nil {
+			_ = e.client.Close()
+		}
+	}()
+
+	err := cmd.run(e, args[1:])
+	var uerr *usageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case errors.As(err, &uerr):
+		fmt.Fprintf(stderr, "%s: %v\nusage: finam-terminal %s\n", cmd.name, err, cmd.usage)
+		return ExitUsage
+	case isRejected(err):
+		fmt.Fprintf(stderr, "%s: %v\n", cmd.name, err)
+		return ExitRejected
+	default:
+		fmt.Fprintf(stderr, "%s: %v\n", cmd.name, err)
+		return ExitError
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: finam-terminal [-account N]              start the terminal UI")
+	fmt.Fprintln(w, "       finam-terminal <command> [flags]         run a headless command")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Common flags:")
+	fmt.Fprintln(w, "  --account A   account ID or 0-based index (default: first available account)")
+	fmt.Fprintln(w, "  --format F    table, json or csv (default: table)")
+	fmt.Fprintln(w, "  DATE          YYYY-MM-DD or RFC 3339 timestamp")
+}
+
+// api connects on first use.
+func (e *env) api() (Client, error) {
+	if e.client != nil {
+		return e.client, nil
+	}
+	client, engine, err := e.connect()
+	if err != nil {
+		return nil, err
+	}
+	e.client, e.risk = client, engine
+	return client, nil
+}
+
+// flagSet is a flag set with the options shared by all commands.
+type flagSet struct {
+	*flag.FlagSet
+	format  *string
+	account *string
+	formats []string // accepted --format values; the output formats when nil
+}
+
+func newFlagSet(e *env, name string, withAccount bool) *flagSet {
+	fs := &flagSet{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError)}
+	fs.SetOutput(e.stderr)
+	fs.format = fs.String("format", FormatTable, "output format: table, json or csv")
+	if withAccount {
+		fs.account = fs.String("account", "", "account ID or 0-based index")
+	}
+	return fs
+}
+
+// Decimal defines a flag holding an exact decimal, such as a price or a lot count.
+func (fs *flagSet) Decimal(name string, usage string) *models.Decimal {
+	d := new(models.Decimal)
+	fs.Var((*decimalValue)(d), name, usage)
+	return d
+}
+
+// decimalValue is a flag.Value parsed with models.ParseDecimal.
+type decimalValue models.Decimal
+
+func (v *decimalValue) String() string { return (*models.Decimal)(v).String() }
+
+func (v *decimalValue) Set(s string) error {
+	d, err := models.ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*v = decimalValue(d)
+	return nil
+}
+
+// parse parses flags that may appear before, between or after positional arguments.
+func (fs *flagSet) parse(args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, usagef("%v", err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	valid := validFormat(*fs.format)
+	if fs.formats != nil {
+		valid = slices.Contains(fs.formats, *fs.format)
+	}
+	if !valid {
+		return nil, usagef("unknown format %q", *fs.format)
+	}
+	return positional, nil
+}
+
+// resolveAccount maps --account to an account ID. It accepts an account ID or a
+// 0-based index; without it the first account that loaded is used.
+func resolveAccount(client Client, flagValue string) (string, error) {
+	accounts, err := client.GetAccounts()
+	if err != nil {
+		return "", err
+	}
+	return pickAccount(accounts, flagValue)
+}
+
+func pickAccount(accounts []models.AccountInfo, flagValue string) (string, error) {
+	if flagValue == "" {
+		for _, acc := range accounts {
+			if acc.LoadError == "" {
+				return acc.ID, nil
+			}
+		}
+		return "", fmt.Errorf("no available accounts")
+	}
+
+	for _, acc := range accounts {
+		if acc.ID == flagValue {
+			return acc.ID, nil
+		}
+	}
+	if idx, err := strconv.Atoi(flagValue); err == nil {
+		if idx < 0 || idx >= len(accounts) {
+			return "", usagef("account index %d out of range (0..%d)", idx, len(accounts)-1)
+		}
+		return accounts[idx].ID, nil
+	}
+	return "", usagef("account %q not found", flagValue)
+}
+
+// parseDate accepts YYYY-MM-DD (local midnight) or an RFC 3339 timestamp.
+// A date-only upper bound covers the whole day.
+func parseDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, usagef("invalid date %q: use YYYY-MM-DD or RFC 3339", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// dateRange parses --from/--to, defaulting the end to now and the start to now-span.
+func dateRange(from, to string, span time.Duration) (time.Time, time.Time, error) {
+	end := time.Now()
+	if to != "" {
+		t, err := parseDate(to, true)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = t
+	}
+	start := end.Add(-span)
+	if from != "" {
+		t, err := parseDate(from, false)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start = t
+	}
+	if start.After(end) {
+		return time.Time{}, time.Time{}, usagef("--from is after --to")
+	}
+	return start, end, nil
+}
+
+// normalizeSymbol upper-cases tickers typed in lower case.
+func normalizeSymbol(s string) string {
+	return strings.ToUpper(strings.TrimSpace(s))
+}
@@ -0,0 +1,408 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"finam-terminal/models"
+	"finam-terminal/risk"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/orders"
+)
+
+// fakeClient is a manual mock for Client.
+type fakeClient struct {
+	accounts  []models.AccountInfo
+	positions map[string][]models.Position
+	quotes    map[string]*models.Quote
+	orders    []models.Order
+	trades    []models.Trade
+	txs       []models.Transaction
+	bars      []models.Bar
+	lotSize   models.Decimal
+	params    *models.AssetParams
+
+	placeErr  error
+	placed    []string
+	cancelled []string
+
+	tradesFrom time.Time
+	tradesTo   time.Time
+	barsTF     marketdata.TimeFrame
+	barsFrom   time.Time
+	barsTo     time.Time
+	closed     bool
+	quotedWith string
+}
+
+func (f *fakeClient) GetAccounts() ([]models.AccountInfo, error) { return f.accounts, nil }
+
+func (f *fakeClient) GetAccountDetails(accountID string) (*models.AccountInfo, []models.Position, error) {
+	return nil, f.positions[accountID], nil
+}
+
+func (f *fakeClient) GetQuotes(accountID string, symbols []string) (map[string]*models.Quote, error) {
+	f.quotedWith = accountID
+	return f.quotes, nil
+}
+
+func (f *fakeClient) GetActiveOrders(accountID string) ([]models.Order, error) { return f.orders, nil }
+
+func (f *fakeClient) GetTradeHistoryRange(accountID string, from, to time.Time) ([]models.Trade, error) {
+	f.tradesFrom, f.tradesTo = from, to
+	return f.trades, nil
+}
+
+func (f *fakeClient) GetTransactions(accountID string, from, to time.Time) ([]models.Transaction, error) {
+	return f.txs, nil
+}
+
+func (f *fakeClient) GetBars(accountID string, symbol string, timeframe marketdata.TimeFrame, from, to time.Time) ([]models.Bar, error) {
+	f.barsTF, f.barsFrom, f.barsTo = timeframe, from, to
+	return f.bars, nil
+}
+
+func (f *fakeClient) GetLotSize(ticker string) models.Decimal { return f.lotSize }
+
+func (f *fakeClient) GetAssetParams(accountID string, symbol string) (*models.AssetParams, error) {
+	return f.params, nil
+}
+
+func (f *fakeClient) BuildOrder(accountID string, symbol string, buySell string, quantity models.Decimal, params *models.OrderParams) (*orders.Order, error) {
+	return &orders.Order{AccountId: accountID, Symbol: symbol + "@TQBR", Type: orders.OrderType_ORDER_TYPE_MARKET}, nil
+}
+
+func (f *fakeClient) BuildSLTPOrder(accountID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice models.Decimal) (*orders.SLTPOrder, error) {
+	return &orders.SLTPOrder{AccountId: accountID, Symbol: symbol + "@TQBR"}, nil
+}
+
+func (f *fakeClient) PlaceOrder(accountID string, symbol string, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error) {
+	if f.placeErr != nil {
+		return "", f.placeErr
+	}
+	f.placed = append(f.placed, fmt.Sprintf("%s %s %s %v %+v", accountID, symbol, buySell, quantity, params))
+	return "ORD100", nil
+}
+
+func (f *fakeClient) PlaceSLTPOrder(accountID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice models.Decimal) (string, error) {
+	if f.placeErr != nil {
+		return "", f.placeErr
+	}
+	f.placed = append(f.placed, fmt.Sprintf("%s %s %s sl=%v@%v tp=%v@%v", accountID, symbol, buySell, slQty, slPrice, tpQty, tpPrice))
+	return "ORD101", nil
+}
+
+func (f *fakeClient) CancelOrder(accountID, orderID string) error {
+	if f.placeErr != nil {
+		return f.placeErr
+	}
+	f.cancelled = append(f.cancelled, orderID)
+	return nil
+}
+
+func (f *fakeClient) Close() error {
+	f.closed = true
+	return nil
+}
+
+func run(t *testing.T, client *fakeClient, args ...string) (int, string, string) {
+	t.Helper()
+	return runWithRisk(t, client, risk.NewEngine(models.RiskLimits{}), args...)
+}
+
+// runWithRisk runs a command whose orders are checked by engine.
+func runWithRisk(t *testing.T, client *fakeClient, engine *risk.Engine, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run(args, func() (Client, *risk.Engine, error) {
+		if client == nil {
+			return nil, nil, fmt.Errorf("connect should not be called")
+		}
+		return client, engine, nil
+	}, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func testAccounts() []models.AccountInfo {
+	return []models.AccountInfo{
+		{ID: "BROKEN", LoadError: "access denied"},
+		{ID: "ACC001", Type: "UNION", Status: "ACTIVE", Equity: "1000.50", UnrealizedPnL: "N/A"},
+	}
+}
+
+func TestRun_AccountsJSON(t *testing.T) {
+	client := &fakeClient{accounts: testAccounts()}
+	code, out, errOut := run(t, client, "accounts", "--format", "json")
+	if code != ExitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, errOut)
+	}
+	if !client.closed {
+		t.Error("Expected client to be closed")
+	}
+	if !strings.Contains(out, `"equity": 1000.50`) {
+		t.Errorf("Expected numeric equity, got:\n%s", out)
+	}
+	if !strings.Contains(out, `"unrealized_pnl": null`) {
+		t.Errorf("Expected null for N/A, got:\n%s", out)
+	}
+	// Column order is preserved
+	if strings.Index(out, `"id"`) > strings.Index(out, `"type"`) {
+		t.Errorf("Expected id before type, got:\n%s", out)
+	}
+}
+
+func TestRun_PositionsCSVDefaultsToFirstLoadedAccount(t *testing.T) {
+	client := &fakeClient{
+		accounts: testAccounts(),
+		positions: map[string][]models.Position{
+			"ACC001": {{Symbol: "SBER@TQBR", Name: "Sberbank", Quantity: models.DecimalOf("100"), LotSize: models.DecimalFromInt(10), AveragePrice: models.DecimalOf("250,5")}},
+		},
+	}
+	code, out, errOut := run(t, client, "positions", "--format=csv")
+	if code != ExitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, errOut)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected header and one row, got:\n%s", out)
+	}
+	if lines[1] != "SBER@TQBR,Sberbank,100,10,250.5,0,0,0,0" {
+		t.Errorf("Unexpected CSV row %q", lines[1])
+	}
+}
+
+func TestRun_QuoteFlagsAfterSymbols(t *testing.T) {
+	client := &fakeClient{
+		accounts: testAccounts(),
+		quotes: map[string]*models.Quote{
+			"SBER@TQBR": {Symbol: "SBER@TQBR", Last: models.DecimalOf("285.00"), Bid: models.DecimalOf("284.90"), Ask: models.DecimalOf("285.10")},
+		},
+	}
+	code, out, errOut := run(t, client, "quote", "sber", "gazp", "--account", "1")
+	if code != ExitError {
+		t.Fatalf("Expected exit 1 for the missing symbol, got %d", code)
+	}
+	if client.quotedWith != "ACC001" {
+		t.Errorf("Expected account index 1 to resolve to ACC001, got %s", client.quotedWith)
+	}
+	if !strings.Contains(out, "SBER@TQBR") || !strings.Contains(out, "285.00") {
+		t.Errorf("Expected SBER row in table, got:\n%s", out)
+	}
+	if !strings.Contains(errOut, "no quote for GAZP") {
+		t.Errorf("Expected missing symbol on stderr, got %q", errOut)
+	}
+}
+
+func TestRun_TradesFilteredByDate(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.Local) }
+	client := &fakeClient{
+		accounts: testAccounts(),
+		trades: []models.Trade{
+			{ID: "T3", Timestamp: day(5)},
+			{ID: "T1", Timestamp: day(1)},
+			{ID: "T2", Timestamp: day(3)},
+		},
+	}
+	code, out, errOut := run(t, client, "trades", "--from", "2026-03-02", "--to", "2026-03-05", "--format", "csv")
+	if code != ExitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, errOut)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "T2,") || !strings.HasPrefix(lines[2], "T3,") {
+		t.Errorf("Expected T2 then T3, got:\n%s", out)
+	}
+	if !client.tradesFrom.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Expected trades requested from March 2nd, got %v", client.tradesFrom)
+	}
+}
+
+func TestRun_BarsTimeframe(t *testing.T) {
+	client := &fakeClient{
+		accounts: testAccounts(),
+		bars:     []models.Bar{{Open: 1, High: 2, Low: 0.5, Close: 1.5, Volume: 100}},
+	}
+	code, _, errOut := run(t, client, "bars", "SBER", "--tf", "h1")
+	if code != ExitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, errOut)
+	}
+	if client.barsTF != marketdata.TimeFrame_TIME_FRAME_H1 {
+		t.Errorf("Expected H1, got %v", client.barsTF)
+	}
+	if span := client.barsTo.Sub(client.barsFrom); span != 30*24*time.Hour {
+		t.Errorf("Expected default 30 day span, got %v", span)
+	}
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	tests := [][]string{
+		{"nope"},
+		{"quote"},
+		{"bars", "SBER", "--tf", "X"},
+		{"accounts", "--format", "xml"},
+		{"trades", "--from", "yesterday"},
+	}
+	for _, args := range tests {
+		code, _, _ := run(t, nil, args...)
+		if code != ExitUsage {
+			t.Errorf("%v: expected exit %d, got %d", args, ExitUsage, code)
+		}
+	}
+}
+
+func TestPickAccount(t *testing.T) {
+	accounts := testAccounts()
+	if id, err := pickAccount(accounts, ""); err != nil || id != "ACC001" {
+		t.Errorf("Default: got %q, %v", id, err)
+	}
+	if id, err := pickAccount(accounts, "0"); err != nil || id != "BROKEN" {
+		t.Errorf("Index 0: got %q, %v", id, err)
+	}
+	if id, err := pickAccount(accounts, "ACC001"); err != nil || id != "ACC001" {
+		t.Errorf("By ID: got %q, %v", id, err)
+	}
+	if _, err := pickAccount(accounts, "5"); err == nil {
+		t.Error("Expected error for index out of range")
+	}
+}
+
+func TestRun_PnLMatchesTradesWithFIFO(t *testing.T) {
+	day := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	client := &fakeClient{
+		accounts: testAccounts(),
+		positions: map[string][]models.Position{
+			"ACC001": {{Symbol: "SBER@TQBR", Quantity: models.DecimalOf("25"), AveragePrice: models.DecimalOf("290")}},
+		},
+		trades: []models.Trade{
+			{ID: "T1", Symbol: "SBER@TQBR", Side: "Buy", Price: models.DecimalOf("300"), Quantity: models.DecimalOf("10"), Timestamp: day},
+			{ID: "T2", Symbol: "SBER@TQBR", Side: "Sell", Price: models.DecimalOf("320"), Quantity: models.DecimalOf("15"), Timestamp: day.Add(2 * time.Hour)},
+		},
+	}
+	// 30 held before the history: the sell closes 15 of them at 290
+	code, out, errOut := run(t, client, "pnl", "--format", "csv")
+	if code != ExitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, errOut)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || lines[1] != "SBER@TQBR,450,450,0,1,0,100,0,25,294" {
+		t.Errorf("Unexpected P&L:\n%s", out)
+	}
+
+	code, out, _ = run(t, client, "pnl", "--by", "lot", "--method", "average", "--format", "csv")
+	if code != ExitOK || !strings.Contains(out, "SBER@TQBR,Long,15,") {
+		t.Errorf("Expected one closed lot at average cost, got:\n%s", out)
+	}
+
+	if code, _, _ := run(t, nil, "pnl", "--method", "lifo"); code != ExitUsage {
+		t.Errorf("Expected exit %d for an unknown method, got %d", ExitUsage, code)
+	}
+}
+
+func TestRun_TransactionsTotalsByMonth(t *testing.T) {
+	day := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	client := &fakeClient{
+		accounts: testAccounts(),
+		txs: []models.Transaction{
+			{ID: "X1", Category: models.TxDeposit, Amount: models.DecimalOf("100000"), Currency: "RUB", Timestamp: day},
+			{ID: "X2", Category: models.TxCommission, Amount: models.DecimalOf("-12.5"), Currency: "RUB", Timestamp: day},
+			{ID: "X3", Category: models.TxCommission, Amount: models.DecimalOf("-7.5"), Currency: "RUB", Timestamp: day.AddDate(0, 0, 3)},
+		},
+	}
+	code, out, errOut := run(t, client, "transactions", "--by", "month", "--format", "csv")
+	if code != ExitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, errOut)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || lines[2] != "2026-03,Commission,RUB,-20,2" {
+		t.Errorf("Unexpected totals:\n%s", out)
+	}
+
+	code, out, _ = run(t, client, "transactions", "--format", "csv")
+	if code != ExitOK || !strings.Contains(out, "X2,") || !strings.Contains(out, ",-12.5,RUB") {
+		t.Errorf("Expected the entries, got:\n%s", out)
+	}
+
+	if code, _, _ := run(t, nil, "transactions", "--by", "week"); code != ExitUsage {
+		t.Errorf("Expected exit %d for an unknown grouping, got %d", ExitUsage, code)
+	}
+}
+
+func TestRun_ExportAllAccounts(t *testing.T) {
+	accounts := append(testAccounts(), models.AccountInfo{ID: "ACC002"})
+	client := &fakeClient{
+		accounts: accounts,
+		positions: map[string][]models.Position{
+			"ACC001": {{Symbol: "SBER@TQBR", Quantity: models.DecimalOf("20"), LotSize: models.DecimalFromInt(10), AveragePrice: models.DecimalOf("300"), CurrentPrice: models.NA}},
+			"ACC002": {{Symbol: "GAZP@TQBR", Quantity: models.DecimalOf("5"), AveragePrice: models.DecimalOf("150,5")}},
+		},
+	}
+	code, out, errOut := run(t, client, "export", "positions", "--all")
+	if code != ExitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, errOut)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || lines[1] != "ACC001,SBER@TQBR,,20,2,300,,0,0,0" || !strings.HasPrefix(lines[2], "ACC002,GAZP@TQBR,,5,,150.5,") {
+		t.Errorf("Unexpected export:\n%s", out)
+	}
+
+	path := filepath.Join(t.TempDir(), "positions.xlsx")
+	code, _, errOut = run(t, client, "export", "positions", "--account", "ACC002", "--format", "xlsx", "--output", path)
+	if code != ExitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, errOut)
+	}
+	if data, err := os.ReadFile(path); err != nil || !bytes.HasPrefix(data, []byte("PK")) {
+		t.Errorf("Expected a workbook in %s, got %v", path, err)
+	}
+
+	if code, _, _ := run(t, nil, "export", "quotes"); code != ExitUsage {
+		t.Errorf("Expected exit %d for unknown data, got %d", ExitUsage, code)
+	}
+	if code, _, _ := run(t, nil, "export", "orders", "--format", "table"); code != ExitUsage {
+		t.Errorf("Expected exit %d for the table format, got %d", ExitUsage, code)
+	}
+}
+
+func TestRun_TaxSummaryAndCloses(t *testing.T) {
+	buy := time.Date(2025, 3, 3, 11, 0, 0, 0, time.Local)
+	client := &fakeClient{
+		accounts: testAccounts(),
+		trades: []models.Trade{
+			{ID: "B1", Symbol: "SBER@TQBR", Side: "Buy", Price: models.DecimalOf("250"), Quantity: models.DecimalOf("100"), Timestamp: buy},
+			{ID: "S1", Symbol: "SBER@TQBR", Side: "Sell", Price: models.DecimalOf("300"), Quantity: models.DecimalOf("100"), Timestamp: buy.AddDate(0, 1, 0)},
+		},
+		txs: []models.Transaction{
+			{Category: models.TxCommission, Symbol: "SBER", Amount: models.DecimalOf("-30"), Currency: "RUB", Timestamp: buy},
+			{Category: models.TxDividend, Symbol: "SBER", Amount: models.DecimalOf("1000"), Currency: "RUB", Timestamp: buy.AddDate(0, 2, 0)},
+		},
+	}
+	code, out, errOut := run(t, client, "tax", "--year", "2025", "--format", "csv")
+	if code != ExitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, errOut)
+	}
+	if !client.tradesFrom.Equal(time.Date(2022, 1, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Expected the history to start three years before, got %v", client.tradesFrom)
+	}
+	// (300 - 250) * 100 - 30 of commission, plus the dividend
+	for _, want := range []string{"trading_base,Tax base of trading,4970.00", "dividends,Dividends (income code 1010),1000.00", "tax,Tax on the year's investment income,776.00"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the summary:\n%s", want, out)
+		}
+	}
+
+	code, out, errOut = run(t, client, "tax", "--year", "2025", "--by", "closes", "--format", "json")
+	if code != ExitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, errOut)
+	}
+	if !strings.Contains(out, `"buy_trade_id": "B1"`) || !strings.Contains(out, `"sell_trade_id": "S1"`) || !strings.Contains(out, `"buy_commission": 30`) {
+		t.Errorf("Expected both legs with the commission, got:\n%s", out)
+	}
+
+	if code, _, _ := run(t, nil, "tax", "--by", "all"); code != ExitUsage {
+		t.Errorf("Expected exit %d for the whole report as a table, got %d", ExitUsage, code)
+	}
+}
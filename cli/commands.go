@@ -0,0 +1,423 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"finam-terminal/export"
+	"finam-terminal/models"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+)
+
+// timeframes maps --tf values to the API enum and the default history span.
+var timeframes = map[string]struct {
+	tf   marketdata.TimeFrame
+	span time.Duration
+}{
+	"M1":  {marketdata.TimeFrame_TIME_FRAME_M1, 24 * time.Hour},
+	"M5":  {marketdata.TimeFrame_TIME_FRAME_M5, 7 * 24 * time.Hour},
+	"M15": {marketdata.TimeFrame_TIME_FRAME_M15, 14 * 24 * time.Hour},
+	"M30": {marketdata.TimeFrame_TIME_FRAME_M30, 30 * 24 * time.Hour},
+	"H1":  {marketdata.TimeFrame_TIME_FRAME_H1, 30 * 24 * time.Hour},
+	"H2":  {marketdata.TimeFrame_TIME_FRAME_H2, 60 * 24 * time.Hour},
+	"H4":  {marketdata.TimeFrame_TIME_FRAME_H4, 90 * 24 * time.Hour},
+	"H8":  {marketdata.TimeFrame_TIME_FRAME_H8, 180 * 24 * time.Hour},
+	"D":   {marketdata.TimeFrame_TIME_FRAME_D, 365 * 24 * time.Hour},
+	"W":   {marketdata.TimeFrame_TIME_FRAME_W, 5 * 365 * 24 * time.Hour},
+	"MN":  {marketdata.TimeFrame_TIME_FRAME_MN, 10 * 365 * 24 * time.Hour},
+	"QR":  {marketdata.TimeFrame_TIME_FRAME_QR, 10 * 365 * 24 * time.Hour},
+}
+
+// tradeHistoryDays is the period of trades used without --from.
+const tradeHistoryDays = 30
+
+// transactionDays is the period of cash transactions used without --from.
+const transactionDays = 90
+
+// connectAccount connects and resolves the --account flag of fs.
+func (e *env) connectAccount(fs *flagSet) (Client, string, error) {
+	client, err := e.api()
+	if err != nil {
+		return nil, "", err
+	}
+	accountID, err := resolveAccount(client, *fs.account)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, accountID, nil
+}
+
+func runAccounts(e *env, args []string) error {
+	fs := newFlagSet(e, "accounts", false)
+	rest, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected argument %q", rest[0])
+	}
+
+	client, err := e.api()
+	if err != nil {
+		return err
+	}
+	accounts, err := client.GetAccounts()
+	if err != nil {
+		return err
+	}
+
+	t := export.NewTable("", export.Text("id"), export.Text("type"), export.Text("status"),
+		export.Num("equity"), export.Num("unrealized_pnl"), export.Text("open_date"),
+		export.Text("error"))
+	for _, acc := range accounts {
+		openDate := ""
+		if !acc.OpenDate.IsZero() {
+			openDate = acc.OpenDate.Format("2006-01-02")
+		}
+		t.Add(acc.ID, acc.Type, acc.Status, acc.Equity, acc.UnrealizedPnL, openDate, acc.LoadError)
+	}
+	return write(e.stdout, t, *fs.format)
+}
+
+func runPositions(e *env, args []string) error {
+	fs := newFlagSet(e, "positions", true)
+	rest, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected argument %q", rest[0])
+	}
+
+	client, accountID, err := e.connectAccount(fs)
+	if err != nil {
+		return err
+	}
+	_, positions, err := client.GetAccountDetails(accountID)
+	if err != nil {
+		return err
+	}
+
+	t := export.NewTable("", export.Text("symbol"), export.Text("name"), export.Num("quantity"),
+		export.Num("lots"), export.Num("average_price"), export.Num("current_price"),
+		export.Num("daily_pnl"), export.Num("unrealized_pnl"), export.Num("value"))
+	for _, p := range positions {
+		t.Add(p.Symbol, p.Name, p.Quantity.String(), export.Lots(p.Quantity, p.LotSize), p.AveragePrice.String(),
+			p.CurrentPrice.String(), p.DailyPnL.String(), p.UnrealizedPnL.String(), p.TotalValue.String())
+	}
+	return write(e.stdout, t, *fs.format)
+}
+
+func runQuote(e *env, args []string) error {
+	fs := newFlagSet(e, "quote", true)
+	symbols, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(symbols) == 0 {
+		return usagef("at least one symbol is required")
+	}
+	for i := range symbols {
+		symbols[i] = normalizeSymbol(symbols[i])
+	}
+
+	client, accountID, err := e.connectAccount(fs)
+	if err != nil {
+		return err
+	}
+	quotes, err := client.GetQuotes(accountID, symbols)
+	if err != nil {
+		return err
+	}
+
+	t := export.NewTable("", export.Text("symbol"), export.Num("last"), export.Num("bid"),
+		export.Num("bid_size"), export.Num("ask"), export.Num("ask_size"), export.Num("open"),
+		export.Num("high"), export.Num("low"), export.Num("close"), export.Num("volume"),
+		export.Text("time"))
+	var missing []string
+	for _, sym := range symbols {
+		q := findQuote(quotes, sym)
+		if q == nil {
+			missing = append(missing, sym)
+			continue
+		}
+		t.Add(q.Symbol, q.Last.String(), q.Bid.String(), q.BidSize.String(), q.Ask.String(), q.AskSize.String(),
+			q.Open.String(), q.High.String(), q.Low.String(), q.Close.String(), q.Volume.String(), export.FormatTime(q.Timestamp))
+	}
+	if err := write(e.stdout, t, *fs.format); err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no quote for %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// findQuote looks a requested symbol up in GetQuotes results, which are keyed by
+// full symbol (TICKER@MIC).
+func findQuote(quotes map[string]*models.Quote, symbol string) *models.Quote {
+	if q, ok := quotes[symbol]; ok {
+		return q
+	}
+	for key, q := range quotes {
+		if strings.HasPrefix(key, symbol+"@") {
+			return q
+		}
+	}
+	return nil
+}
+
+func runOrders(e *env, args []string) error {
+	fs := newFlagSet(e, "orders", true)
+	active := fs.Bool("active", false, "only working orders (Active or Partial)")
+	rest, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected argument %q", rest[0])
+	}
+
+	client, accountID, err := e.connectAccount(fs)
+	if err != nil {
+		return err
+	}
+	orders, err := client.GetActiveOrders(accountID)
+	if err != nil {
+		return err
+	}
+
+	t := export.NewTable("", export.Text("id"), export.Text("symbol"), export.Text("side"),
+		export.Text("type"), export.Text("status"), export.Num("quantity"), export.Num("lots"),
+		export.Num("executed"), export.Num("limit_price"), export.Num("stop_price"),
+		export.Num("sl_price"), export.Num("tp_price"), export.Text("validity"), export.Text("time"))
+	for _, o := range orders {
+		if *active && o.Status != "Active" && o.Status != "Partial" {
+			continue
+		}
+		t.Add(o.ID, o.Symbol, o.Side, o.Type, o.Status,
+			o.Quantity.String(), export.Lots(o.Quantity, client.GetLotSize(o.Symbol)), o.ExecutedQty.String(),
+			o.LimitPrice.String(), o.StopPrice.String(), o.SLPrice.String(), o.TPPrice.String(), o.Validity,
+			export.FormatTime(o.CreationTime))
+	}
+	return write(e.stdout, t, *fs.format)
+}
+
+func runTrades(e *env, args []string) error {
+	fs := newFlagSet(e, "trades", true)
+	from := fs.String("from", "", "start date (default: 30 days ago)")
+	to := fs.String("to", "", "end date (default: now)")
+	rest, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected argument %q", rest[0])
+	}
+	start, end, err := dateRange(*from, *to, tradeHistoryDays*24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	client, accountID, err := e.connectAccount(fs)
+	if err != nil {
+		return err
+	}
+	trades, err := client.GetTradeHistoryRange(accountID, start, end)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].Timestamp.Before(trades[j].Timestamp) })
+
+	t := export.NewTable("", export.Text("id"), export.Text("symbol"), export.Text("side"),
+		export.Num("price"), export.Num("quantity"), export.Num("lots"), export.Num("total"),
+		export.Text("time"))
+	for _, tr := range trades {
+		if tr.Timestamp.Before(start) || tr.Timestamp.After(end) {
+			continue
+		}
+		t.Add(tr.ID, tr.Symbol, tr.Side, tr.Price.String(), tr.Quantity.String(),
+			export.Lots(tr.Quantity, client.GetLotSize(tr.Symbol)), tr.Total.String(), export.FormatTime(tr.Timestamp))
+	}
+	return write(e.stdout, t, *fs.format)
+}
+
+func runTransactions(e *env, args []string) error {
+	fs := newFlagSet(e, "transactions", true)
+	from := fs.String("from", "", "start date (default: 90 days ago)")
+	to := fs.String("to", "", "end date (default: now)")
+	by := fs.String("by", "entry", "grouping: entry, month, quarter or year")
+	rest, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected argument %q", rest[0])
+	}
+	switch *by {
+	case "entry", models.PeriodMonth, models.PeriodQuarter, models.PeriodYear:
+	default:
+		return usagef("unknown grouping %q", *by)
+	}
+	start, end, err := dateRange(*from, *to, transactionDays*24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	client, accountID, err := e.connectAccount(fs)
+	if err != nil {
+		return err
+	}
+	txs, err := client.GetTransactions(accountID, start, end)
+	if err != nil {
+		return err
+	}
+
+	if *by != "entry" {
+		t := export.NewTable("", export.Text("period"), export.Text("category"),
+			export.Text("currency"), export.Num("amount"), export.Num("count"))
+		for _, total := range models.SummarizeTransactions(txs, *by) {
+			t.Add(total.Period, total.Category, total.Currency, total.Amount.Trim().String(), strconv.Itoa(total.Count))
+		}
+		return write(e.stdout, t, *fs.format)
+	}
+	t := export.NewTable("", export.Text("id"), export.Text("time"), export.Text("category"),
+		export.Text("description"), export.Text("symbol"), export.Num("amount"),
+		export.Text("currency"))
+	for _, tx := range txs {
+		t.Add(tx.ID, export.FormatTime(tx.Timestamp), tx.Category, tx.Description, tx.Symbol, tx.Amount.String(), tx.Currency)
+	}
+	return write(e.stdout, t, *fs.format)
+}
+
+func runBars(e *env, args []string) error {
+	fs := newFlagSet(e, "bars", true)
+	tfName := fs.String("tf", "D", "timeframe: M1, M5, M15, M30, H1, H2, H4, H8, D, W, MN, QR")
+	from := fs.String("from", "", "start date (default depends on --tf)")
+	to := fs.String("to", "", "end date (default: now)")
+	rest, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usagef("exactly one symbol is required")
+	}
+	symbol := normalizeSymbol(rest[0])
+
+	tf, ok := timeframes[strings.ToUpper(*tfName)]
+	if !ok {
+		return usagef("unknown timeframe %q", *tfName)
+	}
+	start, end, err := dateRange(*from, *to, tf.span)
+	if err != nil {
+		return err
+	}
+
+	client, accountID, err := e.connectAccount(fs)
+	if err != nil {
+		return err
+	}
+	bars, err := client.GetBars(accountID, symbol, tf.tf, start, end)
+	if err != nil {
+		return err
+	}
+
+	t := export.NewTable("", export.Text("time"), export.Num("open"), export.Num("high"),
+		export.Num("low"), export.Num("close"), export.Num("volume"))
+	for _, b := range bars {
+		t.Add(export.FormatTime(b.Timestamp), formatFloat(b.Open), formatFloat(b.High), formatFloat(b.Low),
+			formatFloat(b.Close), formatFloat(b.Volume))
+	}
+	return write(e.stdout, t, *fs.format)
+}
+
+func runPnL(e *env, args []string) error {
+	fs := newFlagSet(e, "pnl", true)
+	method := fs.String("method", models.MatchFIFO, "lot matching: fifo or average")
+	by := fs.String("by", "instrument", "grouping: instrument, day or lot")
+	from := fs.String("from", "", "first trade date to replay (default: 30 days ago)")
+	to := fs.String("to", "", "last trade date to replay (default: now)")
+	rest, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected argument %q", rest[0])
+	}
+	*method = strings.ToLower(*method)
+	if *method != models.MatchFIFO && *method != models.MatchAverage {
+		return usagef("unknown method %q", *method)
+	}
+	if *by != "instrument" && *by != "day" && *by != "lot" {
+		return usagef("unknown grouping %q", *by)
+	}
+	start, end, err := dateRange(*from, *to, tradeHistoryDays*24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	client, accountID, err := e.connectAccount(fs)
+	if err != nil {
+		return err
+	}
+	trades, err := client.GetTradeHistoryRange(accountID, start, end)
+	if err != nil {
+		return err
+	}
+	// Positions held before the history window are matched at the broker's average price
+	_, positions, err := client.GetAccountDetails(accountID)
+	if err != nil {
+		return err
+	}
+	report, err := models.ComputePnL(trades, models.OpeningLots(positions, trades), *method)
+	if err != nil {
+		return err
+	}
+
+	var t *export.Table
+	switch *by {
+	case "day":
+		t = export.NewTable("", export.Text("date"), export.Num("realized"), export.Num("closes"))
+		for _, d := range report.Days {
+			t.Add(d.Date.Format("2006-01-02"), d.Realized.Trim().String(), strconv.Itoa(d.Closes))
+		}
+	case "lot":
+		t = export.NewTable("", export.Text("symbol"), export.Text("side"), export.Num("quantity"),
+			export.Num("open_price"), export.Num("close_price"), export.Text("opened"),
+			export.Text("closed"), export.Num("holding_hours"), export.Text("close_trade_id"),
+			export.Num("realized"))
+		for _, c := range report.Closed {
+			holding := ""
+			if !c.Opened.IsZero() {
+				holding = formatFloat(math.Round(c.Holding().Hours()*100) / 100)
+			}
+			t.Add(c.Symbol, c.Side, c.Quantity.Trim().String(), c.OpenPrice.Trim().String(), c.ClosePrice.Trim().String(),
+				export.FormatTime(c.Opened), export.FormatTime(c.Closed), holding, c.CloseTradeID, c.PnL.Trim().String())
+		}
+	default:
+		t = export.NewTable("", export.Text("symbol"), export.Num("realized"),
+			export.Num("gross_profit"), export.Num("gross_loss"), export.Num("wins"),
+			export.Num("losses"), export.Num("win_rate"), export.Num("avg_holding_hours"),
+			export.Num("open_quantity"), export.Num("open_price"))
+		row := func(symbol string, s models.PnLStats, openQty, openPrice string) {
+			t.Add(symbol, s.Realized.Trim().String(), s.GrossProfit.Trim().String(), s.GrossLoss.Trim().String(),
+				strconv.Itoa(s.Wins), strconv.Itoa(s.Losses), formatFloat(math.Round(s.WinRate()*100)/100),
+				formatFloat(math.Round(s.AvgHolding.Hours()*100)/100), openQty, openPrice)
+		}
+		for _, inst := range report.Instruments {
+			openQty, openPrice := "", ""
+			if !inst.OpenQuantity.IsZero() {
+				openQty, openPrice = inst.OpenQuantity.Trim().String(), inst.OpenPrice.Trim().String()
+			}
+			row(inst.Symbol, inst.PnLStats, openQty, openPrice)
+		}
+		row("TOTAL", report.Total, "", "")
+	}
+	return write(e.stdout, t, *fs.format)
+}
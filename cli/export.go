@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"finam-terminal/export"
+	"finam-terminal/models"
+)
+
+func runExport(e *env, args []string) error {
+	fs := &flagSet{FlagSet: flag.NewFlagSet("export", flag.ContinueOnError), formats: export.Formats}
+	fs.SetOutput(e.stderr)
+	fs.format = fs.String("format", export.FormatCSV, "file format: csv, json or xlsx")
+	fs.account = fs.String("account", "", "account ID or 0-based index")
+	all := fs.Bool("all", false, "export every available account")
+	from := fs.String("from", "", "start date of trades and transactions (default: 30 and 90 days ago)")
+	to := fs.String("to", "", "end date of trades and transactions (default: now)")
+	output := fs.String("output", "", "file to write (default: standard output)")
+	rest, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usagef("expected one of %s", strings.Join(export.Kinds, ", "))
+	}
+	kind := rest[0]
+	if !slices.Contains(export.Kinds, kind) {
+		return usagef("unknown data %q: expected one of %s", kind, strings.Join(export.Kinds, ", "))
+	}
+	if *all && *fs.account != "" {
+		return usagef("--account and --all cannot be combined")
+	}
+	days := tradeHistoryDays
+	if kind == export.KindTransactions {
+		days = transactionDays
+	}
+	start, end, err := dateRange(*from, *to, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	client, err := e.api()
+	if err != nil {
+		return err
+	}
+	var accountIDs []string
+	if *all {
+		accounts, err := client.GetAccounts()
+		if err != nil {
+			return err
+		}
+		for _, acc := range accounts {
+			if acc.LoadError == "" {
+				accountIDs = append(accountIDs, acc.ID)
+			}
+		}
+	} else {
+		accountID, err := resolveAccount(client, *fs.account)
+		if err != nil {
+			return err
+		}
+		accountIDs = []string{accountID}
+	}
+
+	t, err := exportTable(client, kind, accountIDs, start, end)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		return export.Write(e.stdout, t, *fs.format)
+	}
+	err = writeFile(*output, func(w io.Writer) error { return export.Write(w, t, *fs.format) })
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "exported %d rows to %s\n", len(t.Rows), *output)
+	return nil
+}
+
+// exportTable fetches kind for every account in accountIDs. Trades and transactions are
+// limited to the period between start and end.
+func exportTable(client Client, kind string, accountIDs []string, start, end time.Time) (*export.Table, error) {
+	switch kind {
+	case export.KindPositions:
+		data := make(map[string][]models.Position)
+		for _, id := range accountIDs {
+			_, positions, err := client.GetAccountDetails(id)
+			if err != nil {
+				return nil, err
+			}
+			data[id] = positions
+		}
+		return export.Positions(data), nil
+	case export.KindTrades:
+		data := make(map[string][]models.Trade)
+		for _, id := range accountIDs {
+			trades, err := client.GetTradeHistoryRange(id, start, end)
+			if err != nil {
+				return nil, err
+			}
+			data[id] = slices.DeleteFunc(trades, func(t models.Trade) bool {
+				return t.Timestamp.Before(start) || t.Timestamp.After(end)
+			})
+		}
+		return export.Trades(data, client.GetLotSize), nil
+	case export.KindOrders:
+		data := make(map[string][]models.Order)
+		for _, id := range accountIDs {
+			orders, err := client.GetActiveOrders(id)
+			if err != nil {
+				return nil, err
+			}
+			data[id] = orders
+		}
+		return export.Orders(data, client.GetLotSize), nil
+	default:
+		data := make(map[string][]models.Transaction)
+		for _, id := range accountIDs {
+			txs, err := client.GetTransactions(id, start, end)
+			if err != nil {
+				return nil, err
+			}
+			data[id] = txs
+		}
+		return export.Transactions(data), nil
+	}
+}
+
+// writeFile creates path and writes it with write.
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,413 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"finam-terminal/export"
+	"finam-terminal/models"
+	"finam-terminal/risk"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/orders"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rejectedError marks an order request the broker answered with a refusal,
+// as opposed to a connection failure. It exits with ExitRejected.
+type rejectedError struct{ err error }
+
+func (e *rejectedError) Error() string {
+	// status.FromError prefixes the message with the wrapping context; show the broker's own text
+	var gs interface{ GRPCStatus() *status.Status }
+	if errors.As(e.err, &gs) && gs.GRPCStatus().Message() != "" {
+		return "rejected by broker: " + gs.GRPCStatus().Message()
+	}
+	return "rejected by broker: " + e.err.Error()
+}
+
+func (e *rejectedError) Unwrap() error { return e.err }
+
+// classifyOrderError wraps err as a rejection when the broker processed the request
+// and refused it. Transport and authentication failures are returned unchanged.
+func classifyOrderError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.OK, codes.Canceled, codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented,
+		codes.Internal, codes.Unavailable, codes.Unauthenticated:
+		return err
+	}
+	return &rejectedError{err: err}
+}
+
+// orderTypes maps --type values to the order types of the order modal.
+var orderTypes = map[string]string{
+	"market":      models.OrderTypeMarket,
+	"limit":       models.OrderTypeLimit,
+	"stop":        models.OrderTypeStop,
+	"stop-loss":   models.OrderTypeStop,
+	"take-profit": models.OrderTypeTakeProfit,
+	"tp":          models.OrderTypeTakeProfit,
+	"stop-limit":  models.OrderTypeStopLimit,
+}
+
+// validities maps --validity values to the validities of the order modal. GTD is left out:
+// the order request has no expiry date and only a running terminal cancels GTD orders.
+var validities = map[string]string{
+	"day": models.ValidityDay,
+	"gtc": models.ValidityGTC,
+}
+
+func runOrder(e *env, args []string) error {
+	if len(args) == 0 {
+		return usagef("missing subcommand: place, cancel or sltp")
+	}
+	switch args[0] {
+	case "place":
+		return runOrderPlace(e, args[1:])
+	case "cancel":
+		return runOrderCancel(e, args[1:])
+	case "sltp":
+		return runOrderSLTP(e, args[1:])
+	}
+	return usagef("unknown subcommand %q", args[0])
+}
+
+// parseSide accepts buy/sell in any case.
+func parseSide(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "buy":
+		return "Buy", nil
+	case "sell":
+		return "Sell", nil
+	case "":
+		return "", usagef("--side is required (buy or sell)")
+	}
+	return "", usagef("invalid --side %q: use buy or sell", s)
+}
+
+// orderPlaceArgs are the validated flags of `order place`.
+type orderPlaceArgs struct {
+	symbol string
+	side   string
+	lots   models.Decimal
+	params models.OrderParams
+}
+
+// validateOrderPlace applies the order modal's rules: a symbol, a positive lot count and
+// the prices the order type needs. Prices and a validity that the type does not use are
+// refused rather than silently dropped.
+func validateOrderPlace(symbol, side, orderType, validity string, lots, price, stopPrice models.Decimal) (orderPlaceArgs, error) {
+	var a orderPlaceArgs
+	if symbol == "" {
+		return a, usagef("--symbol is required")
+	}
+	a.symbol = normalizeSymbol(symbol)
+
+	var err error
+	if a.side, err = parseSide(side); err != nil {
+		return a, err
+	}
+	if lots.Sign() <= 0 {
+		return a, usagef("--lots must be greater than 0")
+	}
+	a.lots = lots
+
+	t, ok := orderTypes[strings.ToLower(orderType)]
+	if !ok {
+		return a, usagef("invalid --type %q: use market, limit, stop, take-profit or stop-limit", orderType)
+	}
+	a.params.OrderType = t
+
+	switch t {
+	case models.OrderTypeLimit:
+		if price.Sign() <= 0 {
+			return a, usagef("--price is required for limit orders")
+		}
+		if !stopPrice.IsZero() {
+			return a, usagef("--stop-price is not used by limit orders")
+		}
+		a.params.LimitPrice = price
+	case models.OrderTypeStop, models.OrderTypeTakeProfit:
+		if stopPrice.Sign() <= 0 {
+			return a, usagef("--stop-price is required for %s orders", strings.ToLower(orderType))
+		}
+		if !price.IsZero() {
+			return a, usagef("--price is not used by %s orders", strings.ToLower(orderType))
+		}
+		a.params.StopPrice = stopPrice
+	case models.OrderTypeStopLimit:
+		if stopPrice.Sign() <= 0 || price.Sign() <= 0 {
+			return a, usagef("--stop-price and --price are required for stop-limit orders")
+		}
+		a.params.StopPrice = stopPrice
+		a.params.LimitPrice = price
+	default:
+		if !price.IsZero() || !stopPrice.IsZero() {
+			return a, usagef("market orders take no --price or --stop-price")
+		}
+	}
+
+	if validity != "" {
+		if t == models.OrderTypeMarket {
+			return a, usagef("market orders take no --validity")
+		}
+		if strings.EqualFold(validity, models.ValidityGTD) {
+			return a, usagef("--validity gtd is not supported: the terminal cancels GTD orders itself, place them from the terminal")
+		}
+		v, ok := validities[strings.ToLower(validity)]
+		if !ok {
+			return a, usagef("invalid --validity %q: use day or gtc", validity)
+		}
+		a.params.Validity = v
+	}
+	return a, nil
+}
+
+func runOrderPlace(e *env, args []string) error {
+	fs := newFlagSet(e, "order place", true)
+	symbol := fs.String("symbol", "", "instrument ticker or TICKER@MIC")
+	side := fs.String("side", "", "buy or sell")
+	lotCount := fs.Decimal("lots", "quantity in lots")
+	orderType := fs.String("type", "market", "market, limit, stop, take-profit or stop-limit")
+	price := fs.Decimal("price", "limit price (limit and stop-limit orders)")
+	stopPrice := fs.Decimal("stop-price", "trigger price (stop, take-profit and stop-limit orders)")
+	validity := fs.String("validity", "", "day or gtc (default gtc; not for market orders)")
+	dryRun := fs.Bool("dry-run", false, "print the resolved order without sending it")
+	rest, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected argument %q", rest[0])
+	}
+
+	a, err := validateOrderPlace(*symbol, *side, *orderType, *validity, *lotCount, *price, *stopPrice)
+	if err != nil {
+		return err
+	}
+
+	client, accountID, err := e.connectAccount(fs)
+	if err != nil {
+		return err
+	}
+	e.warnUnknownLotSize(client, a.symbol)
+
+	var params *models.OrderParams
+	if a.params.OrderType != models.OrderTypeMarket {
+		params = &a.params
+	}
+	if err := e.checkRisk(client, accountID, a.symbol, a.side, a.lots, a.params.LimitPrice, a.params.StopPrice); err != nil {
+		return err
+	}
+
+	if *dryRun {
+		req, err := client.BuildOrder(accountID, a.symbol, a.side, a.lots, params)
+		if err != nil {
+			return usagef("%v", err)
+		}
+		fmt.Fprintln(e.stderr, "dry run: order not sent")
+		return write(e.stdout, orderTable(req), *fs.format)
+	}
+
+	id, err := client.PlaceOrder(accountID, a.symbol, a.side, a.lots, params)
+	if err != nil {
+		return classifyOrderError(err)
+	}
+	e.recordOrder(accountID)
+	return write(e.stdout, resultTable(id, "placed"), *fs.format)
+}
+
+func runOrderSLTP(e *env, args []string) error {
+	fs := newFlagSet(e, "order sltp", true)
+	symbol := fs.String("symbol", "", "instrument ticker or TICKER@MIC")
+	side := fs.String("side", "", "side of the protective orders: sell protects a long, buy a short")
+	lotCount := fs.Decimal("lots", "quantity in lots for both legs")
+	sl := fs.Decimal("sl", "stop-loss price")
+	tp := fs.Decimal("tp", "take-profit price")
+	dryRun := fs.Bool("dry-run", false, "print the resolved order without sending it")
+	rest, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected argument %q", rest[0])
+	}
+
+	if *symbol == "" {
+		return usagef("--symbol is required")
+	}
+	sym := normalizeSymbol(*symbol)
+	dir, err := parseSide(*side)
+	if err != nil {
+		return err
+	}
+	if lotCount.Sign() <= 0 {
+		return usagef("--lots must be greater than 0")
+	}
+	if sl.Sign() < 0 || tp.Sign() < 0 {
+		return usagef("--sl and --tp must not be negative")
+	}
+	if sl.IsZero() && tp.IsZero() {
+		return usagef("at least one of --sl or --tp is required")
+	}
+
+	client, accountID, err := e.connectAccount(fs)
+	if err != nil {
+		return err
+	}
+	e.warnUnknownLotSize(client, sym)
+	if err := e.checkRisk(client, accountID, sym, dir, *lotCount, *sl, *tp); err != nil {
+		return err
+	}
+
+	if *dryRun {
+		req, err := client.BuildSLTPOrder(accountID, sym, dir, *lotCount, *sl, *lotCount, *tp)
+		if err != nil {
+			return usagef("%v", err)
+		}
+		fmt.Fprintln(e.stderr, "dry run: order not sent")
+		return write(e.stdout, sltpTable(req), *fs.format)
+	}
+
+	id, err := client.PlaceSLTPOrder(accountID, sym, dir, *lotCount, *sl, *lotCount, *tp)
+	if err != nil {
+		return classifyOrderError(err)
+	}
+	e.recordOrder(accountID)
+	return write(e.stdout, resultTable(id, "placed"), *fs.format)
+}
+
+func runOrderCancel(e *env, args []string) error {
+	fs := newFlagSet(e, "order cancel", true)
+	dryRun := fs.Bool("dry-run", false, "resolve the account and print the request without sending it")
+	rest, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usagef("exactly one order ID is required")
+	}
+	orderID := rest[0]
+
+	client, accountID, err := e.connectAccount(fs)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Fprintln(e.stderr, "dry run: cancellation not sent")
+		t := export.NewTable("", export.Text("account_id"), export.Text("order_id"))
+		t.Add(accountID, orderID)
+		return write(e.stdout, t, *fs.format)
+	}
+
+	if err := client.CancelOrder(accountID, orderID); err != nil {
+		return classifyOrderError(err)
+	}
+	return write(e.stdout, resultTable(orderID, "cancelled"), *fs.format)
+}
+
+// checkRisk runs the terminal's pre-trade checks on an order about to be placed, with the
+// position, last price and trading parameters loaded from the broker. The CLI has no
+// confirmation step, so a violation is never overridden and exits with ExitUsage.
+func (e *env) checkRisk(client Client, accountID, symbol, side string, lots models.Decimal, prices ...models.Decimal) error {
+	o := risk.Order{
+		AccountID: accountID,
+		Ticker:    symbol,
+		Side:      side,
+		Lots:      lots,
+		LotSize:   client.GetLotSize(symbol),
+	}
+	for _, p := range prices {
+		if p.Sign() > 0 {
+			o.Prices = append(o.Prices, p)
+		}
+	}
+
+	_, positions, err := client.GetAccountDetails(accountID)
+	if err != nil {
+		return err
+	}
+	for _, pos := range positions {
+		if pos.Symbol == symbol || pos.Ticker == symbol {
+			if pos.Quantity.Known() {
+				o.Position = pos.Quantity
+			}
+			o.LastPrice = pos.CurrentPrice
+			break
+		}
+	}
+	if o.LastPrice.Sign() <= 0 {
+		if quotes, err := client.GetQuotes(accountID, []string{symbol}); err == nil {
+			for _, q := range quotes {
+				if q != nil && q.Last.Sign() > 0 {
+					o.LastPrice = q.Last
+				}
+			}
+		}
+	}
+	if params, err := client.GetAssetParams(accountID, symbol); err == nil {
+		o.Params = params
+	} else {
+		fmt.Fprintf(e.stderr, "warning: trading parameters of %s unavailable, tradability is not checked: %v\n", symbol, err)
+	}
+
+	if err := e.risk.Check(o); err != nil {
+		return usagef("%v", err)
+	}
+	return nil
+}
+
+// recordOrder counts a placed order towards the daily order limit shared with the terminal.
+func (e *env) recordOrder(accountID string) {
+	if err := e.risk.Record(accountID); err != nil {
+		fmt.Fprintf(e.stderr, "warning: %v\n", err)
+	}
+}
+
+// warnUnknownLotSize notes on stderr that the quantity will be sent unconverted,
+// which is what the TUI does when the lot size is not cached.
+func (e *env) warnUnknownLotSize(client Client, symbol string) {
+	if client.GetLotSize(symbol).Sign() <= 0 {
+		fmt.Fprintf(e.stderr, "warning: lot size for %s is unknown, --lots is sent as the share quantity\n", symbol)
+	}
+}
+
+func resultTable(orderID, result string) *export.Table {
+	t := export.NewTable("", export.Text("order_id"), export.Text("result"))
+	t.Add(orderID, result)
+	return t
+}
+
+// orderTable lists the fields of a resolved order request.
+func orderTable(o *orders.Order) *export.Table {
+	t := export.NewTable("", export.Text("account_id"), export.Text("symbol"), export.Text("side"),
+		export.Text("type"), export.Num("quantity"), export.Num("limit_price"),
+		export.Num("stop_price"), export.Text("stop_condition"), export.Text("valid_before"))
+	t.Add(o.GetAccountId(), o.GetSymbol(), o.GetSide().String(), o.GetType().String(),
+		o.GetQuantity().GetValue(), o.GetLimitPrice().GetValue(), o.GetStopPrice().GetValue(),
+		o.GetStopCondition().String(), o.GetValidBefore().String())
+	return t
+}
+
+// sltpTable lists the fields of a resolved SL/TP request.
+func sltpTable(o *orders.SLTPOrder) *export.Table {
+	t := export.NewTable("", export.Text("account_id"), export.Text("symbol"), export.Text("side"),
+		export.Num("sl_quantity"), export.Num("sl_price"), export.Num("tp_quantity"),
+		export.Num("tp_price"), export.Text("valid_before"))
+	t.Add(o.GetAccountId(), o.GetSymbol(), o.GetSide().String(), o.GetQuantitySl().GetValue(),
+		o.GetSlPrice().GetValue(), o.GetQuantityTp().GetValue(), o.GetTpPrice().GetValue(),
+		o.GetValidBefore().String())
+	return t
+}
+
+// isRejected reports whether err is a broker rejection.
+func isRejected(err error) bool {
+	var rerr *rejectedError
+	return errors.As(err, &rerr)
+}
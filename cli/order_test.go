@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"finam-terminal/models"
+	"finam-terminal/risk"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOrderPlace_Limit(t *testing.T) {
+	client := &fakeClient{accounts: testAccounts(), lotSize: models.DecimalFromInt(10)}
+	code, out, errOut := run(t, client, "order", "place", "--symbol", "sber", "--side", "BUY", "--lots", "2", "--type", "limit", "--price", "250.5")
+	if code != ExitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, errOut)
+	}
+	if len(client.placed) != 1 || !strings.HasPrefix(client.placed[0], "ACC001 SBER Buy 2 &{OrderType:Limit LimitPrice:250.5") {
+		t.Errorf("Unexpected order %v", client.placed)
+	}
+	if !strings.Contains(out, "ORD100") {
+		t.Errorf("Expected order ID in output, got:\n%s", out)
+	}
+}
+
+func TestOrderPlace_StopLimitWithValidity(t *testing.T) {
+	client := &fakeClient{accounts: testAccounts(), lotSize: models.DecimalFromInt(10)}
+	code, _, errOut := run(t, client, "order", "place", "--symbol", "SBER", "--side", "sell", "--lots", "1",
+		"--type", "stop-limit", "--stop-price", "245", "--price", "244.5", "--validity", "day")
+	if code != ExitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, errOut)
+	}
+	if len(client.placed) != 1 || !strings.HasPrefix(client.placed[0], "ACC001 SBER Sell 1 &{OrderType:Stop-Limit LimitPrice:244.5 StopPrice:245 Validity:Day") {
+		t.Errorf("Unexpected order %v", client.placed)
+	}
+}
+
+func TestOrderPlace_DryRunDoesNotSend(t *testing.T) {
+	client := &fakeClient{accounts: testAccounts(), lotSize: models.DecimalFromInt(10)}
+	code, out, errOut := run(t, client, "order", "place", "--symbol", "SBER", "--side", "sell", "--lots", "1", "--dry-run", "--format", "json")
+	if code != ExitOK {
+		t.Fatalf("Expected exit 0, got %d: %s", code, errOut)
+	}
+	if len(client.placed) != 0 {
+		t.Errorf("Dry run must not place orders, got %v", client.placed)
+	}
+	if !strings.Contains(out, `"symbol": "SBER@TQBR"`) || !strings.Contains(out, `"type": "ORDER_TYPE_MARKET"`) {
+		t.Errorf("Expected resolved order, got:\n%s", out)
+	}
+	if !strings.Contains(errOut, "dry run") {
+		t.Errorf("Expected dry run note on stderr, got %q", errOut)
+	}
+}
+
+func TestOrderPlace_UnknownLotSizeWarns(t *testing.T) {
+	client := &fakeClient{accounts: testAccounts()}
+	_, _, errOut := run(t, client, "order", "place", "--symbol", "SBER", "--side", "buy", "--lots", "1", "--dry-run")
+	if !strings.Contains(errOut, "lot size for SBER is unknown") {
+		t.Errorf("Expected lot size warning, got %q", errOut)
+	}
+}
+
+func TestOrderPlace_ValidationErrors(t *testing.T) {
+	tests := [][]string{
+		{"order"},
+		{"order", "amend"},
+		{"order", "place", "--side", "buy", "--lots", "1"},
+		{"order", "place", "--symbol", "SBER", "--lots", "1"},
+		{"order", "place", "--symbol", "SBER", "--side", "hold", "--lots", "1"},
+		{"order", "place", "--symbol", "SBER", "--side", "buy", "--lots", "0"},
+		{"order", "place", "--symbol", "SBER", "--side", "buy", "--lots", "1", "--type", "limit"},
+		{"order", "place", "--symbol", "SBER", "--side", "buy", "--lots", "1", "--type", "stop", "--price", "10"},
+		{"order", "place", "--symbol", "SBER", "--side", "buy", "--lots", "1", "--price", "10"},
+		{"order", "place", "--symbol", "SBER", "--side", "buy", "--lots", "1", "--type", "stop-limit", "--stop-price", "10"},
+		{"order", "place", "--symbol", "SBER", "--side", "buy", "--lots", "1", "--validity", "day"},
+		{"order", "place", "--symbol", "SBER", "--side", "buy", "--lots", "1", "--type", "limit", "--price", "10", "--validity", "gtd"},
+		{"order", "place", "--symbol", "SBER", "--side", "buy", "--lots", "1", "--type", "limit", "--price", "10", "--validity", "week"},
+		{"order", "sltp", "--symbol", "SBER", "--side", "sell", "--lots", "1"},
+		{"order", "cancel"},
+	}
+	for _, args := range tests {
+		// A nil client fails the test if validation lets the request through to connect
+		code, _, _ := run(t, nil, args...)
+		if code != ExitUsage {
+			t.Errorf("%v: expected exit %d, got %d", args, ExitUsage, code)
+		}
+	}
+}
+
+func TestOrderPlace_BrokerRejection(t *testing.T) {
+	client := &fakeClient{
+		accounts: testAccounts(),
+		placeErr: fmt.Errorf("failed to place order: %w", status.Error(codes.FailedPrecondition, "недостаточно средств")),
+	}
+	code, _, errOut := run(t, client, "order", "place", "--symbol", "SBER", "--side", "buy", "--lots", "1")
+	if code != ExitRejected {
+		t.Fatalf("Expected exit %d, got %d", ExitRejected, code)
+	}
+	if !strings.Contains(errOut, "rejected by broker: недостаточно средств") {
+		t.Errorf("Expected broker message, got %q", errOut)
+	}
+}
+
+func TestOrderPlace_ConnectionFailureIsNotRejection(t *testing.T) {
+	client := &fakeClient{
+		accounts: testAccounts(),
+		placeErr: fmt.Errorf("failed to place order: %w", status.Error(codes.Unavailable, "connection refused")),
+	}
+	code, _, _ := run(t, client, "order", "place", "--symbol", "SBER", "--side", "buy", "--lots", "1")
+	if code != ExitError {
+		t.Errorf("Expected exit %d, got %d", ExitError, code)
+	}
+}
+
+func TestOrderSLTPAndCancel(t *testing.T) {
+	client := &fakeClient{accounts: testAccounts(), lotSize: models.DecimalFromInt(10)}
+	code, _, errOut := run(t, client, "order", "sltp", "--symbol", "SBER", "--side", "sell", "--lots", "3", "--sl", "240", "--tp", "270")
+	if code != ExitOK {
+		t.Fatalf("sltp: expected exit 0, got %d: %s", code, errOut)
+	}
+	if len(client.placed) != 1 || client.placed[0] != "ACC001 SBER Sell sl=3@240 tp=3@270" {
+		t.Errorf("Unexpected SL/TP order %v", client.placed)
+	}
+
+	code, out, errOut := run(t, client, "order", "cancel", "ORD7", "--account", "ACC001")
+	if code != ExitOK {
+		t.Fatalf("cancel: expected exit 0, got %d: %s", code, errOut)
+	}
+	if len(client.cancelled) != 1 || client.cancelled[0] != "ORD7" || !strings.Contains(out, "cancelled") {
+		t.Errorf("Unexpected cancel result %v, output:\n%s", client.cancelled, out)
+	}
+}
+
+func TestOrderPlace_RiskChecks(t *testing.T) {
+	positions := map[string][]models.Position{
+		"ACC001": {{Symbol: "SBER@TQBR", Ticker: "SBER", Quantity: models.DecimalFromInt(50), CurrentPrice: models.DecimalOf("285")}},
+	}
+
+	// 40 lots × 10 shares × 285 = 114 000 is over the order value limit
+	client := &fakeClient{accounts: testAccounts(), positions: positions, lotSize: models.DecimalFromInt(10)}
+	engine := risk.NewEngine(models.RiskLimits{MaxOrderValue: 100000, AllowOverride: true})
+	code, _, errOut := runWithRisk(t, client, engine, "order", "place", "--symbol", "SBER", "--side", "buy", "--lots", "40")
+	if code != ExitUsage || len(client.placed) != 0 {
+		t.Fatalf("Expected exit %d and no order, got %d and %v", ExitUsage, code, client.placed)
+	}
+	if !strings.Contains(errOut, "order value 114000.00 exceeds") {
+		t.Errorf("Expected the violation on stderr, got %q", errOut)
+	}
+
+	// A locked terminal refuses opening orders from the CLI but lets a position be reduced
+	client = &fakeClient{accounts: testAccounts(), positions: positions, lotSize: models.DecimalFromInt(10)}
+	engine = risk.NewEngine(models.RiskLimits{})
+	_ = engine.Lock("kill switch engaged")
+	code, _, errOut = runWithRisk(t, client, engine, "order", "place", "--symbol", "SBER", "--side", "buy", "--lots", "1")
+	if code != ExitUsage || !strings.Contains(errOut, "kill switch engaged") {
+		t.Errorf("Expected the lock to refuse a buy, got %d: %q", code, errOut)
+	}
+	code, _, errOut = runWithRisk(t, client, engine, "order", "sltp", "--symbol", "SBER", "--side", "sell", "--lots", "5", "--sl", "270")
+	if code != ExitOK || len(client.placed) != 1 {
+		t.Fatalf("Expected a protective sell to pass while locked, got %d: %q", code, errOut)
+	}
+	if n := engine.SentToday("ACC001"); n != 1 {
+		t.Errorf("Expected the placed order counted, got %d", n)
+	}
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"finam-terminal/export"
+)
+
+// Output formats accepted by --format.
+const (
+	FormatTable = "table"
+	FormatJSON  = export.FormatJSON
+	FormatCSV   = export.FormatCSV
+)
+
+func validFormat(format string) bool {
+	switch format {
+	case FormatTable, FormatJSON, FormatCSV:
+		return true
+	}
+	return false
+}
+
+// write renders t in the requested format: JSON and CSV through the writers shared with the
+// terminal exports, anything else as an aligned text table.
+func write(w io.Writer, t *export.Table, format string) error {
+	switch format {
+	case FormatJSON, FormatCSV:
+		return export.Write(w, t, format)
+	default:
+		return writeTable(w, t)
+	}
+}
+
+func writeTable(w io.Writer, t *export.Table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		headers[i] = strings.ToUpper(c.Key)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range t.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// formatFloat prints a float without trailing zeros.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"finam-terminal/export"
+	"finam-terminal/models"
+)
+
+// taxHistoryYears is how many years before the tax year the trade history starts when
+// the account opening is unknown and --from is not given.
+const taxHistoryYears = 3
+
+// taxTables are the tables of the tax report accepted by --by, with "all" for the whole
+// report in JSON and XLSX.
+var taxTables = []string{"summary", "closes", "income", "all"}
+
+func runTax(e *env, args []string) error {
+	fs := newFlagSet(e, "tax", true)
+	fs.formats = []string{FormatTable, FormatJSON, FormatCSV, export.FormatXLSX}
+	fs.Lookup("format").Usage = "output format: table, json, csv or xlsx"
+	year := fs.Int("year", time.Now().Year()-1, "tax year (default: last year)")
+	from := fs.String("from", "", "start of the trade history (default: the account opening)")
+	by := fs.String("by", "", "report table: summary, closes, income or all (default: all in xlsx, summary otherwise)")
+	output := fs.String("output", "", "file to write (default: standard output)")
+	rest, err := fs.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected argument %q", rest[0])
+	}
+	now := time.Now()
+	if *year < 2000 || *year > now.Year() {
+		return usagef("invalid year %d", *year)
+	}
+	if *by == "" {
+		*by = "summary"
+		if *fs.format == export.FormatXLSX {
+			*by = "all"
+		}
+	}
+	if !slices.Contains(taxTables, *by) {
+		return usagef("unknown table %q: expected one of %s", *by, strings.Join(taxTables, ", "))
+	}
+	if *by == "all" && *fs.format != FormatJSON && *fs.format != export.FormatXLSX {
+		return usagef("--by all needs --format json or xlsx")
+	}
+	var start time.Time
+	if *from != "" {
+		if start, err = parseDate(*from, false); err != nil {
+			return err
+		}
+	}
+
+	client, accountID, err := e.connectAccount(fs)
+	if err != nil {
+		return err
+	}
+	info, positions, err := client.GetAccountDetails(accountID)
+	if err != nil {
+		return err
+	}
+	if start.IsZero() {
+		start = time.Date(*year-taxHistoryYears, 1, 1, 0, 0, 0, 0, time.Local)
+		if info != nil && !info.OpenDate.IsZero() && info.OpenDate.Year() > 1970 {
+			start = info.OpenDate
+		}
+	}
+	// The history runs to now, so the current positions tell what was held before it
+	trades, err := client.GetTradeHistoryRange(accountID, start, now)
+	if err != nil {
+		return err
+	}
+	txs, err := client.GetTransactions(accountID, start, now)
+	if err != nil {
+		return err
+	}
+	report := models.ComputeTax(trades, models.OpeningLots(positions, trades), txs, *year)
+	if report.ForeignPayments > 0 {
+		fmt.Fprintf(e.stderr, "note: %d payments in foreign currencies are listed but not converted to rubles\n", report.ForeignPayments)
+	}
+
+	var tables []*export.Table
+	switch *by {
+	case "summary":
+		tables = []*export.Table{export.TaxSummary(report)}
+	case "closes":
+		tables = []*export.Table{export.TaxCloses(report)}
+	case "income":
+		tables = []*export.Table{export.TaxPayments(report)}
+	default:
+		tables = export.TaxReport(report)
+	}
+	write := func(w io.Writer) error {
+		switch {
+		case len(tables) > 1:
+			return export.WriteBook(w, tables, *fs.format)
+		case *fs.format == export.FormatXLSX:
+			return export.Write(w, tables[0], *fs.format)
+		default:
+			return write(w, tables[0], *fs.format)
+		}
+	}
+	if *output == "" {
+		return write(e.stdout)
+	}
+	if err := writeFile(*output, write); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "wrote the %d tax report to %s\n", *year, *output)
+	return nil
+}
@@ -0,0 +1,350 @@
+// Package conditional implements client-side conditional orders: an order that the
+// terminal sends when a price of the instrument crosses a level, for example "when LAST of
+// SBER@MISX crosses above 310, buy 5 lots at 310.5". The manager evaluates the conditions
+// against streamed quotes, saves the pending orders to a JSON file so they survive a
+// restart, and appends every trigger and its outcome to an audit file.
+package conditional
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"finam-terminal/models"
+
+	"finam-terminal/atomicfile"
+)
+
+// Price fields a condition can watch.
+const (
+	FieldLast = "LAST"
+	FieldBid  = "BID"
+	FieldAsk  = "ASK"
+)
+
+// Condition is a price crossing a level.
+type Condition struct {
+	Field string         `json:"field"` // FieldLast, FieldBid or FieldAsk
+	Above bool           `json:"above"` // Crossing upwards; otherwise downwards
+	Price models.Decimal `json:"price"`
+}
+
+// String formats the condition as "LAST crosses above 310".
+func (c Condition) String() string {
+	dir := "below"
+	if c.Above {
+		dir = "above"
+	}
+	return fmt.Sprintf("%s crosses %s %s", c.Field, dir, c.Price)
+}
+
+// Short formats the condition for a table cell: "LAST ↑ 310".
+func (c Condition) Short() string {
+	arrow := "↓"
+	if c.Above {
+		arrow = "↑"
+	}
+	return fmt.Sprintf("%s %s %s", c.Field, arrow, c.Price)
+}
+
+// Crossed reports whether the price moving from prev to price crossed the level. Reaching
+// the level counts as crossing it.
+func (c Condition) Crossed(prev, price models.Decimal) bool {
+	if c.Above {
+		return prev.Cmp(c.Price) < 0 && price.Cmp(c.Price) >= 0
+	}
+	return prev.Cmp(c.Price) > 0 && price.Cmp(c.Price) <= 0
+}
+
+// Statuses of a conditional order.
+const (
+	StatusWaiting = "Waiting" // Condition not met yet
+	StatusSending = "Sending" // Condition met, the order is being placed
+	StatusFailed  = "Failed"  // The order could not be placed; see Error
+)
+
+// Order is one conditional order. It is sent as a limit order at LimitPrice, or at market
+// when LimitPrice is 0.
+type Order struct {
+	ID         string         `json:"id"`
+	AccountID  string         `json:"account_id"`
+	Symbol     string         `json:"symbol"`
+	Condition  Condition      `json:"condition"`
+	Side       string         `json:"side"`
+	Quantity   models.Decimal `json:"quantity"` // In lots, as sent to PlaceOrder
+	LimitPrice models.Decimal `json:"limit_price,omitzero"`
+	Reference  models.Decimal `json:"reference,omitzero"` // Price when armed; the first quote is compared with it
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	Created    time.Time      `json:"created"`
+}
+
+// Action formats what the order sends: "Buy 5 lots at 310.5" or "Buy 5 lots at market".
+func (o Order) Action() string {
+	at := "market"
+	if o.LimitPrice.Sign() > 0 {
+		at = o.LimitPrice.String()
+	}
+	return fmt.Sprintf("%s %s lots at %s", o.Side, o.Quantity, at)
+}
+
+// ErrSending is returned when an order being sent is changed or removed.
+var ErrSending = errors.New("conditional order is being sent")
+
+// Manager keeps the conditional orders, their file and the audit file. It is safe for
+// concurrent use.
+type Manager struct {
+	// Now returns the current time, used to stamp orders and audit entries.
+	Now func() time.Time
+
+	mu        sync.Mutex
+	path      string
+	auditPath string
+	orders    map[string]*Order
+	last      map[string]models.Decimal // last price seen by each waiting order
+	next      int
+}
+
+// NewManager returns a manager saving its orders to path and appending triggers to
+// auditPath. Empty paths keep the orders in memory only and skip the audit file.
+func NewManager(path, auditPath string) *Manager {
+	return &Manager{
+		Now:       time.Now,
+		path:      path,
+		auditPath: auditPath,
+		orders:    make(map[string]*Order),
+		last:      make(map[string]models.Decimal),
+	}
+}
+
+// Load reads the orders saved in the manager's file. A missing file is not an error. An
+// order that was being sent when the terminal stopped is marked as failed, since it is not
+// known whether it reached the broker.
+func (m *Manager) Load() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read conditional orders: %w", err)
+	}
+	var orders []Order
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return fmt.Errorf("failed to parse conditional orders %s: %w", m.path, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range orders {
+		o := orders[i]
+		if o.Status == StatusSending {
+			o.Status = StatusFailed
+			o.Error = "terminal stopped while sending; check the Orders tab"
+		}
+		m.orders[o.ID] = &o
+		if n, err := strconv.Atoi(strings.TrimPrefix(o.ID, "C")); err == nil && n > m.next {
+			m.next = n
+		}
+	}
+	return nil
+}
+
+// Add arms a conditional order and returns it with its ID assigned.
+func (m *Manager) Add(o Order) (Order, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.next++
+	o.ID = fmt.Sprintf("C%d", m.next)
+	o.Status = StatusWaiting
+	o.Error = ""
+	if o.Created.IsZero() {
+		o.Created = m.Now()
+	}
+	m.orders[o.ID] = &o
+	return o, m.save()
+}
+
+// Update replaces the condition and order of o.ID and arms it again.
+func (m *Manager) Update(o Order) (Order, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cur, ok := m.orders[o.ID]
+	if !ok {
+		return Order{}, fmt.Errorf("conditional order %s not found", o.ID)
+	}
+	if cur.Status == StatusSending {
+		return Order{}, ErrSending
+	}
+	o.AccountID = cur.AccountID
+	o.Created = cur.Created
+	o.Status = StatusWaiting
+	o.Error = ""
+	*cur = o
+	delete(m.last, o.ID)
+	return o, m.save()
+}
+
+// Remove disarms the conditional order id.
+func (m *Manager) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.orders[id]
+	if !ok {
+		return nil
+	}
+	if o.Status == StatusSending {
+		return ErrSending
+	}
+	delete(m.orders, id)
+	delete(m.last, id)
+	return m.save()
+}
+
+// Orders returns the conditional orders of accountID, or of every account when accountID is
+// empty, ordered by creation.
+func (m *Manager) Orders(accountID string) []Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Order
+	for _, o := range m.orders {
+		if accountID == "" || o.AccountID == accountID {
+			out = append(out, *o)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Created.Equal(out[j].Created) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].Created.Before(out[j].Created)
+	})
+	return out
+}
+
+// Get returns the conditional order id.
+func (m *Manager) Get(id string) (Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.orders[id]
+	if !ok {
+		return Order{}, false
+	}
+	return *o, true
+}
+
+// Observe feeds the prices of symbol, keyed by field, to the waiting orders on it and
+// returns the orders whose condition was met. A returned order is marked as sending until
+// Sent or Failed is called. Each trigger is written to the audit file.
+func (m *Manager) Observe(symbol string, prices map[string]models.Decimal) ([]Order, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var fired []Order
+	var errs []error
+	for _, o := range m.orders {
+		if o.Symbol != symbol || o.Status != StatusWaiting {
+			continue
+		}
+		price := prices[o.Condition.Field]
+		if price.Sign() <= 0 {
+			continue
+		}
+		prev, ok := m.last[o.ID]
+		if !ok {
+			prev = o.Reference
+		}
+		m.last[o.ID] = price
+		if prev.Sign() <= 0 || !o.Condition.Crossed(prev, price) {
+			continue
+		}
+		o.Status = StatusSending
+		delete(m.last, o.ID)
+		errs = append(errs, m.audit(*o, fmt.Sprintf("triggered: %s at %s", o.Condition, price)))
+		fired = append(fired, *o)
+	}
+	if len(fired) > 0 {
+		errs = append(errs, m.save())
+	}
+	sort.Slice(fired, func(i, j int) bool { return fired[i].Created.Before(fired[j].Created) })
+	return fired, errors.Join(errs...)
+}
+
+// Sent records that the triggered order id was placed as the broker order orderID and
+// forgets it.
+func (m *Manager) Sent(id, orderID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.orders[id]
+	if !ok {
+		return nil
+	}
+	delete(m.orders, id)
+	return errors.Join(
+		m.audit(*o, fmt.Sprintf("sent: %s as order %s", o.Action(), orderID)),
+		m.save())
+}
+
+// Failed records that the triggered order id could not be placed. The order stays in the
+// list with its error until it is edited or removed.
+func (m *Manager) Failed(id string, cause error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.orders[id]
+	if !ok {
+		return nil
+	}
+	o.Status = StatusFailed
+	o.Error = cause.Error()
+	return errors.Join(
+		m.audit(*o, fmt.Sprintf("failed: %s: %v", o.Action(), cause)),
+		m.save())
+}
+
+// audit appends an entry about o to the audit file. Called with m.mu held.
+func (m *Manager) audit(o Order, event string) error {
+	if m.auditPath == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(m.auditPath), 0755); err != nil {
+		return fmt.Errorf("failed to write conditional audit: %w", err)
+	}
+	f, err := os.OpenFile(m.auditPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write conditional audit: %w", err)
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s %s account=%s symbol=%s %s\n",
+		m.Now().Format(time.RFC3339), o.ID, o.AccountID, o.Symbol, event)
+	if err != nil {
+		return fmt.Errorf("failed to write conditional audit: %w", err)
+	}
+	return nil
+}
+
+// save writes the orders to the manager's file. Called with m.mu held.
+func (m *Manager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	orders := make([]Order, 0, len(m.orders))
+	for _, o := range m.orders {
+		orders = append(orders, *o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].Created.Before(orders[j].Created) })
+	data, err := json.MarshalIndent(orders, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.Write(m.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save conditional orders: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package conditional
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"finam-terminal/models"
+)
+
+func TestCondition_Crossed(t *testing.T) {
+	above := Condition{Field: FieldLast, Above: true, Price: models.DecimalFromInt(310)}
+	below := Condition{Field: FieldLast, Price: models.DecimalFromInt(290)}
+	tests := []struct {
+		c           Condition
+		prev, price string
+		want        bool
+	}{
+		{above, "309", "310", true},
+		{above, "309", "311", true},
+		{above, "310", "311", false}, // Already at the level
+		{above, "305", "309.9", false},
+		{above, "309.99999999", "310.00000000", true},
+		{below, "291", "290", true},
+		{below, "295", "289", true},
+		{below, "289", "288", false},
+	}
+	for _, tt := range tests {
+		if got := tt.c.Crossed(models.DecimalOf(tt.prev), models.DecimalOf(tt.price)); got != tt.want {
+			t.Errorf("%s from %v to %v: got %v, want %v", tt.c, tt.prev, tt.price, got, tt.want)
+		}
+	}
+}
+
+func TestObserve_FiresOnCross(t *testing.T) {
+	m := NewManager("", "")
+	o, _ := m.Add(Order{
+		AccountID: "acc1", Symbol: "SBER@MISX", Side: "Buy", Quantity: models.DecimalFromInt(5), LimitPrice: models.DecimalOf("310.5"),
+		Condition: Condition{Field: FieldLast, Above: true, Price: models.DecimalFromInt(310)},
+		Reference: models.DecimalFromInt(305),
+	})
+
+	if fired, _ := m.Observe("SBER@MISX", map[string]models.Decimal{FieldLast: models.DecimalOf("309"), FieldBid: models.DecimalOf("311")}); len(fired) != 0 {
+		t.Fatalf("Expected no trigger below the level, got %v", fired)
+	}
+	if fired, _ := m.Observe("GAZP@MISX", map[string]models.Decimal{FieldLast: models.DecimalOf("400")}); len(fired) != 0 {
+		t.Fatalf("Expected no trigger from another symbol, got %v", fired)
+	}
+
+	fired, err := m.Observe("SBER@MISX", map[string]models.Decimal{FieldLast: models.DecimalOf("310.2")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fired) != 1 || fired[0].ID != o.ID || fired[0].Status != StatusSending {
+		t.Fatalf("Expected %s to fire, got %+v", o.ID, fired)
+	}
+
+	// Fires once
+	m.Observe("SBER@MISX", map[string]models.Decimal{FieldLast: models.DecimalOf("309")})
+	if fired, _ := m.Observe("SBER@MISX", map[string]models.Decimal{FieldLast: models.DecimalOf("311")}); len(fired) != 0 {
+		t.Fatalf("Expected no second trigger while sending, got %v", fired)
+	}
+	if err := m.Remove(o.ID); !errors.Is(err, ErrSending) {
+		t.Errorf("Expected ErrSending, got %v", err)
+	}
+
+	if err := m.Sent(o.ID, "ord1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Get(o.ID); ok {
+		t.Error("Expected a sent order to be forgotten")
+	}
+}
+
+func TestObserve_WithoutReferenceWaitsForSecondQuote(t *testing.T) {
+	m := NewManager("", "")
+	m.Add(Order{Symbol: "SBER", Side: "Sell", Quantity: models.DecimalFromInt(1), Condition: Condition{Field: FieldBid, Price: models.DecimalFromInt(290)}})
+
+	// The price is already below: no cross is seen
+	if fired, _ := m.Observe("SBER", map[string]models.Decimal{FieldBid: models.DecimalOf("285")}); len(fired) != 0 {
+		t.Fatalf("Expected no trigger on the first quote, got %v", fired)
+	}
+	m.Observe("SBER", map[string]models.Decimal{FieldBid: models.DecimalOf("295")})
+	if fired, _ := m.Observe("SBER", map[string]models.Decimal{FieldBid: models.DecimalOf("290")}); len(fired) != 1 {
+		t.Errorf("Expected a trigger on the cross down, got %v", fired)
+	}
+}
+
+func TestFailedAndUpdate(t *testing.T) {
+	m := NewManager("", "")
+	o, _ := m.Add(Order{Symbol: "SBER", Side: "Buy", Quantity: models.DecimalFromInt(1), Reference: models.DecimalFromInt(100), Condition: Condition{Field: FieldLast, Above: true, Price: models.DecimalFromInt(110)}})
+	m.Observe("SBER", map[string]models.Decimal{FieldLast: models.DecimalOf("111")})
+
+	if err := m.Failed(o.ID, errors.New("rejected")); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := m.Get(o.ID)
+	if got.Status != StatusFailed || got.Error != "rejected" {
+		t.Fatalf("Expected a failed order, got %+v", got)
+	}
+
+	got.Condition.Price = models.DecimalFromInt(120)
+	got.Reference = models.DecimalFromInt(115)
+	updated, err := m.Update(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != StatusWaiting || updated.Error != "" {
+		t.Errorf("Expected the edited order armed again, got %+v", updated)
+	}
+	if fired, _ := m.Observe("SBER", map[string]models.Decimal{FieldLast: models.DecimalOf("121")}); len(fired) != 1 {
+		t.Errorf("Expected the edited condition to fire, got %v", fired)
+	}
+}
+
+func TestManager_PersistsAndAudits(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "conditional-orders.json")
+	auditPath := filepath.Join(dir, "conditional-audit.log")
+
+	m := NewManager(path, auditPath)
+	m.Now = func() time.Time { return time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC) }
+	a, _ := m.Add(Order{AccountID: "acc1", Symbol: "SBER", Side: "Buy", Quantity: models.DecimalFromInt(5), LimitPrice: models.DecimalOf("310.5"), Reference: models.DecimalFromInt(300),
+		Condition: Condition{Field: FieldLast, Above: true, Price: models.DecimalFromInt(310)}})
+	b, _ := m.Add(Order{AccountID: "acc1", Symbol: "GAZP", Side: "Sell", Quantity: models.DecimalFromInt(1), Reference: models.DecimalFromInt(200),
+		Condition: Condition{Field: FieldLast, Price: models.DecimalFromInt(150)}})
+	m.Observe("SBER", map[string]models.Decimal{FieldLast: models.DecimalOf("310")})
+	if err := m.Sent(a.ID, "ord1"); err != nil {
+		t.Fatal(err)
+	}
+
+	audit, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"triggered: LAST crosses above 310 at 310", "sent: Buy 5 lots at 310.5 as order ord1"} {
+		if !strings.Contains(string(audit), want) {
+			t.Errorf("Expected %q in the audit file:\n%s", want, audit)
+		}
+	}
+
+	// An order caught sending by a restart is not sent again
+	m.Observe("GAZP", map[string]models.Decimal{FieldLast: models.DecimalOf("140")})
+
+	restarted := NewManager(path, auditPath)
+	if err := restarted.Load(); err != nil {
+		t.Fatal(err)
+	}
+	orders := restarted.Orders("acc1")
+	if len(orders) != 1 || orders[0].ID != b.ID || orders[0].Status != StatusFailed {
+		t.Fatalf("Expected %s failed after a restart, got %+v", b.ID, orders)
+	}
+
+	next, _ := restarted.Add(Order{AccountID: "acc1", Symbol: "LKOH"})
+	if next.ID == a.ID || next.ID == b.ID {
+		t.Errorf("Expected a new ID, got %s again", next.ID)
+	}
+}
+
+func TestManager_LoadMissingFile(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "none.json"), "")
+	if err := m.Load(); err != nil {
+		t.Errorf("Expected no error for a missing file, got %v", err)
+	}
+}
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"log"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+
+	"finam-terminal/models"
 
 	"github.com/joho/godotenv"
 )
@@ -18,12 +22,19 @@ type Config struct {
 
 	// Application settings
 	RefreshInterval int // in seconds
+
+	// Pre-trade risk checks
+	Risk models.RiskLimits
 }
 
 // Load reads configuration from .env file and environment variables
 func Load() (*Config, error) {
-	// Try to load .env file from current directory
+	// Try to load .env file from current directory, then ~/.finam-cli/.env.
+	// Variables that are already set are not overridden.
 	_ = godotenv.Load()
+	if home, err := os.UserHomeDir(); err == nil {
+		_ = godotenv.Load(filepath.Join(home, ".finam-cli", ".env"))
+	}
 
 	token := os.Getenv("FINAM_API_TOKEN")
 	if token == "" {
@@ -34,11 +45,46 @@ func Load() (*Config, error) {
 		GRPCAddr:        getEnv("FINAM_GRPC_ADDR", "api.finam.ru:443"),
 		APIToken:        token,
 		RefreshInterval: getEnvInt("REFRESH_INTERVAL", 5),
+		Risk:            loadRiskLimits(),
 	}
 
 	return cfg, nil
 }
 
+// loadRiskLimits reads the RISK_* variables. Unset variables leave their check disabled.
+func loadRiskLimits() models.RiskLimits {
+	return models.RiskLimits{
+		MaxOrderValue:    getEnvFloat("RISK_MAX_ORDER_VALUE", 0),
+		MaxLots:          getEnvFloat("RISK_MAX_LOTS", 0),
+		MaxLotsBySymbol:  parseSymbolLimits(os.Getenv("RISK_MAX_LOTS_BY_SYMBOL")),
+		MaxPositionLots:  getEnvFloat("RISK_MAX_POSITION_LOTS", 0),
+		PriceBandPercent: getEnvFloat("RISK_PRICE_BAND_PCT", 0),
+		MaxDailyOrders:   getEnvInt("RISK_MAX_DAILY_ORDERS", 0),
+		AllowOverride:    getEnvBool("RISK_ALLOW_OVERRIDE", true),
+		DailyLossLimit:   getEnvFloat("RISK_DAILY_LOSS_LIMIT", 0),
+		CancelOnLock:     getEnvBool("RISK_CANCEL_ON_LOCK", false),
+	}
+}
+
+// parseSymbolLimits parses "SBER=100,GAZP=50" into a ticker -> limit map.
+// Malformed entries are logged and skipped.
+func parseSymbolLimits(value string) map[string]float64 {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	limits := make(map[string]float64)
+	for _, entry := range strings.Split(value, ",") {
+		ticker, limit, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		v, err := strconv.ParseFloat(strings.TrimSpace(limit), 64)
+		if !ok || err != nil || v < 0 || strings.TrimSpace(ticker) == "" {
+			log.Printf("[WARN] Ignoring malformed RISK_MAX_LOTS_BY_SYMBOL entry %q", entry)
+			continue
+		}
+		limits[strings.ToUpper(strings.TrimSpace(ticker))] = v
+	}
+	return limits
+}
+
 // FindToken searches for the API token in ~/.finam-cli/.env and ./.env
 func FindToken() (string, string) {
 	home, _ := os.UserHomeDir()
@@ -86,6 +132,87 @@ func saveTokenInternal(homeDir, token string) error {
 	return os.WriteFile(envPath, []byte(content), 0644)
 }
 
+// TrailingStopsPath returns the file trailing stops are saved to, ~/.finam-cli/trailing-stops.json.
+// It is empty when the home directory is unknown.
+func TrailingStopsPath() string {
+	return stateFile("trailing-stops.json")
+}
+
+// OCOGroupsPath returns the file OCO groups are saved to, ~/.finam-cli/oco-groups.json.
+// It is empty when the home directory is unknown.
+func OCOGroupsPath() string {
+	return stateFile("oco-groups.json")
+}
+
+// BracketOrdersPath returns the file armed bracket orders are saved to,
+// ~/.finam-cli/bracket-orders.json. It is empty when the home directory is unknown.
+func BracketOrdersPath() string {
+	return stateFile("bracket-orders.json")
+}
+
+// ConditionalOrdersPath returns the file conditional orders are saved to,
+// ~/.finam-cli/conditional-orders.json. It is empty when the home directory is unknown.
+func ConditionalOrdersPath() string {
+	return stateFile("conditional-orders.json")
+}
+
+// ConditionalAuditPath returns the file every conditional order trigger is logged to,
+// ~/.finam-cli/conditional-audit.log. It is empty when the home directory is unknown.
+func ConditionalAuditPath() string {
+	return stateFile("conditional-audit.log")
+}
+
+// ModifyAuditPath returns the file every order modification is logged to,
+// ~/.finam-cli/modify-audit.log. It is empty when the home directory is unknown.
+func ModifyAuditPath() string {
+	return stateFile("modify-audit.log")
+}
+
+// RiskStatePath returns the file the trading lock and the day's order counts are saved to,
+// ~/.finam-cli/risk-state.json. It is empty when the home directory is unknown.
+func RiskStatePath() string {
+	return stateFile("risk-state.json")
+}
+
+// OrderExpiriesPath returns the file the last days of GTD orders are saved to,
+// ~/.finam-cli/order-expiries.json. It is empty when the home directory is unknown.
+func OrderExpiriesPath() string {
+	return stateFile("order-expiries.json")
+}
+
+// AlertsPath returns the file price alerts and the alert log are saved to,
+// ~/.finam-cli/alerts.json. It is empty when the home directory is unknown.
+func AlertsPath() string {
+	return stateFile("alerts.json")
+}
+
+// TradeStorePath returns the append-only file the trade history is kept in,
+// ~/.finam-cli/trades.jsonl. It is empty when the home directory is unknown.
+func TradeStorePath() string {
+	return stateFile("trades.jsonl")
+}
+
+// WatchlistsPath returns the file watchlists are saved to, ~/.finam-cli/watchlists.json.
+// It is empty when the home directory is unknown.
+func WatchlistsPath() string {
+	return stateFile("watchlists.json")
+}
+
+// ExportDir returns the directory exports are saved to by default, ~/.finam-cli/exports.
+// It is empty when the home directory is unknown.
+func ExportDir() string {
+	return stateFile("exports")
+}
+
+// stateFile returns the path of name in ~/.finam-cli, or "" when the home directory is unknown.
+func stateFile(name string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".finam-cli", name)
+}
+
 // getEnv returns the value of an environment variable or a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -94,6 +221,26 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvFloat returns the float value of an environment variable or a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+// getEnvBool returns the boolean value of an environment variable or a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
 // getEnvInt returns the integer value of an environment variable or a default value
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
@@ -173,3 +173,27 @@ func TestLoad(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadRiskLimits(t *testing.T) {
+	t.Setenv("RISK_MAX_ORDER_VALUE", "500000")
+	t.Setenv("RISK_MAX_LOTS_BY_SYMBOL", "sber=100, GAZP=50,broken")
+	t.Setenv("RISK_PRICE_BAND_PCT", "5")
+	t.Setenv("RISK_ALLOW_OVERRIDE", "false")
+
+	limits := loadRiskLimits()
+	if limits.MaxOrderValue != 500000 || limits.PriceBandPercent != 5 {
+		t.Errorf("Unexpected limits: %+v", limits)
+	}
+	if limits.MaxLots != 0 || limits.MaxDailyOrders != 0 {
+		t.Errorf("Expected unset limits to stay disabled, got %+v", limits)
+	}
+	if limits.AllowOverride {
+		t.Error("Expected override to be disabled")
+	}
+	if limits.DailyLossLimit != 0 || limits.CancelOnLock {
+		t.Errorf("Expected the daily loss limit to be off by default, got %+v", limits)
+	}
+	if len(limits.MaxLotsBySymbol) != 2 || limits.MaxLotsBySymbol["SBER"] != 100 || limits.MaxLotsBySymbol["GAZP"] != 50 {
+		t.Errorf("Unexpected per-symbol limits: %v", limits.MaxLotsBySymbol)
+	}
+}
@@ -0,0 +1,312 @@
+// Package export dumps positions, trades, orders and cash transactions to CSV, JSON or a
+// minimal XLSX workbook. Numeric fields are written as real numbers, so the files open in
+// spreadsheets and pandas without cleaning up the display strings of the terminal.
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"finam-terminal/models"
+)
+
+// Export formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatXLSX = "xlsx"
+)
+
+// Formats lists the export formats.
+var Formats = []string{FormatCSV, FormatJSON, FormatXLSX}
+
+// What can be exported
+const (
+	KindPositions    = "positions"
+	KindTrades       = "trades"
+	KindOrders       = "orders"
+	KindTransactions = "transactions"
+)
+
+// Kinds lists what can be exported.
+var Kinds = []string{KindPositions, KindTrades, KindOrders, KindTransactions}
+
+// Column is one field of an export. Numeric cells that are not a plain number, such as
+// "N/A", are left empty in CSV and XLSX and become null in JSON.
+type Column struct {
+	Key     string
+	Numeric bool
+}
+
+// Table is the data of one export, one row per record.
+type Table struct {
+	Name    string // Sheet name in XLSX
+	Columns []Column
+	Rows    [][]string
+}
+
+// NewTable returns an empty table with the given columns.
+func NewTable(name string, columns ...Column) *Table {
+	return &Table{Name: name, Columns: columns}
+}
+
+// Text returns a text column.
+func Text(key string) Column { return Column{Key: key} }
+
+// Num returns a numeric column.
+func Num(key string) Column { return Column{Key: key, Numeric: true} }
+
+// Add appends a row.
+func (t *Table) Add(cells ...string) {
+	t.Rows = append(t.Rows, cells)
+}
+
+// ValidFormat reports whether format is one of Formats.
+func ValidFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// FileName returns the default file name of an export made at now, e.g.
+// "positions-20260307-153000.csv".
+func FileName(kind, format string, now time.Time) string {
+	return fmt.Sprintf("%s-%s.%s", kind, now.Format("20060102-150405"), format)
+}
+
+// Write writes t to w in format.
+func Write(w io.Writer, t *Table, format string) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, t)
+	case FormatJSON:
+		return writeJSON(w, t)
+	case FormatXLSX:
+		return writeXLSX(w, t)
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+// WriteBook writes several tables to w: a workbook with a sheet per table in XLSX, or an
+// object with the records of each table under its name in JSON. CSV holds one table, so
+// each table has to go to a file of its own.
+func WriteBook(w io.Writer, tables []*Table, format string) error {
+	switch format {
+	case FormatJSON:
+		return writeJSONBook(w, tables)
+	case FormatXLSX:
+		return writeXLSX(w, tables...)
+	case FormatCSV:
+		return fmt.Errorf("CSV holds a single table: write each of the %d tables separately", len(tables))
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+func writeCSV(w io.Writer, t *Table) error {
+	cw := csv.NewWriter(w)
+	headers := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		headers[i] = c.Key
+	}
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			if t.Columns[i].Numeric {
+				cell = Number(cell)
+			}
+			record[i] = cell
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeJSON(w io.Writer, t *Table) error {
+	records := make([]record, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		records = append(records, record{columns: t.Columns, cells: row})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// writeJSONBook writes an object keyed by table name, keeping the table order.
+func writeJSONBook(w io.Writer, tables []*Table) error {
+	var b bytes.Buffer
+	b.WriteString("{\n")
+	for i, t := range tables {
+		records := make([]record, 0, len(t.Rows))
+		for _, row := range t.Rows {
+			records = append(records, record{columns: t.Columns, cells: row})
+		}
+		key, _ := json.Marshal(t.Name)
+		value, err := json.MarshalIndent(records, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		b.WriteString("  ")
+		b.Write(key)
+		b.WriteString(": ")
+		b.Write(value)
+		if i < len(tables)-1 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString("}\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// record marshals one row as a JSON object, keeping the column order.
+type record struct {
+	columns []Column
+	cells   []string
+}
+
+func (r record) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, cell := range r.cells {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(r.columns[i].Key)
+		b.Write(key)
+		b.WriteByte(':')
+
+		var value []byte
+		switch {
+		case !r.columns[i].Numeric:
+			value, _ = json.Marshal(cell)
+		case Number(cell) != "":
+			value = []byte(Number(cell))
+		default:
+			value = []byte("null")
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// numberPattern matches the plain decimals the API returns; anything else is not a number.
+var numberPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// Number normalizes an API decimal string to a plain number, or returns "" when it is not one.
+func Number(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	if !numberPattern.MatchString(s) {
+		return ""
+	}
+	return s
+}
+
+// LotSizer returns the lot size of a symbol, or 0 when it is unknown.
+type LotSizer func(symbol string) models.Decimal
+
+// Positions returns the positions of every account in data, accounts in ID order.
+func Positions(data map[string][]models.Position) *Table {
+	t := NewTable("Positions", Text("account"), Text("symbol"), Text("name"), Num("quantity"), Num("lots"),
+		Num("average_price"), Num("current_price"), Num("daily_pnl"), Num("unrealized_pnl"), Num("value"))
+	for _, account := range accountIDs(data) {
+		for _, p := range data[account] {
+			t.Add(account, p.Symbol, p.Name, p.Quantity.String(), Lots(p.Quantity, p.LotSize),
+				p.AveragePrice.String(), p.CurrentPrice.String(), p.DailyPnL.String(), p.UnrealizedPnL.String(),
+				p.TotalValue.String())
+		}
+	}
+	return t
+}
+
+// Trades returns the trades of every account in data, accounts in ID order.
+func Trades(data map[string][]models.Trade, lotSize LotSizer) *Table {
+	t := NewTable("Trades", Text("account"), Text("id"), Text("symbol"), Text("name"), Text("side"),
+		Num("price"), Num("quantity"), Num("lots"), Num("total"), Text("time"))
+	for _, account := range accountIDs(data) {
+		for _, tr := range data[account] {
+			t.Add(account, tr.ID, tr.Symbol, tr.Name, tr.Side, tr.Price.String(), tr.Quantity.String(),
+				Lots(tr.Quantity, lotSizeOf(lotSize, tr.Symbol)), tr.Total.String(), FormatTime(tr.Timestamp))
+		}
+	}
+	return t
+}
+
+// Orders returns the orders of every account in data, accounts in ID order.
+func Orders(data map[string][]models.Order, lotSize LotSizer) *Table {
+	t := NewTable("Orders", Text("account"), Text("id"), Text("symbol"), Text("name"), Text("side"),
+		Text("type"), Text("status"), Num("quantity"), Num("lots"), Num("executed"), Num("remaining"),
+		Num("limit_price"), Num("stop_price"), Num("sl_price"), Num("tp_price"), Text("validity"), Text("time"))
+	for _, account := range accountIDs(data) {
+		for _, o := range data[account] {
+			t.Add(account, o.ID, o.Symbol, o.Name, o.Side, o.Type, o.Status, o.Quantity.String(),
+				Lots(o.Quantity, lotSizeOf(lotSize, o.Symbol)), o.ExecutedQty.String(), o.RemainingQty.String(),
+				o.LimitPrice.String(), o.StopPrice.String(), o.SLPrice.String(), o.TPPrice.String(), o.Validity,
+				FormatTime(o.CreationTime))
+		}
+	}
+	return t
+}
+
+// Transactions returns the cash transactions of every account in data, accounts in ID order.
+func Transactions(data map[string][]models.Transaction) *Table {
+	t := NewTable("Transactions", Text("account"), Text("id"), Text("time"), Text("category"),
+		Text("description"), Text("symbol"), Num("amount"), Text("currency"))
+	for _, account := range accountIDs(data) {
+		for _, tx := range data[account] {
+			t.Add(account, tx.ID, FormatTime(tx.Timestamp), tx.Category, tx.Description, tx.Symbol,
+				tx.Amount.String(), tx.Currency)
+		}
+	}
+	return t
+}
+
+func accountIDs[T any](data map[string][]T) []string {
+	ids := make([]string, 0, len(data))
+	for id := range data {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func lotSizeOf(lotSize LotSizer, symbol string) models.Decimal {
+	if lotSize == nil {
+		return models.Decimal{}
+	}
+	return lotSize(symbol)
+}
+
+// Lots converts a share quantity to lots, to at most 8 decimals, or returns "" when the
+// lot size is unknown.
+func Lots(quantity models.Decimal, lotSize models.Decimal) string {
+	if !quantity.Known() || lotSize.Sign() <= 0 {
+		return ""
+	}
+	return quantity.Div(lotSize, 8).Trim().String()
+}
+
+// FormatTime formats timestamps in RFC 3339; zero times are empty.
+func FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
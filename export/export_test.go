@@ -0,0 +1,159 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"finam-terminal/models"
+)
+
+func testPositions() map[string][]models.Position {
+	return map[string][]models.Position{
+		"ACC2": {{Symbol: "GAZP@MISX", Quantity: models.DecimalOf("100"), LotSize: models.DecimalFromInt(10), AveragePrice: models.DecimalOf("150,5"), CurrentPrice: models.NA}},
+		"ACC1": {{Symbol: "SBER@MISX", Name: "Сбербанк, ао", Quantity: models.DecimalOf("20"), LotSize: models.DecimalFromInt(10), AveragePrice: models.DecimalOf("300"), CurrentPrice: models.DecimalOf("310")}},
+	}
+}
+
+func TestWriteCSV_NumbersAndAccounts(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Positions(testPositions()), FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and two rows, got:\n%s", buf.String())
+	}
+	if lines[1] != `ACC1,SBER@MISX,"Сбербанк, ао",20,2,300,310,0,0,0` {
+		t.Errorf("Unexpected first row %q", lines[1])
+	}
+	if lines[2] != "ACC2,GAZP@MISX,,100,10,150.5,,0,0,0" {
+		t.Errorf("Expected N/A left empty and the decimal comma fixed, got %q", lines[2])
+	}
+}
+
+func TestWriteJSON_NumericColumns(t *testing.T) {
+	at := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	table := Trades(map[string][]models.Trade{
+		"ACC1": {{ID: "T1", Symbol: "SBER@MISX", Side: "Buy", Price: models.DecimalOf("300.5"), Quantity: models.DecimalOf("10"), Total: models.DecimalOf("3005.00"), Timestamp: at}},
+	}, func(string) models.Decimal { return models.DecimalFromInt(10) })
+
+	var buf bytes.Buffer
+	if err := Write(&buf, table, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(rows) != 1 || rows[0]["account"] != "ACC1" || rows[0]["price"] != 300.5 || rows[0]["lots"] != 1.0 || rows[0]["time"] != "2026-03-02T10:00:00Z" {
+		t.Errorf("Unexpected record %+v", rows)
+	}
+}
+
+func TestWriteXLSX_Workbook(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Positions(testPositions()), FormatXLSX); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Not a zip archive: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(body)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Missing part %s", name)
+		}
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `<c r="F3"><v>150.5</v></c>`) {
+		t.Errorf("Expected the average price as a number, got:\n%s", sheet)
+	}
+	if strings.Contains(sheet, "N/A") || strings.Contains(sheet, `r="G3"`) {
+		t.Errorf("Expected N/A left out, got:\n%s", sheet)
+	}
+	if !strings.Contains(sheet, `<t xml:space="preserve">Сбербанк, ао</t>`) {
+		t.Errorf("Expected the name as inline text, got:\n%s", sheet)
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="Positions"`) {
+		t.Errorf("Expected the sheet to be named Positions")
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %s, expected %s", i, got, want)
+		}
+	}
+}
+
+func testTaxYear() models.TaxYear {
+	at := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	return models.TaxYear{
+		Year: 2025,
+		Closes: []models.TaxClose{{
+			Symbol: "SBER@MISX", Side: "Long", Quantity: models.DecimalOf("10"),
+			Buy:  models.TaxLeg{Price: models.DecimalOf("280"), Amount: models.DecimalOf("2800")},
+			Sell: models.TaxLeg{TradeID: "S1", Time: at, Price: models.DecimalOf("300"), Amount: models.DecimalOf("3000"), Commission: models.DecimalOf("1.5")},
+		}},
+		Payments:    []models.TaxPayment{{Time: at, Category: models.TxDividend, Symbol: "SBER", Amount: models.DecimalOf("1000"), Currency: "RUB"}},
+		Proceeds:    models.DecimalOf("3000"),
+		TradingBase: models.DecimalOf("198.5"),
+		Tax:         models.DecimalOf("26"),
+	}
+}
+
+func TestWriteBook_TaxReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBook(&buf, TaxReport(testTaxYear()), FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var book map[string][]map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &book); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, buf.String())
+	}
+	closes := book["Closes"]
+	if len(book) != 3 || len(closes) != 1 || len(book["Income"]) != 1 {
+		t.Fatalf("Expected the summary, one close and one payment, got %+v", book)
+	}
+	if closes[0]["buy_trade_id"] != "" || closes[0]["buy_time"] != "" || closes[0]["holding_days"] != nil || closes[0]["result"] != 198.5 {
+		t.Errorf("Unexpected close %+v", closes[0])
+	}
+
+	buf.Reset()
+	if err := WriteBook(&buf, TaxReport(testTaxYear()), FormatXLSX); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Not a zip archive: %v", err)
+	}
+	var sheets int
+	for _, f := range zr.File {
+		if strings.HasPrefix(f.Name, "xl/worksheets/sheet") {
+			sheets++
+		}
+	}
+	if sheets != 3 {
+		t.Errorf("Expected three sheets, got %d", sheets)
+	}
+
+	if err := WriteBook(&buf, TaxReport(testTaxYear()), FormatCSV); err == nil {
+		t.Error("Expected CSV to be refused for several tables")
+	}
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"strconv"
+
+	"finam-terminal/models"
+)
+
+// TaxReport returns the tables of the tax report of one year: the summary, the closed
+// lots and the income payments.
+func TaxReport(y models.TaxYear) []*Table {
+	return []*Table{TaxSummary(y), TaxCloses(y), TaxPayments(y)}
+}
+
+// TaxSummary returns the totals of the tax report, one line per figure. Where a figure
+// goes into the 3-NDFL declaration its income or deduction code is named.
+func TaxSummary(y models.TaxYear) *Table {
+	t := NewTable("Summary", Text("item"), Text("description"), Num("amount"))
+	add := func(item, description string, v models.Decimal) {
+		t.Add(item, description, formatMoney(v))
+	}
+	t.Add("year", "Tax year", strconv.Itoa(y.Year))
+	add("proceeds", "Sales of securities (income code 1530)", y.Proceeds)
+	add("costs", "Purchases of the securities sold (deduction code 201)", y.Costs)
+	add("commissions", "Commissions (deduction code 201)", y.Commissions)
+	add("other_commissions", "Commissions not tied to a trade, included above", y.OtherCommissions)
+	add("gains", "Profitable closes", y.Gains)
+	add("losses", "Losing closes", y.Losses)
+	add("result", "Result of trading", y.Result)
+	add("loss_carried", "Losses of earlier years set off", y.LossCarried)
+	add("loss_forward", "Losses left for later years", y.LossForward)
+	add("trading_base", "Tax base of trading", y.TradingBase)
+	add("dividends", "Dividends (income code 1010)", y.Dividends)
+	add("coupons", "Coupons (income code 1011)", y.Coupons)
+	add("tax", "Tax on the year's investment income", y.Tax)
+	add("withheld", "Tax withheld by the broker", y.Withheld)
+	add("due", "Tax to pay, negative when overpaid", y.Due)
+	t.Add("foreign_payments", "Payments in other currencies left out", strconv.Itoa(y.ForeignPayments))
+	return t
+}
+
+// TaxCloses returns the lots closed during the year with their buy and sell legs.
+func TaxCloses(y models.TaxYear) *Table {
+	t := NewTable("Closes", Text("symbol"), Text("side"), Num("quantity"),
+		Text("buy_trade_id"), Text("buy_time"), Num("buy_price"), Num("buy_amount"), Num("buy_commission"),
+		Text("sell_trade_id"), Text("sell_time"), Num("sell_price"), Num("sell_amount"), Num("sell_commission"),
+		Num("result"), Num("holding_days"))
+	for _, c := range y.Closes {
+		holding := ""
+		if !c.Opened().IsZero() {
+			holding = strconv.FormatFloat(c.Holding().Hours()/24, 'f', 1, 64)
+		}
+		t.Add(c.Symbol, c.Side, c.Quantity.Trim().String(),
+			c.Buy.TradeID, FormatTime(c.Buy.Time), c.Buy.Price.Trim().String(), formatMoney(c.Buy.Amount),
+			formatMoney(c.Buy.Commission),
+			c.Sell.TradeID, FormatTime(c.Sell.Time), c.Sell.Price.Trim().String(), formatMoney(c.Sell.Amount),
+			formatMoney(c.Sell.Commission),
+			formatMoney(c.Result()), holding)
+	}
+	return t
+}
+
+// TaxPayments returns the dividends, coupons and taxes of the year.
+func TaxPayments(y models.TaxYear) *Table {
+	t := NewTable("Income", Text("time"), Text("category"), Text("symbol"), Text("description"),
+		Num("amount"), Text("currency"))
+	for _, p := range y.Payments {
+		t.Add(FormatTime(p.Time), p.Category, p.Symbol, p.Description, p.Amount.String(), p.Currency)
+	}
+	return t
+}
+
+// formatMoney rounds money to kopecks.
+func formatMoney(v models.Decimal) string {
+	return v.StringFixed(2)
+}
@@ -0,0 +1,126 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The fixed parts of a workbook. Cells hold inline strings, so no shared string table or
+// styles are needed.
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+%s</Types>`
+	xlsxSheetType = `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+%s</Relationships>`
+	xlsxSheetRel = `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>
+`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets>%s</sheets>
+</workbook>`
+	xlsxSheet = `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`
+)
+
+// writeXLSX writes a workbook with one sheet per table: a header row, then one row per
+// record. Numeric cells are stored as numbers, everything else as text.
+func writeXLSX(w io.Writer, tables ...*Table) error {
+	var types, rels, sheets strings.Builder
+	for i, t := range tables {
+		n := i + 1
+		name := t.Name
+		if name == "" {
+			name = fmt.Sprintf("Sheet%d", n)
+		}
+		fmt.Fprintf(&types, xlsxSheetType, n)
+		fmt.Fprintf(&rels, xlsxSheetRel, n, n)
+		fmt.Fprintf(&sheets, xlsxSheet, xmlEscape(name), n, n)
+	}
+	parts := []struct{ path, body string }{
+		{"[Content_Types].xml", fmt.Sprintf(xlsxContentTypes, types.String())},
+		{"_rels/.rels", xlsxRels},
+		{"xl/_rels/workbook.xml.rels", fmt.Sprintf(xlsxWorkbookRels, rels.String())},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, sheets.String())},
+	}
+	for i, t := range tables {
+		parts = append(parts, struct{ path, body string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheetXML(t)})
+	}
+
+	zw := zip.NewWriter(w)
+	for _, p := range parts {
+		f, err := zw.Create(p.path)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func sheetXML(t *Table) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	headers := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		headers[i] = c.Key
+	}
+	writeRow(&b, 1, headers, nil)
+	for i, row := range t.Rows {
+		writeRow(&b, i+2, row, t.Columns)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// writeRow writes one sheet row. Without columns every cell is text.
+func writeRow(b *strings.Builder, row int, cells []string, columns []Column) {
+	fmt.Fprintf(b, `<row r="%d">`, row)
+	for i, cell := range cells {
+		ref := columnName(i) + strconv.Itoa(row)
+		if columns != nil && columns[i].Numeric {
+			// Cells that are not numbers, such as "N/A", are left out
+			if n := Number(cell); n != "" {
+				fmt.Fprintf(b, `<c r="%s"><v>%s</v></c>`, ref, n)
+			}
+			continue
+		}
+		if cell == "" {
+			continue
+		}
+		fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(cell))
+	}
+	b.WriteString(`</row>`)
+}
+
+// columnName returns the spreadsheet name of the zero-based column i: A, B, ..., Z, AA, ...
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -8,9 +8,12 @@ import (
 	"time"
 
 	"finam-terminal/api"
+	"finam-terminal/api/testserver"
+	"finam-terminal/cli"
 	"finam-terminal/config"
 	"finam-terminal/models"
 	"finam-terminal/platform"
+	"finam-terminal/risk"
 	"finam-terminal/ui"
 )
 
@@ -28,8 +31,32 @@ func main() {
 
 	// Parse command line flags
 	accountIdx := flag.Int("account", -1, "Account index to show (0-based)")
+	paper := flag.Bool("paper", false, "Trade offline against a simulated exchange (no token needed)")
+	record := flag.String("record", "", "Record received market data to a journal `file`")
+	replay := flag.String("replay", "", "Paper-trade against market data played back from a journal `file`")
+	replaySpeed := flag.String("replay-speed", "1x", "Replay speed: 1x, 10x or max")
+	flag.Usage = func() {
+		cli.Run([]string{"help"}, nil, flag.CommandLine.Output(), flag.CommandLine.Output())
+		fmt.Fprintln(flag.CommandLine.Output())
+		flag.PrintDefaults()
+	}
 	flag.Parse()
-	_ = accountIdx // Silence unused variable warning until feature is implemented
+
+	speed, err := testserver.ParseReplaySpeed(*replaySpeed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *replay != "" {
+		*paper = true
+	}
+
+	// Headless subcommands print to stdout and never start the TUI
+	if flag.NArg() > 0 {
+		code := cli.Run(flag.Args(), connectHeadless, os.Stdout, os.Stderr)
+		_ = logFile.Close()
+		os.Exit(code)
+	}
 
 	ui.PrintConsoleSplash()
 
@@ -41,7 +68,7 @@ func main() {
 	cfg, _ = config.Load()
 
 	// If token is missing, show setup screen
-	if cfg.APIToken == "" || cfg.APIToken == "your_api_token_here" {
+	if !*paper && (cfg.APIToken == "" || cfg.APIToken == "your_api_token_here") {
 		setup := ui.NewSetupApp(cfg.GRPCAddr)
 		setup.SetOnSave(func(token string) error {
 			return config.SaveTokenToUserHome(token)
@@ -58,7 +85,7 @@ func main() {
 		{
 			Name: "Validating configuration...",
 			Action: func() error {
-				if cfg.APIToken == "" || cfg.APIToken == "your_api_token_here" {
+				if !*paper && (cfg.APIToken == "" || cfg.APIToken == "your_api_token_here") {
 					return fmt.Errorf("FINAM_API_TOKEN is not set")
 				}
 				return nil
@@ -68,8 +95,18 @@ func main() {
 			Name: "Initializing API client...",
 			Action: func() error {
 				var err error
-				client, err = api.NewClient(cfg.GRPCAddr, cfg.APIToken)
-				return err
+				switch {
+				case *replay != "":
+					client, err = api.NewReplayClient(*replay, speed)
+				case *paper:
+					client, err = api.NewPaperClient()
+				default:
+					client, err = api.NewClient(cfg.GRPCAddr, cfg.APIToken)
+				}
+				if err != nil || *record == "" {
+					return err
+				}
+				return client.StartRecording(*record)
 			},
 		},
 		{
@@ -118,9 +155,72 @@ func main() {
 
 	// Start TUI
 	app := ui.NewApp(client, accounts)
+	app.SetRiskLimits(cfg.Risk)
+	app.SetExportDir(config.ExportDir())
+	if *paper {
+		app.SetPaperMode()
+	} else {
+		client.SetModifyAudit(config.ModifyAuditPath())
+		// Keep a broken file for inspection; its orders are then managed until exit only
+		if err := app.SetTrailingStore(config.TrailingStopsPath()); err != nil {
+			log.Printf("[ERROR] %v", err)
+		}
+		if err := app.SetOCOStore(config.OCOGroupsPath()); err != nil {
+			log.Printf("[ERROR] %v", err)
+		}
+		if err := app.SetBracketStore(config.BracketOrdersPath()); err != nil {
+			log.Printf("[ERROR] %v", err)
+		}
+		if err := app.SetConditionalStore(config.ConditionalOrdersPath(), config.ConditionalAuditPath()); err != nil {
+			log.Printf("[ERROR] %v", err)
+		}
+		if err := app.SetAlertStore(config.AlertsPath()); err != nil {
+			log.Printf("[ERROR] %v", err)
+		}
+		if err := app.SetWatchlistStore(config.WatchlistsPath()); err != nil {
+			log.Printf("[ERROR] %v", err)
+		}
+		if err := app.SetTradeStore(config.TradeStorePath()); err != nil {
+			log.Printf("[ERROR] %v", err)
+		}
+		if err := app.SetRiskStore(config.RiskStatePath()); err != nil {
+			log.Printf("[ERROR] %v", err)
+		}
+		if err := app.SetExpiryStore(config.OrderExpiriesPath()); err != nil {
+			log.Printf("[ERROR] %v", err)
+		}
+	}
+	if *accountIdx >= 0 {
+		if !app.SelectAccount(*accountIdx) {
+			fmt.Printf("Account index %d is out of range (0..%d), showing the first account\n", *accountIdx, len(accounts)-1)
+			time.Sleep(2 * time.Second)
+		}
+	}
 	if err := app.Run(); err != nil {
 		log.Fatalf("[ERROR] Application error: %v", err)
 	}
+	if err := client.StopRecording(); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
 
 	fmt.Println("[INFO] Goodbye!")
 }
+
+// connectHeadless creates the API client for CLI subcommands. Unlike the TUI it
+// never shows the setup screen: a missing token is an error for the calling script.
+// Orders are checked against the same risk limits, lock and daily counts as in the TUI.
+func connectHeadless() (cli.Client, *risk.Engine, error) {
+	cfg, _ := config.Load()
+	if cfg.APIToken == "" || cfg.APIToken == "your_api_token_here" {
+		return nil, nil, fmt.Errorf("FINAM_API_TOKEN is not set; run finam-terminal once to set it up")
+	}
+	engine := risk.NewEngine(cfg.Risk)
+	if err := engine.Restore(config.RiskStatePath()); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+	client, err := api.NewClient(cfg.GRPCAddr, cfg.APIToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, engine, nil
+}
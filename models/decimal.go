@@ -0,0 +1,357 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Decimal is an exact decimal number: a price, a quantity or an amount as the API sends it.
+// Arithmetic does not round unless asked to, so lot conversions, totals and price steps
+// never turn 0.3 into 0.30000000000000004. A Decimal keeps the number of decimals it was
+// parsed with, so "300.50" prints as "300.50".
+//
+// The zero value is 0. NA is a value the API left out: it prints as "N/A", counts as 0
+// in comparisons and makes the result of arithmetic NA too.
+type Decimal struct {
+	coef  int64 // The value is coef / 10^scale
+	scale int32
+	na    bool
+}
+
+// NA is an unknown value, such as a price the API did not send.
+var NA = Decimal{na: true}
+
+// maxExponent bounds the exponent ParseDecimal accepts, so "1e1000000000" is refused
+// before any arithmetic rather than building a billion-digit number.
+const maxExponent = 30
+
+// ParseDecimal parses a decimal such as "-1 234,50" or "2.5e3". Spaces are ignored and a
+// comma is read as the decimal point.
+func ParseDecimal(s string) (Decimal, error) {
+	var sb strings.Builder
+	for _, r := range s {
+		if !unicode.IsSpace(r) && r != '\u00A0' {
+			sb.WriteRune(r)
+		}
+	}
+	text := strings.ReplaceAll(sb.String(), ",", ".")
+
+	mantissa, exp := text, 0
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		e, err := strconv.Atoi(text[i+1:])
+		if err != nil {
+			return NA, fmt.Errorf("invalid decimal %q", s)
+		}
+		if e > maxExponent || e < -maxExponent {
+			return NA, fmt.Errorf("decimal %q is out of range", s)
+		}
+		mantissa, exp = text[:i], e
+	}
+	negative := strings.HasPrefix(mantissa, "-")
+	if negative || strings.HasPrefix(mantissa, "+") {
+		mantissa = mantissa[1:]
+	}
+	intPart, frac, _ := strings.Cut(mantissa, ".")
+	digits := intPart + frac
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return NA, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	coef, _ := new(big.Int).SetString(digits, 10)
+	if negative {
+		coef.Neg(coef)
+	}
+	scale := int64(len(frac)) - int64(exp)
+	if scale < 0 {
+		coef.Mul(coef, pow10(int32(-scale)))
+		scale = 0
+	}
+	d := fromBig(coef, int32(scale))
+	if d.na {
+		return NA, fmt.Errorf("decimal %q is out of range", s)
+	}
+	return d, nil
+}
+
+// DecimalOf returns the decimal in s, or NA when s is not a number, such as "N/A".
+func DecimalOf(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		return NA
+	}
+	return d
+}
+
+// DecimalFromInt returns n as a decimal.
+func DecimalFromInt(n int64) Decimal {
+	return Decimal{coef: n}
+}
+
+// DecimalFromFloat returns the shortest decimal that reads back as f, so 0.1 becomes
+// exactly 0.1. NaN and infinities are NA.
+func DecimalFromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return NA
+	}
+	return DecimalOf(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// Known reports whether d is a number rather than NA.
+func (d Decimal) Known() bool { return !d.na }
+
+// IsZero reports whether d is a known zero.
+func (d Decimal) IsZero() bool { return !d.na && d.coef == 0 }
+
+// Sign returns -1, 0 or 1 by the sign of d; NA is 0.
+func (d Decimal) Sign() int {
+	switch {
+	case d.na || d.coef == 0:
+		return 0
+	case d.coef < 0:
+		return -1
+	}
+	return 1
+}
+
+// Scale returns the number of decimals of d.
+func (d Decimal) Scale() int32 { return d.scale }
+
+// Float64 returns d as a float for display and charts; NA is 0.
+func (d Decimal) Float64() float64 {
+	if d.na {
+		return 0
+	}
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	if d.na {
+		return NA
+	}
+	return fromBig(new(big.Int).Neg(d.big()), d.scale)
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	if d.Sign() < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Add returns d + e.
+func (d Decimal) Add(e Decimal) Decimal {
+	if d.na || e.na {
+		return NA
+	}
+	a, b, scale := align(d, e)
+	return fromBig(a.Add(a, b), scale)
+}
+
+// Sub returns d - e.
+func (d Decimal) Sub(e Decimal) Decimal {
+	if d.na || e.na {
+		return NA
+	}
+	a, b, scale := align(d, e)
+	return fromBig(a.Sub(a, b), scale)
+}
+
+// Mul returns d × e with all the decimals of both.
+func (d Decimal) Mul(e Decimal) Decimal {
+	if d.na || e.na {
+		return NA
+	}
+	return fromBig(new(big.Int).Mul(d.big(), e.big()), d.scale+e.scale)
+}
+
+// Div returns d / e rounded half away from zero to places decimals, and NA when e is 0.
+func (d Decimal) Div(e Decimal, places int32) Decimal {
+	if d.na || e.na || e.coef == 0 {
+		return NA
+	}
+	// d / e = (d.coef * 10^(places + e.scale)) / (e.coef * 10^d.scale) / 10^places
+	num := new(big.Int).Mul(d.big(), pow10(places+e.scale))
+	den := new(big.Int).Mul(e.big(), pow10(d.scale))
+	return fromBig(quoRound(num, den), places)
+}
+
+// Round returns d rounded half away from zero to places decimals. A decimal with fewer
+// decimals is returned as it is.
+func (d Decimal) Round(places int32) Decimal {
+	if d.na || d.scale <= places {
+		return d
+	}
+	return fromBig(quoRound(d.big(), pow10(d.scale-places)), places)
+}
+
+// RoundStep returns the multiple of step nearest to d, the one away from zero on a tie.
+// A step that is not positive returns d as it is.
+func (d Decimal) RoundStep(step Decimal) Decimal {
+	if step.Sign() <= 0 {
+		return d
+	}
+	return d.Div(step, 0).Mul(step)
+}
+
+// Snap returns the multiples of step nearest to d from below and from above. Both are d
+// when it is a multiple of step. A step that is not positive returns d twice.
+func (d Decimal) Snap(step Decimal) (below, above Decimal) {
+	if d.na || step.Sign() <= 0 {
+		return d, d
+	}
+	a, b, scale := align(d, step)
+	q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+	if r.Sign() == 0 {
+		return d, d
+	}
+	if r.Sign() < 0 {
+		q.Sub(q, big.NewInt(1))
+	}
+	below = fromBig(q.Mul(q, b), scale)
+	return below, below.Add(step)
+}
+
+// Cmp compares d and e and returns -1, 0 or 1; NA counts as 0.
+func (d Decimal) Cmp(e Decimal) int {
+	if d.na {
+		d = Decimal{}
+	}
+	if e.na {
+		e = Decimal{}
+	}
+	a, b, _ := align(d, e)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and e are the same number, or both NA.
+func (d Decimal) Equal(e Decimal) bool {
+	if d.na || e.na {
+		return d.na == e.na
+	}
+	return d.Cmp(e) == 0
+}
+
+// String returns d as a plain decimal with all its decimals, e.g. "-1234.50", or "N/A".
+func (d Decimal) String() string {
+	if d.na {
+		return "N/A"
+	}
+	digits := strconv.FormatInt(d.coef, 10)
+	sign := ""
+	if d.coef < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if d.scale == 0 {
+		return sign + digits
+	}
+	if n := int(d.scale) + 1 - len(digits); n > 0 {
+		digits = strings.Repeat("0", n) + digits
+	}
+	cut := len(digits) - int(d.scale)
+	return sign + digits[:cut] + "." + digits[cut:]
+}
+
+// StringFixed returns d rounded to exactly places decimals, e.g. "300.50" for two.
+func (d Decimal) StringFixed(places int32) string {
+	if d.na {
+		return "N/A"
+	}
+	r := d.Round(places)
+	if r.scale < places {
+		r = fromBig(new(big.Int).Mul(r.big(), pow10(places-r.scale)), places)
+	}
+	return r.String()
+}
+
+// Trim returns d without the trailing zeros of its fraction, so "300.50" becomes "300.5".
+func (d Decimal) Trim() Decimal {
+	for !d.na && d.scale > 0 && d.coef%10 == 0 {
+		d.coef /= 10
+		d.scale--
+	}
+	return d
+}
+
+// MarshalJSON writes d as a JSON number, or null when it is NA.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if d.na {
+		return []byte("null"), nil
+	}
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number, a numeric string or null.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if string(b) == "null" {
+		*d = NA
+		return nil
+	}
+	v, err := ParseDecimal(strings.Trim(string(b), `"`))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (d Decimal) big() *big.Int {
+	return big.NewInt(d.coef)
+}
+
+// align returns the coefficients of d and e at the larger of their scales.
+func align(d, e Decimal) (*big.Int, *big.Int, int32) {
+	a, b := d.big(), e.big()
+	switch {
+	case d.scale < e.scale:
+		a.Mul(a, pow10(e.scale-d.scale))
+		return a, b, e.scale
+	case e.scale < d.scale:
+		b.Mul(b, pow10(d.scale-e.scale))
+	}
+	return a, b, d.scale
+}
+
+// fromBig returns coef / 10^scale, rounding off decimals until the coefficient fits in
+// int64. A number too large for that is NA.
+func fromBig(coef *big.Int, scale int32) Decimal {
+	ten := big.NewInt(10)
+	for !coef.IsInt64() && scale > 0 {
+		coef = quoRound(coef, ten)
+		scale--
+	}
+	if !coef.IsInt64() {
+		return NA
+	}
+	return Decimal{coef: coef.Int64(), scale: scale}
+}
+
+// quoRound returns num / den rounded half away from zero.
+func quoRound(num, den *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	if twice.Cmp(new(big.Int).Abs(den)) >= 0 {
+		if num.Sign()*den.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
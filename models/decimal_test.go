@@ -0,0 +1,128 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"300.50", "300.50"},
+		{"-1 234,5", "-1234.5"},
+		{"+7", "7"},
+		{".25", "0.25"},
+		{"2.5e3", "2500"},
+		{"15E-4", "0.0015"},
+		{"0.000000001", "0.000000001"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.in)
+		if err != nil || d.String() != tt.want {
+			t.Errorf("ParseDecimal(%q) = %v, %v, want %s", tt.in, d, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "N/A", "1.2.3", "--1", "1e", "abc", "1e1000000000", "1e-4294967297", "1e31"} {
+		if _, err := ParseDecimal(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+	if d := DecimalOf("N/A"); d.Known() || d.String() != "N/A" {
+		t.Errorf("Expected N/A to be unknown, got %v", d)
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	d := DecimalOf
+	if got := d("0.1").Add(d("0.2")); got.String() != "0.3" {
+		t.Errorf("0.1 + 0.2 = %v", got)
+	}
+	if got := d("0.3").Mul(DecimalFromInt(10)); got.String() != "3.0" || !got.Equal(d("3")) {
+		t.Errorf("0.3 * 10 = %v", got)
+	}
+	tenth := 0.1
+	if got := DecimalFromFloat(tenth * 3); got.String() != "0.30000000000000004" {
+		t.Errorf("Expected the float error kept as it is, got %v", got)
+	}
+	if got := d("300.5").Sub(d("310.75")); got.String() != "-10.25" {
+		t.Errorf("300.5 - 310.75 = %v", got)
+	}
+	if got := d("10").Div(d("3"), 4); got.String() != "3.3333" {
+		t.Errorf("10 / 3 = %v", got)
+	}
+	if got := d("-2.5").Round(0); got.String() != "-3" {
+		t.Errorf("Expected -2.5 rounded away from zero, got %v", got)
+	}
+	if got := d("1.005").StringFixed(2); got != "1.01" {
+		t.Errorf("1.005 to two places = %s", got)
+	}
+	if got := d("7").StringFixed(2); got != "7.00" {
+		t.Errorf("7 to two places = %s", got)
+	}
+	if got := d("300.500").Trim(); got.String() != "300.5" {
+		t.Errorf("Trim = %v", got)
+	}
+	if got := d("5").Div(Decimal{}, 2); got.Known() {
+		t.Errorf("Expected division by zero to be N/A, got %v", got)
+	}
+	if got := NA.Add(d("1")); got.Known() {
+		t.Errorf("Expected N/A to propagate, got %v", got)
+	}
+	if d("2").Cmp(d("10")) >= 0 || d("-1").Sign() != -1 || NA.Sign() != 0 {
+		t.Error("Unexpected comparison")
+	}
+	// Too many decimals for int64 are rounded off rather than overflowing
+	if got := d("123456789.123456789").Mul(d("987654321.987654321")); !got.Known() || got.Float64() < 1.2193e17 {
+		t.Errorf("Unexpected large product %v", got)
+	}
+}
+
+func TestDecimal_Step(t *testing.T) {
+	d := DecimalOf
+	step := AssetDetails{Decimals: 2, MinStep: 5}.PriceStep()
+	if step.String() != "0.05" {
+		t.Fatalf("Expected a step of 0.05, got %v", step)
+	}
+	if got := (AssetDetails{Decimals: 1}).PriceStep(); got.String() != "0.1" {
+		t.Errorf("Expected a missing MinStep to count as one unit, got %v", got)
+	}
+
+	tests := []struct {
+		price, below, above, nearest string
+	}{
+		{"300.12", "300.10", "300.15", "300.10"},
+		{"300.13", "300.10", "300.15", "300.15"},
+		{"300.125", "300.100", "300.150", "300.15"},
+		{"300.15", "300.15", "300.15", "300.15"},
+		{"-0.07", "-0.10", "-0.05", "-0.05"},
+	}
+	for _, tt := range tests {
+		below, above := d(tt.price).Snap(step)
+		if below.String() != tt.below || above.String() != tt.above {
+			t.Errorf("Snap(%s) = %v, %v, want %s, %s", tt.price, below, above, tt.below, tt.above)
+		}
+		if got := d(tt.price).RoundStep(step); got.String() != tt.nearest {
+			t.Errorf("RoundStep(%s) = %v, want %s", tt.price, got, tt.nearest)
+		}
+	}
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	var v struct {
+		Price Decimal `json:"price"`
+		Stop  Decimal `json:"stop"`
+		Qty   Decimal `json:"qty"`
+	}
+	if err := json.Unmarshal([]byte(`{"price": 310.5, "stop": null, "qty": "2"}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Price.String() != "310.5" || v.Stop.Known() || v.Qty.String() != "2" {
+		t.Errorf("Unexpected values %+v", v)
+	}
+	b, _ := json.Marshal(v)
+	if string(b) != `{"price":310.5,"stop":null,"qty":2}` {
+		t.Errorf("Unexpected JSON %s", b)
+	}
+}
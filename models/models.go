@@ -1,25 +1,50 @@
 package models
 
 import (
-	"strconv"
 	"strings"
 	"time"
 )
 
 // Order type constants for the order modal
 const (
-	OrderTypeMarket     = "Market"
-	OrderTypeLimit      = "Limit"
-	OrderTypeStop       = "Stop-Loss"
-	OrderTypeTakeProfit = "Take-Profit"
-	OrderTypeSLTP       = "SL + TP"
+	OrderTypeMarket      = "Market"
+	OrderTypeLimit       = "Limit"
+	OrderTypeStop        = "Stop-Loss"
+	OrderTypeTakeProfit  = "Take-Profit"
+	OrderTypeSLTP        = "SL + TP"
+	OrderTypeStopLimit   = "Stop-Limit"
+	OrderTypeTrailing    = "Trailing"    // Stop-Loss that the terminal moves after the price
+	OrderTypeBracket     = "Bracket"     // Entry order protected by SL + TP as it fills
+	OrderTypeConditional = "Conditional" // Order the terminal sends when a price crosses a level
+)
+
+// Validity constants for conditional orders, as shown in the order modal
+const (
+	ValidityDay = "Day" // Until the end of the trading day
+	ValidityGTC = "GTC" // Good till cancelled
+	ValidityGTD = "GTD" // Good till the end of ValidUntil
 )
 
 // OrderParams holds parameters for placing an order beyond basic market orders.
 type OrderParams struct {
-	OrderType  string  // OrderTypeMarket, OrderTypeLimit, OrderTypeStop, OrderTypeTakeProfit
-	LimitPrice float64 // Required for Limit orders
-	StopPrice  float64 // Required for Stop-Loss and Take-Profit orders
+	OrderType  string    // OrderTypeMarket, OrderTypeLimit, OrderTypeStop, OrderTypeTakeProfit, OrderTypeStopLimit
+	LimitPrice Decimal   // Required for Limit and Stop-Limit orders
+	StopPrice  Decimal   // Required for Stop-Loss, Take-Profit and Stop-Limit orders
+	Validity   string    // ValidityDay, ValidityGTC or ValidityGTD for conditional orders; empty means GTC
+	ValidUntil time.Time // Last day of a GTD order
+}
+
+// RiskLimits configures the pre-trade risk checks. A zero limit disables its check.
+type RiskLimits struct {
+	MaxOrderValue    float64            // Max notional of one order, in the quote currency
+	MaxLots          float64            // Max lots in one order
+	MaxLotsBySymbol  map[string]float64 // Per-ticker MaxLots, overrides MaxLots
+	MaxPositionLots  float64            // Max absolute position per instrument after the order, in lots
+	PriceBandPercent float64            // Max deviation of an order price from the last price, in percent
+	MaxDailyOrders   int                // Max orders sent per account per day
+	AllowOverride    bool               // Whether a violation can be overridden after confirmation
+	DailyLossLimit   float64            // Loss of one account's equity since the start of the day that locks trading
+	CancelOnLock     bool               // Whether to cancel all working orders when the loss limit locks trading
 }
 
 // AccountInfo represents account information from Finam API
@@ -29,6 +54,7 @@ type AccountInfo struct {
 	Status        string
 	Equity        string
 	UnrealizedPnL string
+	DailyPnL      Decimal // Day's realized and unrealized P&L of all positions, closed ones included
 	OpenDate      time.Time
 	LoadError     string // Non-empty if account failed to load from broker
 }
@@ -39,44 +65,43 @@ type Position struct {
 	Ticker        string
 	Name          string
 	MIC           string
-	LotSize       float64
-	Quantity      string
-	AveragePrice  string
-	CurrentPrice  string
-	DailyPnL      string
-	UnrealizedPnL string
-	TotalValue    string
+	LotSize       Decimal
+	Quantity      Decimal
+	AveragePrice  Decimal
+	CurrentPrice  Decimal
+	DailyPnL      Decimal
+	UnrealizedPnL Decimal
+	TotalValue    Decimal
 }
 
 // GetCloseDirection returns the inverse direction needed to close the position.
 // Returns "Sell" for Long positions (>0), "Buy" for Short positions (<0),
 // and empty string for zero or invalid positions.
 func (p Position) GetCloseDirection() string {
-	val, err := strconv.ParseFloat(strings.ReplaceAll(p.Quantity, ",", "."), 64)
-	if err != nil || val == 0 {
-		return ""
-	}
-	if val > 0 {
+	switch p.Quantity.Sign() {
+	case 1:
 		return "Sell"
+	case -1:
+		return "Buy"
 	}
-	return "Buy"
+	return ""
 }
 
 // Quote represents a market quote
 type Quote struct {
 	Symbol       string
-	Bid          string
-	BidSize      string
-	Ask          string
-	AskSize      string
-	Last         string
-	LastSize     string
-	Volume       string
-	Open         string
-	High         string
-	Low          string
-	Close        string
-	OpenInterest string
+	Bid          Decimal
+	BidSize      Decimal
+	Ask          Decimal
+	AskSize      Decimal
+	Last         Decimal
+	LastSize     Decimal
+	Volume       Decimal
+	Open         Decimal
+	High         Decimal
+	Low          Decimal
+	Close        Decimal
+	OpenInterest Decimal
 	Timestamp    time.Time
 }
 
@@ -93,7 +118,7 @@ type SecurityInfo struct {
 	Ticker   string
 	Symbol   string
 	Name     string
-	Lot      float64
+	Lot      Decimal
 	Currency string
 }
 
@@ -103,13 +128,14 @@ type Trade struct {
 	Symbol    string
 	Name      string
 	Side      string
-	Price     string
-	Quantity  string
-	Total     string
+	Price     Decimal
+	Quantity  Decimal
+	Total     Decimal
 	Timestamp time.Time
 }
 
-// Bar represents a single candlestick bar for chart rendering
+// Bar represents a single candlestick bar for chart rendering. Its prices are floats
+// because they are only drawn, never traded on.
 type Bar struct {
 	Timestamp time.Time
 	Open      float64
@@ -119,6 +145,40 @@ type Bar struct {
 	Volume    float64
 }
 
+// MarketTrade represents an anonymous exchange print from the time & sales feed
+type MarketTrade struct {
+	ID        string
+	Symbol    string
+	Price     Decimal
+	Size      Decimal
+	Side      string // aggressor side: "Buy", "Sell" or "Unknown"
+	Timestamp time.Time
+}
+
+// OrderBookLevel represents a single price level of the order book
+type OrderBookLevel struct {
+	Price Decimal
+	Size  Decimal
+}
+
+// OrderBook represents the depth of market for an instrument.
+// Bids are sorted from best (highest) price down, asks from best (lowest) price up.
+type OrderBook struct {
+	Symbol    string
+	Bids      []OrderBookLevel
+	Asks      []OrderBookLevel
+	Timestamp time.Time
+}
+
+// Spread returns the difference between the best ask and the best bid.
+// ok is false when either side of the book is empty.
+func (b *OrderBook) Spread() (spread Decimal, ok bool) {
+	if b == nil || len(b.Bids) == 0 || len(b.Asks) == 0 {
+		return Decimal{}, false
+	}
+	return b.Asks[0].Price.Sub(b.Bids[0].Price), true
+}
+
 // AssetDetails represents detailed instrument information from GetAsset API
 type AssetDetails struct {
 	Board            string
@@ -139,6 +199,16 @@ type AssetDetails struct {
 	BondFaceCurrency string // currency of face value (bonds only)
 }
 
+// PriceStep returns the tick size of the instrument: MinStep units of the last of its
+// Decimals, e.g. 0.05 for Decimals 2 and MinStep 5. A missing MinStep counts as one unit.
+func (d AssetDetails) PriceStep() Decimal {
+	step, scale := d.MinStep, max(d.Decimals, 0)
+	if step <= 0 {
+		step = 1
+	}
+	return Decimal{coef: step, scale: scale}
+}
+
 // AssetParams represents trading parameters for an instrument
 type AssetParams struct {
 	IsTradable         bool
@@ -175,18 +245,39 @@ type Order struct {
 	Side          string
 	Type          string
 	Status        string
-	Quantity      string
-	Executed      string
-	Price         string
+	Quantity      Decimal
+	Executed      Decimal
+	Price         Decimal // Stop price of stop orders, limit price otherwise; zero for market orders
 	StopCondition string
-	LimitPrice    string
-	StopPrice     string
+	LimitPrice    Decimal
+	StopPrice     Decimal
 	Validity      string
-	ExecutedQty   string
-	RemainingQty  string
-	SLQty         string
-	TPQty         string
-	SLPrice       string
-	TPPrice       string
+	ExecutedQty   Decimal
+	RemainingQty  Decimal
+	SLQty         Decimal
+	TPQty         Decimal
+	SLPrice       Decimal
+	TPPrice       Decimal
 	CreationTime  time.Time
 }
+
+// PriceText returns the price of the order for display: "Market" for a market order and
+// the stop-loss and take-profit prices of an SL/TP order, e.g. "SL:240 TP:260".
+func (o Order) PriceText() string {
+	if o.Type == "SL/TP" {
+		var parts []string
+		if o.SLPrice.Sign() > 0 {
+			parts = append(parts, "SL:"+o.SLPrice.String())
+		}
+		if o.TPPrice.Sign() > 0 {
+			parts = append(parts, "TP:"+o.TPPrice.String())
+		}
+		if len(parts) > 0 {
+			return strings.Join(parts, " ")
+		}
+	}
+	if o.Price.Sign() <= 0 {
+		return "Market"
+	}
+	return o.Price.String()
+}
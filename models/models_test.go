@@ -36,7 +36,7 @@ func TestPosition_GetCloseDirection(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			p := Position{Quantity: tt.quantity}
+			p := Position{Quantity: DecimalOf(tt.quantity)}
 			if got := p.GetCloseDirection(); got != tt.want {
 				t.Errorf("Position.GetCloseDirection() = %v, want %v", got, tt.want)
 			}
@@ -49,7 +49,7 @@ func TestSecurityInfoStructure(t *testing.T) {
 	sec := SecurityInfo{
 		Ticker:   "AAPL",
 		Name:     "Apple Inc.",
-		Lot:      100,
+		Lot:      DecimalFromInt(100),
 		Currency: "USD",
 	}
 
@@ -59,8 +59,8 @@ func TestSecurityInfoStructure(t *testing.T) {
 	if sec.Name != "Apple Inc." {
 		t.Errorf("Expected Name Apple Inc., got %s", sec.Name)
 	}
-	if sec.Lot != 100 {
-		t.Errorf("Expected Lot 100, got %f", sec.Lot)
+	if sec.Lot.String() != "100" {
+		t.Errorf("Expected Lot 100, got %s", sec.Lot)
 	}
 	if sec.Currency != "USD" {
 		t.Errorf("Expected Currency USD, got %s", sec.Currency)
@@ -72,12 +72,12 @@ func TestTradeModel(t *testing.T) {
 		ID:       "T1",
 		Symbol:   "SBER",
 		Side:     "Buy",
-		Price:    "250.00",
-		Quantity: "10",
-		Total:    "2500.00",
+		Price:    DecimalOf("250.00"),
+		Quantity: DecimalOf("10"),
+		Total:    DecimalOf("2500.00"),
 		Name:     "Сбербанк",
 	}
-	if tr.Total != "2500.00" {
+	if tr.Total.String() != "2500.00" {
 		t.Errorf("Expected Total 2500.00, got %s", tr.Total)
 	}
 	if tr.Name != "Сбербанк" {
@@ -108,62 +108,79 @@ func TestOrderModel_ExtendedFields(t *testing.T) {
 		Side:          "Buy",
 		Type:          "Stop",
 		Status:        "New",
-		Quantity:      "100",
-		Executed:      "0",
-		Price:         "250.00",
+		Quantity:      DecimalOf("100"),
+		Executed:      DecimalOf("0"),
+		Price:         DecimalOf("250.00"),
 		StopCondition: "Last Down",
-		LimitPrice:    "248.00",
-		StopPrice:     "250.00",
+		LimitPrice:    DecimalOf("248.00"),
+		StopPrice:     DecimalOf("250.00"),
 		Validity:      "GTC",
-		ExecutedQty:   "0",
-		RemainingQty:  "100",
-		SLQty:         "50",
-		TPQty:         "50",
-		SLPrice:       "240.00",
-		TPPrice:       "260.00",
+		ExecutedQty:   DecimalOf("0"),
+		RemainingQty:  DecimalOf("100"),
+		SLQty:         DecimalOf("50"),
+		TPQty:         DecimalOf("50"),
+		SLPrice:       DecimalOf("240.00"),
+		TPPrice:       DecimalOf("260.00"),
 	}
 
 	if o.StopCondition != "Last Down" {
 		t.Errorf("Expected StopCondition 'Last Down', got '%s'", o.StopCondition)
 	}
-	if o.LimitPrice != "248.00" {
+	if o.LimitPrice.String() != "248.00" {
 		t.Errorf("Expected LimitPrice '248.00', got '%s'", o.LimitPrice)
 	}
-	if o.StopPrice != "250.00" {
+	if o.StopPrice.String() != "250.00" {
 		t.Errorf("Expected StopPrice '250.00', got '%s'", o.StopPrice)
 	}
 	if o.Validity != "GTC" {
 		t.Errorf("Expected Validity 'GTC', got '%s'", o.Validity)
 	}
-	if o.ExecutedQty != "0" {
+	if o.ExecutedQty.String() != "0" {
 		t.Errorf("Expected ExecutedQty '0', got '%s'", o.ExecutedQty)
 	}
-	if o.RemainingQty != "100" {
+	if o.RemainingQty.String() != "100" {
 		t.Errorf("Expected RemainingQty '100', got '%s'", o.RemainingQty)
 	}
-	if o.SLQty != "50" {
+	if o.SLQty.String() != "50" {
 		t.Errorf("Expected SLQty '50', got '%s'", o.SLQty)
 	}
-	if o.TPQty != "50" {
+	if o.TPQty.String() != "50" {
 		t.Errorf("Expected TPQty '50', got '%s'", o.TPQty)
 	}
-	if o.SLPrice != "240.00" {
+	if o.SLPrice.String() != "240.00" {
 		t.Errorf("Expected SLPrice '240.00', got '%s'", o.SLPrice)
 	}
-	if o.TPPrice != "260.00" {
+	if o.TPPrice.String() != "260.00" {
 		t.Errorf("Expected TPPrice '260.00', got '%s'", o.TPPrice)
 	}
 }
 
+func TestOrder_PriceText(t *testing.T) {
+	tests := []struct {
+		order Order
+		want  string
+	}{
+		{Order{Type: "Market"}, "Market"},
+		{Order{Type: "Limit", Price: DecimalOf("250.50")}, "250.50"},
+		{Order{Type: "SL/TP", SLPrice: DecimalOf("240"), TPPrice: DecimalOf("260")}, "SL:240 TP:260"},
+		{Order{Type: "SL/TP", SLPrice: NA, TPPrice: DecimalOf("260")}, "TP:260"},
+	}
+	for _, tt := range tests {
+		if got := tt.order.PriceText(); got != tt.want {
+			t.Errorf("PriceText() of %+v = %q, want %q", tt.order, got, tt.want)
+		}
+	}
+}
+
 func TestPositionLotSize(t *testing.T) {
 	p := Position{
 		Symbol:   "SBER",
-		LotSize:  10,
-		Quantity: "100",
+		LotSize:  DecimalFromInt(10),
+		Quantity: DecimalOf("100"),
 	}
 
-	if p.LotSize != 10 {
-		t.Errorf("Expected LotSize 10, got %f", p.LotSize)
+	if p.LotSize.String() != "10" {
+		t.Errorf("Expected LotSize 10, got %s", p.LotSize)
 	}
 }
 
@@ -177,3 +194,25 @@ func TestPositionNameField(t *testing.T) {
 		t.Errorf("Expected Name Сбербанк, got %s", p.Name)
 	}
 }
+
+func TestOrderBook_Spread(t *testing.T) {
+	book := &OrderBook{
+		Bids: []OrderBookLevel{{Price: DecimalOf("250.40"), Size: DecimalFromInt(10)}, {Price: DecimalOf("250.30"), Size: DecimalFromInt(5)}},
+		Asks: []OrderBookLevel{{Price: DecimalOf("250.60"), Size: DecimalFromInt(7)}},
+	}
+	spread, ok := book.Spread()
+	if !ok {
+		t.Fatal("Expected spread to be available")
+	}
+	if spread.String() != "0.20" {
+		t.Errorf("Expected spread 0.20, got %s", spread)
+	}
+
+	if _, ok := (&OrderBook{Bids: book.Bids}).Spread(); ok {
+		t.Error("Expected no spread for one-sided book")
+	}
+	var empty *OrderBook
+	if _, ok := empty.Spread(); ok {
+		t.Error("Expected no spread for nil book")
+	}
+}
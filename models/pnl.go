@@ -0,0 +1,341 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Lot matching methods of the P&L engine
+const (
+	MatchFIFO    = "fifo"    // A closing trade matches the oldest open lots first
+	MatchAverage = "average" // A closing trade matches the average cost of the position
+)
+
+// avgPricePlaces is the number of decimals average prices are rounded to. Quantities and
+// the P&L of a lot are exact.
+const avgPricePlaces = 8
+
+// Lot is an open part of a position. Quantity is positive for a long and negative for a
+// short, in units of the instrument like Trade.Quantity.
+type Lot struct {
+	Symbol   string
+	Quantity Decimal
+	Price    Decimal
+	Opened   time.Time // Zero when the lot was opened before the trade history
+	TradeID  string    // Trade that opened the lot; empty before the history or when averaged
+}
+
+// ClosedLot is a part of a position closed by a trade, with its realized P&L.
+type ClosedLot struct {
+	Symbol       string
+	Side         string // "Long" or "Short"
+	Quantity     Decimal
+	OpenPrice    Decimal
+	ClosePrice   Decimal
+	Opened       time.Time // Zero when the lot was opened before the trade history
+	Closed       time.Time
+	OpenTradeID  string
+	CloseTradeID string
+	PnL          Decimal
+}
+
+// Holding returns how long the lot was held, or 0 when its opening is unknown.
+func (c ClosedLot) Holding() time.Duration {
+	if c.Opened.IsZero() {
+		return 0
+	}
+	return c.Closed.Sub(c.Opened)
+}
+
+// PnLStats summarizes realized P&L. Wins and losses count closing trades, so a sell that
+// closes several lots counts once; a trade that made nothing is a loss.
+type PnLStats struct {
+	Realized    Decimal
+	GrossProfit Decimal
+	GrossLoss   Decimal // Negative or zero
+	Wins        int
+	Losses      int
+	AvgHolding  time.Duration // Quantity-weighted over the lots with a known opening
+}
+
+// Closes returns the number of closing trades.
+func (s PnLStats) Closes() int {
+	return s.Wins + s.Losses
+}
+
+// WinRate returns the share of winning closing trades in percent, or 0 without any.
+func (s PnLStats) WinRate() float64 {
+	if s.Closes() == 0 {
+		return 0
+	}
+	return float64(s.Wins) / float64(s.Closes()) * 100
+}
+
+// InstrumentPnL is the realized P&L of one instrument and what is left open.
+type InstrumentPnL struct {
+	Symbol string
+	PnLStats
+	OpenQuantity Decimal
+	OpenPrice    Decimal // Average price of the open lots
+}
+
+// DayPnL is the realized P&L of one calendar day.
+type DayPnL struct {
+	Date     time.Time // Local midnight
+	Realized Decimal
+	Closes   int
+}
+
+// PnLReport is the result of replaying the trades of one account.
+type PnLReport struct {
+	Method      string
+	Closed      []ClosedLot // In closing order
+	Open        []Lot       // By symbol, oldest first
+	Instruments []InstrumentPnL
+	Days        []DayPnL
+	Total       PnLStats
+}
+
+// OpeningLots returns the lots held before the first of trades, derived from the current
+// positions: what the trades do not explain was already held. Their price is the average
+// price reported by the broker and their opening is unknown.
+func OpeningLots(positions []Position, trades []Trade) []Lot {
+	traded := make(map[string]Decimal)
+	for _, t := range trades {
+		traded[t.Symbol] = traded[t.Symbol].Add(signedQuantity(t))
+	}
+	var lots []Lot
+	for _, p := range positions {
+		if !p.Quantity.Known() {
+			continue
+		}
+		held := p.Quantity.Sub(traded[p.Symbol])
+		if held.IsZero() {
+			continue
+		}
+		price := p.AveragePrice
+		if !price.Known() {
+			price = Decimal{}
+		}
+		lots = append(lots, Lot{Symbol: p.Symbol, Quantity: held, Price: price})
+	}
+	return lots
+}
+
+// ComputePnL replays trades on top of the opening lots and matches closing trades with
+// method. A trade that closes more than is open opens a position the other way.
+func ComputePnL(trades []Trade, opening []Lot, method string) (*PnLReport, error) {
+	if method == "" {
+		method = MatchFIFO
+	}
+	if method != MatchFIFO && method != MatchAverage {
+		return nil, fmt.Errorf("unknown P&L method %q", method)
+	}
+
+	open := make(map[string][]Lot)
+	for _, l := range opening {
+		open[l.Symbol] = addLot(open[l.Symbol], l, method)
+	}
+
+	sorted := make([]Trade, len(trades))
+	copy(sorted, trades)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	report := &PnLReport{Method: method}
+	for _, t := range sorted {
+		qty := signedQuantity(t)
+		if qty.IsZero() || !t.Price.Known() {
+			continue
+		}
+		price := t.Price
+		lots := open[t.Symbol]
+		for len(lots) > 0 && !qty.IsZero() && qty.Sign() == -lots[0].Quantity.Sign() {
+			lot := &lots[0]
+			matched := qty.Abs()
+			if lot.Quantity.Abs().Cmp(matched) < 0 {
+				matched = lot.Quantity.Abs()
+			}
+			// A long is closed by selling, a short by buying
+			side, closed, pnl := "Long", matched, price.Sub(lot.Price).Mul(matched)
+			if lot.Quantity.Sign() < 0 {
+				side, closed, pnl = "Short", matched.Neg(), pnl.Neg()
+			}
+			report.Closed = append(report.Closed, ClosedLot{
+				Symbol:       t.Symbol,
+				Side:         side,
+				Quantity:     matched,
+				OpenPrice:    lot.Price,
+				ClosePrice:   price,
+				Opened:       lot.Opened,
+				Closed:       t.Timestamp,
+				OpenTradeID:  lot.TradeID,
+				CloseTradeID: t.ID,
+				PnL:          pnl,
+			})
+			lot.Quantity = lot.Quantity.Sub(closed)
+			qty = qty.Add(closed)
+			if lot.Quantity.IsZero() {
+				lots = lots[1:]
+			}
+		}
+		if !qty.IsZero() {
+			lots = addLot(lots, Lot{Symbol: t.Symbol, Quantity: qty, Price: price, Opened: t.Timestamp, TradeID: t.ID}, method)
+		}
+		open[t.Symbol] = lots
+	}
+
+	report.summarize(open)
+	return report, nil
+}
+
+// addLot adds l to the open lots of its instrument. With average cost the position stays a
+// single lot at the average price, opened at the quantity-weighted average time.
+func addLot(lots []Lot, l Lot, method string) []Lot {
+	if method != MatchAverage || len(lots) == 0 {
+		return append(lots, l)
+	}
+	cur := lots[0]
+	total := cur.Quantity.Add(l.Quantity)
+	cur.Price = cur.Price.Mul(cur.Quantity).Add(l.Price.Mul(l.Quantity)).Div(total, avgPricePlaces).Trim()
+	switch {
+	case cur.Opened.IsZero() || l.Opened.IsZero():
+		cur.Opened = time.Time{}
+	default:
+		share := l.Quantity.Float64() / total.Float64()
+		cur.Opened = cur.Opened.Add(time.Duration(float64(l.Opened.Sub(cur.Opened)) * share))
+	}
+	if cur.TradeID != l.TradeID {
+		cur.TradeID = ""
+	}
+	cur.Quantity = total
+	return []Lot{cur}
+}
+
+// summarize fills the per-instrument, per-day and total figures from the closed and
+// open lots.
+func (r *PnLReport) summarize(open map[string][]Lot) {
+	instruments := make(map[string]*InstrumentPnL)
+	instrument := func(symbol string) *InstrumentPnL {
+		if instruments[symbol] == nil {
+			instruments[symbol] = &InstrumentPnL{Symbol: symbol}
+		}
+		return instruments[symbol]
+	}
+
+	// A closing trade is a win or a loss by the sum of the lots it closed
+	type closing struct {
+		symbol string
+		day    time.Time
+		pnl    Decimal
+	}
+	var order []string
+	closes := make(map[string]*closing)
+	days := make(map[time.Time]*DayPnL)
+	holding := make(map[string][2]float64) // symbol: weighted seconds, quantity
+	var totalHolding [2]float64
+
+	for i, c := range r.Closed {
+		key := c.CloseTradeID
+		if key == "" {
+			key = fmt.Sprintf("#%d", i)
+		}
+		y, m, d := c.Closed.Date()
+		day := time.Date(y, m, d, 0, 0, 0, 0, c.Closed.Location())
+		if closes[key] == nil {
+			closes[key] = &closing{symbol: c.Symbol, day: day}
+			order = append(order, key)
+		}
+		closes[key].pnl = closes[key].pnl.Add(c.PnL)
+
+		if days[day] == nil {
+			days[day] = &DayPnL{Date: day}
+		}
+		days[day].Realized = days[day].Realized.Add(c.PnL)
+
+		if !c.Opened.IsZero() {
+			h, q := c.Holding().Seconds(), c.Quantity.Float64()
+			w := holding[c.Symbol]
+			w[0] += h * q
+			w[1] += q
+			holding[c.Symbol] = w
+			totalHolding[0] += h * q
+			totalHolding[1] += q
+		}
+	}
+
+	for _, key := range order {
+		c := closes[key]
+		days[c.day].Closes++
+		inst := instrument(c.symbol)
+		inst.add(c.pnl)
+		r.Total.add(c.pnl)
+	}
+	for symbol, w := range holding {
+		instrument(symbol).AvgHolding = avgHolding(w)
+	}
+	r.Total.AvgHolding = avgHolding(totalHolding)
+
+	symbols := make([]string, 0, len(open))
+	for symbol := range open {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		var qty, cost Decimal
+		for _, l := range open[symbol] {
+			r.Open = append(r.Open, l)
+			qty = qty.Add(l.Quantity)
+			cost = cost.Add(l.Quantity.Mul(l.Price))
+		}
+		if qty.IsZero() {
+			continue
+		}
+		inst := instrument(symbol)
+		inst.OpenQuantity = qty
+		inst.OpenPrice = cost.Div(qty, avgPricePlaces).Trim()
+	}
+
+	for _, inst := range instruments {
+		r.Instruments = append(r.Instruments, *inst)
+	}
+	sort.Slice(r.Instruments, func(i, j int) bool { return r.Instruments[i].Symbol < r.Instruments[j].Symbol })
+	for _, d := range days {
+		r.Days = append(r.Days, *d)
+	}
+	sort.Slice(r.Days, func(i, j int) bool { return r.Days[i].Date.Before(r.Days[j].Date) })
+}
+
+// add counts one closing trade.
+func (s *PnLStats) add(pnl Decimal) {
+	s.Realized = s.Realized.Add(pnl)
+	if pnl.Sign() > 0 {
+		s.Wins++
+		s.GrossProfit = s.GrossProfit.Add(pnl)
+	} else {
+		s.Losses++
+		s.GrossLoss = s.GrossLoss.Add(pnl)
+	}
+}
+
+func avgHolding(w [2]float64) time.Duration {
+	if w[1] == 0 {
+		return 0
+	}
+	return time.Duration(w[0] / w[1] * float64(time.Second))
+}
+
+// signedQuantity returns the quantity of t, negative for a sell, and zero when unknown.
+func signedQuantity(t Trade) Decimal {
+	if !t.Quantity.Known() {
+		return Decimal{}
+	}
+	qty := t.Quantity.Abs()
+	switch t.Side {
+	case "Buy":
+		return qty
+	case "Sell":
+		return qty.Neg()
+	}
+	return Decimal{}
+}
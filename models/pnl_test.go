@@ -0,0 +1,128 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func pnlTrade(id, symbol, side, price, qty string, ts time.Time) Trade {
+	return Trade{ID: id, Symbol: symbol, Side: side, Price: DecimalOf(price), Quantity: DecimalOf(qty), Timestamp: ts}
+}
+
+// is reports whether d is the number v, whatever its scale.
+func is(d Decimal, v string) bool {
+	return d.Equal(DecimalOf(v))
+}
+
+func TestComputePnL_FIFO(t *testing.T) {
+	day1 := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	trades := []Trade{
+		pnlTrade("T3", "SBER", "Sell", "320", "15", day2),
+		pnlTrade("T1", "SBER", "Buy", "300", "10", day1),
+		pnlTrade("T2", "SBER", "Buy", "310", "10", day1.Add(time.Hour)),
+		pnlTrade("T4", "GAZP", "Sell", "150", "5", day1),
+		pnlTrade("T5", "GAZP", "Buy", "160", "5", day2),
+	}
+
+	r, err := ComputePnL(trades, nil, MatchFIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// SBER: 10 @300 and 5 @310 sold at 320 = 200 + 50; GAZP short: 5 * (150-160) = -50
+	if len(r.Closed) != 3 {
+		t.Fatalf("Expected three closed lots, got %+v", r.Closed)
+	}
+	if !is(r.Total.Realized, "200") || r.Total.Wins != 1 || r.Total.Losses != 1 {
+		t.Errorf("Unexpected total %+v", r.Total)
+	}
+	if r.Closed[2].Side != "Short" || !is(r.Closed[2].PnL, "-50") {
+		t.Errorf("Expected the GAZP short to close last at -50, got %+v", r.Closed[2])
+	}
+
+	if len(r.Instruments) != 2 || r.Instruments[1].Symbol != "SBER" {
+		t.Fatalf("Unexpected instruments %+v", r.Instruments)
+	}
+	sber := r.Instruments[1]
+	if !is(sber.Realized, "250") || !is(sber.OpenQuantity, "5") || !is(sber.OpenPrice, "310") {
+		t.Errorf("Expected SBER +250 with 5 left at 310, got %+v", sber)
+	}
+	// 10 held 24h and 5 held 23h
+	want := time.Duration((10*24 + 5*23) / 15.0 * float64(time.Hour))
+	if sber.AvgHolding != want {
+		t.Errorf("Expected an average holding of %v, got %v", want, sber.AvgHolding)
+	}
+
+	if len(r.Days) != 1 || !r.Days[0].Date.Equal(time.Date(2026, 3, 3, 0, 0, 0, 0, time.Local)) || r.Days[0].Closes != 2 {
+		t.Errorf("Expected both closes on March 3rd, got %+v", r.Days)
+	}
+}
+
+func TestComputePnL_Average(t *testing.T) {
+	day := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	trades := []Trade{
+		pnlTrade("T1", "SBER", "Buy", "300", "10", day),
+		pnlTrade("T2", "SBER", "Buy", "310", "10", day.Add(time.Hour)),
+		pnlTrade("T3", "SBER", "Sell", "320", "15", day.Add(2*time.Hour)),
+	}
+	r, err := ComputePnL(trades, nil, MatchAverage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 15 sold at 320 against an average of 305
+	if !is(r.Total.Realized, "225") {
+		t.Errorf("Expected 225 realized at average cost, got %v", r.Total.Realized)
+	}
+	if len(r.Open) != 1 || !is(r.Open[0].Quantity, "5") || !is(r.Open[0].Price, "305") {
+		t.Errorf("Expected 5 left at 305, got %+v", r.Open)
+	}
+	if r.Total.AvgHolding != 90*time.Minute {
+		t.Errorf("Expected the average position held 90 minutes, got %v", r.Total.AvgHolding)
+	}
+
+	if _, err := ComputePnL(trades, nil, "lifo"); err == nil {
+		t.Error("Expected an unknown method to be refused")
+	}
+}
+
+func TestComputePnL_FlipsThroughZero(t *testing.T) {
+	day := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	r, _ := ComputePnL([]Trade{
+		pnlTrade("T1", "SBER", "Buy", "300", "10", day),
+		pnlTrade("T2", "SBER", "Sell", "290", "15", day.Add(time.Hour)),
+	}, nil, MatchFIFO)
+	if !is(r.Total.Realized, "-100") || r.Total.WinRate() != 0 {
+		t.Errorf("Expected a 100 loss, got %+v", r.Total)
+	}
+	if len(r.Open) != 1 || !is(r.Open[0].Quantity, "-5") || !is(r.Open[0].Price, "290") {
+		t.Errorf("Expected a 5 short left at 290, got %+v", r.Open)
+	}
+}
+
+func TestOpeningLots(t *testing.T) {
+	positions := []Position{
+		{Symbol: "SBER", Quantity: DecimalOf("30"), AveragePrice: DecimalOf("280")},
+		{Symbol: "GAZP", Quantity: DecimalOf("10"), AveragePrice: DecimalOf("150")},
+	}
+	day := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	trades := []Trade{
+		pnlTrade("T1", "SBER", "Buy", "300", "10", day),
+		pnlTrade("T2", "SBER", "Sell", "310", "20", day.Add(time.Hour)),
+		pnlTrade("T3", "GAZP", "Buy", "150", "10", day),
+	}
+
+	lots := OpeningLots(positions, trades)
+	if len(lots) != 1 || lots[0].Symbol != "SBER" || !is(lots[0].Quantity, "40") || !lots[0].Opened.IsZero() {
+		t.Fatalf("Expected 40 SBER held before the history, got %+v", lots)
+	}
+
+	r, _ := ComputePnL(trades, lots, MatchFIFO)
+	// 20 of the 40 held at 280 sold at 310
+	if !is(r.Total.Realized, "600") {
+		t.Errorf("Expected 600 realized against the opening lots, got %v", r.Total.Realized)
+	}
+	if r.Total.AvgHolding != 0 {
+		t.Errorf("Expected no holding period for lots of unknown age, got %v", r.Total.AvgHolding)
+	}
+}
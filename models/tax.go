@@ -0,0 +1,360 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// lossCarryYears is how many years a loss on securities can be carried forward.
+const lossCarryYears = 10
+
+// sharePlaces is the number of decimals of money split between trades or lots, such as
+// the share of a commission. Totals are rounded to kopecks only when they are printed.
+const sharePlaces = 8
+
+// NDFL rates and the thresholds above which the higher rate applies
+var (
+	ndflRate          = DecimalOf("0.13")
+	ndflHighRate      = DecimalOf("0.15")
+	ndflThreshold2021 = DecimalFromInt(5_000_000)
+	ndflThreshold2025 = DecimalFromInt(2_400_000)
+)
+
+// TaxLeg is the buy or the sell side of a closed lot.
+type TaxLeg struct {
+	TradeID    string    // Empty when the lot was opened before the trade history
+	Time       time.Time // Zero when the lot was opened before the trade history
+	Price      Decimal
+	Amount     Decimal // Share of the trade's total that falls on the lot, with a bond's accrued interest
+	Commission Decimal // Share of the trade's commission that falls on the lot
+}
+
+// TaxClose is a lot closed in FIFO order, with both its legs. For a long the buy leg
+// opened it; for a short the sell leg did.
+type TaxClose struct {
+	Symbol   string
+	Side     string // "Long" or "Short"
+	Quantity Decimal
+	Buy      TaxLeg
+	Sell     TaxLeg
+}
+
+// Result returns the taxable result of the close: the sale less the purchase and the
+// commissions of both legs.
+func (c TaxClose) Result() Decimal {
+	return c.Sell.Amount.Sub(c.Buy.Amount).Sub(c.Buy.Commission).Sub(c.Sell.Commission)
+}
+
+// Opened returns when the lot was opened, zero when it was before the trade history.
+func (c TaxClose) Opened() time.Time {
+	if c.Side == "Short" {
+		return c.Sell.Time
+	}
+	return c.Buy.Time
+}
+
+// Closed returns when the lot was closed.
+func (c TaxClose) Closed() time.Time {
+	if c.Side == "Short" {
+		return c.Buy.Time
+	}
+	return c.Sell.Time
+}
+
+// Holding returns how long the lot was held, or 0 when its opening is unknown.
+func (c TaxClose) Holding() time.Duration {
+	if c.Opened().IsZero() {
+		return 0
+	}
+	return c.Closed().Sub(c.Opened())
+}
+
+// TaxPayment is a dividend, a coupon or a tax withheld or refunded by the broker.
+type TaxPayment struct {
+	Time        time.Time
+	Category    string // TxDividend, TxCoupon or TxTax
+	Symbol      string
+	Description string
+	Amount      Decimal // As credited; a withholding is negative
+	Currency    string
+}
+
+// TaxYear is the tax report of one calendar year. Amounts are in rubles: payments in
+// other currencies are listed but left out of the totals, and trades are taken as
+// settled in rubles.
+type TaxYear struct {
+	Year     int
+	Closes   []TaxClose   // By closing time
+	Payments []TaxPayment // By time
+
+	Proceeds         Decimal // Sell legs of the closes
+	Costs            Decimal // Buy legs of the closes
+	Commissions      Decimal // Commissions of the closes and OtherCommissions
+	OtherCommissions Decimal // Commissions of the year that match no trade
+	Gains            Decimal // Profitable closes
+	Losses           Decimal // Losing closes, negative or zero
+	Result           Decimal // Gains and losses less OtherCommissions
+	LossCarried      Decimal // Losses of earlier years set off against Result
+	LossForward      Decimal // Losses of this and earlier years left for later years
+	TradingBase      Decimal // Result less LossCarried, zero when negative
+
+	Dividends Decimal
+	Coupons   Decimal
+	Withheld  Decimal // Tax withheld by the broker less refunds
+	Tax       Decimal // Tax on TradingBase, Dividends and Coupons
+	Due       Decimal // Tax less Withheld; negative when overpaid
+
+	ForeignPayments int // Payments in other currencies, left out of the totals
+}
+
+// NDFL returns the personal income tax on an investment income base of year, rounded to
+// whole rubles. Until 2020 the rate is a flat 13%; from 2021 the part above 5 million
+// rubles is taxed at 15%, and from 2025 the part above 2.4 million.
+func NDFL(year int, base Decimal) Decimal {
+	if base.Sign() <= 0 {
+		return Decimal{}
+	}
+	var threshold Decimal
+	switch {
+	case year >= 2025:
+		threshold = ndflThreshold2025
+	case year >= 2021:
+		threshold = ndflThreshold2021
+	default:
+		return base.Mul(ndflRate).Round(0)
+	}
+	if base.Cmp(threshold) <= 0 {
+		return base.Mul(ndflRate).Round(0)
+	}
+	return threshold.Mul(ndflRate).Add(base.Sub(threshold).Mul(ndflHighRate)).Round(0)
+}
+
+// ComputeTax builds the tax report of year from the whole trade history, the lots held
+// before it and the cash transactions of the same period. Closes are matched in FIFO
+// order. A net loss of a year is carried forward and set off against the results of the
+// next ten years, so the history should start well before year.
+func ComputeTax(trades []Trade, opening []Lot, txs []Transaction, year int) TaxYear {
+	pnl, _ := ComputePnL(trades, opening, MatchFIFO)
+	perTrade, other := tradeCommissions(trades, txs)
+	quantities := make(map[string]Decimal)
+	totals := make(map[string]Decimal)
+	for _, t := range trades {
+		if t.ID != "" {
+			quantities[t.ID] = quantities[t.ID].Add(signedQuantity(t).Abs())
+			totals[t.ID] = totals[t.ID].Add(tradeAmount(t))
+		}
+	}
+	// share returns the part of a per-trade amount that falls on qty of the trade
+	share := func(amounts map[string]Decimal, tradeID string, qty Decimal) Decimal {
+		if quantities[tradeID].IsZero() {
+			return Decimal{}
+		}
+		return amounts[tradeID].Mul(qty).Div(quantities[tradeID], sharePlaces).Trim()
+	}
+	// legAmount takes the amount of a lot from its trade's total, which for a bond includes
+	// the accrued coupon interest; lots opened before the history are valued at their price
+	legAmount := func(tradeID string, price, qty Decimal) Decimal {
+		if totals[tradeID].IsZero() {
+			return price.Mul(qty)
+		}
+		return share(totals, tradeID, qty)
+	}
+
+	years := make(map[int]*TaxYear)
+	yearOf := func(y int) *TaxYear {
+		if years[y] == nil {
+			years[y] = &TaxYear{Year: y}
+		}
+		return years[y]
+	}
+
+	for _, c := range pnl.Closed {
+		opening := TaxLeg{
+			TradeID:    c.OpenTradeID,
+			Time:       c.Opened,
+			Price:      c.OpenPrice,
+			Amount:     legAmount(c.OpenTradeID, c.OpenPrice, c.Quantity),
+			Commission: share(perTrade, c.OpenTradeID, c.Quantity),
+		}
+		closing := TaxLeg{
+			TradeID:    c.CloseTradeID,
+			Time:       c.Closed,
+			Price:      c.ClosePrice,
+			Amount:     legAmount(c.CloseTradeID, c.ClosePrice, c.Quantity),
+			Commission: share(perTrade, c.CloseTradeID, c.Quantity),
+		}
+		tc := TaxClose{Symbol: c.Symbol, Side: c.Side, Quantity: c.Quantity, Buy: opening, Sell: closing}
+		if c.Side == "Short" {
+			tc.Buy, tc.Sell = closing, opening
+		}
+
+		y := yearOf(c.Closed.Year())
+		y.Closes = append(y.Closes, tc)
+		y.Proceeds = y.Proceeds.Add(tc.Sell.Amount)
+		y.Costs = y.Costs.Add(tc.Buy.Amount)
+		y.Commissions = y.Commissions.Add(tc.Buy.Commission).Add(tc.Sell.Commission)
+		if r := tc.Result(); r.Sign() > 0 {
+			y.Gains = y.Gains.Add(r)
+		} else {
+			y.Losses = y.Losses.Add(r)
+		}
+	}
+	for y, amount := range other {
+		ty := yearOf(y)
+		ty.OtherCommissions = ty.OtherCommissions.Add(amount)
+		ty.Commissions = ty.Commissions.Add(amount)
+	}
+
+	for _, tx := range txs {
+		if tx.Category != TxDividend && tx.Category != TxCoupon && tx.Category != TxTax {
+			continue
+		}
+		if !tx.Amount.Known() {
+			continue
+		}
+		amount := tx.Amount
+		y := yearOf(tx.Timestamp.Year())
+		y.Payments = append(y.Payments, TaxPayment{
+			Time:        tx.Timestamp,
+			Category:    tx.Category,
+			Symbol:      tx.Symbol,
+			Description: tx.Description,
+			Amount:      amount,
+			Currency:    tx.Currency,
+		})
+		if !isRubles(tx.Currency) {
+			y.ForeignPayments++
+			continue
+		}
+		switch tx.Category {
+		case TxDividend:
+			y.Dividends = y.Dividends.Add(amount)
+		case TxCoupon:
+			y.Coupons = y.Coupons.Add(amount)
+		case TxTax:
+			y.Withheld = y.Withheld.Sub(amount)
+		}
+	}
+
+	// Carry the losses of earlier years forward, oldest first
+	first := year
+	for y := range years {
+		first = min(first, y)
+	}
+	type loss struct {
+		year   int
+		amount Decimal
+	}
+	var losses []loss
+	for y := first; y <= year; y++ {
+		ty := yearOf(y)
+		ty.Result = ty.Gains.Add(ty.Losses).Sub(ty.OtherCommissions)
+		base := ty.Result
+		for len(losses) > 0 && base.Sign() > 0 {
+			if losses[0].year+lossCarryYears < y {
+				losses = losses[1:]
+				continue
+			}
+			applied := base
+			if losses[0].amount.Cmp(applied) < 0 {
+				applied = losses[0].amount
+			}
+			ty.LossCarried = ty.LossCarried.Add(applied)
+			base = base.Sub(applied)
+			if losses[0].amount = losses[0].amount.Sub(applied); losses[0].amount.Sign() <= 0 {
+				losses = losses[1:]
+			}
+		}
+		if base.Sign() > 0 {
+			ty.TradingBase = base
+		}
+		if ty.Result.Sign() < 0 {
+			losses = append(losses, loss{year: y, amount: ty.Result.Neg()})
+		}
+		for _, l := range losses {
+			ty.LossForward = ty.LossForward.Add(l.amount)
+		}
+		ty.Tax = NDFL(y, ty.TradingBase.Add(ty.Dividends).Add(ty.Coupons))
+		ty.Due = ty.Tax.Sub(ty.Withheld)
+	}
+
+	report := *yearOf(year)
+	sort.SliceStable(report.Closes, func(i, j int) bool {
+		return report.Closes[i].Closed().Before(report.Closes[j].Closed())
+	})
+	sort.SliceStable(report.Payments, func(i, j int) bool {
+		return report.Payments[i].Time.Before(report.Payments[j].Time)
+	})
+	return report
+}
+
+// tradeCommissions spreads the commission transactions over the trades: a commission that
+// names an instrument goes to the trades of that instrument on the same day, in proportion
+// to their amount. Commissions that match no trade are returned by year.
+func tradeCommissions(trades []Trade, txs []Transaction) (map[string]Decimal, map[int]Decimal) {
+	type key struct{ symbol, day string }
+	byDay := make(map[key][]Trade)
+	for _, t := range trades {
+		if t.ID == "" {
+			continue
+		}
+		k := key{tickerOf(t.Symbol), t.Timestamp.Format("2006-01-02")}
+		byDay[k] = append(byDay[k], t)
+	}
+
+	perTrade := make(map[string]Decimal)
+	other := make(map[int]Decimal)
+	for _, tx := range txs {
+		if tx.Category != TxCommission || !isRubles(tx.Currency) {
+			continue
+		}
+		if !tx.Amount.Known() {
+			continue
+		}
+		commission := tx.Amount.Neg() // Charged as a negative amount
+
+		var matched []Trade
+		var total Decimal
+		if tx.Symbol != "" {
+			matched = byDay[key{tickerOf(tx.Symbol), tx.Timestamp.Format("2006-01-02")}]
+			for _, t := range matched {
+				total = total.Add(tradeAmount(t))
+			}
+		}
+		if total.IsZero() {
+			y := tx.Timestamp.Year()
+			other[y] = other[y].Add(commission)
+			continue
+		}
+		for _, t := range matched {
+			perTrade[t.ID] = perTrade[t.ID].Add(commission.Mul(tradeAmount(t)).Div(total, sharePlaces).Trim())
+		}
+	}
+	return perTrade, other
+}
+
+// tradeAmount returns the money amount of t, from its total or else price times quantity.
+func tradeAmount(t Trade) Decimal {
+	if t.Total.Sign() != 0 {
+		return t.Total.Abs()
+	}
+	if !t.Price.Known() {
+		return Decimal{}
+	}
+	return t.Price.Mul(signedQuantity(t)).Abs()
+}
+
+// tickerOf returns the ticker of a symbol without its exchange, e.g. "SBER" of "SBER@MISX".
+func tickerOf(symbol string) string {
+	ticker, _, _ := strings.Cut(symbol, "@")
+	return strings.ToUpper(strings.TrimSpace(ticker))
+}
+
+func isRubles(currency string) bool {
+	switch strings.ToUpper(currency) {
+	case "", "RUB", "RUR":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNDFL(t *testing.T) {
+	tests := []struct {
+		year int
+		base string
+		want string
+	}{
+		{2020, "10000000", "1300000"},
+		{2024, "1000000", "130000"},
+		{2024, "6000000", "800000"},
+		{2025, "3400000", "462000"},
+		{2025, "1000.50", "130"},
+		{2025, "-100", "0"},
+	}
+	for _, tt := range tests {
+		if got := NDFL(tt.year, DecimalOf(tt.base)); !is(got, tt.want) {
+			t.Errorf("NDFL(%d, %v) = %v, want %v", tt.year, tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestComputeTax_LegsAndCommissions(t *testing.T) {
+	buy := time.Date(2024, 11, 5, 11, 0, 0, 0, time.Local)
+	sell := time.Date(2025, 2, 10, 12, 0, 0, 0, time.Local)
+	trades := []Trade{
+		pnlTrade("B1", "SBER@MISX", "Buy", "250", "100", buy),
+		pnlTrade("S1", "SBER@MISX", "Sell", "300", "60", sell),
+		pnlTrade("S2", "GAZP@MISX", "Sell", "150", "10", sell),
+		pnlTrade("B2", "GAZP@MISX", "Buy", "160", "10", sell.Add(time.Hour)),
+	}
+	txs := []Transaction{
+		{Category: TxCommission, Symbol: "SBER", Amount: DecimalOf("-10"), Currency: "RUB", Timestamp: buy.Add(time.Minute)},
+		{Category: TxCommission, Symbol: "SBER", Amount: DecimalOf("-6"), Currency: "RUB", Timestamp: sell.Add(time.Minute)},
+		{Category: TxCommission, Amount: DecimalOf("-300"), Currency: "RUB", Timestamp: sell},
+		{Category: TxDividend, Symbol: "SBER", Amount: DecimalOf("1000"), Currency: "RUB", Timestamp: sell.AddDate(0, 4, 0)},
+		{Category: TxTax, Amount: DecimalOf("-130"), Currency: "RUB", Timestamp: sell.AddDate(0, 4, 0)},
+		{Category: TxCoupon, Symbol: "XS000", Amount: DecimalOf("50"), Currency: "USD", Timestamp: sell},
+	}
+
+	y := ComputeTax(trades, nil, txs, 2025)
+	if len(y.Closes) != 2 {
+		t.Fatalf("Expected two closes, got %+v", y.Closes)
+	}
+
+	long := y.Closes[0]
+	if long.Buy.TradeID != "B1" || long.Sell.TradeID != "S1" || !is(long.Quantity, "60") {
+		t.Errorf("Unexpected legs of the SBER close %+v", long)
+	}
+	// 60 of the 100 bought carry 60% of the buy commission
+	if !is(long.Buy.Commission, "6") || !is(long.Sell.Commission, "6") {
+		t.Errorf("Expected commissions of 6 on both legs, got %+v", long)
+	}
+	if !is(long.Result(), "2988") || long.Holding() != sell.Sub(buy) {
+		t.Errorf("Unexpected result %v or holding %v", long.Result(), long.Holding())
+	}
+
+	short := y.Closes[1]
+	if short.Side != "Short" || short.Sell.TradeID != "S2" || short.Buy.TradeID != "B2" || !is(short.Result(), "-100") {
+		t.Errorf("Expected the GAZP short sold by S2 and bought back by B2, got %+v", short)
+	}
+	if short.Holding() != time.Hour {
+		t.Errorf("Expected the short held an hour, got %v", short.Holding())
+	}
+
+	if !is(y.OtherCommissions, "300") || !is(y.Result, "2588") {
+		t.Errorf("Unexpected other commissions %v or result %v", y.OtherCommissions, y.Result)
+	}
+	if !is(y.Dividends, "1000") || !y.Coupons.IsZero() || y.ForeignPayments != 1 || !is(y.Withheld, "130") {
+		t.Errorf("Unexpected income %+v", y)
+	}
+	if !y.Tax.Equal(NDFL(2025, DecimalFromInt(2588+1000))) || !y.Due.Equal(y.Tax.Sub(DecimalFromInt(130))) {
+		t.Errorf("Unexpected tax %v due %v", y.Tax, y.Due)
+	}
+}
+
+func TestComputeTax_CarriesLossesForward(t *testing.T) {
+	day := func(year int) time.Time { return time.Date(year, 6, 1, 12, 0, 0, 0, time.Local) }
+	trades := []Trade{
+		pnlTrade("1", "SBER", "Buy", "300", "10", day(2023)),
+		pnlTrade("2", "SBER", "Sell", "200", "10", day(2023).Add(time.Hour)),
+		pnlTrade("3", "SBER", "Buy", "200", "10", day(2025)),
+		pnlTrade("4", "SBER", "Sell", "250", "10", day(2025).Add(time.Hour)),
+		pnlTrade("5", "SBER", "Buy", "200", "10", day(2026)),
+		pnlTrade("6", "SBER", "Sell", "300", "10", day(2026).Add(time.Hour)),
+	}
+
+	y2023 := ComputeTax(trades, nil, nil, 2023)
+	if !is(y2023.Result, "-1000") || !y2023.TradingBase.IsZero() || !is(y2023.LossForward, "1000") {
+		t.Errorf("Expected a loss of 1000 to carry forward, got %+v", y2023)
+	}
+
+	y2025 := ComputeTax(trades, nil, nil, 2025)
+	if !is(y2025.LossCarried, "500") || !y2025.TradingBase.IsZero() || !is(y2025.LossForward, "500") {
+		t.Errorf("Expected 500 of the loss set off in 2025, got %+v", y2025)
+	}
+
+	y2026 := ComputeTax(trades, nil, nil, 2026)
+	if !is(y2026.LossCarried, "500") || !is(y2026.TradingBase, "500") || !is(y2026.Tax, "65") || !y2026.LossForward.IsZero() {
+		t.Errorf("Expected the rest of the loss set off in 2026, got %+v", y2026)
+	}
+}
+
+func TestComputeTax_OpeningLots(t *testing.T) {
+	sell := time.Date(2025, 3, 3, 12, 0, 0, 0, time.Local)
+	trades := []Trade{pnlTrade("S1", "SBER", "Sell", "300", "10", sell)}
+	opening := []Lot{{Symbol: "SBER", Quantity: DecimalFromInt(10), Price: DecimalFromInt(280)}}
+
+	y := ComputeTax(trades, opening, nil, 2025)
+	if len(y.Closes) != 1 {
+		t.Fatalf("Expected one close, got %+v", y.Closes)
+	}
+	c := y.Closes[0]
+	if c.Buy.TradeID != "" || !c.Buy.Time.IsZero() || !is(c.Buy.Amount, "2800") || c.Holding() != 0 {
+		t.Errorf("Expected a buy leg before the history at the average price, got %+v", c)
+	}
+}
+
+func TestComputeTax_BondLegsIncludeAccruedInterest(t *testing.T) {
+	buy := time.Date(2025, 3, 3, 12, 0, 0, 0, time.Local)
+	sell := buy.AddDate(0, 2, 0)
+	// 10 bonds at 985 with 100 of accrued interest, then 4 sold at 990 with 40 of it
+	b1 := pnlTrade("B1", "SU26238RMFS4@MISX", "Buy", "985", "10", buy)
+	b1.Total = DecimalOf("9950")
+	s1 := pnlTrade("S1", "SU26238RMFS4@MISX", "Sell", "990", "4", sell)
+	s1.Total = DecimalOf("4000")
+
+	y := ComputeTax([]Trade{b1, s1}, nil, nil, 2025)
+	if len(y.Closes) != 1 {
+		t.Fatalf("Expected one close, got %+v", y.Closes)
+	}
+	c := y.Closes[0]
+	// The buy leg carries 4/10 of the purchase total, interest included
+	if !is(c.Buy.Amount, "3980") || !is(c.Sell.Amount, "4000") || !is(c.Result(), "20") {
+		t.Errorf("Expected legs of 3980 and 4000 from the trade totals, got %+v", c)
+	}
+	if !is(y.Proceeds, "4000") || !is(y.Costs, "3980") {
+		t.Errorf("Expected proceeds 4000 and costs 3980, got %v and %v", y.Proceeds, y.Costs)
+	}
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Categories of cash transactions
+const (
+	TxDeposit    = "Deposit"
+	TxWithdrawal = "Withdrawal"
+	TxCommission = "Commission"
+	TxDividend   = "Dividend"
+	TxCoupon     = "Coupon"
+	TxIncome     = "Income" // Other income, e.g. interest on cash
+	TxTax        = "Tax"
+	TxOther      = "Other"
+)
+
+// TxCategories lists the transaction categories in display order.
+var TxCategories = []string{TxDeposit, TxWithdrawal, TxCommission, TxDividend, TxCoupon, TxIncome, TxTax, TxOther}
+
+// Periods transaction totals are grouped by
+const (
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+	PeriodYear    = "year"
+)
+
+// Transaction is a cash movement on an account: a deposit, a withdrawal, a commission,
+// an income payment or a tax.
+type Transaction struct {
+	ID          string
+	Category    string  // One of TxCategories
+	Description string  // Category as reported by the broker
+	Symbol      string  // Instrument the transaction relates to, if any
+	Amount      Decimal // Signed: negative for money leaving the account
+	Currency    string
+	Timestamp   time.Time
+}
+
+// txKeywords map words in the broker's category to a transaction category. The first
+// match wins, so the more specific words come first.
+var txKeywords = []struct {
+	category string
+	words    []string
+}{
+	{TxDividend, []string{"dividend", "дивиденд"}},
+	{TxCoupon, []string{"coupon", "купон"}},
+	{TxTax, []string{"tax", "налог", "ндфл"}},
+	{TxCommission, []string{"commission", "fee", "комисс", "вознагражд"}},
+	{TxDeposit, []string{"deposit", "пополн", "зачисл", "ввод"}},
+	{TxWithdrawal, []string{"withdraw", "вывод", "списан"}},
+	{TxIncome, []string{"income", "interest", "доход", "процент"}},
+}
+
+// TxCategory returns the transaction category of a category reported by the broker.
+func TxCategory(reported string) string {
+	lower := strings.ToLower(reported)
+	for _, k := range txKeywords {
+		for _, w := range k.words {
+			if strings.Contains(lower, w) {
+				return k.category
+			}
+		}
+	}
+	return TxOther
+}
+
+// TxTotal is the sum of the transactions of one category in one period and currency.
+type TxTotal struct {
+	Period   string // "2026-03", "2026-Q1" or "2026"
+	Category string
+	Currency string
+	Amount   Decimal
+	Count    int
+}
+
+// PeriodOf returns the label of the period t falls in.
+func PeriodOf(t time.Time, period string) string {
+	switch period {
+	case PeriodYear:
+		return strconv.Itoa(t.Year())
+	case PeriodQuarter:
+		return t.Format("2006") + "-Q" + strconv.Itoa((int(t.Month())-1)/3+1)
+	default:
+		return t.Format("2006-01")
+	}
+}
+
+// SummarizeTransactions totals txs by period, category and currency. Periods come oldest
+// first and categories in TxCategories order.
+func SummarizeTransactions(txs []Transaction, period string) []TxTotal {
+	type key struct{ period, category, currency string }
+	totals := make(map[key]*TxTotal)
+	for _, tx := range txs {
+		if !tx.Amount.Known() {
+			continue
+		}
+		k := key{PeriodOf(tx.Timestamp, period), tx.Category, tx.Currency}
+		if totals[k] == nil {
+			totals[k] = &TxTotal{Period: k.period, Category: k.category, Currency: k.currency}
+		}
+		totals[k].Amount = totals[k].Amount.Add(tx.Amount)
+		totals[k].Count++
+	}
+
+	order := make(map[string]int, len(TxCategories))
+	for i, c := range TxCategories {
+		order[c] = i
+	}
+	out := make([]TxTotal, 0, len(totals))
+	for _, t := range totals {
+		out = append(out, *t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		if a.Category != b.Category {
+			return order[a.Category] < order[b.Category]
+		}
+		return a.Currency < b.Currency
+	})
+	return out
+}
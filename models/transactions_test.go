@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTxCategory(t *testing.T) {
+	tests := map[string]string{
+		"DEPOSIT": TxDeposit,
+		"Вывод денежных средств":     TxWithdrawal,
+		"Комиссия брокера":           TxCommission,
+		"Зачисление дивидендов SBER": TxDividend,
+		"Купонный доход":             TxCoupon,
+		"Удержание НДФЛ":             TxTax,
+		"Проценты на остаток":        TxIncome,
+		"Перевод между счетами":      TxOther,
+		"": TxOther,
+	}
+	for reported, want := range tests {
+		if got := TxCategory(reported); got != want {
+			t.Errorf("%q: expected %s, got %s", reported, want, got)
+		}
+	}
+}
+
+func TestSummarizeTransactions(t *testing.T) {
+	at := func(month time.Month, day int) time.Time { return time.Date(2026, month, day, 12, 0, 0, 0, time.Local) }
+	txs := []Transaction{
+		{Category: TxCommission, Amount: DecimalOf("-10.5"), Currency: "RUB", Timestamp: at(1, 10)},
+		{Category: TxDeposit, Amount: DecimalOf("100000"), Currency: "RUB", Timestamp: at(1, 5)},
+		{Category: TxCommission, Amount: DecimalOf("-4.5"), Currency: "RUB", Timestamp: at(1, 20)},
+		{Category: TxCommission, Amount: DecimalOf("-1"), Currency: "USD", Timestamp: at(1, 21)},
+		{Category: TxCoupon, Amount: DecimalOf("35.2"), Currency: "RUB", Timestamp: at(4, 1)},
+	}
+
+	monthly := SummarizeTransactions(txs, PeriodMonth)
+	if len(monthly) != 4 {
+		t.Fatalf("Expected four totals, got %+v", monthly)
+	}
+	if monthly[0].Category != TxDeposit || !monthly[1].Amount.Equal(DecimalFromInt(-15)) || monthly[1].Count != 2 {
+		t.Errorf("Expected the deposit then 15 RUB of commissions in January, got %+v", monthly[:2])
+	}
+	if monthly[2].Currency != "USD" || monthly[3].Period != "2026-04" {
+		t.Errorf("Expected USD commissions apart and the coupon in April, got %+v", monthly[2:])
+	}
+
+	quarterly := SummarizeTransactions(txs, PeriodQuarter)
+	if quarterly[3].Period != "2026-Q2" || quarterly[3].Amount.String() != "35.2" {
+		t.Errorf("Expected the coupon in Q2, got %+v", quarterly[3])
+	}
+	if yearly := SummarizeTransactions(txs, PeriodYear); yearly[0].Period != "2026" {
+		t.Errorf("Expected yearly periods, got %+v", yearly)
+	}
+}
@@ -0,0 +1,251 @@
+// Package oco implements one-cancels-other groups of working orders. The broker has no
+// OCO orders, so the terminal links orders itself: when any member of a group fills, even
+// partially, the other members are cancelled. Groups are saved to a JSON file so they
+// survive a restart.
+package oco
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"finam-terminal/atomicfile"
+)
+
+// Group is a set of working orders of one account that cancel each other.
+type Group struct {
+	ID        string    `json:"id"`
+	AccountID string    `json:"account_id"`
+	OrderIDs  []string  `json:"order_ids"`
+	Created   time.Time `json:"created"`
+}
+
+// Siblings returns the members of the group other than orderID.
+func (g Group) Siblings(orderID string) []string {
+	var out []string
+	for _, id := range g.OrderIDs {
+		if id != orderID {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// ErrTooFew is returned by Link when fewer than two orders are given.
+var ErrTooFew = errors.New("an OCO group needs at least two orders")
+
+// Manager keeps the OCO groups and their file. It is safe for concurrent use.
+type Manager struct {
+	// Now returns the current time, used to stamp new groups.
+	Now func() time.Time
+
+	mu        sync.Mutex
+	path      string
+	groups    map[string]*Group
+	replacing map[string]bool // members being replaced by a modification
+	next      int
+}
+
+// NewManager returns a manager saving its groups to path. An empty path keeps the groups
+// in memory only.
+func NewManager(path string) *Manager {
+	return &Manager{
+		Now:       time.Now,
+		path:      path,
+		groups:    make(map[string]*Group),
+		replacing: make(map[string]bool),
+	}
+}
+
+// Load reads the groups saved in the manager's file. A missing file is not an error.
+func (m *Manager) Load() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read OCO groups: %w", err)
+	}
+	var groups []Group
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return fmt.Errorf("failed to parse OCO groups %s: %w", m.path, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range groups {
+		g := groups[i]
+		m.groups[g.ID] = &g
+		if n, err := strconv.Atoi(strings.TrimPrefix(g.ID, "G")); err == nil && n > m.next {
+			m.next = n
+		}
+	}
+	return nil
+}
+
+// Link groups the working orders orderIDs of accountID and returns the new group. An order
+// belongs to one group at most.
+func (m *Manager) Link(accountID string, orderIDs []string) (Group, error) {
+	var ids []string
+	for _, id := range orderIDs {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) < 2 {
+		return Group{}, ErrTooFew
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		if g := m.groupOf(id); g != nil {
+			return Group{}, fmt.Errorf("order %s is already in OCO group %s", id, g.ID)
+		}
+	}
+	m.next++
+	g := &Group{
+		ID:        fmt.Sprintf("G%d", m.next),
+		AccountID: accountID,
+		OrderIDs:  ids,
+		Created:   m.Now(),
+	}
+	m.groups[g.ID] = g
+	return *g, m.save()
+}
+
+// Unlink dissolves the group id. Its orders are left alone.
+func (m *Manager) Unlink(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.groups[id]; !ok {
+		return nil
+	}
+	delete(m.groups, id)
+	return m.save()
+}
+
+// Groups returns the groups of accountID, or of every account when accountID is empty,
+// ordered by creation.
+func (m *Manager) Groups(accountID string) []Group {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Group
+	for _, g := range m.groups {
+		if accountID == "" || g.AccountID == accountID {
+			out = append(out, clone(*g))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Created.Before(out[j].Created) })
+	return out
+}
+
+// GroupOf returns the group orderID belongs to.
+func (m *Manager) GroupOf(orderID string) (Group, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if g := m.groupOf(orderID); g != nil {
+		return clone(*g), true
+	}
+	return Group{}, false
+}
+
+// Trigger dissolves the group of orderID after that order filled and returns it, so the
+// caller cancels its siblings. Only the first call for a group returns it.
+func (m *Manager) Trigger(orderID string) (Group, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g := m.groupOf(orderID)
+	if g == nil {
+		return Group{}, false, nil
+	}
+	delete(m.groups, g.ID)
+	return clone(*g), true, m.save()
+}
+
+// Drop removes orderID from its group after the order ended without a fill. A group left
+// with a single order is dissolved. Members being replaced are kept.
+func (m *Manager) Drop(orderID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g := m.groupOf(orderID)
+	if g == nil || m.replacing[orderID] {
+		return nil
+	}
+	g.OrderIDs = slices.DeleteFunc(g.OrderIDs, func(id string) bool { return id == orderID })
+	if len(g.OrderIDs) < 2 {
+		delete(m.groups, g.ID)
+	}
+	return m.save()
+}
+
+// Replacing marks orderID as being replaced, so its cancellation does not drop it from its
+// group. Replaced must follow.
+func (m *Manager) Replacing(orderID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replacing[orderID] = true
+}
+
+// Replaced puts newID in place of oldID in its group. An empty newID means the replacement
+// failed and oldID stays.
+func (m *Manager) Replaced(oldID, newID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.replacing, oldID)
+	g := m.groupOf(oldID)
+	if g == nil || newID == "" {
+		return nil
+	}
+	for i, id := range g.OrderIDs {
+		if id == oldID {
+			g.OrderIDs[i] = newID
+		}
+	}
+	return m.save()
+}
+
+// groupOf returns the group holding orderID. Called with m.mu held.
+func (m *Manager) groupOf(orderID string) *Group {
+	for _, g := range m.groups {
+		if slices.Contains(g.OrderIDs, orderID) {
+			return g
+		}
+	}
+	return nil
+}
+
+// save writes the groups to the manager's file. Called with m.mu held.
+func (m *Manager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	groups := make([]Group, 0, len(m.groups))
+	for _, g := range m.groups {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Created.Before(groups[j].Created) })
+	data, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.Write(m.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save OCO groups: %w", err)
+	}
+	return nil
+}
+
+func clone(g Group) Group {
+	g.OrderIDs = slices.Clone(g.OrderIDs)
+	return g
+}
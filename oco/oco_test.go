@@ -0,0 +1,128 @@
+package oco
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLink(t *testing.T) {
+	m := NewManager("")
+
+	if _, err := m.Link("acc1", []string{"o1", "o1"}); !errors.Is(err, ErrTooFew) {
+		t.Errorf("Expected ErrTooFew for one distinct order, got %v", err)
+	}
+
+	g, err := m.Link("acc1", []string{"o1", "o2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.ID == "" || !slices.Equal(g.OrderIDs, []string{"o1", "o2"}) {
+		t.Fatalf("Unexpected group %+v", g)
+	}
+
+	// An order belongs to one group at most
+	if _, err := m.Link("acc1", []string{"o2", "o3"}); err == nil {
+		t.Error("Expected an error linking an order that is already grouped")
+	}
+
+	if got, ok := m.GroupOf("o2"); !ok || got.ID != g.ID {
+		t.Errorf("Expected o2 in %s, got %+v", g.ID, got)
+	}
+	if got := g.Siblings("o1"); !slices.Equal(got, []string{"o2"}) {
+		t.Errorf("Expected sibling o2, got %v", got)
+	}
+}
+
+func TestTrigger(t *testing.T) {
+	m := NewManager("")
+	g, _ := m.Link("acc1", []string{"o1", "o2", "o3"})
+
+	got, ok, err := m.Trigger("o2")
+	if err != nil || !ok || got.ID != g.ID {
+		t.Fatalf("Expected group %s to trigger, got %+v %v %v", g.ID, got, ok, err)
+	}
+	if !slices.Equal(got.Siblings("o2"), []string{"o1", "o3"}) {
+		t.Errorf("Expected siblings o1 and o3, got %v", got.Siblings("o2"))
+	}
+
+	// A group triggers once
+	if _, ok, _ := m.Trigger("o1"); ok {
+		t.Error("Expected no second trigger")
+	}
+	if len(m.Groups("")) != 0 {
+		t.Errorf("Expected the group dissolved, got %+v", m.Groups(""))
+	}
+}
+
+func TestDrop(t *testing.T) {
+	m := NewManager("")
+	g, _ := m.Link("acc1", []string{"o1", "o2", "o3"})
+
+	if err := m.Drop("o1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := m.GroupOf("o2"); !slices.Equal(got.OrderIDs, []string{"o2", "o3"}) {
+		t.Fatalf("Expected o2 and o3 left, got %v", got.OrderIDs)
+	}
+
+	// A member being modified is cancelled by the modification and stays
+	m.Replacing("o2")
+	m.Drop("o2")
+	if err := m.Replaced("o2", "o4"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := m.GroupOf("o4"); !slices.Equal(got.OrderIDs, []string{"o4", "o3"}) {
+		t.Fatalf("Expected o4 in place of o2, got %v", got.OrderIDs)
+	}
+
+	// A single order left is no group
+	m.Drop("o3")
+	if _, ok := m.GroupOf("o4"); ok {
+		t.Errorf("Expected group %s dissolved", g.ID)
+	}
+}
+
+func TestManager_PersistsGroups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "oco-groups.json")
+
+	m := NewManager(path)
+	g, err := m.Link("acc1", []string{"o1", "o2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := NewManager(path)
+	if err := restarted.Load(); err != nil {
+		t.Fatal(err)
+	}
+	groups := restarted.Groups("acc1")
+	if len(groups) != 1 || !slices.Equal(groups[0].OrderIDs, []string{"o1", "o2"}) {
+		t.Fatalf("Expected the group after a restart, got %+v", groups)
+	}
+
+	// New IDs continue after the loaded ones
+	next, _ := restarted.Link("acc1", []string{"o3", "o4"})
+	if next.ID == g.ID {
+		t.Errorf("Expected a new ID, got %s again", next.ID)
+	}
+
+	if err := restarted.Unlink(g.ID); err != nil {
+		t.Fatal(err)
+	}
+	again := NewManager(path)
+	if err := again.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Groups("")) != 1 {
+		t.Errorf("Expected one group after unlinking, got %d", len(again.Groups("")))
+	}
+}
+
+func TestManager_LoadMissingFile(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "none.json"))
+	if err := m.Load(); err != nil {
+		t.Errorf("Expected no error for a missing file, got %v", err)
+	}
+}
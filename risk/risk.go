@@ -0,0 +1,359 @@
+// Package risk implements the pre-trade checks that run before an order is sent to the
+// broker: order size and value limits, a position limit, a fat-finger price band, a daily
+// order count and the instrument's tradability. It also watches the accounts' daily loss and
+// locks trading when the loss limit is hit or the kill switch is pulled. The lock and the
+// day's order counts are saved to a JSON file, so a restart or a CLI run sees them too.
+package risk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"finam-terminal/models"
+
+	"finam-terminal/atomicfile"
+)
+
+// Rule names, as shown next to a violation.
+const (
+	RuleTradable    = "tradable"
+	RuleShortable   = "shortable"
+	RuleLongable    = "longable"
+	RuleOrderValue  = "order value"
+	RuleLots        = "lots"
+	RulePosition    = "position"
+	RulePriceBand   = "price band"
+	RuleDailyOrders = "daily orders"
+	RuleLocked      = "trading locked"
+)
+
+// Order describes an order about to be sent, with the market context the checks need.
+// Zero values for unknown context skip the checks that depend on them.
+type Order struct {
+	AccountID string
+	Ticker    string // Ticker or symbol; the part before "@" is matched against MaxLotsBySymbol
+	Side      string // "Buy" or "Sell"
+	Lots      models.Decimal
+	LotSize   models.Decimal   // Shares per lot; 0 when unknown
+	Prices    []models.Decimal // Limit, stop, SL and TP prices of the order; empty for market orders
+	LastPrice models.Decimal
+	Position  models.Decimal      // Current signed position in shares
+	Params    *models.AssetParams // nil when unknown
+}
+
+// shares returns the order quantity in shares.
+func (o Order) shares() models.Decimal {
+	if o.LotSize.Sign() > 0 {
+		return o.Lots.Mul(o.LotSize)
+	}
+	return o.Lots
+}
+
+// lotPlaces is the number of decimal places kept when dividing shares into lots or
+// computing a price deviation.
+const lotPlaces = 8
+
+// Violation is one failed check.
+type Violation struct {
+	Rule   string
+	Reason string
+}
+
+func (v Violation) String() string {
+	return v.Rule + ": " + v.Reason
+}
+
+// Error reports the violations that stopped an order.
+type Error struct {
+	Violations []Violation
+	// Overridable reports whether the order may still be sent after the user confirms.
+	Overridable bool
+}
+
+func (e *Error) Error() string {
+	reasons := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		reasons[i] = v.String()
+	}
+	return "risk check failed: " + strings.Join(reasons, "; ")
+}
+
+// Engine checks orders against the configured limits, counts the orders sent per day and
+// tracks each account's daily P&L. It is safe for concurrent use.
+type Engine struct {
+	// Now returns the current time; the daily counters and the lock reset at local midnight.
+	Now func() time.Time
+
+	mu         sync.Mutex
+	limits     models.RiskLimits
+	path       string
+	day        string
+	sent       map[string]int // account -> orders sent today
+	lockReason string         // non-empty while trading is locked
+}
+
+// state is the part of an engine saved to its file.
+type state struct {
+	Day        string         `json:"day"`
+	Sent       map[string]int `json:"sent,omitempty"`
+	LockReason string         `json:"lock_reason,omitempty"`
+}
+
+// NewEngine returns an engine enforcing limits.
+func NewEngine(limits models.RiskLimits) *Engine {
+	return &Engine{
+		Now:    time.Now,
+		limits: limits,
+		sent:   make(map[string]int),
+	}
+}
+
+// Restore loads the lock and the order counts saved in path and keeps saving them there.
+// A missing file is not an error; a file from an earlier day is ignored.
+func (e *Engine) Restore(path string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.path = path
+	return e.load()
+}
+
+// Limits returns the configured limits.
+func (e *Engine) Limits() models.RiskLimits {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.limits
+}
+
+// SetLimits replaces the configured limits. The daily order count is kept.
+func (e *Engine) SetLimits(limits models.RiskLimits) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.limits = limits
+}
+
+// Check returns nil when o passes every check, or an *Error listing the violations.
+func (e *Engine) Check(o Order) error {
+	e.mu.Lock()
+	e.refresh()
+	limits := e.limits
+	sent := e.sent[o.AccountID]
+	lockReason := e.lockReason
+	e.mu.Unlock()
+
+	var vs []Violation
+	add := func(rule, format string, args ...any) {
+		vs = append(vs, Violation{Rule: rule, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	signed := o.shares()
+	if o.Side == "Sell" {
+		signed = signed.Neg()
+	}
+	after := o.Position.Add(signed)
+	grows := after.Abs().Cmp(o.Position.Abs()) > 0
+
+	if p := o.Params; p != nil {
+		if !p.IsTradable {
+			add(RuleTradable, "%s is not tradable on this account", o.Ticker)
+		}
+		if after.Sign() < 0 && after.Cmp(o.Position) < 0 && p.Shortable == "Not Available" {
+			add(RuleShortable, "short selling %s is not available", o.Ticker)
+		}
+		if after.Sign() > 0 && after.Cmp(o.Position) > 0 && p.Longable == "Not Available" {
+			add(RuleLongable, "buying %s is not available", o.Ticker)
+		}
+	}
+
+	maxLots := limits.MaxLots
+	if v, ok := limits.MaxLotsBySymbol[tickerOf(o.Ticker)]; ok {
+		maxLots = v
+	}
+	if maxLots > 0 && o.Lots.Cmp(models.DecimalFromFloat(maxLots)) > 0 {
+		add(RuleLots, "%s lots exceeds the limit of %s", o.Lots.Trim(), formatNumber(maxLots))
+	}
+
+	if limits.MaxOrderValue > 0 {
+		// Market orders are valued at the last price, others at their highest price
+		price := o.LastPrice
+		if len(o.Prices) > 0 {
+			price = models.Decimal{}
+			for _, p := range o.Prices {
+				if p.Cmp(price) > 0 {
+					price = p
+				}
+			}
+		}
+		value := o.shares().Mul(price)
+		if price.Sign() > 0 && value.Cmp(models.DecimalFromFloat(limits.MaxOrderValue)) > 0 {
+			add(RuleOrderValue, "order value %s exceeds the limit of %.2f", value.StringFixed(2), limits.MaxOrderValue)
+		}
+	}
+
+	// Only orders that grow the position are limited, so a position can always be reduced
+	if limits.MaxPositionLots > 0 && o.LotSize.Sign() > 0 && grows {
+		lots := after.Abs().Div(o.LotSize, lotPlaces).Trim()
+		if lots.Cmp(models.DecimalFromFloat(limits.MaxPositionLots)) > 0 {
+			add(RulePosition, "position would reach %s lots, limit is %s", lots, formatNumber(limits.MaxPositionLots))
+		}
+	}
+
+	if limits.PriceBandPercent > 0 && o.LastPrice.Sign() > 0 {
+		band := models.DecimalFromFloat(limits.PriceBandPercent)
+		for _, p := range o.Prices {
+			if p.Sign() <= 0 {
+				continue
+			}
+			dev := p.Sub(o.LastPrice).Abs().Mul(models.DecimalFromInt(100)).Div(o.LastPrice, lotPlaces)
+			if dev.Cmp(band) > 0 {
+				add(RulePriceBand, "price %s is %s%% away from the last price %s (band %s%%)",
+					p.Trim(), dev.StringFixed(1), o.LastPrice.Trim(), formatNumber(limits.PriceBandPercent))
+			}
+		}
+	}
+
+	if limits.MaxDailyOrders > 0 && sent >= limits.MaxDailyOrders {
+		add(RuleDailyOrders, "%d orders already sent today, limit is %d", sent, limits.MaxDailyOrders)
+	}
+
+	// A locked terminal may still reduce positions, but never open or add to one
+	locked := lockReason != "" && grows
+	if locked {
+		add(RuleLocked, "%s; only orders that reduce a position are allowed", lockReason)
+	}
+
+	if len(vs) == 0 {
+		return nil
+	}
+	return &Error{Violations: vs, Overridable: limits.AllowOverride && !locked}
+}
+
+// ObservePnL records the daily P&L of accountID as reported by the broker, realized and
+// unrealized results together, so deposits and withdrawals never count as a loss. It reports
+// true when this observation crossed the daily loss limit and locked trading.
+func (e *Engine) ObservePnL(accountID string, pnl models.Decimal) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.refresh()
+	limit := e.limits.DailyLossLimit
+	loss := pnl.Neg()
+	if limit <= 0 || e.lockReason != "" || loss.Cmp(models.DecimalFromFloat(limit)) < 0 {
+		return false, nil
+	}
+	e.lockReason = fmt.Sprintf("daily loss %s on account %s reached the limit of %.2f", loss.StringFixed(2), accountID, limit)
+	return true, e.save()
+}
+
+// Lock stops new opening orders until the end of the day. A terminal that is already
+// locked keeps its first reason.
+func (e *Engine) Lock(reason string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.refresh()
+	if e.lockReason != "" {
+		return nil
+	}
+	e.lockReason = reason
+	return e.save()
+}
+
+// Locked returns the reason trading is locked, or false when it is not.
+func (e *Engine) Locked() (reason string, locked bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rollDay()
+	return e.lockReason, e.lockReason != ""
+}
+
+// Record counts an order sent for accountID towards the daily limit.
+func (e *Engine) Record(accountID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.refresh()
+	e.sent[accountID]++
+	return e.save()
+}
+
+// SentToday returns the number of orders recorded for accountID today, by this process
+// and the ones sharing its file.
+func (e *Engine) SentToday(accountID string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.refresh()
+	return e.sent[accountID]
+}
+
+// rollDay resets the daily counters and the lock on a new day. Called with e.mu held.
+func (e *Engine) rollDay() {
+	if day := e.Now().Format(time.DateOnly); day != e.day {
+		e.day = day
+		clear(e.sent)
+		e.lockReason = ""
+	}
+}
+
+// refresh rolls the day and merges what another process, such as a CLI run, saved to the
+// file since it was last read. Called with e.mu held.
+func (e *Engine) refresh() {
+	// A broken file leaves the state in memory, which the next save writes back
+	_ = e.load()
+}
+
+// load merges the state saved in the engine's file into the engine: the higher order count
+// of each account and the first lock reason win. Called with e.mu held.
+func (e *Engine) load() error {
+	e.rollDay()
+	if e.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(e.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read risk state: %w", err)
+	}
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("failed to parse risk state %s: %w", e.path, err)
+	}
+	if st.Day != e.day {
+		return nil
+	}
+	for acc, n := range st.Sent {
+		e.sent[acc] = max(e.sent[acc], n)
+	}
+	if e.lockReason == "" {
+		e.lockReason = st.LockReason
+	}
+	return nil
+}
+
+// save writes the state to the engine's file. Called with e.mu held.
+func (e *Engine) save() error {
+	if e.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state{Day: e.day, Sent: e.sent, LockReason: e.lockReason}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.Write(e.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save risk state: %w", err)
+	}
+	return nil
+}
+
+// tickerOf strips the exchange code from a symbol.
+func tickerOf(symbol string) string {
+	ticker, _, _ := strings.Cut(symbol, "@")
+	return strings.ToUpper(ticker)
+}
+
+func formatNumber(v float64) string {
+	return fmt.Sprintf("%g", v)
+}
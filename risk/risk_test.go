@@ -0,0 +1,221 @@
+package risk
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"finam-terminal/models"
+)
+
+func rules(err error) []string {
+	var rerr *Error
+	if !errors.As(err, &rerr) {
+		return nil
+	}
+	out := make([]string, len(rerr.Violations))
+	for i, v := range rerr.Violations {
+		out[i] = v.Rule
+	}
+	return out
+}
+
+func TestCheck_NoLimitsPasses(t *testing.T) {
+	e := NewEngine(models.RiskLimits{})
+	if err := e.Check(Order{Ticker: "SBER", Side: "Buy", Lots: models.DecimalFromInt(1000000), LotSize: models.DecimalFromInt(10), LastPrice: models.DecimalFromInt(285)}); err != nil {
+		t.Errorf("Expected no violations without limits, got %v", err)
+	}
+}
+
+func TestCheck_SizeAndValueLimits(t *testing.T) {
+	e := NewEngine(models.RiskLimits{
+		MaxOrderValue:   100000,
+		MaxLots:         50,
+		MaxLotsBySymbol: map[string]float64{"GAZP": 500},
+	})
+
+	// 60 lots × 10 shares × 285 = 171 000
+	err := e.Check(Order{Ticker: "SBER@TQBR", Side: "Buy", Lots: models.DecimalFromInt(60), LotSize: models.DecimalFromInt(10), LastPrice: models.DecimalFromInt(285)})
+	if got := rules(err); len(got) != 2 || got[0] != RuleLots || got[1] != RuleOrderValue {
+		t.Fatalf("Expected lots and order value violations, got %v", got)
+	}
+	if !strings.Contains(err.Error(), "171000.00") {
+		t.Errorf("Expected the order value in the reason, got %q", err)
+	}
+
+	// Per-symbol limit replaces the default
+	if err := e.Check(Order{Ticker: "GAZP", Side: "Buy", Lots: models.DecimalFromInt(60), LotSize: models.DecimalFromInt(10), LastPrice: models.DecimalFromInt(160)}); err != nil {
+		t.Errorf("Expected GAZP to use its own lot limit, got %v", err)
+	}
+
+	// A limit order is valued at its price, not the last price
+	if err := e.Check(Order{Ticker: "SBER", Side: "Buy", Lots: models.DecimalFromInt(30), LotSize: models.DecimalFromInt(10), LastPrice: models.DecimalFromInt(400), Prices: []models.Decimal{models.DecimalFromInt(300)}}); err != nil {
+		t.Errorf("Expected 90 000 at the limit price to pass, got %v", err)
+	}
+}
+
+func TestCheck_PositionLimitOnlyGrowsExposure(t *testing.T) {
+	e := NewEngine(models.RiskLimits{MaxPositionLots: 100})
+
+	err := e.Check(Order{Ticker: "SBER", Side: "Buy", Lots: models.DecimalFromInt(20), LotSize: models.DecimalFromInt(10), Position: models.DecimalFromInt(900)})
+	if got := rules(err); len(got) != 1 || got[0] != RulePosition {
+		t.Errorf("Expected a position violation at 110 lots, got %v", got)
+	}
+	// Reducing an oversized position is always allowed
+	if err := e.Check(Order{Ticker: "SBER", Side: "Sell", Lots: models.DecimalFromInt(20), LotSize: models.DecimalFromInt(10), Position: models.DecimalFromInt(1500)}); err != nil {
+		t.Errorf("Expected a reducing order to pass, got %v", err)
+	}
+}
+
+func TestCheck_PriceBand(t *testing.T) {
+	e := NewEngine(models.RiskLimits{PriceBandPercent: 5})
+
+	if err := e.Check(Order{Ticker: "SBER", Side: "Buy", Lots: models.DecimalFromInt(1), LastPrice: models.DecimalFromInt(285), Prices: []models.Decimal{models.DecimalFromInt(290)}}); err != nil {
+		t.Errorf("Expected 1.8%% deviation to pass, got %v", err)
+	}
+	err := e.Check(Order{Ticker: "SBER", Side: "Buy", Lots: models.DecimalFromInt(1), LastPrice: models.DecimalFromInt(285), Prices: []models.Decimal{models.DecimalFromInt(2850)}})
+	if got := rules(err); len(got) != 1 || got[0] != RulePriceBand {
+		t.Errorf("Expected a price band violation for a fat-fingered price, got %v", got)
+	}
+	// Without a last price the band cannot be checked
+	if err := e.Check(Order{Ticker: "SBER", Side: "Buy", Lots: models.DecimalFromInt(1), Prices: []models.Decimal{models.DecimalFromInt(2850)}}); err != nil {
+		t.Errorf("Expected no band check without a last price, got %v", err)
+	}
+}
+
+func TestCheck_AssetParams(t *testing.T) {
+	e := NewEngine(models.RiskLimits{})
+	params := &models.AssetParams{IsTradable: true, Longable: "Available", Shortable: "Not Available"}
+
+	err := e.Check(Order{Ticker: "SBER", Side: "Sell", Lots: models.DecimalFromInt(2), LotSize: models.DecimalFromInt(10), Position: models.DecimalFromInt(10), Params: params})
+	if got := rules(err); len(got) != 1 || got[0] != RuleShortable {
+		t.Errorf("Expected a short selling violation, got %v", got)
+	}
+	if err := e.Check(Order{Ticker: "SBER", Side: "Sell", Lots: models.DecimalFromInt(1), LotSize: models.DecimalFromInt(10), Position: models.DecimalFromInt(10), Params: params}); err != nil {
+		t.Errorf("Expected closing a long to pass, got %v", err)
+	}
+
+	err = e.Check(Order{Ticker: "SBER", Side: "Buy", Lots: models.DecimalFromInt(1), Params: &models.AssetParams{}})
+	if got := rules(err); len(got) == 0 || got[0] != RuleTradable {
+		t.Errorf("Expected a not tradable violation, got %v", got)
+	}
+}
+
+func TestDailyOrders_ResetAtMidnight(t *testing.T) {
+	now := time.Date(2026, 4, 7, 23, 0, 0, 0, time.Local)
+	e := NewEngine(models.RiskLimits{MaxDailyOrders: 2, AllowOverride: true})
+	e.Now = func() time.Time { return now }
+
+	_ = e.Record("acc1")
+	_ = e.Record("acc1")
+	err := e.Check(Order{AccountID: "acc1", Ticker: "SBER", Side: "Buy", Lots: models.DecimalFromInt(1)})
+	var rerr *Error
+	if !errors.As(err, &rerr) || rerr.Violations[0].Rule != RuleDailyOrders || !rerr.Overridable {
+		t.Fatalf("Expected an overridable daily orders violation, got %v", err)
+	}
+	if err := e.Check(Order{AccountID: "acc2", Ticker: "SBER", Side: "Buy", Lots: models.DecimalFromInt(1)}); err != nil {
+		t.Errorf("Expected the count to be per account, got %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if n := e.SentToday("acc1"); n != 0 {
+		t.Errorf("Expected the count to reset on a new day, got %d", n)
+	}
+}
+
+func TestDailyLoss_LocksOpeningOrders(t *testing.T) {
+	now := time.Date(2026, 4, 7, 12, 0, 0, 0, time.Local)
+	e := NewEngine(models.RiskLimits{DailyLossLimit: 10000, AllowOverride: true})
+	e.Now = func() time.Time { return now }
+
+	observe := func(pnl string) bool {
+		t.Helper()
+		locked, err := e.ObservePnL("acc1", models.DecimalOf(pnl))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return locked
+	}
+	if observe("2000") || observe("-5000") || observe("-9999.99") {
+		t.Fatal("Expected no lock before the loss limit")
+	}
+	if !observe("-11000") {
+		t.Fatal("Expected an 11 000 loss to lock trading")
+	}
+	if observe("-20000") {
+		t.Error("Expected the lock to be reported only once")
+	}
+	if reason, ok := e.Locked(); !ok || !strings.Contains(reason, "acc1") || !strings.Contains(reason, "11000.00") {
+		t.Errorf("Expected a lock naming the account and the loss, got %q, %v", reason, ok)
+	}
+
+	err := e.Check(Order{AccountID: "acc2", Ticker: "SBER", Side: "Buy", Lots: models.DecimalFromInt(1), LotSize: models.DecimalFromInt(10)})
+	var rerr *Error
+	if !errors.As(err, &rerr) || rerr.Violations[0].Rule != RuleLocked || rerr.Overridable {
+		t.Fatalf("Expected a non-overridable lock violation on every account, got %v", err)
+	}
+	if err := e.Check(Order{AccountID: "acc1", Ticker: "SBER", Side: "Sell", Lots: models.DecimalFromInt(1), LotSize: models.DecimalFromInt(10), Position: models.DecimalFromInt(50)}); err != nil {
+		t.Errorf("Expected a reducing order to pass while locked, got %v", err)
+	}
+
+	now = now.Add(24 * time.Hour)
+	if _, ok := e.Locked(); ok {
+		t.Error("Expected the lock to reset on a new day")
+	}
+}
+
+func TestRestore_KeepsLockAndCountsForTheDay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "risk-state.json")
+	now := time.Date(2026, 4, 7, 12, 0, 0, 0, time.Local)
+	clock := func() time.Time { return now }
+
+	e := NewEngine(models.RiskLimits{MaxDailyOrders: 5})
+	e.Now = clock
+	if err := e.Restore(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Record("acc1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Lock("kill switch engaged"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second process, such as a CLI run, sees the lock and adds its own orders
+	other := NewEngine(models.RiskLimits{MaxDailyOrders: 5})
+	other.Now = clock
+	if err := other.Restore(path); err != nil {
+		t.Fatal(err)
+	}
+	if reason, ok := other.Locked(); !ok || reason != "kill switch engaged" {
+		t.Errorf("Expected the saved kill switch, got %q, %v", reason, ok)
+	}
+	if err := other.Record("acc1"); err != nil {
+		t.Fatal(err)
+	}
+	if n := e.SentToday("acc1"); n != 2 {
+		t.Errorf("Expected the orders of both processes counted, got %d", n)
+	}
+
+	// The state of an earlier day is ignored
+	now = now.Add(24 * time.Hour)
+	next := NewEngine(models.RiskLimits{})
+	next.Now = clock
+	if err := next.Restore(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := next.Locked(); ok || next.SentToday("acc1") != 0 {
+		t.Errorf("Expected a fresh day, got lock %v and %d orders", ok, next.SentToday("acc1"))
+	}
+}
+
+func TestLock_KeepsFirstReason(t *testing.T) {
+	e := NewEngine(models.RiskLimits{})
+	_ = e.Lock("kill switch")
+	_ = e.Lock("other")
+	if reason, ok := e.Locked(); !ok || reason != "kill switch" {
+		t.Errorf("Expected the first lock reason, got %q, %v", reason, ok)
+	}
+}
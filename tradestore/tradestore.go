@@ -0,0 +1,355 @@
+// Package tradestore keeps the trade history of the accounts in a local append-only file,
+// so months of trades can be browsed without fetching them from the broker again. The
+// file also records which periods were fetched, and only the rest is requested later.
+package tradestore
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"finam-terminal/models"
+)
+
+// Fetcher returns the trades of an account between from and to.
+type Fetcher func(accountID string, from, to time.Time) ([]models.Trade, error)
+
+// Span is a period of time. From is inclusive and To exclusive.
+type Span struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Filter selects trades. Empty fields match every trade.
+type Filter struct {
+	Symbol string    // Ticker or full symbol, case-insensitive
+	Side   string    // "Buy" or "Sell"
+	From   time.Time // Inclusive
+	To     time.Time // Exclusive
+}
+
+// Match reports whether t passes the filter.
+func (f Filter) Match(t models.Trade) bool {
+	if f.Symbol != "" {
+		symbol := strings.ToUpper(f.Symbol)
+		full := strings.ToUpper(t.Symbol)
+		if full != symbol && !strings.HasPrefix(full, symbol+"@") {
+			return false
+		}
+	}
+	if f.Side != "" && !strings.EqualFold(t.Side, f.Side) {
+		return false
+	}
+	if !f.From.IsZero() && t.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !t.Timestamp.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// IsZero reports whether the filter matches every trade.
+func (f Filter) IsZero() bool {
+	return f == Filter{}
+}
+
+// entry is one line of the store file: a trade, or a period fetched in full.
+type entry struct {
+	Account string       `json:"account"`
+	Trade   *tradeRecord `json:"trade,omitempty"`
+	Synced  *Span        `json:"synced,omitempty"`
+}
+
+type tradeRecord struct {
+	ID        string    `json:"id"`
+	Symbol    string    `json:"symbol"`
+	Name      string    `json:"name,omitempty"`
+	Side      string    `json:"side"`
+	Price     string    `json:"price"`
+	Quantity  string    `json:"quantity"`
+	Total     string    `json:"total"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// account is the history of one account: its trades oldest first and the merged
+// periods fetched in full.
+type account struct {
+	trades []models.Trade
+	seen   map[string]bool
+	synced []Span
+}
+
+// Store keeps the trades of every account and appends new ones to its file. It is safe
+// for concurrent use.
+type Store struct {
+	mu       sync.Mutex
+	path     string
+	accounts map[string]*account
+
+	// Now returns the current time; fetched periods never reach past it.
+	Now func() time.Time
+}
+
+// NewStore returns a store appending to path. An empty path keeps the trades in memory only.
+func NewStore(path string) *Store {
+	return &Store{
+		path:     path,
+		accounts: make(map[string]*account),
+		Now:      time.Now,
+	}
+}
+
+// Load reads the store file. A missing file is not an error, and a line cut short by a
+// crash is skipped.
+func (s *Store) Load() error {
+	if s.path == "" {
+		return nil
+	}
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read trade store: %w", err)
+	}
+	defer f.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var e entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.Account == "" {
+			log.Printf("[WARN] Skipping line %d of trade store %s", line, s.path)
+			continue
+		}
+		acc := s.account(e.Account)
+		if e.Trade != nil {
+			acc.add(e.Trade.trade())
+		}
+		if e.Synced != nil {
+			acc.markSynced(*e.Synced)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read trade store: %w", err)
+	}
+	for _, acc := range s.accounts {
+		acc.sort()
+	}
+	return nil
+}
+
+// Add stores the trades of accountID that are not known yet and returns how many were new.
+func (s *Store) Add(accountID string, trades []models.Trade) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acc := s.account(accountID)
+	var entries []entry
+	for _, t := range trades {
+		if !acc.add(t) {
+			continue
+		}
+		rec := recordOf(t)
+		entries = append(entries, entry{Account: accountID, Trade: &rec})
+	}
+	acc.sort()
+	return len(entries), s.append(entries)
+}
+
+// Missing returns the parts of the period between from and to that were never fetched
+// for accountID, oldest first. The period is cut at the current time.
+func (s *Store) Missing(accountID string, from, to time.Time) []Span {
+	if now := s.Now(); to.After(now) {
+		to = now
+	}
+	if !from.Before(to) {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var gaps []Span
+	cursor := from
+	for _, sp := range s.account(accountID).synced {
+		if !sp.To.After(cursor) {
+			continue
+		}
+		if !sp.From.Before(to) {
+			break
+		}
+		if sp.From.After(cursor) {
+			gaps = append(gaps, Span{From: cursor, To: sp.From})
+		}
+		cursor = sp.To
+		if !cursor.Before(to) {
+			return gaps
+		}
+	}
+	return append(gaps, Span{From: cursor, To: to})
+}
+
+// Sync fetches the parts of the period between from and to that are missing for
+// accountID, stores their trades and records them as fetched. It returns the number of
+// new trades.
+func (s *Store) Sync(accountID string, from, to time.Time, fetch Fetcher) (int, error) {
+	added := 0
+	for _, gap := range s.Missing(accountID, from, to) {
+		trades, err := fetch(accountID, gap.From, gap.To)
+		if err != nil {
+			return added, err
+		}
+		n, err := s.Add(accountID, trades)
+		added += n
+		if err != nil {
+			return added, err
+		}
+		if err := s.markSynced(accountID, gap); err != nil {
+			return added, err
+		}
+	}
+	return added, nil
+}
+
+// Trades returns the stored trades of accountID that pass f, oldest first.
+func (s *Store) Trades(accountID string, f Filter) []models.Trade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []models.Trade
+	for _, t := range s.account(accountID).trades {
+		if f.Match(t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// markSynced records sp as fetched in full for accountID.
+func (s *Store) markSynced(accountID string, sp Span) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.account(accountID).markSynced(sp)
+	return s.append([]entry{{Account: accountID, Synced: &sp}})
+}
+
+// account returns the history of id, creating it. Called with s.mu held.
+func (s *Store) account(id string) *account {
+	acc := s.accounts[id]
+	if acc == nil {
+		acc = &account{seen: make(map[string]bool)}
+		s.accounts[id] = acc
+	}
+	return acc
+}
+
+// append writes entries to the end of the store file. Called with s.mu held.
+func (s *Store) append(entries []entry) error {
+	if s.path == "" || len(entries) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to save trades: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to save trades: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	// Start on a new line after a line cut short by a crash
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			w.WriteByte('\n')
+		}
+	}
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to save trades: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to save trades: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to save trades: %w", err)
+	}
+	return nil
+}
+
+// add appends t unless its ID is known. It reports whether t was added.
+func (a *account) add(t models.Trade) bool {
+	if t.ID != "" {
+		if a.seen[t.ID] {
+			return false
+		}
+		a.seen[t.ID] = true
+	}
+	a.trades = append(a.trades, t)
+	return true
+}
+
+func (a *account) sort() {
+	sort.SliceStable(a.trades, func(i, j int) bool { return a.trades[i].Timestamp.Before(a.trades[j].Timestamp) })
+}
+
+// markSynced merges sp into the fetched periods, keeping them sorted and disjoint.
+func (a *account) markSynced(sp Span) {
+	if !sp.From.Before(sp.To) {
+		return
+	}
+	spans := append(a.synced, sp)
+	sort.Slice(spans, func(i, j int) bool { return spans[i].From.Before(spans[j].From) })
+	merged := spans[:1]
+	for _, next := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if next.From.After(last.To) {
+			merged = append(merged, next)
+			continue
+		}
+		if next.To.After(last.To) {
+			last.To = next.To
+		}
+	}
+	a.synced = merged
+}
+
+func recordOf(t models.Trade) tradeRecord {
+	return tradeRecord{
+		ID:        t.ID,
+		Symbol:    t.Symbol,
+		Name:      t.Name,
+		Side:      t.Side,
+		Price:     t.Price.String(),
+		Quantity:  t.Quantity.String(),
+		Total:     t.Total.String(),
+		Timestamp: t.Timestamp,
+	}
+}
+
+func (r tradeRecord) trade() models.Trade {
+	return models.Trade{
+		ID:        r.ID,
+		Symbol:    r.Symbol,
+		Name:      r.Name,
+		Side:      r.Side,
+		Price:     models.DecimalOf(r.Price),
+		Quantity:  models.DecimalOf(r.Quantity),
+		Total:     models.DecimalOf(r.Total),
+		Timestamp: r.Timestamp.Local(),
+	}
+}
@@ -0,0 +1,134 @@
+package tradestore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"finam-terminal/models"
+)
+
+var day0 = time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)
+
+func day(n int) time.Time { return day0.AddDate(0, 0, n) }
+
+func newTestStore(path string) *Store {
+	s := NewStore(path)
+	s.Now = func() time.Time { return day(90) }
+	return s
+}
+
+func TestStore_SyncFetchesOnlyMissingPeriods(t *testing.T) {
+	s := newTestStore("")
+	var fetched []Span
+	fetch := func(accountID string, from, to time.Time) ([]models.Trade, error) {
+		fetched = append(fetched, Span{from, to})
+		return []models.Trade{{ID: "T" + from.Format("0102"), Symbol: "SBER@MISX", Side: "Buy", Timestamp: from}}, nil
+	}
+
+	if n, err := s.Sync("acc1", day(60), day(100), fetch); n != 1 || err != nil {
+		t.Fatalf("Expected one new trade, got %d %v", n, err)
+	}
+	if len(fetched) != 1 || !fetched[0].To.Equal(day(90)) {
+		t.Fatalf("Expected the period cut at now, got %v", fetched)
+	}
+
+	fetched = nil
+	s.Sync("acc1", day(0), day(90), fetch)
+	if len(fetched) != 1 || !fetched[0].From.Equal(day(0)) || !fetched[0].To.Equal(day(60)) {
+		t.Errorf("Expected only the older months fetched, got %v", fetched)
+	}
+
+	fetched = nil
+	s.Now = func() time.Time { return day(91) }
+	s.Sync("acc1", day(0), day(91), fetch)
+	if len(fetched) != 1 || !fetched[0].From.Equal(day(90)) {
+		t.Errorf("Expected only the last day fetched, got %v", fetched)
+	}
+
+	if trades := s.Trades("acc1", Filter{}); len(trades) != 3 || trades[0].ID != "T0101" {
+		t.Errorf("Expected three trades oldest first, got %+v", trades)
+	}
+}
+
+func TestStore_AddDeduplicates(t *testing.T) {
+	s := newTestStore("")
+	trades := []models.Trade{{ID: "T1", Timestamp: day(2)}, {ID: "T2", Timestamp: day(1)}}
+	if n, _ := s.Add("acc1", trades); n != 2 {
+		t.Fatalf("Expected two new trades, got %d", n)
+	}
+	if n, _ := s.Add("acc1", append(trades, models.Trade{ID: "T3", Timestamp: day(3)})); n != 1 {
+		t.Errorf("Expected only T3 new, got %d", n)
+	}
+	if n, _ := s.Add("acc2", trades); n != 2 {
+		t.Errorf("Expected accounts to be kept apart, got %d", n)
+	}
+	if got := s.Trades("acc1", Filter{}); got[0].ID != "T2" || got[2].ID != "T3" {
+		t.Errorf("Expected trades sorted by time, got %+v", got)
+	}
+}
+
+func TestStore_Filter(t *testing.T) {
+	s := newTestStore("")
+	s.Add("acc1", []models.Trade{
+		{ID: "T1", Symbol: "SBER@MISX", Side: "Buy", Timestamp: day(1)},
+		{ID: "T2", Symbol: "SBERP@MISX", Side: "Sell", Timestamp: day(2)},
+		{ID: "T3", Symbol: "SBER@MISX", Side: "Sell", Timestamp: day(3)},
+	})
+
+	tests := []struct {
+		filter Filter
+		want   int
+	}{
+		{Filter{Symbol: "sber"}, 2},
+		{Filter{Side: "Sell"}, 2},
+		{Filter{Symbol: "SBER", Side: "Sell"}, 1},
+		{Filter{From: day(2), To: day(3)}, 1},
+		{Filter{}, 3},
+	}
+	for _, tt := range tests {
+		if got := s.Trades("acc1", tt.filter); len(got) != tt.want {
+			t.Errorf("%+v: expected %d trades, got %d", tt.filter, tt.want, len(got))
+		}
+	}
+}
+
+func TestStore_PersistsAndSkipsTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trades.jsonl")
+	s := newTestStore(path)
+	s.Sync("acc1", day(30), day(90), func(string, time.Time, time.Time) ([]models.Trade, error) {
+		return []models.Trade{{ID: "T1", Symbol: "SBER@MISX", Price: models.DecimalOf("300.5"), Timestamp: day(40)}}, nil
+	})
+
+	// A crash in the middle of a write leaves half a line behind
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"account":"acc1","trade":{"id":"T2"`)
+	f.Close()
+
+	restarted := newTestStore(path)
+	if err := restarted.Load(); err != nil {
+		t.Fatal(err)
+	}
+	trades := restarted.Trades("acc1", Filter{})
+	if len(trades) != 1 || trades[0].Price.String() != "300.5" || !trades[0].Timestamp.Equal(day(40)) {
+		t.Errorf("Expected T1 after a restart, got %+v", trades)
+	}
+	restarted.Add("acc1", []models.Trade{{ID: "T3", Timestamp: day(50)}})
+
+	again := newTestStore(path)
+	again.Load()
+	if trades := again.Trades("acc1", Filter{}); len(trades) != 2 || trades[1].ID != "T3" {
+		t.Errorf("Expected a trade added after the torn line to survive, got %+v", trades)
+	}
+	if gaps := restarted.Missing("acc1", day(0), day(90)); len(gaps) != 1 || !gaps[0].To.Equal(day(30)) {
+		t.Errorf("Expected the fetched period to be remembered, got %v", gaps)
+	}
+}
+
+func TestStore_LoadMissingFile(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "none.jsonl"))
+	if err := s.Load(); err != nil {
+		t.Errorf("Expected no error for a missing file, got %v", err)
+	}
+}
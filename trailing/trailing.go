@@ -0,0 +1,320 @@
+// Package trailing implements client-side trailing stops. The broker has no trailing
+// orders, so each trailing stop is a plain stop order that the terminal moves after the
+// market: the manager tracks the best price seen since the stop was set and reports when the
+// stop order should follow it. Stops are saved to a JSON file so they survive a restart.
+package trailing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"finam-terminal/models"
+
+	"finam-terminal/atomicfile"
+)
+
+// Stop is one trailing stop. A Sell stop protects a long position and trails the highest
+// price; a Buy stop protects a short position and trails the lowest price.
+type Stop struct {
+	ID        string         `json:"id"`
+	AccountID string         `json:"account_id"`
+	Symbol    string         `json:"symbol"`
+	Side      string         `json:"side"`     // Side of the stop order: "Sell" or "Buy"
+	Quantity  models.Decimal `json:"quantity"` // In lots, as sent to PlaceOrder
+	Distance  models.Decimal `json:"distance,omitzero"`
+	Percent   models.Decimal `json:"percent,omitzero"`
+	Step      models.Decimal `json:"step,omitzero"` // Smallest stop move worth a replace; 0 moves on every improvement
+	Tick      models.Decimal `json:"tick,omitzero"` // Price step of the instrument; 0 when unknown
+	Extreme   models.Decimal `json:"extreme"`       // Best price seen since the stop was set
+	StopPrice models.Decimal `json:"stop_price"`    // Price of the working stop order
+	OrderID   string         `json:"order_id"`
+	Created   time.Time      `json:"created"`
+}
+
+// Trail formats the trail distance as it is entered: "5" or "1.5%".
+func (s Stop) Trail() string {
+	if s.Percent.Sign() > 0 {
+		return s.Percent.Trim().String() + "%"
+	}
+	return s.Distance.Trim().String()
+}
+
+// StopFor returns the stop price that trails extreme by the stop's distance or percent,
+// snapped to the tick toward the market so the exchange accepts it and it never trails
+// further than asked.
+func (s Stop) StopFor(extreme models.Decimal) models.Decimal {
+	trail := s.Distance
+	if s.Percent.Sign() > 0 {
+		trail = extreme.Mul(s.Percent).Div(models.DecimalFromInt(100), pricePlaces)
+	}
+	if s.Side == "Buy" {
+		below, _ := extreme.Add(trail).Snap(s.Tick)
+		return below.Trim()
+	}
+	_, above := extreme.Sub(trail).Snap(s.Tick)
+	return above.Trim()
+}
+
+// better reports whether price is a better extreme than the current one.
+func (s Stop) better(price models.Decimal) bool {
+	if s.Side == "Buy" {
+		return price.Cmp(s.Extreme) < 0
+	}
+	return price.Cmp(s.Extreme) > 0
+}
+
+// ParseTrail parses a trail entered as an absolute distance ("5") or a percent of the
+// price ("1.5%").
+func ParseTrail(text string) (distance, percent models.Decimal, err error) {
+	text = strings.ReplaceAll(strings.TrimSpace(text), ",", ".")
+	if p, ok := strings.CutSuffix(text, "%"); ok {
+		v, err := models.ParseDecimal(strings.TrimSpace(p))
+		if err != nil || v.Sign() <= 0 || v.Cmp(models.DecimalFromInt(100)) >= 0 {
+			return models.Decimal{}, models.Decimal{}, fmt.Errorf("invalid trail percent %q", text)
+		}
+		return models.Decimal{}, v, nil
+	}
+	v, err := models.ParseDecimal(text)
+	if err != nil || v.Sign() <= 0 {
+		return models.Decimal{}, models.Decimal{}, fmt.Errorf("invalid trail distance %q", text)
+	}
+	return v, models.Decimal{}, nil
+}
+
+// Move asks for the stop order of Stop to be replaced by one at Price.
+type Move struct {
+	Stop  Stop
+	Price models.Decimal
+}
+
+const (
+	// retryDelay is how long a stop waits after a failed move before it is moved again.
+	retryDelay = 10 * time.Second
+	// maxMoveFailures is how many moves in a row may fail before the stop stops trailing.
+	maxMoveFailures = 3
+	// pricePlaces is the number of decimals kept in a percent trail before it is snapped.
+	pricePlaces = 8
+)
+
+// Manager keeps the trailing stops and their file. It is safe for concurrent use.
+type Manager struct {
+	// Now returns the current time; failed moves are retried after retryDelay.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	path     string
+	stops    map[string]*Stop
+	moving   map[string]bool      // stops with a replace in flight
+	retry    map[string]time.Time // stops whose last move failed -> earliest retry
+	failures map[string]int       // stops -> moves failed in a row
+	next     int
+}
+
+// NewManager returns a manager saving its stops to path. An empty path keeps the stops in
+// memory only.
+func NewManager(path string) *Manager {
+	return &Manager{
+		Now:      time.Now,
+		path:     path,
+		stops:    make(map[string]*Stop),
+		moving:   make(map[string]bool),
+		retry:    make(map[string]time.Time),
+		failures: make(map[string]int),
+	}
+}
+
+// Load reads the stops saved in the manager's file. A missing file is not an error.
+func (m *Manager) Load() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read trailing stops: %w", err)
+	}
+	var stops []Stop
+	if err := json.Unmarshal(data, &stops); err != nil {
+		return fmt.Errorf("failed to parse trailing stops %s: %w", m.path, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range stops {
+		s := stops[i]
+		m.stops[s.ID] = &s
+		if n, err := strconv.Atoi(strings.TrimPrefix(s.ID, "T")); err == nil && n > m.next {
+			m.next = n
+		}
+	}
+	return nil
+}
+
+// Add registers a trailing stop whose stop order is already working and returns it with its
+// ID assigned.
+func (m *Manager) Add(s Stop) (Stop, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.next++
+	s.ID = fmt.Sprintf("T%d", m.next)
+	if s.Created.IsZero() {
+		s.Created = m.Now()
+	}
+	m.stops[s.ID] = &s
+	return s, m.save()
+}
+
+// ErrMoving is returned by Remove while the stop order is being replaced.
+var ErrMoving = errors.New("trailing stop is being moved")
+
+// Remove forgets the trailing stop id. Its stop order is left alone. A stop whose order is
+// being replaced is kept and ErrMoving is returned, since its working order is not known yet.
+func (m *Manager) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.stops[id]; !ok {
+		return nil
+	}
+	if m.moving[id] {
+		return ErrMoving
+	}
+	delete(m.stops, id)
+	delete(m.retry, id)
+	delete(m.failures, id)
+	return m.save()
+}
+
+// Stops returns the trailing stops of accountID, or of every account when accountID is
+// empty, ordered by creation.
+func (m *Manager) Stops(accountID string) []Stop {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Stop
+	for _, s := range m.stops {
+		if accountID == "" || s.AccountID == accountID {
+			out = append(out, *s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Created.Before(out[j].Created) })
+	return out
+}
+
+// ByOrderID returns the trailing stop whose working stop order is orderID.
+func (m *Manager) ByOrderID(orderID string) (Stop, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.stops {
+		if s.OrderID == orderID {
+			return *s, true
+		}
+	}
+	return Stop{}, false
+}
+
+// Moving reports whether the stop order of id is being replaced.
+func (m *Manager) Moving(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.moving[id]
+}
+
+// Observe feeds the last price of symbol to its trailing stops and returns the stops whose
+// order should move. A returned stop is marked as moving until Moved or MoveFailed is called.
+// A new extreme is saved with the next move, so a restart may resume from an older one.
+func (m *Manager) Observe(symbol string, price models.Decimal) []Move {
+	if price.Sign() <= 0 {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.Now()
+	var moves []Move
+	for _, s := range m.stops {
+		if s.Symbol != symbol {
+			continue
+		}
+		if s.better(price) {
+			s.Extreme = price
+		}
+		if m.moving[s.ID] || now.Before(m.retry[s.ID]) {
+			continue
+		}
+		target := s.StopFor(s.Extreme)
+		gain := target.Sub(s.StopPrice)
+		if s.Side == "Buy" {
+			gain = gain.Neg()
+		}
+		if gain.Sign() <= 0 || gain.Cmp(s.Step) < 0 {
+			continue
+		}
+		m.moving[s.ID] = true
+		moves = append(moves, Move{Stop: *s, Price: target})
+	}
+	return moves
+}
+
+// Moved records that the stop order of id was replaced by orderID at price.
+func (m *Manager) Moved(id, orderID string, price models.Decimal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.moving, id)
+	delete(m.retry, id)
+	delete(m.failures, id)
+	s, ok := m.stops[id]
+	if !ok {
+		return nil
+	}
+	s.OrderID = orderID
+	s.StopPrice = price
+	return m.save()
+}
+
+// MoveFailed clears the moving mark of id after a failed replace. The move is retried by
+// the first price observed after retryDelay. After maxMoveFailures failures in a row the
+// stop is forgotten, leaving its stop order working where it is, and dropped is true.
+func (m *Manager) MoveFailed(id string) (dropped bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.moving, id)
+	if _, ok := m.stops[id]; !ok {
+		return false, nil
+	}
+	m.failures[id]++
+	if m.failures[id] < maxMoveFailures {
+		m.retry[id] = m.Now().Add(retryDelay)
+		return false, nil
+	}
+	delete(m.stops, id)
+	delete(m.retry, id)
+	delete(m.failures, id)
+	return true, m.save()
+}
+
+// save writes the stops to the manager's file. Called with m.mu held.
+func (m *Manager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	stops := make([]Stop, 0, len(m.stops))
+	for _, s := range m.stops {
+		stops = append(stops, *s)
+	}
+	sort.Slice(stops, func(i, j int) bool { return stops[i].Created.Before(stops[j].Created) })
+	data, err := json.MarshalIndent(stops, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.Write(m.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save trailing stops: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package trailing
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"finam-terminal/models"
+)
+
+func TestParseTrail(t *testing.T) {
+	tests := []struct {
+		input    string
+		distance string
+		percent  string
+		wantErr  bool
+	}{
+		{"5", "5", "0", false},
+		{"2,5", "2.5", "0", false},
+		{"1.5%", "0", "1.5", false},
+		{" 3 % ", "0", "3", false},
+		{"0", "0", "0", true},
+		{"100%", "0", "0", true},
+		{"abc", "0", "0", true},
+	}
+	for _, tt := range tests {
+		d, p, err := ParseTrail(tt.input)
+		if (err != nil) != tt.wantErr || !d.Equal(models.DecimalOf(tt.distance)) || !p.Equal(models.DecimalOf(tt.percent)) {
+			t.Errorf("ParseTrail(%q) = %v, %v, %v", tt.input, d, p, err)
+		}
+	}
+}
+
+func TestObserve_SellStopFollowsHighs(t *testing.T) {
+	m := NewManager("")
+	s, _ := m.Add(Stop{Symbol: "SBER", Side: "Sell", Distance: models.DecimalOf("5"), Step: models.DecimalOf("1"), Extreme: models.DecimalOf("300"), StopPrice: models.DecimalOf("295"), OrderID: "o1"})
+
+	// Falling prices never move a sell stop
+	if moves := m.Observe("SBER", models.DecimalOf("298")); len(moves) != 0 {
+		t.Fatalf("Expected no move on a lower price, got %v", moves)
+	}
+	// A gain below the step only raises the high-water mark
+	if moves := m.Observe("SBER", models.DecimalOf("300.5")); len(moves) != 0 {
+		t.Fatalf("Expected no move below the step, got %v", moves)
+	}
+
+	moves := m.Observe("SBER", models.DecimalOf("302"))
+	if len(moves) != 1 || !moves[0].Price.Equal(models.DecimalOf("297")) {
+		t.Fatalf("Expected a move to 297, got %v", moves)
+	}
+
+	// No second move while the first is in flight
+	if moves := m.Observe("SBER", models.DecimalOf("310")); len(moves) != 0 {
+		t.Fatalf("Expected no move while moving, got %v", moves)
+	}
+	if err := m.Remove(s.ID); !errors.Is(err, ErrMoving) {
+		t.Errorf("Expected ErrMoving, got %v", err)
+	}
+
+	if err := m.Moved(s.ID, "o2", models.DecimalOf("297")); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := m.ByOrderID("o2"); !ok || !got.StopPrice.Equal(models.DecimalOf("297")) || !got.Extreme.Equal(models.DecimalOf("310")) {
+		t.Errorf("Expected the stop on o2 at 297 with extreme 310, got %+v", got)
+	}
+
+	// The high reached while moving is used by the next price
+	if moves := m.Observe("SBER", models.DecimalOf("305")); len(moves) != 1 || !moves[0].Price.Equal(models.DecimalOf("305")) {
+		t.Errorf("Expected a move to 305 from the 310 high, got %v", moves)
+	}
+}
+
+func TestObserve_BuyStopPercent(t *testing.T) {
+	m := NewManager("")
+	m.Add(Stop{Symbol: "GAZP", Side: "Buy", Percent: models.DecimalOf("10"), Extreme: models.DecimalOf("200"), StopPrice: models.DecimalOf("220"), OrderID: "o1"})
+
+	if moves := m.Observe("GAZP", models.DecimalOf("210")); len(moves) != 0 {
+		t.Fatalf("Expected no move on a higher price, got %v", moves)
+	}
+	moves := m.Observe("GAZP", models.DecimalOf("150"))
+	if len(moves) != 1 || !moves[0].Price.Equal(models.DecimalOf("165")) {
+		t.Fatalf("Expected a move to 165, got %v", moves)
+	}
+	now := time.Now()
+	m.Now = func() time.Time { return now }
+	m.MoveFailed(moves[0].Stop.ID)
+
+	// A failed move waits before it is retried
+	if moves := m.Observe("GAZP", models.DecimalOf("149")); len(moves) != 0 {
+		t.Fatalf("Expected no retry right after the failed move, got %v", moves)
+	}
+	now = now.Add(retryDelay)
+	if moves := m.Observe("GAZP", models.DecimalOf("151")); len(moves) != 1 || !moves[0].Price.Equal(models.DecimalOf("163.9")) {
+		t.Errorf("Expected a retry to 163.9 from the 149 low, got %v", moves)
+	}
+}
+
+func TestStopFor_SnapsTowardTheMarket(t *testing.T) {
+	tick := models.DecimalOf("0.05")
+	sell := Stop{Side: "Sell", Percent: models.DecimalOf("1.5"), Tick: tick}
+	// 301.37 less 1.5% is 296.84945, raised to the tick below the market
+	if got := sell.StopFor(models.DecimalOf("301.37")); got.String() != "296.85" {
+		t.Errorf("Expected a sell stop of 296.85, got %s", got)
+	}
+	buy := Stop{Side: "Buy", Distance: models.DecimalOf("2.33"), Tick: tick}
+	if got := buy.StopFor(models.DecimalOf("150")); got.String() != "152.3" {
+		t.Errorf("Expected a buy stop of 152.3, got %s", got)
+	}
+}
+
+func TestMoveFailed_GivesUpAfterRepeatedFailures(t *testing.T) {
+	m := NewManager("")
+	now := time.Now()
+	m.Now = func() time.Time { return now }
+	s, _ := m.Add(Stop{Symbol: "SBER", Side: "Sell", Distance: models.DecimalOf("5"), Extreme: models.DecimalOf("300"), StopPrice: models.DecimalOf("295"), OrderID: "o1"})
+
+	price := models.DecimalOf("301")
+	for i := 1; i <= maxMoveFailures; i++ {
+		if moves := m.Observe("SBER", price); len(moves) != 1 {
+			t.Fatalf("Expected move %d, got %v", i, moves)
+		}
+		dropped, err := m.MoveFailed(s.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dropped != (i == maxMoveFailures) {
+			t.Fatalf("Failure %d: dropped = %v", i, dropped)
+		}
+		now = now.Add(retryDelay)
+		price = price.Add(models.DecimalFromInt(1))
+	}
+	if len(m.Stops("")) != 0 {
+		t.Errorf("Expected the stop to be forgotten, got %+v", m.Stops(""))
+	}
+}
+
+func TestManager_PersistsStops(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "trailing-stops.json")
+
+	m := NewManager(path)
+	s, err := m.Add(Stop{AccountID: "acc1", Symbol: "SBER", Side: "Sell", Distance: models.DecimalOf("5"), Extreme: models.DecimalOf("300"), StopPrice: models.DecimalOf("295"), OrderID: "o1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Observe("SBER", models.DecimalOf("310"))
+	if err := m.Moved(s.ID, "o2", models.DecimalOf("305")); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := NewManager(path)
+	if err := restarted.Load(); err != nil {
+		t.Fatal(err)
+	}
+	stops := restarted.Stops("acc1")
+	if len(stops) != 1 || stops[0].OrderID != "o2" || !stops[0].StopPrice.Equal(models.DecimalOf("305")) || !stops[0].Extreme.Equal(models.DecimalOf("310")) {
+		t.Fatalf("Expected the moved stop after a restart, got %+v", stops)
+	}
+
+	// New IDs continue after the loaded ones
+	next, _ := restarted.Add(Stop{AccountID: "acc1", Symbol: "GAZP", Side: "Sell", Distance: models.DecimalOf("1")})
+	if next.ID == s.ID {
+		t.Errorf("Expected a new ID, got %s again", next.ID)
+	}
+
+	if err := restarted.Remove(s.ID); err != nil {
+		t.Fatal(err)
+	}
+	again := NewManager(path)
+	if err := again.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Stops("")) != 1 {
+		t.Errorf("Expected one stop after removal, got %d", len(again.Stops("")))
+	}
+}
+
+func TestManager_LoadMissingFile(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "none.json"))
+	if err := m.Load(); err != nil {
+		t.Errorf("Expected no error for a missing file, got %v", err)
+	}
+}
@@ -22,7 +22,7 @@ func TestAccountIntegration_SingleAccount(t *testing.T) {
 		{ID: "ACC_SINGLE", Equity: "50000.00"},
 	}
 	app := createTestAppWithAccounts(accounts)
-	app.positions["ACC_SINGLE"] = []models.Position{{DailyPnL: "1234.56"}}
+	app.positions["ACC_SINGLE"] = []models.Position{{DailyPnL: models.DecimalOf("1234.56")}}
 	app.selectedIdx = 0
 	updateAccountList(app)
 
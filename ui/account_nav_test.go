@@ -86,3 +86,15 @@ func TestAccountNavigation_BoundsCheck(t *testing.T) {
 		t.Errorf("Expected row 0, got %d", row)
 	}
 }
+
+func TestSelectAccount(t *testing.T) {
+	accounts := []models.AccountInfo{{ID: "ACC1"}, {ID: "ACC2"}}
+	app := NewApp(&mockClient{}, accounts)
+
+	if !app.SelectAccount(1) || app.selectedIdx != 1 {
+		t.Errorf("Expected account 1 selected, got %d", app.selectedIdx)
+	}
+	if app.SelectAccount(2) || app.selectedIdx != 1 {
+		t.Errorf("Expected out-of-range index to keep selection, got %d", app.selectedIdx)
+	}
+}
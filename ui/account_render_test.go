@@ -51,8 +51,8 @@ func TestUpdateAccountList_PnLColors(t *testing.T) {
 		{ID: "ACC3", Equity: "3000.00"},
 	}
 	app := createTestAppWithAccounts(accounts)
-	app.positions["ACC1"] = []models.Position{{DailyPnL: "500.00"}}
-	app.positions["ACC2"] = []models.Position{{DailyPnL: "-300.00"}}
+	app.positions["ACC1"] = []models.Position{{DailyPnL: models.DecimalOf("500.00")}}
+	app.positions["ACC2"] = []models.Position{{DailyPnL: models.DecimalOf("-300.00")}}
 	// ACC3 has no positions → zero PnL
 	updateAccountList(app)
 
@@ -81,7 +81,7 @@ func TestUpdateAccountList_PositivePnLHasPlusSign(t *testing.T) {
 		{ID: "ACC1", Equity: "1000.00"},
 	}
 	app := createTestAppWithAccounts(accounts)
-	app.positions["ACC1"] = []models.Position{{DailyPnL: "500.00"}}
+	app.positions["ACC1"] = []models.Position{{DailyPnL: models.DecimalOf("500.00")}}
 	updateAccountList(app)
 
 	dataText := app.portfolioView.AccountTable.GetCell(1, 0).Text
@@ -95,7 +95,7 @@ func TestUpdateAccountList_NegativePnLHasMinusSign(t *testing.T) {
 		{ID: "ACC1", Equity: "1000.00"},
 	}
 	app := createTestAppWithAccounts(accounts)
-	app.positions["ACC1"] = []models.Position{{DailyPnL: "-300.50"}}
+	app.positions["ACC1"] = []models.Position{{DailyPnL: models.DecimalOf("-300.50")}}
 	updateAccountList(app)
 
 	dataText := app.portfolioView.AccountTable.GetCell(1, 0).Text
@@ -0,0 +1,272 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"finam-terminal/alerts"
+	"finam-terminal/models"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// alertOptions are the alert types offered by the alert prompt.
+var alertOptions = []struct {
+	label string
+	kind  string
+	above bool
+}{
+	{"Price above", alerts.KindPrice, true},
+	{"Price below", alerts.KindPrice, false},
+	{"Change above, %", alerts.KindChange, true},
+	{"Change below, %", alerts.KindChange, false},
+	{"Volume spike, x avg", alerts.KindVolume, true},
+	{"Spread above", alerts.KindSpread, true},
+}
+
+// SetAlertStore loads the alerts and the alert log saved in path and keeps saving them
+// there. Without a store, alerts last until the terminal exits.
+func (a *App) SetAlertStore(path string) error {
+	m := alerts.NewManager(path)
+	if err := m.Load(); err != nil {
+		return err
+	}
+	a.alerts = m
+	if n := len(m.Alerts()); n > 0 {
+		log.Printf("[INFO] Watching %d alerts from %s", n, path)
+	}
+	return nil
+}
+
+// ShowAlertPrompt asks for a new alert on symbol. An empty symbol lets the user type one.
+func (a *App) ShowAlertPrompt(symbol string) {
+	returnFocus := a.app.GetFocus()
+
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle(" New Alert ").SetTitleAlign(tview.AlignCenter)
+	form.SetBackgroundColor(tcell.ColorBlack)
+	form.SetButtonBackgroundColor(tcell.ColorDarkBlue).
+		SetButtonTextColor(tcell.ColorWhite).
+		SetLabelColor(tcell.ColorYellow).
+		SetFieldBackgroundColor(tcell.ColorWhite).
+		SetFieldTextColor(tcell.ColorBlack)
+
+	labels := make([]string, len(alertOptions))
+	for i, o := range alertOptions {
+		labels[i] = o.label
+	}
+	a.dataMutex.RLock()
+	accountID := ""
+	if a.selectedIdx >= 0 && a.selectedIdx < len(a.accounts) {
+		accountID = a.accounts[a.selectedIdx].ID
+	}
+	a.dataMutex.RUnlock()
+
+	value := ""
+	if symbol != "" {
+		if last := a.lastPrice(accountID, symbol); last.Sign() > 0 {
+			value = last.String()
+		}
+	}
+
+	form.AddInputField("Instrument:", symbol, 20, nil, nil)
+	form.AddDropDown("Type:", labels, 0, nil)
+	form.AddInputField("Value:", value, 20, tview.InputFieldFloat, nil)
+
+	closePrompt := func() {
+		a.pages.RemovePage("price_alert")
+		a.app.SetFocus(returnFocus)
+	}
+	form.AddButton("Set", func() {
+		idx, _ := form.GetFormItemByLabel("Type:").(*tview.DropDown).GetCurrentOption()
+		text := form.GetFormItemByLabel("Value:").(*tview.InputField).GetText()
+		v, err := parseFloat(text)
+		if err != nil {
+			a.SetStatus("Invalid alert value", StatusError)
+			return
+		}
+		instrument := strings.ToUpper(strings.TrimSpace(form.GetFormItemByLabel("Instrument:").(*tview.InputField).GetText()))
+		if err := a.addAlert(instrument, idx, v); err != nil {
+			a.SetStatus(err.Error(), StatusError)
+			return
+		}
+		closePrompt()
+	})
+	form.AddButton("Cancel", closePrompt)
+	form.SetCancelFunc(closePrompt)
+	if symbol != "" {
+		form.SetFocus(1)
+	}
+
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(form, 11, 1, true).
+			AddItem(nil, 0, 1, false), 46, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	a.pages.AddPage("price_alert", flex, true, true)
+	a.app.SetFocus(form)
+}
+
+// IsAlertPromptOpen returns true if the new alert prompt is currently shown.
+func (a *App) IsAlertPromptOpen() bool {
+	name, _ := a.pages.GetFrontPage()
+	return name == "price_alert"
+}
+
+// addAlert starts watching symbol with the alert type alertOptions[option] at value.
+func (a *App) addAlert(symbol string, option int, value float64) error {
+	if option < 0 || option >= len(alertOptions) {
+		return fmt.Errorf("unknown alert type")
+	}
+	o := alertOptions[option]
+	alert, err := a.alerts.Add(alerts.Alert{Symbol: symbol, Kind: o.kind, Above: o.above, Value: value})
+	if alert.ID == "" {
+		return err
+	}
+	if err != nil {
+		log.Printf("[ERROR] Alert %s on %s will not survive a restart: %v", alert.ID, symbol, err)
+	}
+	log.Printf("[INFO] Alert %s set: %s %s", alert.ID, alert.Symbol, alert)
+	a.SetStatus(fmt.Sprintf("Alert %s set: %s %s", alert.ID, alert.Symbol, alert), StatusSuccess)
+	updateAlertsTable(a)
+	a.updateQuoteSubscription()
+	return nil
+}
+
+// evaluateAlerts checks the alerts against streamed quotes and reports the ones that
+// fired. Must be called on the UI thread.
+func (a *App) evaluateAlerts(batch map[string]models.Quote) {
+	observed := make(map[string]bool)
+	var fired []alerts.Event
+	for _, alert := range a.alerts.Alerts() {
+		if observed[alert.Symbol] {
+			continue
+		}
+		observed[alert.Symbol] = true
+		for _, q := range batch {
+			if !symbolMatches(q.Symbol, alert.Symbol) {
+				continue
+			}
+			events, err := a.alerts.Observe(alert.Symbol, alertSample(q))
+			if err != nil {
+				log.Printf("[ERROR] %v", err)
+			}
+			fired = append(fired, events...)
+			break
+		}
+	}
+	if len(fired) == 0 {
+		return
+	}
+
+	for _, e := range fired {
+		log.Printf("[INFO] Alert %s fired: %s", e.Alert.ID, e)
+	}
+	a.bellPending.Store(true)
+	msg := "Alert: " + fired[len(fired)-1].String()
+	if len(fired) > 1 {
+		msg = fmt.Sprintf("%d alerts fired, latest: %s", len(fired), fired[len(fired)-1])
+	}
+	a.SetStatus(msg, StatusAlert)
+	updateAlertsTable(a)
+	a.updateQuoteSubscription()
+}
+
+// alertSample converts a quote to the market state alerts watch. The close of a live quote
+// is the previous session's close.
+func alertSample(q models.Quote) alerts.Sample {
+	var s alerts.Sample
+	s.Last = q.Last.Float64()
+	s.Bid = q.Bid.Float64()
+	s.Ask = q.Ask.Float64()
+	s.PrevClose = q.Close.Float64()
+	s.Volume = q.Volume.Float64()
+	return s
+}
+
+// selectedAlert returns the active alert selected in the Alerts table. Its rows come
+// before the alert log.
+func (a *App) selectedAlert() (alerts.Alert, bool) {
+	row, _ := a.portfolioView.TabbedView.AlertsTable.GetSelection()
+	active := a.alerts.Alerts()
+	if row < 1 || row > len(active) {
+		return alerts.Alert{}, false
+	}
+	return active[row-1], true
+}
+
+// removeSelectedAlert stops watching the alert selected in the Alerts table.
+func (a *App) removeSelectedAlert() {
+	alert, ok := a.selectedAlert()
+	if !ok {
+		return
+	}
+	if err := a.alerts.Remove(alert.ID); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+	log.Printf("[INFO] Alert %s removed", alert.ID)
+	a.SetStatus(fmt.Sprintf("Alert %s removed", alert.ID), StatusSuccess)
+	updateAlertsTable(a)
+	a.updateQuoteSubscription()
+}
+
+// clearAlertLog forgets the fired alerts.
+func (a *App) clearAlertLog() {
+	if err := a.alerts.ClearLog(); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+	updateAlertsTable(a)
+}
+
+// updateAlertsTable shows the active alerts followed by the alert log, newest first.
+func updateAlertsTable(app *App) {
+	table := app.portfolioView.TabbedView.AlertsTable
+	table.Clear()
+
+	headers := []string{"Time", "Symbol", "Alert", "Status"}
+	headerStyle := tcell.StyleDefault.
+		Background(tcell.ColorDarkBlue).
+		Foreground(tcell.ColorWhite).
+		Bold(true)
+	for i, h := range headers {
+		table.SetCell(0, i, tview.NewTableCell(h).SetStyle(headerStyle).SetExpansion(1))
+	}
+
+	active := app.alerts.Alerts()
+	events := app.alerts.Log()
+	if len(active) == 0 && len(events) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell("No alerts. Press L in the search or the profile to set one").
+			SetTextColor(tcell.ColorGray).SetSelectable(false))
+		return
+	}
+
+	row := 1
+	for _, alert := range active {
+		setAlertRow(table, row, alert.Created.Format("01-02 15:04"), alert.Symbol, alert.String(), "Watching", tcell.ColorLightCyan)
+		row++
+	}
+	for _, e := range events {
+		setAlertRow(table, row, e.Time.Format("01-02 15:04:05"), e.Alert.Symbol, e.Alert.String(), "Fired: "+e.Observation(), tcell.ColorOrange)
+		row++
+	}
+	if sel, _ := table.GetSelection(); sel < 1 || sel >= row {
+		table.Select(1, 0)
+	}
+}
+
+func setAlertRow(table *tview.Table, row int, when, symbol, alert, status string, statusColor tcell.Color) {
+	rowBg := tcell.ColorBlack
+	if (row-1)%2 == 0 {
+		rowBg = tcell.ColorDarkGray
+	}
+	style := tcell.StyleDefault.Background(rowBg)
+	table.SetCell(row, 0, tview.NewTableCell(when).SetStyle(style.Foreground(tcell.ColorWhite)))
+	table.SetCell(row, 1, tview.NewTableCell(symbol).SetStyle(style.Foreground(tcell.ColorLightYellow)))
+	table.SetCell(row, 2, tview.NewTableCell(alert).SetStyle(style.Foreground(tcell.ColorWhite)))
+	table.SetCell(row, 3, tview.NewTableCell(status).SetStyle(style.Foreground(statusColor)))
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"finam-terminal/alerts"
+	"finam-terminal/models"
+)
+
+func TestAlerts_FireFromQuotes(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	if err := app.addAlert("SBER", 0, 310); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := app.addAlert("GAZP", 5, 0.5); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if symbols := app.quoteSymbols(); len(symbols) != 2 {
+		t.Errorf("Expected the alert symbols to be subscribed, got %v", symbols)
+	}
+
+	app.applyQuotes(map[string]models.Quote{"SBER@MISX": {Symbol: "SBER@MISX", Last: models.DecimalOf("309.5")}})
+	if len(app.alerts.Log()) != 0 || app.bellPending.Load() {
+		t.Fatal("Expected no alert below the level")
+	}
+
+	app.applyQuotes(map[string]models.Quote{"SBER@MISX": {Symbol: "SBER@MISX", Last: models.DecimalOf("310.2")}})
+	log := app.alerts.Log()
+	if len(log) != 1 || log[0].Alert.Symbol != "SBER" {
+		t.Fatalf("Expected the SBER alert in the log, got %v", log)
+	}
+	if !app.bellPending.Load() {
+		t.Error("Expected the bell to ring on the next draw")
+	}
+	app.dataMutex.RLock()
+	msg, typ := app.statusMessage, app.statusType
+	app.dataMutex.RUnlock()
+	if typ != StatusAlert || !strings.Contains(msg, "SBER price ≥ 310") {
+		t.Errorf("Expected an alert status, got %q (%v)", msg, typ)
+	}
+
+	table := app.portfolioView.TabbedView.AlertsTable
+	if got := table.GetCell(1, 3).Text; got != "Watching" {
+		t.Errorf("Expected the GAZP alert still watching first, got %q", got)
+	}
+	if got := table.GetCell(2, 3).Text; got != "Fired: last 310.2" {
+		t.Errorf("Expected the fired alert in the log, got %q", got)
+	}
+}
+
+func TestAlerts_RemoveSelected(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	app.addAlert("SBER", 2, 5)
+	app.addAlert("GAZP", 4, 3)
+
+	table := app.portfolioView.TabbedView.AlertsTable
+	table.Select(2, 0)
+	app.removeSelectedAlert()
+
+	active := app.alerts.Alerts()
+	if len(active) != 1 || active[0].Symbol != "SBER" || active[0].Kind != alerts.KindChange {
+		t.Fatalf("Expected only the SBER change alert left, got %v", active)
+	}
+	if err := app.addAlert("LKOH", 0, 0); err == nil {
+		t.Error("Expected a price alert at zero to be refused")
+	}
+}
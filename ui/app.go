@@ -1,12 +1,24 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"finam-terminal/alerts"
+	"finam-terminal/api"
+	"finam-terminal/conditional"
 	"finam-terminal/models"
+	"finam-terminal/oco"
+	"finam-terminal/risk"
+	"finam-terminal/tradestore"
+	"finam-terminal/trailing"
+	"finam-terminal/watchlist"
 
+	"github.com/gdamore/tcell/v2"
 	_ "github.com/gdamore/tcell/v2/encoding" // Register encodings for Windows support
 	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
 	"github.com/rivo/tview"
@@ -21,20 +33,29 @@ type APIClient interface {
 	GetAccounts() ([]models.AccountInfo, error)
 	GetAccountDetails(accountID string) (*models.AccountInfo, []models.Position, error)
 	GetQuotes(accountID string, symbols []string) (map[string]*models.Quote, error)
-	PlaceOrder(accountID string, symbol string, buySell string, quantity float64, params *models.OrderParams) (string, error)
-	PlaceSLTPOrder(accountID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice float64) (string, error)
-	ClosePosition(accountID string, symbol string, currentQuantity string, closeQuantity float64) (string, error)
+	SubscribeQuotes(accountID string, symbols []string)
+	SetQuoteHandler(handler func(models.Quote))
+	SubscribeOrderBook(accountID string, symbol string, handler func(*models.OrderBook)) (stop func())
+	SubscribeLatestTrades(accountID string, symbol string, handler func([]models.MarketTrade)) (stop func())
+	PlaceOrder(accountID string, symbol string, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error)
+	PlaceSLTPOrder(accountID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice models.Decimal) (string, error)
+	ModifyOrder(accountID, orderID, symbol, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error)
+	ModifySLTPOrder(accountID, orderID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice models.Decimal) (string, error)
+	ClosePosition(accountID string, symbol string, currentQuantity, closeQuantity models.Decimal) (string, error)
 
 	// Search operations
 	SearchSecurities(query string) ([]models.SecurityInfo, error)
 	GetSnapshots(accountID string, symbols []string) (map[string]models.Quote, error)
-	GetLotSize(ticker string) float64
+	GetLotSize(ticker string) models.Decimal
 	GetInstrumentName(key string) string
 
 	// History and Orders
-	GetTradeHistory(accountID string) ([]models.Trade, error)
+	GetTradeHistoryRange(accountID string, from, to time.Time) ([]models.Trade, error)
 	GetActiveOrders(accountID string) ([]models.Order, error)
+	GetTransactions(accountID string, from, to time.Time) ([]models.Transaction, error)
 	CancelOrder(accountID, orderID string) error
+	SubscribeOrders(accountID string, onSnapshot func([]models.Order), onUpdate func([]models.Order)) (stop func())
+	SubscribeTrades(accountID string, handler func([]models.Trade)) (stop func())
 
 	// Instrument Profile
 	GetBars(accountID string, symbol string, timeframe marketdata.TimeFrame, from, to time.Time) ([]models.Bar, error)
@@ -52,6 +73,7 @@ type App struct {
 	history       map[string][]models.Trade
 	activeOrders  map[string][]models.Order
 	quotes        map[string]map[string]*models.Quote
+	quoteBuf      quoteBuffer
 	selectedIdx   int
 	dataMutex     DataMutex
 	stopChan      chan struct{}
@@ -76,6 +98,81 @@ type App struct {
 	profileSymbol    string
 	profileTimeframe int  // 0=M5, 1=H1, 2=D, 3=W
 	profileOpen      bool
+
+	// Profile order book and time & sales streams
+	bookStream  streamSubscription
+	bookMu      sync.Mutex
+	bookLatest  *models.OrderBook
+	bookPending atomic.Bool
+	tapeStream  streamSubscription
+	tapeMu      sync.Mutex
+	tapePending []models.MarketTrade
+	tapeQueued  atomic.Bool
+
+	// Quote stream symbols waiting to be subscribed, sent one set at a time
+	quoteSubMu      sync.Mutex
+	quoteSubPending *quoteSubscription
+	quoteSubRunning bool
+
+	// Order and trade streams, two per loaded account
+	accountStreams []func()
+	ordersLive     map[string]bool // Accounts whose orders stream delivered a snapshot
+
+	// Pre-trade risk checks, daily loss limit and kill switch
+	risk *risk.Engine
+
+	// Expiry of GTD orders, which the terminal cancels itself
+	expiryMu    sync.Mutex
+	orderExpiry map[string]orderExpiry
+	expiryPath  string
+
+	// Client-side trailing stops
+	trailing *trailing.Manager
+
+	// Bracket entries by order ID, protected by SL/TP orders as they fill
+	bracketMu   sync.Mutex
+	brackets    map[string]*bracket
+	bracketPath string
+
+	// OCO groups of working orders, and the orders marked for linking (UI thread only)
+	oco       *oco.Manager
+	ocoMarked map[string]bool
+
+	// Conditional orders waiting for their price condition
+	conditional *conditional.Manager
+
+	// Watchlists, the list shown in the Watchlists tab and the live data of its
+	// instruments (UI thread only)
+	watchlists  *watchlist.Manager
+	watchName   string
+	watchQuotes map[string]models.Quote
+	watchSpark  map[string][]float64
+
+	// Price alerts, and whether a fired alert should ring the bell on the next draw
+	alerts      *alerts.Manager
+	bellPending atomic.Bool
+
+	// Trades fetched so far, how many days back the History tab reaches and what it
+	// shows (UI thread only)
+	tradeStore    *tradestore.Store
+	historyDays   int
+	historyFilter tradestore.Filter
+
+	// Lot matching method and grouping of the P&L tab (UI thread only)
+	pnlMethod string
+	pnlView   pnlView
+
+	// Cash transactions by account (guarded by dataMutex), how many days back the
+	// Transactions tab reaches and whether it shows totals by txPeriod (UI thread only)
+	transactions map[string][]models.Transaction
+	txDays       int
+	txTotals     bool
+	txPeriod     string
+
+	// Directory exports are saved to by default
+	exportDir string
+
+	paperMode bool
 }
 
 type StatusType int
@@ -85,6 +182,7 @@ const (
 	StatusLoading
 	StatusSuccess
 	StatusError
+	StatusAlert
 )
 
 // DataMutex wraps mutex for thread-safe data access
@@ -101,26 +199,41 @@ func NewApp(client APIClient, accounts []models.AccountInfo) *App {
 		positions:    make(map[string][]models.Position),
 		history:      make(map[string][]models.Trade),
 		activeOrders: make(map[string][]models.Order),
+		ordersLive:   make(map[string]bool),
 		quotes:       make(map[string]map[string]*models.Quote),
 		selectedIdx:  0,
 		stopChan:     make(chan struct{}),
 		pages:        tview.NewPages(),
+		risk:         risk.NewEngine(models.RiskLimits{}),
+		orderExpiry:  make(map[string]orderExpiry),
+		trailing:     trailing.NewManager(""),
+		brackets:     make(map[string]*bracket),
+		oco:          oco.NewManager(""),
+		ocoMarked:    make(map[string]bool),
+		conditional:  conditional.NewManager("", ""),
+		alerts:       alerts.NewManager(""),
+		watchlists:   watchlist.NewManager(""),
+		watchName:    watchlist.DefaultName,
+		watchQuotes:  make(map[string]models.Quote),
+		watchSpark:   make(map[string][]float64),
+		tradeStore:   tradestore.NewStore(""),
+		historyDays:  historyPage,
+		pnlMethod:    models.MatchFIFO,
+		transactions: make(map[string][]models.Transaction),
+		txDays:       transactionsPage,
+		txPeriod:     models.PeriodMonth,
 	}
 	a.portfolioView = NewPortfolioView(a.app)
 	a.header = createHeader()
 	a.statusBar = createStatusBar()
 
 	// Initialize OrderModal
-	a.orderModal = NewOrderModal(a.app, func(sub OrderSubmission) {
-		if err := a.SubmitOrder(sub); err != nil {
-			a.ShowError(extractUserMessage(err))
-		}
-	}, func() {
+	a.orderModal = NewOrderModal(a.app, a.submitFromModal, func() {
 		a.CloseOrderModal()
 	})
 
 	// Initialize ClosePositionModal
-	a.closeModal = NewClosePositionModal(a.app, func(quantity float64) {
+	a.closeModal = NewClosePositionModal(a.app, func(quantity models.Decimal) {
 		if err := a.SubmitClosePosition(quantity); err != nil {
 			a.ShowError(extractUserMessage(err))
 		}
@@ -140,11 +253,27 @@ func NewApp(client APIClient, accounts []models.AccountInfo) *App {
 		a.CloseSearchModal()
 		a.OpenProfileForSymbol(symbol)
 	})
+	a.searchModal.SetOnResultsChanged(a.updateQuoteSubscription)
+	a.searchModal.SetOnWatch(a.addToWatchlist)
+	a.searchModal.SetOnAlert(func(symbol string) {
+		a.CloseSearchModal()
+		if a.profileOpen {
+			a.app.SetFocus(a.profilePanel.ChartView)
+		}
+		a.ShowAlertPrompt(symbol)
+	})
 
 	// Initialize ProfilePanel
 	a.profilePanel = NewProfilePanel(a.app)
 	a.profileTimeframe = 2 // Default: Daily
 
+	// Ring the terminal bell after the draw that shows a fired alert
+	a.app.SetAfterDrawFunc(func(screen tcell.Screen) {
+		if a.bellPending.CompareAndSwap(true, false) {
+			screen.Beep()
+		}
+	})
+
 	return a
 }
 
@@ -156,7 +285,7 @@ func (a *App) CloseCloseModal() {
 func (a *App) CloseOrderModal() {
 	a.orderModal.RestoreCallback()
 	a.pages.HidePage("modal")
-	a.app.SetFocus(a.portfolioView.TabbedView.PositionsTable)
+	a.app.SetFocus(a.portfolioView.TabbedView.ActiveTable())
 }
 
 // OpenSearchModal opens the security search modal
@@ -174,7 +303,8 @@ func (a *App) OpenSearchModal() {
 // CloseSearchModal closes the security search modal
 func (a *App) CloseSearchModal() {
 	a.pages.HidePage("search_modal")
-	a.app.SetFocus(a.portfolioView.TabbedView.PositionsTable)
+	a.app.SetFocus(a.portfolioView.TabbedView.ActiveTable())
+	a.updateQuoteSubscription()
 }
 
 // IsSearchModalOpen returns true if the search modal is currently open
@@ -186,7 +316,7 @@ func (a *App) IsSearchModalOpen() bool {
 // OpenOrderModalWithTicker opens the order entry modal with a pre-populated ticker
 func (a *App) OpenOrderModalWithTicker(ticker string) {
 	a.orderModal.SetInstrument(ticker)
-	a.orderModal.SetQuantity(0)
+	a.orderModal.SetQuantity(models.Decimal{})
 	a.orderModal.ResetOrderType()
 	lotSize := a.client.GetLotSize(ticker)
 	a.orderModal.SetLotSize(lotSize)
@@ -200,22 +330,47 @@ func (a *App) OpenOrderModalWithTicker(ticker string) {
 	}
 	a.dataMutex.RUnlock()
 
-	var price float64
+	var price models.Decimal
 	if accountID != "" {
 		if snapshots, err := a.client.GetSnapshots(accountID, []string{ticker}); err == nil {
 			if q, ok := snapshots[ticker]; ok {
-				if p, err := parseFloat(q.Last); err == nil {
-					price = p
-				}
+				price = q.Last
 			}
 		}
 	}
 	a.orderModal.SetPrice(price)
+	a.loadOrderPriceStep(accountID, ticker)
 
 	a.pages.ShowPage("modal")
 	a.app.SetFocus(a.orderModal.Form)
 }
 
+// loadOrderPriceStep clears the tick size of the order modal and fetches the one of symbol
+// in the background. The modal snaps prices to it once it arrives, unless it was switched
+// to another instrument meanwhile.
+func (a *App) loadOrderPriceStep(accountID, symbol string) {
+	a.orderModal.SetPriceStep(models.Decimal{})
+	if accountID == "" || symbol == "" || a.client == nil {
+		return
+	}
+	go func() {
+		details, err := a.client.GetAssetInfo(accountID, symbol)
+		if err != nil {
+			log.Printf("[WARN] Price step of %s unknown, prices are sent as typed: %v", symbol, err)
+			return
+		}
+		if details == nil {
+			return
+		}
+		step := details.PriceStep()
+		a.app.QueueUpdateDraw(func() {
+			if a.orderModal.GetInstrument() == symbol {
+				a.orderModal.SetPriceStep(step)
+			}
+		})
+	}()
+}
+
 // IsModalOpen returns true if the order modal is currently open
 func (a *App) IsModalOpen() bool {
 	name, _ := a.pages.GetFrontPage()
@@ -250,44 +405,30 @@ func (a *App) SubmitOrder(sub OrderSubmission) error {
 	accountID := a.accounts[a.selectedIdx].ID
 	a.dataMutex.RUnlock()
 
-	// Show loading status
-	a.SetStatus("Placing order...", StatusLoading)
-
-	var id string
-	var err error
-
-	switch sub.OrderType {
-	case models.OrderTypeSLTP:
-		id, err = a.client.PlaceSLTPOrder(
-			accountID, sub.Instrument, sub.Direction,
-			sub.Quantity, sub.SLPrice,
-			sub.Quantity, sub.TPPrice,
-		)
-	case models.OrderTypeTakeProfit:
-		params := &models.OrderParams{
-			OrderType: sub.OrderType,
-			StopPrice: sub.TPPrice, // TP price is sent as stop price with opposite condition
+	if !sub.riskChecked {
+		if err := a.checkOrderRisk(accountID, sub); err != nil {
+			a.SetStatus("Order blocked by risk checks", StatusError)
+			return err
 		}
-		id, err = a.client.PlaceOrder(accountID, sub.Instrument, sub.Direction, sub.Quantity, params)
-	default:
-		var params *models.OrderParams
-		if sub.OrderType != "" && sub.OrderType != models.OrderTypeMarket {
-			params = &models.OrderParams{
-				OrderType:  sub.OrderType,
-				LimitPrice: sub.LimitPrice,
-				StopPrice:  sub.StopPrice,
-			}
-		}
-		id, err = a.client.PlaceOrder(accountID, sub.Instrument, sub.Direction, sub.Quantity, params)
 	}
 
+	// Show loading status
+	a.SetStatus("Placing order...", StatusLoading)
+
+	id, err := a.sendSubmission(accountID, sub, "")
 	if err != nil {
 		msg := extractUserMessage(err)
 		a.SetStatus(fmt.Sprintf("Order failed: %v", msg), StatusError)
 		return err
 	}
 
-	a.SetStatus(fmt.Sprintf("Order placed: %s", id), StatusSuccess)
+	if sub.OrderType == models.OrderTypeConditional {
+		// Counted against the daily limit when it is sent
+		a.SetStatus(fmt.Sprintf("Conditional order %s armed", id), StatusSuccess)
+	} else {
+		a.recordOrder(accountID)
+		a.SetStatus(fmt.Sprintf("Order placed: %s", id), StatusSuccess)
+	}
 
 	// Refresh data
 	a.loadDataAsync(accountID)
@@ -298,8 +439,70 @@ func (a *App) SubmitOrder(sub OrderSubmission) error {
 	return nil
 }
 
+// sendSubmission sends sub as a new order, or as the replacement of the working order
+// replaceID when it is set, and returns the ID of the placed order.
+func (a *App) sendSubmission(accountID string, sub OrderSubmission, replaceID string) (string, error) {
+	switch sub.OrderType {
+	case models.OrderTypeTrailing, models.OrderTypeBracket, models.OrderTypeConditional:
+		if replaceID != "" {
+			return "", fmt.Errorf("a %s order cannot replace a working order; cancel it and place a new one", sub.OrderType)
+		}
+	}
+	switch sub.OrderType {
+	case models.OrderTypeTrailing:
+		return a.placeTrailingStop(accountID, sub)
+	case models.OrderTypeBracket:
+		return a.placeBracket(accountID, sub)
+	case models.OrderTypeConditional:
+		return a.placeConditional(accountID, sub)
+	}
+	if sub.OrderType == models.OrderTypeSLTP {
+		if replaceID != "" {
+			return a.client.ModifySLTPOrder(accountID, replaceID, sub.Instrument, sub.Direction,
+				sub.Quantity, sub.SLPrice, sub.Quantity, sub.TPPrice)
+		}
+		return a.client.PlaceSLTPOrder(
+			accountID, sub.Instrument, sub.Direction,
+			sub.Quantity, sub.SLPrice,
+			sub.Quantity, sub.TPPrice,
+		)
+	}
+
+	var params *models.OrderParams
+	switch sub.OrderType {
+	case models.OrderTypeTakeProfit:
+		params = &models.OrderParams{
+			OrderType:  sub.OrderType,
+			StopPrice:  sub.TPPrice, // TP price is sent as stop price with opposite condition
+			Validity:   sub.Validity,
+			ValidUntil: sub.ValidUntil,
+		}
+	case "", models.OrderTypeMarket:
+	default:
+		params = &models.OrderParams{
+			OrderType:  sub.OrderType,
+			LimitPrice: sub.LimitPrice,
+			StopPrice:  sub.StopPrice,
+			Validity:   sub.Validity,
+			ValidUntil: sub.ValidUntil,
+		}
+	}
+
+	var id string
+	var err error
+	if replaceID != "" {
+		id, err = a.client.ModifyOrder(accountID, replaceID, sub.Instrument, sub.Direction, sub.Quantity, params)
+	} else {
+		id, err = a.client.PlaceOrder(accountID, sub.Instrument, sub.Direction, sub.Quantity, params)
+	}
+	if err == nil {
+		a.trackExpiry(accountID, id, replaceID, sub)
+	}
+	return id, err
+}
+
 // SubmitClosePosition submits an order to close an existing position
-func (a *App) SubmitClosePosition(closeQuantity float64) error {
+func (a *App) SubmitClosePosition(closeQuantity models.Decimal) error {
 	// Get selected row to identify the position again
 	row, _ := a.portfolioView.TabbedView.PositionsTable.GetSelection()
 	if row <= 0 {
@@ -334,6 +537,7 @@ func (a *App) SubmitClosePosition(closeQuantity float64) error {
 		return err
 	}
 
+	a.recordOrder(accountID)
 	a.SetStatus(fmt.Sprintf("Position closed: %s", id), StatusSuccess)
 
 	// Refresh data
@@ -360,7 +564,7 @@ func (a *App) getSelectedOrder() (models.Order, error) {
 		return models.Order{}, fmt.Errorf("no account selected")
 	}
 	accountID := a.accounts[a.selectedIdx].ID
-	orders := a.activeOrders[accountID]
+	orders, _ := a.groupOrderRows(a.activeOrders[accountID])
 
 	if idx < 0 || idx >= len(orders) {
 		return models.Order{}, fmt.Errorf("invalid order selection")
@@ -371,6 +575,11 @@ func (a *App) getSelectedOrder() (models.Order, error) {
 
 // cancelOrder cancels an order and refreshes the orders list.
 func (a *App) cancelOrder(accountID, orderID string) error {
+	if err := a.releaseTrailingStop(orderID); err != nil {
+		a.SetStatus(fmt.Sprintf("Cancel failed: %s", err), StatusError)
+		return err
+	}
+
 	a.SetStatus("Cancelling order...", StatusLoading)
 
 	err := a.client.CancelOrder(accountID, orderID)
@@ -390,6 +599,11 @@ func (a *App) cancelOrder(accountID, orderID string) error {
 
 // ShowCancelConfirmation shows a Yes/No confirmation modal for cancelling an order.
 func (a *App) ShowCancelConfirmation() {
+	if c, ok := a.selectedConditional(); ok {
+		a.showCancelConditional(c)
+		return
+	}
+
 	order, err := a.getSelectedOrder()
 	if err != nil {
 		a.SetStatus("No order selected", StatusError)
@@ -409,7 +623,7 @@ func (a *App) ShowCancelConfirmation() {
 	if displayName == "" {
 		displayName = order.Symbol
 	}
-	text := fmt.Sprintf("Cancel %s %s %s @ %s?", order.Type, order.Side, displayName, order.Price)
+	text := fmt.Sprintf("Cancel %s %s %s @ %s?", order.Type, order.Side, displayName, order.PriceText())
 
 	modal := tview.NewModal().
 		SetText(text).
@@ -450,14 +664,22 @@ func mapOrderTypeToModal(orderType string) string {
 		return models.OrderTypeTakeProfit
 	case "SL/TP":
 		return models.OrderTypeSLTP
+	case "Stop-Limit":
+		return models.OrderTypeStopLimit
 	default:
 		return ""
 	}
 }
 
 // ShowModifyOrderModal opens the order modal pre-filled with the selected order's parameters.
-// On submit, the old order is cancelled and a new one is placed.
+// On submit, the order is replaced through the client, which keeps the old order working
+// until the replacement is confirmed.
 func (a *App) ShowModifyOrderModal() {
+	if c, ok := a.selectedConditional(); ok {
+		a.showModifyConditional(c)
+		return
+	}
+
 	order, err := a.getSelectedOrder()
 	if err != nil {
 		a.SetStatus("No order selected", StatusError)
@@ -469,6 +691,15 @@ func (a *App) ShowModifyOrderModal() {
 		return
 	}
 
+	if _, ok := a.trailing.ByOrderID(order.ID); ok {
+		a.SetStatus("Trailing stops move by themselves; cancel and place a new one to change the trail", StatusError)
+		return
+	}
+	if _, _, ok := a.bracketOf(order.ID); ok {
+		a.SetStatus("Bracket entries cannot be modified; cancel and place a new bracket", StatusError)
+		return
+	}
+
 	a.dataMutex.RLock()
 	accountID := a.accounts[a.selectedIdx].ID
 	a.dataMutex.RUnlock()
@@ -483,45 +714,59 @@ func (a *App) ShowModifyOrderModal() {
 	// Pre-fill modal
 	a.orderModal.SetInstrument(order.Symbol)
 	a.orderModal.SetDirection(order.Side)
+	a.orderModal.LimitOrderTypes(replaceableOrderTypes)
 	a.orderModal.SetOrderType(modalType)
 
 	// Set lot size
 	lotSize := a.client.GetLotSize(order.Symbol)
 	a.orderModal.SetLotSize(lotSize)
+	a.loadOrderPriceStep(accountID, order.Symbol)
 
 	// Set quantity (parse from string)
-	if qty, err := parseFloat(order.Quantity); err == nil && qty > 0 {
+	if qty := order.Quantity; qty.Sign() > 0 {
 		// Convert shares to lots if lot size is known
-		if lotSize > 0 {
-			a.orderModal.SetQuantity(qty / lotSize)
-		} else {
-			a.orderModal.SetQuantity(qty)
+		if lotSize.Sign() > 0 {
+			qty = qty.Div(lotSize, 8).Trim()
 		}
+		a.orderModal.SetQuantity(qty)
 	}
 
 	// Set prices based on order type
 	switch modalType {
 	case models.OrderTypeLimit:
-		if p, err := parseFloat(order.LimitPrice); err == nil {
+		if p := order.LimitPrice; p.Sign() > 0 {
 			a.orderModal.SetLimitPrice(p)
 		}
 	case models.OrderTypeStop:
-		if p, err := parseFloat(order.StopPrice); err == nil {
+		if p := order.StopPrice; p.Sign() > 0 {
 			a.orderModal.SetStopPrice(p)
 		}
 	case models.OrderTypeTakeProfit:
-		if p, err := parseFloat(order.TPPrice); err == nil {
+		if p := order.TPPrice; p.Sign() > 0 {
 			a.orderModal.SetTPPrice(p)
-		} else if p, err := parseFloat(order.StopPrice); err == nil {
+		} else if p := order.StopPrice; p.Sign() > 0 {
 			a.orderModal.SetTPPrice(p)
 		}
 	case models.OrderTypeSLTP:
-		if p, err := parseFloat(order.SLPrice); err == nil {
+		if p := order.SLPrice; p.Sign() > 0 {
 			a.orderModal.SetSLPrice(p)
 		}
-		if p, err := parseFloat(order.TPPrice); err == nil {
+		if p := order.TPPrice; p.Sign() > 0 {
 			a.orderModal.SetTPPrice(p)
 		}
+	case models.OrderTypeStopLimit:
+		if p := order.StopPrice; p.Sign() > 0 {
+			a.orderModal.SetStopPrice(p)
+		}
+		if p := order.LimitPrice; p.Sign() > 0 {
+			a.orderModal.SetLimitPrice(p)
+		}
+	}
+	if until := a.orderValidUntil(order.ID); !until.IsZero() {
+		a.orderModal.SetValidity(models.ValidityGTD)
+		a.orderModal.SetValidUntil(until)
+	} else if order.Validity != "" {
+		a.orderModal.SetValidity(order.Validity)
 	}
 
 	// Set display name and title
@@ -533,33 +778,50 @@ func (a *App) ShowModifyOrderModal() {
 
 	// Set modify callback (original is saved internally for restoration)
 	a.orderModal.SetCallback(func(sub OrderSubmission) {
+		// Check the replacement before the old order is cancelled; on a violation the
+		// modal stays in modify mode so the user can correct the order
+		if err := a.checkOrderRisk(accountID, sub); err != nil {
+			var rerr *risk.Error
+			if errors.As(err, &rerr) {
+				retry := a.orderModal.GetCallback()
+				a.ShowRiskViolation(rerr, func() {
+					sub.OverrideRisk = true
+					retry(sub)
+				})
+			}
+			return
+		}
+		sub.riskChecked = true
+
 		// Restore original callback immediately
 		a.orderModal.RestoreCallback()
 
-		// Run cancel + place in a goroutine to avoid blocking the UI
+		// Run the modification in a goroutine to avoid blocking the UI
 		go func() {
-			// Cancel old order first
-			a.SetStatus("Cancelling old order...", StatusLoading)
-			cancelErr := a.client.CancelOrder(accountID, order.ID)
-			if cancelErr != nil {
-				msg := extractUserMessage(cancelErr)
-				a.SetStatus(fmt.Sprintf("Cancel failed: %s", msg), StatusError)
-				a.app.QueueUpdateDraw(func() {
-					a.ShowError(fmt.Sprintf("Failed to cancel order: %s", msg))
-				})
-				return
+			a.SetStatus("Modifying order...", StatusLoading)
+			a.oco.Replacing(order.ID)
+			id, err := a.sendSubmission(accountID, sub, order.ID)
+			if err := a.oco.Replaced(order.ID, id); err != nil {
+				log.Printf("[ERROR] %v", err)
 			}
 
-			// Place new order
-			if err := a.SubmitOrder(sub); err != nil {
+			// Refresh orders: a failed modification may still have changed them
+			a.loadOrdersAsync(accountID)
+
+			if err != nil {
+				msg := modifyFailureMessage(order.ID, err)
+				a.SetStatus("Modify failed", StatusError)
 				a.app.QueueUpdateDraw(func() {
-					a.ShowError(fmt.Sprintf("Old order was cancelled but new order failed: %s", extractUserMessage(err)))
+					a.ShowError(msg)
 				})
 				return
 			}
 
-			// Refresh orders
-			a.loadOrdersAsync(accountID)
+			a.recordOrder(accountID)
+			a.SetStatus(fmt.Sprintf("Order modified: %s", id), StatusSuccess)
+			a.app.QueueUpdateDraw(func() {
+				a.CloseOrderModal()
+			})
 		}()
 	})
 
@@ -567,6 +829,20 @@ func (a *App) ShowModifyOrderModal() {
 	a.app.SetFocus(a.orderModal.Form)
 }
 
+// modifyFailureMessage explains what is left on the book after a failed modification.
+func modifyFailureMessage(orderID string, err error) string {
+	var merr *api.ModifyError
+	if !errors.As(err, &merr) {
+		return fmt.Sprintf("Failed to modify order: %s", extractUserMessage(err))
+	}
+	msg := extractUserMessage(merr.Err)
+	if merr.NewOrderID != "" {
+		return fmt.Sprintf("Order %s was not cancelled and the new order %s could not be withdrawn: %s\n\nBoth orders may be active — check the Orders tab.",
+			orderID, merr.NewOrderID, msg)
+	}
+	return fmt.Sprintf("Order was not modified: %s\n\nThe original order %s was kept.", msg, orderID)
+}
+
 // ShowError displays an error modal
 func (a *App) ShowError(msg string) {
 	modal := tview.NewModal().
@@ -586,6 +862,16 @@ func (a *App) ShowError(msg string) {
 	a.pages.AddPage("alert", modal, false, true)
 }
 
+// SelectAccount makes the account at idx (0-based) the selected one.
+// It reports false and keeps the current selection when idx is out of range.
+func (a *App) SelectAccount(idx int) bool {
+	if idx < 0 || idx >= len(a.accounts) {
+		return false
+	}
+	a.selectedIdx = idx
+	return true
+}
+
 // Run starts the TUI application
 func (a *App) Run() error {
 	// Build layout
@@ -609,7 +895,7 @@ func (a *App) Run() error {
 		AddItem(nil, 0, 1, false).
 		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
 			AddItem(nil, 0, 1, false).
-			AddItem(a.orderModal.Layout, 20, 1, true). // Height 20 (form + price fields + info + footer)
+			AddItem(a.orderModal.Layout, 24, 1, true). // Height 24 (form + price and validity fields + info + footer)
 			AddItem(nil, 0, 1, false), 50, 1, true).   // Width 50
 		AddItem(nil, 0, 1, false)
 
@@ -638,7 +924,10 @@ func (a *App) Run() error {
 	updateInfoPanel(a)
 	updateStatusBar(a)
 
-	// Start background refresh
+	// Start background refresh and the streamed quote, order and trade feeds
+	a.client.SetQuoteHandler(a.onQuote)
+	go a.quoteLoop()
+	a.startAccountStreams()
 	go a.backgroundRefresh()
 
 	return a.app.SetRoot(a.pages, true).EnableMouse(false).Run()
@@ -647,6 +936,9 @@ func (a *App) Run() error {
 // Stop stops the application
 func (a *App) Stop() {
 	a.stopOnce.Do(func() {
+		a.stopOrderBook()
+		a.stopTape()
+		a.stopAccountStreams()
 		close(a.stopChan)
 		a.app.Stop()
 	})
@@ -678,7 +970,7 @@ func (a *App) OpenOrderModal() {
 	}
 
 	a.orderModal.SetInstrument(symbol)
-	a.orderModal.SetQuantity(0)
+	a.orderModal.SetQuantity(models.Decimal{})
 	a.orderModal.ResetOrderType()
 	a.orderModal.SetDisplayName(displayName)
 
@@ -693,8 +985,8 @@ func (a *App) OpenOrderModal() {
 			accID := a.accounts[a.selectedIdx].ID
 			for _, pos := range a.positions[accID] {
 				if pos.Ticker == symbol {
-					if p, err := parseFloat(pos.CurrentPrice); err == nil {
-						a.orderModal.SetPrice(p)
+					if pos.CurrentPrice.Known() {
+						a.orderModal.SetPrice(pos.CurrentPrice)
 					}
 					break
 				}
@@ -702,9 +994,17 @@ func (a *App) OpenOrderModal() {
 		}
 		a.dataMutex.RUnlock()
 	} else {
-		a.orderModal.SetLotSize(0)
-		a.orderModal.SetPrice(0)
+		a.orderModal.SetLotSize(models.Decimal{})
+		a.orderModal.SetPrice(models.Decimal{})
+	}
+
+	a.dataMutex.RLock()
+	accountID := ""
+	if a.selectedIdx < len(a.accounts) {
+		accountID = a.accounts[a.selectedIdx].ID
 	}
+	a.dataMutex.RUnlock()
+	a.loadOrderPriceStep(accountID, symbol)
 
 	a.pages.ShowPage("modal")
 	a.app.SetFocus(a.orderModal.Form)
@@ -724,23 +1024,21 @@ func (a *App) OpenCloseModal() {
 			if idx >= 0 && idx < len(positions) {
 				pos := positions[idx]
 				// Parse values for display
-				qty, err := parseFloat(pos.Quantity)
-				if err != nil {
+				if !pos.Quantity.Known() {
+					a.dataMutex.RUnlock()
 					a.ShowError(fmt.Sprintf("Invalid quantity format '%s' for %s", pos.Quantity, pos.Ticker))
 					return
 				}
-				if qty <= 0 {
+				if pos.Quantity.Sign() <= 0 {
+					a.dataMutex.RUnlock()
 					a.ShowError(fmt.Sprintf("Position %s has non-positive quantity: %s", pos.Ticker, pos.Quantity))
 					return
 				}
 
-				price, _ := parseFloat(pos.CurrentPrice)
-				pnl, _ := parseFloat(pos.UnrealizedPnL)
-
-				if pos.LotSize > 0 {
-					a.closeModal.SetPositionDataWithLots(pos.Ticker, qty, price, pnl, pos.LotSize)
+				if pos.LotSize.Sign() > 0 {
+					a.closeModal.SetPositionDataWithLots(pos.Ticker, pos.Quantity, pos.CurrentPrice, pos.UnrealizedPnL, pos.LotSize)
 				} else {
-					a.closeModal.SetPositionData(pos.Ticker, qty, price, pnl)
+					a.closeModal.SetPositionData(pos.Ticker, pos.Quantity, pos.CurrentPrice, pos.UnrealizedPnL)
 				}
 				a.closeModal.SetDisplayName(pos.Name)
 				a.pages.ShowPage("close_modal")
@@ -797,6 +1095,7 @@ func (a *App) OpenProfileForSymbol(symbol string) {
 	a.profileSymbol = symbol
 	a.profileOpen = true
 	a.profilePanel.SetTimeframe(a.profileTimeframe)
+	a.profilePanel.SetLotSize(a.client.GetLotSize(symbol))
 	a.profilePanel.Update(nil) // Show loading state
 	a.pages.SwitchToPage("profile")
 	a.app.SetFocus(a.profilePanel.ChartView)
@@ -810,15 +1109,21 @@ func (a *App) OpenProfileForSymbol(symbol string) {
 
 	if accountID != "" {
 		a.loadProfileAsync(accountID, symbol, a.profileTimeframe)
+		a.startOrderBook(accountID, symbol)
+		a.startTape(accountID, symbol)
 	}
+	a.updateQuoteSubscription()
 }
 
 // CloseProfile closes the profile overlay and returns to the main view.
 func (a *App) CloseProfile() {
 	a.profileOpen = false
 	a.profileSymbol = ""
+	a.stopOrderBook()
+	a.stopTape()
 	a.pages.SwitchToPage("main")
-	a.app.SetFocus(a.portfolioView.TabbedView.PositionsTable)
+	a.app.SetFocus(a.portfolioView.TabbedView.ActiveTable())
+	a.updateQuoteSubscription()
 }
 
 // IsProfileOpen returns true if the profile overlay is currently shown.
@@ -0,0 +1,244 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"finam-terminal/models"
+
+	"finam-terminal/atomicfile"
+)
+
+// bracket is an entry order whose fills are protected by SL/TP orders placed by the
+// terminal. Quantities are in shares, as the broker reports executed quantities.
+type bracket struct {
+	AccountID string         `json:"account_id"`
+	Symbol    string         `json:"symbol"`
+	Side      string         `json:"side"` // Side of the entry
+	SLPrice   models.Decimal `json:"sl_price,omitzero"`
+	TPPrice   models.Decimal `json:"tp_price,omitzero"`
+
+	Protected     models.Decimal `json:"protected,omitzero"`       // Filled shares covered by placed SL/TP orders
+	Pending       models.Decimal `json:"pending,omitzero"`         // Filled shares with an SL/TP order being placed
+	Failed        models.Decimal `json:"failed,omitzero"`          // Filled shares whose SL/TP order was rejected
+	ProtectionIDs []string       `json:"protection_ids,omitempty"` // SL/TP orders placed for the fills
+
+	resumed bool // Loaded from the store; the entry may have finished while the terminal was closed
+}
+
+// exitSide returns the side of the protection orders.
+func (b *bracket) exitSide() string {
+	if b.Side == "Sell" {
+		return "Buy"
+	}
+	return "Sell"
+}
+
+// SetBracketStore loads the bracket entries armed in path and saves them there from now on,
+// so fills of an entry placed in an earlier session are still protected.
+func (a *App) SetBracketStore(path string) error {
+	a.bracketMu.Lock()
+	defer a.bracketMu.Unlock()
+	a.bracketPath = path
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read bracket orders: %w", err)
+	}
+	brackets := make(map[string]*bracket)
+	if err := json.Unmarshal(data, &brackets); err != nil {
+		return fmt.Errorf("failed to parse bracket orders %s: %w", path, err)
+	}
+	for id, b := range brackets {
+		// The terminal exited while protecting these shares; whether it was placed is unknown
+		if b.Pending.Sign() > 0 {
+			log.Printf("[WARN] Bracket %s: SL/TP for %s shares of %s may not have been placed, check the Orders tab",
+				id, b.Pending, b.Symbol)
+			b.Failed = b.Failed.Add(b.Pending)
+			b.Pending = models.Decimal{}
+		}
+		b.resumed = true
+		a.brackets[id] = b
+	}
+	if n := len(brackets); n > 0 {
+		log.Printf("[INFO] Resumed %d bracket orders from %s", n, path)
+	}
+	return nil
+}
+
+// placeBracket places the entry of a bracket order, at market or at sub.LimitPrice, and
+// arms its SL/TP protection. The protection is placed by protectBrackets as the entry fills.
+func (a *App) placeBracket(accountID string, sub OrderSubmission) (string, error) {
+	var params *models.OrderParams
+	if sub.LimitPrice.Sign() > 0 {
+		params = &models.OrderParams{OrderType: models.OrderTypeLimit, LimitPrice: sub.LimitPrice}
+	}
+	id, err := a.client.PlaceOrder(accountID, sub.Instrument, sub.Direction, sub.Quantity, params)
+	if err != nil {
+		return "", err
+	}
+
+	a.bracketMu.Lock()
+	a.brackets[id] = &bracket{
+		AccountID: accountID,
+		Symbol:    sub.Instrument,
+		Side:      sub.Direction,
+		SLPrice:   sub.SLPrice,
+		TPPrice:   sub.TPPrice,
+	}
+	a.saveBrackets()
+	a.bracketMu.Unlock()
+
+	log.Printf("[INFO] Bracket entry %s: %s %s, SL %s TP %s", id, sub.Direction, sub.Instrument,
+		sub.SLPrice, sub.TPPrice)
+
+	// A market entry may have filled before it was armed
+	a.protectBrackets(accountID)
+	return id, nil
+}
+
+// protectBrackets places SL/TP orders for the newly filled part of every bracket entry of
+// accountID, and closes the brackets whose entry is no longer working. An entry cancelled
+// or rejected before any fill leaves nothing to protect and its protection is dropped.
+// A resumed entry missing from the loaded orders finished while the terminal was closed;
+// its fills are unknown, so the bracket is closed with a warning.
+func (a *App) protectBrackets(accountID string) {
+	a.dataMutex.RLock()
+	entries := make(map[string]models.Order)
+	for _, o := range a.activeOrders[accountID] {
+		entries[o.ID] = o
+	}
+	_, loaded := a.activeOrders[accountID]
+	a.dataMutex.RUnlock()
+
+	a.bracketMu.Lock()
+	changed := false
+	var unprotected []string
+	for id, b := range a.brackets {
+		if b.AccountID != accountID {
+			continue
+		}
+		entry, ok := entries[id]
+		if !ok {
+			switch {
+			case b.Pending.Sign() > 0:
+			case b.Protected.Add(b.Failed).Sign() > 0:
+				// A finished entry leaves the streamed order list after its fills were seen
+				delete(a.brackets, id)
+				changed = true
+				log.Printf("[INFO] Bracket entry %s finished, %s shares protected by %v", id, b.Protected, b.ProtectionIDs)
+			case b.resumed && loaded:
+				delete(a.brackets, id)
+				changed = true
+				unprotected = append(unprotected, b.Symbol)
+				log.Printf("[WARN] Bracket entry %s on %s finished while the terminal was closed, its fills are not protected",
+					id, b.Symbol)
+			}
+			continue
+		}
+
+		executed := entry.ExecutedQty
+		if executed.Sign() <= 0 && (entry.Status == "Filled" || entry.Status == "Executed") {
+			executed = entry.Quantity
+		}
+		if fill := executed.Sub(b.Protected).Sub(b.Pending).Sub(b.Failed); fill.Sign() > 0 {
+			b.Pending = b.Pending.Add(fill)
+			changed = true
+			go a.placeProtection(id, b, fill)
+		}
+
+		if entry.Status == "" || isOrderCancellable(entry.Status) || b.Pending.Sign() > 0 {
+			continue
+		}
+		delete(a.brackets, id)
+		changed = true
+		if b.Protected.Add(b.Failed).Sign() == 0 {
+			log.Printf("[INFO] Bracket entry %s %s without fills, protection cancelled", id, entry.Status)
+		} else {
+			log.Printf("[INFO] Bracket entry %s %s, %s shares protected by %v", id, entry.Status,
+				b.Protected, b.ProtectionIDs)
+		}
+	}
+	if changed {
+		a.saveBrackets()
+	}
+	a.bracketMu.Unlock()
+
+	if len(unprotected) > 0 {
+		a.SetStatus(fmt.Sprintf("Bracket entry on %s finished while the terminal was closed — check the position, SL/TP was not placed",
+			strings.Join(unprotected, ", ")), StatusError)
+	}
+}
+
+// placeProtection places the SL/TP order for fill shares of the bracket entry entryID.
+func (a *App) placeProtection(entryID string, b *bracket, fill models.Decimal) {
+	lots := fill
+	if lotSize := a.client.GetLotSize(b.Symbol); lotSize.Sign() > 0 {
+		lots = fill.Div(lotSize, 8).Trim()
+	}
+
+	id, err := a.client.PlaceSLTPOrder(b.AccountID, b.Symbol, b.exitSide(), lots, b.SLPrice, lots, b.TPPrice)
+
+	a.bracketMu.Lock()
+	b.Pending = b.Pending.Sub(fill)
+	if err != nil {
+		b.Failed = b.Failed.Add(fill)
+	} else {
+		b.Protected = b.Protected.Add(fill)
+		b.ProtectionIDs = append(b.ProtectionIDs, id)
+	}
+	a.saveBrackets()
+	a.bracketMu.Unlock()
+
+	// Close the bracket if its entry finished while the order was placed
+	defer a.protectBrackets(b.AccountID)
+
+	if err != nil {
+		log.Printf("[ERROR] Bracket %s: SL/TP for %s lots of %s not placed: %v", entryID, lots, b.Symbol, err)
+		a.SetStatus(fmt.Sprintf("SL/TP for %s not placed: %s — the fill is unprotected", b.Symbol, extractUserMessage(err)), StatusError)
+		return
+	}
+	log.Printf("[INFO] Bracket %s: SL/TP %s placed for %s lots of %s", entryID, id, lots, b.Symbol)
+	a.SetStatus(fmt.Sprintf("SL/TP placed for %s lots of %s", lots, b.Symbol), StatusSuccess)
+}
+
+// bracketOf returns the protection prices of the bracket entry orderID.
+func (a *App) bracketOf(orderID string) (sl, tp models.Decimal, ok bool) {
+	a.bracketMu.Lock()
+	defer a.bracketMu.Unlock()
+	b, ok := a.brackets[orderID]
+	if !ok {
+		return models.Decimal{}, models.Decimal{}, false
+	}
+	return b.SLPrice, b.TPPrice, true
+}
+
+// saveBrackets writes the armed brackets to the bracket store. The caller holds bracketMu.
+func (a *App) saveBrackets() {
+	if a.bracketPath == "" {
+		return
+	}
+	if err := writeBrackets(a.bracketPath, a.brackets); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+}
+
+func writeBrackets(path string, brackets map[string]*bracket) error {
+	data, err := json.MarshalIndent(brackets, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.Write(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save bracket orders: %w", err)
+	}
+	return nil
+}
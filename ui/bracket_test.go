@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"finam-terminal/models"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBracket_ProtectsEachFill(t *testing.T) {
+	var entry *models.OrderParams
+	protections := make(chan string, 4)
+	mockClient := &mockClient{
+		PlaceOrderFunc: func(id, sym, side string, qty models.Decimal, p *models.OrderParams) (string, error) {
+			entry = p
+			return "entry1", nil
+		},
+		PlaceSLTPOrderFunc: func(accountID, symbol, side string, slQty, slPrice, tpQty, tpPrice models.Decimal) (string, error) {
+			protections <- fmt.Sprintf("%s %v@%v/%v@%v", side, slQty, slPrice, tpQty, tpPrice)
+			return "sltp", nil
+		},
+		GetLotSizeFunc: func(ticker string) models.Decimal { return models.DecimalFromInt(10) },
+	}
+	app := NewApp(mockClient, []models.AccountInfo{{ID: "acc1"}})
+
+	err := app.SubmitOrder(OrderSubmission{
+		Instrument: "SBER",
+		Quantity:   models.DecimalFromInt(10),
+		Direction:  "Buy",
+		OrderType:  models.OrderTypeBracket,
+		LimitPrice: models.DecimalFromInt(300),
+		SLPrice:    models.DecimalFromInt(290),
+		TPPrice:    models.DecimalFromInt(320),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if entry == nil || entry.OrderType != models.OrderTypeLimit || entry.LimitPrice.Float64() != 300 {
+		t.Fatalf("Expected a limit entry at 300, got %+v", entry)
+	}
+
+	expect := func(want string) {
+		t.Helper()
+		select {
+		case got := <-protections:
+			if got != want {
+				t.Errorf("Expected SL/TP %q, got %q", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected SL/TP %q to be placed", want)
+		}
+	}
+
+	// 30 of 100 shares filled: 3 lots protected
+	app.applyOrderUpdates("acc1", []models.Order{{ID: "entry1", Status: "Partial", Quantity: models.DecimalOf("100"), ExecutedQty: models.DecimalOf("30")}})
+	expect("Sell 3@290/3@320")
+
+	// The same fill reported again places nothing
+	app.applyOrderUpdates("acc1", []models.Order{{ID: "entry1", Status: "Partial", Quantity: models.DecimalOf("100"), ExecutedQty: models.DecimalOf("30")}})
+
+	app.applyOrderUpdates("acc1", []models.Order{{ID: "entry1", Status: "Filled", Quantity: models.DecimalOf("100"), ExecutedQty: models.DecimalOf("100")}})
+	expect("Sell 7@290/7@320")
+
+	select {
+	case got := <-protections:
+		t.Errorf("Unexpected SL/TP %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, _, ok := app.bracketOf("entry1"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the bracket to close after its entry filled")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBracket_CancelledEntryDropsProtection(t *testing.T) {
+	mockClient := &mockClient{
+		PlaceSLTPOrderFunc: func(accountID, symbol, side string, slQty, slPrice, tpQty, tpPrice models.Decimal) (string, error) {
+			t.Error("Unexpected SL/TP for an entry without fills")
+			return "", nil
+		},
+	}
+	app := NewApp(mockClient, []models.AccountInfo{{ID: "acc1"}})
+
+	id, err := app.placeBracket("acc1", OrderSubmission{Instrument: "SBER", Quantity: models.DecimalFromInt(1), Direction: "Sell", SLPrice: models.DecimalFromInt(310)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.setOrders("acc1", []models.Order{{ID: id, Status: "Cancelled", Quantity: models.DecimalOf("10")}})
+	if _, _, ok := app.bracketOf(id); ok {
+		t.Error("Expected the bracket to be dropped with its cancelled entry")
+	}
+}
+
+func TestBracketPricesValid(t *testing.T) {
+	tests := []struct {
+		dir           string
+		entry, sl, tp float64
+		expected      bool
+	}{
+		{"Buy", 300, 290, 320, true},
+		{"Buy", 300, 0, 320, true},
+		{"Buy", 0, 290, 0, true}, // market entry
+		{"Buy", 300, 0, 0, false},
+		{"Buy", 300, 310, 320, false},
+		{"Buy", 300, 290, 280, false},
+		{"Sell", 300, 310, 280, true},
+		{"Sell", 300, 290, 0, false},
+	}
+	for _, tt := range tests {
+		if got := bracketPricesValid(tt.dir, tt.entry, tt.sl, tt.tp); got != tt.expected {
+			t.Errorf("bracketPricesValid(%s, %v, %v, %v) = %v, want %v", tt.dir, tt.entry, tt.sl, tt.tp, got, tt.expected)
+		}
+	}
+}
+
+func TestBracket_StoreSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bracket-orders.json")
+	app := NewApp(&mockClient{
+		PlaceOrderFunc: func(id, sym, side string, qty models.Decimal, p *models.OrderParams) (string, error) {
+			return "entry1", nil
+		},
+	}, []models.AccountInfo{{ID: "acc1"}})
+	if err := app.SetBracketStore(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := app.placeBracket("acc1", OrderSubmission{Instrument: "SBER", Quantity: models.DecimalFromInt(10),
+		Direction: "Buy", LimitPrice: models.DecimalFromInt(300), SLPrice: models.DecimalFromInt(290)}); err != nil {
+		t.Fatal(err)
+	}
+
+	protections := make(chan string, 1)
+	restarted := NewApp(&mockClient{
+		PlaceSLTPOrderFunc: func(accountID, symbol, side string, slQty, slPrice, tpQty, tpPrice models.Decimal) (string, error) {
+			protections <- fmt.Sprintf("%s %s %v@%v", accountID, side, slQty, slPrice)
+			return "sltp", nil
+		},
+	}, []models.AccountInfo{{ID: "acc1"}})
+	if err := restarted.SetBracketStore(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sl, _, ok := restarted.bracketOf("entry1"); !ok || sl.Float64() != 290 {
+		t.Fatalf("Expected the bracket of entry1 to be reloaded, got %v %v", sl, ok)
+	}
+
+	restarted.setOrders("acc1", []models.Order{{ID: "entry1", Status: "Partial", Quantity: models.DecimalOf("10"), ExecutedQty: models.DecimalOf("4")}})
+	select {
+	case got := <-protections:
+		if got != "acc1 Sell 4@290" {
+			t.Errorf("Expected SL/TP for the fill, got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the reloaded bracket to protect the fill")
+	}
+	// The store is saved together with the protected quantity; wait for it before the
+	// temporary directory is removed
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		restarted.bracketMu.Lock()
+		protected := restarted.brackets["entry1"].Protected
+		restarted.bracketMu.Unlock()
+		if protected.Sign() > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the protected fill to be saved")
+		}
+	}
+}
+
+func TestBracket_ResumedEntryFinishedWhileClosed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bracket-orders.json")
+	app := NewApp(&mockClient{
+		PlaceOrderFunc: func(id, sym, side string, qty models.Decimal, p *models.OrderParams) (string, error) {
+			return "entry1", nil
+		},
+	}, []models.AccountInfo{{ID: "acc1"}})
+	if err := app.SetBracketStore(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := app.placeBracket("acc1", OrderSubmission{Instrument: "SBER", Quantity: models.DecimalFromInt(10),
+		Direction: "Buy", LimitPrice: models.DecimalFromInt(300), SLPrice: models.DecimalFromInt(290)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An entry placed in this session is kept until it shows up in the orders
+	app.setOrders("acc1", nil)
+	if _, _, ok := app.bracketOf("entry1"); !ok {
+		t.Fatal("Expected the new entry to stay armed")
+	}
+
+	restarted := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	if err := restarted.SetBracketStore(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	restarted.protectBrackets("acc1")
+	if _, _, ok := restarted.bracketOf("entry1"); !ok {
+		t.Fatal("Expected the resumed entry to stay armed until the orders are loaded")
+	}
+
+	restarted.setOrders("acc1", []models.Order{{ID: "other", Status: "Active"}})
+	if _, _, ok := restarted.bracketOf("entry1"); ok {
+		t.Error("Expected the finished entry to be closed")
+	}
+	restarted.dataMutex.RLock()
+	msg, typ := restarted.statusMessage, restarted.statusType
+	restarted.dataMutex.RUnlock()
+	if typ != StatusError || !strings.Contains(msg, "SBER") {
+		t.Errorf("Expected a warning about SBER, got %q", msg)
+	}
+
+	again := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	if err := again.SetBracketStore(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, _, ok := again.bracketOf("entry1"); ok {
+		t.Error("Expected the closed bracket to be gone from the store")
+	}
+}
@@ -2,8 +2,8 @@ package ui
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
+
+	"finam-terminal/models"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -16,7 +16,7 @@ type ClosePositionModal struct {
 	Footer   *tview.TextView
 	infoArea *tview.TextView
 	app      *tview.Application
-	callback func(float64)
+	callback func(models.Decimal)
 	onCancel func()
 	onError  func(string)
 
@@ -26,13 +26,13 @@ type ClosePositionModal struct {
 	priceField    *tview.InputField // Read-only
 
 	// State
-	currentPrice float64
-	maxQuantity  float64
-	lotSize      float64
+	currentPrice models.Decimal
+	maxQuantity  models.Decimal
+	lotSize      models.Decimal
 }
 
 // NewClosePositionModal creates a new close position modal
-func NewClosePositionModal(app *tview.Application, callback func(float64), onCancel func(), onError func(string)) *ClosePositionModal {
+func NewClosePositionModal(app *tview.Application, callback func(models.Decimal), onCancel func(), onError func(string)) *ClosePositionModal {
 	m := &ClosePositionModal{
 		Layout:   tview.NewFlex(),
 		Form:     tview.NewForm(),
@@ -93,7 +93,7 @@ func (m *ClosePositionModal) setupUI() {
 			}
 		} else {
 			if m.onError != nil {
-				m.onError(fmt.Sprintf("Invalid quantity. Must be > 0 and <= %v", m.maxQuantity))
+				m.onError(fmt.Sprintf("Invalid quantity. Must be > 0 and <= %s", m.maxQuantity))
 			}
 		}
 	})
@@ -131,63 +131,53 @@ func (m *ClosePositionModal) SetDisplayName(name string) {
 }
 
 // SetPositionData populates the modal with position details
-func (m *ClosePositionModal) SetPositionData(symbol string, quantity float64, price float64, pnl float64) {
+func (m *ClosePositionModal) SetPositionData(symbol string, quantity, price, pnl models.Decimal) {
 	m.symbolField.SetText(symbol)
 
 	// Keep maxQuantity state but leave the field empty for user input as requested
-	absQty := quantity
-	if absQty < 0 {
-		absQty = -absQty
-	}
-
 	m.quantityField.SetText("")
-	m.priceField.SetText(fmt.Sprintf("%.2f", price))
+	m.priceField.SetText(price.StringFixed(2))
 	m.currentPrice = price
-	m.maxQuantity = absQty
+	m.maxQuantity = quantity.Abs()
 }
 
 func (m *ClosePositionModal) GetSymbol() string {
 	return m.symbolField.GetText()
 }
 
-func (m *ClosePositionModal) GetQuantity() float64 {
-	// Allow comma in user input
-	val, err := strconv.ParseFloat(strings.ReplaceAll(m.quantityField.GetText(), ",", "."), 64)
+func (m *ClosePositionModal) GetQuantity() models.Decimal {
+	// ParseDecimal allows a comma in user input
+	val, err := models.ParseDecimal(m.quantityField.GetText())
 	if err != nil {
-		return 0
+		return models.Decimal{}
 	}
 	return val
 }
 
 func (m *ClosePositionModal) Validate() bool {
 	qty := m.GetQuantity()
-	return qty > 0 && qty <= m.maxQuantity
+	return qty.Sign() > 0 && qty.Cmp(m.maxQuantity) <= 0
 }
 
 // GetLotSize returns the current lot size
-func (m *ClosePositionModal) GetLotSize() float64 {
+func (m *ClosePositionModal) GetLotSize() models.Decimal {
 	return m.lotSize
 }
 
 // SetPositionDataWithLots populates the modal with position details using lot-based quantities
-func (m *ClosePositionModal) SetPositionDataWithLots(symbol string, quantity float64, price float64, pnl float64, lotSize float64) {
+func (m *ClosePositionModal) SetPositionDataWithLots(symbol string, quantity, price, pnl, lotSize models.Decimal) {
 	m.symbolField.SetText(symbol)
 	m.lotSize = lotSize
 
-	absQty := quantity
-	if absQty < 0 {
-		absQty = -absQty
-	}
-
 	// Convert max quantity to lots
-	if lotSize > 0 {
-		m.maxQuantity = absQty / lotSize
+	if lotSize.Sign() > 0 {
+		m.maxQuantity = quantity.Abs().Div(lotSize, 8).Trim()
 	} else {
-		m.maxQuantity = absQty
+		m.maxQuantity = quantity.Abs()
 	}
 
 	m.quantityField.SetText("")
-	m.priceField.SetText(fmt.Sprintf("%.2f", price))
+	m.priceField.SetText(price.StringFixed(2))
 	m.currentPrice = price
 
 	// Update info area
@@ -196,9 +186,9 @@ func (m *ClosePositionModal) SetPositionDataWithLots(symbol string, quantity flo
 
 // updateInfo refreshes the quantity label and info area with lot information
 func (m *ClosePositionModal) updateInfo() {
-	if m.lotSize > 0 {
-		m.quantityField.SetLabel(fmt.Sprintf("Lots (size - %.0f): ", m.lotSize))
-		m.infoArea.SetText(fmt.Sprintf(" Position: %.0f lots", m.maxQuantity))
+	if m.lotSize.Sign() > 0 {
+		m.quantityField.SetLabel(fmt.Sprintf("Lots (size - %s): ", m.lotSize))
+		m.infoArea.SetText(fmt.Sprintf(" Position: %s lots", m.maxQuantity))
 	} else {
 		m.quantityField.SetLabel("Quantity:     ")
 		m.infoArea.SetText("")
@@ -3,6 +3,8 @@ package ui
 import (
 	"testing"
 
+	"finam-terminal/models"
+
 	"github.com/rivo/tview"
 )
 
@@ -26,13 +28,13 @@ func TestClosePositionModal_SetPositionData(t *testing.T) {
 	app := tview.NewApplication()
 	modal := NewClosePositionModal(app, nil, nil, nil)
 
-	modal.SetPositionData("SBER", 100, 250.5, 500.0)
+	modal.SetPositionData("SBER", models.DecimalFromInt(100), models.DecimalOf("250.5"), models.DecimalFromInt(500))
 
 	if modal.GetSymbol() != "SBER" {
 		t.Errorf("Expected symbol SBER, got %s", modal.GetSymbol())
 	}
-	if modal.GetQuantity() != 0 {
-		t.Errorf("Expected quantity 0 (empty field), got %f", modal.GetQuantity())
+	if modal.GetQuantity().Sign() != 0 {
+		t.Errorf("Expected quantity 0 (empty field), got %s", modal.GetQuantity())
 	}
 }
 
@@ -41,7 +43,7 @@ func TestClosePositionModal_Validation(t *testing.T) {
 	modal := NewClosePositionModal(app, nil, nil, nil)
 
 	// Set data: 100 shares
-	modal.SetPositionData("SBER", 100, 250.0, 1000.0)
+	modal.SetPositionData("SBER", models.DecimalFromInt(100), models.DecimalFromInt(250), models.DecimalFromInt(1000))
 
 	// Empty/Zero
 	modal.quantityField.SetText("0")
@@ -77,7 +79,7 @@ func TestClosePositionModal_Validation(t *testing.T) {
 func TestClosePositionModal_ValidateBehavior(t *testing.T) {
 	// Setup
 	modal := NewClosePositionModal(nil, nil, nil, nil)
-	modal.SetPositionData("TEST", 100.0, 10.0, 50.0)
+	modal.SetPositionData("TEST", models.DecimalFromInt(100), models.DecimalFromInt(10), models.DecimalFromInt(50))
 	modal.quantityField.SetText("100")
 
 	// Case 1: Default Valid
@@ -105,7 +107,7 @@ func TestClosePositionModal_ValidateBehavior(t *testing.T) {
 
 	// Case 5: Float Quantity (should be valid in logic, even if UI blocks it)
 	// We want to verify that the validation logic ITSELF supports floats.
-	modal.SetPositionData("TEST", 1.5, 10.0, 50.0)
+	modal.SetPositionData("TEST", models.DecimalOf("1.5"), models.DecimalFromInt(10), models.DecimalFromInt(50))
 	modal.quantityField.SetText("1.5")
 	if !modal.Validate() {
 		t.Error("Float quantity 1.5 should be valid (Max 1.5)")
@@ -116,7 +118,7 @@ func TestClosePositionModal_LotBasedDisplay(t *testing.T) {
 	modal := NewClosePositionModal(nil, nil, nil, nil)
 
 	// SetPositionData with lot size: 100 shares, lot size 10 = 10 lots
-	modal.SetPositionDataWithLots("SBER", 100.0, 250.50, 500.0, 10.0)
+	modal.SetPositionDataWithLots("SBER", models.DecimalFromInt(100), models.DecimalOf("250.50"), models.DecimalFromInt(500), models.DecimalFromInt(10))
 
 	// Symbol should be set
 	if modal.GetSymbol() != "SBER" {
@@ -124,12 +126,12 @@ func TestClosePositionModal_LotBasedDisplay(t *testing.T) {
 	}
 
 	// Max quantity should be in lots (100 shares / 10 lot size = 10 lots)
-	if modal.maxQuantity != 10 {
+	if modal.maxQuantity.String() != "10" {
 		t.Errorf("Expected maxQuantity 10 (lots), got %v", modal.maxQuantity)
 	}
 
 	// Lot info should be displayed
-	if modal.GetLotSize() != 10 {
+	if modal.GetLotSize().String() != "10" {
 		t.Errorf("Expected lot size 10, got %v", modal.GetLotSize())
 	}
 }
@@ -138,7 +140,7 @@ func TestClosePositionModal_LotValidation(t *testing.T) {
 	modal := NewClosePositionModal(nil, nil, nil, nil)
 
 	// 100 shares, lot size 10 = max 10 lots
-	modal.SetPositionDataWithLots("SBER", 100.0, 250.50, 500.0, 10.0)
+	modal.SetPositionDataWithLots("SBER", models.DecimalFromInt(100), models.DecimalOf("250.50"), models.DecimalFromInt(500), models.DecimalFromInt(10))
 
 	// Valid: 5 lots (within 10 max)
 	modal.quantityField.SetText("5")
@@ -163,7 +165,7 @@ func TestClosePositionModal_DisplayName(t *testing.T) {
 	modal := NewClosePositionModal(nil, nil, nil, nil)
 
 	// With name: title should include the name
-	modal.SetPositionDataWithLots("SBER", 100.0, 250.50, 500.0, 10.0)
+	modal.SetPositionDataWithLots("SBER", models.DecimalFromInt(100), models.DecimalOf("250.50"), models.DecimalFromInt(500), models.DecimalFromInt(10))
 	modal.SetDisplayName("Сбербанк")
 
 	title := modal.Layout.GetTitle()
@@ -173,7 +175,7 @@ func TestClosePositionModal_DisplayName(t *testing.T) {
 
 	// Without name: title should be default
 	modal2 := NewClosePositionModal(nil, nil, nil, nil)
-	modal2.SetPositionDataWithLots("UNKNOWN", 50.0, 100.0, 0.0, 1.0)
+	modal2.SetPositionDataWithLots("UNKNOWN", models.DecimalFromInt(50), models.DecimalFromInt(100), models.DecimalFromInt(0), models.DecimalFromInt(1))
 	modal2.SetDisplayName("")
 
 	title2 := modal2.Layout.GetTitle()
@@ -185,7 +187,7 @@ func TestClosePositionModal_DisplayName(t *testing.T) {
 func TestClosePositionModal_LotInfoText(t *testing.T) {
 	modal := NewClosePositionModal(nil, nil, nil, nil)
 
-	modal.SetPositionDataWithLots("SBER", 100.0, 250.50, 500.0, 10.0)
+	modal.SetPositionDataWithLots("SBER", models.DecimalFromInt(100), models.DecimalOf("250.50"), models.DecimalFromInt(500), models.DecimalFromInt(10))
 
 	// Quantity label should show lot size
 	label := modal.quantityField.GetLabel()
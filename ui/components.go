@@ -27,18 +27,30 @@ const (
 	TabPositions TabType = iota
 	TabHistory
 	TabOrders
+	TabWatchlists
+	TabAlerts
+	TabPnL
+	TabTransactions
 )
 
-// TabbedView manages a tabbed interface for positions, history, and orders
+// tabCount is the number of tabs in the tabbed view
+const tabCount = 7
+
+// TabbedView manages a tabbed interface for positions, history, orders, watchlists, alerts,
+// realized P&L and cash transactions
 type TabbedView struct {
 	*tview.Flex
 	ActiveTab TabType
 
-	PositionsTable *tview.Table
-	HistoryTable   *tview.Table
-	OrdersTable    *tview.Table
-	Content        *tview.Pages // To switch between tables
-	Header         *tview.TextView
+	PositionsTable    *tview.Table
+	HistoryTable      *tview.Table
+	OrdersTable       *tview.Table
+	WatchlistTable    *tview.Table
+	AlertsTable       *tview.Table
+	PnLTable          *tview.Table
+	TransactionsTable *tview.Table
+	Content           *tview.Pages // To switch between tables
+	Header            *tview.TextView
 }
 
 // NewPortfolioView creates a new PortfolioView component
@@ -65,13 +77,17 @@ func NewPortfolioView(app *tview.Application) *PortfolioView {
 // NewTabbedView creates a new TabbedView component
 func NewTabbedView() *TabbedView {
 	tv := &TabbedView{
-		Flex:           tview.NewFlex().SetDirection(tview.FlexRow),
-		ActiveTab:      TabPositions,
-		PositionsTable: createPositionsTable(),
-		HistoryTable:   createHistoryTable(),
-		OrdersTable:    createOrdersTable(),
-		Content:        tview.NewPages(),
-		Header:         tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter),
+		Flex:              tview.NewFlex().SetDirection(tview.FlexRow),
+		ActiveTab:         TabPositions,
+		PositionsTable:    createPositionsTable(),
+		HistoryTable:      createHistoryTable(),
+		OrdersTable:       createOrdersTable(),
+		WatchlistTable:    createWatchlistTable(),
+		AlertsTable:       createAlertsTable(),
+		PnLTable:          createPnLTable(),
+		TransactionsTable: createTransactionsTable(),
+		Content:           tview.NewPages(),
+		Header:            tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter),
 	}
 
 	tv.Header.SetBackgroundColor(tcell.ColorBlack)
@@ -79,6 +95,10 @@ func NewTabbedView() *TabbedView {
 	tv.Content.AddPage("positions", tv.PositionsTable, true, true)
 	tv.Content.AddPage("history", tv.HistoryTable, true, false)
 	tv.Content.AddPage("orders", tv.OrdersTable, true, false)
+	tv.Content.AddPage("watchlists", tv.WatchlistTable, true, false)
+	tv.Content.AddPage("alerts", tv.AlertsTable, true, false)
+	tv.Content.AddPage("pnl", tv.PnLTable, true, false)
+	tv.Content.AddPage("transactions", tv.TransactionsTable, true, false)
 
 	tv.AddItem(tv.Header, 1, 0, false)
 	tv.AddItem(tv.Content, 0, 1, true)
@@ -89,7 +109,7 @@ func NewTabbedView() *TabbedView {
 
 // UpdateHeader updates the visual representation of tabs
 func (tv *TabbedView) UpdateHeader() {
-	tabs := []string{" Positions ", " History ", " Orders "}
+	tabs := []string{" Positions ", " History ", " Orders ", " Watchlists ", " Alerts ", " P&L ", " Transactions "}
 	var headerText strings.Builder
 	for i, tab := range tabs {
 		if TabType(i) == tv.ActiveTab {
@@ -114,10 +134,38 @@ func (tv *TabbedView) SetTab(tab TabType) {
 		tv.Content.SwitchToPage("history")
 	case TabOrders:
 		tv.Content.SwitchToPage("orders")
+	case TabWatchlists:
+		tv.Content.SwitchToPage("watchlists")
+	case TabAlerts:
+		tv.Content.SwitchToPage("alerts")
+	case TabPnL:
+		tv.Content.SwitchToPage("pnl")
+	case TabTransactions:
+		tv.Content.SwitchToPage("transactions")
 	}
 	tv.UpdateHeader()
 }
 
+// ActiveTable returns the table of the active tab
+func (tv *TabbedView) ActiveTable() *tview.Table {
+	switch tv.ActiveTab {
+	case TabHistory:
+		return tv.HistoryTable
+	case TabOrders:
+		return tv.OrdersTable
+	case TabWatchlists:
+		return tv.WatchlistTable
+	case TabAlerts:
+		return tv.AlertsTable
+	case TabPnL:
+		return tv.PnLTable
+	case TabTransactions:
+		return tv.TransactionsTable
+	default:
+		return tv.PositionsTable
+	}
+}
+
 // UpdateAccounts populates the account table with two rows per account
 func (pv *PortfolioView) UpdateAccounts(accounts []models.AccountInfo) {
 	pv.AccountTable.Clear()
@@ -177,9 +225,9 @@ func (pv *PortfolioView) UpdatePositions(positions []models.Position) {
 
 		pv.TabbedView.PositionsTable.SetCell(i+1, 0, tview.NewTableCell(pos.Symbol).SetTextColor(tcell.ColorWhite))
 		pv.TabbedView.PositionsTable.SetCell(i+1, 1, tview.NewTableCell(displayQty).SetTextColor(tcell.ColorWhite))
-		pv.TabbedView.PositionsTable.SetCell(i+1, 2, tview.NewTableCell(pos.AveragePrice).SetTextColor(tcell.ColorWhite))
-		pv.TabbedView.PositionsTable.SetCell(i+1, 3, tview.NewTableCell(pos.CurrentPrice).SetTextColor(tcell.ColorWhite))
-		pv.TabbedView.PositionsTable.SetCell(i+1, 4, tview.NewTableCell(pos.UnrealizedPnL).SetTextColor(tcell.ColorWhite))
+		pv.TabbedView.PositionsTable.SetCell(i+1, 2, tview.NewTableCell(pos.AveragePrice.String()).SetTextColor(tcell.ColorWhite))
+		pv.TabbedView.PositionsTable.SetCell(i+1, 3, tview.NewTableCell(pos.CurrentPrice.String()).SetTextColor(tcell.ColorWhite))
+		pv.TabbedView.PositionsTable.SetCell(i+1, 4, tview.NewTableCell(pos.UnrealizedPnL.String()).SetTextColor(tcell.ColorWhite))
 	}
 }
 
@@ -222,6 +270,30 @@ func headerVersionLabel() string {
 	return v
 }
 
+// SetPaperMode marks the header so a simulated session cannot be mistaken for a real account.
+func (a *App) SetPaperMode() {
+	a.paperMode = true
+	updateHeader(a)
+}
+
+// updateHeader redraws the header for the session state. A trading lock outranks the
+// paper trading label and stays in red until the lock resets.
+func updateHeader(app *App) {
+	text := fmt.Sprintf(" Finam Terminal %s ", headerVersionLabel())
+	bg, fg := tcell.ColorDarkCyan, tcell.ColorWhite
+	if app.paperMode {
+		text = fmt.Sprintf(" Finam Terminal %s — PAPER TRADING ", headerVersionLabel())
+		bg, fg = tcell.ColorDarkGoldenrod, tcell.ColorBlack
+	}
+	if reason, locked := app.risk.Locked(); locked {
+		text = fmt.Sprintf("%s— TRADING LOCKED: %s ", text, reason)
+		bg, fg = tcell.ColorDarkRed, tcell.ColorWhite
+	}
+	app.header.SetText(text)
+	app.header.SetBackgroundColor(bg)
+	app.header.SetTextColor(fg)
+}
+
 // createAccountList creates the account list panel
 func createAccountList() *tview.List {
 	list := tview.NewList()
@@ -264,6 +336,50 @@ func createOrdersTable() *tview.Table {
 	return table
 }
 
+// createWatchlistTable creates the watchlists table
+func createWatchlistTable() *tview.Table {
+	table := tview.NewTable()
+	table.SetBorder(true)
+	table.SetTitle(" Watchlist ")
+	table.SetBackgroundColor(tcell.ColorBlack)
+	table.SetSelectable(true, false)
+	table.SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack))
+	return table
+}
+
+// createAlertsTable creates the alerts table
+func createAlertsTable() *tview.Table {
+	table := tview.NewTable()
+	table.SetBorder(true)
+	table.SetTitle(" Alerts ")
+	table.SetBackgroundColor(tcell.ColorBlack)
+	table.SetSelectable(true, false)
+	table.SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack))
+	return table
+}
+
+// createPnLTable creates the realized P&L table
+func createPnLTable() *tview.Table {
+	table := tview.NewTable()
+	table.SetBorder(true)
+	table.SetTitle(" P&L ")
+	table.SetBackgroundColor(tcell.ColorBlack)
+	table.SetSelectable(true, false)
+	table.SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack))
+	return table
+}
+
+// createTransactionsTable creates the cash transactions table
+func createTransactionsTable() *tview.Table {
+	table := tview.NewTable()
+	table.SetBorder(true)
+	table.SetTitle(" Transactions ")
+	table.SetBackgroundColor(tcell.ColorBlack)
+	table.SetSelectable(true, false)
+	table.SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack))
+	return table
+}
+
 // createInfoLabel creates the info panel
 func createInfoLabel() *tview.TextView {
 	label := tview.NewTextView()
@@ -0,0 +1,320 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"finam-terminal/conditional"
+	"finam-terminal/models"
+	"finam-terminal/risk"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// SetConditionalStore loads the conditional orders saved in path and keeps saving them
+// there; triggers are appended to auditPath. Without a store, conditional orders last until
+// the terminal exits.
+func (a *App) SetConditionalStore(path, auditPath string) error {
+	m := conditional.NewManager(path, auditPath)
+	if err := m.Load(); err != nil {
+		return err
+	}
+	a.conditional = m
+	if n := len(m.Orders("")); n > 0 {
+		log.Printf("[INFO] Resumed %d conditional orders from %s", n, path)
+	}
+	return nil
+}
+
+// placeConditional arms a conditional order from sub. Nothing is sent to the broker until
+// its condition is met.
+func (a *App) placeConditional(accountID string, sub OrderSubmission) (string, error) {
+	o, err := a.conditional.Add(conditional.Order{
+		AccountID:  accountID,
+		Symbol:     sub.Instrument,
+		Condition:  sub.Condition,
+		Side:       sub.Direction,
+		Quantity:   sub.Quantity,
+		LimitPrice: sub.LimitPrice,
+		Reference:  a.conditionPrice(accountID, sub.Instrument, sub.Condition.Field),
+	})
+	if err != nil {
+		log.Printf("[ERROR] Conditional order on %s will not survive a restart: %v", sub.Instrument, err)
+	}
+	log.Printf("[INFO] Conditional order %s armed: when %s of %s, %s", o.ID, o.Condition, o.Symbol, o.Action())
+	return o.ID, nil
+}
+
+// conditionPrice returns the current value of field for symbol, or 0 when it is unknown.
+func (a *App) conditionPrice(accountID, symbol, field string) models.Decimal {
+	if field == conditional.FieldLast {
+		return a.lastPrice(accountID, symbol)
+	}
+	snapshots, err := a.client.GetSnapshots(accountID, []string{symbol})
+	if err != nil {
+		return models.Decimal{}
+	}
+	q, ok := snapshots[symbol]
+	if !ok {
+		return models.Decimal{}
+	}
+	return quoteField(q, field)
+}
+
+// quoteField returns the price of q watched by a condition on field, or 0 when it is
+// unknown.
+func quoteField(q models.Quote, field string) models.Decimal {
+	price := q.Last
+	switch field {
+	case conditional.FieldBid:
+		price = q.Bid
+	case conditional.FieldAsk:
+		price = q.Ask
+	}
+	if !price.Known() {
+		return models.Decimal{}
+	}
+	return price
+}
+
+// evaluateConditionals checks the conditional orders against streamed quotes and sends the
+// orders whose condition was met. Must be called on the UI thread.
+func (a *App) evaluateConditionals(batch map[string]models.Quote) {
+	observed := make(map[string]bool)
+	triggered := false
+	for _, o := range a.conditional.Orders("") {
+		if o.Status != conditional.StatusWaiting || observed[o.Symbol] {
+			continue
+		}
+		observed[o.Symbol] = true
+		for _, q := range batch {
+			if !symbolMatches(q.Symbol, o.Symbol) {
+				continue
+			}
+			fired, err := a.conditional.Observe(o.Symbol, map[string]models.Decimal{
+				conditional.FieldLast: quoteField(q, conditional.FieldLast),
+				conditional.FieldBid:  quoteField(q, conditional.FieldBid),
+				conditional.FieldAsk:  quoteField(q, conditional.FieldAsk),
+			})
+			if err != nil {
+				log.Printf("[ERROR] %v", err)
+			}
+			for _, c := range fired {
+				triggered = true
+				go a.sendConditional(c)
+			}
+			break
+		}
+	}
+	if triggered {
+		updateOrdersTable(a)
+	}
+}
+
+// sendConditional places the order of a triggered conditional order. The pre-trade checks
+// run again without the override confirmed when it was armed, so a violation at trigger
+// time, a locked account or a tripped kill switch fails it.
+func (a *App) sendConditional(c conditional.Order) {
+	log.Printf("[AUDIT] Conditional order %s triggered: %s of %s, sending %s", c.ID, c.Condition, c.Symbol, c.Action())
+
+	sub := OrderSubmission{
+		Instrument: c.Symbol,
+		Quantity:   c.Quantity,
+		Direction:  c.Side,
+		OrderType:  models.OrderTypeMarket,
+	}
+	if c.LimitPrice.Sign() > 0 {
+		sub.OrderType = models.OrderTypeLimit
+		sub.LimitPrice = c.LimitPrice
+	}
+
+	id, err := "", a.checkOrderRisk(c.AccountID, sub)
+	if err == nil {
+		id, err = a.sendSubmission(c.AccountID, sub, "")
+	}
+	if err != nil {
+		cause := err
+		var rerr *risk.Error
+		if !errors.As(err, &rerr) {
+			cause = errors.New(extractUserMessage(err))
+		}
+		if ferr := a.conditional.Failed(c.ID, cause); ferr != nil {
+			log.Printf("[ERROR] %v", ferr)
+		}
+		log.Printf("[AUDIT] Conditional order %s failed: %v", c.ID, err)
+		a.SetStatus(fmt.Sprintf("Conditional %s on %s not sent: %v", c.ID, c.Symbol, cause), StatusError)
+		a.app.QueueUpdateDraw(func() { updateOrdersTable(a) })
+		return
+	}
+
+	a.recordOrder(c.AccountID)
+	if err := a.conditional.Sent(c.ID, id); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+	log.Printf("[AUDIT] Conditional order %s sent as order %s", c.ID, id)
+	a.SetStatus(fmt.Sprintf("Conditional %s triggered: %s %s sent", c.ID, c.Side, c.Symbol), StatusSuccess)
+	a.loadOrdersAsync(c.AccountID)
+}
+
+// selectedConditional returns the conditional order selected in the Orders table. Its rows
+// follow the broker orders.
+func (a *App) selectedConditional() (conditional.Order, bool) {
+	row, _ := a.portfolioView.TabbedView.OrdersTable.GetSelection()
+
+	a.dataMutex.RLock()
+	if a.selectedIdx < 0 || a.selectedIdx >= len(a.accounts) {
+		a.dataMutex.RUnlock()
+		return conditional.Order{}, false
+	}
+	accountID := a.accounts[a.selectedIdx].ID
+	idx := row - 1 - len(a.activeOrders[accountID])
+	a.dataMutex.RUnlock()
+
+	orders := a.conditional.Orders(accountID)
+	if idx < 0 || idx >= len(orders) {
+		return conditional.Order{}, false
+	}
+	return orders[idx], true
+}
+
+// showCancelConditional asks to disarm the conditional order c.
+func (a *App) showCancelConditional(c conditional.Order) {
+	text := fmt.Sprintf("Cancel conditional %s: when %s of %s, %s?", c.ID, c.Condition, c.Symbol, c.Action())
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Yes", "No"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("cancel_confirm")
+			a.app.SetFocus(a.portfolioView.TabbedView.OrdersTable)
+			if buttonLabel == "Yes" {
+				a.cancelConditional(c.ID)
+			}
+		})
+	a.pages.AddPage("cancel_confirm", modal, false, true)
+}
+
+// cancelConditional disarms the conditional order id. Must be called on the UI thread.
+func (a *App) cancelConditional(id string) {
+	if err := a.conditional.Remove(id); err != nil {
+		if errors.Is(err, conditional.ErrSending) {
+			a.SetStatus(fmt.Sprintf("Conditional %s is being sent", id), StatusError)
+			return
+		}
+		log.Printf("[ERROR] %v", err)
+	}
+	log.Printf("[INFO] Conditional order %s cancelled", id)
+	updateOrdersTable(a)
+	a.SetStatus(fmt.Sprintf("Conditional %s cancelled", id), StatusSuccess)
+}
+
+// showModifyConditional opens the order modal pre-filled with the conditional order c. On
+// submit the order is re-armed with the new condition; a failed order is armed again.
+func (a *App) showModifyConditional(c conditional.Order) {
+	if c.Status == conditional.StatusSending {
+		a.SetStatus(fmt.Sprintf("Conditional %s is being sent", c.ID), StatusError)
+		return
+	}
+
+	a.orderModal.SetInstrument(c.Symbol)
+	a.orderModal.SetDirection(c.Side)
+	a.orderModal.LimitOrderTypes([]string{models.OrderTypeConditional})
+	a.orderModal.SetLotSize(a.client.GetLotSize(c.Symbol))
+	a.loadOrderPriceStep(c.AccountID, c.Symbol)
+	a.orderModal.SetQuantity(c.Quantity)
+	a.orderModal.SetCondition(c.Condition)
+	a.orderModal.SetLimitPrice(c.LimitPrice)
+	a.orderModal.SetModifyTitle(c.ID + " " + c.Symbol)
+
+	a.orderModal.SetCallback(func(sub OrderSubmission) {
+		if sub.OrderType != models.OrderTypeConditional {
+			a.SetStatus("A conditional order stays conditional; cancel it to place another type", StatusError)
+			return
+		}
+		if err := a.checkOrderRisk(c.AccountID, sub); err != nil {
+			var rerr *risk.Error
+			if errors.As(err, &rerr) {
+				retry := a.orderModal.GetCallback()
+				a.ShowRiskViolation(rerr, func() {
+					sub.OverrideRisk = true
+					retry(sub)
+				})
+			}
+			return
+		}
+		a.orderModal.RestoreCallback()
+
+		updated := c
+		updated.Symbol = sub.Instrument
+		updated.Condition = sub.Condition
+		updated.Side = sub.Direction
+		updated.Quantity = sub.Quantity
+		updated.LimitPrice = sub.LimitPrice
+		updated.Reference = a.conditionPrice(c.AccountID, sub.Instrument, sub.Condition.Field)
+		if _, err := a.conditional.Update(updated); err != nil {
+			log.Printf("[ERROR] %v", err)
+			if errors.Is(err, conditional.ErrSending) {
+				a.ShowError(fmt.Sprintf("Conditional %s was triggered and is being sent", c.ID))
+				return
+			}
+			a.ShowError(fmt.Sprintf("Failed to modify conditional %s: %v", c.ID, err))
+			return
+		}
+		log.Printf("[INFO] Conditional order %s modified: when %s of %s, %s", c.ID, updated.Condition, updated.Symbol, updated.Action())
+		a.SetStatus(fmt.Sprintf("Conditional %s modified", c.ID), StatusSuccess)
+		a.CloseOrderModal()
+		updateOrdersTable(a)
+		a.updateQuoteSubscription()
+	})
+
+	a.pages.ShowPage("modal")
+	a.app.SetFocus(a.orderModal.Form)
+}
+
+// renderConditionalRows adds the conditional orders of accountID to the Orders table from
+// row on.
+func renderConditionalRows(app *App, accountID string, row int) {
+	table := app.portfolioView.TabbedView.OrdersTable
+	for i, c := range app.conditional.Orders(accountID) {
+		rowNum := row + i
+		rowBg := tcell.ColorBlack
+		if (rowNum-1)%2 == 0 {
+			rowBg = tcell.ColorDarkGray
+		}
+		style := tcell.StyleDefault.Background(rowBg)
+
+		sideColor := tcell.ColorGreen
+		if c.Side == "Sell" {
+			sideColor = tcell.ColorRed
+		}
+		statusColor := tcell.ColorLightCyan
+		if c.Status == conditional.StatusFailed {
+			statusColor = tcell.ColorRed
+		}
+
+		condition := c.Condition.Short() + " → "
+		if c.LimitPrice.Sign() > 0 {
+			condition += c.LimitPrice.String()
+		} else {
+			condition += "Market"
+		}
+
+		cells := []*tview.TableCell{
+			tview.NewTableCell(c.Symbol).SetStyle(style.Foreground(tcell.ColorLightYellow)).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(c.Side).SetStyle(style.Foreground(sideColor)),
+			tview.NewTableCell(models.OrderTypeConditional).SetStyle(style.Foreground(tcell.ColorWhite)),
+			tview.NewTableCell(c.Status).SetStyle(style.Foreground(statusColor)),
+			tview.NewTableCell(c.Quantity.String()).SetStyle(style.Foreground(tcell.ColorWhite)),
+			tview.NewTableCell("").SetStyle(style),
+			tview.NewTableCell(condition).SetStyle(style.Foreground(tcell.ColorWhite)),
+			tview.NewTableCell(c.Created.Format("01-02 15:04")).SetStyle(style.Foreground(tcell.ColorWhite)),
+		}
+		for col, cell := range cells {
+			if col > 0 {
+				cell.SetAlign(tview.AlignRight)
+			}
+			table.SetCell(rowNum, col, cell)
+		}
+	}
+}
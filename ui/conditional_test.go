@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"finam-terminal/conditional"
+	"finam-terminal/models"
+)
+
+func TestConditional_SendsOrderWhenPriceCrosses(t *testing.T) {
+	placed := make(chan *models.OrderParams, 2)
+	mockClient := &mockClient{
+		PlaceOrderFunc: func(accountID, symbol, side string, qty models.Decimal, p *models.OrderParams) (string, error) {
+			if symbol != "SBER@MISX" || side != "Buy" || qty.Float64() != 5 {
+				t.Errorf("Unexpected order %s %s %v", side, symbol, qty)
+			}
+			placed <- p
+			return "ord1", nil
+		},
+		GetSnapshotsFunc: func(accountID string, symbols []string) (map[string]models.Quote, error) {
+			return map[string]models.Quote{"SBER@MISX": {Symbol: "SBER@MISX", Last: models.DecimalOf("305")}}, nil
+		},
+	}
+	app := NewApp(mockClient, []models.AccountInfo{{ID: "acc1"}})
+
+	err := app.SubmitOrder(OrderSubmission{
+		Instrument: "SBER@MISX",
+		Quantity:   models.DecimalFromInt(5),
+		Direction:  "Buy",
+		OrderType:  models.OrderTypeConditional,
+		LimitPrice: models.DecimalOf("310.5"),
+		Condition:  conditional.Condition{Field: conditional.FieldLast, Above: true, Price: models.DecimalFromInt(310)},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	orders := app.conditional.Orders("acc1")
+	if len(orders) != 1 || !orders[0].Reference.Equal(models.DecimalFromInt(305)) || orders[0].Status != conditional.StatusWaiting {
+		t.Fatalf("Expected a waiting conditional order armed at 305, got %+v", orders)
+	}
+
+	app.applyQuotes(map[string]models.Quote{"SBER@MISX": {Symbol: "SBER@MISX", Last: models.DecimalOf("309.9")}})
+	select {
+	case p := <-placed:
+		t.Fatalf("Unexpected order below the level: %+v", p)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	app.applyQuotes(map[string]models.Quote{"SBER@MISX": {Symbol: "SBER@MISX", Last: models.DecimalOf("310.2")}})
+	select {
+	case p := <-placed:
+		if p == nil || p.OrderType != models.OrderTypeLimit || p.LimitPrice.Float64() != 310.5 {
+			t.Errorf("Expected a limit order at 310.5, got %+v", p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the order to be sent when LAST crossed 310")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(app.conditional.Orders("acc1")) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the sent conditional order to be forgotten")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestConditional_TriggerRechecksRiskWithoutOverride(t *testing.T) {
+	mockClient := &mockClient{
+		PlaceOrderFunc: func(accountID, symbol, side string, qty models.Decimal, p *models.OrderParams) (string, error) {
+			t.Error("Order must not be sent past the risk checks")
+			return "", nil
+		},
+		GetSnapshotsFunc: func(accountID string, symbols []string) (map[string]models.Quote, error) {
+			return map[string]models.Quote{"SBER@MISX": {Symbol: "SBER@MISX", Last: models.DecimalOf("305")}}, nil
+		},
+	}
+	app := NewApp(mockClient, []models.AccountInfo{{ID: "acc1"}})
+	app.SetRiskLimits(models.RiskLimits{PriceBandPercent: 10, AllowOverride: true})
+
+	// Armed with a confirmed override of the price band
+	err := app.SubmitOrder(OrderSubmission{
+		Instrument:   "SBER@MISX",
+		Quantity:     models.DecimalFromInt(5),
+		Direction:    "Buy",
+		OrderType:    models.OrderTypeConditional,
+		LimitPrice:   models.DecimalFromInt(3105), // an extra digit
+		Condition:    conditional.Condition{Field: conditional.FieldLast, Above: true, Price: models.DecimalFromInt(310)},
+		OverrideRisk: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	app.applyQuotes(map[string]models.Quote{"SBER@MISX": {Symbol: "SBER@MISX", Last: models.DecimalOf("310.2")}})
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		orders := app.conditional.Orders("acc1")
+		if len(orders) == 1 && orders[0].Status == conditional.StatusFailed {
+			if !strings.Contains(orders[0].Error, "risk check failed") {
+				t.Errorf("Expected the violation as the error, got %q", orders[0].Error)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the conditional order to fail at trigger time, got %+v", orders)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestConditional_OrdersTabRows(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	app.activeOrders["acc1"] = []models.Order{{ID: "o1", Symbol: "GAZP", Status: "Active", Type: "Limit"}}
+	c, _ := app.conditional.Add(conditional.Order{
+		AccountID: "acc1", Symbol: "SBER@MISX", Side: "Buy", Quantity: models.DecimalFromInt(5), LimitPrice: models.DecimalOf("310.5"),
+		Condition: conditional.Condition{Field: conditional.FieldLast, Above: true, Price: models.DecimalFromInt(310)},
+	})
+
+	updateOrdersTable(app)
+	table := app.portfolioView.TabbedView.OrdersTable
+
+	if got := table.GetCell(2, 2).Text; got != models.OrderTypeConditional {
+		t.Errorf("Expected a Conditional row after the broker orders, got type %q", got)
+	}
+	if got := table.GetCell(2, 3).Text; got != conditional.StatusWaiting {
+		t.Errorf("Expected status %q, got %q", conditional.StatusWaiting, got)
+	}
+	if got := table.GetCell(2, 6).Text; got != "LAST ↑ 310 → 310.5" {
+		t.Errorf("Unexpected condition %q", got)
+	}
+
+	table.Select(1, 0)
+	if _, ok := app.selectedConditional(); ok {
+		t.Error("Expected the broker order row not to select a conditional order")
+	}
+	table.Select(2, 0)
+	got, ok := app.selectedConditional()
+	if !ok || got.ID != c.ID {
+		t.Fatalf("Expected %s selected, got %+v", c.ID, got)
+	}
+
+	app.cancelConditional(c.ID)
+	if len(app.conditional.Orders("acc1")) != 0 {
+		t.Error("Expected the conditional order to be cancelled")
+	}
+	if table.GetRowCount() != 2 {
+		t.Errorf("Expected only the broker order left, got %d rows", table.GetRowCount())
+	}
+}
+
+func TestConditional_ModifyFailureIsReported(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	setupModalPage(app)
+	c, err := app.conditional.Add(conditional.Order{AccountID: "acc1", Symbol: "SBER@MISX", Side: "Buy",
+		Quantity: models.DecimalFromInt(5), LimitPrice: models.DecimalOf("310.5"),
+		Condition: conditional.Condition{Field: conditional.FieldLast, Above: true, Price: models.DecimalFromInt(310)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.showModifyConditional(c)
+	// Cancelled from elsewhere while the modal was open
+	if err := app.conditional.Remove(c.ID); err != nil {
+		t.Fatal(err)
+	}
+	app.orderModal.GetCallback()(app.orderModal.buildSubmission())
+
+	if !app.pages.HasPage("alert") {
+		t.Error("Expected the failure to be shown")
+	}
+	app.dataMutex.RLock()
+	msg := app.statusMessage
+	app.dataMutex.RUnlock()
+	if strings.Contains(msg, "modified") {
+		t.Errorf("Expected no success status, got %q", msg)
+	}
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"finam-terminal/models"
+	"finam-terminal/tradestore"
 	"log"
 	"strings"
 	"sync"
@@ -33,30 +34,15 @@ func (a *App) loadDataAsync(accountID string) {
 			return
 		}
 
-		var finalQuotes map[string]*models.Quote
-		if len(pos) > 0 {
-			symbols := make([]string, len(pos))
-			for i, p := range pos {
-				symbols[i] = p.Symbol
-			}
-			quotes, err := a.client.GetQuotes(accountID, symbols)
-			if err != nil {
-				log.Printf("[WARN] Failed to get quotes for %s: %v", accountID, err)
-				a.SetStatus("Data loaded (Quotes failed)", StatusSuccess)
-			} else {
-				finalQuotes = quotes
-				a.SetStatus("Data updated", StatusSuccess)
-			}
-		} else {
-			a.SetStatus("Data updated", StatusSuccess)
-		}
+		// Quotes arrive through the quote stream (see quotes.go), not from this poll
+		a.SetStatus("Data updated", StatusSuccess)
 
 		// Clear status after some time
 		time.AfterFunc(3*time.Second, func() {
 			a.dataMutex.RLock()
 			currentMsg := a.statusMessage
 			a.dataMutex.RUnlock()
-			if currentMsg == "Data updated" || currentMsg == "Data loaded (Quotes failed)" {
+			if currentMsg == "Data updated" {
 				a.SetStatus("", StatusInfo)
 			}
 		})
@@ -64,12 +50,18 @@ func (a *App) loadDataAsync(accountID string) {
 		// Schedule a UI update on the main thread
 		a.app.QueueUpdateDraw(func() {
 			a.dataMutex.Lock()
-			a.positions[accountID] = pos
-			if finalQuotes != nil {
-				a.quotes[accountID] = finalQuotes
-			} else {
-				a.quotes[accountID] = make(map[string]*models.Quote)
+			// Keep streamed quotes for symbols still held and show their last price
+			quotes := make(map[string]*models.Quote, len(pos))
+			for i := range pos {
+				if q, ok := a.quotes[accountID][pos[i].Symbol]; ok {
+					quotes[pos[i].Symbol] = q
+					if q.Last.Known() {
+						pos[i].CurrentPrice = q.Last
+					}
+				}
 			}
+			a.positions[accountID] = pos
+			a.quotes[accountID] = quotes
 			// Update account info (Equity, UnrealizedPnL) with fresh data from API
 			if accInfo != nil {
 				for i := range a.accounts {
@@ -82,6 +74,10 @@ func (a *App) loadDataAsync(accountID string) {
 			}
 			a.dataMutex.Unlock()
 
+			if accInfo != nil {
+				a.trackDailyLoss(accountID, accInfo.DailyPnL)
+			}
+
 			// If the data for the currently viewed account is updated, refresh the view.
 			if a.selectedIdx < len(a.accounts) && a.accounts[a.selectedIdx].ID == accountID {
 				updateAccountList(a)
@@ -89,6 +85,9 @@ func (a *App) loadDataAsync(accountID string) {
 				updateInfoPanel(a)
 				updateStatusBar(a)
 			}
+
+			// Positions may have opened or closed: follow them with the quote stream
+			a.updateQuoteSubscription()
 		})
 	}()
 }
@@ -101,13 +100,16 @@ func (a *App) loadHistoryAsync(accountID string) {
 		}
 	}
 	a.SetStatus("Loading History...", StatusLoading)
+	to := time.Now()
+	from := to.AddDate(0, 0, -a.historyDays)
 	go func() {
-		history, err := a.client.GetTradeHistory(accountID)
-		if err != nil {
+		// Only the periods not in the trade store are fetched
+		if _, err := a.tradeStore.Sync(accountID, from, to, a.client.GetTradeHistoryRange); err != nil {
 			log.Printf("[WARN] Failed to load history for %s: %v", accountID, err)
 			a.SetStatus("Error loading history", StatusError)
 			return
 		}
+		history := a.tradeStore.Trades(accountID, tradestore.Filter{})
 
 		a.dataMutex.Lock()
 		a.history[accountID] = history
@@ -116,6 +118,7 @@ func (a *App) loadHistoryAsync(accountID string) {
 		a.app.QueueUpdateDraw(func() {
 			if a.selectedIdx < len(a.accounts) && a.accounts[a.selectedIdx].ID == accountID {
 				updateHistoryTable(a)
+				updatePnLTable(a)
 				a.SetStatus("History updated", StatusSuccess)
 			}
 		})
@@ -138,19 +141,32 @@ func (a *App) loadOrdersAsync(accountID string) {
 			return
 		}
 
-		a.dataMutex.Lock()
-		a.activeOrders[accountID] = orders
-		a.dataMutex.Unlock()
-
 		a.app.QueueUpdateDraw(func() {
+			a.setLoadedOrders(accountID, orders)
 			if a.selectedIdx < len(a.accounts) && a.accounts[a.selectedIdx].ID == accountID {
 				updateOrdersTable(a)
+				a.refreshProfileOwnOrders()
 				a.SetStatus("Orders updated", StatusSuccess)
 			}
 		})
 	}()
 }
 
+// setLoadedOrders replaces the order list of accountID with orders loaded from the API.
+// While the orders stream is up, finished orders are dropped once the terminal-managed
+// orders have seen them, so a reload does not bring back orders the stream removed. Must
+// be called on the UI thread.
+func (a *App) setLoadedOrders(accountID string, orders []models.Order) {
+	a.dataMutex.Lock()
+	a.activeOrders[accountID] = orders
+	a.dataMutex.Unlock()
+
+	a.trackOrders(accountID)
+	if a.ordersStreaming(accountID) {
+		a.dropFinishedOrders(accountID)
+	}
+}
+
 // loadProfileAsync loads all profile data in parallel goroutines.
 func (a *App) loadProfileAsync(accountID, symbol string, timeframeIdx int) {
 	go func() {
@@ -224,12 +240,32 @@ func (a *App) loadProfileAsync(accountID, symbol string, timeframeIdx int) {
 			mu.Unlock()
 		})
 
+		// 6. GetActiveOrders — our working orders are marked in the order book
+		var orders []models.Order
+		ordersLoaded := false
+		wg.Go(func() {
+			o, err := a.client.GetActiveOrders(accountID)
+			if err != nil {
+				log.Printf("[WARN] GetActiveOrders failed for profile %s: %v", symbol, err)
+				return
+			}
+			orders = o
+			ordersLoaded = true
+		})
+
 		wg.Wait()
 
+		if ordersLoaded {
+			a.dataMutex.Lock()
+			a.activeOrders[accountID] = orders
+			a.dataMutex.Unlock()
+		}
+
 		a.app.QueueUpdateDraw(func() {
 			if a.profileOpen && a.profileSymbol == symbol {
 				a.profilePanel.Update(profile)
 				a.profilePanel.RestoreFooter()
+				a.refreshProfileOwnOrders()
 			}
 		})
 	}()
@@ -256,59 +292,8 @@ func (a *App) loadProfileBarsAsync(accountID, symbol string, timeframeIdx int) {
 	}()
 }
 
-// refreshProfileQuoteAndBars refreshes only quote and bars for an open profile.
-func (a *App) refreshProfileQuoteAndBars(accountID, symbol string, timeframeIdx int) {
-	go func() {
-		var wg sync.WaitGroup
-		var newQuote *models.Quote
-		var newBars []models.Bar
-		var mu sync.Mutex
-
-		wg.Go(func() {
-			quotes, err := a.client.GetQuotes(accountID, []string{symbol})
-			if err != nil {
-				return
-			}
-			mu.Lock()
-			for _, q := range quotes {
-				newQuote = q
-				break
-			}
-			mu.Unlock()
-		})
-
-		wg.Go(func() {
-			now := time.Now()
-			tf := profileTimeframeEnums[timeframeIdx]
-			from := now.Add(-profileTimeframeDurations[timeframeIdx])
-			bars, err := a.client.GetBars(accountID, symbol, tf, from, now)
-			if err != nil {
-				return
-			}
-			mu.Lock()
-			newBars = bars
-			mu.Unlock()
-		})
-
-		wg.Wait()
-
-		a.app.QueueUpdateDraw(func() {
-			if a.profileOpen && a.profileSymbol == symbol {
-				if p := a.profilePanel.GetProfile(); p != nil {
-					if newQuote != nil {
-						p.Quote = newQuote
-					}
-					if newBars != nil {
-						p.Bars = newBars
-					}
-					a.profilePanel.Update(p)
-				}
-			}
-		})
-	}()
-}
-
-// backgroundRefresh runs periodic data refresh
+// backgroundRefresh runs periodic refresh of account data and profile bars.
+// Quotes are not polled here: they are pushed by the quote stream.
 func (a *App) backgroundRefresh() {
 	// Initial refresh immediately
 	time.Sleep(500 * time.Millisecond) // Give more time for UI start
@@ -326,6 +311,8 @@ func (a *App) backgroundRefresh() {
 		case <-a.stopChan:
 			return
 		case <-ticker.C:
+			a.expireOrders(time.Now())
+
 			// access UI state (selectedIdx) safely on the UI thread
 			a.app.QueueUpdateDraw(func() {
 				// Prioritize the active account
@@ -333,9 +320,9 @@ func (a *App) backgroundRefresh() {
 					activeID := a.accounts[a.selectedIdx].ID
 					a.loadDataAsync(activeID)
 
-					// Refresh profile if open
+					// Refresh profile bars if open; its quote is streamed
 					if a.profileOpen && a.profileSymbol != "" {
-						a.refreshProfileQuoteAndBars(activeID, a.profileSymbol, a.profileTimeframe)
+						a.loadProfileBarsAsync(activeID, a.profileSymbol, a.profileTimeframe)
 					}
 
 					// Refresh others
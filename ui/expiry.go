@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"finam-terminal/models"
+
+	"finam-terminal/atomicfile"
+)
+
+// orderExpiry is the end of a GTD order's validity. The order request has no expiry date,
+// so the terminal remembers it and cancels the order itself.
+type orderExpiry struct {
+	AccountID  string    `json:"account_id"`
+	ValidUntil time.Time `json:"valid_until"` // Last day the order works
+}
+
+// SetExpiryStore loads the expiries of GTD orders from path and saves them there from now on,
+// so orders placed in an earlier session are still cancelled after their last day.
+func (a *App) SetExpiryStore(path string) error {
+	a.expiryMu.Lock()
+	defer a.expiryMu.Unlock()
+	a.expiryPath = path
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read GTD order expiries: %w", err)
+	}
+	expiries := make(map[string]orderExpiry)
+	if err := json.Unmarshal(data, &expiries); err != nil {
+		return fmt.Errorf("failed to parse GTD order expiries %s: %w", path, err)
+	}
+	for id, e := range expiries {
+		a.orderExpiry[id] = e
+	}
+	if n := len(expiries); n > 0 {
+		log.Printf("[INFO] Watching the expiry of %d GTD orders from %s", n, path)
+	}
+	return nil
+}
+
+// trackExpiry remembers the expiry of the GTD order id placed from sub on accountID,
+// replacing the entry of the order it replaced.
+func (a *App) trackExpiry(accountID, id, replacedID string, sub OrderSubmission) {
+	tracked := sub.Validity == models.ValidityGTD && !sub.ValidUntil.IsZero() && id != ""
+	if !tracked && replacedID == "" {
+		return
+	}
+	a.expiryMu.Lock()
+	defer a.expiryMu.Unlock()
+	if replacedID != "" {
+		delete(a.orderExpiry, replacedID)
+	}
+	if tracked {
+		a.orderExpiry[id] = orderExpiry{AccountID: accountID, ValidUntil: sub.ValidUntil}
+	}
+	a.saveExpiries()
+}
+
+// orderValidUntil returns the last day of the GTD order id, or the zero time when the
+// terminal did not place it.
+func (a *App) orderValidUntil(id string) time.Time {
+	a.expiryMu.Lock()
+	defer a.expiryMu.Unlock()
+	return a.orderExpiry[id].ValidUntil
+}
+
+// expireOrders cancels the GTD orders whose last day ended before now. An order the loaded
+// orders show as finished is forgotten; any other one is cancelled by ID, including orders
+// missing from the list. The order streams remove cancelled orders from the Orders tab.
+func (a *App) expireOrders(now time.Time) {
+	a.expiryMu.Lock()
+	var due []string
+	for id, e := range a.orderExpiry {
+		if !now.Before(e.ValidUntil.AddDate(0, 0, 1)) {
+			due = append(due, id)
+		}
+	}
+	a.expiryMu.Unlock()
+
+	var cancelled, forgotten int
+	for _, id := range due {
+		a.expiryMu.Lock()
+		e := a.orderExpiry[id]
+		a.expiryMu.Unlock()
+
+		listed, working := a.orderState(e.AccountID, id)
+		if working || !listed {
+			if err := a.client.CancelOrder(e.AccountID, id); err != nil {
+				if listed {
+					// Retried on the next refresh
+					log.Printf("[ERROR] Failed to cancel expired GTD order %s: %v", id, err)
+					continue
+				}
+				// Most likely already finished and dropped from the list
+				log.Printf("[WARN] Failed to cancel expired GTD order %s, which is not in the order list: %v", id, err)
+			} else {
+				log.Printf("[INFO] GTD order %s expired on %s and was cancelled", id, e.ValidUntil.Format(validUntilLayout))
+				cancelled++
+			}
+		}
+
+		a.expiryMu.Lock()
+		delete(a.orderExpiry, id)
+		a.expiryMu.Unlock()
+		forgotten++
+	}
+
+	if forgotten > 0 {
+		a.expiryMu.Lock()
+		a.saveExpiries()
+		a.expiryMu.Unlock()
+	}
+	if cancelled > 0 {
+		a.SetStatus(fmt.Sprintf("%d expired GTD orders cancelled", cancelled), StatusInfo)
+	}
+}
+
+// orderState reports whether the loaded orders of accountID hold the order id and whether
+// it is still working.
+func (a *App) orderState(accountID, id string) (listed, working bool) {
+	a.dataMutex.RLock()
+	defer a.dataMutex.RUnlock()
+	for _, o := range a.activeOrders[accountID] {
+		if o.ID == id {
+			return true, isOrderCancellable(o.Status)
+		}
+	}
+	return false, false
+}
+
+// saveExpiries writes the tracked expiries to the expiry store. The caller holds expiryMu.
+func (a *App) saveExpiries() {
+	if a.expiryPath == "" {
+		return
+	}
+	if err := writeExpiries(a.expiryPath, a.orderExpiry); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+}
+
+func writeExpiries(path string, expiries map[string]orderExpiry) error {
+	data, err := json.MarshalIndent(expiries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.Write(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save GTD order expiries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"finam-terminal/models"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGTDOrder_CancelledAfterValidUntil(t *testing.T) {
+	var params *models.OrderParams
+	var cancelled []string
+	mockClient := &mockClient{
+		PlaceOrderFunc: func(id, sym, side string, qty models.Decimal, p *models.OrderParams) (string, error) {
+			params = p
+			return "ord1", nil
+		},
+		CancelOrderFunc: func(accountID, orderID string) error {
+			cancelled = append(cancelled, orderID)
+			return nil
+		},
+	}
+	app := NewApp(mockClient, []models.AccountInfo{{ID: "acc1"}})
+
+	until := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+	err := app.SubmitOrder(OrderSubmission{
+		Instrument: "SBER",
+		Quantity:   models.DecimalFromInt(1),
+		Direction:  "Sell",
+		OrderType:  models.OrderTypeStop,
+		StopPrice:  models.DecimalFromInt(240),
+		Validity:   models.ValidityGTD,
+		ValidUntil: until,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if params == nil || params.Validity != models.ValidityGTD || !params.ValidUntil.Equal(until) {
+		t.Fatalf("Expected GTD params, got %+v", params)
+	}
+	if !app.orderValidUntil("ord1").Equal(until) {
+		t.Fatal("Expected the expiry of ord1 to be tracked")
+	}
+
+	app.dataMutex.Lock()
+	app.activeOrders["acc1"] = []models.Order{{ID: "ord1", Status: "Active"}}
+	app.dataMutex.Unlock()
+
+	// Still valid during its last day
+	app.expireOrders(until.Add(23 * time.Hour))
+	if len(cancelled) != 0 {
+		t.Fatalf("Expected no cancel on the last valid day, got %v", cancelled)
+	}
+
+	app.expireOrders(until.AddDate(0, 0, 1))
+	if len(cancelled) != 1 || cancelled[0] != "ord1" {
+		t.Fatalf("Expected ord1 to be cancelled, got %v", cancelled)
+	}
+	if !app.orderValidUntil("ord1").IsZero() {
+		t.Error("Expected the expired order to be forgotten")
+	}
+}
+
+func TestGTDOrder_FinishedOrderForgotten(t *testing.T) {
+	mockClient := &mockClient{
+		CancelOrderFunc: func(accountID, orderID string) error {
+			t.Errorf("Unexpected cancel of %s", orderID)
+			return nil
+		},
+	}
+	app := NewApp(mockClient, []models.AccountInfo{{ID: "acc1"}})
+
+	until := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+	app.trackExpiry("acc1", "ord1", "", OrderSubmission{Validity: models.ValidityGTD, ValidUntil: until})
+	app.activeOrders["acc1"] = []models.Order{{ID: "ord1", Status: "Filled"}}
+
+	app.expireOrders(until.AddDate(0, 0, 2))
+	if !app.orderValidUntil("ord1").IsZero() {
+		t.Error("Expected the filled order to be forgotten")
+	}
+}
+
+func TestGTDOrder_UnlistedOrderCancelledByID(t *testing.T) {
+	var cancelled []string
+	mockClient := &mockClient{
+		CancelOrderFunc: func(accountID, orderID string) error {
+			cancelled = append(cancelled, accountID+"/"+orderID)
+			return nil
+		},
+	}
+	app := NewApp(mockClient, []models.AccountInfo{{ID: "acc1"}})
+
+	until := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+	app.trackExpiry("acc1", "ord1", "", OrderSubmission{Validity: models.ValidityGTD, ValidUntil: until})
+
+	// The orders of the account are not loaded yet
+	app.expireOrders(until.AddDate(0, 0, 1))
+	if len(cancelled) != 1 || cancelled[0] != "acc1/ord1" {
+		t.Fatalf("Expected ord1 to be cancelled by ID, got %v", cancelled)
+	}
+	if !app.orderValidUntil("ord1").IsZero() {
+		t.Error("Expected the expired order to be forgotten")
+	}
+}
+
+func TestGTDOrder_ExpiryStoreSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "order-expiries.json")
+	until := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	if err := app.SetExpiryStore(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	app.trackExpiry("acc1", "ord1", "", OrderSubmission{Validity: models.ValidityGTD, ValidUntil: until})
+
+	var cancelled []string
+	restarted := NewApp(&mockClient{
+		CancelOrderFunc: func(accountID, orderID string) error {
+			cancelled = append(cancelled, orderID)
+			return nil
+		},
+	}, []models.AccountInfo{{ID: "acc1"}})
+	if err := restarted.SetExpiryStore(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !restarted.orderValidUntil("ord1").Equal(until) {
+		t.Fatalf("Expected the expiry of ord1 to be reloaded, got %v", restarted.orderValidUntil("ord1"))
+	}
+
+	restarted.expireOrders(until.AddDate(0, 0, 1))
+	if len(cancelled) != 1 || cancelled[0] != "ord1" {
+		t.Fatalf("Expected ord1 to be cancelled, got %v", cancelled)
+	}
+	again := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	if err := again.SetExpiryStore(path); err != nil || !again.orderValidUntil("ord1").IsZero() {
+		t.Errorf("Expected the cancelled order to be gone from the store, err %v", err)
+	}
+}
+
+func TestGTDOrder_TrackedOnPlacingAccount(t *testing.T) {
+	app := NewApp(&mockClient{
+		PlaceOrderFunc: func(id, sym, side string, qty models.Decimal, p *models.OrderParams) (string, error) {
+			return "ord1", nil
+		},
+	}, []models.AccountInfo{{ID: "acc1"}, {ID: "acc2"}})
+
+	until := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+	_, err := app.sendSubmission("acc2", OrderSubmission{Instrument: "SBER", Quantity: models.DecimalFromInt(1), Direction: "Buy",
+		OrderType: models.OrderTypeLimit, LimitPrice: models.DecimalFromInt(250), Validity: models.ValidityGTD, ValidUntil: until}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	app.expiryMu.Lock()
+	got := app.orderExpiry["ord1"].AccountID
+	app.expiryMu.Unlock()
+	if got != "acc2" {
+		t.Errorf("Expected the expiry of ord1 on acc2, got %q", got)
+	}
+}
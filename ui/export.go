@@ -0,0 +1,259 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"finam-terminal/export"
+	"finam-terminal/models"
+	"finam-terminal/tradestore"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// exportScopes are the account choices of the export form.
+var exportScopes = []string{"Selected account", "All accounts"}
+
+// SetExportDir sets the directory exports are saved to by default. Without it they go
+// to the working directory.
+func (a *App) SetExportDir(dir string) {
+	a.exportDir = dir
+}
+
+// exportKind returns what the active tab exports, or "" when it has nothing to export.
+func (a *App) exportKind() string {
+	switch a.portfolioView.TabbedView.ActiveTab {
+	case TabPositions:
+		return export.KindPositions
+	case TabHistory:
+		return export.KindTrades
+	case TabOrders:
+		return export.KindOrders
+	case TabTransactions:
+		return export.KindTransactions
+	}
+	return ""
+}
+
+// ShowExportForm asks for the format, the accounts and the file to export the active
+// tab to. The P&L tab exports the tax report instead.
+func (a *App) ShowExportForm() {
+	if a.portfolioView.TabbedView.ActiveTab == TabPnL {
+		a.ShowTaxForm()
+		return
+	}
+	kind := a.exportKind()
+	if kind == "" {
+		a.SetStatus("Nothing to export on this tab", StatusInfo)
+		return
+	}
+	returnFocus := a.app.GetFocus()
+
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" Export %s ", strings.ToUpper(kind[:1])+kind[1:])).SetTitleAlign(tview.AlignCenter)
+	form.SetBackgroundColor(tcell.ColorBlack)
+	form.SetButtonBackgroundColor(tcell.ColorDarkBlue).
+		SetButtonTextColor(tcell.ColorWhite).
+		SetLabelColor(tcell.ColorYellow).
+		SetFieldBackgroundColor(tcell.ColorWhite).
+		SetFieldTextColor(tcell.ColorBlack)
+
+	path := tview.NewInputField().SetLabel("File:").SetFieldWidth(48).
+		SetText(filepath.Join(a.exportDir, export.FileName(kind, export.FormatCSV, time.Now())))
+	formats := make([]string, len(export.Formats))
+	for i, f := range export.Formats {
+		formats[i] = strings.ToUpper(f)
+	}
+	form.AddDropDown("Format:", formats, 0, func(option string, _ int) {
+		// Keep the file extension in step with the format
+		text := path.GetText()
+		path.SetText(strings.TrimSuffix(text, filepath.Ext(text)) + "." + strings.ToLower(option))
+	})
+	form.AddDropDown("Accounts:", exportScopes, 0, nil)
+	form.AddFormItem(path)
+
+	closeForm := func() {
+		a.pages.RemovePage("export")
+		a.app.SetFocus(returnFocus)
+	}
+	form.AddButton("Export", func() {
+		_, format := form.GetFormItemByLabel("Format:").(*tview.DropDown).GetCurrentOption()
+		scope, _ := form.GetFormItemByLabel("Accounts:").(*tview.DropDown).GetCurrentOption()
+		file := strings.TrimSpace(path.GetText())
+		if file == "" {
+			a.SetStatus("Enter a file name", StatusError)
+			return
+		}
+		closeForm()
+		a.exportAsync(kind, strings.ToLower(format), scope == 1, file)
+	})
+	form.AddButton("Cancel", closeForm)
+	form.SetCancelFunc(closeForm)
+
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(form, 11, 1, true).
+			AddItem(nil, 0, 1, false), 64, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	a.pages.AddPage("export", flex, true, true)
+	a.app.SetFocus(form)
+}
+
+// IsExportFormOpen returns true if the export form is currently shown.
+func (a *App) IsExportFormOpen() bool {
+	name, _ := a.pages.GetFrontPage()
+	return name == "export"
+}
+
+// exportRequest is what to export, taken from the UI state when the export starts.
+type exportRequest struct {
+	kind          string
+	accountIDs    []string
+	historyDays   int
+	historyFilter tradestore.Filter
+	txDays        int
+}
+
+// exportAsync writes kind of the selected account, or of every available account, to
+// path in the background.
+func (a *App) exportAsync(kind, format string, allAccounts bool, path string) {
+	req := exportRequest{
+		kind:          kind,
+		historyDays:   a.historyDays,
+		historyFilter: a.historyFilter,
+		txDays:        a.txDays,
+	}
+	for i, acc := range a.accounts {
+		if acc.LoadError == "" && (allAccounts || i == a.selectedIdx) {
+			req.accountIDs = append(req.accountIDs, acc.ID)
+		}
+	}
+	if len(req.accountIDs) == 0 {
+		a.SetStatus("No account to export", StatusError)
+		return
+	}
+	a.SetStatus("Exporting...", StatusLoading)
+	go func() {
+		table, err := a.exportTable(req)
+		if err == nil {
+			err = writeExport(path, format, table)
+		}
+		if err != nil {
+			log.Printf("[ERROR] Export of %s failed: %v", kind, err)
+			a.SetStatus(fmt.Sprintf("Export failed: %v", err), StatusError)
+			return
+		}
+		log.Printf("[INFO] Exported %d %s to %s", len(table.Rows), kind, path)
+		a.SetStatus(fmt.Sprintf("Exported %d rows to %s", len(table.Rows), path), StatusSuccess)
+	}()
+}
+
+// exportTable collects the data of req. Data already loaded is exported as the tabs show
+// it, with the history filter applied to trades; accounts never opened are loaded first.
+// It blocks on the API, so it must not run on the UI thread.
+func (a *App) exportTable(req exportRequest) (*export.Table, error) {
+	switch req.kind {
+	case export.KindPositions:
+		data := make(map[string][]models.Position)
+		for _, id := range req.accountIDs {
+			a.dataMutex.RLock()
+			positions, ok := a.positions[id]
+			a.dataMutex.RUnlock()
+			if !ok {
+				var err error
+				if _, positions, err = a.client.GetAccountDetails(id); err != nil {
+					return nil, err
+				}
+			}
+			data[id] = positions
+		}
+		return export.Positions(data), nil
+
+	case export.KindTrades:
+		to := time.Now()
+		from := to.AddDate(0, 0, -req.historyDays)
+		data := make(map[string][]models.Trade)
+		for _, id := range req.accountIDs {
+			a.dataMutex.RLock()
+			_, ok := a.history[id]
+			a.dataMutex.RUnlock()
+			if !ok {
+				if _, err := a.tradeStore.Sync(id, from, to, a.client.GetTradeHistoryRange); err != nil {
+					return nil, err
+				}
+			}
+			data[id] = a.tradeStore.Trades(id, req.historyFilter)
+		}
+		return export.Trades(data, a.client.GetLotSize), nil
+
+	case export.KindOrders:
+		data := make(map[string][]models.Order)
+		for _, id := range req.accountIDs {
+			a.dataMutex.RLock()
+			orders, ok := a.activeOrders[id]
+			a.dataMutex.RUnlock()
+			if !ok {
+				var err error
+				if orders, err = a.client.GetActiveOrders(id); err != nil {
+					return nil, err
+				}
+			}
+			data[id] = orders
+		}
+		return export.Orders(data, a.client.GetLotSize), nil
+
+	case export.KindTransactions:
+		to := time.Now()
+		from := to.AddDate(0, 0, -req.txDays)
+		data := make(map[string][]models.Transaction)
+		for _, id := range req.accountIDs {
+			a.dataMutex.RLock()
+			txs, ok := a.transactions[id]
+			a.dataMutex.RUnlock()
+			if !ok {
+				var err error
+				if txs, err = a.client.GetTransactions(id, from, to); err != nil {
+					return nil, err
+				}
+			}
+			data[id] = txs
+		}
+		return export.Transactions(data), nil
+	}
+	return nil, fmt.Errorf("unknown export %q", req.kind)
+}
+
+// writeExport writes table to path in format, creating the directory.
+func writeExport(path, format string, table *export.Table) error {
+	return saveExport(path, func(w io.Writer) error { return export.Write(w, table, format) })
+}
+
+// saveExport creates path and its directory and writes it with write.
+func saveExport(path string, write func(w io.Writer) error) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to save export: %w", err)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to save export: %w", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to save export: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to save export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"finam-terminal/export"
+	"finam-terminal/models"
+	"finam-terminal/tradestore"
+)
+
+func TestExport_LoadedAndMissingAccounts(t *testing.T) {
+	var fetched []string
+	client := &mockClient{
+		GetAccountDetailsFunc: func(accountID string) (*models.AccountInfo, []models.Position, error) {
+			fetched = append(fetched, accountID)
+			return &models.AccountInfo{ID: accountID}, []models.Position{{Symbol: "GAZP@MISX", Quantity: models.DecimalOf("5")}}, nil
+		},
+		GetTradeHistoryRangeFunc: func(accountID string, from, to time.Time) ([]models.Trade, error) {
+			return []models.Trade{{ID: "T9", Symbol: "GAZP@MISX", Side: "Sell", Price: models.DecimalOf("150"), Quantity: models.DecimalOf("5"), Timestamp: to.Add(-time.Hour)}}, nil
+		},
+	}
+	app := NewApp(client, []models.AccountInfo{{ID: "acc1"}, {ID: "acc2"}, {ID: "acc3", LoadError: "blocked"}})
+	app.positions["acc1"] = []models.Position{{Symbol: "SBER@MISX", Quantity: models.DecimalOf("10"), AveragePrice: models.NA}}
+
+	table, err := app.exportTable(exportRequest{kind: export.KindPositions, accountIDs: []string{"acc1", "acc2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fetched) != 1 || fetched[0] != "acc2" {
+		t.Errorf("Expected only the account never loaded to be fetched, got %v", fetched)
+	}
+	if len(table.Rows) != 2 || table.Rows[0][1] != "SBER@MISX" || table.Rows[1][0] != "acc2" {
+		t.Errorf("Unexpected rows %v", table.Rows)
+	}
+
+	path := filepath.Join(t.TempDir(), "out", "positions.csv")
+	if err := writeExport(path, export.FormatCSV, table); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 3 || lines[1] != "acc1,SBER@MISX,,10,,,0,0,0,0" {
+		t.Errorf("Unexpected export:\n%s", data)
+	}
+
+	table, err = app.exportTable(exportRequest{kind: export.KindTrades, accountIDs: []string{"acc1"},
+		historyDays: historyPage, historyFilter: tradestore.Filter{Side: "Buy"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 0 {
+		t.Errorf("Expected the history filter to leave out the sell, got %v", table.Rows)
+	}
+}
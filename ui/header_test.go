@@ -60,3 +60,14 @@ func TestCreateHeader_RendersDevBuild(t *testing.T) {
 		t.Errorf("header text = %q, must not contain hardcoded legacy version", text)
 	}
 }
+
+// TestSetPaperMode_MarksHeader verifies that a simulated session is labelled in the header.
+func TestSetPaperMode_MarksHeader(t *testing.T) {
+	app := NewApp(&mockClient{}, nil)
+	app.SetPaperMode()
+
+	text := app.header.GetText(true)
+	if !strings.Contains(text, "PAPER TRADING") || !strings.Contains(text, "Finam Terminal") {
+		t.Errorf("header text = %q, want Finam Terminal with a PAPER TRADING label", text)
+	}
+}
@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"finam-terminal/models"
+	"finam-terminal/tradestore"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// historyPage is how many days of trades the History tab loads at first and adds each
+// time older trades are requested.
+const historyPage = 30
+
+// historySides are the side choices of the history filter.
+var historySides = []string{"All", "Buy", "Sell"}
+
+// SetTradeStore loads the trades saved in path and keeps appending new ones there, so
+// periods fetched once are not requested again. Without a store, trades are kept until
+// the terminal exits.
+func (a *App) SetTradeStore(path string) error {
+	s := tradestore.NewStore(path)
+	if err := s.Load(); err != nil {
+		return err
+	}
+	a.tradeStore = s
+	return nil
+}
+
+// loadOlderHistory extends the History tab by another historyPage days back.
+func (a *App) loadOlderHistory() {
+	if a.selectedIdx < 0 || a.selectedIdx >= len(a.accounts) {
+		return
+	}
+	a.historyDays += historyPage
+	a.loadHistoryAsync(a.accounts[a.selectedIdx].ID)
+}
+
+// ShowHistoryFilter asks for the instrument, side and dates the History tab shows.
+func (a *App) ShowHistoryFilter() {
+	returnFocus := a.app.GetFocus()
+	f := a.historyFilter
+
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle(" Filter History ").SetTitleAlign(tview.AlignCenter)
+	form.SetBackgroundColor(tcell.ColorBlack)
+	form.SetButtonBackgroundColor(tcell.ColorDarkBlue).
+		SetButtonTextColor(tcell.ColorWhite).
+		SetLabelColor(tcell.ColorYellow).
+		SetFieldBackgroundColor(tcell.ColorWhite).
+		SetFieldTextColor(tcell.ColorBlack)
+
+	side := 0
+	for i, s := range historySides {
+		if s == f.Side {
+			side = i
+		}
+	}
+	form.AddInputField("Instrument:", f.Symbol, 20, nil, nil)
+	form.AddDropDown("Side:", historySides, side, nil)
+	form.AddInputField("From (YYYY-MM-DD):", formatFilterDate(f.From, false), 12, nil, nil)
+	form.AddInputField("To (YYYY-MM-DD):", formatFilterDate(f.To, true), 12, nil, nil)
+
+	closeFilter := func() {
+		a.pages.RemovePage("history_filter")
+		a.app.SetFocus(returnFocus)
+	}
+	form.AddButton("Apply", func() {
+		_, side := form.GetFormItemByLabel("Side:").(*tview.DropDown).GetCurrentOption()
+		filter, err := parseHistoryFilter(
+			form.GetFormItemByLabel("Instrument:").(*tview.InputField).GetText(),
+			side,
+			form.GetFormItemByLabel("From (YYYY-MM-DD):").(*tview.InputField).GetText(),
+			form.GetFormItemByLabel("To (YYYY-MM-DD):").(*tview.InputField).GetText(),
+		)
+		if err != nil {
+			a.SetStatus(err.Error(), StatusError)
+			return
+		}
+		closeFilter()
+		a.applyHistoryFilter(filter)
+	})
+	form.AddButton("Clear", func() {
+		closeFilter()
+		a.applyHistoryFilter(tradestore.Filter{})
+	})
+	form.SetCancelFunc(closeFilter)
+
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(form, 13, 1, true).
+			AddItem(nil, 0, 1, false), 46, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	a.pages.AddPage("history_filter", flex, true, true)
+	a.app.SetFocus(form)
+}
+
+// IsHistoryFilterOpen returns true if the history filter form is currently shown.
+func (a *App) IsHistoryFilterOpen() bool {
+	name, _ := a.pages.GetFrontPage()
+	return name == "history_filter"
+}
+
+// applyHistoryFilter shows the trades passing f. A start date before the loaded period
+// loads the trades back to it.
+func (a *App) applyHistoryFilter(f tradestore.Filter) {
+	a.historyFilter = f
+	updateHistoryTable(a)
+	if f.From.IsZero() || a.selectedIdx < 0 || a.selectedIdx >= len(a.accounts) {
+		return
+	}
+	if days := int(time.Since(f.From).Hours()/24) + 1; days > a.historyDays {
+		a.historyDays = days
+		a.loadHistoryAsync(a.accounts[a.selectedIdx].ID)
+	}
+}
+
+// parseHistoryFilter builds a filter from the form fields. The end date is inclusive.
+func parseHistoryFilter(symbol, side, from, to string) (tradestore.Filter, error) {
+	f := tradestore.Filter{Symbol: strings.ToUpper(strings.TrimSpace(symbol))}
+	if side != "All" {
+		f.Side = side
+	}
+	if from = strings.TrimSpace(from); from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			return f, fmt.Errorf("invalid start date %q", from)
+		}
+		f.From = t
+	}
+	if to = strings.TrimSpace(to); to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			return f, fmt.Errorf("invalid end date %q", to)
+		}
+		f.To = t.AddDate(0, 0, 1)
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return f, fmt.Errorf("start date is after the end date")
+	}
+	return f, nil
+}
+
+// formatFilterDate formats a filter bound for the form; the end bound is exclusive, so
+// the day before it is shown.
+func formatFilterDate(t time.Time, end bool) string {
+	if t.IsZero() {
+		return ""
+	}
+	if end {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t.Format("2006-01-02")
+}
+
+// historyFilterTitle describes the active history filter for the table title.
+func historyFilterTitle(f tradestore.Filter) string {
+	var parts []string
+	if f.Symbol != "" {
+		parts = append(parts, f.Symbol)
+	}
+	if f.Side != "" {
+		parts = append(parts, f.Side)
+	}
+	if !f.From.IsZero() || !f.To.IsZero() {
+		parts = append(parts, fmt.Sprintf("%s..%s", formatFilterDate(f.From, false), formatFilterDate(f.To, true)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// storeTrades keeps streamed trades of accountID in the trade store.
+func (a *App) storeTrades(accountID string, trades []models.Trade) {
+	if _, err := a.tradeStore.Add(accountID, trades); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"finam-terminal/models"
+)
+
+func TestHistory_FilterAndOlderTrades(t *testing.T) {
+	ranges := make(chan [2]time.Time, 2)
+	client := &mockClient{
+		GetTradeHistoryRangeFunc: func(accountID string, from, to time.Time) ([]models.Trade, error) {
+			ranges <- [2]time.Time{from, to}
+			return nil, nil
+		},
+	}
+	app := NewApp(client, []models.AccountInfo{{ID: "acc1"}})
+	day := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	app.history["acc1"] = []models.Trade{
+		{ID: "T1", Symbol: "SBER@MISX", Side: "Buy", Price: models.DecimalOf("300"), Quantity: models.DecimalOf("10"), Timestamp: day},
+		{ID: "T2", Symbol: "GAZP@MISX", Side: "Sell", Price: models.DecimalOf("150"), Quantity: models.DecimalOf("10"), Timestamp: day.AddDate(0, 0, 1)},
+		{ID: "T3", Symbol: "SBER@MISX", Side: "Sell", Price: models.DecimalOf("310"), Quantity: models.DecimalOf("10"), Timestamp: day.AddDate(0, 0, 2)},
+	}
+
+	f, err := parseHistoryFilter(" sber ", "Sell", "", "2026-03-04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.applyHistoryFilter(f)
+	table := app.portfolioView.TabbedView.HistoryTable
+	if rows := table.GetRowCount(); rows != 2 || table.GetCell(1, 2).Text != "310" {
+		t.Errorf("Expected only the SBER sell, got %d rows", rows)
+	}
+	if got := table.GetTitle(); got != " History: SBER, Sell, ..2026-03-04 " {
+		t.Errorf("Unexpected title %q", got)
+	}
+
+	if _, err := parseHistoryFilter("", "All", "2026-03-05", "2026-03-01"); err == nil {
+		t.Error("Expected a start date after the end date to be refused")
+	}
+
+	app.loadOlderHistory()
+	select {
+	case r := <-ranges:
+		if days := r[1].Sub(r[0]).Hours() / 24; days < 59 || days > 61 {
+			t.Errorf("Expected 60 days of history requested, got %.1f", days)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the older trades to be requested")
+	}
+}
@@ -24,6 +24,14 @@ func setupInputHandlers(app *App) {
 			case TabOrders:
 				app.portfolioView.TabbedView.OrdersTable.Clear()
 				app.loadOrdersAsync(accountID)
+			case TabWatchlists:
+				app.loadWatchlistAsync()
+			case TabAlerts:
+				updateAlertsTable(app)
+			case TabPnL:
+				app.loadHistoryAsync(accountID)
+			case TabTransactions:
+				app.loadTransactionsAsync(accountID)
 			}
 		}
 	}
@@ -31,6 +39,7 @@ func setupInputHandlers(app *App) {
 	switchAccount := func(idx int) {
 		if idx >= 0 && idx < len(app.accounts) {
 			app.selectedIdx = idx
+			app.ocoMarked = make(map[string]bool)
 			updateAccountList(app)
 
 			// Update view immediately with cached data
@@ -46,7 +55,17 @@ func setupInputHandlers(app *App) {
 			case TabHistory:
 				app.loadHistoryAsync(accountID)
 			case TabOrders:
-				app.loadOrdersAsync(accountID)
+				if app.ordersStreaming(accountID) {
+					updateOrdersTable(app)
+				} else {
+					app.loadOrdersAsync(accountID)
+				}
+			case TabPnL:
+				updatePnLTable(app)
+				app.loadHistoryAsync(accountID)
+			case TabTransactions:
+				updateTransactionsTable(app)
+				app.loadTransactionsAsync(accountID)
 			}
 		}
 	}
@@ -61,6 +80,19 @@ func setupInputHandlers(app *App) {
 			app.app.SetFocus(app.portfolioView.TabbedView.HistoryTable)
 		case TabOrders:
 			app.app.SetFocus(app.portfolioView.TabbedView.OrdersTable)
+		case TabWatchlists:
+			app.app.SetFocus(app.portfolioView.TabbedView.WatchlistTable)
+			updateWatchlistTable(app)
+			app.loadWatchlistAsync()
+		case TabAlerts:
+			app.app.SetFocus(app.portfolioView.TabbedView.AlertsTable)
+			updateAlertsTable(app)
+		case TabPnL:
+			app.app.SetFocus(app.portfolioView.TabbedView.PnLTable)
+			updatePnLTable(app)
+		case TabTransactions:
+			app.app.SetFocus(app.portfolioView.TabbedView.TransactionsTable)
+			updateTransactionsTable(app)
 		}
 		if app.selectedIdx >= len(app.accounts) {
 			return
@@ -79,18 +111,25 @@ func setupInputHandlers(app *App) {
 			// Always reload — trades may come from other terminals
 			app.loadHistoryAsync(accountID)
 		case TabOrders:
-			// Always reload — orders may change from other terminals
-			app.loadOrdersAsync(accountID)
+			// The orders stream keeps the list current, changes from other terminals included
+			if !app.ordersStreaming(accountID) {
+				app.loadOrdersAsync(accountID)
+			}
+		case TabPnL:
+			// P&L is replayed from the trade history, reload it like the History tab
+			app.loadHistoryAsync(accountID)
+		case TabTransactions:
+			app.loadTransactionsAsync(accountID)
 		}
 	}
 
 	nextTab := func() {
-		next := (int(app.portfolioView.TabbedView.ActiveTab) + 1) % 3
+		next := (int(app.portfolioView.TabbedView.ActiveTab) + 1) % tabCount
 		switchToTab(TabType(next))
 	}
 
 	prevTab := func() {
-		prev := (int(app.portfolioView.TabbedView.ActiveTab) - 1 + 3) % 3
+		prev := (int(app.portfolioView.TabbedView.ActiveTab) - 1 + tabCount) % tabCount
 		switchToTab(TabType(prev))
 	}
 
@@ -115,6 +154,9 @@ func setupInputHandlers(app *App) {
 					table.Select(row-1, 0)
 				}
 				return nil
+			case tcell.KeyCtrlS:
+				app.ShowExportForm()
+				return nil
 			}
 			switch event.Key() {
 			case tcell.KeyEnter:
@@ -122,11 +164,25 @@ func setupInputHandlers(app *App) {
 					app.OpenProfile()
 					return nil
 				}
+				if table == app.portfolioView.TabbedView.WatchlistTable {
+					if symbol, ok := app.selectedWatchSymbol(); ok {
+						app.OpenProfileForSymbol(symbol)
+					}
+					return nil
+				}
 			case tcell.KeyDelete:
 				if table == app.portfolioView.TabbedView.OrdersTable {
 					app.ShowCancelConfirmation()
 					return nil
 				}
+				if table == app.portfolioView.TabbedView.AlertsTable {
+					app.removeSelectedAlert()
+					return nil
+				}
+				if table == app.portfolioView.TabbedView.WatchlistTable {
+					app.removeSelectedWatch()
+					return nil
+				}
 			}
 			switch event.Rune() {
 			case 'q', 'Q', 'й', 'Й':
@@ -139,21 +195,106 @@ func setupInputHandlers(app *App) {
 				if table == app.portfolioView.TabbedView.PositionsTable {
 					app.OpenOrderModal()
 				}
+				if table == app.portfolioView.TabbedView.WatchlistTable {
+					if symbol, ok := app.selectedWatchSymbol(); ok {
+						app.OpenOrderModalWithTicker(symbol)
+					}
+				}
 				return nil
 			case 'x', 'X', 'ч', 'Ч':
 				if table == app.portfolioView.TabbedView.OrdersTable {
 					app.ShowCancelConfirmation()
 				}
+				if table == app.portfolioView.TabbedView.AlertsTable {
+					app.removeSelectedAlert()
+				}
+				if table == app.portfolioView.TabbedView.WatchlistTable {
+					app.removeSelectedWatch()
+				}
 				return nil
 			case 'e', 'E', 'у', 'У':
 				if table == app.portfolioView.TabbedView.OrdersTable {
 					app.ShowModifyOrderModal()
 				}
 				return nil
+			case ' ':
+				if table == app.portfolioView.TabbedView.OrdersTable {
+					app.toggleOCOMark()
+					return nil
+				}
+			case 'g', 'G', 'п', 'П':
+				if table == app.portfolioView.TabbedView.OrdersTable {
+					app.linkOCOGroup()
+				}
+				return nil
+			case 'u', 'U', 'г', 'Г':
+				if table == app.portfolioView.TabbedView.OrdersTable {
+					app.unlinkOCOGroup()
+				}
+				return nil
 			case 'c', 'C', 'с', 'С':
 				if table == app.portfolioView.TabbedView.PositionsTable {
 					app.OpenCloseModal()
 				}
+				if table == app.portfolioView.TabbedView.AlertsTable {
+					app.clearAlertLog()
+				}
+				return nil
+			case 'l', 'L', 'д', 'Д':
+				if table == app.portfolioView.TabbedView.AlertsTable {
+					app.ShowAlertPrompt("")
+				}
+				return nil
+			case '<', ',', 'б', 'Б':
+				if table == app.portfolioView.TabbedView.WatchlistTable {
+					app.cycleWatchlist(-1)
+					return nil
+				}
+			case '>', '.', 'ю', 'Ю':
+				if table == app.portfolioView.TabbedView.WatchlistTable {
+					app.cycleWatchlist(1)
+					return nil
+				}
+			case 'n', 'N', 'т', 'Т':
+				if table == app.portfolioView.TabbedView.WatchlistTable {
+					app.ShowNewWatchlistPrompt()
+				}
+				return nil
+			case 'd', 'D', 'в', 'В':
+				if table == app.portfolioView.TabbedView.WatchlistTable {
+					app.showDeleteWatchlist()
+				}
+				return nil
+			case 'f', 'F', 'а', 'А':
+				if table == app.portfolioView.TabbedView.HistoryTable {
+					app.ShowHistoryFilter()
+				}
+				return nil
+			case 'b', 'B', 'и', 'И':
+				switch table {
+				case app.portfolioView.TabbedView.HistoryTable:
+					app.loadOlderHistory()
+				case app.portfolioView.TabbedView.TransactionsTable:
+					app.loadOlderTransactions()
+				}
+				return nil
+			case 'm', 'M', 'ь', 'Ь':
+				if table == app.portfolioView.TabbedView.PnLTable {
+					app.togglePnLMethod()
+				}
+				return nil
+			case 'v', 'V', 'м', 'М':
+				switch table {
+				case app.portfolioView.TabbedView.PnLTable:
+					app.cyclePnLView()
+				case app.portfolioView.TabbedView.TransactionsTable:
+					app.toggleTxTotals()
+				}
+				return nil
+			case 'p', 'P', 'з', 'З':
+				if table == app.portfolioView.TabbedView.TransactionsTable {
+					app.cycleTxPeriod()
+				}
 				return nil
 			case 's', 'S', 'ы', 'Ы':
 				app.OpenSearchModal()
@@ -166,6 +307,10 @@ func setupInputHandlers(app *App) {
 	setupTableNavigation(app.portfolioView.TabbedView.PositionsTable)
 	setupTableNavigation(app.portfolioView.TabbedView.HistoryTable)
 	setupTableNavigation(app.portfolioView.TabbedView.OrdersTable)
+	setupTableNavigation(app.portfolioView.TabbedView.WatchlistTable)
+	setupTableNavigation(app.portfolioView.TabbedView.AlertsTable)
+	setupTableNavigation(app.portfolioView.TabbedView.PnLTable)
+	setupTableNavigation(app.portfolioView.TabbedView.TransactionsTable)
 
 	app.portfolioView.AccountTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
@@ -191,6 +336,12 @@ func setupInputHandlers(app *App) {
 	})
 
 	app.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Kill switch works from every screen and dialog
+		if event.Key() == tcell.KeyF12 {
+			app.KillSwitch()
+			return nil
+		}
+
 		// If profile overlay is open, handle profile keys at global level
 		// to avoid focus-related issues with ChartView inside Flex/Pages
 		if app.IsProfileOpen() {
@@ -198,6 +349,18 @@ func setupInputHandlers(app *App) {
 			if app.IsAlertOpen() {
 				return event
 			}
+			// Block filter and alert prompts handle Enter/Escape themselves
+			if app.IsBlockFilterOpen() || app.IsAlertPromptOpen() {
+				return event
+			}
+			// Risk violation dialog over the order modal
+			if app.IsRiskConfirmOpen() {
+				if event.Key() == tcell.KeyEscape {
+					app.closeRiskConfirm()
+					return nil
+				}
+				return event
+			}
 			// Cancel confirmation on top of profile
 			if app.IsCancelConfirmOpen() {
 				if event.Key() == tcell.KeyEscape {
@@ -250,6 +413,12 @@ func setupInputHandlers(app *App) {
 			case 'a', 'A', 'ф', 'Ф':
 				app.OpenOrderModalWithTicker(app.profileSymbol)
 				return nil
+			case 'b', 'B', 'и', 'И':
+				app.ShowBlockFilterInput()
+				return nil
+			case 'l', 'L', 'д', 'Д':
+				app.ShowAlertPrompt(app.profileSymbol)
+				return nil
 			case 'r', 'R', 'к', 'К':
 				if app.selectedIdx >= 0 && app.selectedIdx < len(app.accounts) {
 					app.profilePanel.Footer.SetText("[yellow]Refreshing...[-]")
@@ -266,6 +435,20 @@ func setupInputHandlers(app *App) {
 			return nil // Consume unhandled keys to prevent them from reaching ChartView
 		}
 
+		// Alert, watchlist, history filter, export and tax report prompts handle Enter/Escape themselves
+		if app.IsAlertPromptOpen() || app.IsWatchlistPromptOpen() || app.IsHistoryFilterOpen() || app.IsExportFormOpen() || app.IsTaxFormOpen() {
+			return event
+		}
+
+		// Risk violation dialog — Escape goes back to the order modal
+		if app.IsRiskConfirmOpen() {
+			if event.Key() == tcell.KeyEscape {
+				app.closeRiskConfirm()
+				return nil
+			}
+			return event
+		}
+
 		// Cancel confirmation modal — pass all events through (Tab, Enter work natively)
 		if app.IsCancelConfirmOpen() {
 			if event.Key() == tcell.KeyEscape {
@@ -315,6 +498,14 @@ func setupInputHandlers(app *App) {
 					app.app.SetFocus(app.portfolioView.TabbedView.HistoryTable)
 				case TabOrders:
 					app.app.SetFocus(app.portfolioView.TabbedView.OrdersTable)
+				case TabWatchlists:
+					app.app.SetFocus(app.portfolioView.TabbedView.WatchlistTable)
+				case TabAlerts:
+					app.app.SetFocus(app.portfolioView.TabbedView.AlertsTable)
+				case TabPnL:
+					app.app.SetFocus(app.portfolioView.TabbedView.PnLTable)
+				case TabTransactions:
+					app.app.SetFocus(app.portfolioView.TabbedView.TransactionsTable)
 				}
 			} else {
 				// Switch back to Account Table
@@ -12,18 +12,29 @@ type mockClient struct {
 	GetAccountsFunc       func() ([]models.AccountInfo, error)
 	GetAccountDetailsFunc func(accountID string) (*models.AccountInfo, []models.Position, error)
 	GetQuotesFunc         func(accountID string, symbols []string) (map[string]*models.Quote, error)
-	PlaceOrderFunc        func(accountID string, symbol string, buySell string, quantity float64, params *models.OrderParams) (string, error)
-	ClosePositionFunc     func(accountID string, symbol string, currentQuantity string, closeQuantity float64) (string, error)
-	PlaceSLTPOrderFunc    func(accountID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice float64) (string, error)
+	SubscribeQuotesFunc   func(accountID string, symbols []string)
+	SetQuoteHandlerFunc   func(handler func(models.Quote))
+
+	SubscribeOrderBookFunc    func(accountID string, symbol string, handler func(*models.OrderBook)) func()
+	SubscribeLatestTradesFunc func(accountID string, symbol string, handler func([]models.MarketTrade)) func()
+	PlaceOrderFunc            func(accountID string, symbol string, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error)
+	ClosePositionFunc         func(accountID string, symbol string, currentQuantity, closeQuantity models.Decimal) (string, error)
+	PlaceSLTPOrderFunc        func(accountID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice models.Decimal) (string, error)
+	ModifyOrderFunc           func(accountID, orderID, symbol, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error)
+	ModifySLTPOrderFunc       func(accountID, orderID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice models.Decimal) (string, error)
 
 	SearchSecuritiesFunc  func(query string) ([]models.SecurityInfo, error)
 	GetSnapshotsFunc      func(accountID string, symbols []string) (map[string]models.Quote, error)
-	GetLotSizeFunc        func(ticker string) float64
+	GetLotSizeFunc        func(ticker string) models.Decimal
 	GetInstrumentNameFunc func(key string) string
 
-	GetTradeHistoryFunc func(accountID string) ([]models.Trade, error)
-	GetActiveOrdersFunc func(accountID string) ([]models.Order, error)
-	CancelOrderFunc     func(accountID, orderID string) error
+	GetTradeHistoryFunc      func(accountID string) ([]models.Trade, error)
+	GetTradeHistoryRangeFunc func(accountID string, from, to time.Time) ([]models.Trade, error)
+	GetActiveOrdersFunc      func(accountID string) ([]models.Order, error)
+	GetTransactionsFunc      func(accountID string, from, to time.Time) ([]models.Transaction, error)
+	CancelOrderFunc          func(accountID, orderID string) error
+	SubscribeOrdersFunc      func(accountID string, onSnapshot func([]models.Order), onUpdate func([]models.Order)) func()
+	SubscribeTradesFunc      func(accountID string, handler func([]models.Trade)) func()
 
 	GetBarsFunc        func(accountID string, symbol string, timeframe marketdata.TimeFrame, from, to time.Time) ([]models.Bar, error)
 	GetAssetInfoFunc   func(accountID string, symbol string) (*models.AssetDetails, error)
@@ -52,21 +63,61 @@ func (m *mockClient) GetQuotes(accountID string, symbols []string) (map[string]*
 	return make(map[string]*models.Quote), nil
 }
 
-func (m *mockClient) PlaceOrder(accountID string, symbol string, buySell string, quantity float64, params *models.OrderParams) (string, error) {
+func (m *mockClient) SubscribeQuotes(accountID string, symbols []string) {
+	if m.SubscribeQuotesFunc != nil {
+		m.SubscribeQuotesFunc(accountID, symbols)
+	}
+}
+
+func (m *mockClient) SetQuoteHandler(handler func(models.Quote)) {
+	if m.SetQuoteHandlerFunc != nil {
+		m.SetQuoteHandlerFunc(handler)
+	}
+}
+
+func (m *mockClient) SubscribeOrderBook(accountID string, symbol string, handler func(*models.OrderBook)) func() {
+	if m.SubscribeOrderBookFunc != nil {
+		return m.SubscribeOrderBookFunc(accountID, symbol, handler)
+	}
+	return func() {}
+}
+
+func (m *mockClient) SubscribeLatestTrades(accountID string, symbol string, handler func([]models.MarketTrade)) func() {
+	if m.SubscribeLatestTradesFunc != nil {
+		return m.SubscribeLatestTradesFunc(accountID, symbol, handler)
+	}
+	return func() {}
+}
+
+func (m *mockClient) PlaceOrder(accountID string, symbol string, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error) {
 	if m.PlaceOrderFunc != nil {
 		return m.PlaceOrderFunc(accountID, symbol, buySell, quantity, params)
 	}
 	return "tx-123", nil
 }
 
-func (m *mockClient) PlaceSLTPOrder(accountID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice float64) (string, error) {
+func (m *mockClient) PlaceSLTPOrder(accountID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice models.Decimal) (string, error) {
 	if m.PlaceSLTPOrderFunc != nil {
 		return m.PlaceSLTPOrderFunc(accountID, symbol, buySell, slQty, slPrice, tpQty, tpPrice)
 	}
 	return "tx-123", nil
 }
 
-func (m *mockClient) ClosePosition(accountID string, symbol string, currentQuantity string, closeQuantity float64) (string, error) {
+func (m *mockClient) ModifyOrder(accountID, orderID, symbol, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error) {
+	if m.ModifyOrderFunc != nil {
+		return m.ModifyOrderFunc(accountID, orderID, symbol, buySell, quantity, params)
+	}
+	return "tx-123", nil
+}
+
+func (m *mockClient) ModifySLTPOrder(accountID, orderID, symbol, buySell string, slQty, slPrice, tpQty, tpPrice models.Decimal) (string, error) {
+	if m.ModifySLTPOrderFunc != nil {
+		return m.ModifySLTPOrderFunc(accountID, orderID, symbol, buySell, slQty, slPrice, tpQty, tpPrice)
+	}
+	return "tx-123", nil
+}
+
+func (m *mockClient) ClosePosition(accountID string, symbol string, currentQuantity, closeQuantity models.Decimal) (string, error) {
 	if m.ClosePositionFunc != nil {
 		return m.ClosePositionFunc(accountID, symbol, currentQuantity, closeQuantity)
 	}
@@ -87,11 +138,11 @@ func (m *mockClient) GetSnapshots(accountID string, symbols []string) (map[strin
 	return make(map[string]models.Quote), nil
 }
 
-func (m *mockClient) GetLotSize(ticker string) float64 {
+func (m *mockClient) GetLotSize(ticker string) models.Decimal {
 	if m.GetLotSizeFunc != nil {
 		return m.GetLotSizeFunc(ticker)
 	}
-	return 1
+	return models.DecimalFromInt(1)
 }
 
 func (m *mockClient) GetInstrumentName(key string) string {
@@ -108,6 +159,14 @@ func (m *mockClient) GetTradeHistory(accountID string) ([]models.Trade, error) {
 	return nil, nil
 }
 
+// GetTradeHistoryRange falls back to GetTradeHistoryFunc when no range function is set.
+func (m *mockClient) GetTradeHistoryRange(accountID string, from, to time.Time) ([]models.Trade, error) {
+	if m.GetTradeHistoryRangeFunc != nil {
+		return m.GetTradeHistoryRangeFunc(accountID, from, to)
+	}
+	return m.GetTradeHistory(accountID)
+}
+
 func (m *mockClient) GetActiveOrders(accountID string) ([]models.Order, error) {
 	if m.GetActiveOrdersFunc != nil {
 		return m.GetActiveOrdersFunc(accountID)
@@ -115,6 +174,13 @@ func (m *mockClient) GetActiveOrders(accountID string) ([]models.Order, error) {
 	return nil, nil
 }
 
+func (m *mockClient) GetTransactions(accountID string, from, to time.Time) ([]models.Transaction, error) {
+	if m.GetTransactionsFunc != nil {
+		return m.GetTransactionsFunc(accountID, from, to)
+	}
+	return nil, nil
+}
+
 func (m *mockClient) GetBars(accountID string, symbol string, timeframe marketdata.TimeFrame, from, to time.Time) ([]models.Bar, error) {
 	if m.GetBarsFunc != nil {
 		return m.GetBarsFunc(accountID, symbol, timeframe, from, to)
@@ -149,3 +215,17 @@ func (m *mockClient) CancelOrder(accountID, orderID string) error {
 	}
 	return nil
 }
+
+func (m *mockClient) SubscribeOrders(accountID string, onSnapshot func([]models.Order), onUpdate func([]models.Order)) func() {
+	if m.SubscribeOrdersFunc != nil {
+		return m.SubscribeOrdersFunc(accountID, onSnapshot, onUpdate)
+	}
+	return func() {}
+}
+
+func (m *mockClient) SubscribeTrades(accountID string, handler func([]models.Trade)) func() {
+	if m.SubscribeTradesFunc != nil {
+		return m.SubscribeTradesFunc(accountID, handler)
+	}
+	return func() {}
+}
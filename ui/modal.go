@@ -1,10 +1,12 @@
 package ui
 
 import (
+	"finam-terminal/conditional"
 	"finam-terminal/models"
+	"finam-terminal/trailing"
 	"fmt"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -13,13 +15,29 @@ import (
 // OrderSubmission holds all parameters from the order modal
 type OrderSubmission struct {
 	Instrument string
-	Quantity   float64
+	Quantity   models.Decimal
 	Direction  string
-	OrderType  string  // models.OrderType* constants
-	LimitPrice float64 // For Limit orders
-	StopPrice  float64 // For Stop-Loss orders
-	SLPrice    float64 // For SL+TP orders
-	TPPrice    float64 // For SL+TP and Take-Profit orders
+	OrderType  string         // models.OrderType* constants
+	LimitPrice models.Decimal // For Limit and Stop-Limit orders, and Bracket limit entries
+	StopPrice  models.Decimal // For Stop-Loss and Stop-Limit orders
+	SLPrice    models.Decimal // For SL+TP and Bracket orders
+	TPPrice    models.Decimal // For SL+TP, Take-Profit and Bracket orders
+	Validity   string         // models.Validity* for Stop-Loss, Take-Profit and Stop-Limit orders
+	ValidUntil time.Time      // Last day of a GTD order
+
+	// Trailing stops trail the price by TrailDistance or TrailPercent and move their
+	// stop order in steps of at least TrailStep
+	TrailDistance models.Decimal
+	TrailPercent  models.Decimal
+	TrailStep     models.Decimal
+
+	// Conditional orders are sent at LimitPrice, or at market without one, once Condition is met
+	Condition conditional.Condition
+
+	// OverrideRisk sends the order despite risk check violations the user confirmed
+	OverrideRisk bool
+	// riskChecked marks a submission that already passed the risk checks
+	riskChecked bool
 }
 
 // OrderModal represents the order entry modal
@@ -42,13 +60,23 @@ type OrderModal struct {
 	stopPriceField  *tview.InputField
 	slPriceField    *tview.InputField
 	tpPriceField    *tview.InputField
+	validity        *tview.DropDown
+	validUntilField *tview.InputField
+	trailField      *tview.InputField
+	trailStepField  *tview.InputField
+	conditionField  *tview.DropDown
+	triggerField    *tview.InputField
 
 	// State
 	currentDir       string
 	currentOrderType string
-	lotSize          float64
-	price            float64
+	currentValidity  string
+	currentCondition string
+	lotSize          models.Decimal
+	price            models.Decimal
+	priceStep        models.Decimal        // Tick size of the instrument; zero while unknown
 	originalCallback func(OrderSubmission) // saved by SetCallback for restoration on cancel
+	typeOptions      []string              // Order types the dropdown offers
 }
 
 var orderTypeOptions = []string{
@@ -57,6 +85,75 @@ var orderTypeOptions = []string{
 	models.OrderTypeStop,
 	models.OrderTypeTakeProfit,
 	models.OrderTypeSLTP,
+	models.OrderTypeStopLimit,
+	models.OrderTypeTrailing,
+	models.OrderTypeBracket,
+	models.OrderTypeConditional,
+}
+
+// replaceableOrderTypes are the order types a working order can be modified into: the
+// exchange replaces the order. Trailing stops, brackets and conditional orders are run by
+// the terminal and cannot take a working order's place.
+var replaceableOrderTypes = []string{
+	models.OrderTypeMarket,
+	models.OrderTypeLimit,
+	models.OrderTypeStop,
+	models.OrderTypeTakeProfit,
+	models.OrderTypeSLTP,
+	models.OrderTypeStopLimit,
+}
+
+// conditionOptions are the conditions of a Conditional order: the price field watched and
+// the direction it must cross the trigger price in.
+var conditionOptions = []string{
+	"Last above",
+	"Last below",
+	"Bid above",
+	"Bid below",
+	"Ask above",
+	"Ask below",
+}
+
+// conditionFromOption builds the condition of a conditionOptions entry crossing price.
+func conditionFromOption(option string, price models.Decimal) conditional.Condition {
+	field, dir, _ := strings.Cut(option, " ")
+	return conditional.Condition{
+		Field: strings.ToUpper(field),
+		Above: dir == "above",
+		Price: price,
+	}
+}
+
+// conditionOption returns the conditionOptions entry of c.
+func conditionOption(c conditional.Condition) string {
+	dir := "below"
+	if c.Above {
+		dir = "above"
+	}
+	field := strings.ToLower(c.Field)
+	if field != "" {
+		field = strings.ToUpper(field[:1]) + field[1:]
+	}
+	return field + " " + dir
+}
+
+var validityOptions = []string{
+	models.ValidityGTC,
+	models.ValidityDay,
+	models.ValidityGTD,
+}
+
+// validUntilLayout is the date format of the Valid Until field
+const validUntilLayout = "02.01.2006"
+
+// hasValidity reports whether orders of orderType take a validity. SL+TP pairs are
+// always sent as GTC.
+func hasValidity(orderType string) bool {
+	switch orderType {
+	case models.OrderTypeLimit, models.OrderTypeStop, models.OrderTypeTakeProfit, models.OrderTypeStopLimit:
+		return true
+	}
+	return false
 }
 
 // NewOrderModal creates a new order modal
@@ -71,6 +168,8 @@ func NewOrderModal(app *tview.Application, callback func(OrderSubmission), onCan
 		onCancel:         onCancel,
 		currentDir:       "Buy",
 		currentOrderType: models.OrderTypeMarket,
+		currentValidity:  models.ValidityGTC,
+		currentCondition: conditionOptions[0],
 	}
 	m.setupUI()
 	return m
@@ -115,22 +214,15 @@ func (m *OrderModal) setupUI() {
 		SetLabel("Direction:  ").
 		SetOptions([]string{"Buy", "Sell"}, func(text string, index int) {
 			m.currentDir = text
+			m.updateCreateButton()
 		}).
 		SetCurrentOption(0).
 		SetFieldWidth(15)
 
 	m.orderType = tview.NewDropDown().
 		SetLabel("Order Type: ").
-		SetOptions(orderTypeOptions, func(text string, index int) {
-			if text != m.currentOrderType {
-				m.currentOrderType = text
-				m.rebuildPriceFields()
-				m.updateCreateButton()
-				m.updateInfo()
-			}
-		}).
-		SetCurrentOption(0).
 		SetFieldWidth(15)
+	m.setOrderTypes(orderTypeOptions)
 
 	// Style dropdowns consistently
 	dropdownStyle := tcell.StyleDefault.Background(tcell.ColorWhite).Foreground(tcell.ColorBlack)
@@ -169,14 +261,16 @@ func (m *OrderModal) setupUI() {
 	m.Footer.SetTextColor(tcell.ColorWhite).
 		SetDynamicColors(true).
 		SetTextAlign(tview.AlignLeft).
-		SetText(" [yellow]TAB[white] Move  [yellow]ENTER[white] Select  [yellow]ESC[white] Close")
+		SetText(orderModalFooter)
 
 	// Assemble Layout
 	m.Layout.AddItem(m.Form, 0, 1, true).
-		AddItem(m.infoArea, 2, 0, false).
+		AddItem(m.infoArea, 3, 0, false).
 		AddItem(m.Footer, 1, 0, false)
 }
 
+const orderModalFooter = " [yellow]TAB[white] Move  [yellow]ENTER[white] Select  [yellow]ESC[white] Close"
+
 // rebuildPriceFields removes old dynamic price fields and adds new ones based on order type
 func (m *OrderModal) rebuildPriceFields() {
 	// Remove existing dynamic price fields (they are after index 3 = orderType dropdown)
@@ -187,6 +281,12 @@ func (m *OrderModal) rebuildPriceFields() {
 	m.stopPriceField = nil
 	m.slPriceField = nil
 	m.tpPriceField = nil
+	m.validity = nil
+	m.validUntilField = nil
+	m.trailField = nil
+	m.trailStepField = nil
+	m.conditionField = nil
+	m.triggerField = nil
 
 	priceAcceptFunc := func(text string, lastChar rune) bool {
 		// Allow digits and one decimal point
@@ -201,14 +301,15 @@ func (m *OrderModal) rebuildPriceFields() {
 
 	changedFunc := func(text string) {
 		m.updateCreateButton()
+		m.updateInfo()
 	}
 
 	insertIdx := 4 // After orderType dropdown
 
 	// Pre-fill price fields with current market price
 	defaultPrice := ""
-	if m.price > 0 {
-		defaultPrice = strconv.FormatFloat(m.price, 'f', -1, 64)
+	if m.price.Sign() > 0 {
+		defaultPrice = m.priceText(m.price)
 	}
 
 	switch m.currentOrderType {
@@ -261,7 +362,354 @@ func (m *OrderModal) rebuildPriceFields() {
 			SetChangedFunc(changedFunc)
 		m.Form.AddFormItem(m.tpPriceField)
 		m.moveLastFormItemTo(insertIdx + 1)
+
+	case models.OrderTypeStopLimit:
+		m.stopPriceField = tview.NewInputField().
+			SetLabel("Stop Price: ").
+			SetFieldWidth(15).
+			SetText(defaultPrice).
+			SetAcceptanceFunc(priceAcceptFunc).
+			SetChangedFunc(changedFunc)
+		m.Form.AddFormItem(m.stopPriceField)
+		m.moveLastFormItemTo(insertIdx)
+
+		m.limitPriceField = tview.NewInputField().
+			SetLabel("Limit Price:").
+			SetFieldWidth(15).
+			SetText(defaultPrice).
+			SetAcceptanceFunc(priceAcceptFunc).
+			SetChangedFunc(changedFunc)
+		m.Form.AddFormItem(m.limitPriceField)
+		m.moveLastFormItemTo(insertIdx + 1)
+
+	case models.OrderTypeTrailing:
+		m.trailField = tview.NewInputField().
+			SetLabel("Trail:      ").
+			SetFieldWidth(15).
+			SetAcceptanceFunc(func(text string, lastChar rune) bool {
+				// A price distance, or a percent of the price ending in %
+				if strings.Contains(text[:len(text)-1], "%") {
+					return false
+				}
+				return lastChar == '%' || priceAcceptFunc(text, lastChar)
+			}).
+			SetChangedFunc(changedFunc)
+		m.Form.AddFormItem(m.trailField)
+		m.moveLastFormItemTo(insertIdx)
+
+		m.trailStepField = tview.NewInputField().
+			SetLabel("Step:       ").
+			SetFieldWidth(15).
+			SetAcceptanceFunc(priceAcceptFunc).
+			SetChangedFunc(changedFunc)
+		m.Form.AddFormItem(m.trailStepField)
+		m.moveLastFormItemTo(insertIdx + 1)
+
+	case models.OrderTypeBracket:
+		// An empty entry price enters at market
+		m.limitPriceField = tview.NewInputField().
+			SetLabel("Entry Price:").
+			SetFieldWidth(15).
+			SetText(defaultPrice).
+			SetAcceptanceFunc(priceAcceptFunc).
+			SetChangedFunc(changedFunc)
+		m.Form.AddFormItem(m.limitPriceField)
+		m.moveLastFormItemTo(insertIdx)
+
+		m.slPriceField = tview.NewInputField().
+			SetLabel("SL Price:   ").
+			SetFieldWidth(15).
+			SetAcceptanceFunc(priceAcceptFunc).
+			SetChangedFunc(changedFunc)
+		m.Form.AddFormItem(m.slPriceField)
+		m.moveLastFormItemTo(insertIdx + 1)
+
+		m.tpPriceField = tview.NewInputField().
+			SetLabel("TP Price:   ").
+			SetFieldWidth(15).
+			SetAcceptanceFunc(priceAcceptFunc).
+			SetChangedFunc(changedFunc)
+		m.Form.AddFormItem(m.tpPriceField)
+		m.moveLastFormItemTo(insertIdx + 2)
+
+	case models.OrderTypeConditional:
+		m.conditionField = tview.NewDropDown().
+			SetLabel("Condition:  ").
+			SetOptions(conditionOptions, nil).
+			SetFieldWidth(15)
+		m.conditionField.SetListStyles(
+			tcell.StyleDefault.Background(tcell.ColorWhite).Foreground(tcell.ColorBlack),
+			tcell.StyleDefault.Background(tcell.ColorOrange).Foreground(tcell.ColorBlack))
+		for i, opt := range conditionOptions {
+			if opt == m.currentCondition {
+				m.conditionField.SetCurrentOption(i)
+			}
+		}
+		m.conditionField.SetSelectedFunc(func(text string, index int) {
+			m.currentCondition = text
+		})
+		m.Form.AddFormItem(m.conditionField)
+		m.moveLastFormItemTo(insertIdx)
+
+		m.triggerField = tview.NewInputField().
+			SetLabel("Trigger:    ").
+			SetFieldWidth(15).
+			SetText(defaultPrice).
+			SetAcceptanceFunc(priceAcceptFunc).
+			SetChangedFunc(changedFunc)
+		m.Form.AddFormItem(m.triggerField)
+		m.moveLastFormItemTo(insertIdx + 1)
+
+		// An empty limit price sends a market order
+		m.limitPriceField = tview.NewInputField().
+			SetLabel("Limit Price:").
+			SetFieldWidth(15).
+			SetAcceptanceFunc(priceAcceptFunc).
+			SetChangedFunc(changedFunc)
+		m.Form.AddFormItem(m.limitPriceField)
+		m.moveLastFormItemTo(insertIdx + 2)
 	}
+
+	for _, field := range m.priceFields() {
+		m.addTickKeys(field)
+	}
+	if hasValidity(m.currentOrderType) {
+		m.addValidityFields()
+	}
+	m.Footer.SetText(m.footerText())
+}
+
+// priceFields returns the price fields shown for the current order type.
+func (m *OrderModal) priceFields() []*tview.InputField {
+	var fields []*tview.InputField
+	for _, f := range []*tview.InputField{m.stopPriceField, m.limitPriceField, m.slPriceField, m.tpPriceField, m.triggerField} {
+		if f != nil {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// addTickKeys makes Up and Down step the price in field by one tick, and snaps the price
+// to the tick grid when the field is left.
+func (m *OrderModal) addTickKeys(field *tview.InputField) {
+	field.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp:
+			m.stepPrice(field, true)
+		case tcell.KeyDown:
+			m.stepPrice(field, false)
+		default:
+			return event
+		}
+		return nil
+	})
+	field.SetDoneFunc(func(key tcell.Key) {
+		if key != tcell.KeyEscape {
+			m.snapPrice(field)
+		}
+	})
+}
+
+// stepPrice moves the price in field one tick up or down. A price off the grid moves to
+// the nearest grid price in that direction; an empty field starts from the current price.
+// Until the tick size is known the price steps by one unit of its last decimal.
+func (m *OrderModal) stepPrice(field *tview.InputField, up bool) {
+	price := m.getPriceFieldValue(field)
+	step := m.priceStep
+	if step.Sign() <= 0 {
+		step = models.AssetDetails{Decimals: price.Scale()}.PriceStep()
+	}
+	if price.Sign() <= 0 {
+		if m.price.Sign() > 0 {
+			field.SetText(m.priceText(m.price.RoundStep(step)))
+		}
+		return
+	}
+
+	below, above := price.Snap(step)
+	switch {
+	case up && below.Equal(above):
+		price = price.Add(step)
+	case up:
+		price = above
+	case below.Equal(above):
+		price = price.Sub(step)
+	default:
+		price = below
+	}
+	if price.Sign() > 0 {
+		field.SetText(m.priceText(price))
+	}
+}
+
+// snapPrice rounds the price in field to the nearest tick. Text that is not a price is
+// left for validation.
+func (m *OrderModal) snapPrice(field *tview.InputField) {
+	price := m.getPriceFieldValue(field)
+	if price.Sign() <= 0 || m.priceStep.Sign() <= 0 {
+		return
+	}
+	snapped := price.RoundStep(m.priceStep)
+	if snapped.Sign() <= 0 {
+		_, snapped = price.Snap(m.priceStep)
+	}
+	field.SetText(m.priceText(snapped))
+}
+
+// priceText formats price with the decimals of the instrument, or as it is while the tick
+// size is unknown.
+func (m *OrderModal) priceText(price models.Decimal) string {
+	if m.priceStep.Sign() <= 0 {
+		return price.String()
+	}
+	return price.StringFixed(m.priceStep.Scale())
+}
+
+// priceError describes the first price off the tick grid and names the nearest valid
+// prices, or returns "" when every price is on the grid.
+func (m *OrderModal) priceError() string {
+	if m.priceStep.Sign() <= 0 {
+		return ""
+	}
+	for _, field := range m.priceFields() {
+		price := m.getPriceFieldValue(field)
+		if price.Sign() <= 0 {
+			continue
+		}
+		below, above := price.Snap(m.priceStep)
+		if below.Equal(above) {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimSpace(field.GetLabel()), ":")
+		nearest := m.priceText(above)
+		if below.Sign() > 0 {
+			nearest = m.priceText(below) + " or " + nearest
+		}
+		return fmt.Sprintf("%s %s is off the %s tick: use %s", name, price, m.priceStep, nearest)
+	}
+	return ""
+}
+
+// addValidityFields appends the validity dropdown, and the date field for GTD, after the
+// price fields.
+func (m *OrderModal) addValidityFields() {
+	m.validity = tview.NewDropDown().
+		SetLabel("Validity:   ").
+		SetOptions(validityOptions, nil).
+		SetFieldWidth(15)
+	m.validity.SetListStyles(
+		tcell.StyleDefault.Background(tcell.ColorWhite).Foreground(tcell.ColorBlack),
+		tcell.StyleDefault.Background(tcell.ColorOrange).Foreground(tcell.ColorBlack))
+	for i, opt := range validityOptions {
+		if opt == m.currentValidity {
+			m.validity.SetCurrentOption(i)
+		}
+	}
+	// Set after the initial option so building the field does not toggle the date field
+	m.validity.SetSelectedFunc(func(text string, index int) {
+		if text != m.currentValidity {
+			m.currentValidity = text
+			m.toggleValidUntilField()
+			m.updateCreateButton()
+		}
+	})
+	m.Form.AddFormItem(m.validity)
+	m.toggleValidUntilField()
+}
+
+// toggleValidUntilField shows the date field while GTD is selected. The field is always
+// the last form item, so the price fields keep what the user typed.
+func (m *OrderModal) toggleValidUntilField() {
+	if m.currentValidity != models.ValidityGTD {
+		if m.validUntilField != nil {
+			m.Form.RemoveFormItem(m.Form.GetFormItemCount() - 1)
+			m.validUntilField = nil
+		}
+		m.Footer.SetText(m.footerText())
+		return
+	}
+	if m.validUntilField != nil {
+		return
+	}
+
+	m.validUntilField = tview.NewInputField().
+		SetLabel("Valid Until:").
+		SetFieldWidth(15).
+		SetText(time.Now().AddDate(0, 0, 1).Format(validUntilLayout)).
+		SetAcceptanceFunc(func(text string, lastChar rune) bool {
+			return (lastChar >= '0' && lastChar <= '9') || lastChar == '.'
+		}).
+		SetChangedFunc(func(text string) {
+			m.updateCreateButton()
+		})
+	// + and - step the date by a day, starting from today when the field is not a date
+	m.validUntilField.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		step := 0
+		switch event.Rune() {
+		case '+':
+			step = 1
+		case '-':
+			step = -1
+		default:
+			return event
+		}
+		date, ok := m.getValidUntil()
+		if !ok {
+			date = today()
+		}
+		m.validUntilField.SetText(date.AddDate(0, 0, step).Format(validUntilLayout))
+		return nil
+	})
+	m.Form.AddFormItem(m.validUntilField)
+	m.Footer.SetText(m.footerText())
+}
+
+// footerText lists the modal's keys, with the date keys while the date field is shown.
+func (m *OrderModal) footerText() string {
+	text := orderModalFooter
+	if len(m.priceFields()) > 0 {
+		text += "  [yellow]↑/↓[white] Tick"
+	}
+	if m.validUntilField != nil {
+		text += "  [yellow]+/-[white] Date"
+	}
+	return text
+}
+
+// getValidUntil parses the Valid Until field. ok is false when the field is not shown or
+// does not hold a date.
+func (m *OrderModal) getValidUntil() (date time.Time, ok bool) {
+	if m.validUntilField == nil {
+		return time.Time{}, false
+	}
+	date, err := time.ParseInLocation(validUntilLayout, m.validUntilField.GetText(), time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// bracketPricesValid checks the protection prices of a bracket entered in direction. At
+// least one of sl and tp is required; with a limit entry, the stop must be on the losing
+// side of it and the target on the winning side.
+func bracketPricesValid(direction string, entry, sl, tp float64) bool {
+	if sl <= 0 && tp <= 0 {
+		return false
+	}
+	if entry <= 0 {
+		return true
+	}
+	if direction == "Sell" {
+		return (sl <= 0 || sl > entry) && (tp <= 0 || tp < entry)
+	}
+	return (sl <= 0 || sl < entry) && (tp <= 0 || tp > entry)
+}
+
+// today returns the start of the current local day.
+func today() time.Time {
+	y, mo, d := time.Now().Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, time.Local)
 }
 
 // removeDynamicFields removes all form items after the base 4 (instrument, quantity, direction, orderType)
@@ -313,20 +761,20 @@ func (m *OrderModal) GetInstrument() string {
 	return m.instrument.GetText()
 }
 
-func (m *OrderModal) SetQuantity(q float64) {
-	if q == 0 {
+func (m *OrderModal) SetQuantity(q models.Decimal) {
+	if q.Sign() == 0 {
 		m.quantity.SetText("")
 	} else {
-		m.quantity.SetText(strconv.FormatFloat(q, 'f', -1, 64))
+		m.quantity.SetText(q.Trim().String())
 	}
 	m.updateCreateButton()
 	m.updateInfo()
 }
 
-func (m *OrderModal) GetQuantity() float64 {
-	val, err := strconv.ParseFloat(m.quantity.GetText(), 64)
+func (m *OrderModal) GetQuantity() models.Decimal {
+	val, err := models.ParseDecimal(m.quantity.GetText())
 	if err != nil {
-		return 0
+		return models.Decimal{}
 	}
 	return val
 }
@@ -340,9 +788,13 @@ func (m *OrderModal) GetOrderType() string {
 	return m.currentOrderType
 }
 
-// ResetOrderType resets the order type dropdown to Market
+// ResetOrderType resets the order type dropdown to Market, the validity to GTC and the
+// condition to the first one
 func (m *OrderModal) ResetOrderType() {
+	m.setOrderTypes(orderTypeOptions)
 	m.currentOrderType = models.OrderTypeMarket
+	m.currentValidity = models.ValidityGTC
+	m.currentCondition = conditionOptions[0]
 	m.orderType.SetCurrentOption(0)
 	m.rebuildPriceFields()
 }
@@ -360,7 +812,7 @@ func (m *OrderModal) SetDirection(dir string) {
 
 // SetOrderType sets the order type dropdown and rebuilds price fields
 func (m *OrderModal) SetOrderType(orderType string) {
-	for i, opt := range orderTypeOptions {
+	for i, opt := range m.typeOptions {
 		if opt == orderType {
 			m.orderType.SetCurrentOption(i)
 			m.currentOrderType = orderType
@@ -370,34 +822,101 @@ func (m *OrderModal) SetOrderType(orderType string) {
 	}
 }
 
+// GetValidity returns the selected validity, or "" for order types without one
+func (m *OrderModal) GetValidity() string {
+	if !hasValidity(m.currentOrderType) {
+		return ""
+	}
+	return m.currentValidity
+}
+
+// SetValidity selects Day, GTC or GTD (must be called after SetOrderType)
+func (m *OrderModal) SetValidity(validity string) {
+	if m.validity == nil {
+		m.currentValidity = validity
+		return
+	}
+	for i, opt := range validityOptions {
+		if opt == validity {
+			m.validity.SetCurrentOption(i)
+			return
+		}
+	}
+}
+
+// SetValidUntil sets the last day of a GTD order (must be called after SetValidity)
+func (m *OrderModal) SetValidUntil(date time.Time) {
+	if m.validUntilField != nil && !date.IsZero() {
+		m.validUntilField.SetText(date.Format(validUntilLayout))
+	}
+}
+
+// SetCondition selects the condition of a Conditional order and its trigger price (must be
+// called after SetOrderType)
+func (m *OrderModal) SetCondition(c conditional.Condition) {
+	opt := conditionOption(c)
+	for i, o := range conditionOptions {
+		if o == opt {
+			m.currentCondition = opt
+			if m.conditionField != nil {
+				m.conditionField.SetCurrentOption(i)
+			}
+		}
+	}
+	if m.triggerField != nil && c.Price.Sign() > 0 {
+		m.triggerField.SetText(m.priceText(c.Price))
+	}
+}
+
 // SetLimitPrice sets the limit price field value (must be called after SetOrderType)
-func (m *OrderModal) SetLimitPrice(price float64) {
-	if m.limitPriceField != nil && price > 0 {
-		m.limitPriceField.SetText(strconv.FormatFloat(price, 'f', -1, 64))
+func (m *OrderModal) SetLimitPrice(price models.Decimal) {
+	if m.limitPriceField != nil && price.Sign() > 0 {
+		m.limitPriceField.SetText(m.priceText(price))
 	}
 }
 
 // SetStopPrice sets the stop price field value (must be called after SetOrderType)
-func (m *OrderModal) SetStopPrice(price float64) {
-	if m.stopPriceField != nil && price > 0 {
-		m.stopPriceField.SetText(strconv.FormatFloat(price, 'f', -1, 64))
+func (m *OrderModal) SetStopPrice(price models.Decimal) {
+	if m.stopPriceField != nil && price.Sign() > 0 {
+		m.stopPriceField.SetText(m.priceText(price))
 	}
 }
 
 // SetSLPrice sets the SL price field value (must be called after SetOrderType for SL+TP)
-func (m *OrderModal) SetSLPrice(price float64) {
-	if m.slPriceField != nil && price > 0 {
-		m.slPriceField.SetText(strconv.FormatFloat(price, 'f', -1, 64))
+func (m *OrderModal) SetSLPrice(price models.Decimal) {
+	if m.slPriceField != nil && price.Sign() > 0 {
+		m.slPriceField.SetText(m.priceText(price))
 	}
 }
 
 // SetTPPrice sets the TP price field value (must be called after SetOrderType for SL+TP or Take-Profit)
-func (m *OrderModal) SetTPPrice(price float64) {
-	if m.tpPriceField != nil && price > 0 {
-		m.tpPriceField.SetText(strconv.FormatFloat(price, 'f', -1, 64))
+func (m *OrderModal) SetTPPrice(price models.Decimal) {
+	if m.tpPriceField != nil && price.Sign() > 0 {
+		m.tpPriceField.SetText(m.priceText(price))
 	}
 }
 
+// LimitOrderTypes offers only types in the order type dropdown and selects the first one.
+// ResetOrderType offers all types again.
+func (m *OrderModal) LimitOrderTypes(types []string) {
+	m.setOrderTypes(types)
+	m.currentOrderType = types[0]
+	m.rebuildPriceFields()
+}
+
+func (m *OrderModal) setOrderTypes(types []string) {
+	m.typeOptions = types
+	m.orderType.SetOptions(types, func(text string, index int) {
+		if text != m.currentOrderType {
+			m.currentOrderType = text
+			m.rebuildPriceFields()
+			m.updateCreateButton()
+			m.updateInfo()
+		}
+	})
+	m.orderType.SetCurrentOption(0)
+}
+
 // SetModifyTitle sets the modal title for modify mode
 func (m *OrderModal) SetModifyTitle(name string) {
 	if name != "" {
@@ -428,13 +947,15 @@ func (m *OrderModal) RestoreCallback() {
 	}
 }
 
-func (m *OrderModal) getPriceFieldValue(field *tview.InputField) float64 {
+// getPriceFieldValue returns the price in field exactly as typed, or 0 when it is empty or
+// not a number.
+func (m *OrderModal) getPriceFieldValue(field *tview.InputField) models.Decimal {
 	if field == nil {
-		return 0
+		return models.Decimal{}
 	}
-	val, err := strconv.ParseFloat(field.GetText(), 64)
+	val, err := models.ParseDecimal(field.GetText())
 	if err != nil {
-		return 0
+		return models.Decimal{}
 	}
 	return val
 }
@@ -443,32 +964,58 @@ func (m *OrderModal) Validate() bool {
 	if m.GetInstrument() == "" {
 		return false
 	}
-	if m.GetQuantity() <= 0 {
+	if m.GetQuantity().Sign() <= 0 {
 		return false
 	}
 
 	switch m.currentOrderType {
 	case models.OrderTypeLimit:
-		if m.getPriceFieldValue(m.limitPriceField) <= 0 {
+		if m.getPriceFieldValue(m.limitPriceField).Sign() <= 0 {
 			return false
 		}
 	case models.OrderTypeStop:
-		if m.getPriceFieldValue(m.stopPriceField) <= 0 {
+		if m.getPriceFieldValue(m.stopPriceField).Sign() <= 0 {
 			return false
 		}
 	case models.OrderTypeTakeProfit:
-		if m.getPriceFieldValue(m.tpPriceField) <= 0 {
+		if m.getPriceFieldValue(m.tpPriceField).Sign() <= 0 {
 			return false
 		}
 	case models.OrderTypeSLTP:
 		sl := m.getPriceFieldValue(m.slPriceField)
 		tp := m.getPriceFieldValue(m.tpPriceField)
-		if sl <= 0 && tp <= 0 {
+		if sl.Sign() <= 0 && tp.Sign() <= 0 {
 			return false // At least one must be set
 		}
+	case models.OrderTypeStopLimit:
+		if m.getPriceFieldValue(m.stopPriceField).Sign() <= 0 || m.getPriceFieldValue(m.limitPriceField).Sign() <= 0 {
+			return false
+		}
+	case models.OrderTypeTrailing:
+		if _, _, err := trailing.ParseTrail(m.trailField.GetText()); err != nil {
+			return false
+		}
+	case models.OrderTypeBracket:
+		entry := m.getPriceFieldValue(m.limitPriceField)
+		sl := m.getPriceFieldValue(m.slPriceField)
+		tp := m.getPriceFieldValue(m.tpPriceField)
+		if !bracketPricesValid(m.currentDir, entry.Float64(), sl.Float64(), tp.Float64()) {
+			return false
+		}
+	case models.OrderTypeConditional:
+		if m.getPriceFieldValue(m.triggerField).Sign() <= 0 {
+			return false
+		}
+	}
+
+	if m.GetValidity() == models.ValidityGTD {
+		date, ok := m.getValidUntil()
+		if !ok || date.Before(today()) {
+			return false
+		}
 	}
 
-	return true
+	return m.priceError() == ""
 }
 
 func (m *OrderModal) buildSubmission() OrderSubmission {
@@ -489,47 +1036,84 @@ func (m *OrderModal) buildSubmission() OrderSubmission {
 	case models.OrderTypeSLTP:
 		sub.SLPrice = m.getPriceFieldValue(m.slPriceField)
 		sub.TPPrice = m.getPriceFieldValue(m.tpPriceField)
+	case models.OrderTypeStopLimit:
+		sub.StopPrice = m.getPriceFieldValue(m.stopPriceField)
+		sub.LimitPrice = m.getPriceFieldValue(m.limitPriceField)
+	case models.OrderTypeTrailing:
+		sub.TrailDistance, sub.TrailPercent, _ = trailing.ParseTrail(m.trailField.GetText())
+		sub.TrailStep = m.getPriceFieldValue(m.trailStepField)
+	case models.OrderTypeBracket:
+		sub.LimitPrice = m.getPriceFieldValue(m.limitPriceField)
+		sub.SLPrice = m.getPriceFieldValue(m.slPriceField)
+		sub.TPPrice = m.getPriceFieldValue(m.tpPriceField)
+	case models.OrderTypeConditional:
+		sub.Condition = conditionFromOption(m.currentCondition, m.getPriceFieldValue(m.triggerField))
+		sub.LimitPrice = m.getPriceFieldValue(m.limitPriceField)
+	}
+
+	sub.Validity = m.GetValidity()
+	if sub.Validity == models.ValidityGTD {
+		sub.ValidUntil, _ = m.getValidUntil()
 	}
 
 	return sub
 }
 
 // SetLotSize sets the lot size for the current instrument and updates the info display
-func (m *OrderModal) SetLotSize(lotSize float64) {
+func (m *OrderModal) SetLotSize(lotSize models.Decimal) {
 	m.lotSize = lotSize
 	m.updateInfo()
 }
 
 // GetLotSize returns the current lot size
-func (m *OrderModal) GetLotSize() float64 {
+func (m *OrderModal) GetLotSize() models.Decimal {
 	return m.lotSize
 }
 
+// SetPriceStep sets the tick size of the instrument, zero when unknown. Prices already on
+// its grid are shown with its decimals; prices off the grid are left for validation.
+func (m *OrderModal) SetPriceStep(step models.Decimal) {
+	m.priceStep = step
+	for _, field := range m.priceFields() {
+		price := m.getPriceFieldValue(field)
+		if below, above := price.Snap(step); price.Sign() > 0 && below.Equal(above) {
+			field.SetText(m.priceText(price))
+		}
+	}
+	m.updateCreateButton()
+	m.updateInfo()
+}
+
+// GetPriceStep returns the tick size of the instrument, zero when unknown
+func (m *OrderModal) GetPriceStep() models.Decimal {
+	return m.priceStep
+}
+
 // SetPrice sets the current price for estimated cost calculation
-func (m *OrderModal) SetPrice(price float64) {
+func (m *OrderModal) SetPrice(price models.Decimal) {
 	m.price = price
 	m.updateInfo()
 }
 
 // GetTotalShares returns the total shares (quantity in lots * lot size)
-func (m *OrderModal) GetTotalShares() float64 {
+func (m *OrderModal) GetTotalShares() models.Decimal {
 	qty := m.GetQuantity()
-	if m.lotSize > 0 {
-		return qty * m.lotSize
+	if m.lotSize.Sign() > 0 {
+		return qty.Mul(m.lotSize)
 	}
 	return qty
 }
 
 // GetEstimatedCost returns the estimated cost (total shares * price)
-func (m *OrderModal) GetEstimatedCost() float64 {
-	return m.GetTotalShares() * m.price
+func (m *OrderModal) GetEstimatedCost() models.Decimal {
+	return m.GetTotalShares().Mul(m.price)
 }
 
 // updateInfo refreshes the quantity label and info area based on lot size
 func (m *OrderModal) updateInfo() {
 	// Update quantity label to show lot size
-	if m.lotSize > 0 {
-		m.quantity.SetLabel(fmt.Sprintf("Lots (size - %.0f): ", m.lotSize))
+	if m.lotSize.Sign() > 0 {
+		m.quantity.SetLabel(fmt.Sprintf("Lots (size - %s): ", m.lotSize))
 	} else {
 		m.quantity.SetLabel("Quantity:   ")
 	}
@@ -538,14 +1122,23 @@ func (m *OrderModal) updateInfo() {
 	var lines []string
 
 	// Current price reference
-	if m.price > 0 {
-		lines = append(lines, fmt.Sprintf(" Current Price: %.2f", m.price))
+	if m.price.Sign() > 0 {
+		line := fmt.Sprintf(" Current Price: %s", m.price.StringFixed(2))
+		if m.priceStep.Sign() > 0 {
+			line = fmt.Sprintf(" Current Price: %s  Tick: %s", m.priceText(m.price), m.priceStep)
+		}
+		lines = append(lines, line)
 	}
 
 	// Estimated cost
 	qty := m.GetQuantity()
-	if m.lotSize > 0 && qty > 0 && m.price > 0 {
-		lines = append(lines, fmt.Sprintf(" Est. Cost: %.2f", m.GetEstimatedCost()))
+	if m.lotSize.Sign() > 0 && qty.Sign() > 0 && m.price.Sign() > 0 {
+		lines = append(lines, fmt.Sprintf(" Est. Cost: %s", m.GetEstimatedCost().StringFixed(2)))
+	}
+
+	// Prices off the tick grid, with the nearest valid ones
+	if msg := m.priceError(); msg != "" {
+		lines = append(lines, " [red]"+tview.Escape(msg)+"[-]")
 	}
 
 	if len(lines) > 0 {
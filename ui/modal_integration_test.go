@@ -12,7 +12,7 @@ func TestOpenCloseModal(t *testing.T) {
 	app := NewApp(mockClient, accounts)
 	app.selectedIdx = 0
 	app.positions["acc1"] = []models.Position{
-		{Ticker: "SBER", Quantity: "10", CurrentPrice: "250.50", UnrealizedPnL: "100"},
+		{Ticker: "SBER", Quantity: models.DecimalOf("10"), CurrentPrice: models.DecimalOf("250.50"), UnrealizedPnL: models.DecimalOf("100")},
 	}
 
 	// Mock table selection (row 1 is first position)
@@ -34,8 +34,8 @@ func TestOpenCloseModal(t *testing.T) {
 	}
 
 	// Quantity field is intentionally cleared for user input
-	if app.closeModal.GetQuantity() != 0 {
-		t.Errorf("Expected quantity 0, got %f", app.closeModal.GetQuantity())
+	if app.closeModal.GetQuantity().Sign() != 0 {
+		t.Errorf("Expected quantity 0, got %s", app.closeModal.GetQuantity())
 	}
 }
 
@@ -1,9 +1,12 @@
 package ui
 
 import (
+	"finam-terminal/conditional"
 	"finam-terminal/models"
+	"strings"
 	"testing"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
@@ -44,7 +47,7 @@ func TestOrderModal_Validation(t *testing.T) {
 		t.Error("Expected validation to fail with quantity 0")
 	}
 
-	modal.SetQuantity(10)
+	modal.SetQuantity(models.DecimalFromInt(10))
 	if !modal.Validate() {
 		t.Error("Expected validation to pass with valid inputs")
 	}
@@ -72,12 +75,12 @@ func TestOrderModal_SetLotSize(t *testing.T) {
 	modal := NewOrderModal(app, nil, nil)
 
 	// Default lot size should be 0
-	if modal.GetLotSize() != 0 {
+	if !modal.GetLotSize().IsZero() {
 		t.Errorf("Expected default lot size 0, got %v", modal.GetLotSize())
 	}
 
-	modal.SetLotSize(10)
-	if modal.GetLotSize() != 10 {
+	modal.SetLotSize(models.DecimalFromInt(10))
+	if modal.GetLotSize().String() != "10" {
 		t.Errorf("Expected lot size 10, got %v", modal.GetLotSize())
 	}
 
@@ -92,19 +95,19 @@ func TestOrderModal_LotBasedCalculation(t *testing.T) {
 	app := tview.NewApplication()
 	modal := NewOrderModal(app, nil, nil)
 
-	modal.SetLotSize(10)
-	modal.SetPrice(250.50)
-	modal.SetQuantity(2) // 2 lots
+	modal.SetLotSize(models.DecimalFromInt(10))
+	modal.SetPrice(models.DecimalOf("250.50"))
+	modal.SetQuantity(models.DecimalFromInt(2)) // 2 lots
 
 	// Total shares = 2 * 10 = 20
 	totalShares := modal.GetTotalShares()
-	if totalShares != 20 {
+	if totalShares.String() != "20" {
 		t.Errorf("Expected total shares 20, got %v", totalShares)
 	}
 
 	// Estimated cost = 20 * 250.50 = 5010
 	estimatedCost := modal.GetEstimatedCost()
-	if estimatedCost != 5010 {
+	if estimatedCost.Cmp(models.DecimalFromInt(5010)) != 0 {
 		t.Errorf("Expected estimated cost 5010, got %v", estimatedCost)
 	}
 }
@@ -113,9 +116,9 @@ func TestOrderModal_LotInfoDisplay(t *testing.T) {
 	app := tview.NewApplication()
 	modal := NewOrderModal(app, nil, nil)
 
-	modal.SetLotSize(10)
-	modal.SetPrice(250.50)
-	modal.SetQuantity(3)
+	modal.SetLotSize(models.DecimalFromInt(10))
+	modal.SetPrice(models.DecimalOf("250.50"))
+	modal.SetQuantity(models.DecimalFromInt(3))
 
 	// Quantity label should show lot size
 	label := modal.quantity.GetLabel()
@@ -155,18 +158,18 @@ func TestOrderModal_DisplayName(t *testing.T) {
 
 func TestOrderModal_QuantityIsInLots(t *testing.T) {
 	// Verify the callback receives lot quantity (not shares)
-	var receivedQty float64
+	var receivedQty models.Decimal
 	app := tview.NewApplication()
 	modal := NewOrderModal(app, func(sub OrderSubmission) {
 		receivedQty = sub.Quantity
 	}, nil)
 
 	modal.SetInstrument("SBER")
-	modal.SetLotSize(10)
-	modal.SetQuantity(5) // 5 lots
+	modal.SetLotSize(models.DecimalFromInt(10))
+	modal.SetQuantity(models.DecimalFromInt(5)) // 5 lots
 
 	// GetQuantity should return the lot-based quantity (5), not shares (50)
-	if modal.GetQuantity() != 5 {
+	if modal.GetQuantity().Float64() != 5 {
 		t.Errorf("Expected GetQuantity to return 5 (lots), got %v", modal.GetQuantity())
 	}
 
@@ -175,7 +178,7 @@ func TestOrderModal_QuantityIsInLots(t *testing.T) {
 		modal.callback(modal.buildSubmission())
 	}
 
-	if receivedQty != 5 {
+	if receivedQty.Float64() != 5 {
 		t.Errorf("Expected callback to receive 5 (lots), got %v", receivedQty)
 	}
 }
@@ -194,7 +197,7 @@ func TestOrderModal_LimitValidation(t *testing.T) {
 	modal := NewOrderModal(app, nil, nil)
 
 	modal.SetInstrument("SBER")
-	modal.SetQuantity(1)
+	modal.SetQuantity(models.DecimalFromInt(1))
 
 	// Switch to Limit
 	modal.currentOrderType = models.OrderTypeLimit
@@ -219,7 +222,7 @@ func TestOrderModal_StopValidation(t *testing.T) {
 	modal := NewOrderModal(app, nil, nil)
 
 	modal.SetInstrument("SBER")
-	modal.SetQuantity(1)
+	modal.SetQuantity(models.DecimalFromInt(1))
 
 	// Switch to Stop-Loss
 	modal.currentOrderType = models.OrderTypeStop
@@ -244,7 +247,7 @@ func TestOrderModal_TakeProfitValidation(t *testing.T) {
 	modal := NewOrderModal(app, nil, nil)
 
 	modal.SetInstrument("SBER")
-	modal.SetQuantity(1)
+	modal.SetQuantity(models.DecimalFromInt(1))
 
 	// Switch to Take-Profit
 	modal.currentOrderType = models.OrderTypeTakeProfit
@@ -269,7 +272,7 @@ func TestOrderModal_SLTPValidation(t *testing.T) {
 	modal := NewOrderModal(app, nil, nil)
 
 	modal.SetInstrument("SBER")
-	modal.SetQuantity(1)
+	modal.SetQuantity(models.DecimalFromInt(1))
 
 	// Switch to SL+TP
 	modal.currentOrderType = models.OrderTypeSLTP
@@ -319,3 +322,258 @@ func TestOrderModal_ResetOrderType(t *testing.T) {
 		t.Error("Expected limit price field to be nil after reset")
 	}
 }
+
+func TestOrderModal_StopLimitValidation(t *testing.T) {
+	app := tview.NewApplication()
+	modal := NewOrderModal(app, nil, nil)
+
+	modal.SetInstrument("SBER")
+	modal.SetQuantity(models.DecimalFromInt(1))
+	modal.SetOrderType(models.OrderTypeStopLimit)
+
+	if modal.stopPriceField == nil || modal.limitPriceField == nil {
+		t.Fatal("Expected stop and limit price fields for Stop-Limit")
+	}
+
+	// Both prices are required
+	modal.SetStopPrice(models.DecimalFromInt(245))
+	if modal.Validate() {
+		t.Error("Expected validation to fail without limit price")
+	}
+
+	modal.SetLimitPrice(models.DecimalOf("244.5"))
+	if !modal.Validate() {
+		t.Error("Expected validation to pass with stop and limit prices set")
+	}
+
+	sub := modal.buildSubmission()
+	if sub.StopPrice.Float64() != 245 || sub.LimitPrice.Float64() != 244.5 || sub.Validity != models.ValidityGTC {
+		t.Errorf("Unexpected submission: %+v", sub)
+	}
+}
+
+func TestOrderModal_ValidityFields(t *testing.T) {
+	app := tview.NewApplication()
+	modal := NewOrderModal(app, nil, nil)
+
+	modal.SetInstrument("SBER")
+	modal.SetQuantity(models.DecimalFromInt(1))
+
+	// Market orders have no validity choice
+	modal.SetOrderType(models.OrderTypeMarket)
+	if modal.validity != nil || modal.GetValidity() != "" {
+		t.Error("Expected no validity field for Market orders")
+	}
+
+	modal.SetOrderType(models.OrderTypeLimit)
+	if modal.validity == nil || modal.GetValidity() != models.ValidityGTC {
+		t.Error("Expected a validity field for Limit orders")
+	}
+
+	modal.SetOrderType(models.OrderTypeStop)
+	modal.SetStopPrice(models.DecimalFromInt(240))
+	if modal.validity == nil {
+		t.Fatal("Expected a validity field for Stop-Loss orders")
+	}
+	items := modal.Form.GetFormItemCount()
+
+	// GTD adds the date field without touching the typed price
+	modal.SetValidity(models.ValidityGTD)
+	if modal.validUntilField == nil || modal.Form.GetFormItemCount() != items+1 {
+		t.Fatal("Expected the Valid Until field after selecting GTD")
+	}
+	if modal.stopPriceField.GetText() != "240" {
+		t.Errorf("Expected the stop price to be kept, got %q", modal.stopPriceField.GetText())
+	}
+
+	tomorrow := today().AddDate(0, 0, 1)
+	if date, ok := modal.getValidUntil(); !ok || !date.Equal(tomorrow) {
+		t.Errorf("Expected tomorrow as the default date, got %v", date)
+	}
+
+	modal.SetValidity(models.ValidityDay)
+	if modal.validUntilField != nil || modal.Form.GetFormItemCount() != items {
+		t.Error("Expected the Valid Until field to be removed for Day")
+	}
+	if modal.buildSubmission().Validity != models.ValidityDay {
+		t.Error("Expected Day validity in the submission")
+	}
+}
+
+func TestOrderModal_GTDValidation(t *testing.T) {
+	app := tview.NewApplication()
+	modal := NewOrderModal(app, nil, nil)
+
+	modal.SetInstrument("SBER")
+	modal.SetQuantity(models.DecimalFromInt(1))
+	modal.SetOrderType(models.OrderTypeTakeProfit)
+	modal.SetTPPrice(models.DecimalFromInt(270))
+	modal.SetValidity(models.ValidityGTD)
+
+	modal.validUntilField.SetText("31.02.2026")
+	if modal.Validate() {
+		t.Error("Expected validation to fail with an invalid date")
+	}
+
+	modal.SetValidUntil(today().AddDate(0, 0, -1))
+	if modal.Validate() {
+		t.Error("Expected validation to fail with a past date")
+	}
+
+	until := today().AddDate(0, 0, 7)
+	modal.SetValidUntil(until)
+	if !modal.Validate() {
+		t.Error("Expected validation to pass with a future date")
+	}
+	if sub := modal.buildSubmission(); !sub.ValidUntil.Equal(until) {
+		t.Errorf("Expected ValidUntil %v, got %v", until, sub.ValidUntil)
+	}
+
+	// Reset returns to GTC
+	modal.ResetOrderType()
+	modal.SetOrderType(models.OrderTypeStop)
+	if modal.GetValidity() != models.ValidityGTC || modal.validUntilField != nil {
+		t.Errorf("Expected GTC after reset, got %s", modal.GetValidity())
+	}
+}
+
+func TestOrderModal_TrailingValidation(t *testing.T) {
+	app := tview.NewApplication()
+	modal := NewOrderModal(app, nil, nil)
+
+	modal.SetInstrument("SBER")
+	modal.SetQuantity(models.DecimalFromInt(1))
+	modal.SetOrderType(models.OrderTypeTrailing)
+
+	if modal.trailField == nil || modal.validity != nil {
+		t.Fatal("Expected a trail field and no validity for Trailing")
+	}
+	if modal.Validate() {
+		t.Error("Expected validation to fail without a trail")
+	}
+
+	modal.trailField.SetText("1.5%")
+	modal.trailStepField.SetText("0.5")
+	if !modal.Validate() {
+		t.Error("Expected validation to pass with a percent trail")
+	}
+	sub := modal.buildSubmission()
+	if !sub.TrailPercent.Equal(models.DecimalOf("1.5")) || !sub.TrailDistance.IsZero() || !sub.TrailStep.Equal(models.DecimalOf("0.5")) {
+		t.Errorf("Unexpected submission: %+v", sub)
+	}
+
+	modal.trailField.SetText("5")
+	if sub := modal.buildSubmission(); !sub.TrailDistance.Equal(models.DecimalFromInt(5)) || !sub.TrailPercent.IsZero() {
+		t.Errorf("Expected a distance trail, got %+v", sub)
+	}
+}
+
+func TestOrderModal_BracketFields(t *testing.T) {
+	app := tview.NewApplication()
+	modal := NewOrderModal(app, nil, nil)
+
+	modal.SetInstrument("SBER")
+	modal.SetQuantity(models.DecimalFromInt(1))
+	modal.SetOrderType(models.OrderTypeBracket)
+
+	if modal.limitPriceField == nil || modal.slPriceField == nil || modal.tpPriceField == nil {
+		t.Fatal("Expected entry, SL and TP fields for Bracket")
+	}
+
+	// Market entry with a stop only
+	modal.limitPriceField.SetText("")
+	modal.SetSLPrice(models.DecimalFromInt(290))
+	if !modal.Validate() {
+		t.Error("Expected a market entry with SL to be valid")
+	}
+
+	modal.SetLimitPrice(models.DecimalFromInt(280))
+	if modal.Validate() {
+		t.Error("Expected a buy stop above the entry to be invalid")
+	}
+
+	modal.SetLimitPrice(models.DecimalFromInt(300))
+	modal.SetTPPrice(models.DecimalFromInt(320))
+	sub := modal.buildSubmission()
+	if sub.LimitPrice.Float64() != 300 || sub.SLPrice.Float64() != 290 || sub.TPPrice.Float64() != 320 {
+		t.Errorf("Unexpected submission: %+v", sub)
+	}
+}
+
+func TestOrderModal_ConditionalFields(t *testing.T) {
+	app := tview.NewApplication()
+	modal := NewOrderModal(app, nil, nil)
+
+	modal.SetInstrument("SBER")
+	modal.SetQuantity(models.DecimalFromInt(5))
+	modal.SetOrderType(models.OrderTypeConditional)
+
+	if modal.conditionField == nil || modal.triggerField == nil || modal.limitPriceField == nil {
+		t.Fatal("Expected condition, trigger and limit fields for Conditional")
+	}
+	if modal.Validate() {
+		t.Error("Expected a conditional order without a trigger price to be invalid")
+	}
+
+	modal.SetCondition(conditional.Condition{Field: conditional.FieldBid, Price: models.DecimalFromInt(290)})
+	sub := modal.buildSubmission()
+	want := conditional.Condition{Field: conditional.FieldBid, Price: models.DecimalFromInt(290)}
+	if !modal.Validate() || !sameCondition(sub.Condition, want) || sub.LimitPrice.Float64() != 0 {
+		t.Errorf("Expected a market order when BID crosses below 290, got %+v", sub)
+	}
+
+	modal.SetCondition(conditional.Condition{Field: conditional.FieldLast, Above: true, Price: models.DecimalFromInt(310)})
+	modal.SetLimitPrice(models.DecimalOf("310.5"))
+	sub = modal.buildSubmission()
+	want = conditional.Condition{Field: conditional.FieldLast, Above: true, Price: models.DecimalFromInt(310)}
+	if !sameCondition(sub.Condition, want) || sub.LimitPrice.Float64() != 310.5 {
+		t.Errorf("Expected a limit at 310.5 when LAST crosses above 310, got %+v", sub)
+	}
+}
+
+// sameCondition compares conditions by value, ignoring how the price was written.
+func sameCondition(a, b conditional.Condition) bool {
+	return a.Field == b.Field && a.Above == b.Above && a.Price.Equal(b.Price)
+}
+
+func TestOrderModal_PriceStep(t *testing.T) {
+	app := tview.NewApplication()
+	modal := NewOrderModal(app, nil, nil)
+
+	modal.SetInstrument("SBER")
+	modal.SetQuantity(models.DecimalFromInt(1))
+	modal.SetOrderType(models.OrderTypeLimit)
+	modal.SetLimitPrice(models.DecimalOf("300.1"))
+	modal.SetPriceStep(models.AssetDetails{Decimals: 2, MinStep: 5}.PriceStep())
+	if got := modal.limitPriceField.GetText(); got != "300.10" {
+		t.Errorf("Expected the price shown with two decimals, got %q", got)
+	}
+
+	modal.limitPriceField.SetText("300.12")
+	if modal.Validate() {
+		t.Error("Expected a price off the 0.05 tick to be invalid")
+	}
+	if info := modal.infoArea.GetText(true); !strings.Contains(info, "Limit Price 300.12 is off the 0.05 tick: use 300.10 or 300.15") {
+		t.Errorf("Expected the nearest valid prices in the info area, got %q", info)
+	}
+
+	// Leaving the field snaps the price to the nearest tick
+	key := func(k tcell.Key) {
+		modal.limitPriceField.InputHandler()(tcell.NewEventKey(k, 0, tcell.ModNone), func(tview.Primitive) {})
+	}
+	key(tcell.KeyTab)
+	if got := modal.limitPriceField.GetText(); got != "300.10" || !modal.Validate() {
+		t.Errorf("Expected the price snapped to 300.10, got %q", got)
+	}
+
+	key(tcell.KeyUp)
+	key(tcell.KeyUp)
+	if got := modal.limitPriceField.GetText(); got != "300.20" {
+		t.Errorf("Expected two ticks up to reach 300.20, got %q", got)
+	}
+	modal.limitPriceField.SetText("300.17")
+	key(tcell.KeyDown)
+	if got := modal.limitPriceField.GetText(); got != "300.15" {
+		t.Errorf("Expected Down to move an off-grid price to the tick below, got %q", got)
+	}
+}
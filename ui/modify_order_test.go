@@ -1,8 +1,10 @@
 package ui
 
 import (
+	"finam-terminal/api"
 	"finam-terminal/models"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/rivo/tview"
@@ -18,8 +20,8 @@ func TestMapOrderTypeToModal(t *testing.T) {
 		{"Stop", models.OrderTypeStop},
 		{"Take-Profit", models.OrderTypeTakeProfit},
 		{"SL/TP", models.OrderTypeSLTP},
-		{"Unknown", ""},        // unsupported type
-		{"Stop-Limit", ""},     // unsupported type
+		{"Stop-Limit", models.OrderTypeStopLimit},
+		{"Unknown", ""}, // unsupported type
 	}
 
 	for _, tt := range tests {
@@ -35,7 +37,7 @@ func setupModalPage(app *App) {
 		AddItem(nil, 0, 1, false).
 		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
 			AddItem(nil, 0, 1, false).
-			AddItem(app.orderModal.Layout, 20, 1, true).
+			AddItem(app.orderModal.Layout, 24, 1, true).
 			AddItem(nil, 0, 1, false), 50, 1, true).
 		AddItem(nil, 0, 1, false)
 	app.pages.AddPage("modal", modalFlex, true, false)
@@ -43,7 +45,7 @@ func setupModalPage(app *App) {
 
 func TestShowModifyOrderModal_PreFillsLimitOrder(t *testing.T) {
 	mock := &mockClient{
-		GetLotSizeFunc: func(ticker string) float64 { return 10 },
+		GetLotSizeFunc: func(ticker string) models.Decimal { return models.DecimalFromInt(10) },
 		GetInstrumentNameFunc: func(key string) string {
 			if key == "SBER@MOEX" {
 				return "Sberbank"
@@ -61,8 +63,8 @@ func TestShowModifyOrderModal_PreFillsLimitOrder(t *testing.T) {
 			Side:       "Buy",
 			Type:       "Limit",
 			Status:     "Active",
-			Quantity:   "100",
-			LimitPrice: "250.50",
+			Quantity:   models.DecimalOf("100"),
+			LimitPrice: models.DecimalOf("250.50"),
 		},
 	}
 
@@ -87,21 +89,21 @@ func TestShowModifyOrderModal_PreFillsLimitOrder(t *testing.T) {
 		t.Errorf("OrderType = %q, want %q", got, models.OrderTypeLimit)
 	}
 	// Quantity: 100 shares / 10 lot size = 10 lots
-	if got := app.orderModal.GetQuantity(); got != 10 {
+	if got := app.orderModal.GetQuantity(); got.Float64() != 10 {
 		t.Errorf("Quantity = %v, want %v", got, 10.0)
 	}
 	// Limit price field should be set
 	if app.orderModal.limitPriceField == nil {
 		t.Fatal("Expected limitPriceField to be created")
 	}
-	if got := app.orderModal.getPriceFieldValue(app.orderModal.limitPriceField); got != 250.50 {
+	if got := app.orderModal.getPriceFieldValue(app.orderModal.limitPriceField); got.Float64() != 250.50 {
 		t.Errorf("LimitPrice = %v, want %v", got, 250.50)
 	}
 }
 
 func TestShowModifyOrderModal_PreFillsStopOrder(t *testing.T) {
 	mock := &mockClient{
-		GetLotSizeFunc: func(ticker string) float64 { return 1 },
+		GetLotSizeFunc: func(ticker string) models.Decimal { return models.DecimalFromInt(1) },
 	}
 	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
 	app.activeOrders["acc1"] = []models.Order{
@@ -111,8 +113,8 @@ func TestShowModifyOrderModal_PreFillsStopOrder(t *testing.T) {
 			Side:      "Sell",
 			Type:      "Stop",
 			Status:    "Active",
-			Quantity:  "50",
-			StopPrice: "180.00",
+			Quantity:  models.DecimalOf("50"),
+			StopPrice: models.DecimalOf("180.00"),
 		},
 	}
 
@@ -130,14 +132,14 @@ func TestShowModifyOrderModal_PreFillsStopOrder(t *testing.T) {
 	if app.orderModal.stopPriceField == nil {
 		t.Fatal("Expected stopPriceField to be created")
 	}
-	if got := app.orderModal.getPriceFieldValue(app.orderModal.stopPriceField); got != 180.00 {
+	if got := app.orderModal.getPriceFieldValue(app.orderModal.stopPriceField); got.Float64() != 180.00 {
 		t.Errorf("StopPrice = %v, want %v", got, 180.00)
 	}
 }
 
 func TestShowModifyOrderModal_PreFillsSLTPOrder(t *testing.T) {
 	mock := &mockClient{
-		GetLotSizeFunc: func(ticker string) float64 { return 1 },
+		GetLotSizeFunc: func(ticker string) models.Decimal { return models.DecimalFromInt(1) },
 	}
 	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
 	app.activeOrders["acc1"] = []models.Order{
@@ -147,9 +149,9 @@ func TestShowModifyOrderModal_PreFillsSLTPOrder(t *testing.T) {
 			Side:     "Buy",
 			Type:     "SL/TP",
 			Status:   "Active",
-			Quantity: "200",
-			SLPrice:  "90.00",
-			TPPrice:  "120.00",
+			Quantity: models.DecimalOf("200"),
+			SLPrice:  models.DecimalOf("90.00"),
+			TPPrice:  models.DecimalOf("120.00"),
 		},
 	}
 
@@ -167,10 +169,10 @@ func TestShowModifyOrderModal_PreFillsSLTPOrder(t *testing.T) {
 	if app.orderModal.tpPriceField == nil {
 		t.Fatal("Expected tpPriceField to be created")
 	}
-	if got := app.orderModal.getPriceFieldValue(app.orderModal.slPriceField); got != 90.00 {
+	if got := app.orderModal.getPriceFieldValue(app.orderModal.slPriceField); got.Float64() != 90.00 {
 		t.Errorf("SLPrice = %v, want %v", got, 90.00)
 	}
-	if got := app.orderModal.getPriceFieldValue(app.orderModal.tpPriceField); got != 120.00 {
+	if got := app.orderModal.getPriceFieldValue(app.orderModal.tpPriceField); got.Float64() != 120.00 {
 		t.Errorf("TPPrice = %v, want %v", got, 120.00)
 	}
 }
@@ -195,12 +197,12 @@ func TestShowModifyOrderModal_NonCancellableOrderRejected(t *testing.T) {
 
 func TestShowModifyOrderModal_TitleShowsModify(t *testing.T) {
 	mock := &mockClient{
-		GetLotSizeFunc:        func(ticker string) float64 { return 1 },
+		GetLotSizeFunc:        func(ticker string) models.Decimal { return models.DecimalFromInt(1) },
 		GetInstrumentNameFunc: func(key string) string { return "Sberbank" },
 	}
 	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
 	app.activeOrders["acc1"] = []models.Order{
-		{ID: "O1", Symbol: "SBER", Name: "Sberbank", Side: "Buy", Type: "Market", Status: "Active", Quantity: "10"},
+		{ID: "O1", Symbol: "SBER", Name: "Sberbank", Side: "Buy", Type: "Market", Status: "Active", Quantity: models.DecimalOf("10")},
 	}
 
 	updateOrdersTable(app)
@@ -218,23 +220,23 @@ func TestShowModifyOrderModal_TitleShowsModify(t *testing.T) {
 	}
 }
 
-func TestModifyOrderFlow_CancelThenPlace(t *testing.T) {
-	cancelCalled := false
-	placeCalled := false
+func TestModifyOrderFlow_ReplacesThroughClient(t *testing.T) {
+	var replacedID string
 	var placedParams *models.OrderParams
 	done := make(chan struct{})
 
 	mock := &mockClient{
-		GetLotSizeFunc: func(ticker string) float64 { return 1 },
+		GetLotSizeFunc: func(ticker string) models.Decimal { return models.DecimalFromInt(1) },
 		CancelOrderFunc: func(accountID, orderID string) error {
-			cancelCalled = true
-			if orderID != "O1" {
-				t.Errorf("Expected cancel orderID O1, got %s", orderID)
-			}
+			t.Error("The UI must not cancel the old order itself")
 			return nil
 		},
-		PlaceOrderFunc: func(accountID, symbol, buySell string, quantity float64, params *models.OrderParams) (string, error) {
-			placeCalled = true
+		PlaceOrderFunc: func(accountID, symbol, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error) {
+			t.Error("The UI must not place the replacement itself")
+			return "", nil
+		},
+		ModifyOrderFunc: func(accountID, orderID, symbol, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error) {
+			replacedID = orderID
 			placedParams = params
 			return "NEW-1", nil
 		},
@@ -252,8 +254,8 @@ func TestModifyOrderFlow_CancelThenPlace(t *testing.T) {
 			Side:       "Buy",
 			Type:       "Limit",
 			Status:     "Active",
-			Quantity:   "10",
-			LimitPrice: "250",
+			Quantity:   models.DecimalOf("10"),
+			LimitPrice: models.DecimalOf("250"),
 		},
 	}
 
@@ -270,52 +272,56 @@ func TestModifyOrderFlow_CancelThenPlace(t *testing.T) {
 	// Wait for the goroutine to complete
 	<-done
 
-	if !cancelCalled {
-		t.Error("Expected CancelOrder to be called")
-	}
-	if !placeCalled {
-		t.Error("Expected PlaceOrder to be called")
+	if replacedID != "O1" {
+		t.Errorf("Expected order O1 to be replaced, got %q", replacedID)
 	}
-	if placedParams == nil {
-		t.Fatal("Expected order params to be set for Limit order")
+	if placedParams == nil || placedParams.LimitPrice.Float64() != 250 {
+		t.Fatalf("Expected limit order params at 250, got %+v", placedParams)
 	}
 }
 
-func TestModifyOrderFlow_CancelFails_NoNewOrder(t *testing.T) {
-	placeCalled := false
+func TestModifyOrderFlow_SLTPUsesSLTPReplace(t *testing.T) {
 	done := make(chan struct{})
+	var slPrice, tpPrice models.Decimal
 
 	mock := &mockClient{
-		GetLotSizeFunc: func(ticker string) float64 { return 1 },
-		CancelOrderFunc: func(accountID, orderID string) error {
-			defer func() { close(done) }()
-			return fmt.Errorf("rpc error: code = NotFound desc = Order already executed")
-		},
-		PlaceOrderFunc: func(accountID, symbol, buySell string, quantity float64, params *models.OrderParams) (string, error) {
-			placeCalled = true
+		GetLotSizeFunc: func(ticker string) models.Decimal { return models.DecimalFromInt(1) },
+		ModifySLTPOrderFunc: func(accountID, orderID, symbol, buySell string, slQty, sl, tpQty, tp models.Decimal) (string, error) {
+			slPrice, tpPrice = sl, tp
 			return "NEW-1", nil
 		},
+		GetActiveOrdersFunc: func(accountID string) ([]models.Order, error) {
+			defer func() { close(done) }()
+			return nil, nil
+		},
 	}
 
 	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
-	setupModalPage(app)
 	app.activeOrders["acc1"] = []models.Order{
-		{ID: "O1", Symbol: "SBER", Side: "Buy", Type: "Limit", Status: "Active", Quantity: "10", LimitPrice: "250"},
+		{ID: "O1", Symbol: "SBER", Side: "Sell", Type: "SL/TP", Status: "Active", Quantity: models.DecimalOf("10"), SLPrice: models.DecimalOf("240"), TPPrice: models.DecimalOf("260")},
 	}
 
 	updateOrdersTable(app)
 	app.portfolioView.TabbedView.OrdersTable.Select(1, 0)
 
 	app.ShowModifyOrderModal()
+	app.orderModal.GetCallback()(app.orderModal.buildSubmission())
+	<-done
 
-	sub := app.orderModal.buildSubmission()
-	app.orderModal.GetCallback()(sub)
+	if slPrice.Float64() != 240 || tpPrice.Float64() != 260 {
+		t.Errorf("Expected SL 240 and TP 260, got %v and %v", slPrice, tpPrice)
+	}
+}
 
-	// Wait for the goroutine to complete
-	<-done
+func TestModifyFailureMessage(t *testing.T) {
+	kept := modifyFailureMessage("O1", &api.ModifyError{OldOrderID: "O1", Err: fmt.Errorf("insufficient funds")})
+	if !strings.Contains(kept, "original order O1 was kept") {
+		t.Errorf("Expected the old order to be reported as kept, got %q", kept)
+	}
 
-	if placeCalled {
-		t.Error("PlaceOrder should NOT be called when cancel fails")
+	both := modifyFailureMessage("O1", &api.ModifyError{OldOrderID: "O1", NewOrderID: "N1", Err: fmt.Errorf("connection lost")})
+	if !strings.Contains(both, "N1") || !strings.Contains(both, "Both orders may be active") {
+		t.Errorf("Expected a warning about both orders, got %q", both)
 	}
 }
 
@@ -324,11 +330,11 @@ func TestModifyOrderFlow_CallbackRestoredAfterModify(t *testing.T) {
 	done := make(chan struct{})
 
 	mock := &mockClient{
-		GetLotSizeFunc: func(ticker string) float64 { return 1 },
+		GetLotSizeFunc: func(ticker string) models.Decimal { return models.DecimalFromInt(1) },
 		CancelOrderFunc: func(accountID, orderID string) error {
 			return nil
 		},
-		PlaceOrderFunc: func(accountID, symbol, buySell string, quantity float64, params *models.OrderParams) (string, error) {
+		PlaceOrderFunc: func(accountID, symbol, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error) {
 			return "NEW-1", nil
 		},
 		GetActiveOrdersFunc: func(accountID string) ([]models.Order, error) {
@@ -352,7 +358,7 @@ func TestModifyOrderFlow_CallbackRestoredAfterModify(t *testing.T) {
 	}
 
 	app.activeOrders["acc1"] = []models.Order{
-		{ID: "O1", Symbol: "SBER", Side: "Buy", Type: "Market", Status: "Active", Quantity: "10"},
+		{ID: "O1", Symbol: "SBER", Side: "Buy", Type: "Market", Status: "Active", Quantity: models.DecimalOf("10")},
 	}
 
 	updateOrdersTable(app)
@@ -374,3 +380,86 @@ func TestModifyOrderFlow_CallbackRestoredAfterModify(t *testing.T) {
 		t.Error("Original callback should be restored after modify")
 	}
 }
+
+func TestModifyOrderFlow_RiskViolationKeepsOldOrder(t *testing.T) {
+	mock := &mockClient{
+		GetLotSizeFunc: func(ticker string) models.Decimal { return models.DecimalFromInt(1) },
+		CancelOrderFunc: func(accountID, orderID string) error {
+			t.Error("CancelOrder should NOT be called when the new order fails the risk checks")
+			return nil
+		},
+	}
+
+	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
+	app.SetRiskLimits(models.RiskLimits{MaxLots: 5, AllowOverride: true})
+	setupModalPage(app)
+	app.activeOrders["acc1"] = []models.Order{
+		{ID: "O1", Symbol: "SBER", Side: "Buy", Type: "Limit", Status: "Active", Quantity: models.DecimalOf("10"), LimitPrice: models.DecimalOf("250")},
+	}
+
+	updateOrdersTable(app)
+	app.portfolioView.TabbedView.OrdersTable.Select(1, 0)
+
+	app.ShowModifyOrderModal()
+	modifyCallback := app.orderModal.GetCallback()
+
+	sub := app.orderModal.buildSubmission()
+	modifyCallback(sub)
+
+	if !app.IsRiskConfirmOpen() {
+		t.Fatal("Expected the risk violation dialog to be shown")
+	}
+	// The modal stays in modify mode so the order can be corrected
+	if app.orderModal.originalCallback == nil {
+		t.Error("Expected the modify callback to stay active")
+	}
+}
+
+func TestShowModifyOrderModal_OffersReplaceableTypesOnly(t *testing.T) {
+	mock := &mockClient{
+		GetLotSizeFunc: func(ticker string) models.Decimal { return models.DecimalFromInt(1) },
+	}
+	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
+	setupModalPage(app)
+	app.activeOrders["acc1"] = []models.Order{
+		{ID: "O1", Symbol: "SBER", Side: "Buy", Type: "Limit", Status: "Active", Quantity: models.DecimalOf("10"), LimitPrice: models.DecimalOf("250")},
+	}
+	updateOrdersTable(app)
+	app.portfolioView.TabbedView.OrdersTable.Select(1, 0)
+
+	app.ShowModifyOrderModal()
+
+	if got := app.orderModal.orderType.GetOptionCount(); got != len(replaceableOrderTypes) {
+		t.Errorf("Modify dropdown offers %d types, want %d", got, len(replaceableOrderTypes))
+	}
+	app.orderModal.SetOrderType(models.OrderTypeTrailing)
+	if got := app.orderModal.GetOrderType(); got != models.OrderTypeLimit {
+		t.Errorf("OrderType = %q after selecting Trailing, want %q", got, models.OrderTypeLimit)
+	}
+
+	app.CloseOrderModal()
+	app.OpenOrderModalWithTicker("SBER")
+	if got := app.orderModal.orderType.GetOptionCount(); got != len(orderTypeOptions) {
+		t.Errorf("New order dropdown offers %d types, want %d", got, len(orderTypeOptions))
+	}
+}
+
+func TestSendSubmission_RejectsUnreplaceableTypes(t *testing.T) {
+	mock := &mockClient{
+		ModifyOrderFunc: func(accountID, orderID, symbol, buySell string, quantity models.Decimal, params *models.OrderParams) (string, error) {
+			t.Error("No order must be sent")
+			return "", nil
+		},
+	}
+	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
+
+	for _, typ := range []string{models.OrderTypeTrailing, models.OrderTypeBracket, models.OrderTypeConditional} {
+		sub := OrderSubmission{Instrument: "SBER", Direction: "Buy", Quantity: models.DecimalFromInt(1), OrderType: typ}
+		if _, err := app.sendSubmission("acc1", sub, "O1"); err == nil {
+			t.Errorf("%s replacing O1: expected an error", typ)
+		}
+	}
+	if n := len(app.trailing.Stops("acc1")) + len(app.conditional.Orders("acc1")); n != 0 {
+		t.Errorf("Expected nothing armed, got %d orders", n)
+	}
+}
@@ -0,0 +1,250 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"finam-terminal/models"
+	"finam-terminal/oco"
+)
+
+// SetOCOStore loads the OCO groups saved in path and keeps saving them there.
+// Without a store, OCO groups last until the terminal exits.
+func (a *App) SetOCOStore(path string) error {
+	m := oco.NewManager(path)
+	if err := m.Load(); err != nil {
+		return err
+	}
+	a.oco = m
+	if n := len(m.Groups("")); n > 0 {
+		log.Printf("[INFO] Resumed %d OCO groups from %s", n, path)
+	}
+	return nil
+}
+
+// toggleOCOMark marks or unmarks the selected order for linking into an OCO group.
+// Must be called on the UI thread.
+func (a *App) toggleOCOMark() {
+	order, err := a.getSelectedOrder()
+	if err != nil {
+		a.SetStatus("No order selected", StatusError)
+		return
+	}
+	if a.ocoMarked[order.ID] {
+		delete(a.ocoMarked, order.ID)
+	} else {
+		if !isOrderCancellable(order.Status) {
+			a.SetStatus("Only active orders can be linked", StatusError)
+			return
+		}
+		a.ocoMarked[order.ID] = true
+	}
+	updateOrdersTable(a)
+
+	if n := len(a.ocoMarked); n > 0 {
+		a.SetStatus(fmt.Sprintf("%d orders marked — press G to link them as OCO", n), StatusInfo)
+	} else {
+		a.SetStatus("No orders marked", StatusInfo)
+	}
+}
+
+// linkOCOGroup links the marked orders of the selected account into an OCO group.
+// Must be called on the UI thread.
+func (a *App) linkOCOGroup() {
+	a.dataMutex.RLock()
+	accountID := a.accounts[a.selectedIdx].ID
+	orders := a.activeOrders[accountID]
+	a.dataMutex.RUnlock()
+
+	var ids []string
+	for _, o := range orders {
+		if !a.ocoMarked[o.ID] || !isOrderCancellable(o.Status) {
+			continue
+		}
+		if _, ok := a.trailing.ByOrderID(o.ID); ok {
+			a.SetStatus("Trailing stops cannot be linked: their order changes as they move", StatusError)
+			return
+		}
+		ids = append(ids, o.ID)
+	}
+
+	g, err := a.oco.Link(accountID, ids)
+	if err != nil && g.ID == "" {
+		a.SetStatus(fmt.Sprintf("OCO not linked: %s — mark orders with Space", err), StatusError)
+		return
+	}
+	if err != nil {
+		log.Printf("[ERROR] OCO group %s will not survive a restart: %v", g.ID, err)
+	}
+	a.ocoMarked = make(map[string]bool)
+	updateOrdersTable(a)
+
+	log.Printf("[INFO] OCO group %s linked: %v", g.ID, g.OrderIDs)
+	a.SetStatus(fmt.Sprintf("OCO group %s: %d orders linked", g.ID, len(g.OrderIDs)), StatusSuccess)
+}
+
+// unlinkOCOGroup dissolves the OCO group of the selected order, leaving its orders working.
+// Must be called on the UI thread.
+func (a *App) unlinkOCOGroup() {
+	order, err := a.getSelectedOrder()
+	if err != nil {
+		a.SetStatus("No order selected", StatusError)
+		return
+	}
+	g, ok := a.oco.GroupOf(order.ID)
+	if !ok {
+		a.SetStatus("Order is not in an OCO group", StatusError)
+		return
+	}
+	if err := a.oco.Unlink(g.ID); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+	updateOrdersTable(a)
+
+	log.Printf("[INFO] OCO group %s unlinked", g.ID)
+	a.SetStatus(fmt.Sprintf("OCO group %s unlinked", g.ID), StatusSuccess)
+}
+
+// enforceOCO cancels the siblings of every OCO member of accountID that filled, even
+// partially, and drops members that ended without a fill. Members missing from the list
+// are kept.
+func (a *App) enforceOCO(accountID string) {
+	groups := a.oco.Groups(accountID)
+	if len(groups) == 0 {
+		return
+	}
+
+	a.dataMutex.RLock()
+	orders := make(map[string]models.Order, len(a.activeOrders[accountID]))
+	for _, o := range a.activeOrders[accountID] {
+		orders[o.ID] = o
+	}
+	a.dataMutex.RUnlock()
+
+	for _, g := range groups {
+		for _, id := range g.OrderIDs {
+			o, ok := orders[id]
+			if !ok || o.Status == "" {
+				continue
+			}
+			if orderHasFill(o) {
+				triggered, ok, err := a.oco.Trigger(id)
+				if err != nil {
+					log.Printf("[ERROR] %v", err)
+				}
+				if ok {
+					go a.cancelOCOSiblings(triggered, o)
+				}
+				break
+			}
+			if !isOrderCancellable(o.Status) {
+				if err := a.oco.Drop(id); err != nil {
+					log.Printf("[ERROR] %v", err)
+				}
+				log.Printf("[INFO] OCO group %s: order %s %s without a fill, left the group", g.ID, id, o.Status)
+			}
+		}
+	}
+}
+
+// cancelOCOSiblings cancels the members of the triggered group other than the filled order.
+func (a *App) cancelOCOSiblings(g oco.Group, filled models.Order) {
+	a.dataMutex.RLock()
+	status := make(map[string]string)
+	for _, o := range a.activeOrders[g.AccountID] {
+		status[o.ID] = o.Status
+	}
+	a.dataMutex.RUnlock()
+
+	log.Printf("[INFO] OCO group %s triggered by %s %s %s", g.ID, filled.ID, filled.Status, filled.Symbol)
+	var failed []string
+	for _, id := range g.Siblings(filled.ID) {
+		if st, ok := status[id]; ok && !isOrderCancellable(st) {
+			continue
+		}
+		if err := a.client.CancelOrder(g.AccountID, id); err != nil {
+			log.Printf("[ERROR] OCO group %s: failed to cancel order %s: %v", g.ID, id, err)
+			failed = append(failed, id)
+			continue
+		}
+		log.Printf("[INFO] OCO group %s: order %s cancelled", g.ID, id)
+	}
+
+	if len(failed) > 0 {
+		a.SetStatus(fmt.Sprintf("OCO %s: orders %s not cancelled — cancel them manually", g.ID, strings.Join(failed, ", ")), StatusError)
+		return
+	}
+	a.SetStatus(fmt.Sprintf("OCO %s: %s filled, other orders cancelled", g.ID, filled.Symbol), StatusSuccess)
+}
+
+// orderHasFill reports whether any part of o was executed.
+func orderHasFill(o models.Order) bool {
+	if o.Status == "Filled" || o.Status == "Executed" || o.Status == "Partial" {
+		return true
+	}
+	return o.ExecutedQty.Sign() > 0
+}
+
+// groupOrderRows orders the rows of the Orders tab so the members of each OCO group follow
+// the first of them, and returns the group of each row.
+func (a *App) groupOrderRows(orders []models.Order) ([]models.Order, []*oco.Group) {
+	groups := a.oco.Groups("")
+	if len(groups) == 0 {
+		return orders, make([]*oco.Group, len(orders))
+	}
+	groupOf := make(map[string]*oco.Group)
+	for i := range groups {
+		for _, id := range groups[i].OrderIDs {
+			groupOf[id] = &groups[i]
+		}
+	}
+
+	byGroup := make(map[*oco.Group][]models.Order)
+	for _, o := range orders {
+		if g := groupOf[o.ID]; g != nil {
+			byGroup[g] = append(byGroup[g], o)
+		}
+	}
+
+	rows := make([]models.Order, 0, len(orders))
+	rowGroups := make([]*oco.Group, 0, len(orders))
+	placed := make(map[*oco.Group]bool)
+	for _, o := range orders {
+		g := groupOf[o.ID]
+		if g == nil {
+			rows = append(rows, o)
+			rowGroups = append(rowGroups, nil)
+			continue
+		}
+		if placed[g] {
+			continue
+		}
+		placed[g] = true
+		for _, m := range byGroup[g] {
+			rows = append(rows, m)
+			rowGroups = append(rowGroups, g)
+		}
+	}
+	return rows, rowGroups
+}
+
+// ocoRowPrefix draws the bracket that joins the rows of an OCO group in the Orders tab.
+func ocoRowPrefix(rowGroups []*oco.Group, i int) string {
+	g := rowGroups[i]
+	if g == nil {
+		return ""
+	}
+	first := i == 0 || rowGroups[i-1] != g
+	last := i == len(rowGroups)-1 || rowGroups[i+1] != g
+	switch {
+	case first && last:
+		return "─ "
+	case first:
+		return "┌ "
+	case last:
+		return "└ "
+	default:
+		return "│ "
+	}
+}
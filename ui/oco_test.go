@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"finam-terminal/models"
+)
+
+func TestOCO_FillCancelsSiblings(t *testing.T) {
+	cancelled := make(chan string, 4)
+	mockClient := &mockClient{
+		CancelOrderFunc: func(accountID, orderID string) error {
+			cancelled <- orderID
+			return nil
+		},
+	}
+	app := NewApp(mockClient, []models.AccountInfo{{ID: "acc1"}})
+	app.activeOrders["acc1"] = []models.Order{
+		{ID: "o1", Symbol: "SBER", Status: "Active", Quantity: models.DecimalOf("10")},
+		{ID: "o2", Symbol: "SBER", Status: "Active", Quantity: models.DecimalOf("10")},
+		{ID: "o3", Symbol: "SBER", Status: "Active", Quantity: models.DecimalOf("10")},
+	}
+	app.ocoMarked = map[string]bool{"o1": true, "o2": true, "o3": true}
+	app.linkOCOGroup()
+
+	if _, ok := app.oco.GroupOf("o2"); !ok {
+		t.Fatal("Expected the marked orders to be linked")
+	}
+	if len(app.ocoMarked) != 0 {
+		t.Errorf("Expected the marks cleared after linking, got %v", app.ocoMarked)
+	}
+
+	// A partial fill is enough to cancel the other orders
+	app.applyOrderUpdates("acc1", []models.Order{{ID: "o2", Symbol: "SBER", Status: "Partial", Quantity: models.DecimalOf("10"), ExecutedQty: models.DecimalOf("3")}})
+
+	var got []string
+	for len(got) < 2 {
+		select {
+		case id := <-cancelled:
+			got = append(got, id)
+		case <-time.After(time.Second):
+			t.Fatalf("Expected o1 and o3 to be cancelled, got %v", got)
+		}
+	}
+	sort.Strings(got)
+	if strings.Join(got, ",") != "o1,o3" {
+		t.Errorf("Expected o1 and o3 to be cancelled, got %v", got)
+	}
+	if _, ok := app.oco.GroupOf("o2"); ok {
+		t.Error("Expected the group to be dissolved after it triggered")
+	}
+
+	// The rest of the fill triggers nothing
+	app.applyOrderUpdates("acc1", []models.Order{{ID: "o2", Symbol: "SBER", Status: "Filled", Quantity: models.DecimalOf("10"), ExecutedQty: models.DecimalOf("10")}})
+	select {
+	case id := <-cancelled:
+		t.Errorf("Unexpected cancel of %s", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestOCO_CancelledMemberLeavesGroup(t *testing.T) {
+	mockClient := &mockClient{
+		CancelOrderFunc: func(accountID, orderID string) error {
+			t.Errorf("Unexpected cancel of %s", orderID)
+			return nil
+		},
+	}
+	app := NewApp(mockClient, []models.AccountInfo{{ID: "acc1"}})
+	app.activeOrders["acc1"] = []models.Order{
+		{ID: "o1", Symbol: "SBER", Status: "Active"},
+		{ID: "o2", Symbol: "SBER", Status: "Active"},
+	}
+	if _, err := app.oco.Link("acc1", []string{"o1", "o2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	app.applyOrderUpdates("acc1", []models.Order{{ID: "o1", Symbol: "SBER", Status: "Cancelled"}})
+
+	if _, ok := app.oco.GroupOf("o2"); ok {
+		t.Error("Expected a group left with one order to be dissolved")
+	}
+	time.Sleep(50 * time.Millisecond)
+}
+
+func TestOCO_GroupedRows(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	app.activeOrders["acc1"] = []models.Order{
+		{ID: "o1", Symbol: "SBER", Status: "Active", Type: "Limit"},
+		{ID: "x", Symbol: "GAZP", Status: "Active", Type: "Limit"},
+		{ID: "o3", Symbol: "LKOH", Status: "Active", Type: "Stop"},
+	}
+	g, _ := app.oco.Link("acc1", []string{"o1", "o3"})
+
+	updateOrdersTable(app)
+	table := app.portfolioView.TabbedView.OrdersTable
+
+	want := []string{"┌ SBER", "└ LKOH", "GAZP"}
+	for i, w := range want {
+		if got := table.GetCell(i+1, 0).Text; got != w {
+			t.Errorf("Row %d: expected %q, got %q", i+1, w, got)
+		}
+	}
+	if cond := table.GetCell(2, 6).Text; !strings.HasSuffix(cond, "OCO "+g.ID) {
+		t.Errorf("Expected the group ID in the condition, got %q", cond)
+	}
+
+	// Selection follows the displayed order
+	table.Select(2, 0)
+	if o, err := app.getSelectedOrder(); err != nil || o.ID != "o3" {
+		t.Errorf("Expected o3 selected, got %+v %v", o, err)
+	}
+}
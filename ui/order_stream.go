@@ -0,0 +1,228 @@
+package ui
+
+import (
+	"fmt"
+
+	"finam-terminal/models"
+)
+
+// startAccountStreams subscribes to order and trade updates for every account that loaded.
+// Updates are applied on the UI thread in the order they arrive.
+func (a *App) startAccountStreams() {
+	for _, acc := range a.accounts {
+		if acc.LoadError != "" {
+			continue
+		}
+		accountID := acc.ID
+
+		stopOrders := a.client.SubscribeOrders(accountID,
+			func(list []models.Order) {
+				a.app.QueueUpdateDraw(func() { a.setOrders(accountID, list) })
+			},
+			func(list []models.Order) {
+				a.app.QueueUpdateDraw(func() { a.applyOrderUpdates(accountID, list) })
+			})
+		stopTrades := a.client.SubscribeTrades(accountID, func(trades []models.Trade) {
+			a.app.QueueUpdateDraw(func() { a.applyTrades(accountID, trades) })
+		})
+
+		a.accountStreams = append(a.accountStreams, stopOrders, stopTrades)
+	}
+}
+
+// stopAccountStreams cancels all order and trade subscriptions.
+func (a *App) stopAccountStreams() {
+	for _, stop := range a.accountStreams {
+		stop()
+	}
+	a.accountStreams = nil
+}
+
+// setOrders replaces the order list of an account with a stream snapshot. From then on
+// the stream keeps the list current and it is not reloaded on tab or account switches.
+// Must be called on the UI thread.
+func (a *App) setOrders(accountID string, list []models.Order) {
+	a.dataMutex.Lock()
+	a.activeOrders[accountID] = list
+	a.ordersLive[accountID] = true
+	a.dataMutex.Unlock()
+
+	a.trackOrders(accountID)
+	a.refreshOrdersView(accountID)
+}
+
+// applyOrderUpdates upserts streamed orders by ID and reports fills and rejects
+// in the status bar. Orders that reached a final status are dropped once the
+// terminal-managed orders have seen it. Must be called on the UI thread.
+func (a *App) applyOrderUpdates(accountID string, updates []models.Order) {
+	a.dataMutex.Lock()
+	current := a.activeOrders[accountID]
+	var events []orderEvent
+	for _, o := range updates {
+		idx := -1
+		for i := range current {
+			if current[i].ID == o.ID {
+				idx = i
+				break
+			}
+		}
+		var prev *models.Order
+		if idx >= 0 {
+			prev = &current[idx]
+		}
+		if ev, ok := detectOrderEvent(prev, o); ok {
+			events = append(events, ev)
+		}
+		if idx >= 0 {
+			current[idx] = o
+		} else {
+			current = append(current, o)
+		}
+	}
+	a.activeOrders[accountID] = current
+	a.dataMutex.Unlock()
+
+	a.trackOrders(accountID)
+	a.dropFinishedOrders(accountID)
+	a.refreshOrdersView(accountID)
+
+	for _, ev := range events {
+		a.SetStatus(a.formatOrderEvent(ev), ev.statusType())
+	}
+}
+
+// applyTrades appends streamed trades to the account history, skipping known trade IDs.
+// Must be called on the UI thread.
+func (a *App) applyTrades(accountID string, trades []models.Trade) {
+	a.dataMutex.Lock()
+	history := a.history[accountID]
+	seen := make(map[string]bool, len(history))
+	for _, t := range history {
+		seen[t.ID] = true
+	}
+	added := false
+	for _, t := range trades {
+		if seen[t.ID] {
+			continue
+		}
+		seen[t.ID] = true
+		history = append(history, t)
+		added = true
+	}
+	a.history[accountID] = history
+	a.dataMutex.Unlock()
+	a.storeTrades(accountID, trades)
+
+	if added && a.isSelectedAccount(accountID) {
+		updateHistoryTable(a)
+		updatePnLTable(a)
+	}
+}
+
+// dropFinishedOrders removes the orders of accountID that reached a final status. Called
+// once the terminal-managed orders have seen them.
+func (a *App) dropFinishedOrders(accountID string) {
+	a.dataMutex.Lock()
+	defer a.dataMutex.Unlock()
+	working := make([]models.Order, 0, len(a.activeOrders[accountID]))
+	for _, o := range a.activeOrders[accountID] {
+		if !isOrderFinished(o.Status) {
+			working = append(working, o)
+		}
+	}
+	a.activeOrders[accountID] = working
+}
+
+// trackOrders lets the terminal-managed orders of accountID react to its loaded orders:
+// trailing stops end with their stop order, bracket entries protect their fills and
+// filled OCO members cancel their siblings.
+func (a *App) trackOrders(accountID string) {
+	a.syncTrailingStops(accountID)
+	a.protectBrackets(accountID)
+	a.enforceOCO(accountID)
+}
+
+// refreshOrdersView redraws the Orders tab and the profile DOM markers when
+// accountID is the selected account.
+func (a *App) refreshOrdersView(accountID string) {
+	if !a.isSelectedAccount(accountID) {
+		return
+	}
+	updateOrdersTable(a)
+	a.refreshProfileOwnOrders()
+}
+
+// ordersStreaming reports whether the orders stream of accountID keeps its order list current.
+func (a *App) ordersStreaming(accountID string) bool {
+	a.dataMutex.RLock()
+	defer a.dataMutex.RUnlock()
+	return a.ordersLive[accountID]
+}
+
+func (a *App) isSelectedAccount(accountID string) bool {
+	return a.selectedIdx >= 0 && a.selectedIdx < len(a.accounts) && a.accounts[a.selectedIdx].ID == accountID
+}
+
+type orderEventKind int
+
+const (
+	orderFilled orderEventKind = iota
+	orderPartiallyFilled
+	orderRejected
+)
+
+// orderEvent is an order status transition worth telling the user about.
+type orderEvent struct {
+	kind  orderEventKind
+	order models.Order
+}
+
+func (e orderEvent) statusType() StatusType {
+	if e.kind == orderRejected {
+		return StatusError
+	}
+	return StatusSuccess
+}
+
+// detectOrderEvent compares an update with the previously known state of the order.
+// prev is nil for an order we have not seen yet.
+func detectOrderEvent(prev *models.Order, o models.Order) (orderEvent, bool) {
+	switch o.Status {
+	case "Filled", "Executed":
+		if prev == nil || (prev.Status != "Filled" && prev.Status != "Executed") {
+			return orderEvent{kind: orderFilled, order: o}, true
+		}
+	case "Partial":
+		if prev == nil || prev.Status != "Partial" || !prev.ExecutedQty.Equal(o.ExecutedQty) {
+			return orderEvent{kind: orderPartiallyFilled, order: o}, true
+		}
+	case "Rejected", "Failed":
+		if prev == nil || prev.Status != o.Status {
+			return orderEvent{kind: orderRejected, order: o}, true
+		}
+	}
+	return orderEvent{}, false
+}
+
+// formatOrderEvent builds the status bar message for an order event, with quantities in lots.
+func (a *App) formatOrderEvent(ev orderEvent) string {
+	o := ev.order
+	name := o.Symbol
+	if o.Name != "" {
+		name = o.Name
+	}
+	lotSize := a.client.GetLotSize(o.Symbol)
+	qty := displayLots(o.Quantity, lotSize)
+
+	price := " @ " + o.PriceText()
+
+	switch ev.kind {
+	case orderFilled:
+		return fmt.Sprintf("Order filled: %s %s %s lots%s", o.Side, name, qty, price)
+	case orderPartiallyFilled:
+		return fmt.Sprintf("Order partially filled: %s %s %s/%s lots%s",
+			o.Side, name, displayLots(o.ExecutedQty, lotSize), qty, price)
+	default:
+		return fmt.Sprintf("Order rejected: %s %s %s lots%s", o.Side, name, qty, price)
+	}
+}
@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"finam-terminal/models"
+)
+
+func TestDetectOrderEvent(t *testing.T) {
+	active := &models.Order{ID: "1", Status: "Active"}
+	partial := &models.Order{ID: "1", Status: "Partial", ExecutedQty: models.DecimalOf("10")}
+	filled := &models.Order{ID: "1", Status: "Filled"}
+
+	tests := []struct {
+		name   string
+		prev   *models.Order
+		update models.Order
+		want   orderEventKind
+		ok     bool
+	}{
+		{"active to filled", active, models.Order{ID: "1", Status: "Filled"}, orderFilled, true},
+		{"unknown executed", nil, models.Order{ID: "1", Status: "Executed"}, orderFilled, true},
+		{"filled again", filled, models.Order{ID: "1", Status: "Filled"}, 0, false},
+		{"active to partial", active, models.Order{ID: "1", Status: "Partial", ExecutedQty: models.DecimalOf("10")}, orderPartiallyFilled, true},
+		{"partial grows", partial, models.Order{ID: "1", Status: "Partial", ExecutedQty: models.DecimalOf("20")}, orderPartiallyFilled, true},
+		{"partial unchanged", partial, models.Order{ID: "1", Status: "Partial", ExecutedQty: models.DecimalOf("10")}, 0, false},
+		{"partial rescaled", partial, models.Order{ID: "1", Status: "Partial", ExecutedQty: models.DecimalOf("10.000")}, 0, false},
+		{"rejected", active, models.Order{ID: "1", Status: "Rejected"}, orderRejected, true},
+		{"cancelled", active, models.Order{ID: "1", Status: "Cancelled"}, 0, false},
+		{"still active", active, models.Order{ID: "1", Status: "Active"}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, ok := detectOrderEvent(tt.prev, tt.update)
+			if ok != tt.ok {
+				t.Fatalf("Expected ok=%v, got %v", tt.ok, ok)
+			}
+			if ok && ev.kind != tt.want {
+				t.Errorf("Expected kind %v, got %v", tt.want, ev.kind)
+			}
+		})
+	}
+}
+
+func TestApplyOrderUpdates_UpsertAndToast(t *testing.T) {
+	client := &mockClient{
+		GetLotSizeFunc: func(ticker string) models.Decimal { return models.DecimalFromInt(10) },
+	}
+	accounts := []models.AccountInfo{{ID: "acc1"}}
+	app := NewApp(client, accounts)
+
+	app.setOrders("acc1", []models.Order{
+		{ID: "1", Symbol: "SBER@TQBR", Side: "Buy", Status: "Active", Quantity: models.DecimalOf("100"), Price: models.DecimalOf("250.5")},
+		{ID: "3", Symbol: "LKOH@TQBR", Side: "Buy", Status: "Active", Quantity: models.DecimalOf("2"), Price: models.DecimalOf("7000")},
+	})
+	if !app.ordersStreaming("acc1") {
+		t.Error("Expected the snapshot to mark the orders of acc1 as streamed")
+	}
+
+	app.applyOrderUpdates("acc1", []models.Order{
+		{ID: "3", Symbol: "LKOH@TQBR", Side: "Buy", Status: "Partial", Quantity: models.DecimalOf("2"), ExecutedQty: models.DecimalOf("1"), Price: models.DecimalOf("7000")},
+		{ID: "1", Symbol: "SBER@TQBR", Side: "Buy", Status: "Filled", Quantity: models.DecimalOf("100"), Price: models.DecimalOf("250.5")},
+		{ID: "2", Symbol: "GAZP@TQBR", Side: "Sell", Status: "Active", Quantity: models.DecimalOf("10"), Price: models.DecimalOf("150")},
+	})
+
+	app.dataMutex.RLock()
+	orders := app.activeOrders["acc1"]
+	msg, typ := app.statusMessage, app.statusType
+	app.dataMutex.RUnlock()
+
+	if len(orders) != 2 {
+		t.Fatalf("Expected the filled order to be dropped, got %+v", orders)
+	}
+	if orders[0].ID != "3" || orders[0].Status != "Partial" {
+		t.Errorf("Expected order 3 to be updated in place, got %s %s", orders[0].ID, orders[0].Status)
+	}
+	if orders[1].ID != "2" {
+		t.Errorf("Expected new order appended, got %s", orders[1].ID)
+	}
+	if typ != StatusSuccess || !strings.Contains(msg, "Order filled: Buy SBER@TQBR 10 lots @ 250.5") {
+		t.Errorf("Unexpected toast %q (type %v)", msg, typ)
+	}
+}
+
+func TestApplyOrderUpdates_RejectToast(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+
+	app.applyOrderUpdates("acc1", []models.Order{
+		{ID: "9", Symbol: "SBER@TQBR", Name: "Sberbank", Side: "Sell", Status: "Rejected", Quantity: models.DecimalOf("1"), Price: models.DecimalOf("Market")},
+	})
+
+	app.dataMutex.RLock()
+	msg, typ := app.statusMessage, app.statusType
+	app.dataMutex.RUnlock()
+
+	if typ != StatusError || !strings.HasPrefix(msg, "Order rejected: Sell Sberbank") {
+		t.Errorf("Unexpected toast %q (type %v)", msg, typ)
+	}
+}
+
+func TestApplyTrades_Dedup(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+
+	app.applyTrades("acc1", []models.Trade{{ID: "T1"}, {ID: "T2"}})
+	// Reconnect resends the history snapshot plus a new trade
+	app.applyTrades("acc1", []models.Trade{{ID: "T1"}, {ID: "T2"}, {ID: "T3"}})
+
+	history := app.history["acc1"]
+	if len(history) != 3 {
+		t.Fatalf("Expected 3 unique trades, got %d", len(history))
+	}
+	if history[2].ID != "T3" {
+		t.Errorf("Expected T3 appended last, got %s", history[2].ID)
+	}
+}
+
+func TestSetLoadedOrders_DropsFinishedWhileStreaming(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	loaded := []models.Order{{ID: "1", Status: "Active"}, {ID: "2", Status: "Filled"}, {ID: "3", Status: "Cancelled"}}
+
+	// Without the stream the reload shows the finished orders too
+	app.setLoadedOrders("acc1", loaded)
+	if n := len(app.activeOrders["acc1"]); n != 3 {
+		t.Fatalf("Expected all 3 orders without the stream, got %d", n)
+	}
+
+	app.setOrders("acc1", []models.Order{{ID: "1", Status: "Active"}})
+	app.setLoadedOrders("acc1", loaded)
+	got := app.activeOrders["acc1"]
+	if len(got) != 1 || got[0].ID != "1" {
+		t.Errorf("Expected only the working order while streaming, got %+v", got)
+	}
+}
@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"finam-terminal/models"
+)
+
+// orderBookDepth is the number of price levels shown on each side of the DOM.
+const orderBookDepth = 10
+
+// startOrderBook subscribes the profile DOM to the order book of symbol,
+// replacing any previous subscription.
+func (a *App) startOrderBook(accountID, symbol string) {
+	a.stopOrderBook()
+	a.bookStream.start(func() func() {
+		return a.client.SubscribeOrderBook(accountID, symbol, a.onOrderBook)
+	})
+}
+
+// stopOrderBook cancels the profile order book subscription, if any.
+func (a *App) stopOrderBook() {
+	a.bookStream.cancel()
+	a.bookMu.Lock()
+	a.bookLatest = nil
+	a.bookMu.Unlock()
+}
+
+// onOrderBook receives order book snapshots from the stream goroutine.
+// Bursts are coalesced: only the latest snapshot is drawn.
+func (a *App) onOrderBook(book *models.OrderBook) {
+	a.bookMu.Lock()
+	a.bookLatest = book
+	a.bookMu.Unlock()
+
+	if !a.bookPending.CompareAndSwap(false, true) {
+		return
+	}
+	a.app.QueueUpdateDraw(func() {
+		a.bookPending.Store(false)
+
+		a.bookMu.Lock()
+		latest := a.bookLatest
+		a.bookMu.Unlock()
+
+		if latest != nil && a.profileOpen && symbolMatches(latest.Symbol, a.profileSymbol) {
+			a.profilePanel.UpdateOrderBook(latest)
+		}
+	})
+}
+
+// refreshProfileOwnOrders passes the working orders for the open profile's instrument to the DOM.
+// Must be called on the UI thread.
+func (a *App) refreshProfileOwnOrders() {
+	if !a.profileOpen || a.profileSymbol == "" {
+		return
+	}
+
+	a.dataMutex.RLock()
+	var own []models.Order
+	if a.selectedIdx >= 0 && a.selectedIdx < len(a.accounts) {
+		for _, o := range a.activeOrders[a.accounts[a.selectedIdx].ID] {
+			if isOrderCancellable(o.Status) && symbolMatches(o.Symbol, a.profileSymbol) {
+				own = append(own, o)
+			}
+		}
+	}
+	a.dataMutex.RUnlock()
+
+	a.profilePanel.SetOwnOrders(own)
+}
+
+// ownOrderMarks sums working limit orders per price level and side, in lots. Levels are
+// keyed by the price without trailing zeros.
+type ownOrderMarks map[string]struct{ buy, sell models.Decimal }
+
+func newOwnOrderMarks(orders []models.Order, lotSize models.Decimal) ownOrderMarks {
+	marks := make(ownOrderMarks)
+	for _, o := range orders {
+		if o.LimitPrice.Sign() <= 0 {
+			continue
+		}
+		price := o.LimitPrice.Trim().String()
+		remaining := o.RemainingQty
+		if remaining.Sign() <= 0 {
+			remaining = o.Quantity
+		}
+		if !remaining.Known() {
+			continue
+		}
+		if lotSize.Sign() > 0 {
+			remaining = remaining.Div(lotSize, 8).Trim()
+		}
+		m := marks[price]
+		if o.Side == "Buy" {
+			m.buy = m.buy.Add(remaining)
+		} else {
+			m.sell = m.sell.Add(remaining)
+		}
+		marks[price] = m
+	}
+	return marks
+}
+
+// at returns the marker text for a price level, or "" when we have no orders there.
+func (m ownOrderMarks) at(price models.Decimal) string {
+	v, ok := m[price.Trim().String()]
+	if !ok {
+		return ""
+	}
+	var parts []string
+	if v.buy.Sign() > 0 {
+		parts = append(parts, "B"+v.buy.Trim().String())
+	}
+	if v.sell.Sign() > 0 {
+		parts = append(parts, "S"+v.sell.Trim().String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatBookPrice formats a price with the instrument's decimals when known.
+func formatBookPrice(price models.Decimal, decimals int32) string {
+	if decimals > 0 {
+		return price.StringFixed(decimals)
+	}
+	return price.String()
+}
+
+// renderOrderBookText renders bid/ask ladders with cumulative size and the spread.
+// Asks are printed above the spread with the best ask nearest to it, bids below.
+func renderOrderBookText(book *models.OrderBook, own []models.Order, lotSize models.Decimal, decimals int32) string {
+	if book == nil {
+		return "[gray]Loading..."
+	}
+	if len(book.Bids) == 0 && len(book.Asks) == 0 {
+		return "[gray]Order book is empty"
+	}
+
+	marks := newOwnOrderMarks(own, lotSize)
+	line := func(sb *strings.Builder, color string, lvl models.OrderBookLevel, cum models.Decimal) {
+		mark := marks.at(lvl.Price)
+		if mark != "" {
+			mark = "[yellow::b]◄" + mark + "[-:-:-]"
+		}
+		fmt.Fprintf(sb, "[%s]%10s[-] %8s %9s %s\n",
+			color,
+			formatBookPrice(lvl.Price, decimals),
+			formatNumber(lvl.Size.Float64(), 0),
+			formatNumber(cum.Float64(), 0),
+			mark)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[gray]%10s %8s %9s[-]\n", "Price", "Size", "Cum")
+
+	asks := book.Asks
+	if len(asks) > orderBookDepth {
+		asks = asks[:orderBookDepth]
+	}
+	askCum := make([]models.Decimal, len(asks))
+	var cum models.Decimal
+	for i, lvl := range asks {
+		cum = cum.Add(lvl.Size)
+		askCum[i] = cum
+	}
+	for i := len(asks) - 1; i >= 0; i-- {
+		line(&sb, "red", asks[i], askCum[i])
+	}
+
+	if spread, ok := book.Spread(); ok {
+		mid := book.Asks[0].Price.Add(book.Bids[0].Price).Float64() / 2
+		pct := 0.0
+		if mid > 0 {
+			pct = spread.Float64() / mid * 100
+		}
+		fmt.Fprintf(&sb, "[cyan]── Spread %s (%.2f%%) ──[-]\n", formatBookPrice(spread, decimals), pct)
+	} else {
+		sb.WriteString("[cyan]── Spread N/A ──[-]\n")
+	}
+
+	bids := book.Bids
+	if len(bids) > orderBookDepth {
+		bids = bids[:orderBookDepth]
+	}
+	cum = models.Decimal{}
+	for _, lvl := range bids {
+		cum = cum.Add(lvl.Size)
+		line(&sb, "green", lvl, cum)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"finam-terminal/models"
+	"strings"
+	"testing"
+)
+
+func TestRenderOrderBookText_LaddersAndSpread(t *testing.T) {
+	book := &models.OrderBook{
+		Symbol: "SBER@TQBR",
+		Bids:   []models.OrderBookLevel{{Price: models.DecimalOf("250.40"), Size: models.DecimalFromInt(10)}, {Price: models.DecimalOf("250.30"), Size: models.DecimalFromInt(5)}},
+		Asks:   []models.OrderBookLevel{{Price: models.DecimalOf("250.60"), Size: models.DecimalFromInt(7)}, {Price: models.DecimalOf("250.70"), Size: models.DecimalFromInt(3)}},
+	}
+
+	text := renderOrderBookText(book, nil, models.Decimal{}, 2)
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("Expected header + 2 asks + spread + 2 bids, got %d lines:\n%s", len(lines), text)
+	}
+
+	// Worst ask on top, with cumulative size from the best ask outward
+	if !strings.Contains(lines[1], "250.70") || !strings.Contains(lines[1], "10") {
+		t.Errorf("Expected top line to be ask 250.70 with cum 10, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "250.60") {
+		t.Errorf("Expected best ask next to spread, got %q", lines[2])
+	}
+	if !strings.Contains(lines[3], "Spread 0.20") {
+		t.Errorf("Expected spread line, got %q", lines[3])
+	}
+	if !strings.Contains(lines[5], "250.30") || !strings.Contains(lines[5], "15") {
+		t.Errorf("Expected last bid 250.30 with cum 15, got %q", lines[5])
+	}
+}
+
+func TestRenderOrderBookText_OwnOrders(t *testing.T) {
+	book := &models.OrderBook{
+		Bids: []models.OrderBookLevel{{Price: models.DecimalOf("250.40"), Size: models.DecimalFromInt(10)}},
+		Asks: []models.OrderBookLevel{{Price: models.DecimalOf("250.60"), Size: models.DecimalFromInt(7)}},
+	}
+	own := []models.Order{
+		{Side: "Buy", LimitPrice: models.DecimalOf("250.40"), Quantity: models.DecimalOf("30"), Status: "Active"},
+		{Side: "Sell", LimitPrice: models.DecimalOf("260.00"), Quantity: models.DecimalOf("10"), Status: "Active"},
+	}
+
+	text := renderOrderBookText(book, own, models.DecimalFromInt(10), 2)
+	if !strings.Contains(text, "◄B3") {
+		t.Errorf("Expected own buy of 3 lots marked at 250.40, got:\n%s", text)
+	}
+	if strings.Contains(text, "S1") {
+		t.Errorf("Expected order outside the book not to be marked, got:\n%s", text)
+	}
+}
+
+func TestRenderOrderBookText_Empty(t *testing.T) {
+	if got := renderOrderBookText(nil, nil, models.Decimal{}, 0); !strings.Contains(got, "Loading") {
+		t.Errorf("Expected loading text for nil book, got %q", got)
+	}
+	if got := renderOrderBookText(&models.OrderBook{}, nil, models.Decimal{}, 0); !strings.Contains(got, "empty") {
+		t.Errorf("Expected empty text, got %q", got)
+	}
+}
@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"finam-terminal/models"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// pnlView is the grouping of the P&L tab.
+type pnlView int
+
+const (
+	pnlByInstrument pnlView = iota
+	pnlByDay
+	pnlByLot
+	pnlViewCount
+)
+
+func (v pnlView) String() string {
+	switch v {
+	case pnlByDay:
+		return "by day"
+	case pnlByLot:
+		return "closed lots"
+	default:
+		return "by instrument"
+	}
+}
+
+// accountPnL replays the loaded trade history of accountID with the selected matching
+// method. What the history does not explain is taken from the current positions.
+func (a *App) accountPnL(accountID string) (*models.PnLReport, error) {
+	a.dataMutex.RLock()
+	trades := a.history[accountID]
+	positions := a.positions[accountID]
+	a.dataMutex.RUnlock()
+
+	return models.ComputePnL(trades, models.OpeningLots(positions, trades), a.pnlMethod)
+}
+
+// togglePnLMethod switches the P&L tab between FIFO and average cost matching.
+func (a *App) togglePnLMethod() {
+	if a.pnlMethod == models.MatchFIFO {
+		a.pnlMethod = models.MatchAverage
+	} else {
+		a.pnlMethod = models.MatchFIFO
+	}
+	updatePnLTable(a)
+}
+
+// cyclePnLView shows the next grouping of the P&L tab.
+func (a *App) cyclePnLView() {
+	a.pnlView = (a.pnlView + 1) % pnlViewCount
+	updatePnLTable(a)
+}
+
+// updatePnLTable shows the realized P&L of the selected account in the current grouping,
+// followed by a total row.
+func updatePnLTable(app *App) {
+	table := app.portfolioView.TabbedView.PnLTable
+	table.Clear()
+
+	method := "FIFO"
+	if app.pnlMethod == models.MatchAverage {
+		method = "Average cost"
+	}
+	table.SetTitle(fmt.Sprintf(" P&L: %s, %s ", method, app.pnlView))
+
+	var headers []string
+	switch app.pnlView {
+	case pnlByDay:
+		headers = []string{"Date", "Realized", "Closes"}
+	case pnlByLot:
+		headers = []string{"Instrument", "Side", "Qty (Lots)", "Open", "Close", "Opened", "Closed", "Held", "Realized"}
+	default:
+		headers = []string{"Instrument", "Realized", "Closes", "Win %", "Avg Held", "Open Qty (Lots)", "Open Avg"}
+	}
+	headerStyle := tcell.StyleDefault.
+		Background(tcell.ColorDarkBlue).
+		Foreground(tcell.ColorWhite).
+		Bold(true)
+	for i, h := range headers {
+		align := tview.AlignRight
+		if i == 0 {
+			align = tview.AlignLeft
+		}
+		table.SetCell(0, i, tview.NewTableCell(h).SetStyle(headerStyle).SetAlign(align).SetExpansion(1))
+	}
+
+	if app.selectedIdx < 0 || app.selectedIdx >= len(app.accounts) {
+		return
+	}
+	report, err := app.accountPnL(app.accounts[app.selectedIdx].ID)
+	if err != nil {
+		log.Printf("[WARN] Failed to compute P&L: %v", err)
+		table.SetCell(1, 0, tview.NewTableCell(err.Error()).SetTextColor(tcell.ColorRed).SetSelectable(false))
+		return
+	}
+	if len(report.Closed) == 0 && len(report.Open) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell("No trades to match").
+			SetTextColor(tcell.ColorGray).SetSelectable(false))
+		return
+	}
+
+	row := 1
+	switch app.pnlView {
+	case pnlByDay:
+		for _, d := range report.Days {
+			setPnLRow(table, row, 1, d.Realized, d.Date.Format("2006-01-02"), "", strconv.Itoa(d.Closes))
+			row++
+		}
+		setPnLTotalRow(table, row, 1, report.Total.Realized, "Total", "", strconv.Itoa(report.Total.Closes()))
+	case pnlByLot:
+		for _, c := range report.Closed {
+			setPnLRow(table, row, 8, c.PnL, c.Symbol, c.Side, app.pnlQuantity(c.Symbol, c.Quantity),
+				formatPnLPrice(c.OpenPrice), formatPnLPrice(c.ClosePrice),
+				formatPnLTime(c.Opened), formatPnLTime(c.Closed), formatHolding(c.Holding()), "")
+			row++
+		}
+		setPnLTotalRow(table, row, 8, report.Total.Realized, "Total", "", "", "", "", "", "",
+			formatHolding(report.Total.AvgHolding), "")
+	default:
+		for _, inst := range report.Instruments {
+			openQty, openPrice := "", ""
+			if !inst.OpenQuantity.IsZero() {
+				openQty = app.pnlQuantity(inst.Symbol, inst.OpenQuantity)
+				openPrice = formatPnLPrice(inst.OpenPrice)
+			}
+			setPnLRow(table, row, 1, inst.Realized, inst.Symbol, "", strconv.Itoa(inst.Closes()),
+				formatWinRate(inst.PnLStats), formatHolding(inst.AvgHolding), openQty, openPrice)
+			row++
+		}
+		setPnLTotalRow(table, row, 1, report.Total.Realized, "Total", "", strconv.Itoa(report.Total.Closes()),
+			formatWinRate(report.Total), formatHolding(report.Total.AvgHolding), "", "")
+	}
+
+	if sel, _ := table.GetSelection(); sel < 1 || sel > row {
+		table.Select(1, 0)
+	}
+}
+
+// setPnLRow fills a row of the P&L table. The cell at realizedCol shows realized, colored
+// by its sign.
+func setPnLRow(table *tview.Table, row, realizedCol int, realized models.Decimal, cells ...string) {
+	rowBg := tcell.ColorBlack
+	if (row-1)%2 == 0 {
+		rowBg = tcell.ColorDarkGray
+	}
+	setPnLCells(table, row, tcell.StyleDefault.Background(rowBg), realizedCol, realized, cells)
+}
+
+// setPnLTotalRow fills the bold, unselectable total row after the others.
+func setPnLTotalRow(table *tview.Table, row, realizedCol int, realized models.Decimal, cells ...string) {
+	setPnLCells(table, row, tcell.StyleDefault.Background(tcell.ColorBlack).Bold(true), realizedCol, realized, cells)
+	for col := range cells {
+		table.GetCell(row, col).SetSelectable(false)
+	}
+}
+
+func setPnLCells(table *tview.Table, row int, style tcell.Style, realizedCol int, realized models.Decimal, cells []string) {
+	cells[realizedCol] = formatNumber(realized.Float64(), 2)
+	for col, text := range cells {
+		color := tcell.ColorWhite
+		align := tview.AlignRight
+		switch col {
+		case 0:
+			color = tcell.ColorLightYellow
+			align = tview.AlignLeft
+		case realizedCol:
+			color = pnlColor(realized.Float64())
+		}
+		table.SetCell(row, col, tview.NewTableCell(text).SetStyle(style.Foreground(color)).SetAlign(align))
+	}
+}
+
+func pnlColor(v float64) tcell.Color {
+	switch {
+	case v > 0:
+		return tcell.ColorGreen
+	case v < 0:
+		return tcell.ColorRed
+	}
+	return tcell.ColorWhite
+}
+
+// pnlQuantity formats a quantity of units of symbol in lots.
+func (a *App) pnlQuantity(symbol string, qty models.Decimal) string {
+	var lotSize models.Decimal
+	if a.client != nil {
+		lotSize = a.client.GetLotSize(symbol)
+	}
+	return displayLots(qty, lotSize)
+}
+
+// formatPnLPrice formats a price, average prices to at most 6 decimals.
+func formatPnLPrice(price models.Decimal) string {
+	return price.Round(6).Trim().String()
+}
+
+func formatPnLTime(t time.Time) string {
+	if t.IsZero() {
+		return "before history"
+	}
+	return t.Format("01-02 15:04")
+}
+
+func formatWinRate(s models.PnLStats) string {
+	if s.Closes() == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", s.WinRate())
+}
+
+// formatHolding formats a holding period in days and hours, or hours and minutes for
+// shorter ones.
+func formatHolding(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd %dh", int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	}
+	return fmt.Sprintf("%dm", int(d/time.Minute))
+}
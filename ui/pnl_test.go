@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"finam-terminal/models"
+)
+
+func TestPnLTable_MethodsAndViews(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	day := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	app.history["acc1"] = []models.Trade{
+		{ID: "T1", Symbol: "SBER@MISX", Side: "Buy", Price: models.DecimalOf("300"), Quantity: models.DecimalOf("10"), Timestamp: day},
+		{ID: "T2", Symbol: "SBER@MISX", Side: "Buy", Price: models.DecimalOf("310"), Quantity: models.DecimalOf("10"), Timestamp: day.Add(time.Hour)},
+		{ID: "T3", Symbol: "SBER@MISX", Side: "Sell", Price: models.DecimalOf("320"), Quantity: models.DecimalOf("15"), Timestamp: day.Add(2 * time.Hour)},
+	}
+	app.positions["acc1"] = []models.Position{{Symbol: "SBER@MISX", Quantity: models.DecimalOf("5"), AveragePrice: models.DecimalOf("305")}}
+
+	table := app.portfolioView.TabbedView.PnLTable
+	updatePnLTable(app)
+	if got := table.GetCell(1, 1).Text; got != "250.00" {
+		t.Errorf("Expected 250.00 realized with FIFO, got %q", got)
+	}
+	if got := table.GetCell(1, 3).Text; got != "100%" {
+		t.Errorf("Expected a 100%% win rate, got %q", got)
+	}
+	if got := table.GetCell(2, 0).Text; got != "Total" {
+		t.Errorf("Expected the total row, got %q", got)
+	}
+
+	app.togglePnLMethod()
+	if got := table.GetCell(1, 1).Text; got != "225.00" {
+		t.Errorf("Expected 225.00 realized at average cost, got %q", got)
+	}
+	if got := table.GetTitle(); got != " P&L: Average cost, by instrument " {
+		t.Errorf("Unexpected title %q", got)
+	}
+
+	app.cyclePnLView()
+	if got := table.GetCell(1, 0).Text; got != "2026-03-02" {
+		t.Errorf("Expected the day view, got %q", got)
+	}
+
+	app.togglePnLMethod()
+	app.cyclePnLView()
+	if rows := table.GetRowCount(); rows != 4 {
+		t.Fatalf("Expected two closed lots and a total, got %d rows", rows)
+	}
+	if got := table.GetCell(2, 8).Text; got != "50.00" {
+		t.Errorf("Expected the second lot to make 50.00, got %q", got)
+	}
+	if got := table.GetCell(2, 7).Text; got != "1h 0m" {
+		t.Errorf("Expected the second lot held an hour, got %q", got)
+	}
+}
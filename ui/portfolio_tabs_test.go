@@ -8,16 +8,16 @@ import (
 
 func TestHistoryTable_LotBasedQuantity(t *testing.T) {
 	mock := &mockClient{}
-	mock.GetLotSizeFunc = func(ticker string) float64 {
+	mock.GetLotSizeFunc = func(ticker string) models.Decimal {
 		if ticker == "SBER" || ticker == "SBER@TQBR" {
-			return 10
+			return models.DecimalFromInt(10)
 		}
-		return 1
+		return models.DecimalFromInt(1)
 	}
 
 	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
 	app.history["acc1"] = []models.Trade{
-		{ID: "T1", Symbol: "SBER@TQBR", Side: "Buy", Quantity: "100", Price: "250.00", Total: "25000.00"},
+		{ID: "T1", Symbol: "SBER@TQBR", Side: "Buy", Quantity: models.DecimalOf("100"), Price: models.DecimalOf("250.00"), Total: models.DecimalOf("25000.00")},
 	}
 
 	updateHistoryTable(app)
@@ -38,16 +38,16 @@ func TestHistoryTable_LotBasedQuantity(t *testing.T) {
 
 func TestOrdersTable_LotBasedQuantity(t *testing.T) {
 	mock := &mockClient{}
-	mock.GetLotSizeFunc = func(ticker string) float64 {
+	mock.GetLotSizeFunc = func(ticker string) models.Decimal {
 		if ticker == "GAZP" || ticker == "GAZP@TQBR" {
-			return 10
+			return models.DecimalFromInt(10)
 		}
-		return 1
+		return models.DecimalFromInt(1)
 	}
 
 	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
 	app.activeOrders["acc1"] = []models.Order{
-		{ID: "O1", Symbol: "GAZP@TQBR", Side: "Sell", Type: "Market", Status: "New", Quantity: "50"},
+		{ID: "O1", Symbol: "GAZP@TQBR", Side: "Sell", Type: "Market", Status: "New", Quantity: models.DecimalOf("50")},
 	}
 
 	updateOrdersTable(app)
@@ -111,7 +111,7 @@ func TestPositionsTable_InstrumentHeader(t *testing.T) {
 	mock := &mockClient{}
 	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
 	app.positions["acc1"] = []models.Position{
-		{Symbol: "SBER@TQBR", Ticker: "SBER", Name: "Сбербанк", Quantity: "10", LotSize: 1},
+		{Symbol: "SBER@TQBR", Ticker: "SBER", Name: "Сбербанк", Quantity: models.DecimalOf("10"), LotSize: models.DecimalFromInt(1)},
 	}
 
 	updatePositionsTable(app)
@@ -132,7 +132,7 @@ func TestPositionsTable_FallbackToTicker(t *testing.T) {
 	mock := &mockClient{}
 	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
 	app.positions["acc1"] = []models.Position{
-		{Symbol: "UNKNOWN@TQBR", Ticker: "UNKNOWN", Name: "", Quantity: "10", LotSize: 1},
+		{Symbol: "UNKNOWN@TQBR", Ticker: "UNKNOWN", Name: "", Quantity: models.DecimalOf("10"), LotSize: models.DecimalFromInt(1)},
 	}
 
 	updatePositionsTable(app)
@@ -145,10 +145,10 @@ func TestPositionsTable_FallbackToTicker(t *testing.T) {
 
 func TestHistoryTable_InstrumentHeader(t *testing.T) {
 	mock := &mockClient{}
-	mock.GetLotSizeFunc = func(ticker string) float64 { return 1 }
+	mock.GetLotSizeFunc = func(ticker string) models.Decimal { return models.DecimalFromInt(1) }
 	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
 	app.history["acc1"] = []models.Trade{
-		{ID: "T1", Symbol: "SBER", Name: "Сбербанк", Side: "Buy", Quantity: "10", Price: "250", Total: "2500"},
+		{ID: "T1", Symbol: "SBER", Name: "Сбербанк", Side: "Buy", Quantity: models.DecimalOf("10"), Price: models.DecimalOf("250"), Total: models.DecimalOf("2500")},
 	}
 
 	updateHistoryTable(app)
@@ -166,10 +166,10 @@ func TestHistoryTable_InstrumentHeader(t *testing.T) {
 
 func TestOrdersTable_InstrumentHeader(t *testing.T) {
 	mock := &mockClient{}
-	mock.GetLotSizeFunc = func(ticker string) float64 { return 1 }
+	mock.GetLotSizeFunc = func(ticker string) models.Decimal { return models.DecimalFromInt(1) }
 	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
 	app.activeOrders["acc1"] = []models.Order{
-		{ID: "O1", Symbol: "GAZP", Name: "Газпром", Side: "Buy", Type: "Market", Status: "New", Quantity: "10"},
+		{ID: "O1", Symbol: "GAZP", Name: "Газпром", Side: "Buy", Type: "Market", Status: "New", Quantity: models.DecimalOf("10")},
 	}
 
 	updateOrdersTable(app)
@@ -187,12 +187,12 @@ func TestOrdersTable_InstrumentHeader(t *testing.T) {
 
 func TestStatusBar_OrdersTabShortcuts(t *testing.T) {
 	mock := &mockClient{}
-	mock.GetLotSizeFunc = func(ticker string) float64 { return 1 }
+	mock.GetLotSizeFunc = func(ticker string) models.Decimal { return models.DecimalFromInt(1) }
 	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
 
 	// Add a cancellable order
 	app.activeOrders["acc1"] = []models.Order{
-		{ID: "O1", Symbol: "SBER", Side: "Buy", Type: "Limit", Status: "New", Quantity: "10"},
+		{ID: "O1", Symbol: "SBER", Side: "Buy", Type: "Limit", Status: "New", Quantity: models.DecimalOf("10")},
 	}
 
 	// Switch to Orders tab and focus the table
@@ -225,20 +225,20 @@ func searchString(s, substr string) bool {
 
 func TestOrdersTable_EnhancedColumns(t *testing.T) {
 	mock := &mockClient{}
-	mock.GetLotSizeFunc = func(ticker string) float64 { return 10 }
+	mock.GetLotSizeFunc = func(ticker string) models.Decimal { return models.DecimalFromInt(10) }
 	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
 
 	app.activeOrders["acc1"] = []models.Order{
 		{
 			ID: "STOP-1", Symbol: "SBER@TQBR", Name: "Сбербанк", Side: "Sell",
-			Type: "Stop", Status: "New", Quantity: "100",
-			StopPrice: "240.00", StopCondition: "Last Down", Validity: "GTC",
-			ExecutedQty: "0", RemainingQty: "100",
+			Type: "Stop", Status: "New", Quantity: models.DecimalOf("100"),
+			StopPrice: models.DecimalOf("240.00"), StopCondition: "Last Down", Validity: "GTC",
+			ExecutedQty: models.DecimalOf("0"), RemainingQty: models.DecimalOf("100"),
 		},
 		{
 			ID: "SLTP-1", Symbol: "GAZP@TQBR", Name: "Газпром", Side: "Sell",
-			Type: "SL/TP", Status: "New", Quantity: "",
-			SLPrice: "170.00", TPPrice: "200.00", SLQty: "10", TPQty: "10", Validity: "GTC",
+			Type: "SL/TP", Status: "New", Quantity: models.DecimalOf(""),
+			SLPrice: models.DecimalOf("170.00"), TPPrice: models.DecimalOf("200.00"), SLQty: models.DecimalOf("10"), TPQty: models.DecimalOf("10"), Validity: "GTC",
 		},
 	}
 
@@ -274,14 +274,14 @@ func TestOrdersTable_EnhancedColumns(t *testing.T) {
 
 func TestOrdersTable_NonGTCValidityInlined(t *testing.T) {
 	mock := &mockClient{}
-	mock.GetLotSizeFunc = func(ticker string) float64 { return 1 }
+	mock.GetLotSizeFunc = func(ticker string) models.Decimal { return models.DecimalFromInt(1) }
 	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
 
 	app.activeOrders["acc1"] = []models.Order{
 		{
 			ID: "LIM-1", Symbol: "SBER", Side: "Buy",
-			Type: "Limit", Status: "Active", Quantity: "10",
-			LimitPrice: "250.00", Validity: "Day",
+			Type: "Limit", Status: "Active", Quantity: models.DecimalOf("10"),
+			LimitPrice: models.DecimalOf("250.00"), Validity: "Day",
 		},
 	}
 
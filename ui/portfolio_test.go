@@ -96,8 +96,8 @@ func TestPortfolioView_UpdatePositions(t *testing.T) {
 	pv := NewPortfolioView(app)
 
 	positions := []models.Position{
-		{Symbol: "S1", Quantity: "10", AveragePrice: "100", CurrentPrice: "110", UnrealizedPnL: "100"},
-		{Symbol: "S2", Quantity: "5", AveragePrice: "200", CurrentPrice: "190", UnrealizedPnL: "-50"},
+		{Symbol: "S1", Quantity: models.DecimalOf("10"), AveragePrice: models.DecimalOf("100"), CurrentPrice: models.DecimalOf("110"), UnrealizedPnL: models.DecimalOf("100")},
+		{Symbol: "S2", Quantity: models.DecimalOf("5"), AveragePrice: models.DecimalOf("200"), CurrentPrice: models.DecimalOf("190"), UnrealizedPnL: models.DecimalOf("-50")},
 	}
 
 	pv.UpdatePositions(positions)
@@ -117,7 +117,7 @@ func TestPortfolioView_LotBasedQuantity(t *testing.T) {
 	pv := NewPortfolioView(app)
 
 	positions := []models.Position{
-		{Symbol: "SBER", Ticker: "SBER", MIC: "TQBR", Quantity: "100", LotSize: 10},
+		{Symbol: "SBER", Ticker: "SBER", MIC: "TQBR", Quantity: models.DecimalOf("100"), LotSize: models.DecimalFromInt(10)},
 	}
 
 	pv.UpdatePositions(positions)
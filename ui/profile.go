@@ -15,11 +15,19 @@ type ProfilePanel struct {
 	Layout    *tview.Flex
 	InfoPanel *tview.TextView
 	ChartView *tview.TextView
+	BookView  *tview.TextView
+	TapeView  *tview.TextView
 	Footer    *tview.TextView
 
 	app       *tview.Application
 	profile   *models.InstrumentProfile
 	timeframe int // 0=M5, 1=H1, 2=D, 3=W
+
+	book      *models.OrderBook
+	ownOrders []models.Order
+	tape      []models.MarketTrade // newest first
+	blockLots float64              // highlight prints of at least this many lots; 0 = off
+	lotSize   models.Decimal       // fallback lot size until asset details are loaded
 }
 
 // GetProfile returns the current instrument profile (may be nil).
@@ -43,15 +51,31 @@ func NewProfilePanel(app *tview.Application) *ProfilePanel {
 		SetDynamicColors(true)
 	p.ChartView.SetBorder(true).SetTitle(" Chart ")
 
+	p.BookView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+	p.BookView.SetBorder(true).SetTitle(" Order Book ")
+
+	p.TapeView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+	p.TapeView.SetBorder(true).SetTitle(" Time & Sales ")
+
 	p.Footer = tview.NewTextView().
 		SetDynamicColors(true).
 		SetTextAlign(tview.AlignCenter)
 	p.Footer.SetText(profileFooterText)
 
-	// Horizontal: InfoPanel (42 cols fixed) + ChartView (flex)
+	// Right column: order book (header + 2x10 levels + spread + borders) above the tape
+	marketColumn := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(p.BookView, 2*orderBookDepth+4, 0, false).
+		AddItem(p.TapeView, 0, 1, false)
+
+	// Horizontal: InfoPanel (42 cols fixed) + ChartView (flex) + market column (40 cols fixed)
 	contentRow := tview.NewFlex().SetDirection(tview.FlexColumn).
 		AddItem(p.InfoPanel, 42, 0, false).
-		AddItem(p.ChartView, 0, 1, false)
+		AddItem(p.ChartView, 0, 1, false).
+		AddItem(marketColumn, 40, 0, false)
 
 	// Vertical: content + footer
 	p.Layout = tview.NewFlex().SetDirection(tview.FlexRow).
@@ -61,18 +85,74 @@ func NewProfilePanel(app *tview.Application) *ProfilePanel {
 	return p
 }
 
-const profileFooterText = "[yellow]1[white] M5  [yellow]2[white] H1  [yellow]3[white] D  [yellow]4[white] W  │  [yellow]A[white] Order  [yellow]R[white] Refresh  [yellow]ESC[white] Back"
+const profileFooterText = "[yellow]1[white] M5  [yellow]2[white] H1  [yellow]3[white] D  [yellow]4[white] W  │  [yellow]A[white] Order  [yellow]L[white] Alert  [yellow]B[white] Block filter  [yellow]R[white] Refresh  [yellow]ESC[white] Back"
 
 // RestoreFooter resets the footer to the default hint text.
 func (p *ProfilePanel) RestoreFooter() {
 	p.Footer.SetText(profileFooterText)
 }
 
-// Update performs a full refresh of both the info panel and chart.
+// Update performs a full refresh of the info panel, chart and order book.
 func (p *ProfilePanel) Update(profile *models.InstrumentProfile) {
+	if profile == nil || p.profile == nil || profile.Symbol != p.profile.Symbol {
+		// Different instrument (or loading state): drop the previous book and tape
+		p.book = nil
+		p.tape = nil
+	}
 	p.profile = profile
 	p.renderInfoPanel()
 	p.renderChart()
+	p.renderOrderBook()
+	p.renderTape()
+}
+
+// UpdateOrderBook replaces the order book snapshot and redraws the DOM column.
+func (p *ProfilePanel) UpdateOrderBook(book *models.OrderBook) {
+	p.book = book
+	p.renderOrderBook()
+}
+
+// SetOwnOrders sets our working orders for the instrument, marked in the DOM at their price levels.
+func (p *ProfilePanel) SetOwnOrders(orders []models.Order) {
+	p.ownOrders = orders
+	p.renderOrderBook()
+}
+
+// SetLotSize sets the lot size used until the asset details with the exact lot size are loaded.
+func (p *ProfilePanel) SetLotSize(lotSize models.Decimal) {
+	p.lotSize = lotSize
+}
+
+// instrumentLotSize returns the lot size from the asset details, or the fallback set by SetLotSize.
+func (p *ProfilePanel) instrumentLotSize() models.Decimal {
+	if p.profile != nil && p.profile.Details != nil {
+		if lot := models.DecimalOf(p.profile.Details.LotSize); lot.Sign() > 0 {
+			return lot
+		}
+	}
+	return p.lotSize
+}
+
+// instrumentDecimals returns the price decimals from the asset details, 0 if unknown.
+func (p *ProfilePanel) instrumentDecimals() int32 {
+	if p.profile != nil && p.profile.Details != nil {
+		return p.profile.Details.Decimals
+	}
+	return 0
+}
+
+// renderOrderBook renders the DOM column.
+func (p *ProfilePanel) renderOrderBook() {
+	p.BookView.SetText(renderOrderBookText(p.book, p.ownOrders, p.instrumentLotSize(), p.instrumentDecimals()))
+}
+
+// UpdateQuote replaces the quote of the current profile and redraws only the info panel.
+func (p *ProfilePanel) UpdateQuote(quote *models.Quote) {
+	if p.profile == nil {
+		return
+	}
+	p.profile.Quote = quote
+	p.renderInfoPanel()
 }
 
 // UpdateChart refreshes only the chart (used for timeframe switches).
@@ -121,7 +201,7 @@ func (p *ProfilePanel) renderInfoPanel() {
 		writeField(&sb, "Currency", d.QuoteCurrency)
 		writeField(&sb, "Lot Size", d.LotSize)
 		writeField(&sb, "Decimals", fmt.Sprintf("%d", d.Decimals))
-		writeField(&sb, "Min Step", fmt.Sprintf("%d", d.MinStep))
+		writeField(&sb, "Min Step", d.PriceStep().String())
 		if d.ExpirationDate != "" {
 			writeField(&sb, "Expiry", d.ExpirationDate)
 		}
@@ -154,16 +234,16 @@ func (p *ProfilePanel) renderInfoPanel() {
 	// Quote section
 	if q := p.profile.Quote; q != nil {
 		sb.WriteString("[cyan::b]─── Quote ───[-:-:-]\n")
-		writeField(&sb, "Last", q.Last)
+		writeField(&sb, "Last", q.Last.String())
 		writeField(&sb, "Bid", fmt.Sprintf("%s (%s)", q.Bid, q.BidSize))
 		writeField(&sb, "Ask", fmt.Sprintf("%s (%s)", q.Ask, q.AskSize))
-		writeField(&sb, "Volume", q.Volume)
-		writeField(&sb, "Open", q.Open)
-		writeField(&sb, "High", q.High)
-		writeField(&sb, "Low", q.Low)
-		writeField(&sb, "Close", q.Close)
-		if q.OpenInterest != "" && q.OpenInterest != "0" {
-			writeField(&sb, "Open Int.", q.OpenInterest)
+		writeField(&sb, "Volume", q.Volume.String())
+		writeField(&sb, "Open", q.Open.String())
+		writeField(&sb, "High", q.High.String())
+		writeField(&sb, "Low", q.Low.String())
+		writeField(&sb, "Close", q.Close.String())
+		if q.OpenInterest.Sign() != 0 {
+			writeField(&sb, "Open Int.", q.OpenInterest.String())
 		}
 		sb.WriteString("\n")
 	}
@@ -51,16 +51,16 @@ func TestProfilePanel_Update(t *testing.T) {
 			MinStep:       1,
 		},
 		Quote: &models.Quote{
-			Last:    "280.50",
-			Bid:     "280.40",
-			BidSize: "100",
-			Ask:     "280.60",
-			AskSize: "50",
-			Volume:  "1000000",
-			Open:    "278.00",
-			High:    "282.00",
-			Low:     "277.50",
-			Close:   "279.00",
+			Last:    models.DecimalOf("280.50"),
+			Bid:     models.DecimalOf("280.40"),
+			BidSize: models.DecimalOf("100"),
+			Ask:     models.DecimalOf("280.60"),
+			AskSize: models.DecimalOf("50"),
+			Volume:  models.DecimalOf("1000000"),
+			Open:    models.DecimalOf("278.00"),
+			High:    models.DecimalOf("282.00"),
+			Low:     models.DecimalOf("277.50"),
+			Close:   models.DecimalOf("279.00"),
 		},
 		Params: &models.AssetParams{
 			IsTradable: true,
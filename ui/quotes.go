@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"finam-terminal/conditional"
+	"finam-terminal/models"
+)
+
+// quoteFlushInterval batches streamed quotes so a burst of updates costs one redraw.
+const quoteFlushInterval = 250 * time.Millisecond
+
+// quoteBuffer collects streamed quotes between UI flushes, keeping only the latest per symbol.
+type quoteBuffer struct {
+	mu      sync.Mutex
+	pending map[string]models.Quote
+}
+
+// put stores a quote, replacing any older pending quote for the same symbol.
+func (b *quoteBuffer) put(q models.Quote) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pending == nil {
+		b.pending = make(map[string]models.Quote)
+	}
+	b.pending[q.Symbol] = q
+}
+
+// take returns the pending quotes and resets the buffer.
+func (b *quoteBuffer) take() map[string]models.Quote {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	batch := b.pending
+	b.pending = nil
+	return batch
+}
+
+// onQuote is the stream handler registered with the API client.
+// It runs on the stream goroutine and only buffers the update.
+func (a *App) onQuote(q models.Quote) {
+	a.quoteBuf.put(q)
+}
+
+// quoteLoop periodically applies buffered quotes on the UI thread.
+func (a *App) quoteLoop() {
+	ticker := time.NewTicker(quoteFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stopChan:
+			return
+		case <-ticker.C:
+			batch := a.quoteBuf.take()
+			if len(batch) == 0 {
+				continue
+			}
+			a.app.QueueUpdateDraw(func() {
+				a.applyQuotes(batch)
+			})
+		}
+	}
+}
+
+// applyQuotes pushes streamed quotes into positions, the search results, the open profile,
+// the watchlist, the trailing stops, the conditional orders and the alerts.
+// Must be called on the UI thread.
+func (a *App) applyQuotes(batch map[string]models.Quote) {
+	a.dataMutex.Lock()
+	currentChanged := false
+	for accID, positions := range a.positions {
+		for i := range positions {
+			q, ok := batch[positions[i].Symbol]
+			if !ok {
+				continue
+			}
+			if a.quotes[accID] == nil {
+				a.quotes[accID] = make(map[string]*models.Quote)
+			}
+			quote := q
+			a.quotes[accID][q.Symbol] = &quote
+			if q.Last.Known() {
+				positions[i].CurrentPrice = q.Last
+			}
+			if a.selectedIdx < len(a.accounts) && a.accounts[a.selectedIdx].ID == accID {
+				currentChanged = true
+			}
+		}
+	}
+	a.dataMutex.Unlock()
+
+	if currentChanged {
+		updatePositionsTable(a)
+		updateInfoPanel(a)
+	}
+
+	a.applyWatchQuotes(batch)
+	a.trailQuotes(batch)
+	a.evaluateConditionals(batch)
+	a.evaluateAlerts(batch)
+
+	if a.IsSearchModalOpen() {
+		for _, q := range batch {
+			a.searchModal.UpdateQuote(q)
+		}
+	}
+
+	if a.profileOpen && a.profileSymbol != "" {
+		for _, q := range batch {
+			if symbolMatches(q.Symbol, a.profileSymbol) {
+				quote := q
+				a.profilePanel.UpdateQuote(&quote)
+				break
+			}
+		}
+	}
+}
+
+// quoteSymbols returns every symbol currently in view: positions of all accounts,
+// the search results while the search modal is open, the open profile and the active
+// watchlist, plus the symbols of the trailing stops, the waiting conditional orders and
+// the alerts.
+// Must be called on the UI thread.
+func (a *App) quoteSymbols() []string {
+	var symbols []string
+
+	a.dataMutex.RLock()
+	for _, positions := range a.positions {
+		for _, p := range positions {
+			symbols = append(symbols, p.Symbol)
+		}
+	}
+	a.dataMutex.RUnlock()
+
+	if a.IsSearchModalOpen() {
+		symbols = append(symbols, a.searchModal.Symbols()...)
+	}
+	if a.profileOpen && a.profileSymbol != "" {
+		symbols = append(symbols, a.profileSymbol)
+	}
+	symbols = append(symbols, a.activeWatchlist().Symbols...)
+	for _, s := range a.trailing.Stops("") {
+		symbols = append(symbols, s.Symbol)
+	}
+	for _, c := range a.conditional.Orders("") {
+		if c.Status == conditional.StatusWaiting {
+			symbols = append(symbols, c.Symbol)
+		}
+	}
+	for _, alert := range a.alerts.Alerts() {
+		symbols = append(symbols, alert.Symbol)
+	}
+	return symbols
+}
+
+// updateQuoteSubscription points the quote stream at the symbols currently in view.
+// Must be called on the UI thread.
+func (a *App) updateQuoteSubscription() {
+	a.dataMutex.RLock()
+	accountID := ""
+	if a.selectedIdx >= 0 && a.selectedIdx < len(a.accounts) {
+		accountID = a.accounts[a.selectedIdx].ID
+	}
+	a.dataMutex.RUnlock()
+
+	symbols := a.quoteSymbols()
+
+	// Resolving tickers may hit the API, keep it off the UI thread. One goroutine sends the
+	// sets in turn, so an older set never lands after a newer one.
+	a.quoteSubMu.Lock()
+	a.quoteSubPending = &quoteSubscription{accountID: accountID, symbols: symbols}
+	running := a.quoteSubRunning
+	a.quoteSubRunning = true
+	a.quoteSubMu.Unlock()
+	if !running {
+		go a.sendQuoteSubscriptions()
+	}
+}
+
+// quoteSubscription is a set of symbols to stream quotes for.
+type quoteSubscription struct {
+	accountID string
+	symbols   []string
+}
+
+// sendQuoteSubscriptions subscribes to the latest pending symbol set until none is left.
+// Sets requested while one is being sent replace each other; only the last one is sent.
+func (a *App) sendQuoteSubscriptions() {
+	for {
+		a.quoteSubMu.Lock()
+		sub := a.quoteSubPending
+		a.quoteSubPending = nil
+		if sub == nil {
+			a.quoteSubRunning = false
+			a.quoteSubMu.Unlock()
+			return
+		}
+		a.quoteSubMu.Unlock()
+
+		a.client.SubscribeQuotes(sub.accountID, sub.symbols)
+	}
+}
+
+// symbolMatches reports whether a full symbol (TICKER@MIC) refers to the given
+// symbol, which may be either a full symbol or a bare ticker.
+func symbolMatches(fullSymbol, symbol string) bool {
+	if fullSymbol == symbol {
+		return true
+	}
+	if strings.Contains(symbol, "@") {
+		return false
+	}
+	ticker, _, _ := strings.Cut(fullSymbol, "@")
+	return ticker == symbol
+}
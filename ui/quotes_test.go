@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"finam-terminal/models"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+func TestApplyQuotes_UpdatesPositions(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}, {ID: "acc2"}})
+	app.positions["acc1"] = []models.Position{
+		{Ticker: "SBER", Symbol: "SBER@TQBR", Quantity: models.DecimalOf("10"), CurrentPrice: models.DecimalOf("250.00")},
+	}
+	app.positions["acc2"] = []models.Position{
+		{Ticker: "SBER", Symbol: "SBER@TQBR", Quantity: models.DecimalOf("5"), CurrentPrice: models.DecimalOf("250.00")},
+		{Ticker: "GAZP", Symbol: "GAZP@TQBR", Quantity: models.DecimalOf("1"), CurrentPrice: models.DecimalOf("160.00")},
+	}
+
+	app.applyQuotes(map[string]models.Quote{
+		"SBER@TQBR": {Symbol: "SBER@TQBR", Last: models.DecimalOf("251.50")},
+	})
+
+	for _, acc := range []string{"acc1", "acc2"} {
+		if got := app.positions[acc][0].CurrentPrice; got.String() != "251.50" {
+			t.Errorf("%s: expected SBER price 251.50, got %s", acc, got)
+		}
+		if q := app.quotes[acc]["SBER@TQBR"]; q == nil || q.Last.String() != "251.50" {
+			t.Errorf("%s: expected cached SBER quote", acc)
+		}
+	}
+	if got := app.positions["acc2"][1].CurrentPrice; got.String() != "160.00" {
+		t.Errorf("Expected GAZP price untouched, got %s", got)
+	}
+
+	// Selected account's table shows the streamed price
+	if cell := app.portfolioView.TabbedView.PositionsTable.GetCell(1, 3); cell.Text != "251.50" {
+		t.Errorf("Expected positions table price 251.50, got %s", cell.Text)
+	}
+}
+
+func TestApplyQuotes_UpdatesProfile(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	app.profileOpen = true
+	app.profileSymbol = "SBER@TQBR"
+	app.profilePanel.Update(&models.InstrumentProfile{Symbol: "SBER@TQBR", Quote: &models.Quote{Last: models.DecimalOf("250.00")}})
+
+	app.applyQuotes(map[string]models.Quote{
+		"SBER@TQBR": {Symbol: "SBER@TQBR", Last: models.DecimalOf("252.00")},
+	})
+
+	if p := app.profilePanel.GetProfile(); p.Quote == nil || p.Quote.Last.String() != "252.00" {
+		t.Errorf("Expected profile quote 252.00, got %+v", p.Quote)
+	}
+}
+
+func TestQuoteSymbols(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}, {ID: "acc2"}})
+	app.positions["acc1"] = []models.Position{{Symbol: "SBER@TQBR"}}
+	app.positions["acc2"] = []models.Position{{Symbol: "GAZP@TQBR"}}
+	app.profileOpen = true
+	app.profileSymbol = "YNDX@TQBR"
+
+	got := app.quoteSymbols()
+	slices.Sort(got)
+	want := []string{"GAZP@TQBR", "SBER@TQBR", "YNDX@TQBR"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestSearchModal_UpdateQuote(t *testing.T) {
+	modal := NewSearchModal(tview.NewApplication(), nil, nil, nil, nil)
+	modal.results = []models.SecurityInfo{
+		{Ticker: "SBER", Symbol: "SBER@TQBR", Name: "Sberbank"},
+		{Ticker: "GAZP", Symbol: "GAZP@TQBR", Name: "Gazprom"},
+	}
+	modal.updateTable(nil)
+
+	modal.UpdateQuote(models.Quote{Symbol: "GAZP@TQBR", Last: models.DecimalOf("110.00"), Close: models.DecimalOf("100.00")})
+
+	if cell := modal.Table.GetCell(2, 4); cell.Text != "110.00" {
+		t.Errorf("Expected GAZP price 110.00, got %s", cell.Text)
+	}
+	if cell := modal.Table.GetCell(2, 5); cell.Text != "+10.00%" {
+		t.Errorf("Expected GAZP change +10.00%%, got %s", cell.Text)
+	}
+	if cell := modal.Table.GetCell(1, 4); cell.Text != "..." {
+		t.Errorf("Expected SBER price untouched, got %s", cell.Text)
+	}
+}
+
+func TestSymbolMatches(t *testing.T) {
+	tests := []struct {
+		full, symbol string
+		want         bool
+	}{
+		{"SBER@TQBR", "SBER@TQBR", true},
+		{"SBER@TQBR", "SBER", true},
+		{"SBER@TQBR", "SBER@MISX", false},
+		{"SBER@TQBR", "GAZP", false},
+	}
+	for _, tt := range tests {
+		if got := symbolMatches(tt.full, tt.symbol); got != tt.want {
+			t.Errorf("symbolMatches(%q, %q) = %v, want %v", tt.full, tt.symbol, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateQuoteSubscription_LatestSetLandsLast(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var sent [][]string
+	mock := &mockClient{
+		SubscribeQuotesFunc: func(accountID string, symbols []string) {
+			mu.Lock()
+			sent = append(sent, symbols)
+			first := len(sent) == 1
+			mu.Unlock()
+			if first {
+				<-release // The first set is slow to resolve
+			}
+		},
+	}
+	app := NewApp(mock, []models.AccountInfo{{ID: "acc1"}})
+
+	for _, symbols := range [][]string{{"SBER"}, {"GAZP"}, {"LKOH"}} {
+		app.dataMutex.Lock()
+		app.positions["acc1"] = []models.Position{{Symbol: symbols[0]}}
+		app.dataMutex.Unlock()
+		app.updateQuoteSubscription()
+	}
+	close(release)
+
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		app.quoteSubMu.Lock()
+		running := app.quoteSubRunning
+		app.quoteSubMu.Unlock()
+		if !running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the pending subscriptions to be sent")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) == 0 || !slices.Contains(sent[len(sent)-1], "LKOH") {
+		t.Errorf("Expected the latest set to be subscribed last, got %v", sent)
+	}
+	for _, symbols := range sent[1:] {
+		if slices.Contains(symbols, "GAZP") {
+			t.Errorf("Expected the superseded set to be skipped, got %v", sent)
+		}
+	}
+}
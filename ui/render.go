@@ -59,9 +59,7 @@ func updateAccountList(app *App) {
 		app.dataMutex.RLock()
 		var dailyTotal float64
 		for _, p := range app.positions[acc.ID] {
-			if val, err := parseFloat(p.DailyPnL); err == nil {
-				dailyTotal += val
-			}
+			dailyTotal += p.DailyPnL.Float64()
 		}
 		app.dataMutex.RUnlock()
 
@@ -121,39 +119,29 @@ func updatePositionsTable(app *App) {
 		quote := q[p.Symbol]
 		rowNum := row + 1
 
-		qty, _ := parseFloat(p.Quantity)
 		displayQty := displayLots(p.Quantity, p.LotSize)
 
 		totalValue := "N/A"
-		if quote != nil && quote.Last != "N/A" {
-			lastPrice, _ := parseFloat(quote.Last)
-			totalValue = fmt.Sprintf("%.2f", qty*lastPrice)
+		if quote != nil && quote.Last.Known() {
+			totalValue = p.Quantity.Mul(quote.Last).StringFixed(2)
 		}
 
-		dailyPnL := p.DailyPnL
+		dailyPnL := p.DailyPnL.String()
 		dailyColor := tcell.ColorWhite
-		if dailyPnL != "N/A" {
-			if val, err := parseFloat(dailyPnL); err == nil {
-				if val > 0 {
-					dailyPnL = "+" + dailyPnL
-					dailyColor = tcell.ColorGreen
-				} else if val < 0 {
-					dailyColor = tcell.ColorRed
-				}
-			}
+		if p.DailyPnL.Sign() > 0 {
+			dailyPnL = "+" + dailyPnL
+			dailyColor = tcell.ColorGreen
+		} else if p.DailyPnL.Sign() < 0 {
+			dailyColor = tcell.ColorRed
 		}
 
-		unrealizedPnL := p.UnrealizedPnL
+		unrealizedPnL := p.UnrealizedPnL.String()
 		unrealColor := tcell.ColorWhite
-		if unrealizedPnL != "N/A" {
-			if val, err := parseFloat(unrealizedPnL); err == nil {
-				if val > 0 {
-					unrealizedPnL = "+" + unrealizedPnL
-					unrealColor = tcell.ColorGreen
-				} else if val < 0 {
-					unrealColor = tcell.ColorRed
-				}
-			}
+		if p.UnrealizedPnL.Sign() > 0 {
+			unrealizedPnL = "+" + unrealizedPnL
+			unrealColor = tcell.ColorGreen
+		} else if p.UnrealizedPnL.Sign() < 0 {
+			unrealColor = tcell.ColorRed
 		}
 
 		displayName := p.Name
@@ -173,9 +161,9 @@ func updatePositionsTable(app *App) {
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(tcell.ColorLightYellow)).SetAlign(tview.AlignLeft))
 		app.portfolioView.TabbedView.PositionsTable.SetCell(rowNum, 1, tview.NewTableCell(displayQty).
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(tcell.ColorWhite)).SetAlign(tview.AlignRight))
-		app.portfolioView.TabbedView.PositionsTable.SetCell(rowNum, 2, tview.NewTableCell(p.AveragePrice).
+		app.portfolioView.TabbedView.PositionsTable.SetCell(rowNum, 2, tview.NewTableCell(p.AveragePrice.String()).
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(tcell.ColorWhite)).SetAlign(tview.AlignRight))
-		app.portfolioView.TabbedView.PositionsTable.SetCell(rowNum, 3, tview.NewTableCell(p.CurrentPrice).
+		app.portfolioView.TabbedView.PositionsTable.SetCell(rowNum, 3, tview.NewTableCell(p.CurrentPrice.String()).
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(tcell.ColorLightCyan)).SetAlign(tview.AlignRight))
 		app.portfolioView.TabbedView.PositionsTable.SetCell(rowNum, 4, tview.NewTableCell(dailyPnL).
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(dailyColor)).SetAlign(tview.AlignRight))
@@ -232,6 +220,19 @@ func updateHistoryTable(app *App) {
 	history := app.history[accountID]
 	app.dataMutex.RUnlock()
 
+	title := " History "
+	if !app.historyFilter.IsZero() {
+		title = fmt.Sprintf(" History: %s ", historyFilterTitle(app.historyFilter))
+		var filtered []models.Trade
+		for _, t := range history {
+			if app.historyFilter.Match(t) {
+				filtered = append(filtered, t)
+			}
+		}
+		history = filtered
+	}
+	app.portfolioView.TabbedView.HistoryTable.SetTitle(title)
+
 	for row, t := range history {
 		rowNum := row + 1
 		rowBg := tcell.ColorBlack
@@ -250,7 +251,7 @@ func updateHistoryTable(app *App) {
 		timeStr := t.Timestamp.Format("01-02 15:04")
 
 		// Convert quantity to lots
-		var lotSize float64
+		var lotSize models.Decimal
 		if app.client != nil {
 			lotSize = app.client.GetLotSize(t.Symbol)
 		}
@@ -265,18 +266,22 @@ func updateHistoryTable(app *App) {
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(tcell.ColorLightYellow)).SetAlign(tview.AlignLeft))
 		app.portfolioView.TabbedView.HistoryTable.SetCell(rowNum, 1, tview.NewTableCell(t.Side).
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(sideColor)).SetAlign(tview.AlignRight))
-		app.portfolioView.TabbedView.HistoryTable.SetCell(rowNum, 2, tview.NewTableCell(t.Price).
+		app.portfolioView.TabbedView.HistoryTable.SetCell(rowNum, 2, tview.NewTableCell(t.Price.String()).
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(tcell.ColorWhite)).SetAlign(tview.AlignRight))
 		app.portfolioView.TabbedView.HistoryTable.SetCell(rowNum, 3, tview.NewTableCell(displayQty).
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(tcell.ColorWhite)).SetAlign(tview.AlignRight))
-		app.portfolioView.TabbedView.HistoryTable.SetCell(rowNum, 4, tview.NewTableCell(t.Total).
+		app.portfolioView.TabbedView.HistoryTable.SetCell(rowNum, 4, tview.NewTableCell(t.Total.String()).
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(tcell.ColorLightGreen)).SetAlign(tview.AlignRight))
 		app.portfolioView.TabbedView.HistoryTable.SetCell(rowNum, 5, tview.NewTableCell(timeStr).
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(tcell.ColorWhite)).SetAlign(tview.AlignRight))
 	}
 
 	if len(history) == 0 {
-		app.portfolioView.TabbedView.HistoryTable.SetCell(1, 0, tview.NewTableCell("No trade history found").
+		message := "No trade history found"
+		if !app.historyFilter.IsZero() {
+			message = "No trades match the filter"
+		}
+		app.portfolioView.TabbedView.HistoryTable.SetCell(1, 0, tview.NewTableCell(message).
 			SetSelectable(false).
 			SetAlign(tview.AlignCenter).
 			SetTextColor(tcell.ColorGray))
@@ -311,7 +316,7 @@ func updateOrdersTable(app *App) {
 		return
 	}
 	accountID := app.accounts[app.selectedIdx].ID
-	orders := app.activeOrders[accountID]
+	orders, rowGroups := app.groupOrderRows(app.activeOrders[accountID])
 	app.dataMutex.RUnlock()
 
 	for row, o := range orders {
@@ -349,12 +354,31 @@ func updateOrdersTable(app *App) {
 		if orderDisplayName == "" {
 			orderDisplayName = o.Symbol
 		}
+		orderDisplayName = ocoRowPrefix(rowGroups, row) + orderDisplayName
+		if app.ocoMarked[o.ID] {
+			orderDisplayName = "● " + orderDisplayName
+		}
 
-		// Build Price/Condition display
+		// Build Price/Condition display. GTD orders go out as GTC, so their date is the terminal's
+		if until := app.orderValidUntil(o.ID); !until.IsZero() {
+			o.Validity = models.ValidityGTD + " " + until.Format(validUntilLayout)
+		}
 		priceCondition := formatOrderPriceCondition(o)
+		orderType := o.Type
+		if s, ok := app.trailing.ByOrderID(o.ID); ok && isCancellable {
+			orderType = models.OrderTypeTrailing
+			priceCondition += " trail " + s.Trail()
+		}
+		if sl, tp, ok := app.bracketOf(o.ID); ok && isCancellable {
+			orderType = models.OrderTypeBracket
+			priceCondition += formatBracketProtection(sl, tp)
+		}
+		if g := rowGroups[row]; g != nil {
+			priceCondition += " OCO " + g.ID
+		}
 
 		// Convert quantity to lots for display
-		var lotSize float64
+		var lotSize models.Decimal
 		if app.client != nil {
 			lotSize = app.client.GetLotSize(o.Symbol)
 		}
@@ -379,7 +403,7 @@ func updateOrdersTable(app *App) {
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(nameColor)).SetAlign(tview.AlignLeft))
 		app.portfolioView.TabbedView.OrdersTable.SetCell(rowNum, 1, tview.NewTableCell(o.Side).
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(sideColor)).SetAlign(tview.AlignRight))
-		app.portfolioView.TabbedView.OrdersTable.SetCell(rowNum, 2, tview.NewTableCell(o.Type).
+		app.portfolioView.TabbedView.OrdersTable.SetCell(rowNum, 2, tview.NewTableCell(orderType).
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(fgColor)).SetAlign(tview.AlignRight))
 		app.portfolioView.TabbedView.OrdersTable.SetCell(rowNum, 3, tview.NewTableCell(o.Status).
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(statusColor)).SetAlign(tview.AlignRight))
@@ -393,7 +417,9 @@ func updateOrdersTable(app *App) {
 			SetStyle(tcell.StyleDefault.Background(rowBg).Foreground(fgColor)).SetAlign(tview.AlignRight))
 	}
 
-	if len(orders) == 0 {
+	renderConditionalRows(app, accountID, len(orders)+1)
+
+	if len(orders) == 0 && app.portfolioView.TabbedView.OrdersTable.GetRowCount() <= 1 {
 		app.portfolioView.TabbedView.OrdersTable.SetCell(1, 0, tview.NewTableCell("No active orders").
 			SetSelectable(false).
 			SetAlign(tview.AlignCenter).
@@ -401,6 +427,18 @@ func updateOrdersTable(app *App) {
 	}
 }
 
+// formatBracketProtection shows the armed SL/TP prices of a bracket entry.
+func formatBracketProtection(sl, tp models.Decimal) string {
+	var parts []string
+	if sl.Sign() > 0 {
+		parts = append(parts, "SL:"+sl.String())
+	}
+	if tp.Sign() > 0 {
+		parts = append(parts, "TP:"+tp.String())
+	}
+	return " → " + strings.Join(parts, " / ")
+}
+
 // formatOrderPriceCondition builds a display string for the Price/Condition column.
 // Non-GTC validity is appended in parentheses, e.g. "SL: 100.50 ↓ (Day)".
 func formatOrderPriceCondition(o models.Order) string {
@@ -408,16 +446,16 @@ func formatOrderPriceCondition(o models.Order) string {
 	switch o.Type {
 	case "SL/TP":
 		var parts []string
-		if o.SLPrice != "" && o.SLPrice != "N/A" && o.SLPrice != "0" {
-			parts = append(parts, "SL:"+o.SLPrice)
+		if o.SLPrice.Sign() > 0 {
+			parts = append(parts, "SL:"+o.SLPrice.String())
 		}
-		if o.TPPrice != "" && o.TPPrice != "N/A" && o.TPPrice != "0" {
-			parts = append(parts, "TP:"+o.TPPrice)
+		if o.TPPrice.Sign() > 0 {
+			parts = append(parts, "TP:"+o.TPPrice.String())
 		}
 		if len(parts) > 0 {
 			result = strings.Join(parts, " / ")
 		} else {
-			result = o.Price
+			result = o.PriceText()
 		}
 	case "Stop":
 		arrow := ""
@@ -427,13 +465,13 @@ func formatOrderPriceCondition(o models.Order) string {
 		case "Last Up":
 			arrow = " ↑"
 		}
-		result = "SL: " + o.StopPrice + arrow
+		result = "SL: " + o.StopPrice.String() + arrow
 	case "Stop-Limit":
-		result = "Stop: " + o.StopPrice + " Lim: " + o.LimitPrice
+		result = "Stop: " + o.StopPrice.String() + " Lim: " + o.LimitPrice.String()
 	case "Limit":
-		result = o.LimitPrice
+		result = o.LimitPrice.String()
 	default:
-		result = o.Price
+		result = o.PriceText()
 	}
 
 	// Append non-GTC validity
@@ -455,14 +493,8 @@ func updateInfoPanel(app *App) {
 	var totalPnL float64
 
 	for _, p := range pos {
-		if qty, err := parseFloat(p.Quantity); err == nil {
-			if price, err := parseFloat(p.CurrentPrice); err == nil {
-				totalValue += qty * price
-			}
-		}
-		if val, err := parseFloat(p.DailyPnL); err == nil {
-			totalPnL += val
-		}
+		totalValue += p.Quantity.Mul(p.CurrentPrice).Float64()
+		totalPnL += p.DailyPnL.Float64()
 	}
 
 	app.portfolioView.UpdateSummary(acc)
@@ -499,6 +531,8 @@ func updateStatusBar(app *App) {
 		statusText = fmt.Sprintf("[green]%s[white]", statusMsg)
 	case StatusError:
 		statusText = fmt.Sprintf("[red]%s[white]", statusMsg)
+	case StatusAlert:
+		statusText = fmt.Sprintf("[black:yellow] %s [white:-]", statusMsg)
 	default:
 		statusText = statusMsg
 	}
@@ -509,18 +543,38 @@ func updateStatusBar(app *App) {
 
 	var shortcuts string
 	if app.profileOpen {
-		shortcuts = "[yellow]1-4[white] Timeframe  [yellow]A[white] Order  [yellow]R[white] Refresh  [yellow]ESC[white] Back"
+		shortcuts = "[yellow]1-4[white] Timeframe  [yellow]A[white] Order  [yellow]L[white] Alert  [yellow]R[white] Refresh  [yellow]ESC[white] Back"
 	} else {
-		shortcuts = "[yellow]F2[white] Refresh [yellow]Tab[white] Switch Area [yellow]←/→[white] Tabs [yellow]q[white] Quit"
+		shortcuts = "[yellow]F2[white] Refresh [yellow]Tab[white] Switch Area [yellow]←/→[white] Tabs [yellow]F12[white] Kill [yellow]q[white] Quit"
 		// Check if TabbedView.PositionsTable is active and focused
 		if app.portfolioView.TabbedView.ActiveTab == TabPositions &&
 			app.app.GetFocus() == app.portfolioView.TabbedView.PositionsTable {
-			shortcuts += " | [yellow]A[white] Buy [yellow]C[white] Close"
+			shortcuts += " | [yellow]A[white] Buy [yellow]C[white] Close [yellow]^S[white] Export"
+		}
+		if app.portfolioView.TabbedView.ActiveTab == TabHistory &&
+			app.app.GetFocus() == app.portfolioView.TabbedView.HistoryTable {
+			shortcuts += " | [yellow]F[white] Filter [yellow]B[white] Older [yellow]^S[white] Export [yellow]R[white] Refresh"
 		}
 		// Check if TabbedView.OrdersTable is active and focused
 		if app.portfolioView.TabbedView.ActiveTab == TabOrders &&
 			app.app.GetFocus() == app.portfolioView.TabbedView.OrdersTable {
-			shortcuts += " | [yellow]X[white] Cancel [yellow]E[white] Modify [yellow]R[white] Refresh"
+			shortcuts += " | [yellow]X[white] Cancel [yellow]E[white] Modify [yellow]Space/G[white] OCO [yellow]^S[white] Export [yellow]R[white] Refresh"
+		}
+		if app.portfolioView.TabbedView.ActiveTab == TabWatchlists &&
+			app.app.GetFocus() == app.portfolioView.TabbedView.WatchlistTable {
+			shortcuts += " | [yellow]Enter[white] Profile [yellow]A[white] Order [yellow]X[white] Remove [yellow]</>[white] List [yellow]N/D[white] New/Delete List"
+		}
+		if app.portfolioView.TabbedView.ActiveTab == TabAlerts &&
+			app.app.GetFocus() == app.portfolioView.TabbedView.AlertsTable {
+			shortcuts += " | [yellow]L[white] New [yellow]X[white] Remove [yellow]C[white] Clear Log"
+		}
+		if app.portfolioView.TabbedView.ActiveTab == TabPnL &&
+			app.app.GetFocus() == app.portfolioView.TabbedView.PnLTable {
+			shortcuts += " | [yellow]M[white] FIFO/Average [yellow]V[white] Group [yellow]^S[white] Tax Report [yellow]R[white] Refresh"
+		}
+		if app.portfolioView.TabbedView.ActiveTab == TabTransactions &&
+			app.app.GetFocus() == app.portfolioView.TabbedView.TransactionsTable {
+			shortcuts += " | [yellow]V[white] Entries/Totals [yellow]P[white] Period [yellow]B[white] Older [yellow]^S[white] Export [yellow]R[white] Refresh"
 		}
 	}
 
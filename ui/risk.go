@@ -0,0 +1,235 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"finam-terminal/models"
+	"finam-terminal/risk"
+
+	"github.com/rivo/tview"
+)
+
+// SetRiskLimits configures the pre-trade checks applied to every order from the order modal.
+func (a *App) SetRiskLimits(limits models.RiskLimits) {
+	a.risk.SetLimits(limits)
+}
+
+// SetRiskStore loads the trading lock and the day's order counts saved in path and keeps
+// saving them there, so a lock survives a restart and applies to CLI orders as well.
+func (a *App) SetRiskStore(path string) error {
+	if err := a.risk.Restore(path); err != nil {
+		return err
+	}
+	if reason, locked := a.risk.Locked(); locked {
+		log.Printf("[WARN] Trading locked since an earlier run: %s", reason)
+	}
+	return nil
+}
+
+// recordOrder counts an order sent for accountID towards the daily order limit.
+func (a *App) recordOrder(accountID string) {
+	if err := a.risk.Record(accountID); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+}
+
+// checkOrderRisk runs the pre-trade checks for sub. With sub.OverrideRisk set the
+// violations are logged and the order is let through.
+func (a *App) checkOrderRisk(accountID string, sub OrderSubmission) error {
+	err := a.risk.Check(a.riskOrder(accountID, sub))
+	var rerr *risk.Error
+	if !errors.As(err, &rerr) {
+		return err
+	}
+	if sub.OverrideRisk && rerr.Overridable {
+		log.Printf("[WARN] Risk override confirmed for %s %s %s lots on %s: %v",
+			sub.Direction, sub.Instrument, sub.Quantity, accountID, rerr)
+		return nil
+	}
+	log.Printf("[INFO] Order %s %s %s lots on %s blocked: %v", sub.Direction, sub.Instrument, sub.Quantity, accountID, rerr)
+	return rerr
+}
+
+// riskOrder collects what the checks need to know about sub: lot size, the position from
+// the loaded portfolio, the last price and the instrument's trading parameters.
+func (a *App) riskOrder(accountID string, sub OrderSubmission) risk.Order {
+	o := risk.Order{
+		AccountID: accountID,
+		Ticker:    sub.Instrument,
+		Side:      sub.Direction,
+		Lots:      sub.Quantity,
+		LotSize:   a.client.GetLotSize(sub.Instrument),
+	}
+	for _, p := range []models.Decimal{sub.LimitPrice, sub.StopPrice, sub.SLPrice, sub.TPPrice} {
+		if p.Sign() > 0 {
+			o.Prices = append(o.Prices, p)
+		}
+	}
+
+	a.dataMutex.RLock()
+	for _, pos := range a.positions[accountID] {
+		if pos.Symbol == sub.Instrument || pos.Ticker == sub.Instrument {
+			if pos.Quantity.Known() {
+				o.Position = pos.Quantity
+			}
+			o.LastPrice = pos.CurrentPrice
+			break
+		}
+	}
+	a.dataMutex.RUnlock()
+
+	if o.LastPrice.Sign() <= 0 {
+		if snapshots, err := a.client.GetSnapshots(accountID, []string{sub.Instrument}); err == nil {
+			if q, ok := snapshots[sub.Instrument]; ok {
+				o.LastPrice = q.Last
+			}
+		}
+	}
+
+	params, err := a.client.GetAssetParams(accountID, sub.Instrument)
+	if err != nil {
+		log.Printf("[WARN] Risk checks: trading parameters of %s unavailable: %v", sub.Instrument, err)
+	} else {
+		o.Params = params
+	}
+	return o
+}
+
+// submitFromModal places the order from the order modal and reports failures. An order
+// stopped by the risk checks is offered for override when the limits allow it.
+func (a *App) submitFromModal(sub OrderSubmission) {
+	err := a.SubmitOrder(sub)
+	if err == nil {
+		return
+	}
+	var rerr *risk.Error
+	if errors.As(err, &rerr) {
+		a.ShowRiskViolation(rerr, func() {
+			sub.OverrideRisk = true
+			a.submitFromModal(sub)
+		})
+		return
+	}
+	a.ShowError(extractUserMessage(err))
+}
+
+// ShowRiskViolation lists the failed checks over the order modal. When the violation is
+// overridable, "Send anyway" calls onOverride; otherwise the dialog can only be dismissed.
+func (a *App) ShowRiskViolation(err *risk.Error, onOverride func()) {
+	var b strings.Builder
+	b.WriteString("Order blocked by risk checks:\n\n")
+	for _, v := range err.Violations {
+		fmt.Fprintf(&b, "• %s\n", v)
+	}
+
+	buttons := []string{"OK"}
+	if err.Overridable && onOverride != nil {
+		b.WriteString("\nSend the order anyway?")
+		buttons = []string{"Send anyway", "Back"}
+	}
+
+	modal := tview.NewModal().
+		SetText(b.String()).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.closeRiskConfirm()
+			if buttonLabel == "Send anyway" {
+				onOverride()
+			}
+		})
+
+	a.pages.AddPage("risk_confirm", modal, false, true)
+}
+
+// closeRiskConfirm removes the risk dialog and returns focus to the order modal.
+func (a *App) closeRiskConfirm() {
+	a.pages.RemovePage("risk_confirm")
+	if a.IsModalOpen() {
+		a.app.SetFocus(a.orderModal.Form)
+	}
+}
+
+// IsRiskConfirmOpen returns true if the risk violation dialog is currently shown
+func (a *App) IsRiskConfirmOpen() bool {
+	name, _ := a.pages.GetFrontPage()
+	return name == "risk_confirm"
+}
+
+// trackDailyLoss feeds an account's daily P&L to the daily loss limit and locks trading
+// when the limit is crossed. Must be called on the UI thread.
+func (a *App) trackDailyLoss(accountID string, pnl models.Decimal) {
+	if !pnl.Known() {
+		return
+	}
+	locked, err := a.risk.ObservePnL(accountID, pnl)
+	if err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+	if !locked {
+		return
+	}
+	reason, _ := a.risk.Locked()
+	log.Printf("[WARN] Trading locked: %s", reason)
+	updateHeader(a)
+	if a.risk.Limits().CancelOnLock {
+		go a.cancelAllOrders("Daily loss limit reached")
+		return
+	}
+	a.SetStatus("Daily loss limit reached: new opening orders are refused", StatusError)
+}
+
+// KillSwitch locks trading for the rest of the day and cancels every working order on
+// every account. Must be called on the UI thread.
+func (a *App) KillSwitch() {
+	if err := a.risk.Lock("kill switch engaged"); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+	log.Printf("[WARN] Kill switch engaged")
+	updateHeader(a)
+	go a.cancelAllOrders("Kill switch")
+}
+
+// cancelAllOrders cancels the working orders of all loaded accounts and reports the
+// outcome in the status bar after prefix. The order streams remove the cancelled orders
+// from the Orders tab.
+func (a *App) cancelAllOrders(prefix string) {
+	a.SetStatus(prefix+": cancelling all orders...", StatusLoading)
+
+	a.dataMutex.RLock()
+	accounts := append([]models.AccountInfo(nil), a.accounts...)
+	a.dataMutex.RUnlock()
+
+	var cancelled, failed int
+	for _, acc := range accounts {
+		if acc.LoadError != "" {
+			continue
+		}
+		orders, err := a.client.GetActiveOrders(acc.ID)
+		if err != nil {
+			log.Printf("[ERROR] Cancel all: failed to load orders for %s: %v", acc.ID, err)
+			failed++
+			continue
+		}
+		for _, o := range orders {
+			if !isOrderCancellable(o.Status) {
+				continue
+			}
+			if err := a.client.CancelOrder(acc.ID, o.ID); err != nil {
+				log.Printf("[ERROR] Cancel all: order %s on %s: %v", o.ID, acc.ID, err)
+				failed++
+				continue
+			}
+			cancelled++
+		}
+	}
+
+	log.Printf("[INFO] %s: %d orders cancelled, %d failures", prefix, cancelled, failed)
+	if failed > 0 {
+		a.SetStatus(fmt.Sprintf("%s: %d orders cancelled, %d failed — check the Orders tab", prefix, cancelled, failed), StatusError)
+		return
+	}
+	a.SetStatus(fmt.Sprintf("%s: %d orders cancelled, trading locked", prefix, cancelled), StatusError)
+}
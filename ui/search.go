@@ -15,7 +15,7 @@ import (
 type APISearchClient interface {
 	SearchSecurities(query string) ([]models.SecurityInfo, error)
 	GetSnapshots(accountID string, symbols []string) (map[string]models.Quote, error)
-	GetLotSize(ticker string) float64
+	GetLotSize(ticker string) models.Decimal
 }
 
 // SearchModal represents the security search window
@@ -31,12 +31,14 @@ type SearchModal struct {
 	onSelect      func(ticker string)
 	onCancel      func()
 	onViewProfile func(symbol string)
+	onResults     func()
+	onAlert       func(symbol string)
+	onWatch       func(symbol string)
 
 	results      []models.SecurityInfo
 	searchTimer  *time.Timer
 	searchCancel context.CancelFunc
 	timerMutex   sync.Mutex
-	refreshStop  chan struct{}
 
 	searching bool
 	lastError string
@@ -54,7 +56,6 @@ func NewSearchModal(app *tview.Application, client APISearchClient, onSelect fun
 		onSelect:      onSelect,
 		onCancel:      onCancel,
 		onViewProfile: onViewProfile,
-		refreshStop:   make(chan struct{}),
 	}
 	m.setupUI()
 	return m
@@ -67,6 +68,54 @@ func (m *SearchModal) SetAccountID(accountID string) {
 	m.accountID = accountID
 }
 
+// SetOnResultsChanged sets a callback invoked on the UI thread whenever the result list changes.
+func (m *SearchModal) SetOnResultsChanged(fn func()) {
+	m.onResults = fn
+}
+
+// SetOnAlert sets a callback invoked with the selected symbol when L is pressed.
+func (m *SearchModal) SetOnAlert(fn func(symbol string)) {
+	m.onAlert = fn
+}
+
+// SetOnWatch sets a callback invoked with the selected symbol when W is pressed.
+func (m *SearchModal) SetOnWatch(fn func(symbol string)) {
+	m.onWatch = fn
+}
+
+// Symbols returns the symbols of the current results (full symbol, or ticker if unknown).
+func (m *SearchModal) Symbols() []string {
+	symbols := make([]string, 0, len(m.results))
+	for _, res := range m.results {
+		if res.Symbol != "" {
+			symbols = append(symbols, res.Symbol)
+		} else {
+			symbols = append(symbols, res.Ticker)
+		}
+	}
+	return symbols
+}
+
+// UpdateQuote refreshes the price cells of the result rows matching the quote's symbol.
+func (m *SearchModal) UpdateQuote(q models.Quote) {
+	for i, res := range m.results {
+		sym := res.Symbol
+		if sym == "" {
+			sym = res.Ticker
+		}
+		if symbolMatches(q.Symbol, sym) {
+			m.setPriceCells(i+1, &q)
+		}
+	}
+}
+
+// resultsChanged notifies the listener that the result list was replaced.
+func (m *SearchModal) resultsChanged() {
+	if m.onResults != nil {
+		m.onResults()
+	}
+}
+
 func (m *SearchModal) setupUI() {
 	m.Layout.SetDirection(tview.FlexRow).
 		SetBorder(true).
@@ -120,8 +169,6 @@ func (m *SearchModal) setupUI() {
 
 // PerformSearch executes the search and updates the UI
 func (m *SearchModal) PerformSearch(query string) {
-	m.stopRefresh()
-
 	m.timerMutex.Lock()
 	if m.searchCancel != nil {
 		m.searchCancel()
@@ -134,6 +181,7 @@ func (m *SearchModal) PerformSearch(query string) {
 			m.lastError = ""
 			m.updateTable(nil)
 			m.updateFooter()
+			m.resultsChanged()
 		})
 		m.searchCancel = nil
 		m.timerMutex.Unlock()
@@ -180,11 +228,12 @@ func (m *SearchModal) PerformSearch(query string) {
 			m.results = nil
 			m.updateTable(nil)
 			m.updateFooter()
+			m.resultsChanged()
 		})
 		return
 	}
 
-	// Fetch snapshots using full symbol to distinguish duplicate tickers on different exchanges
+	// Fetch initial snapshots using full symbol to distinguish duplicate tickers on different exchanges
 	var symbols []string
 	for _, res := range results {
 		if res.Symbol != "" {
@@ -207,7 +256,7 @@ func (m *SearchModal) PerformSearch(query string) {
 		if sym == "" {
 			sym = results[i].Ticker
 		}
-		if lot := m.client.GetLotSize(sym); lot > 0 {
+		if lot := m.client.GetLotSize(sym); lot.Sign() > 0 {
 			results[i].Lot = lot
 		}
 	}
@@ -224,73 +273,9 @@ func (m *SearchModal) PerformSearch(query string) {
 		m.results = results
 		m.updateTable(quotes)
 		m.updateFooter()
+		// Further price updates arrive through the quote stream
+		m.resultsChanged()
 	})
-
-	if len(results) > 0 {
-		m.startRefresh()
-	}
-}
-
-func (m *SearchModal) stopRefresh() {
-	m.timerMutex.Lock()
-	defer m.timerMutex.Unlock()
-	close(m.refreshStop)
-	m.refreshStop = make(chan struct{})
-}
-
-func (m *SearchModal) startRefresh() {
-	m.timerMutex.Lock()
-	stopChan := m.refreshStop
-	m.timerMutex.Unlock()
-
-	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-stopChan:
-				return
-			case <-ticker.C:
-				m.timerMutex.Lock()
-				if len(m.results) == 0 {
-					m.timerMutex.Unlock()
-					continue
-				}
-				var symbols []string
-				for _, res := range m.results {
-					if res.Symbol != "" {
-						symbols = append(symbols, res.Symbol)
-					} else {
-						symbols = append(symbols, res.Ticker)
-					}
-				}
-				accID := m.accountID
-				m.timerMutex.Unlock()
-
-				quotes, err := m.client.GetSnapshots(accID, symbols)
-				if err == nil {
-					m.app.QueueUpdateDraw(func() {
-						m.updatePrices(quotes)
-					})
-				}
-			}
-		}
-	}()
-}
-
-func (m *SearchModal) updatePrices(quotes map[string]models.Quote) {
-	for i, res := range m.results {
-		q, ok := quotes[res.Ticker]
-		if !ok {
-			q, ok = quotes[res.Symbol]
-		}
-
-		if ok {
-			row := i + 1
-			m.Table.SetCell(row, 4, tview.NewTableCell(q.Last).SetTextColor(tcell.ColorGreen).SetAlign(tview.AlignRight))
-		}
-	}
 }
 
 func (m *SearchModal) updateTableHeader() {
@@ -354,7 +339,7 @@ func (m *SearchModal) updateTable(quotes map[string]models.Quote) {
 			SetExpansion(1))
 
 		// Lot
-		m.Table.SetCell(row, 2, tview.NewTableCell(fmt.Sprintf("%v", res.Lot)).
+		m.Table.SetCell(row, 2, tview.NewTableCell(res.Lot.String()).
 			SetTextColor(tcell.ColorWhite).
 			SetAlign(tview.AlignCenter).
 			SetMaxWidth(8))
@@ -366,51 +351,60 @@ func (m *SearchModal) updateTable(quotes map[string]models.Quote) {
 			SetMaxWidth(8))
 
 		// Price & Change %
-		priceCell := tview.NewTableCell("...").
-			SetTextColor(tcell.ColorGray).
-			SetAlign(tview.AlignRight).
-			SetMaxWidth(10)
-
-		changeCell := tview.NewTableCell("").
-			SetAlign(tview.AlignRight).
-			SetMaxWidth(10)
-
 		q, ok := quotes[res.Ticker]
 		if !ok {
 			q, ok = quotes[res.Symbol]
 		}
-
 		if ok {
-			priceCell.SetText(q.Last).SetTextColor(tcell.ColorGreen)
-
-			// Calculate change
-			if last, err := parseFloat(q.Last); err == nil {
-				if prevClose, err := parseFloat(q.Close); err == nil && prevClose > 0 {
-					change := ((last - prevClose) / prevClose) * 100
-					changeStr := fmt.Sprintf("%.2f%%", change)
-					if change > 0 {
-						changeCell.SetText("+" + changeStr).SetTextColor(tcell.ColorGreen)
-					} else if change < 0 {
-						changeCell.SetText(changeStr).SetTextColor(tcell.ColorRed)
-					} else {
-						changeCell.SetText(changeStr).SetTextColor(tcell.ColorGray)
-					}
+			m.setPriceCells(row, &q)
+		} else {
+			m.setPriceCells(row, nil)
+		}
+	}
+	m.Table.ScrollToBeginning()
+}
+
+// setPriceCells renders the Price and Change % cells of a result row; nil means no quote yet.
+func (m *SearchModal) setPriceCells(row int, q *models.Quote) {
+	priceCell := tview.NewTableCell("...").
+		SetTextColor(tcell.ColorGray).
+		SetAlign(tview.AlignRight).
+		SetMaxWidth(10)
+
+	changeCell := tview.NewTableCell("").
+		SetAlign(tview.AlignRight).
+		SetMaxWidth(10)
+
+	if q != nil {
+		priceCell.SetText(q.Last.String()).SetTextColor(tcell.ColorGreen)
+
+		// Calculate change
+		if q.Last.Known() {
+			last := q.Last.Float64()
+			if prevClose := q.Close.Float64(); prevClose > 0 {
+				change := ((last - prevClose) / prevClose) * 100
+				changeStr := fmt.Sprintf("%.2f%%", change)
+				if change > 0 {
+					changeCell.SetText("+" + changeStr).SetTextColor(tcell.ColorGreen)
+				} else if change < 0 {
+					changeCell.SetText(changeStr).SetTextColor(tcell.ColorRed)
 				} else {
-					changeCell.SetText("N/A").SetTextColor(tcell.ColorGray)
+					changeCell.SetText(changeStr).SetTextColor(tcell.ColorGray)
 				}
+			} else {
+				changeCell.SetText("N/A").SetTextColor(tcell.ColorGray)
 			}
-		} else {
-			changeCell.SetText("...").SetTextColor(tcell.ColorGray)
 		}
-
-		m.Table.SetCell(row, 4, priceCell)
-		m.Table.SetCell(row, 5, changeCell)
+	} else {
+		changeCell.SetText("...").SetTextColor(tcell.ColorGray)
 	}
-	m.Table.ScrollToBeginning()
+
+	m.Table.SetCell(row, 4, priceCell)
+	m.Table.SetCell(row, 5, changeCell)
 }
 
 func (m *SearchModal) updateFooter() {
-	shortcuts := " [yellow]TAB[white] Switch Focus [yellow]UP/DOWN[white] Navigate [yellow]ENTER/A[white] Buy [yellow]P[white] Profile [yellow]ESC[white] Close"
+	shortcuts := " [yellow]TAB[white] Switch Focus [yellow]UP/DOWN[white] Navigate [yellow]ENTER/A[white] Buy [yellow]P[white] Profile [yellow]L[white] Alert [yellow]W[white] Watch [yellow]ESC[white] Close"
 	status := ""
 	if m.searching {
 		status = " | [yellow]Searching...[white]"
@@ -430,7 +424,6 @@ func (m *SearchModal) setupHandlers() {
 			m.app.SetFocus(m.Table)
 			return nil
 		case tcell.KeyEscape:
-			m.stopRefresh()
 			if m.onCancel != nil {
 				m.onCancel()
 			}
@@ -445,7 +438,6 @@ func (m *SearchModal) setupHandlers() {
 			m.app.SetFocus(m.Input)
 			return nil
 		case tcell.KeyEscape:
-			m.stopRefresh()
 			if m.onCancel != nil {
 				m.onCancel()
 			}
@@ -454,7 +446,6 @@ func (m *SearchModal) setupHandlers() {
 			row, _ := m.Table.GetSelection()
 			if row > 0 && row <= len(m.results) {
 				ticker := m.results[row-1].Ticker
-				m.stopRefresh()
 				if m.onSelect != nil {
 					m.onSelect(ticker)
 				}
@@ -465,7 +456,6 @@ func (m *SearchModal) setupHandlers() {
 				row, _ := m.Table.GetSelection()
 				if row > 0 && row <= len(m.results) {
 					ticker := m.results[row-1].Ticker
-					m.stopRefresh()
 					if m.onSelect != nil {
 						m.onSelect(ticker)
 					}
@@ -479,13 +469,38 @@ func (m *SearchModal) setupHandlers() {
 					if symbol == "" {
 						symbol = m.results[row-1].Ticker
 					}
-					m.stopRefresh()
 					if m.onViewProfile != nil {
 						m.onViewProfile(symbol)
 					}
 				}
 				return nil
 			}
+			if event.Rune() == 'l' || event.Rune() == 'L' || event.Rune() == 'д' || event.Rune() == 'Д' {
+				row, _ := m.Table.GetSelection()
+				if row > 0 && row <= len(m.results) {
+					symbol := m.results[row-1].Symbol
+					if symbol == "" {
+						symbol = m.results[row-1].Ticker
+					}
+					if m.onAlert != nil {
+						m.onAlert(symbol)
+					}
+				}
+				return nil
+			}
+			if event.Rune() == 'w' || event.Rune() == 'W' || event.Rune() == 'ц' || event.Rune() == 'Ц' {
+				row, _ := m.Table.GetSelection()
+				if row > 0 && row <= len(m.results) {
+					symbol := m.results[row-1].Symbol
+					if symbol == "" {
+						symbol = m.results[row-1].Ticker
+					}
+					if m.onWatch != nil {
+						m.onWatch(symbol)
+					}
+				}
+				return nil
+			}
 		}
 		return event
 	})
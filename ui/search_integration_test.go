@@ -14,7 +14,7 @@ func TestSearchToOrderTransition(t *testing.T) {
 			return []models.SecurityInfo{{Ticker: "AAPL", Symbol: "AAPL@NASD", Name: "Apple"}}, nil
 		},
 		GetSnapshotsFunc: func(accountID string, symbols []string) (map[string]models.Quote, error) {
-			return map[string]models.Quote{"AAPL": {Last: "150.00"}}, nil
+			return map[string]models.Quote{"AAPL": {Last: models.DecimalOf("150.00")}}, nil
 		},
 		GetAccountsFunc: func() ([]models.AccountInfo, error) {
 			return []models.AccountInfo{{ID: "acc1"}}, nil
@@ -0,0 +1,45 @@
+package ui
+
+import "sync"
+
+// streamSubscription tracks one background stream owned by the UI.
+// Subscribing may block on symbol resolution, so it runs off the UI thread;
+// a generation counter makes sure a subscription that completes after it was
+// cancelled (or replaced) is stopped instead of leaking.
+type streamSubscription struct {
+	mu   sync.Mutex
+	stop func()
+	gen  int
+}
+
+// start cancels the current subscription and runs subscribe in the background.
+func (s *streamSubscription) start(subscribe func() (stop func())) {
+	s.cancel()
+
+	s.mu.Lock()
+	gen := s.gen
+	s.mu.Unlock()
+
+	go func() {
+		stop := subscribe()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if gen != s.gen {
+			stop()
+			return
+		}
+		s.stop = stop
+	}()
+}
+
+// cancel stops the current subscription, if any.
+func (s *streamSubscription) cancel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gen++
+	if s.stop != nil {
+		s.stop()
+		s.stop = nil
+	}
+}
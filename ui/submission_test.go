@@ -1,22 +1,26 @@
 package ui
 
 import (
+	"errors"
 	"finam-terminal/models"
+	"finam-terminal/risk"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 )
 
 func TestSubmitOrder_Success(t *testing.T) {
 	accounts := []models.AccountInfo{{ID: "acc1"}}
 	mockClient := &mockClient{
-		PlaceOrderFunc: func(id string, sym string, side string, qty float64, params *models.OrderParams) (string, error) {
+		PlaceOrderFunc: func(id string, sym string, side string, qty models.Decimal, params *models.OrderParams) (string, error) {
 			if id != "acc1" {
 				return "", fmt.Errorf("wrong account")
 			}
 			if sym != "SBER" {
 				return "", fmt.Errorf("wrong symbol")
 			}
-			if qty != 10 {
+			if qty.Float64() != 10 {
 				return "", fmt.Errorf("wrong qty")
 			}
 			return "ord1", nil
@@ -28,7 +32,7 @@ func TestSubmitOrder_Success(t *testing.T) {
 	// Act
 	err := app.SubmitOrder(OrderSubmission{
 		Instrument: "SBER",
-		Quantity:   10,
+		Quantity:   models.DecimalFromInt(10),
 		Direction:  "Buy",
 		OrderType:  models.OrderTypeMarket,
 	})
@@ -42,7 +46,7 @@ func TestSubmitOrder_Success(t *testing.T) {
 func TestSubmitOrder_Error(t *testing.T) {
 	accounts := []models.AccountInfo{{ID: "acc1"}}
 	mockClient := &mockClient{
-		PlaceOrderFunc: func(id string, sym string, side string, qty float64, params *models.OrderParams) (string, error) {
+		PlaceOrderFunc: func(id string, sym string, side string, qty models.Decimal, params *models.OrderParams) (string, error) {
 			return "", fmt.Errorf("api error")
 		},
 	}
@@ -52,7 +56,7 @@ func TestSubmitOrder_Error(t *testing.T) {
 	// Act
 	err := app.SubmitOrder(OrderSubmission{
 		Instrument: "SBER",
-		Quantity:   10,
+		Quantity:   models.DecimalFromInt(10),
 		Direction:  "Buy",
 		OrderType:  models.OrderTypeMarket,
 	})
@@ -69,12 +73,12 @@ func TestSubmitOrder_Error(t *testing.T) {
 func TestSubmitClosePosition_Success(t *testing.T) {
 	accounts := []models.AccountInfo{{ID: "acc1"}}
 	mockClient := &mockClient{
-		ClosePositionFunc: func(id string, sym string, curQty string, closeQty float64) (string, error) {
+		ClosePositionFunc: func(id string, sym string, curQty, closeQty models.Decimal) (string, error) {
 			// CRITICAL: Verify we receive the full symbol (Ticker@MIC)
 			if sym != "SBER@TQBR" {
 				return "", fmt.Errorf("expected symbol 'SBER@TQBR', got '%s'", sym)
 			}
-			if closeQty != 5 {
+			if closeQty.Float64() != 5 {
 				return "", fmt.Errorf("wrong qty")
 			}
 			return "cls1", nil
@@ -83,13 +87,13 @@ func TestSubmitClosePosition_Success(t *testing.T) {
 	app := NewApp(mockClient, accounts)
 	app.selectedIdx = 0
 	// Setup position with Ticker and full Symbol
-	app.positions["acc1"] = []models.Position{{Ticker: "SBER", Symbol: "SBER@TQBR", Quantity: "10"}}
+	app.positions["acc1"] = []models.Position{{Ticker: "SBER", Symbol: "SBER@TQBR", Quantity: models.DecimalOf("10")}}
 
 	// Mock table selection
 	app.portfolioView.TabbedView.PositionsTable.Select(1, 0)
 
 	// Act
-	err := app.SubmitClosePosition(5)
+	err := app.SubmitClosePosition(models.DecimalFromInt(5))
 
 	// Assert
 	if err != nil {
@@ -100,19 +104,19 @@ func TestSubmitClosePosition_Success(t *testing.T) {
 func TestSubmitClosePosition_Error(t *testing.T) {
 	accounts := []models.AccountInfo{{ID: "acc1"}}
 	mockClient := &mockClient{
-		ClosePositionFunc: func(id string, sym string, curQty string, closeQty float64) (string, error) {
+		ClosePositionFunc: func(id string, sym string, curQty, closeQty models.Decimal) (string, error) {
 			return "", fmt.Errorf("api error")
 		},
 	}
 	app := NewApp(mockClient, accounts)
 	app.selectedIdx = 0
-	app.positions["acc1"] = []models.Position{{Ticker: "SBER", Quantity: "10"}}
+	app.positions["acc1"] = []models.Position{{Ticker: "SBER", Quantity: models.DecimalOf("10")}}
 
 	// Mock table selection
 	app.portfolioView.TabbedView.PositionsTable.Select(1, 0)
 
 	// Act
-	err := app.SubmitClosePosition(5)
+	err := app.SubmitClosePosition(models.DecimalFromInt(5))
 
 	// Assert
 	if err == nil {
@@ -122,3 +126,134 @@ func TestSubmitClosePosition_Error(t *testing.T) {
 		t.Errorf("Expected 'api error', got '%v'", err)
 	}
 }
+
+func TestSubmitOrder_BlockedByRiskChecks(t *testing.T) {
+	accounts := []models.AccountInfo{{ID: "acc1"}}
+	placed := 0
+	mockClient := &mockClient{
+		PlaceOrderFunc: func(id string, sym string, side string, qty models.Decimal, params *models.OrderParams) (string, error) {
+			placed++
+			return "ord1", nil
+		},
+		GetLotSizeFunc: func(ticker string) models.Decimal { return models.DecimalFromInt(10) },
+	}
+	app := NewApp(mockClient, accounts)
+	app.SetRiskLimits(models.RiskLimits{PriceBandPercent: 10, AllowOverride: true})
+	app.positions["acc1"] = []models.Position{{Ticker: "SBER", Symbol: "SBER@TQBR", Quantity: models.DecimalOf("0"), CurrentPrice: models.DecimalOf("285")}}
+
+	sub := OrderSubmission{
+		Instrument: "SBER",
+		Quantity:   models.DecimalFromInt(1),
+		Direction:  "Buy",
+		OrderType:  models.OrderTypeLimit,
+		LimitPrice: models.DecimalFromInt(2850), // an extra zero
+	}
+	err := app.SubmitOrder(sub)
+
+	var rerr *risk.Error
+	if !errors.As(err, &rerr) || rerr.Violations[0].Rule != risk.RulePriceBand {
+		t.Fatalf("Expected a price band violation, got %v", err)
+	}
+	if placed != 0 {
+		t.Fatal("Expected the order not to be sent")
+	}
+
+	// The confirmed override sends the order and counts it
+	sub.OverrideRisk = true
+	if err := app.SubmitOrder(sub); err != nil {
+		t.Fatalf("Expected the overridden order to be sent, got %v", err)
+	}
+	if placed != 1 || app.risk.SentToday("acc1") != 1 {
+		t.Errorf("Expected one order sent and counted, got %d sent, %d counted", placed, app.risk.SentToday("acc1"))
+	}
+}
+
+func TestSubmitOrder_OverrideDisabled(t *testing.T) {
+	accounts := []models.AccountInfo{{ID: "acc1"}}
+	mockClient := &mockClient{
+		PlaceOrderFunc: func(id string, sym string, side string, qty models.Decimal, params *models.OrderParams) (string, error) {
+			t.Error("Order must not be sent")
+			return "", nil
+		},
+	}
+	app := NewApp(mockClient, accounts)
+	app.SetRiskLimits(models.RiskLimits{MaxLots: 5})
+
+	err := app.SubmitOrder(OrderSubmission{
+		Instrument:   "SBER",
+		Quantity:     models.DecimalFromInt(10),
+		Direction:    "Buy",
+		OrderType:    models.OrderTypeMarket,
+		OverrideRisk: true,
+	})
+	var rerr *risk.Error
+	if !errors.As(err, &rerr) || rerr.Overridable {
+		t.Errorf("Expected a non-overridable violation, got %v", err)
+	}
+}
+
+func TestDailyLossLimit_LocksTerminal(t *testing.T) {
+	accounts := []models.AccountInfo{{ID: "acc1"}}
+	mockClient := &mockClient{
+		PlaceOrderFunc: func(id string, sym string, side string, qty models.Decimal, params *models.OrderParams) (string, error) {
+			t.Error("Opening order must not be sent while locked")
+			return "", nil
+		},
+	}
+	app := NewApp(mockClient, accounts)
+	app.SetRiskLimits(models.RiskLimits{DailyLossLimit: 5000, AllowOverride: true})
+
+	app.trackDailyLoss("acc1", models.DecimalOf("-4000"))
+	app.trackDailyLoss("acc1", models.DecimalOf("-5500"))
+
+	if _, locked := app.risk.Locked(); !locked {
+		t.Fatal("Expected a 5 500 loss to lock trading")
+	}
+	if text := app.header.GetText(true); !strings.Contains(text, "TRADING LOCKED") {
+		t.Errorf("header text = %q, want a TRADING LOCKED banner", text)
+	}
+
+	err := app.SubmitOrder(OrderSubmission{
+		Instrument:   "SBER",
+		Quantity:     models.DecimalFromInt(1),
+		Direction:    "Buy",
+		OrderType:    models.OrderTypeMarket,
+		OverrideRisk: true,
+	})
+	var rerr *risk.Error
+	if !errors.As(err, &rerr) || rerr.Overridable {
+		t.Errorf("Expected a non-overridable lock violation, got %v", err)
+	}
+}
+
+func TestCancelAllOrders_AllAccounts(t *testing.T) {
+	accounts := []models.AccountInfo{{ID: "acc1"}, {ID: "acc2"}, {ID: "acc3", LoadError: "blocked"}}
+	var mu sync.Mutex
+	var cancelled []string
+	mockClient := &mockClient{
+		GetActiveOrdersFunc: func(accountID string) ([]models.Order, error) {
+			if accountID == "acc3" {
+				t.Error("Accounts that failed to load must be skipped")
+			}
+			return []models.Order{
+				{ID: accountID + "-1", Status: "Active"},
+				{ID: accountID + "-2", Status: "Partial"},
+				{ID: accountID + "-3", Status: "Filled"},
+			}, nil
+		},
+		CancelOrderFunc: func(accountID, orderID string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			cancelled = append(cancelled, orderID)
+			return nil
+		},
+	}
+	app := NewApp(mockClient, accounts)
+
+	app.cancelAllOrders("Kill switch")
+
+	want := []string{"acc1-1", "acc1-2", "acc2-1", "acc2-2"}
+	if fmt.Sprint(cancelled) != fmt.Sprint(want) {
+		t.Errorf("Cancelled %v, want %v", cancelled, want)
+	}
+}
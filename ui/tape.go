@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"finam-terminal/models"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// tapeMaxRows caps the number of prints kept in the time & sales tape.
+const tapeMaxRows = 200
+
+// startTape subscribes the profile tape to the exchange prints of symbol,
+// replacing any previous subscription.
+func (a *App) startTape(accountID, symbol string) {
+	a.stopTape()
+	a.tapeStream.start(func() func() {
+		return a.client.SubscribeLatestTrades(accountID, symbol, a.onTrades)
+	})
+}
+
+// stopTape cancels the profile tape subscription, if any.
+func (a *App) stopTape() {
+	a.tapeStream.cancel()
+	a.tapeMu.Lock()
+	a.tapePending = nil
+	a.tapeMu.Unlock()
+}
+
+// onTrades receives prints from the stream goroutine and batches them into one redraw.
+func (a *App) onTrades(trades []models.MarketTrade) {
+	a.tapeMu.Lock()
+	a.tapePending = append(a.tapePending, trades...)
+	a.tapeMu.Unlock()
+
+	if !a.tapeQueued.CompareAndSwap(false, true) {
+		return
+	}
+	a.app.QueueUpdateDraw(func() {
+		a.tapeQueued.Store(false)
+
+		a.tapeMu.Lock()
+		batch := a.tapePending
+		a.tapePending = nil
+		a.tapeMu.Unlock()
+
+		if !a.profileOpen {
+			return
+		}
+		var matching []models.MarketTrade
+		for _, t := range batch {
+			if symbolMatches(t.Symbol, a.profileSymbol) {
+				matching = append(matching, t)
+			}
+		}
+		if len(matching) > 0 {
+			a.profilePanel.AddTrades(matching)
+		}
+	})
+}
+
+// AddTrades merges new prints into the tape, newest first, skipping trade IDs already shown.
+func (p *ProfilePanel) AddTrades(trades []models.MarketTrade) {
+	seen := make(map[string]bool, len(p.tape))
+	for _, t := range p.tape {
+		if t.ID != "" {
+			seen[t.ID] = true
+		}
+	}
+
+	var fresh []models.MarketTrade
+	for _, t := range trades {
+		if t.ID != "" {
+			if seen[t.ID] {
+				continue
+			}
+			seen[t.ID] = true
+		}
+		fresh = append(fresh, t)
+	}
+	if len(fresh) == 0 {
+		return
+	}
+
+	merged := make([]models.MarketTrade, 0, len(fresh)+len(p.tape))
+	merged = append(merged, fresh...)
+	merged = append(merged, p.tape...)
+	// Batches arrive oldest first; keep the tape ordered newest first
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp.After(merged[j].Timestamp)
+	})
+	if len(merged) > tapeMaxRows {
+		merged = merged[:tapeMaxRows]
+	}
+	p.tape = merged
+	p.renderTape()
+}
+
+// SetBlockFilter sets the block-size threshold in lots; prints of at least that size are highlighted.
+func (p *ProfilePanel) SetBlockFilter(lots float64) {
+	p.blockLots = lots
+	p.renderTape()
+}
+
+// BlockFilter returns the current block-size threshold in lots (0 = off).
+func (p *ProfilePanel) BlockFilter() float64 {
+	return p.blockLots
+}
+
+// renderTape renders the time & sales column.
+func (p *ProfilePanel) renderTape() {
+	title := " Time & Sales "
+	if p.blockLots > 0 {
+		title = fmt.Sprintf(" Time & Sales [≥%s lots] ", strconv.FormatFloat(p.blockLots, 'f', -1, 64))
+	}
+	p.TapeView.SetTitle(title)
+	p.TapeView.SetText(renderTapeText(p.tape, p.instrumentLotSize(), p.instrumentDecimals(), p.blockLots))
+	p.TapeView.ScrollToBeginning()
+}
+
+// renderTapeText renders prints as time, price, size in lots and aggressor side.
+// Buyer-initiated prints are green, seller-initiated red; prints of at least
+// blockLots lots are highlighted.
+func renderTapeText(trades []models.MarketTrade, lotSize models.Decimal, decimals int32, blockLots float64) string {
+	if len(trades) == 0 {
+		return "[gray]No trades yet"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[gray]%-8s %10s %8s %4s[-]\n", "Time", "Price", "Lots", "Side")
+	for _, t := range trades {
+		lots := t.Size
+		if lotSize.Sign() > 0 {
+			lots = t.Size.Div(lotSize, 8).Trim()
+		}
+
+		color := "gray"
+		side := "—"
+		switch t.Side {
+		case "Buy":
+			color, side = "green", "B"
+		case "Sell":
+			color, side = "red", "S"
+		}
+		style := color
+		if blockLots > 0 && lots.Float64() >= blockLots {
+			style = color + ":darkslategray:b"
+		}
+
+		fmt.Fprintf(&sb, "[%s]%-8s %10s %8s %4s[-:-:-]\n",
+			style,
+			t.Timestamp.Format("15:04:05"),
+			formatBookPrice(t.Price, decimals),
+			lots.String(),
+			side)
+	}
+	return sb.String()
+}
+
+// ShowBlockFilterInput opens a prompt for the tape's block-size highlight threshold.
+func (a *App) ShowBlockFilterInput() {
+	input := tview.NewInputField().
+		SetLabel(" Lots ≥ ").
+		SetFieldWidth(12).
+		SetAcceptanceFunc(tview.InputFieldFloat)
+	if cur := a.profilePanel.BlockFilter(); cur > 0 {
+		input.SetText(strconv.FormatFloat(cur, 'f', -1, 64))
+	}
+	input.SetBorder(true).SetTitle(" Block Filter (empty/0 = off) ")
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			text := strings.TrimSpace(input.GetText())
+			lots := 0.0
+			if text != "" {
+				v, err := parseFloat(text)
+				if err != nil || v < 0 {
+					a.SetStatus("Invalid block size", StatusError)
+					return
+				}
+				lots = v
+			}
+			a.profilePanel.SetBlockFilter(lots)
+		}
+		a.pages.RemovePage("block_filter")
+		a.app.SetFocus(a.profilePanel.ChartView)
+	})
+
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(input, 3, 1, true).
+			AddItem(nil, 0, 1, false), 40, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	a.pages.AddPage("block_filter", flex, true, true)
+	a.app.SetFocus(input)
+}
+
+// IsBlockFilterOpen returns true if the block filter prompt is currently shown.
+func (a *App) IsBlockFilterOpen() bool {
+	name, _ := a.pages.GetFrontPage()
+	return name == "block_filter"
+}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"finam-terminal/models"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+func TestRenderTapeText_SidesAndBlocks(t *testing.T) {
+	ts := time.Date(2026, 1, 15, 10, 30, 5, 0, time.Local)
+	trades := []models.MarketTrade{
+		{ID: "2", Price: models.DecimalOf("250.10"), Size: models.DecimalFromInt(500), Side: "Sell", Timestamp: ts},
+		{ID: "1", Price: models.DecimalOf("250.20"), Size: models.DecimalFromInt(20), Side: "Buy", Timestamp: ts},
+	}
+
+	text := renderTapeText(trades, models.DecimalFromInt(10), 2, 50)
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header + 2 prints, got %d lines:\n%s", len(lines), text)
+	}
+
+	// 500 shares / lot 10 = 50 lots: a block, highlighted red print
+	if !strings.HasPrefix(lines[1], "[red:darkslategray:b]10:30:05") || !strings.Contains(lines[1], "250.10") || !strings.Contains(lines[1], " 50 ") {
+		t.Errorf("Expected highlighted red block print, got %q", lines[1])
+	}
+	// 20 shares = 2 lots: a regular green print
+	if !strings.HasPrefix(lines[2], "[green]") || !strings.Contains(lines[2], " 2 ") {
+		t.Errorf("Expected regular green print, got %q", lines[2])
+	}
+}
+
+func TestProfilePanel_AddTrades_DedupAndOrder(t *testing.T) {
+	panel := NewProfilePanel(tview.NewApplication())
+	base := time.Date(2026, 1, 15, 10, 0, 0, 0, time.Local)
+
+	panel.AddTrades([]models.MarketTrade{
+		{ID: "1", Price: models.DecimalOf("100"), Size: models.DecimalFromInt(1), Side: "Buy", Timestamp: base},
+		{ID: "2", Price: models.DecimalOf("101"), Size: models.DecimalFromInt(1), Side: "Buy", Timestamp: base.Add(time.Second)},
+	})
+	// Reconnect resends the snapshot plus one new print
+	panel.AddTrades([]models.MarketTrade{
+		{ID: "2", Price: models.DecimalOf("101"), Size: models.DecimalFromInt(1), Side: "Buy", Timestamp: base.Add(time.Second)},
+		{ID: "3", Price: models.DecimalOf("102"), Size: models.DecimalFromInt(1), Side: "Sell", Timestamp: base.Add(2 * time.Second)},
+	})
+
+	if len(panel.tape) != 3 {
+		t.Fatalf("Expected 3 unique prints, got %d", len(panel.tape))
+	}
+	for i, id := range []string{"3", "2", "1"} {
+		if panel.tape[i].ID != id {
+			t.Errorf("Position %d: expected trade %s, got %s", i, id, panel.tape[i].ID)
+		}
+	}
+}
+
+func TestProfilePanel_TapeCapped(t *testing.T) {
+	panel := NewProfilePanel(tview.NewApplication())
+	base := time.Date(2026, 1, 15, 10, 0, 0, 0, time.Local)
+
+	var trades []models.MarketTrade
+	for i := 0; i < tapeMaxRows+50; i++ {
+		trades = append(trades, models.MarketTrade{ID: fmt.Sprintf("T%d", i), Size: models.DecimalFromInt(1), Timestamp: base.Add(time.Duration(i) * time.Second)})
+	}
+	panel.AddTrades(trades)
+
+	if len(panel.tape) != tapeMaxRows {
+		t.Errorf("Expected tape capped at %d, got %d", tapeMaxRows, len(panel.tape))
+	}
+	if !panel.tape[0].Timestamp.Equal(base.Add(time.Duration(tapeMaxRows+49) * time.Second)) {
+		t.Errorf("Expected newest print first, got %v", panel.tape[0].Timestamp)
+	}
+}
+
+func TestProfilePanel_BlockFilterTitle(t *testing.T) {
+	panel := NewProfilePanel(tview.NewApplication())
+	panel.SetBlockFilter(100)
+
+	if got := panel.TapeView.GetTitle(); !strings.Contains(got, "≥100 lots") {
+		t.Errorf("Expected block filter in title, got %q", got)
+	}
+	if panel.BlockFilter() != 100 {
+		t.Errorf("Expected block filter 100, got %v", panel.BlockFilter())
+	}
+}
@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"finam-terminal/export"
+	"finam-terminal/models"
+	"finam-terminal/tradestore"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// taxHistoryYears is how many years before the tax year the trade history starts when
+// the account opening is unknown.
+const taxHistoryYears = 3
+
+// taxFormats are the formats of the tax report, the workbook first.
+var taxFormats = []string{export.FormatXLSX, export.FormatJSON, export.FormatCSV}
+
+// ShowTaxForm asks for the year, the format and the file of the tax report of the
+// selected account.
+func (a *App) ShowTaxForm() {
+	if a.selectedIdx < 0 || a.selectedIdx >= len(a.accounts) || a.accounts[a.selectedIdx].LoadError != "" {
+		a.SetStatus("No account for the tax report", StatusError)
+		return
+	}
+	account := a.accounts[a.selectedIdx]
+	returnFocus := a.app.GetFocus()
+
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle(" Tax Report ").SetTitleAlign(tview.AlignCenter)
+	form.SetBackgroundColor(tcell.ColorBlack)
+	form.SetButtonBackgroundColor(tcell.ColorDarkBlue).
+		SetButtonTextColor(tcell.ColorWhite).
+		SetLabelColor(tcell.ColorYellow).
+		SetFieldBackgroundColor(tcell.ColorWhite).
+		SetFieldTextColor(tcell.ColorBlack)
+
+	lastYear := time.Now().Year() - 1
+	path := tview.NewInputField().SetLabel("File:").SetFieldWidth(48).
+		SetText(filepath.Join(a.exportDir, taxFileName(lastYear, taxFormats[0])))
+	year := tview.NewInputField().SetLabel("Year:").SetFieldWidth(6).
+		SetText(strconv.Itoa(lastYear)).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	year.SetChangedFunc(func(text string) {
+		// Keep a default file name in step with the year
+		if y, err := strconv.Atoi(text); err == nil {
+			dir, name := filepath.Split(path.GetText())
+			if strings.HasPrefix(name, "tax-") {
+				path.SetText(filepath.Join(dir, taxFileName(y, strings.TrimPrefix(filepath.Ext(name), "."))))
+			}
+		}
+	})
+	form.AddFormItem(year)
+	formats := make([]string, len(taxFormats))
+	for i, f := range taxFormats {
+		formats[i] = strings.ToUpper(f)
+	}
+	form.AddDropDown("Format:", formats, 0, func(option string, _ int) {
+		text := path.GetText()
+		path.SetText(strings.TrimSuffix(text, filepath.Ext(text)) + "." + strings.ToLower(option))
+	})
+	form.AddFormItem(path)
+
+	closeForm := func() {
+		a.pages.RemovePage("tax_report")
+		a.app.SetFocus(returnFocus)
+	}
+	form.AddButton("Export", func() {
+		y, err := strconv.Atoi(year.GetText())
+		if err != nil || y < 2000 || y > time.Now().Year() {
+			a.SetStatus("Enter a past or the current year", StatusError)
+			return
+		}
+		_, format := form.GetFormItemByLabel("Format:").(*tview.DropDown).GetCurrentOption()
+		file := strings.TrimSpace(path.GetText())
+		if file == "" {
+			a.SetStatus("Enter a file name", StatusError)
+			return
+		}
+		closeForm()
+		a.taxReportAsync(account, y, strings.ToLower(format), file)
+	})
+	form.AddButton("Cancel", closeForm)
+	form.SetCancelFunc(closeForm)
+
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(form, 11, 1, true).
+			AddItem(nil, 0, 1, false), 64, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	a.pages.AddPage("tax_report", flex, true, true)
+	a.app.SetFocus(form)
+}
+
+// IsTaxFormOpen returns true if the tax report form is currently shown.
+func (a *App) IsTaxFormOpen() bool {
+	name, _ := a.pages.GetFrontPage()
+	return name == "tax_report"
+}
+
+// taxFileName returns the default file name of the tax report of year.
+func taxFileName(year int, format string) string {
+	return fmt.Sprintf("tax-%d.%s", year, format)
+}
+
+// taxReportAsync builds the tax report of year for account and writes it to path in the
+// background.
+func (a *App) taxReportAsync(account models.AccountInfo, year int, format, path string) {
+	a.SetStatus(fmt.Sprintf("Building the %d tax report...", year), StatusLoading)
+	go func() {
+		report, err := a.taxReport(account, year)
+		if err == nil {
+			err = writeTaxReport(path, format, export.TaxReport(report))
+		}
+		if err != nil {
+			log.Printf("[ERROR] Tax report %d for %s failed: %v", year, account.ID, err)
+			a.SetStatus(fmt.Sprintf("Tax report failed: %v", err), StatusError)
+			return
+		}
+		log.Printf("[INFO] Tax report %d for %s saved to %s", year, account.ID, path)
+		status := fmt.Sprintf("Tax report %d saved to %s: tax %s, due %s", year, path,
+			formatNumber(report.Tax.Float64(), 0), formatNumber(report.Due.Float64(), 0))
+		if report.ForeignPayments > 0 {
+			status += fmt.Sprintf(" (%d foreign payments not converted)", report.ForeignPayments)
+		}
+		a.SetStatus(status, StatusSuccess)
+	}()
+}
+
+// taxReport computes the tax report of year from the whole trade history of account,
+// synced into the trade store from the account opening, and its cash transactions. It
+// blocks on the API, so it must not run on the UI thread.
+func (a *App) taxReport(account models.AccountInfo, year int) (models.TaxYear, error) {
+	to := time.Now()
+	from := time.Date(year-taxHistoryYears, 1, 1, 0, 0, 0, 0, time.Local)
+	if !account.OpenDate.IsZero() && account.OpenDate.Year() > 1970 {
+		from = account.OpenDate
+	}
+	if _, err := a.tradeStore.Sync(account.ID, from, to, a.client.GetTradeHistoryRange); err != nil {
+		return models.TaxYear{}, err
+	}
+	trades := a.tradeStore.Trades(account.ID, tradestore.Filter{})
+	txs, err := a.client.GetTransactions(account.ID, from, to)
+	if err != nil {
+		return models.TaxYear{}, err
+	}
+
+	a.dataMutex.RLock()
+	positions, ok := a.positions[account.ID]
+	a.dataMutex.RUnlock()
+	if !ok {
+		if _, positions, err = a.client.GetAccountDetails(account.ID); err != nil {
+			return models.TaxYear{}, err
+		}
+	}
+	return models.ComputeTax(trades, models.OpeningLots(positions, trades), txs, year), nil
+}
+
+// writeTaxReport writes the tables of the tax report to path. CSV holds one table, so
+// each goes to a file of its own named after it, e.g. "tax-2025-closes.csv".
+func writeTaxReport(path, format string, tables []*export.Table) error {
+	if format != export.FormatCSV {
+		return saveExport(path, func(w io.Writer) error { return export.WriteBook(w, tables, format) })
+	}
+	stem := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, t := range tables {
+		if err := writeExport(stem+"-"+strings.ToLower(t.Name)+".csv", format, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"finam-terminal/export"
+	"finam-terminal/models"
+)
+
+func TestTaxReport_FromAccountOpening(t *testing.T) {
+	opened := time.Date(2023, 5, 1, 0, 0, 0, 0, time.Local)
+	buy := time.Date(2024, 3, 1, 11, 0, 0, 0, time.Local)
+	var historyFrom, txFrom time.Time
+	client := &mockClient{
+		GetTradeHistoryRangeFunc: func(accountID string, from, to time.Time) ([]models.Trade, error) {
+			if historyFrom.IsZero() || from.Before(historyFrom) {
+				historyFrom = from
+			}
+			var trades []models.Trade
+			for _, tr := range []models.Trade{
+				{ID: "B1", Symbol: "SBER@MISX", Side: "Buy", Price: models.DecimalOf("250"), Quantity: models.DecimalOf("10"), Timestamp: buy},
+				{ID: "S1", Symbol: "SBER@MISX", Side: "Sell", Price: models.DecimalOf("300"), Quantity: models.DecimalOf("10"), Timestamp: buy.AddDate(1, 0, 0)},
+			} {
+				if !tr.Timestamp.Before(from) && tr.Timestamp.Before(to) {
+					trades = append(trades, tr)
+				}
+			}
+			return trades, nil
+		},
+		GetTransactionsFunc: func(accountID string, from, to time.Time) ([]models.Transaction, error) {
+			txFrom = from
+			return []models.Transaction{
+				{Category: models.TxCoupon, Symbol: "SU26238", Amount: models.DecimalOf("35.4"), Currency: "RUB", Timestamp: buy.AddDate(1, 1, 0)},
+			}, nil
+		},
+	}
+	account := models.AccountInfo{ID: "acc1", OpenDate: opened}
+	app := NewApp(client, []models.AccountInfo{account})
+	app.positions["acc1"] = nil
+
+	report, err := app.taxReport(account, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !historyFrom.Equal(opened) || !txFrom.Equal(opened) {
+		t.Errorf("Expected the history from the account opening, got trades from %v and transactions from %v", historyFrom, txFrom)
+	}
+	if len(report.Closes) != 1 || report.Closes[0].Buy.TradeID != "B1" || report.TradingBase.String() != "500" || report.Coupons.String() != "35.4" {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	path := filepath.Join(t.TempDir(), "tax-2025.csv")
+	if err := writeTaxReport(path, export.FormatCSV, export.TaxReport(report)); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"tax-2025-summary.csv", "tax-2025-closes.csv", "tax-2025-income.csv"} {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(path), name)); err != nil {
+			t.Errorf("Expected %s to be written: %v", name, err)
+		}
+	}
+}
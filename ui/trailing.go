@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"finam-terminal/models"
+	"finam-terminal/trailing"
+)
+
+// SetTrailingStore loads the trailing stops saved in path and keeps saving them there.
+// Without a store, trailing stops last until the terminal exits.
+func (a *App) SetTrailingStore(path string) error {
+	m := trailing.NewManager(path)
+	if err := m.Load(); err != nil {
+		return err
+	}
+	a.trailing = m
+	if n := len(m.Stops("")); n > 0 {
+		log.Printf("[INFO] Resumed %d trailing stops from %s", n, path)
+	}
+	return nil
+}
+
+// placeTrailingStop places the initial stop order of a trailing stop, trailing the last
+// price, and hands it to the trailing manager. Stop prices are snapped to the instrument's
+// price step when it is known.
+func (a *App) placeTrailingStop(accountID string, sub OrderSubmission) (string, error) {
+	last := a.lastPrice(accountID, sub.Instrument)
+	if last.Sign() <= 0 {
+		return "", fmt.Errorf("no last price for %s to trail", sub.Instrument)
+	}
+
+	var tick models.Decimal
+	if details, err := a.client.GetAssetInfo(accountID, sub.Instrument); err != nil {
+		log.Printf("[WARN] Price step of %s unknown, trailing stop prices are not snapped: %v", sub.Instrument, err)
+	} else if details != nil {
+		tick = details.PriceStep()
+	}
+
+	stop := trailing.Stop{
+		AccountID: accountID,
+		Symbol:    sub.Instrument,
+		Side:      sub.Direction,
+		Quantity:  sub.Quantity,
+		Distance:  sub.TrailDistance,
+		Percent:   sub.TrailPercent,
+		Step:      sub.TrailStep,
+		Tick:      tick,
+		Extreme:   last,
+	}
+	if !stop.Step.Known() {
+		stop.Step = models.Decimal{}
+	}
+	stop.StopPrice = stop.StopFor(last)
+	if stop.StopPrice.Sign() <= 0 {
+		return "", fmt.Errorf("trail %s is wider than the price %s", stop.Trail(), last)
+	}
+
+	id, err := a.client.PlaceOrder(accountID, sub.Instrument, sub.Direction, sub.Quantity, &models.OrderParams{
+		OrderType: models.OrderTypeStop,
+		StopPrice: stop.StopPrice,
+	})
+	if err != nil {
+		return "", err
+	}
+	stop.OrderID = id
+
+	stop, err = a.trailing.Add(stop)
+	if err != nil {
+		log.Printf("[ERROR] Trailing stop on %s will not survive a restart: %v", sub.Instrument, err)
+	}
+	log.Printf("[INFO] Trailing stop %s: %s %s trail %s, stop %s (order %s)",
+		stop.ID, stop.Side, stop.Symbol, stop.Trail(), stop.StopPrice, id)
+	return id, nil
+}
+
+// trailQuotes feeds streamed last prices to the trailing stops and moves the stop orders
+// that fell behind. Must be called on the UI thread.
+func (a *App) trailQuotes(batch map[string]models.Quote) {
+	observed := make(map[string]bool)
+	for _, s := range a.trailing.Stops("") {
+		if observed[s.Symbol] {
+			continue
+		}
+		observed[s.Symbol] = true
+		for _, q := range batch {
+			if !symbolMatches(q.Symbol, s.Symbol) {
+				continue
+			}
+			if q.Last.Known() {
+				for _, mv := range a.trailing.Observe(s.Symbol, q.Last) {
+					go a.moveTrailingStop(mv)
+				}
+			}
+			break
+		}
+	}
+}
+
+// moveTrailingStop replaces the stop order of a trailing stop with one at the new price.
+// The old order stays working until the replacement is placed. When the broker keeps
+// refusing the move, the stop stops trailing and the user is told where its order was left.
+func (a *App) moveTrailingStop(mv trailing.Move) {
+	s := mv.Stop
+	id, err := a.client.ModifyOrder(s.AccountID, s.OrderID, s.Symbol, s.Side, s.Quantity, &models.OrderParams{
+		OrderType: models.OrderTypeStop,
+		StopPrice: mv.Price,
+	})
+	if err != nil {
+		log.Printf("[ERROR] Failed to move trailing stop %s on %s to %s: %v", s.ID, s.Symbol, mv.Price, err)
+		dropped, serr := a.trailing.MoveFailed(s.ID)
+		if serr != nil {
+			log.Printf("[ERROR] %v", serr)
+		}
+		if !dropped {
+			a.SetStatus(fmt.Sprintf("Trailing stop %s not moved: %s", s.Symbol, extractUserMessage(err)), StatusError)
+			return
+		}
+		log.Printf("[WARN] Trailing stop %s on %s stopped after repeated failures, order %s left at %s",
+			s.ID, s.Symbol, s.OrderID, s.StopPrice)
+		msg := fmt.Sprintf("Trailing stop %s stopped trailing: %s\n\nIts stop order stays at %s.",
+			s.Symbol, extractUserMessage(err), s.StopPrice)
+		a.SetStatus(fmt.Sprintf("Trailing stop %s stopped", s.Symbol), StatusError)
+		a.app.QueueUpdateDraw(func() {
+			a.ShowError(msg)
+		})
+		return
+	}
+	if err := a.trailing.Moved(s.ID, id, mv.Price); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+	log.Printf("[INFO] Trailing stop %s on %s moved to %s (order %s)", s.ID, s.Symbol, mv.Price, id)
+	a.SetStatus(fmt.Sprintf("Trailing stop %s moved to %s", s.Symbol, mv.Price), StatusInfo)
+}
+
+// syncTrailingStops forgets the trailing stops of accountID whose stop order was filled,
+// cancelled or rejected. Orders missing from the list are kept: the list may predate a move.
+func (a *App) syncTrailingStops(accountID string) {
+	stops := a.trailing.Stops(accountID)
+	if len(stops) == 0 {
+		return
+	}
+
+	a.dataMutex.RLock()
+	status := make(map[string]string, len(a.activeOrders[accountID]))
+	for _, o := range a.activeOrders[accountID] {
+		status[o.ID] = o.Status
+	}
+	a.dataMutex.RUnlock()
+
+	for _, s := range stops {
+		st, ok := status[s.OrderID]
+		if !ok || st == "" || isOrderCancellable(st) {
+			continue
+		}
+		if err := a.trailing.Remove(s.ID); err != nil {
+			continue // Being moved; the order it is moved to decides
+		}
+		log.Printf("[INFO] Trailing stop %s on %s ended: order %s %s", s.ID, s.Symbol, s.OrderID, st)
+	}
+}
+
+// releaseTrailingStop stops trailing the order orderID before it is cancelled by the user.
+func (a *App) releaseTrailingStop(orderID string) error {
+	s, ok := a.trailing.ByOrderID(orderID)
+	if !ok {
+		return nil
+	}
+	if err := a.trailing.Remove(s.ID); err != nil {
+		if errors.Is(err, trailing.ErrMoving) {
+			return fmt.Errorf("trailing stop on %s is being moved, try again", s.Symbol)
+		}
+		log.Printf("[ERROR] %v", err)
+	}
+	return nil
+}
+
+// lastPrice returns the last price of symbol from the loaded positions, or from a snapshot
+// when the account holds no position in it.
+func (a *App) lastPrice(accountID, symbol string) models.Decimal {
+	var price models.Decimal
+	a.dataMutex.RLock()
+	for _, pos := range a.positions[accountID] {
+		if pos.Symbol == symbol || pos.Ticker == symbol {
+			price = pos.CurrentPrice
+			break
+		}
+	}
+	if q := a.quotes[accountID][symbol]; price.Sign() <= 0 && q != nil {
+		price = q.Last
+	}
+	a.dataMutex.RUnlock()
+	if price.Sign() > 0 {
+		return price
+	}
+
+	if snapshots, err := a.client.GetSnapshots(accountID, []string{symbol}); err == nil {
+		if q, ok := snapshots[symbol]; ok && q.Last.Sign() > 0 {
+			return q.Last
+		}
+	}
+	return models.Decimal{}
+}
@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"finam-terminal/models"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTrailingStop_PlacesAndMovesStopOrder(t *testing.T) {
+	var placed *models.OrderParams
+	moved := make(chan *models.OrderParams, 1)
+	mockClient := &mockClient{
+		PlaceOrderFunc: func(id, sym, side string, qty models.Decimal, p *models.OrderParams) (string, error) {
+			placed = p
+			return "ord1", nil
+		},
+		ModifyOrderFunc: func(accountID, orderID, symbol, side string, qty models.Decimal, p *models.OrderParams) (string, error) {
+			if orderID != "ord1" {
+				t.Errorf("Expected ord1 to be replaced, got %s", orderID)
+			}
+			moved <- p
+			return "ord2", nil
+		},
+	}
+	app := NewApp(mockClient, []models.AccountInfo{{ID: "acc1"}})
+	app.positions["acc1"] = []models.Position{{Symbol: "SBER@MISX", Ticker: "SBER", Quantity: models.DecimalOf("100"), CurrentPrice: models.DecimalOf("300")}}
+
+	err := app.SubmitOrder(OrderSubmission{
+		Instrument:    "SBER",
+		Quantity:      models.DecimalFromInt(10),
+		Direction:     "Sell",
+		OrderType:     models.OrderTypeTrailing,
+		TrailDistance: models.DecimalFromInt(5),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if placed == nil || placed.OrderType != models.OrderTypeStop || placed.StopPrice.Float64() != 295 {
+		t.Fatalf("Expected a Stop-Loss at 295, got %+v", placed)
+	}
+
+	// The Orders tab marks the stop order as trailing
+	app.activeOrders["acc1"] = []models.Order{{ID: "ord1", Symbol: "SBER@MISX", Type: "Stop", Status: "Active", StopPrice: models.DecimalOf("295")}}
+	updateOrdersTable(app)
+	if got := app.portfolioView.TabbedView.OrdersTable.GetCell(1, 2).Text; got != models.OrderTypeTrailing {
+		t.Errorf("Expected type %q, got %q", models.OrderTypeTrailing, got)
+	}
+
+	app.trailQuotes(map[string]models.Quote{"SBER@MISX": {Symbol: "SBER@MISX", Last: models.DecimalOf("310")}})
+	select {
+	case p := <-moved:
+		if p.StopPrice.Float64() != 305 {
+			t.Errorf("Expected the stop moved to 305, got %v", p.StopPrice)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the stop order to be moved")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if s, ok := app.trailing.ByOrderID("ord2"); ok && s.StopPrice.Equal(models.DecimalFromInt(305)) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the trailing stop to follow the replacement order")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTrailingStop_EndsWithItsOrder(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	if err := app.SetTrailingStore(filepath.Join(t.TempDir(), "trailing-stops.json")); err != nil {
+		t.Fatal(err)
+	}
+	app.positions["acc1"] = []models.Position{{Symbol: "SBER@MISX", Ticker: "SBER", CurrentPrice: models.DecimalOf("300")}}
+	if _, err := app.placeTrailingStop("acc1", OrderSubmission{Instrument: "SBER", Quantity: models.DecimalFromInt(1), Direction: "Sell", TrailPercent: models.DecimalFromInt(2)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Still working: kept
+	app.setOrders("acc1", []models.Order{{ID: "tx-123", Status: "Active"}})
+	if len(app.trailing.Stops("acc1")) != 1 {
+		t.Fatal("Expected the trailing stop to be kept while its order works")
+	}
+
+	app.setOrders("acc1", []models.Order{{ID: "tx-123", Status: "Filled"}})
+	if len(app.trailing.Stops("acc1")) != 0 {
+		t.Error("Expected the trailing stop to end with its filled order")
+	}
+}
+
+func TestTrailingStop_CancelReleasesStop(t *testing.T) {
+	var cancelled string
+	mockClient := &mockClient{
+		CancelOrderFunc: func(accountID, orderID string) error {
+			cancelled = orderID
+			return nil
+		},
+	}
+	app := NewApp(mockClient, []models.AccountInfo{{ID: "acc1"}})
+	app.positions["acc1"] = []models.Position{{Symbol: "SBER@MISX", Ticker: "SBER", CurrentPrice: models.DecimalOf("300")}}
+	if _, err := app.placeTrailingStop("acc1", OrderSubmission{Instrument: "SBER", Quantity: models.DecimalFromInt(1), Direction: "Sell", TrailDistance: models.DecimalFromInt(5)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.cancelOrder("acc1", "tx-123"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cancelled != "tx-123" || len(app.trailing.Stops("")) != 0 {
+		t.Errorf("Expected the order cancelled and the trailing stop released, got %q and %d stops",
+			cancelled, len(app.trailing.Stops("")))
+	}
+}
+
+func TestTrailingStop_SnapsToPriceStep(t *testing.T) {
+	var placed *models.OrderParams
+	mockClient := &mockClient{
+		GetAssetInfoFunc: func(accountID, symbol string) (*models.AssetDetails, error) {
+			return &models.AssetDetails{Decimals: 2, MinStep: 5}, nil
+		},
+		PlaceOrderFunc: func(id, sym, side string, qty models.Decimal, p *models.OrderParams) (string, error) {
+			placed = p
+			return "ord1", nil
+		},
+	}
+	app := NewApp(mockClient, []models.AccountInfo{{ID: "acc1"}})
+	app.positions["acc1"] = []models.Position{{Symbol: "SBER@MISX", Ticker: "SBER", CurrentPrice: models.DecimalOf("301.37")}}
+
+	sub := OrderSubmission{Instrument: "SBER", Quantity: models.DecimalFromInt(1), Direction: "Sell", TrailPercent: models.DecimalOf("1.5")}
+	if _, err := app.placeTrailingStop("acc1", sub); err != nil {
+		t.Fatal(err)
+	}
+	// 296.84945 is raised to the 0.05 tick, toward the market
+	if placed == nil || placed.StopPrice.String() != "296.85" {
+		t.Errorf("Expected a stop at 296.85, got %+v", placed)
+	}
+}
@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"finam-terminal/models"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// transactionsPage is how many days of cash transactions the Transactions tab loads at
+// first and adds each time older ones are requested.
+const transactionsPage = 90
+
+// txPeriods are the periods the Transactions tab totals by, in the order P cycles them.
+var txPeriods = []string{models.PeriodMonth, models.PeriodQuarter, models.PeriodYear}
+
+// loadTransactionsAsync loads the cash transactions of the last txDays days.
+func (a *App) loadTransactionsAsync(accountID string) {
+	for _, acc := range a.accounts {
+		if acc.ID == accountID && acc.LoadError != "" {
+			return
+		}
+	}
+	a.SetStatus("Loading Transactions...", StatusLoading)
+	to := time.Now()
+	from := to.AddDate(0, 0, -a.txDays)
+	go func() {
+		txs, err := a.client.GetTransactions(accountID, from, to)
+		if err != nil {
+			log.Printf("[WARN] Failed to load transactions for %s: %v", accountID, err)
+			a.SetStatus("Error loading transactions", StatusError)
+			return
+		}
+
+		a.dataMutex.Lock()
+		a.transactions[accountID] = txs
+		a.dataMutex.Unlock()
+
+		a.app.QueueUpdateDraw(func() {
+			if a.selectedIdx < len(a.accounts) && a.accounts[a.selectedIdx].ID == accountID {
+				updateTransactionsTable(a)
+				a.SetStatus("Transactions updated", StatusSuccess)
+			}
+		})
+	}()
+}
+
+// loadOlderTransactions extends the Transactions tab by another transactionsPage days back.
+func (a *App) loadOlderTransactions() {
+	if a.selectedIdx < 0 || a.selectedIdx >= len(a.accounts) {
+		return
+	}
+	a.txDays += transactionsPage
+	a.loadTransactionsAsync(a.accounts[a.selectedIdx].ID)
+}
+
+// toggleTxTotals switches the Transactions tab between the entries and their totals.
+func (a *App) toggleTxTotals() {
+	a.txTotals = !a.txTotals
+	updateTransactionsTable(a)
+}
+
+// cycleTxPeriod totals the Transactions tab by the next period.
+func (a *App) cycleTxPeriod() {
+	for i, p := range txPeriods {
+		if p == a.txPeriod {
+			a.txPeriod = txPeriods[(i+1)%len(txPeriods)]
+			break
+		}
+	}
+	a.txTotals = true
+	updateTransactionsTable(a)
+}
+
+// updateTransactionsTable shows the cash transactions of the selected account grouped by
+// category, newest first, or their totals per period and category.
+func updateTransactionsTable(app *App) {
+	table := app.portfolioView.TabbedView.TransactionsTable
+	table.Clear()
+
+	var headers []string
+	if app.txTotals {
+		table.SetTitle(fmt.Sprintf(" Transactions: totals by %s, %d days ", app.txPeriod, app.txDays))
+		headers = []string{"Period", "Category", "Amount", "Currency", "Count"}
+	} else {
+		table.SetTitle(fmt.Sprintf(" Transactions: %d days ", app.txDays))
+		headers = []string{"Category", "Date", "Description", "Instrument", "Amount", "Currency"}
+	}
+	headerStyle := tcell.StyleDefault.
+		Background(tcell.ColorDarkBlue).
+		Foreground(tcell.ColorWhite).
+		Bold(true)
+	for i, h := range headers {
+		table.SetCell(0, i, tview.NewTableCell(h).SetStyle(headerStyle).SetExpansion(1))
+	}
+
+	if app.selectedIdx < 0 || app.selectedIdx >= len(app.accounts) {
+		return
+	}
+	app.dataMutex.RLock()
+	txs := app.transactions[app.accounts[app.selectedIdx].ID]
+	app.dataMutex.RUnlock()
+	if len(txs) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell("No transactions").
+			SetTextColor(tcell.ColorGray).SetSelectable(false))
+		return
+	}
+
+	row := 1
+	if app.txTotals {
+		for _, t := range models.SummarizeTransactions(txs, app.txPeriod) {
+			setTxRow(table, row, 2, t.Amount, t.Period, t.Category, "", t.Currency, strconv.Itoa(t.Count))
+			row++
+		}
+	} else {
+		for _, category := range models.TxCategories {
+			for i := len(txs) - 1; i >= 0; i-- {
+				tx := txs[i]
+				if tx.Category != category {
+					continue
+				}
+				setTxRow(table, row, 4, tx.Amount, tx.Category, tx.Timestamp.Format("2006-01-02 15:04"),
+					tx.Description, tx.Symbol, tx.Amount.String(), tx.Currency)
+				row++
+			}
+		}
+	}
+
+	if sel, _ := table.GetSelection(); sel < 1 || sel >= row {
+		table.Select(1, 0)
+	}
+}
+
+// setTxRow fills a row of the Transactions table. The cell at amountCol is colored by the
+// sign of amount, and shows it formatted when empty.
+func setTxRow(table *tview.Table, row, amountCol int, amount models.Decimal, cells ...string) {
+	rowBg := tcell.ColorBlack
+	if (row-1)%2 == 0 {
+		rowBg = tcell.ColorDarkGray
+	}
+	style := tcell.StyleDefault.Background(rowBg)
+	if cells[amountCol] == "" {
+		cells[amountCol] = formatNumber(amount.Float64(), 2)
+	}
+	for col, text := range cells {
+		color := tcell.ColorWhite
+		align := tview.AlignLeft
+		switch col {
+		case 0:
+			color = tcell.ColorLightYellow
+		case amountCol:
+			color = pnlColor(amount.Float64())
+			align = tview.AlignRight
+		}
+		table.SetCell(row, col, tview.NewTableCell(text).SetStyle(style.Foreground(color)).SetAlign(align))
+	}
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"finam-terminal/models"
+)
+
+func TestTransactionsTable_EntriesAndTotals(t *testing.T) {
+	loaded := make(chan time.Duration, 2)
+	client := &mockClient{
+		GetTransactionsFunc: func(accountID string, from, to time.Time) ([]models.Transaction, error) {
+			loaded <- to.Sub(from)
+			return nil, nil
+		},
+	}
+	app := NewApp(client, []models.AccountInfo{{ID: "acc1"}})
+	day := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	app.transactions["acc1"] = []models.Transaction{
+		{Category: models.TxCommission, Description: "Комиссия", Amount: models.DecimalOf("-10"), Currency: "RUB", Timestamp: day},
+		{Category: models.TxDeposit, Description: "Пополнение", Amount: models.DecimalOf("50000"), Currency: "RUB", Timestamp: day},
+		{Category: models.TxCommission, Description: "Комиссия", Amount: models.DecimalOf("-5.5"), Currency: "RUB", Timestamp: day.AddDate(0, 0, 10)},
+	}
+
+	table := app.portfolioView.TabbedView.TransactionsTable
+	updateTransactionsTable(app)
+	if rows := table.GetRowCount(); rows != 4 {
+		t.Fatalf("Expected three entries, got %d rows", rows)
+	}
+	if table.GetCell(1, 0).Text != models.TxDeposit || table.GetCell(2, 4).Text != "-5.5" {
+		t.Errorf("Expected the deposit first and commissions newest first, got %q and %q",
+			table.GetCell(1, 0).Text, table.GetCell(2, 4).Text)
+	}
+
+	app.toggleTxTotals()
+	if rows := table.GetRowCount(); rows != 3 || table.GetCell(2, 2).Text != "-15.50" || table.GetCell(2, 4).Text != "2" {
+		t.Errorf("Expected monthly totals with both commissions, got %d rows", rows)
+	}
+	app.cycleTxPeriod()
+	if got := table.GetCell(1, 0).Text; got != "2026-Q1" {
+		t.Errorf("Expected quarterly totals, got period %q", got)
+	}
+
+	app.loadOlderTransactions()
+	select {
+	case d := <-loaded:
+		if days := d.Hours() / 24; days < 179 || days > 181 {
+			t.Errorf("Expected 180 days of transactions requested, got %.1f", days)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the older transactions to be requested")
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"strings"
 	"unicode"
+
+	"finam-terminal/models"
 )
 
 // maskAccountID masks account ID for display
@@ -50,17 +52,13 @@ func extractUserMessage(err error) string {
 	return msg
 }
 
-// displayLots converts a raw quantity string to lot-based display.
-// If lotSize > 0, divides qty by lotSize; otherwise returns rawQty as-is.
-func displayLots(rawQty string, lotSize float64) string {
-	if lotSize <= 0 {
-		return rawQty
-	}
-	qty, err := parseFloat(rawQty)
-	if err != nil {
-		return rawQty
+// displayLots converts a raw quantity to lot-based display.
+// If lotSize > 0, divides qty by lotSize; otherwise returns qty as-is.
+func displayLots(qty models.Decimal, lotSize models.Decimal) string {
+	if lotSize.Sign() <= 0 || !qty.Known() {
+		return qty.String()
 	}
-	return fmt.Sprintf("%v", qty/lotSize)
+	return qty.Div(lotSize, 8).Trim().String()
 }
 
 // accountIdxToRow converts an account index to the first table row for that account.
@@ -118,6 +116,15 @@ func isOrderCancellable(status string) bool {
 	return status == "Active" || status == "Partial"
 }
 
+// isOrderFinished reports whether an order with the given status can no longer change.
+func isOrderFinished(status string) bool {
+	switch status {
+	case "Filled", "Executed", "Cancelled", "Rejected", "Expired", "Failed":
+		return true
+	}
+	return false
+}
+
 // parseFloat parses a string to float64, handling commas as decimal separators
 // and removing whitespace (including NBSP).
 func parseFloat(s string) (float64, error) {
@@ -0,0 +1,359 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"finam-terminal/models"
+	"finam-terminal/watchlist"
+
+	"github.com/FinamWeb/finam-trade-api/go/grpc/tradeapi/v1/marketdata"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Sparkline of the Watchlists tab: hourly closes of the last trading days
+const (
+	sparkPoints   = 24
+	sparkLookback = 7 * 24 * time.Hour
+)
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// SetWatchlistStore loads the watchlists saved in path and keeps saving them there.
+// Without a store, the lists last until the terminal exits.
+func (a *App) SetWatchlistStore(path string) error {
+	m := watchlist.NewManager(path)
+	if err := m.Load(); err != nil {
+		return err
+	}
+	a.watchlists = m
+	a.watchName = m.Lists()[0].Name
+	return nil
+}
+
+// activeWatchlist returns the list shown in the Watchlists tab.
+func (a *App) activeWatchlist() watchlist.List {
+	if l, ok := a.watchlists.Get(a.watchName); ok {
+		return l
+	}
+	l := a.watchlists.Lists()[0]
+	a.watchName = l.Name
+	return l
+}
+
+// addToWatchlist adds symbol to the active watchlist. Must be called on the UI thread.
+func (a *App) addToWatchlist(symbol string) {
+	list := a.activeWatchlist()
+	added, err := a.watchlists.Add(list.Name, symbol)
+	if err != nil {
+		log.Printf("[ERROR] %v", err)
+		if !added {
+			a.SetStatus(err.Error(), StatusError)
+			return
+		}
+	}
+	if !added {
+		a.SetStatus(fmt.Sprintf("%s is already in %s", symbol, list.Name), StatusInfo)
+		return
+	}
+	a.SetStatus(fmt.Sprintf("%s added to %s", symbol, list.Name), StatusSuccess)
+	updateWatchlistTable(a)
+	a.updateQuoteSubscription()
+	a.loadWatchlistAsync()
+}
+
+// removeSelectedWatch takes the selected instrument off the active watchlist.
+func (a *App) removeSelectedWatch() {
+	symbol, ok := a.selectedWatchSymbol()
+	if !ok {
+		return
+	}
+	if err := a.watchlists.Remove(a.watchName, symbol); err != nil {
+		log.Printf("[ERROR] %v", err)
+	}
+	a.SetStatus(fmt.Sprintf("%s removed from %s", symbol, a.watchName), StatusSuccess)
+	updateWatchlistTable(a)
+	a.updateQuoteSubscription()
+}
+
+// selectedWatchSymbol returns the instrument selected in the Watchlists table.
+func (a *App) selectedWatchSymbol() (string, bool) {
+	row, _ := a.portfolioView.TabbedView.WatchlistTable.GetSelection()
+	symbols := a.activeWatchlist().Symbols
+	if row < 1 || row > len(symbols) {
+		return "", false
+	}
+	return symbols[row-1], true
+}
+
+// cycleWatchlist shows the next (delta 1) or previous (delta -1) watchlist.
+func (a *App) cycleWatchlist(delta int) {
+	lists := a.watchlists.Lists()
+	idx := 0
+	for i, l := range lists {
+		if l.Name == a.watchName {
+			idx = i
+		}
+	}
+	idx = (idx + delta + len(lists)) % len(lists)
+	a.watchName = lists[idx].Name
+	a.portfolioView.TabbedView.WatchlistTable.Select(1, 0)
+	updateWatchlistTable(a)
+	a.updateQuoteSubscription()
+	a.loadWatchlistAsync()
+}
+
+// ShowNewWatchlistPrompt asks for the name of a new watchlist and switches to it.
+func (a *App) ShowNewWatchlistPrompt() {
+	input := tview.NewInputField().
+		SetLabel(" Name: ").
+		SetFieldWidth(24)
+	input.SetBorder(true).SetTitle(" New Watchlist ")
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			name := strings.TrimSpace(input.GetText())
+			if err := a.watchlists.Create(name); err != nil {
+				if errors.Is(err, watchlist.ErrExists) || name == "" {
+					a.SetStatus(err.Error(), StatusError)
+					return
+				}
+				log.Printf("[ERROR] %v", err)
+			}
+			a.watchName = name
+			a.SetStatus(fmt.Sprintf("Watchlist %s created", name), StatusSuccess)
+			updateWatchlistTable(a)
+			a.updateQuoteSubscription()
+		}
+		a.pages.RemovePage("watchlist_prompt")
+		a.app.SetFocus(a.portfolioView.TabbedView.WatchlistTable)
+	})
+
+	flex := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(input, 3, 1, true).
+			AddItem(nil, 0, 1, false), 40, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	a.pages.AddPage("watchlist_prompt", flex, true, true)
+	a.app.SetFocus(input)
+}
+
+// showDeleteWatchlist asks to delete the active watchlist.
+func (a *App) showDeleteWatchlist() {
+	name := a.watchName
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Delete watchlist %s?", name)).
+		AddButtons([]string{"Yes", "No"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("watchlist_prompt")
+			a.app.SetFocus(a.portfolioView.TabbedView.WatchlistTable)
+			if buttonLabel != "Yes" {
+				return
+			}
+			if err := a.watchlists.Delete(name); err != nil {
+				if errors.Is(err, watchlist.ErrLast) {
+					a.SetStatus(err.Error(), StatusError)
+					return
+				}
+				log.Printf("[ERROR] %v", err)
+			}
+			a.SetStatus(fmt.Sprintf("Watchlist %s deleted", name), StatusSuccess)
+			a.cycleWatchlist(0)
+		})
+	a.pages.AddPage("watchlist_prompt", modal, false, true)
+}
+
+// IsWatchlistPromptOpen returns true if the new or delete watchlist prompt is shown.
+func (a *App) IsWatchlistPromptOpen() bool {
+	name, _ := a.pages.GetFrontPage()
+	return name == "watchlist_prompt"
+}
+
+// loadWatchlistAsync fetches quotes and hourly bars for the instruments of the active
+// watchlist. Streamed quotes keep them current afterwards.
+func (a *App) loadWatchlistAsync() {
+	symbols := a.activeWatchlist().Symbols
+	a.dataMutex.RLock()
+	accountID := ""
+	if a.selectedIdx >= 0 && a.selectedIdx < len(a.accounts) {
+		accountID = a.accounts[a.selectedIdx].ID
+	}
+	a.dataMutex.RUnlock()
+	if len(symbols) == 0 || accountID == "" {
+		return
+	}
+
+	go func() {
+		quotes, err := a.client.GetSnapshots(accountID, symbols)
+		if err != nil {
+			log.Printf("[WARN] Failed to load watchlist quotes: %v", err)
+		}
+		now := time.Now()
+		sparks := make(map[string][]float64)
+		for _, symbol := range symbols {
+			bars, err := a.client.GetBars(accountID, symbol, marketdata.TimeFrame_TIME_FRAME_H1, now.Add(-sparkLookback), now)
+			if err != nil {
+				log.Printf("[WARN] Failed to load bars for %s: %v", symbol, err)
+				continue
+			}
+			closes := make([]float64, 0, len(bars))
+			for _, b := range bars {
+				closes = append(closes, b.Close)
+			}
+			if len(closes) > sparkPoints {
+				closes = closes[len(closes)-sparkPoints:]
+			}
+			sparks[symbol] = closes
+		}
+
+		a.app.QueueUpdateDraw(func() {
+			for symbol, q := range quotes {
+				a.watchQuotes[symbol] = q
+			}
+			for symbol, closes := range sparks {
+				a.watchSpark[symbol] = closes
+			}
+			updateWatchlistTable(a)
+		})
+	}()
+}
+
+// applyWatchQuotes keeps the active watchlist current with streamed quotes. The last point
+// of the sparkline follows the last price. Must be called on the UI thread.
+func (a *App) applyWatchQuotes(batch map[string]models.Quote) {
+	changed := false
+	for _, symbol := range a.activeWatchlist().Symbols {
+		for _, q := range batch {
+			if !symbolMatches(q.Symbol, symbol) {
+				continue
+			}
+			a.watchQuotes[symbol] = q
+			if q.Last.Sign() > 0 {
+				if spark := a.watchSpark[symbol]; len(spark) > 0 {
+					spark[len(spark)-1] = q.Last.Float64()
+				}
+			}
+			changed = true
+			break
+		}
+	}
+	if changed && a.portfolioView.TabbedView.ActiveTab == TabWatchlists {
+		updateWatchlistTable(a)
+	}
+}
+
+// updateWatchlistTable shows the instruments of the active watchlist with their quotes.
+func updateWatchlistTable(app *App) {
+	table := app.portfolioView.TabbedView.WatchlistTable
+	table.Clear()
+
+	lists := app.watchlists.Lists()
+	list := app.activeWatchlist()
+	pos := 1
+	for i, l := range lists {
+		if l.Name == list.Name {
+			pos = i + 1
+		}
+	}
+	table.SetTitle(fmt.Sprintf(" Watchlist: %s (%d/%d) ", list.Name, pos, len(lists)))
+
+	headers := []string{"Instrument", "Last", "Change %", "Bid", "Ask", "Volume", "Trend"}
+	headerStyle := tcell.StyleDefault.
+		Background(tcell.ColorDarkBlue).
+		Foreground(tcell.ColorWhite).
+		Bold(true)
+	for i, h := range headers {
+		align := tview.AlignRight
+		if i == 0 || i == len(headers)-1 {
+			align = tview.AlignLeft
+		}
+		table.SetCell(0, i, tview.NewTableCell(h).SetStyle(headerStyle).SetAlign(align).SetExpansion(1))
+	}
+
+	if len(list.Symbols) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell("Empty list. Press W in the search to add an instrument").
+			SetSelectable(false).
+			SetTextColor(tcell.ColorGray))
+		return
+	}
+
+	for i, symbol := range list.Symbols {
+		row := i + 1
+		rowBg := tcell.ColorBlack
+		if i%2 == 0 {
+			rowBg = tcell.ColorDarkGray
+		}
+		style := tcell.StyleDefault.Background(rowBg)
+
+		last, change, bid, ask, volume := "...", "", "", "", ""
+		changeColor := tcell.ColorGray
+		trendColor := tcell.ColorWhite
+		if q, ok := app.watchQuotes[symbol]; ok {
+			last, bid, ask, volume = q.Last.String(), q.Bid.String(), q.Ask.String(), q.Volume.String()
+			if q.Last.Known() && q.Close.Sign() > 0 {
+				pct := (q.Last.Float64() - q.Close.Float64()) / q.Close.Float64() * 100
+				change = fmt.Sprintf("%.2f%%", pct)
+				switch {
+				case pct > 0:
+					change = "+" + change
+					changeColor = tcell.ColorGreen
+					trendColor = tcell.ColorGreen
+				case pct < 0:
+					changeColor = tcell.ColorRed
+					trendColor = tcell.ColorRed
+				}
+			} else {
+				change = "N/A"
+			}
+		}
+
+		cells := []*tview.TableCell{
+			tview.NewTableCell(symbol).SetStyle(style.Foreground(tcell.ColorLightYellow)).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(last).SetStyle(style.Foreground(tcell.ColorWhite)),
+			tview.NewTableCell(change).SetStyle(style.Foreground(changeColor)),
+			tview.NewTableCell(bid).SetStyle(style.Foreground(tcell.ColorWhite)),
+			tview.NewTableCell(ask).SetStyle(style.Foreground(tcell.ColorWhite)),
+			tview.NewTableCell(volume).SetStyle(style.Foreground(tcell.ColorWhite)),
+			tview.NewTableCell(sparkline(app.watchSpark[symbol])).SetStyle(style.Foreground(trendColor)).SetAlign(tview.AlignLeft),
+		}
+		for col, cell := range cells {
+			if col > 0 && col < len(cells)-1 {
+				cell.SetAlign(tview.AlignRight)
+			}
+			table.SetCell(row, col, cell)
+		}
+	}
+	if sel, _ := table.GetSelection(); sel < 1 || sel > len(list.Symbols) {
+		table.Select(1, 0)
+	}
+}
+
+// sparkline draws values as a row of block characters scaled between their minimum and
+// maximum.
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		idx := len(sparkBlocks) / 2
+		if hi > lo {
+			idx = int((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[idx])
+	}
+	return b.String()
+}
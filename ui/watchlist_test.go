@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"testing"
+
+	"finam-terminal/models"
+)
+
+func TestWatchlist_AddAndStreamQuotes(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	app.watchlists.Add(app.watchName, "SBER@MISX")
+	app.watchlists.Add(app.watchName, "GAZP@MISX")
+	app.watchSpark["SBER@MISX"] = []float64{300, 305, 302}
+	app.portfolioView.TabbedView.SetTab(TabWatchlists)
+
+	if symbols := app.quoteSymbols(); len(symbols) != 2 {
+		t.Errorf("Expected the watchlist to be subscribed, got %v", symbols)
+	}
+
+	app.applyQuotes(map[string]models.Quote{
+		"SBER@MISX": {Symbol: "SBER@MISX", Last: models.DecimalOf("309"), Close: models.DecimalOf("300"), Bid: models.DecimalOf("308.9"), Ask: models.DecimalOf("309.1"), Volume: models.DecimalOf("12000")},
+	})
+
+	table := app.portfolioView.TabbedView.WatchlistTable
+	if got := table.GetCell(1, 1).Text; got != "309" {
+		t.Errorf("Expected the streamed last price, got %q", got)
+	}
+	if got := table.GetCell(1, 2).Text; got != "+3.00%" {
+		t.Errorf("Expected +3.00%%, got %q", got)
+	}
+	if got := table.GetCell(1, 6).Text; got != "▁▄█" {
+		t.Errorf("Expected the sparkline to end at the last price, got %q", got)
+	}
+	if got := table.GetCell(2, 1).Text; got != "..." {
+		t.Errorf("Expected GAZP still loading, got %q", got)
+	}
+
+	table.Select(2, 0)
+	app.removeSelectedWatch()
+	if l := app.activeWatchlist(); len(l.Symbols) != 1 || l.Symbols[0] != "SBER@MISX" {
+		t.Errorf("Expected GAZP removed, got %v", l.Symbols)
+	}
+}
+
+func TestWatchlist_CycleLists(t *testing.T) {
+	app := NewApp(&mockClient{}, []models.AccountInfo{{ID: "acc1"}})
+	app.watchlists.Create("Bonds")
+
+	app.cycleWatchlist(1)
+	if app.watchName != "Bonds" {
+		t.Fatalf("Expected the Bonds list, got %s", app.watchName)
+	}
+	if got := app.portfolioView.TabbedView.WatchlistTable.GetTitle(); got != " Watchlist: Bonds (2/2) " {
+		t.Errorf("Unexpected title %q", got)
+	}
+	app.cycleWatchlist(1)
+	if app.watchName != "Main" {
+		t.Errorf("Expected the lists to wrap around, got %s", app.watchName)
+	}
+}
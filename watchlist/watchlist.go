@@ -0,0 +1,178 @@
+// Package watchlist keeps named lists of instruments the user follows. The lists are
+// saved to a JSON file so they survive a restart.
+package watchlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"finam-terminal/atomicfile"
+)
+
+// DefaultName is the name of the list created when there is none.
+const DefaultName = "Main"
+
+// List is a named list of instrument symbols, in the order they were added.
+type List struct {
+	Name    string   `json:"name"`
+	Symbols []string `json:"symbols"`
+}
+
+var (
+	// ErrNotFound is returned for a list name that does not exist.
+	ErrNotFound = errors.New("watchlist not found")
+	// ErrExists is returned when a list of that name already exists.
+	ErrExists = errors.New("watchlist already exists")
+	// ErrLast is returned when deleting the only list.
+	ErrLast = errors.New("the last watchlist cannot be deleted")
+)
+
+// Manager keeps the watchlists and their file. It is safe for concurrent use.
+type Manager struct {
+	mu    sync.Mutex
+	path  string
+	lists []List
+}
+
+// NewManager returns a manager saving its lists to path, holding one empty DefaultName
+// list. An empty path keeps the lists in memory only.
+func NewManager(path string) *Manager {
+	return &Manager{
+		path:  path,
+		lists: []List{{Name: DefaultName}},
+	}
+}
+
+// Load reads the lists saved in the manager's file. A missing file is not an error.
+func (m *Manager) Load() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read watchlists: %w", err)
+	}
+	var lists []List
+	if err := json.Unmarshal(data, &lists); err != nil {
+		return fmt.Errorf("failed to parse watchlists %s: %w", m.path, err)
+	}
+	if len(lists) == 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lists = lists
+	return nil
+}
+
+// Lists returns every list in display order.
+func (m *Manager) Lists() []List {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]List, len(m.lists))
+	for i, l := range m.lists {
+		out[i] = List{Name: l.Name, Symbols: slices.Clone(l.Symbols)}
+	}
+	return out
+}
+
+// Get returns the list name.
+func (m *Manager) Get(name string) (List, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.index(name)
+	if i < 0 {
+		return List{}, false
+	}
+	return List{Name: m.lists[i].Name, Symbols: slices.Clone(m.lists[i].Symbols)}, true
+}
+
+// Create adds an empty list after the others.
+func (m *Manager) Create(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("watchlist name is empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.index(name) >= 0 {
+		return fmt.Errorf("%w: %s", ErrExists, name)
+	}
+	m.lists = append(m.lists, List{Name: name})
+	return m.save()
+}
+
+// Delete removes the list name. The last list cannot be deleted.
+func (m *Manager) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.index(name)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if len(m.lists) == 1 {
+		return ErrLast
+	}
+	m.lists = slices.Delete(m.lists, i, i+1)
+	return m.save()
+}
+
+// Add appends symbol to the list name. It reports false when the symbol is already there.
+func (m *Manager) Add(name, symbol string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.index(name)
+	if i < 0 {
+		return false, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if slices.Contains(m.lists[i].Symbols, symbol) {
+		return false, nil
+	}
+	m.lists[i].Symbols = append(m.lists[i].Symbols, symbol)
+	return true, m.save()
+}
+
+// Remove takes symbol off the list name.
+func (m *Manager) Remove(name, symbol string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.index(name)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	n := len(m.lists[i].Symbols)
+	m.lists[i].Symbols = slices.DeleteFunc(m.lists[i].Symbols, func(s string) bool { return s == symbol })
+	if len(m.lists[i].Symbols) == n {
+		return nil
+	}
+	return m.save()
+}
+
+// index returns the position of the list name, or -1. Called with m.mu held.
+func (m *Manager) index(name string) int {
+	return slices.IndexFunc(m.lists, func(l List) bool { return l.Name == name })
+}
+
+// save writes the lists to the manager's file. Called with m.mu held.
+func (m *Manager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(m.lists, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.Write(m.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save watchlists: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package watchlist
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestManager_AddRemove(t *testing.T) {
+	m := NewManager("")
+	if added, err := m.Add(DefaultName, "SBER@MISX"); !added || err != nil {
+		t.Fatalf("Expected SBER added, got %v %v", added, err)
+	}
+	m.Add(DefaultName, "GAZP@MISX")
+	if added, _ := m.Add(DefaultName, "SBER@MISX"); added {
+		t.Error("Expected a duplicate not to be added")
+	}
+	if _, err := m.Add("Bonds", "SU26238RMFS4@MISX"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if err := m.Remove(DefaultName, "SBER@MISX"); err != nil {
+		t.Fatal(err)
+	}
+	l, _ := m.Get(DefaultName)
+	if len(l.Symbols) != 1 || l.Symbols[0] != "GAZP@MISX" {
+		t.Errorf("Expected only GAZP left, got %v", l.Symbols)
+	}
+}
+
+func TestManager_CreateDelete(t *testing.T) {
+	m := NewManager("")
+	if err := m.Create("Bonds"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Create("Bonds"); !errors.Is(err, ErrExists) {
+		t.Errorf("Expected ErrExists, got %v", err)
+	}
+	if err := m.Create("  "); err == nil {
+		t.Error("Expected an empty name to be refused")
+	}
+	if lists := m.Lists(); len(lists) != 2 || lists[1].Name != "Bonds" {
+		t.Fatalf("Expected Main and Bonds, got %v", lists)
+	}
+
+	if err := m.Delete(DefaultName); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete("Bonds"); !errors.Is(err, ErrLast) {
+		t.Errorf("Expected ErrLast, got %v", err)
+	}
+}
+
+func TestManager_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watchlists.json")
+	m := NewManager(path)
+	m.Create("Futures")
+	m.Add("Futures", "SiM6@RTSX")
+	m.Add(DefaultName, "SBER@MISX")
+
+	restarted := NewManager(path)
+	if err := restarted.Load(); err != nil {
+		t.Fatal(err)
+	}
+	lists := restarted.Lists()
+	if len(lists) != 2 || lists[0].Name != DefaultName || lists[1].Symbols[0] != "SiM6@RTSX" {
+		t.Errorf("Expected both lists after a restart, got %v", lists)
+	}
+}
+
+func TestManager_LoadMissingFile(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "none.json"))
+	if err := m.Load(); err != nil {
+		t.Errorf("Expected no error for a missing file, got %v", err)
+	}
+	if lists := m.Lists(); len(lists) != 1 || lists[0].Name != DefaultName {
+		t.Errorf("Expected the default list, got %v", lists)
+	}
+}